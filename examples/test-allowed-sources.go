@@ -44,7 +44,7 @@ func main() {
 
 	// Get allowed sources
 	fmt.Printf("Finding services that can access %s in namespace %s...\n", targetService, namespace)
-	result, err := analyzer.GetAllowedSources(context.Background(), namespace, targetService)
+	result, err := analyzer.GetAllowedSources(context.Background(), namespace, targetService, false)
 	if err != nil {
 		log.Fatalf("Failed to get allowed sources: %v", err)
 	}
@@ -44,7 +44,7 @@ func main() {
 
 	// Get allowed targets
 	fmt.Printf("Finding services that %s can access in namespace %s...\n", sourceService, namespace)
-	result, err := analyzer.GetAllowedTargets(context.Background(), namespace, sourceService)
+	result, err := analyzer.GetAllowedTargets(context.Background(), namespace, sourceService, false)
 	if err != nil {
 		log.Fatalf("Failed to get allowed targets: %v", err)
 	}
@@ -40,7 +40,7 @@ func main() {
 		fmt.Printf("Listing meshed services in namespace: %s...\n", namespace)
 	}
 
-	result, err := lister.ListMeshedServices(context.Background(), namespace)
+	result, err := lister.ListMeshedServices(context.Background(), namespace, "", false)
 	if err != nil {
 		log.Fatalf("Failed to list meshed services: %v", err)
 	}
@@ -43,7 +43,7 @@ func main() {
 
 	// Create metrics collector
 	fmt.Println("Connecting to Prometheus...")
-	collector, err := metrics.NewMetricsCollector(clients.Config, clients.Clientset, "linkerd")
+	collector, err := metrics.NewMetricsCollector(clients.Config, clients.Clientset, clients.DynamicClient, "linkerd")
 	if err != nil {
 		log.Fatalf("Failed to create metrics collector: %v\n", err)
 	}
@@ -56,7 +56,7 @@ func main() {
 	fmt.Printf("Service: %s/%s\n", namespace, service)
 	fmt.Printf("Time Range: %s\n\n", timeRange)
 
-	result, err := collector.GetServiceMetrics(ctx, namespace, service, timeRange)
+	result, err := collector.GetServiceMetrics(ctx, namespace, service, timeRange, "")
 	if err != nil {
 		log.Fatalf("Failed to get service metrics: %v", err)
 	}
@@ -75,14 +75,17 @@ func main() {
 
 		fmt.Println("Service Metrics:")
 		fmt.Printf("  Service: %s/%s\n", serviceMetrics.Namespace, serviceMetrics.Service)
-		fmt.Printf("  Request Rate: %.2f req/s\n", serviceMetrics.RequestRate)
-		fmt.Printf("  Success Rate: %.2f%%\n", serviceMetrics.SuccessRate)
-		fmt.Printf("  Error Rate: %.2f%%\n", serviceMetrics.ErrorRate)
+		if !serviceMetrics.DataAvailable {
+			fmt.Println("  No request metrics found in the time range")
+		}
+		fmt.Printf("  Request Rate: %s req/s\n", formatOptional(serviceMetrics.RequestRate))
+		fmt.Printf("  Success Rate: %s%%\n", formatOptional(serviceMetrics.SuccessRate))
+		fmt.Printf("  Error Rate: %s%%\n", formatOptional(serviceMetrics.ErrorRate))
 		fmt.Printf("  Latency:\n")
-		fmt.Printf("    P50: %.2fms\n", serviceMetrics.Latency.P50)
-		fmt.Printf("    P95: %.2fms\n", serviceMetrics.Latency.P95)
-		fmt.Printf("    P99: %.2fms\n", serviceMetrics.Latency.P99)
-		fmt.Printf("    Mean: %.2fms\n", serviceMetrics.Latency.Mean)
+		fmt.Printf("    P50: %sms\n", formatOptional(serviceMetrics.Latency.P50))
+		fmt.Printf("    P95: %sms\n", formatOptional(serviceMetrics.Latency.P95))
+		fmt.Printf("    P99: %sms\n", formatOptional(serviceMetrics.Latency.P99))
+		fmt.Printf("    Mean: %sms\n", formatOptional(serviceMetrics.Latency.Mean))
 
 		if len(serviceMetrics.ErrorsByStatus) > 0 {
 			fmt.Printf("  Errors by Status:\n")
@@ -144,12 +147,20 @@ func main() {
 		fmt.Println("Top Services:")
 		for i, svc := range topServices.Services {
 			fmt.Printf("%d. %s\n", i+1, svc.Service)
-			fmt.Printf("   Request Rate: %.2f req/s\n", svc.RequestRate)
-			fmt.Printf("   Success Rate: %.2f%%\n", svc.SuccessRate)
-			fmt.Printf("   Error Rate: %.2f%%\n", svc.ErrorRate)
-			fmt.Printf("   P95 Latency: %.2fms\n", svc.LatencyP95)
+			fmt.Printf("   Request Rate: %s req/s\n", formatOptional(svc.RequestRate))
+			fmt.Printf("   Success Rate: %s%%\n", formatOptional(svc.SuccessRate))
+			fmt.Printf("   Error Rate: %s%%\n", formatOptional(svc.ErrorRate))
+			fmt.Printf("   P95 Latency: %sms\n", formatOptional(svc.LatencyP95))
 		}
 	}
 
 	fmt.Println("\n✓ All metrics tests completed successfully")
 }
+
+// formatOptional prints a metric value, or n/a when Prometheus had no data for it
+func formatOptional(v *float64) string {
+	if v == nil {
+		return "n/a"
+	}
+	return fmt.Sprintf("%.2f", *v)
+}
@@ -0,0 +1,49 @@
+// Package buildinfo describes the build of the running binary
+package buildinfo
+
+import (
+	"runtime"
+	"runtime/debug"
+)
+
+// unknown is reported for build details neither the linker flags nor the Go toolchain recorded
+const unknown = "unknown"
+
+// Info identifies the build of the server
+type Info struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	Date      string `json:"date"`
+	GoVersion string `json:"goVersion"`
+}
+
+// New builds the Info from the values set with -ldflags. Empty values fall back to the VCS
+// revision and commit time the Go toolchain embeds in the binary, then to "unknown".
+func New(version, commit, date string) Info {
+	info := Info{Version: version, Commit: commit, Date: date, GoVersion: runtime.Version()}
+
+	if build, ok := debug.ReadBuildInfo(); ok {
+		if info.Version == "" && build.Main.Version != "" && build.Main.Version != "(devel)" {
+			info.Version = build.Main.Version
+		}
+		for _, setting := range build.Settings {
+			switch setting.Key {
+			case "vcs.revision":
+				if info.Commit == "" {
+					info.Commit = setting.Value
+				}
+			case "vcs.time":
+				if info.Date == "" {
+					info.Date = setting.Value
+				}
+			}
+		}
+	}
+
+	for _, value := range []*string{&info.Version, &info.Commit, &info.Date} {
+		if *value == "" {
+			*value = unknown
+		}
+	}
+	return info
+}
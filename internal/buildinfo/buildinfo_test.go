@@ -0,0 +1,30 @@
+package buildinfo_test
+
+import (
+	"runtime"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/christianhuening/linkerd-mcp/internal/buildinfo"
+)
+
+var _ = Describe("New", func() {
+	It("should keep the values set with ldflags", func() {
+		info := buildinfo.New("v1.2.3", "abc123", "2025-01-02T03:04:05Z")
+		Expect(info).To(Equal(buildinfo.Info{
+			Version:   "v1.2.3",
+			Commit:    "abc123",
+			Date:      "2025-01-02T03:04:05Z",
+			GoVersion: runtime.Version(),
+		}))
+	})
+
+	It("should never report empty values", func() {
+		info := buildinfo.New("", "", "")
+		Expect(info.Version).NotTo(BeEmpty())
+		Expect(info.Commit).NotTo(BeEmpty())
+		Expect(info.Date).NotTo(BeEmpty())
+		Expect(info.GoVersion).To(Equal(runtime.Version()))
+	})
+})
@@ -3,18 +3,30 @@ package config
 import (
 	"fmt"
 	"os"
+	"strconv"
+	"time"
 
+	"k8s.io/client-go/discovery"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
 )
 
+const (
+	// DefaultKubeQPS replaces client-go's default of 5, which throttles the many list and get
+	// calls made when analyzing policy on large clusters
+	DefaultKubeQPS = 50
+	// DefaultKubeBurst replaces client-go's default of 10
+	DefaultKubeBurst = 100
+)
+
 // KubernetesClients holds the Kubernetes clients
 type KubernetesClients struct {
-	Config        *rest.Config
-	Clientset     *kubernetes.Clientset
-	DynamicClient dynamic.Interface
+	Config          *rest.Config
+	Clientset       *kubernetes.Clientset
+	DynamicClient   dynamic.Interface
+	DiscoveryClient discovery.DiscoveryInterface
 }
 
 // NewKubernetesClients creates and returns Kubernetes clients
@@ -35,30 +47,99 @@ func NewKubernetesClients() (*KubernetesClients, error) {
 	}
 
 	return &KubernetesClients{
-		Config:        config,
-		Clientset:     clientset,
-		DynamicClient: dynamicClient,
+		Config:          config,
+		Clientset:       clientset,
+		DynamicClient:   dynamicClient,
+		DiscoveryClient: clientset.Discovery(),
 	}, nil
 }
 
-// GetKubeConfig returns the Kubernetes configuration
+// GetKubeConfig returns the Kubernetes configuration for the KUBE_CONTEXT context (default: the
+// current context), tuned by the KUBE_QPS, KUBE_BURST and KUBE_TIMEOUT environment variables
 func GetKubeConfig() (*rest.Config, error) {
-	// Try in-cluster config first (when running in Kubernetes)
-	config, err := rest.InClusterConfig()
-	if err == nil {
-		return config, nil
+	config, err := loadKubeConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := applyClientTuning(config); err != nil {
+		return nil, err
 	}
+	return config, nil
+}
+
+// loadKubeConfig loads the in-cluster configuration, falling back to the kubeconfig files. In a
+// pod the in-cluster configuration wins unless KUBE_CONTEXT explicitly selects a context.
+func loadKubeConfig() (*rest.Config, error) {
+	kubeContext := os.Getenv("KUBE_CONTEXT")
 
-	// Fall back to kubeconfig file (for local development)
-	kubeconfig := os.Getenv("KUBECONFIG")
-	if kubeconfig == "" {
-		kubeconfig = os.Getenv("HOME") + "/.kube/config"
+	// Try in-cluster config first (when running in Kubernetes)
+	if kubeContext == "" {
+		if config, err := rest.InClusterConfig(); err == nil {
+			return config, nil
+		}
 	}
 
-	config, err = clientcmd.BuildConfigFromFlags("", kubeconfig)
+	// Fall back to the kubeconfig files (for local development). The default loading rules merge
+	// every path listed in KUBECONFIG, or read ~/.kube/config when it is unset.
+	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+	overrides := &clientcmd.ConfigOverrides{CurrentContext: kubeContext}
+
+	config, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules, overrides).ClientConfig()
 	if err != nil {
+		if kubeContext != "" {
+			return nil, fmt.Errorf("failed to load context %q: %w", kubeContext, err)
+		}
 		return nil, err
 	}
 
 	return config, nil
 }
+
+// KubernetesCheckIntervalFromEnv reads how often the server re-checks that the Kubernetes API
+// server is reachable from KUBE_CHECK_INTERVAL (a duration; default 0, no periodic check)
+func KubernetesCheckIntervalFromEnv() (time.Duration, error) {
+	value := os.Getenv("KUBE_CHECK_INTERVAL")
+	if value == "" {
+		return 0, nil
+	}
+
+	interval, err := time.ParseDuration(value)
+	if err != nil || interval <= 0 {
+		return 0, fmt.Errorf("invalid KUBE_CHECK_INTERVAL %q: must be a positive duration", value)
+	}
+	return interval, nil
+}
+
+// applyClientTuning sets the client rate limits from KUBE_QPS (default 50) and KUBE_BURST
+// (default 100), and the timeout of each request from KUBE_TIMEOUT (a duration; default none)
+func applyClientTuning(config *rest.Config) error {
+	config.QPS = DefaultKubeQPS
+	config.Burst = DefaultKubeBurst
+
+	if value := os.Getenv("KUBE_QPS"); value != "" {
+		qps, err := strconv.ParseFloat(value, 32)
+		if err != nil || qps <= 0 {
+			return fmt.Errorf("invalid KUBE_QPS %q: must be a positive number", value)
+		}
+		config.QPS = float32(qps)
+	}
+
+	if value := os.Getenv("KUBE_BURST"); value != "" {
+		burst, err := strconv.Atoi(value)
+		if err != nil || burst <= 0 {
+			return fmt.Errorf("invalid KUBE_BURST %q: must be a positive integer", value)
+		}
+		config.Burst = burst
+	}
+
+	if value := os.Getenv("KUBE_TIMEOUT"); value != "" {
+		timeout, err := time.ParseDuration(value)
+		if err != nil || timeout <= 0 {
+			return fmt.Errorf("invalid KUBE_TIMEOUT %q: must be a positive duration", value)
+		}
+		config.Timeout = timeout
+	}
+
+	return nil
+}
@@ -0,0 +1,35 @@
+package config
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/christianhuening/linkerd-mcp/internal/kube"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// DefaultLinkerdNamespace is the namespace Linkerd installs its control plane into by default
+const DefaultLinkerdNamespace = "linkerd"
+
+// LinkerdNamespace returns the Linkerd control plane namespace from the LINKERD_NAMESPACE
+// environment variable, or DefaultLinkerdNamespace when it is unset
+func LinkerdNamespace() string {
+	if namespace := os.Getenv("LINKERD_NAMESPACE"); namespace != "" {
+		return namespace
+	}
+	return DefaultLinkerdNamespace
+}
+
+// ProxyContainerFromEnv returns the name of the proxy container from the LINKERD_PROXY_CONTAINER
+// environment variable, for installs that rename it, or kube.DefaultProxyContainerName when it
+// is unset
+func ProxyContainerFromEnv() (string, error) {
+	name := os.Getenv("LINKERD_PROXY_CONTAINER")
+	if name == "" {
+		return kube.DefaultProxyContainerName, nil
+	}
+	if errs := validation.IsDNS1123Label(name); len(errs) > 0 {
+		return "", fmt.Errorf("invalid LINKERD_PROXY_CONTAINER %q: must be a container name (%s)", name, errs[0])
+	}
+	return name, nil
+}
@@ -0,0 +1,27 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/christianhuening/linkerd-mcp/internal/kube"
+)
+
+// NamespaceFilterFromEnv reads the namespaces covered by cluster-wide operations from the
+// comma-separated NAMESPACE_ALLOWLIST and NAMESPACE_DENYLIST environment variables. Entries are
+// namespace names, or regular expressions between slashes. The denylist defaults to
+// kube.DefaultNamespaceDenylist; setting it to an empty value covers the system namespaces too.
+func NamespaceFilterFromEnv() (*kube.NamespaceFilter, error) {
+	deny := kube.DefaultNamespaceDenylist
+	if value, ok := os.LookupEnv("NAMESPACE_DENYLIST"); ok {
+		deny = strings.Split(value, ",")
+	}
+	allow := strings.Split(os.Getenv("NAMESPACE_ALLOWLIST"), ",")
+
+	filter, err := kube.NewNamespaceFilter(allow, deny)
+	if err != nil {
+		return nil, fmt.Errorf("invalid NAMESPACE_ALLOWLIST or NAMESPACE_DENYLIST: %w", err)
+	}
+	return filter, nil
+}
@@ -0,0 +1,89 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+// PrometheusStartupCheckMode controls whether the server probes Prometheus when it starts
+type PrometheusStartupCheckMode string
+
+const (
+	// PrometheusStartupCheckOff skips the probe; Prometheus errors surface on the first query
+	PrometheusStartupCheckOff PrometheusStartupCheckMode = "off"
+	// PrometheusStartupCheckWarn probes Prometheus and logs the outcome without failing
+	PrometheusStartupCheckWarn PrometheusStartupCheckMode = "warn"
+	// PrometheusStartupCheckRequire probes Prometheus and fails startup when it is unreachable
+	PrometheusStartupCheckRequire PrometheusStartupCheckMode = "require"
+)
+
+// DefaultPrometheusStartupTimeout bounds the startup probe so an unreachable Prometheus
+// doesn't hold up the server
+const DefaultPrometheusStartupTimeout = 5 * time.Second
+
+// PrometheusStartupCheck configures the Prometheus probe run by server.New
+type PrometheusStartupCheck struct {
+	Mode    PrometheusStartupCheckMode
+	Timeout time.Duration
+}
+
+// PrometheusStartupCheckFromEnv reads the startup probe configuration from the
+// LINKERD_PROMETHEUS_STARTUP_CHECK (off, warn or require; default off) and
+// LINKERD_PROMETHEUS_STARTUP_TIMEOUT (a duration; default 5s) environment variables
+func PrometheusStartupCheckFromEnv() (PrometheusStartupCheck, error) {
+	check := PrometheusStartupCheck{
+		Mode:    PrometheusStartupCheckOff,
+		Timeout: DefaultPrometheusStartupTimeout,
+	}
+
+	switch mode := PrometheusStartupCheckMode(os.Getenv("LINKERD_PROMETHEUS_STARTUP_CHECK")); mode {
+	case "":
+	case PrometheusStartupCheckOff, PrometheusStartupCheckWarn, PrometheusStartupCheckRequire:
+		check.Mode = mode
+	default:
+		return check, fmt.Errorf("invalid LINKERD_PROMETHEUS_STARTUP_CHECK %q: must be one of off, warn, require", mode)
+	}
+
+	if value := os.Getenv("LINKERD_PROMETHEUS_STARTUP_TIMEOUT"); value != "" {
+		timeout, err := time.ParseDuration(value)
+		if err != nil || timeout <= 0 {
+			return check, fmt.Errorf("invalid LINKERD_PROMETHEUS_STARTUP_TIMEOUT %q: must be a positive duration", value)
+		}
+		check.Timeout = timeout
+	}
+
+	return check, nil
+}
+
+// DefaultMetricsNamespace returns the namespace metrics tools query when called without one,
+// from the DEFAULT_METRICS_NAMESPACE environment variable. Empty means the namespace argument
+// is required.
+func DefaultMetricsNamespace() string {
+	return os.Getenv("DEFAULT_METRICS_NAMESPACE")
+}
+
+// PromQLPassthroughMode controls whether the query_linkerd_metrics tool runs raw PromQL
+type PromQLPassthroughMode string
+
+const (
+	// PromQLPassthroughOff doesn't register the tool
+	PromQLPassthroughOff PromQLPassthroughMode = "off"
+	// PromQLPassthroughLinkerd only runs queries selecting Linkerd proxy metrics
+	PromQLPassthroughLinkerd PromQLPassthroughMode = "linkerd"
+	// PromQLPassthroughAny runs any query
+	PromQLPassthroughAny PromQLPassthroughMode = "any"
+)
+
+// PromQLPassthroughFromEnv reads whether raw PromQL queries are allowed from the
+// LINKERD_PROMQL_PASSTHROUGH environment variable (off, linkerd or any; default off)
+func PromQLPassthroughFromEnv() (PromQLPassthroughMode, error) {
+	switch mode := PromQLPassthroughMode(os.Getenv("LINKERD_PROMQL_PASSTHROUGH")); mode {
+	case "":
+		return PromQLPassthroughOff, nil
+	case PromQLPassthroughOff, PromQLPassthroughLinkerd, PromQLPassthroughAny:
+		return mode, nil
+	default:
+		return PromQLPassthroughOff, fmt.Errorf("invalid LINKERD_PROMQL_PASSTHROUGH %q: must be one of off, linkerd, any", mode)
+	}
+}
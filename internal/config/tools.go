@@ -0,0 +1,111 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// DefaultToolTimeout bounds a tool call when TOOL_TIMEOUT is unset
+const DefaultToolTimeout = 30 * time.Second
+
+// toolTimeoutPrefix starts the variables overriding the timeout of a single tool
+const toolTimeoutPrefix = "TOOL_TIMEOUT_"
+
+// ToolTimeouts bound how long a tool call may run before it is abandoned
+type ToolTimeouts struct {
+	Default time.Duration
+	PerTool map[string]time.Duration
+}
+
+// For returns the timeout of a tool. Zero means no timeout.
+func (t ToolTimeouts) For(tool string) time.Duration {
+	if timeout, ok := t.PerTool[tool]; ok {
+		return timeout
+	}
+	return t.Default
+}
+
+// Max returns the longest timeout of any tool
+func (t ToolTimeouts) Max() time.Duration {
+	longest := t.Default
+	for _, timeout := range t.PerTool {
+		longest = max(longest, timeout)
+	}
+	return longest
+}
+
+// ToolTimeoutsFromEnv reads the default tool timeout from TOOL_TIMEOUT (a duration; default
+// 30s) and per-tool overrides from TOOL_TIMEOUT_<TOOL>, where <TOOL> is the tool name in upper
+// case, e.g. TOOL_TIMEOUT_EXPORT_POLICY_GRAPH=2m
+func ToolTimeoutsFromEnv() (ToolTimeouts, error) {
+	timeouts := ToolTimeouts{
+		Default: DefaultToolTimeout,
+		PerTool: map[string]time.Duration{},
+	}
+
+	if value := os.Getenv("TOOL_TIMEOUT"); value != "" {
+		timeout, err := parseToolTimeout("TOOL_TIMEOUT", value)
+		if err != nil {
+			return timeouts, err
+		}
+		timeouts.Default = timeout
+	}
+
+	for _, entry := range os.Environ() {
+		name, value, _ := strings.Cut(entry, "=")
+		if !strings.HasPrefix(name, toolTimeoutPrefix) || value == "" {
+			continue
+		}
+		timeout, err := parseToolTimeout(name, value)
+		if err != nil {
+			return timeouts, err
+		}
+		timeouts.PerTool[strings.ToLower(strings.TrimPrefix(name, toolTimeoutPrefix))] = timeout
+	}
+
+	return timeouts, nil
+}
+
+func parseToolTimeout(name, value string) (time.Duration, error) {
+	timeout, err := time.ParseDuration(value)
+	if err != nil || timeout <= 0 {
+		return 0, fmt.Errorf("invalid %s %q: must be a positive duration", name, value)
+	}
+	return timeout, nil
+}
+
+// ToolFilter restricts the tools the server registers. The zero value allows every tool.
+type ToolFilter struct {
+	Enabled  map[string]bool // when not empty, only these tools are registered
+	Disabled map[string]bool
+}
+
+// Allows reports whether a tool is registered. A disabled tool is never registered, even when it
+// is also enabled.
+func (f ToolFilter) Allows(tool string) bool {
+	if f.Disabled[tool] {
+		return false
+	}
+	return len(f.Enabled) == 0 || f.Enabled[tool]
+}
+
+// ToolFilterFromEnv reads the tools to register from the comma-separated ENABLED_TOOLS and
+// DISABLED_TOOLS environment variables. When neither is set, every tool is registered.
+func ToolFilterFromEnv() ToolFilter {
+	return ToolFilter{
+		Enabled:  parseToolNames(os.Getenv("ENABLED_TOOLS")),
+		Disabled: parseToolNames(os.Getenv("DISABLED_TOOLS")),
+	}
+}
+
+func parseToolNames(value string) map[string]bool {
+	names := map[string]bool{}
+	for _, name := range strings.Split(value, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names[name] = true
+		}
+	}
+	return names
+}
@@ -0,0 +1,221 @@
+package health
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// trustRootsConfigMapName holds the trust anchors proxies validate identities against
+	trustRootsConfigMapName = "linkerd-identity-trust-roots"
+	trustRootsKey           = "ca-bundle.crt"
+	// issuerSecretName holds the certificate linkerd-identity signs proxy certificates with
+	issuerSecretName = "linkerd-identity-issuer"
+	// DefaultCertificateExpiryWarning is how long before expiry a certificate is reported as expiring
+	DefaultCertificateExpiryWarning = 30 * 24 * time.Hour
+)
+
+// issuerCertificateKeys are the keys of the issuer certificate in kubernetes.io/tls secrets and in
+// the linkerd.io/tls secrets of older installs
+var issuerCertificateKeys = []string{"tls.crt", "crt.pem"}
+
+// Certificate problems reported by CheckCertificates
+const (
+	CertificateProblemTrustAnchorMissing  = "TrustAnchorMissing"
+	CertificateProblemTrustAnchorExpired  = "TrustAnchorExpired"
+	CertificateProblemTrustAnchorExpiring = "TrustAnchorExpiring"
+	CertificateProblemIssuerMissing       = "IssuerMissing"
+	CertificateProblemIssuerExpired       = "IssuerExpired"
+	CertificateProblemIssuerExpiring      = "IssuerExpiring"
+	CertificateProblemChainMismatch       = "ChainMismatch"
+)
+
+// CertificateInfo describes one certificate of the identity chain
+type CertificateInfo struct {
+	Subject       string    `json:"subject"`
+	Issuer        string    `json:"issuer"`
+	NotBefore     time.Time `json:"notBefore"`
+	NotAfter      time.Time `json:"notAfter"`
+	DaysRemaining int       `json:"daysRemaining"` // negative once expired
+	Expired       bool      `json:"expired"`
+	Expiring      bool      `json:"expiring"` // expires within the warning window
+}
+
+// CertificateIssue is a problem of the identity chain with what to do about it
+type CertificateIssue struct {
+	Problem     string `json:"problem"`
+	Severity    string `json:"severity"` // error or warning
+	Message     string `json:"message"`
+	Remediation string `json:"remediation"`
+}
+
+// CertificateReport is the state of the trust anchors and issuer certificate of the control plane
+type CertificateReport struct {
+	Namespace                 string             `json:"namespace"`
+	Healthy                   bool               `json:"healthy"` // no error; expiring certificates are warnings
+	TrustAnchors              []CertificateInfo  `json:"trustAnchors"`
+	Issuer                    *CertificateInfo   `json:"issuer"`
+	IssuerSignedByTrustAnchor bool               `json:"issuerSignedByTrustAnchor"`
+	Issues                    []CertificateIssue `json:"issues"`
+}
+
+// CheckCertificates reports the expiry of the trust anchors, from the linkerd-identity-trust-roots
+// ConfigMap, and of the issuer certificate, from the linkerd-identity-issuer Secret, and whether
+// the issuer is signed by one of the trust anchors. Certificates expiring within warnWithin are
+// flagged; zero uses DefaultCertificateExpiryWarning.
+func (c *Checker) CheckCertificates(ctx context.Context, warnWithin time.Duration) (*mcp.CallToolResult, error) {
+	if warnWithin <= 0 {
+		warnWithin = DefaultCertificateExpiryWarning
+	}
+
+	anchorsPEM := ""
+	configMap, err := c.clientset.CoreV1().ConfigMaps(c.controlPlaneNamespace).Get(ctx, trustRootsConfigMapName, metav1.GetOptions{})
+	switch {
+	case errors.IsNotFound(err):
+	case err != nil:
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to get ConfigMap %s: %v", trustRootsConfigMapName, err)), nil
+	default:
+		anchorsPEM = configMap.Data[trustRootsKey]
+	}
+
+	var issuerPEM []byte
+	secret, err := c.clientset.CoreV1().Secrets(c.controlPlaneNamespace).Get(ctx, issuerSecretName, metav1.GetOptions{})
+	switch {
+	case errors.IsNotFound(err):
+	case err != nil:
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to get Secret %s: %v", issuerSecretName, err)), nil
+	default:
+		for _, key := range issuerCertificateKeys {
+			if data, ok := secret.Data[key]; ok {
+				issuerPEM = data
+				break
+			}
+		}
+	}
+
+	report := BuildCertificateReport([]byte(anchorsPEM), issuerPEM, time.Now(), warnWithin)
+	report.Namespace = c.controlPlaneNamespace
+
+	data, err := json.Marshal(report)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal certificate report: %v", err)), nil
+	}
+
+	return mcp.NewToolResultText(string(data)), nil
+}
+
+// BuildCertificateReport checks PEM encoded trust anchors and issuer certificate at a point in
+// time. The anchors may be a bundle, as during a trust anchor rotation; the issuer has to be signed
+// by one of them. Missing or unparseable certificates are reported as missing.
+func BuildCertificateReport(anchorsPEM, issuerPEM []byte, now time.Time, warnWithin time.Duration) CertificateReport {
+	report := CertificateReport{
+		TrustAnchors: []CertificateInfo{},
+		Issues:       []CertificateIssue{},
+	}
+	addIssue := func(problem, severity, message, remediation string) {
+		report.Issues = append(report.Issues, CertificateIssue{problem, severity, message, remediation})
+	}
+
+	anchors := parseCertificates(anchorsPEM)
+	if len(anchors) == 0 {
+		addIssue(CertificateProblemTrustAnchorMissing, "error",
+			fmt.Sprintf("No trust anchor found in ConfigMap %s (key %s)", trustRootsConfigMapName, trustRootsKey),
+			"Check that Linkerd is installed in this namespace; reinstall the trust anchor with the identity.trustAnchorsPEM value")
+	}
+	for _, anchor := range anchors {
+		info := certificateInfo(anchor, now, warnWithin)
+		report.TrustAnchors = append(report.TrustAnchors, info)
+		switch {
+		case info.Expired:
+			addIssue(CertificateProblemTrustAnchorExpired, "error",
+				fmt.Sprintf("Trust anchor %s expired on %s", info.Subject, info.NotAfter.Format(time.RFC3339)),
+				"Generate a new trust anchor and issuer, update identity.trustAnchorsPEM and the issuer secret, then restart the control plane and every meshed workload; proxies can't validate each other until they do")
+		case info.Expiring:
+			addIssue(CertificateProblemTrustAnchorExpiring, "warning",
+				fmt.Sprintf("Trust anchor %s expires in %d days", info.Subject, info.DaysRemaining),
+				"Rotate the trust anchor before it expires: add the new anchor to the bundle alongside the current one, roll out the workloads, then switch the issuer and remove the old anchor")
+		}
+	}
+
+	issuers := parseCertificates(issuerPEM)
+	if len(issuers) == 0 {
+		addIssue(CertificateProblemIssuerMissing, "error",
+			fmt.Sprintf("No issuer certificate found in Secret %s", issuerSecretName),
+			"Recreate the issuer secret with a certificate signed by the trust anchor, or check that cert-manager issued it")
+	} else {
+		issuer := issuers[0]
+		info := certificateInfo(issuer, now, warnWithin)
+		report.Issuer = &info
+		switch {
+		case info.Expired:
+			addIssue(CertificateProblemIssuerExpired, "error",
+				fmt.Sprintf("Issuer certificate %s expired on %s; proxies can't obtain or renew their certificates", info.Subject, info.NotAfter.Format(time.RFC3339)),
+				"Issue a new issuer certificate signed by the current trust anchor, update the issuer secret and restart linkerd-identity; the trust anchor and workloads can stay as they are")
+		case info.Expiring:
+			addIssue(CertificateProblemIssuerExpiring, "warning",
+				fmt.Sprintf("Issuer certificate %s expires in %d days", info.Subject, info.DaysRemaining),
+				"Rotate the issuer certificate before it expires, or let cert-manager renew it automatically")
+		}
+
+		for _, anchor := range anchors {
+			if issuer.CheckSignatureFrom(anchor) == nil {
+				report.IssuerSignedByTrustAnchor = true
+				break
+			}
+		}
+		if len(anchors) > 0 && !report.IssuerSignedByTrustAnchor {
+			addIssue(CertificateProblemChainMismatch, "error",
+				fmt.Sprintf("Issuer certificate %s is not signed by any trust anchor; proxies will reject the certificates it issues", info.Subject),
+				"Issue the issuer certificate from the trust anchor in the trust roots ConfigMap, or add its signing anchor to the bundle, then restart linkerd-identity")
+		}
+	}
+
+	report.Healthy = true
+	for _, issue := range report.Issues {
+		if issue.Severity == "error" {
+			report.Healthy = false
+		}
+	}
+	return report
+}
+
+// parseCertificates decodes the certificates of a PEM bundle, skipping other blocks and
+// certificates that don't parse
+func parseCertificates(data []byte) []*x509.Certificate {
+	certificates := []*x509.Certificate{}
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			return certificates
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		if certificate, err := x509.ParseCertificate(block.Bytes); err == nil {
+			certificates = append(certificates, certificate)
+		}
+	}
+}
+
+func certificateInfo(certificate *x509.Certificate, now time.Time, warnWithin time.Duration) CertificateInfo {
+	remaining := certificate.NotAfter.Sub(now)
+	return CertificateInfo{
+		Subject:       certificate.Subject.String(),
+		Issuer:        certificate.Issuer.String(),
+		NotBefore:     certificate.NotBefore,
+		NotAfter:      certificate.NotAfter,
+		DaysRemaining: int(math.Floor(remaining.Hours() / 24)),
+		Expired:       remaining <= 0,
+		Expiring:      remaining > 0 && remaining <= warnWithin,
+	}
+}
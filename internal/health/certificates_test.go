@@ -0,0 +1,197 @@
+package health_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/christianhuening/linkerd-mcp/internal/health"
+	"github.com/christianhuening/linkerd-mcp/internal/testutil"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// testCA is a CA certificate with its key, able to sign the next certificate of the chain
+type testCA struct {
+	certificate *x509.Certificate
+	key         *ecdsa.PrivateKey
+	pem         []byte
+}
+
+// newTestCA creates a CA certificate valid until notAfter, signed by parent or self-signed when
+// parent is nil
+func newTestCA(commonName string, notAfter time.Time, parent *testCA) testCA {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	Expect(err).NotTo(HaveOccurred())
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: commonName},
+		NotBefore:             notAfter.Add(-365 * 24 * time.Hour),
+		NotAfter:              notAfter,
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+	}
+	signer, signerKey := template, key
+	if parent != nil {
+		signer, signerKey = parent.certificate, parent.key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, signer, &key.PublicKey, signerKey)
+	Expect(err).NotTo(HaveOccurred())
+	certificate, err := x509.ParseCertificate(der)
+	Expect(err).NotTo(HaveOccurred())
+
+	return testCA{
+		certificate: certificate,
+		key:         key,
+		pem:         pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+	}
+}
+
+var _ = Describe("Certificates", func() {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	year := 365 * 24 * time.Hour
+
+	problems := func(report health.CertificateReport) []string {
+		found := []string{}
+		for _, issue := range report.Issues {
+			found = append(found, issue.Problem)
+		}
+		return found
+	}
+
+	Describe("BuildCertificateReport", func() {
+		It("should report a valid chain as healthy", func() {
+			anchor := newTestCA("root.linkerd.cluster.local", now.Add(10*year), nil)
+			issuer := newTestCA("identity.linkerd.cluster.local", now.Add(year), &anchor)
+
+			report := health.BuildCertificateReport(anchor.pem, issuer.pem, now, health.DefaultCertificateExpiryWarning)
+
+			Expect(report.Healthy).To(BeTrue())
+			Expect(report.Issues).To(BeEmpty())
+			Expect(report.IssuerSignedByTrustAnchor).To(BeTrue())
+			Expect(report.TrustAnchors).To(HaveLen(1))
+			Expect(report.TrustAnchors[0].Subject).To(Equal("CN=root.linkerd.cluster.local"))
+			Expect(report.Issuer.DaysRemaining).To(Equal(365))
+			Expect(report.Issuer.Expired).To(BeFalse())
+		})
+
+		It("should distinguish an expired issuer from an expired trust anchor", func() {
+			anchor := newTestCA("root.linkerd.cluster.local", now.Add(year), nil)
+			issuer := newTestCA("identity.linkerd.cluster.local", now.Add(-time.Hour), &anchor)
+
+			report := health.BuildCertificateReport(anchor.pem, issuer.pem, now, health.DefaultCertificateExpiryWarning)
+			Expect(report.Healthy).To(BeFalse())
+			Expect(problems(report)).To(Equal([]string{health.CertificateProblemIssuerExpired}))
+			Expect(report.Issuer.DaysRemaining).To(Equal(-1))
+
+			expiredAnchor := newTestCA("root.linkerd.cluster.local", now.Add(-time.Hour), nil)
+			validIssuer := newTestCA("identity.linkerd.cluster.local", now.Add(year), &expiredAnchor)
+
+			report = health.BuildCertificateReport(expiredAnchor.pem, validIssuer.pem, now, health.DefaultCertificateExpiryWarning)
+			Expect(report.Healthy).To(BeFalse())
+			Expect(problems(report)).To(Equal([]string{health.CertificateProblemTrustAnchorExpired}))
+		})
+
+		It("should report an issuer signed by another anchor as a chain mismatch", func() {
+			anchor := newTestCA("root.linkerd.cluster.local", now.Add(10*year), nil)
+			otherAnchor := newTestCA("other-root.linkerd.cluster.local", now.Add(10*year), nil)
+			issuer := newTestCA("identity.linkerd.cluster.local", now.Add(year), &otherAnchor)
+
+			report := health.BuildCertificateReport(anchor.pem, issuer.pem, now, health.DefaultCertificateExpiryWarning)
+
+			Expect(report.Healthy).To(BeFalse())
+			Expect(report.IssuerSignedByTrustAnchor).To(BeFalse())
+			Expect(problems(report)).To(Equal([]string{health.CertificateProblemChainMismatch}))
+			Expect(report.Issues[0].Remediation).NotTo(BeEmpty())
+		})
+
+		It("should accept an issuer signed by any anchor of a bundle", func() {
+			oldAnchor := newTestCA("old-root.linkerd.cluster.local", now.Add(year), nil)
+			newAnchor := newTestCA("new-root.linkerd.cluster.local", now.Add(10*year), nil)
+			issuer := newTestCA("identity.linkerd.cluster.local", now.Add(year), &newAnchor)
+
+			bundle := append(append([]byte{}, oldAnchor.pem...), newAnchor.pem...)
+			report := health.BuildCertificateReport(bundle, issuer.pem, now, health.DefaultCertificateExpiryWarning)
+
+			Expect(report.Healthy).To(BeTrue())
+			Expect(report.TrustAnchors).To(HaveLen(2))
+			Expect(report.IssuerSignedByTrustAnchor).To(BeTrue())
+		})
+
+		It("should warn about certificates expiring within the window without failing", func() {
+			anchor := newTestCA("root.linkerd.cluster.local", now.Add(10*year), nil)
+			issuer := newTestCA("identity.linkerd.cluster.local", now.Add(10*24*time.Hour), &anchor)
+
+			report := health.BuildCertificateReport(anchor.pem, issuer.pem, now, health.DefaultCertificateExpiryWarning)
+			Expect(report.Healthy).To(BeTrue())
+			Expect(problems(report)).To(Equal([]string{health.CertificateProblemIssuerExpiring}))
+			Expect(report.Issues[0].Severity).To(Equal("warning"))
+
+			report = health.BuildCertificateReport(anchor.pem, issuer.pem, now, 7*24*time.Hour)
+			Expect(report.Issues).To(BeEmpty())
+		})
+
+		It("should report missing certificates", func() {
+			report := health.BuildCertificateReport(nil, []byte("not a certificate"), now, health.DefaultCertificateExpiryWarning)
+
+			Expect(report.Healthy).To(BeFalse())
+			Expect(report.Issuer).To(BeNil())
+			Expect(problems(report)).To(Equal([]string{health.CertificateProblemTrustAnchorMissing, health.CertificateProblemIssuerMissing}))
+		})
+	})
+
+	Describe("CheckCertificates", func() {
+		It("should read the trust roots ConfigMap and the issuer Secret of the control plane", func() {
+			anchor := newTestCA("root.linkerd.cluster.local", time.Now().Add(10*year), nil)
+			issuer := newTestCA("identity.linkerd.cluster.local", time.Now().Add(year), &anchor)
+
+			clientset := fake.NewSimpleClientset(
+				&corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{Name: "linkerd-identity-trust-roots", Namespace: "linkerd-cp"},
+					Data:       map[string]string{"ca-bundle.crt": string(anchor.pem)},
+				},
+				&corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{Name: "linkerd-identity-issuer", Namespace: "linkerd-cp"},
+					Type:       corev1.SecretTypeTLS,
+					Data:       map[string][]byte{"tls.crt": issuer.pem},
+				},
+			)
+			checker := health.NewChecker(clientset)
+			checker.SetControlPlaneNamespace("linkerd-cp")
+
+			result, err := checker.CheckCertificates(context.Background(), 0)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.IsError).To(BeFalse())
+
+			var report health.CertificateReport
+			Expect(testutil.ParseJSONResult(result, &report)).To(Succeed())
+			Expect(report.Namespace).To(Equal("linkerd-cp"))
+			Expect(report.Healthy).To(BeTrue())
+			Expect(report.IssuerSignedByTrustAnchor).To(BeTrue())
+		})
+
+		It("should report a control plane without certificates", func() {
+			result, err := health.NewChecker(fake.NewSimpleClientset()).CheckCertificates(context.Background(), 0)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.IsError).To(BeFalse())
+
+			var report health.CertificateReport
+			Expect(testutil.ParseJSONResult(result, &report)).To(Succeed())
+			Expect(report.Healthy).To(BeFalse())
+			Expect(problems(report)).To(ContainElement(health.CertificateProblemTrustAnchorMissing))
+		})
+	})
+})
@@ -4,28 +4,51 @@ import (
 	"context"
 	"encoding/json"
 
+	"github.com/christianhuening/linkerd-mcp/internal/kube"
 	"github.com/mark3labs/mcp-go/mcp"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 )
 
+// DefaultRestartThreshold is the number of container restarts above which a
+// running component is reported as degraded
+const DefaultRestartThreshold = 5
+
 // Checker provides health checking functionality for Linkerd mesh
 type Checker struct {
-	clientset kubernetes.Interface
+	clientset             kubernetes.Interface
+	restartThreshold      int32
+	controlPlaneNamespace string
+	namespaceFilter       *kube.NamespaceFilter
 }
 
 // NewChecker creates a new health checker
 func NewChecker(clientset kubernetes.Interface) *Checker {
 	return &Checker{
-		clientset: clientset,
+		clientset:             clientset,
+		restartThreshold:      DefaultRestartThreshold,
+		controlPlaneNamespace: defaultControlPlaneNamespace,
 	}
 }
 
+// SetControlPlaneNamespace sets the namespace of the Linkerd control plane, used when a
+// health check isn't given one. Empty values are ignored.
+func (c *Checker) SetControlPlaneNamespace(namespace string) {
+	if namespace != "" {
+		c.controlPlaneNamespace = namespace
+	}
+}
+
+// SetNamespaceFilter restricts the namespaces scanned by cluster-wide data plane checks
+func (c *Checker) SetNamespaceFilter(filter *kube.NamespaceFilter) {
+	c.namespaceFilter = filter
+}
+
 // CheckMeshHealth checks the health status of the Linkerd service mesh
 func (c *Checker) CheckMeshHealth(ctx context.Context, namespace string) (*mcp.CallToolResult, error) {
 	if namespace == "" {
-		namespace = "linkerd"
+		namespace = c.controlPlaneNamespace
 	}
 
 	// Get Linkerd control plane pods
@@ -41,6 +64,7 @@ func (c *Checker) CheckMeshHealth(ctx context.Context, namespace string) (*mcp.C
 		"totalPods":     len(pods.Items),
 		"healthyPods":   0,
 		"unhealthyPods": 0,
+		"degradedPods":  0,
 		"components":    []map[string]interface{}{},
 	}
 
@@ -60,17 +84,37 @@ func (c *Checker) CheckMeshHealth(ctx context.Context, namespace string) (*mcp.C
 			}
 		}
 
+		restartCount, containers, lastTerminationReason := containerDetails(pod)
+
+		// A crash-looping pod can be Running between restarts, so flag it separately
+		degraded := restartCount > c.restartThreshold
+
 		if healthy {
 			healthStatus["healthyPods"] = healthStatus["healthyPods"].(int) + 1
 		} else {
 			healthStatus["unhealthyPods"] = healthStatus["unhealthyPods"].(int) + 1
 		}
+		if degraded {
+			healthStatus["degradedPods"] = healthStatus["degradedPods"].(int) + 1
+		}
 
 		componentInfo := map[string]interface{}{
-			"name":      pod.Name,
-			"component": component,
-			"healthy":   healthy,
-			"status":    status,
+			"name":         pod.Name,
+			"component":    component,
+			"healthy":      healthy,
+			"degraded":     degraded,
+			"status":       status,
+			"restartCount": restartCount,
+			"containers":   containers,
+		}
+		if lastTerminationReason != "" {
+			componentInfo["lastTerminationReason"] = lastTerminationReason
+		}
+		if reason, message := podStateReason(pod); reason != "" {
+			componentInfo["reason"] = reason
+			if message != "" {
+				componentInfo["message"] = message
+			}
 		}
 
 		healthStatus["components"] = append(healthStatus["components"].([]map[string]interface{}), componentInfo)
@@ -79,3 +123,75 @@ func (c *Checker) CheckMeshHealth(ctx context.Context, namespace string) (*mcp.C
 	result, _ := json.MarshalIndent(healthStatus, "", "  ")
 	return mcp.NewToolResultText(string(result)), nil
 }
+
+// containerDetails summarizes container statuses of a pod: the total restart count,
+// per-container readiness and the most recent termination reason, if any
+func containerDetails(pod corev1.Pod) (int32, []map[string]interface{}, string) {
+	var restartCount int32
+	containers := []map[string]interface{}{}
+	lastTerminationReason := ""
+	var lastFinishedAt metav1.Time
+
+	for _, cs := range pod.Status.ContainerStatuses {
+		restartCount += cs.RestartCount
+		containers = append(containers, map[string]interface{}{
+			"name":         cs.Name,
+			"ready":        cs.Ready,
+			"restartCount": cs.RestartCount,
+		})
+
+		if terminated := cs.LastTerminationState.Terminated; terminated != nil {
+			if lastTerminationReason == "" || lastFinishedAt.Before(&terminated.FinishedAt) {
+				lastTerminationReason = terminated.Reason
+				lastFinishedAt = terminated.FinishedAt
+			}
+		}
+	}
+
+	return restartCount, containers, lastTerminationReason
+}
+
+// containerStartingReasons are the waiting reasons of containers that are starting normally.
+// They explain a pod less than any other reason.
+var containerStartingReasons = map[string]bool{
+	"ContainerCreating": true,
+	"PodInitializing":   true,
+}
+
+// podStateReason returns the most relevant reason a pod isn't running, and its message: the
+// reason of the pod itself (e.g. Evicted), then the waiting or terminated state of a container
+// (e.g. ImagePullBackOff, CrashLoopBackOff), init containers first since they block the others,
+// then why the pod can't be scheduled, and finally that the node of a pod in the Unknown phase
+// stopped reporting. It is empty for a pod with nothing to report.
+func podStateReason(pod corev1.Pod) (string, string) {
+	if pod.Status.Reason != "" {
+		return pod.Status.Reason, pod.Status.Message
+	}
+
+	startingReason, startingMessage := "", ""
+	statuses := append(append([]corev1.ContainerStatus{}, pod.Status.InitContainerStatuses...), pod.Status.ContainerStatuses...)
+	for _, cs := range statuses {
+		if waiting := cs.State.Waiting; waiting != nil && waiting.Reason != "" {
+			if !containerStartingReasons[waiting.Reason] {
+				return waiting.Reason, waiting.Message
+			}
+			if startingReason == "" {
+				startingReason, startingMessage = waiting.Reason, waiting.Message
+			}
+		}
+		if terminated := cs.State.Terminated; terminated != nil && terminated.ExitCode != 0 {
+			return terminated.Reason, terminated.Message
+		}
+	}
+
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodScheduled && condition.Status == corev1.ConditionFalse && condition.Reason != "" {
+			return condition.Reason, condition.Message
+		}
+	}
+
+	if startingReason == "" && pod.Status.Phase == corev1.PodUnknown {
+		return string(corev1.PodUnknown), "The state of the pod could not be obtained, typically because its node is unreachable"
+	}
+	return startingReason, startingMessage
+}
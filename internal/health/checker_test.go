@@ -97,6 +97,110 @@ var _ = Describe("Checker", func() {
 			})
 		})
 
+		Context("when components are not running", func() {
+			BeforeEach(func() {
+				imagePull := testutil.CreateLinkerdControlPlanePod("identity-1", "linkerd", "identity", corev1.PodPending, false)
+				imagePull.Status.ContainerStatuses = []corev1.ContainerStatus{
+					{Name: "linkerd-proxy", State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "PodInitializing"}}},
+					{Name: "identity", State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{
+						Reason:  "ImagePullBackOff",
+						Message: "Back-off pulling image \"cr.l5d.io/linkerd/controller:edge\"",
+					}}},
+				}
+
+				evicted := testutil.CreateLinkerdControlPlanePod("destination-1", "linkerd", "destination", corev1.PodFailed, false)
+				evicted.Status.Reason = "Evicted"
+				evicted.Status.Message = "The node was low on resource: memory."
+
+				unschedulable := testutil.CreateLinkerdControlPlanePod("proxy-injector-1", "linkerd", "proxy-injector", corev1.PodPending, false)
+				unschedulable.Status.Conditions = append(unschedulable.Status.Conditions, corev1.PodCondition{
+					Type:    corev1.PodScheduled,
+					Status:  corev1.ConditionFalse,
+					Reason:  "Unschedulable",
+					Message: "0/3 nodes are available: 3 Insufficient cpu.",
+				})
+
+				clientset = fake.NewSimpleClientset(
+					imagePull, evicted, unschedulable,
+					testutil.CreateLinkerdControlPlanePod("sp-validator-1", "linkerd", "sp-validator", corev1.PodUnknown, false),
+					testutil.CreateLinkerdControlPlanePod("heartbeat-1", "linkerd", "heartbeat", corev1.PodRunning, true),
+				)
+				checker = health.NewChecker(clientset)
+			})
+
+			It("should report why each component isn't running", func() {
+				result, err := checker.CheckMeshHealth(ctx, "linkerd")
+				Expect(err).NotTo(HaveOccurred())
+
+				var healthStatus map[string]interface{}
+				Expect(testutil.ParseJSONResult(result, &healthStatus)).To(Succeed())
+
+				components := map[string]map[string]interface{}{}
+				for _, comp := range healthStatus["components"].([]interface{}) {
+					component := comp.(map[string]interface{})
+					components[component["name"].(string)] = component
+				}
+
+				Expect(components["identity-1"]["status"]).To(Equal("Pending"))
+				Expect(components["identity-1"]["reason"]).To(Equal("ImagePullBackOff"))
+				Expect(components["identity-1"]["message"]).To(ContainSubstring("cr.l5d.io"))
+				Expect(components["destination-1"]["reason"]).To(Equal("Evicted"))
+				Expect(components["destination-1"]["message"]).To(Equal("The node was low on resource: memory."))
+				Expect(components["proxy-injector-1"]["reason"]).To(Equal("Unschedulable"))
+				Expect(components["sp-validator-1"]["reason"]).To(Equal("Unknown"))
+				Expect(components["heartbeat-1"]).NotTo(HaveKey("reason"))
+				Expect(components["heartbeat-1"]).NotTo(HaveKey("message"))
+			})
+		})
+
+		Context("when a running component is crash looping", func() {
+			BeforeEach(func() {
+				pod := testutil.CreateLinkerdControlPlanePod("destination-1", "linkerd", "destination", corev1.PodRunning, true)
+				pod.Status.ContainerStatuses = []corev1.ContainerStatus{
+					{
+						Name:         "destination",
+						Ready:        true,
+						RestartCount: 7,
+						LastTerminationState: corev1.ContainerState{
+							Terminated: &corev1.ContainerStateTerminated{Reason: "OOMKilled"},
+						},
+					},
+					{
+						Name:         "linkerd-proxy",
+						Ready:        false,
+						RestartCount: 1,
+					},
+				}
+				clientset = fake.NewSimpleClientset(pod)
+				checker = health.NewChecker(clientset)
+			})
+
+			It("should report restarts, container readiness and flag the pod as degraded", func() {
+				result, err := checker.CheckMeshHealth(ctx, "linkerd")
+				Expect(err).NotTo(HaveOccurred())
+
+				var healthStatus map[string]interface{}
+				err = testutil.ParseJSONResult(result, &healthStatus)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(healthStatus["degradedPods"]).To(BeNumerically("==", 1))
+
+				components := healthStatus["components"].([]interface{})
+				Expect(components).To(HaveLen(1))
+
+				component := components[0].(map[string]interface{})
+				Expect(component["status"]).To(Equal("Running"))
+				Expect(component["degraded"]).To(BeTrue())
+				Expect(component["restartCount"]).To(BeNumerically("==", 8))
+				Expect(component["lastTerminationReason"]).To(Equal("OOMKilled"))
+
+				containers := component["containers"].([]interface{})
+				Expect(containers).To(HaveLen(2))
+				Expect(containers[0].(map[string]interface{})["ready"]).To(BeTrue())
+				Expect(containers[1].(map[string]interface{})["ready"]).To(BeFalse())
+			})
+		})
+
 		Context("when namespace is empty", func() {
 			BeforeEach(func() {
 				clientset = fake.NewSimpleClientset(
@@ -157,5 +261,27 @@ var _ = Describe("Checker", func() {
 				Expect(healthStatus["totalPods"]).To(BeNumerically("==", 1))
 			})
 		})
+
+		Context("with a configured control plane namespace", func() {
+			BeforeEach(func() {
+				clientset = fake.NewSimpleClientset(
+					testutil.CreateLinkerdControlPlanePod("destination-1", "linkerd-control-plane", "destination", corev1.PodRunning, true),
+				)
+				checker = health.NewChecker(clientset)
+				checker.SetControlPlaneNamespace("linkerd-control-plane")
+			})
+
+			It("should default to the configured namespace", func() {
+				result, err := checker.CheckMeshHealth(ctx, "")
+				Expect(err).NotTo(HaveOccurred())
+
+				var healthStatus map[string]interface{}
+				err = testutil.ParseJSONResult(result, &healthStatus)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(healthStatus["namespace"]).To(Equal("linkerd-control-plane"))
+				Expect(healthStatus["totalPods"]).To(BeNumerically("==", 1))
+			})
+		})
 	})
 })
@@ -0,0 +1,95 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+)
+
+const linkerdPolicyGroup = "policy.linkerd.io"
+
+// requiredCRDs lists the Linkerd policy resources queried by this server, together with
+// the API version the analyzers and validators use
+var requiredCRDs = []struct {
+	resource string
+	version  string
+}{
+	{resource: "servers", version: "v1beta3"},
+	{resource: "authorizationpolicies", version: "v1alpha1"},
+	{resource: "meshtlsauthentications", version: "v1alpha1"},
+	{resource: "networkauthentications", version: "v1alpha1"},
+	{resource: "httproutes", version: "v1alpha1"},
+}
+
+// CRDChecker verifies that the Linkerd CRDs are registered with the API server
+type CRDChecker struct {
+	discoveryClient discovery.DiscoveryInterface
+}
+
+// NewCRDChecker creates a new CRD checker
+func NewCRDChecker(discoveryClient discovery.DiscoveryInterface) *CRDChecker {
+	return &CRDChecker{
+		discoveryClient: discoveryClient,
+	}
+}
+
+// CheckCRDs reports which Linkerd policy CRDs are installed and which versions are served
+func (c *CRDChecker) CheckCRDs(ctx context.Context) (*mcp.CallToolResult, error) {
+	_, resourceLists, err := c.discoveryClient.ServerGroupsAndResources()
+	if err != nil && !discovery.IsGroupDiscoveryFailedError(err) {
+		return mcp.NewToolResultError("Failed to discover API resources: " + err.Error()), nil
+	}
+
+	// resource name -> served versions
+	served := map[string][]string{}
+	for _, list := range resourceLists {
+		gv, err := schema.ParseGroupVersion(list.GroupVersion)
+		if err != nil || gv.Group != linkerdPolicyGroup {
+			continue
+		}
+		for _, resource := range list.APIResources {
+			served[resource.Name] = append(served[resource.Name], gv.Version)
+		}
+	}
+
+	crds := []map[string]interface{}{}
+	missing := []string{}
+	for _, required := range requiredCRDs {
+		name := required.resource + "." + linkerdPolicyGroup
+		versions := served[required.resource]
+		sort.Strings(versions)
+
+		requiredVersionServed := false
+		for _, v := range versions {
+			if v == required.version {
+				requiredVersionServed = true
+				break
+			}
+		}
+
+		if len(versions) == 0 {
+			missing = append(missing, name)
+		}
+
+		crds = append(crds, map[string]interface{}{
+			"name":                  name,
+			"installed":             len(versions) > 0,
+			"servedVersions":        versions,
+			"requiredVersion":       required.version,
+			"requiredVersionServed": requiredVersionServed,
+		})
+	}
+
+	status := map[string]interface{}{
+		"allInstalled": len(missing) == 0,
+		"crds":         crds,
+		"missing":      missing,
+	}
+
+	result, _ := json.MarshalIndent(status, "", "  ")
+	return mcp.NewToolResultText(string(result)), nil
+}
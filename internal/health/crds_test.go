@@ -0,0 +1,104 @@
+package health_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/christianhuening/linkerd-mcp/internal/health"
+	"github.com/christianhuening/linkerd-mcp/internal/testutil"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+var _ = Describe("CRDChecker", func() {
+	var (
+		ctx       context.Context
+		discovery *fakediscovery.FakeDiscovery
+		checker   *health.CRDChecker
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		discovery = fake.NewSimpleClientset().Discovery().(*fakediscovery.FakeDiscovery)
+		checker = health.NewCRDChecker(discovery)
+	})
+
+	Context("when all policy CRDs are installed", func() {
+		BeforeEach(func() {
+			discovery.Resources = []*metav1.APIResourceList{
+				{
+					GroupVersion: "policy.linkerd.io/v1beta1",
+					APIResources: []metav1.APIResource{{Name: "servers"}, {Name: "httproutes"}},
+				},
+				{
+					GroupVersion: "policy.linkerd.io/v1beta3",
+					APIResources: []metav1.APIResource{{Name: "servers"}},
+				},
+				{
+					GroupVersion: "policy.linkerd.io/v1alpha1",
+					APIResources: []metav1.APIResource{
+						{Name: "authorizationpolicies"},
+						{Name: "meshtlsauthentications"},
+						{Name: "networkauthentications"},
+						{Name: "httproutes"},
+					},
+				},
+			}
+		})
+
+		It("should report every CRD with its served versions", func() {
+			result, err := checker.CheckCRDs(ctx)
+			Expect(err).NotTo(HaveOccurred())
+
+			var status map[string]interface{}
+			err = testutil.ParseJSONResult(result, &status)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(status["allInstalled"]).To(BeTrue())
+			Expect(status["missing"]).To(BeEmpty())
+
+			crds := status["crds"].([]interface{})
+			Expect(crds).To(HaveLen(5))
+
+			servers := crds[0].(map[string]interface{})
+			Expect(servers["name"]).To(Equal("servers.policy.linkerd.io"))
+			Expect(servers["servedVersions"]).To(ConsistOf("v1beta1", "v1beta3"))
+			Expect(servers["requiredVersionServed"]).To(BeTrue())
+		})
+	})
+
+	Context("when policy CRDs are missing", func() {
+		BeforeEach(func() {
+			discovery.Resources = []*metav1.APIResourceList{
+				{
+					GroupVersion: "policy.linkerd.io/v1beta1",
+					APIResources: []metav1.APIResource{{Name: "servers"}},
+				},
+			}
+		})
+
+		It("should list the missing CRDs and flag unserved versions", func() {
+			result, err := checker.CheckCRDs(ctx)
+			Expect(err).NotTo(HaveOccurred())
+
+			var status map[string]interface{}
+			err = testutil.ParseJSONResult(result, &status)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(status["allInstalled"]).To(BeFalse())
+			Expect(status["missing"]).To(ConsistOf(
+				"authorizationpolicies.policy.linkerd.io",
+				"meshtlsauthentications.policy.linkerd.io",
+				"networkauthentications.policy.linkerd.io",
+				"httproutes.policy.linkerd.io",
+			))
+
+			servers := status["crds"].([]interface{})[0].(map[string]interface{})
+			Expect(servers["installed"]).To(BeTrue())
+			Expect(servers["requiredVersionServed"]).To(BeFalse())
+		})
+	})
+})
@@ -0,0 +1,164 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/christianhuening/linkerd-mcp/internal/kube"
+	"github.com/mark3labs/mcp-go/mcp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	proxyVersionAnnotation       = "linkerd.io/proxy-version"
+	defaultControlPlaneNamespace = "linkerd"
+)
+
+// CheckDataPlaneHealth checks the health of Linkerd proxies injected into application pods, and
+// counts them by proxy version. An empty namespace scans the namespaces of the whole cluster the
+// namespace filter covers.
+func (c *Checker) CheckDataPlaneHealth(ctx context.Context, namespace string) (*mcp.CallToolResult, error) {
+	pods, err := c.clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return mcp.NewToolResultError("Failed to list pods: " + err.Error()), nil
+	}
+	if namespace == "" {
+		pods.Items = c.namespaceFilter.Pods(pods.Items)
+	}
+
+	controlPlaneVersion := c.controlPlaneVersion(ctx)
+
+	namespaces := map[string]map[string]int{}
+	proxyVersions := map[string]int{}
+	unhealthy := []map[string]interface{}{}
+	totalProxies := 0
+	healthyProxies := 0
+
+	for _, pod := range pods.Items {
+		if !hasProxyContainer(pod) {
+			continue
+		}
+		// Control plane proxies are covered by check_mesh_health
+		if _, ok := pod.Labels["linkerd.io/control-plane-component"]; ok {
+			continue
+		}
+
+		totalProxies++
+		if _, ok := namespaces[pod.Namespace]; !ok {
+			namespaces[pod.Namespace] = map[string]int{"healthy": 0, "unhealthy": 0}
+		}
+
+		reasons := []string{}
+		proxy := findProxyContainerStatus(pod)
+		ready := proxy != nil && proxy.Ready
+		var restartCount int32
+		if proxy != nil {
+			restartCount = proxy.RestartCount
+		}
+		version := proxyVersion(pod)
+		if version == "" {
+			proxyVersions["unknown"]++
+		} else {
+			proxyVersions[version]++
+		}
+
+		if !ready {
+			reasons = append(reasons, "proxy container is not ready")
+		}
+		if restartCount > c.restartThreshold {
+			reasons = append(reasons, fmt.Sprintf("proxy container restarted %d times", restartCount))
+		}
+		if controlPlaneVersion != "" && version != "" && !proxyVersionCompatible(version, controlPlaneVersion) {
+			reasons = append(reasons, fmt.Sprintf("proxy version %s does not match control plane version %s", version, controlPlaneVersion))
+		}
+
+		if len(reasons) == 0 {
+			healthyProxies++
+			namespaces[pod.Namespace]["healthy"]++
+			continue
+		}
+
+		namespaces[pod.Namespace]["unhealthy"]++
+		unhealthy = append(unhealthy, map[string]interface{}{
+			"name":         pod.Name,
+			"namespace":    pod.Namespace,
+			"proxyVersion": version,
+			"ready":        ready,
+			"restartCount": restartCount,
+			"reasons":      reasons,
+		})
+	}
+
+	healthStatus := map[string]interface{}{
+		"namespace":           namespace,
+		"controlPlaneVersion": controlPlaneVersion,
+		"totalProxies":        totalProxies,
+		"healthyProxies":      healthyProxies,
+		"unhealthyProxies":    totalProxies - healthyProxies,
+		"namespaces":          namespaces,
+		"proxyVersions":       proxyVersions,
+		"issues":              unhealthy,
+	}
+
+	result, _ := json.MarshalIndent(healthStatus, "", "  ")
+	return mcp.NewToolResultText(string(result)), nil
+}
+
+// controlPlaneVersion returns the proxy version of the first meshed control plane pod,
+// or an empty string when the control plane version cannot be determined
+func (c *Checker) controlPlaneVersion(ctx context.Context) string {
+	pods, err := c.clientset.CoreV1().Pods(c.controlPlaneNamespace).List(ctx, metav1.ListOptions{
+		LabelSelector: "linkerd.io/control-plane-component",
+	})
+	if err != nil {
+		return ""
+	}
+
+	for _, pod := range pods.Items {
+		if version := proxyVersion(pod); version != "" {
+			return version
+		}
+	}
+	return ""
+}
+
+// hasProxyContainer reports whether the pod has the Linkerd proxy injected
+func hasProxyContainer(pod corev1.Pod) bool {
+	for _, container := range pod.Spec.Containers {
+		if kube.IsProxyContainer(container.Name) {
+			return true
+		}
+	}
+	return false
+}
+
+// findProxyContainerStatus returns the status of the proxy container, if reported
+func findProxyContainerStatus(pod corev1.Pod) *corev1.ContainerStatus {
+	for i := range pod.Status.ContainerStatuses {
+		if kube.IsProxyContainer(pod.Status.ContainerStatuses[i].Name) {
+			return &pod.Status.ContainerStatuses[i]
+		}
+	}
+	return nil
+}
+
+// proxyVersion returns the proxy version of a pod from the injector annotation,
+// falling back to the proxy image tag
+func proxyVersion(pod corev1.Pod) string {
+	if version := pod.Annotations[proxyVersionAnnotation]; version != "" {
+		return version
+	}
+
+	for _, container := range pod.Spec.Containers {
+		if !kube.IsProxyContainer(container.Name) {
+			continue
+		}
+		if idx := strings.LastIndex(container.Image, ":"); idx != -1 && !strings.Contains(container.Image[idx:], "/") {
+			return container.Image[idx+1:]
+		}
+	}
+	return ""
+}
@@ -0,0 +1,168 @@
+package health_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/christianhuening/linkerd-mcp/internal/health"
+	"github.com/christianhuening/linkerd-mcp/internal/kube"
+	"github.com/christianhuening/linkerd-mcp/internal/testutil"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func withProxyStatus(pod *corev1.Pod, ready bool, restarts int32) *corev1.Pod {
+	pod.Status.ContainerStatuses = []corev1.ContainerStatus{
+		{Name: "app", Ready: true},
+		{Name: "linkerd-proxy", Ready: ready, RestartCount: restarts},
+	}
+	return pod
+}
+
+var _ = Describe("CheckDataPlaneHealth", func() {
+	var (
+		ctx     context.Context
+		checker *health.Checker
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+
+		controlPlane := testutil.CreateLinkerdControlPlanePod("destination-1", "linkerd", "destination", corev1.PodRunning, true)
+		controlPlane.Spec.Containers = append(controlPlane.Spec.Containers, corev1.Container{
+			Name:  "linkerd-proxy",
+			Image: "cr.l5d.io/linkerd/proxy:stable-2.14.0",
+		})
+
+		outdated := testutil.CreateMeshedPod("api-1", "prod", "api")
+		outdated.Spec.Containers[1].Image = "cr.l5d.io/linkerd/proxy:stable-2.13.0"
+
+		clientset := fake.NewSimpleClientset(
+			controlPlane,
+			withProxyStatus(testutil.CreateMeshedPod("frontend-1", "prod", "frontend"), true, 0),
+			withProxyStatus(testutil.CreateMeshedPod("backend-1", "prod", "backend"), false, 0),
+			withProxyStatus(testutil.CreateMeshedPod("worker-1", "jobs", "worker"), true, 12),
+			withProxyStatus(outdated, true, 0),
+			testutil.CreatePod("plain-1", "prod", "default", map[string]string{"app": "plain"}, corev1.PodRunning, true),
+		)
+		checker = health.NewChecker(clientset)
+	})
+
+	It("should aggregate proxy health per namespace across the cluster", func() {
+		result, err := checker.CheckDataPlaneHealth(ctx, "")
+		Expect(err).NotTo(HaveOccurred())
+
+		var status map[string]interface{}
+		err = testutil.ParseJSONResult(result, &status)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(status["controlPlaneVersion"]).To(Equal("stable-2.14.0"))
+		Expect(status["totalProxies"]).To(BeNumerically("==", 4))
+		Expect(status["healthyProxies"]).To(BeNumerically("==", 1))
+		Expect(status["unhealthyProxies"]).To(BeNumerically("==", 3))
+
+		namespaces := status["namespaces"].(map[string]interface{})
+		prod := namespaces["prod"].(map[string]interface{})
+		Expect(prod["healthy"]).To(BeNumerically("==", 1))
+		Expect(prod["unhealthy"]).To(BeNumerically("==", 2))
+		jobs := namespaces["jobs"].(map[string]interface{})
+		Expect(jobs["unhealthy"]).To(BeNumerically("==", 1))
+		Expect(namespaces).NotTo(HaveKey("linkerd"))
+
+		Expect(status["proxyVersions"]).To(Equal(map[string]interface{}{
+			"stable-2.14.0": float64(3),
+			"stable-2.13.0": float64(1),
+		}))
+	})
+
+	It("should explain why each proxy is unhealthy", func() {
+		result, err := checker.CheckDataPlaneHealth(ctx, "")
+		Expect(err).NotTo(HaveOccurred())
+
+		var status map[string]interface{}
+		err = testutil.ParseJSONResult(result, &status)
+		Expect(err).NotTo(HaveOccurred())
+
+		reasonsByPod := map[string][]interface{}{}
+		for _, issue := range status["issues"].([]interface{}) {
+			entry := issue.(map[string]interface{})
+			reasonsByPod[entry["name"].(string)] = entry["reasons"].([]interface{})
+		}
+
+		Expect(reasonsByPod["backend-1"]).To(ContainElement("proxy container is not ready"))
+		Expect(reasonsByPod["worker-1"]).To(ContainElement("proxy container restarted 12 times"))
+		Expect(reasonsByPod["api-1"]).To(ContainElement(ContainSubstring("does not match control plane version")))
+		Expect(reasonsByPod).NotTo(HaveKey("frontend-1"))
+	})
+
+	It("should only flag proxies of another release than the control plane", func() {
+		controlPlane := testutil.CreateLinkerdControlPlanePod("destination-1", "linkerd", "destination", corev1.PodRunning, true)
+		controlPlane.Spec.Containers = append(controlPlane.Spec.Containers, corev1.Container{
+			Name:  "linkerd-proxy",
+			Image: "cr.l5d.io/linkerd/proxy:stable-2.14.1",
+		})
+		pods := []runtime.Object{controlPlane}
+		for name, version := range map[string]string{
+			"patch-1":  "stable-2.14.0",
+			"build-1":  "stable-2.14.1-rc.1",
+			"minor-1":  "stable-2.15.1",
+			"edge-1":   "edge-24.3.2",
+			"custom-1": "dev-a1b2c3",
+		} {
+			pod := testutil.CreateMeshedPod(name, "prod", "app")
+			pod.Spec.Containers[1].Image = "cr.l5d.io/linkerd/proxy:" + version
+			pods = append(pods, withProxyStatus(pod, true, 0))
+		}
+
+		result, err := health.NewChecker(fake.NewSimpleClientset(pods...)).CheckDataPlaneHealth(ctx, "prod")
+		Expect(err).NotTo(HaveOccurred())
+
+		var status map[string]interface{}
+		err = testutil.ParseJSONResult(result, &status)
+		Expect(err).NotTo(HaveOccurred())
+
+		flagged := []string{}
+		for _, issue := range status["issues"].([]interface{}) {
+			flagged = append(flagged, issue.(map[string]interface{})["name"].(string))
+		}
+		Expect(flagged).To(ConsistOf("minor-1", "edge-1", "custom-1"))
+	})
+
+	It("should leave out namespaces the namespace filter doesn't cover", func() {
+		filter, err := kube.NewNamespaceFilter(nil, []string{"jobs"})
+		Expect(err).NotTo(HaveOccurred())
+		checker.SetNamespaceFilter(filter)
+
+		result, err := checker.CheckDataPlaneHealth(ctx, "")
+		Expect(err).NotTo(HaveOccurred())
+
+		var status map[string]interface{}
+		err = testutil.ParseJSONResult(result, &status)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(status["totalProxies"]).To(BeNumerically("==", 3))
+		Expect(status["namespaces"]).NotTo(HaveKey("jobs"))
+
+		// A namespace given explicitly is scanned even when denied
+		result, err = checker.CheckDataPlaneHealth(ctx, "jobs")
+		Expect(err).NotTo(HaveOccurred())
+		err = testutil.ParseJSONResult(result, &status)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(status["totalProxies"]).To(BeNumerically("==", 1))
+	})
+
+	It("should restrict the scan to the given namespace", func() {
+		result, err := checker.CheckDataPlaneHealth(ctx, "jobs")
+		Expect(err).NotTo(HaveOccurred())
+
+		var status map[string]interface{}
+		err = testutil.ParseJSONResult(result, &status)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(status["namespace"]).To(Equal("jobs"))
+		Expect(status["totalProxies"]).To(BeNumerically("==", 1))
+	})
+})
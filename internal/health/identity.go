@@ -0,0 +1,217 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	identityServiceName = "linkerd-identity"
+	// proxyAdminPort serves the proxy's metrics, including its identity certificate expiry
+	proxyAdminPort = "4191"
+	// identityCertExpirationMetric is reported by a proxy once it holds an identity certificate
+	identityCertExpirationMetric = "identity_cert_expiration_timestamp_seconds"
+	// identityProxySampleLimit caps the number of proxies queried through the API server proxy
+	identityProxySampleLimit = 10
+)
+
+// identityProxyCheck is the certificate state reported by a sampled proxy's admin endpoint
+type identityProxyCheck struct {
+	Name             string     `json:"name"`
+	Namespace        string     `json:"namespace"`
+	CertificateValid bool       `json:"certificateValid"`
+	ExpiresAt        *time.Time `json:"expiresAt,omitempty"`
+	Error            string     `json:"error,omitempty"`
+}
+
+// CheckIdentityReachability checks that the linkerd-identity Service has ready endpoints, so that
+// proxies can obtain and renew their certificates, and reports the meshed pods likely unable to
+// obtain an identity. When checkProxies is set, the admin endpoint of one ready proxy per namespace
+// is queried for its certificate expiry. An empty namespace scans the whole cluster.
+func (c *Checker) CheckIdentityReachability(ctx context.Context, namespace string, checkProxies bool) (*mcp.CallToolResult, error) {
+	now := time.Now()
+	issues := []string{}
+
+	identity := map[string]interface{}{
+		"name":              identityServiceName,
+		"namespace":         c.controlPlaneNamespace,
+		"exists":            false,
+		"readyEndpoints":    0,
+		"notReadyEndpoints": 0,
+	}
+	readyEndpoints := 0
+
+	_, err := c.clientset.CoreV1().Services(c.controlPlaneNamespace).Get(ctx, identityServiceName, metav1.GetOptions{})
+	switch {
+	case errors.IsNotFound(err):
+		issues = append(issues, fmt.Sprintf("Service %s/%s not found", c.controlPlaneNamespace, identityServiceName))
+	case err != nil:
+		return mcp.NewToolResultError("Failed to get identity service: " + err.Error()), nil
+	default:
+		identity["exists"] = true
+		ready, notReady := c.identityEndpoints(ctx)
+		readyEndpoints = ready
+		identity["readyEndpoints"] = ready
+		identity["notReadyEndpoints"] = notReady
+		if ready == 0 {
+			issues = append(issues, fmt.Sprintf("Service %s/%s has no ready endpoints; proxies cannot obtain or renew certificates", c.controlPlaneNamespace, identityServiceName))
+		}
+	}
+
+	pods, err := c.clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return mcp.NewToolResultError("Failed to list pods: " + err.Error()), nil
+	}
+
+	meshedPods := 0
+	atRisk := map[string]map[string]interface{}{}
+	addReason := func(pod corev1.Pod, reason string) {
+		key := pod.Namespace + "/" + pod.Name
+		if _, ok := atRisk[key]; !ok {
+			atRisk[key] = map[string]interface{}{
+				"name":      pod.Name,
+				"namespace": pod.Namespace,
+				"reasons":   []string{},
+			}
+		}
+		atRisk[key]["reasons"] = append(atRisk[key]["reasons"].([]string), reason)
+	}
+
+	sampled := map[string]bool{}
+	samples := []corev1.Pod{}
+	for _, pod := range pods.Items {
+		if !hasProxyContainer(pod) {
+			continue
+		}
+		if _, ok := pod.Labels["linkerd.io/control-plane-component"]; ok {
+			continue
+		}
+		meshedPods++
+
+		if readyEndpoints == 0 {
+			addReason(pod, "identity service has no ready endpoints")
+		}
+		// The proxy only becomes ready once it holds a certificate
+		proxy := findProxyContainerStatus(pod)
+		if proxy == nil || !proxy.Ready {
+			addReason(pod, "proxy container is not ready; it may be waiting for an identity certificate")
+			continue
+		}
+
+		if !sampled[pod.Namespace] && len(samples) < identityProxySampleLimit {
+			sampled[pod.Namespace] = true
+			samples = append(samples, pod)
+		}
+	}
+
+	proxyChecks := []identityProxyCheck{}
+	if checkProxies {
+		for _, pod := range samples {
+			check := c.checkProxyIdentity(ctx, pod, now)
+			if !check.CertificateValid {
+				addReason(pod, "proxy does not report a valid identity certificate: "+check.Error)
+			}
+			proxyChecks = append(proxyChecks, check)
+		}
+	}
+
+	podsAtRisk := []map[string]interface{}{}
+	for _, pod := range atRisk {
+		podsAtRisk = append(podsAtRisk, pod)
+	}
+	sort.Slice(podsAtRisk, func(i, j int) bool {
+		return podsAtRisk[i]["namespace"].(string)+"/"+podsAtRisk[i]["name"].(string) <
+			podsAtRisk[j]["namespace"].(string)+"/"+podsAtRisk[j]["name"].(string)
+	})
+
+	status := map[string]interface{}{
+		"namespace":       namespace,
+		"identityService": identity,
+		"meshedPods":      meshedPods,
+		"podsAtRisk":      podsAtRisk,
+		"healthy":         len(issues) == 0 && len(podsAtRisk) == 0,
+		"issues":          issues,
+	}
+	if checkProxies {
+		status["proxyChecks"] = proxyChecks
+	}
+
+	result, _ := json.MarshalIndent(status, "", "  ")
+	return mcp.NewToolResultText(string(result)), nil
+}
+
+// identityEndpoints counts the ready and not ready addresses of the identity Service
+func (c *Checker) identityEndpoints(ctx context.Context) (int, int) {
+	endpoints, err := c.clientset.CoreV1().Endpoints(c.controlPlaneNamespace).Get(ctx, identityServiceName, metav1.GetOptions{})
+	if err != nil {
+		return 0, 0
+	}
+
+	ready, notReady := 0, 0
+	for _, subset := range endpoints.Subsets {
+		ready += len(subset.Addresses)
+		notReady += len(subset.NotReadyAddresses)
+	}
+	return ready, notReady
+}
+
+// checkProxyIdentity reads the certificate expiry from a proxy's admin metrics through the API
+// server pod proxy
+func (c *Checker) checkProxyIdentity(ctx context.Context, pod corev1.Pod, now time.Time) identityProxyCheck {
+	check := identityProxyCheck{Name: pod.Name, Namespace: pod.Namespace}
+
+	request := c.clientset.CoreV1().Pods(pod.Namespace).ProxyGet("http", pod.Name, proxyAdminPort, "/metrics", nil)
+	if request == nil {
+		check.Error = "proxy admin endpoint is not reachable"
+		return check
+	}
+	body, err := request.DoRaw(ctx)
+	if err != nil {
+		check.Error = fmt.Sprintf("failed to query proxy admin endpoint: %v", err)
+		return check
+	}
+
+	expiry, found := parseCertExpiration(string(body))
+	if !found {
+		check.Error = "proxy has not reported an identity certificate"
+		return check
+	}
+	check.ExpiresAt = &expiry
+	if !expiry.After(now) {
+		check.Error = fmt.Sprintf("identity certificate expired at %s", expiry.UTC().Format(time.RFC3339))
+		return check
+	}
+
+	check.CertificateValid = true
+	return check
+}
+
+// parseCertExpiration extracts the identity certificate expiry from proxy metrics in the
+// Prometheus text format. A zero value means the proxy has no certificate yet.
+func parseCertExpiration(metrics string) (time.Time, bool) {
+	for _, line := range strings.Split(metrics, "\n") {
+		if !strings.HasPrefix(line, identityCertExpirationMetric) {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		seconds, err := strconv.ParseFloat(fields[1], 64)
+		if err != nil || seconds <= 0 {
+			return time.Time{}, false
+		}
+		return time.Unix(int64(seconds), 0), true
+	}
+	return time.Time{}, false
+}
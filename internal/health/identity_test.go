@@ -0,0 +1,193 @@
+package health_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/christianhuening/linkerd-mcp/internal/health"
+	"github.com/christianhuening/linkerd-mcp/internal/testutil"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	restclient "k8s.io/client-go/rest"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// proxyResponse is a canned response from a proxy admin endpoint
+type proxyResponse struct {
+	body string
+}
+
+func (r proxyResponse) DoRaw(context.Context) ([]byte, error) {
+	return []byte(r.body), nil
+}
+
+func (r proxyResponse) Stream(context.Context) (io.ReadCloser, error) {
+	return io.NopCloser(strings.NewReader(r.body)), nil
+}
+
+func identityService(namespace string) *corev1.Service {
+	return &corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "linkerd-identity", Namespace: namespace}}
+}
+
+func identityEndpoints(namespace string, ready, notReady int) *corev1.Endpoints {
+	subset := corev1.EndpointSubset{}
+	for i := 0; i < ready; i++ {
+		subset.Addresses = append(subset.Addresses, corev1.EndpointAddress{IP: fmt.Sprintf("10.0.0.%d", i+1)})
+	}
+	for i := 0; i < notReady; i++ {
+		subset.NotReadyAddresses = append(subset.NotReadyAddresses, corev1.EndpointAddress{IP: fmt.Sprintf("10.0.1.%d", i+1)})
+	}
+	return &corev1.Endpoints{
+		ObjectMeta: metav1.ObjectMeta{Name: "linkerd-identity", Namespace: namespace},
+		Subsets:    []corev1.EndpointSubset{subset},
+	}
+}
+
+var _ = Describe("CheckIdentityReachability", func() {
+	var ctx context.Context
+
+	meshedPods := func() []runtime.Object {
+		return []runtime.Object{
+			withProxyStatus(testutil.CreateMeshedPod("frontend-1", "prod", "frontend"), true, 0),
+			withProxyStatus(testutil.CreateMeshedPod("backend-1", "prod", "backend"), false, 0),
+			withProxyStatus(testutil.CreateMeshedPod("worker-1", "jobs", "worker"), true, 0),
+		}
+	}
+
+	check := func(checker *health.Checker, namespace string, checkProxies bool) map[string]interface{} {
+		result, err := checker.CheckIdentityReachability(ctx, namespace, checkProxies)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.IsError).To(BeFalse())
+
+		var status map[string]interface{}
+		Expect(testutil.ParseJSONResult(result, &status)).To(Succeed())
+		return status
+	}
+
+	atRisk := func(status map[string]interface{}) map[string][]interface{} {
+		pods := map[string][]interface{}{}
+		for _, p := range status["podsAtRisk"].([]interface{}) {
+			pod := p.(map[string]interface{})
+			pods[pod["namespace"].(string)+"/"+pod["name"].(string)] = pod["reasons"].([]interface{})
+		}
+		return pods
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+	})
+
+	Context("when the identity service has ready endpoints", func() {
+		It("should report only pods whose proxy is not ready", func() {
+			objects := append(meshedPods(), identityService("linkerd"), identityEndpoints("linkerd", 2, 1))
+			checker := health.NewChecker(fake.NewSimpleClientset(objects...))
+
+			status := check(checker, "", false)
+
+			identity := status["identityService"].(map[string]interface{})
+			Expect(identity["exists"]).To(BeTrue())
+			Expect(identity["readyEndpoints"]).To(BeNumerically("==", 2))
+			Expect(identity["notReadyEndpoints"]).To(BeNumerically("==", 1))
+			Expect(status["meshedPods"]).To(BeNumerically("==", 3))
+			Expect(status["issues"]).To(BeEmpty())
+			Expect(status).NotTo(HaveKey("proxyChecks"))
+
+			pods := atRisk(status)
+			Expect(pods).To(HaveLen(1))
+			Expect(pods).To(HaveKey("prod/backend-1"))
+			Expect(status["healthy"]).To(BeFalse())
+		})
+
+		It("should use the configured control plane namespace", func() {
+			objects := []runtime.Object{
+				withProxyStatus(testutil.CreateMeshedPod("frontend-1", "prod", "frontend"), true, 0),
+				identityService("linkerd-control-plane"),
+				identityEndpoints("linkerd-control-plane", 1, 0),
+			}
+			checker := health.NewChecker(fake.NewSimpleClientset(objects...))
+			checker.SetControlPlaneNamespace("linkerd-control-plane")
+
+			status := check(checker, "prod", false)
+			Expect(status["healthy"]).To(BeTrue())
+		})
+	})
+
+	Context("when the identity service has no ready endpoints", func() {
+		It("should report every meshed pod at risk", func() {
+			objects := append(meshedPods(), identityService("linkerd"), identityEndpoints("linkerd", 0, 2))
+			checker := health.NewChecker(fake.NewSimpleClientset(objects...))
+
+			status := check(checker, "", false)
+
+			Expect(status["issues"]).To(ConsistOf(ContainSubstring("has no ready endpoints")))
+			pods := atRisk(status)
+			Expect(pods).To(HaveLen(3))
+			Expect(pods["prod/frontend-1"]).To(ConsistOf("identity service has no ready endpoints"))
+			Expect(pods["prod/backend-1"]).To(HaveLen(2))
+			Expect(status["healthy"]).To(BeFalse())
+		})
+
+		It("should report a missing identity service", func() {
+			checker := health.NewChecker(fake.NewSimpleClientset(meshedPods()...))
+
+			status := check(checker, "jobs", false)
+
+			Expect(status["identityService"].(map[string]interface{})["exists"]).To(BeFalse())
+			Expect(status["issues"]).To(ConsistOf(ContainSubstring("linkerd/linkerd-identity not found")))
+			Expect(atRisk(status)).To(HaveKey("jobs/worker-1"))
+		})
+	})
+
+	Context("when proxies are checked", func() {
+		var clientset *fake.Clientset
+
+		BeforeEach(func() {
+			objects := append(meshedPods(), identityService("linkerd"), identityEndpoints("linkerd", 1, 0))
+			clientset = fake.NewSimpleClientset(objects...)
+
+			valid := time.Now().Add(24 * time.Hour).Unix()
+			clientset.PrependProxyReactor("pods", func(action k8stesting.Action) (bool, restclient.ResponseWrapper, error) {
+				proxy := action.(k8stesting.ProxyGetAction)
+				Expect(proxy.GetPort()).To(Equal("4191"))
+				Expect(proxy.GetPath()).To(Equal("/metrics"))
+
+				switch proxy.GetName() {
+				case "frontend-1":
+					return true, proxyResponse{body: fmt.Sprintf("# HELP identity_cert_expiration_timestamp_seconds Time when this proxy's current mTLS identity certificate will expire.\n# TYPE identity_cert_expiration_timestamp_seconds gauge\nidentity_cert_expiration_timestamp_seconds %d\n", valid)}, nil
+				default:
+					return true, proxyResponse{body: "identity_cert_expiration_timestamp_seconds 0\n"}, nil
+				}
+			})
+		})
+
+		It("should sample one ready proxy per namespace and report its certificate", func() {
+			checker := health.NewChecker(clientset)
+
+			status := check(checker, "", true)
+
+			checks := map[string]map[string]interface{}{}
+			for _, c := range status["proxyChecks"].([]interface{}) {
+				proxyCheck := c.(map[string]interface{})
+				checks[proxyCheck["namespace"].(string)+"/"+proxyCheck["name"].(string)] = proxyCheck
+			}
+			Expect(checks).To(HaveLen(2))
+			Expect(checks["prod/frontend-1"]["certificateValid"]).To(BeTrue())
+			Expect(checks["prod/frontend-1"]).To(HaveKey("expiresAt"))
+			Expect(checks["jobs/worker-1"]["certificateValid"]).To(BeFalse())
+			Expect(checks["jobs/worker-1"]["error"]).To(ContainSubstring("has not reported an identity certificate"))
+
+			pods := atRisk(status)
+			Expect(pods).To(HaveKey("prod/backend-1"))
+			Expect(pods["jobs/worker-1"]).To(ConsistOf(ContainSubstring("does not report a valid identity certificate")))
+			Expect(pods).NotTo(HaveKey("prod/frontend-1"))
+		})
+	})
+})
@@ -0,0 +1,103 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/christianhuening/linkerd-mcp/internal/validation/validators"
+	"github.com/mark3labs/mcp-go/mcp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const injectAnnotation = "linkerd.io/inject"
+
+// AuditDeploymentInjection checks a deployment end-to-end for proxy injection: the effective
+// inject decision, whether its pods actually run the proxy, proxy version skew against the
+// control plane, and the validity of the pod template's proxy annotations
+func (c *Checker) AuditDeploymentInjection(ctx context.Context, namespace, deploymentName string) (*mcp.CallToolResult, error) {
+	deployment, err := c.clientset.AppsV1().Deployments(namespace).Get(ctx, deploymentName, metav1.GetOptions{})
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to get deployment %s/%s: %v", namespace, deploymentName, err)), nil
+	}
+
+	// Pod template annotations take precedence over the namespace annotation
+	injectValue := deployment.Spec.Template.Annotations[injectAnnotation]
+	injectSource := "podTemplate"
+	if injectValue == "" {
+		injectSource = "namespace"
+		ns, err := c.clientset.CoreV1().Namespaces().Get(ctx, namespace, metav1.GetOptions{})
+		if err == nil {
+			injectValue = ns.Annotations[injectAnnotation]
+		}
+	}
+	if injectValue == "" {
+		injectSource = "none"
+	}
+	shouldInject := injectValue == "enabled" || injectValue == "ingress"
+
+	issues := []string{}
+
+	annotationResult := validators.NewProxyValidator(c.clientset).ValidatePodTemplate(ctx, deployment)
+	for _, issue := range annotationResult.Issues {
+		if issue.Severity == validators.SeverityError {
+			issues = append(issues, fmt.Sprintf("invalid pod template annotation: %s", issue.Message))
+		}
+	}
+
+	selector, err := metav1.LabelSelectorAsSelector(deployment.Spec.Selector)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Invalid selector on deployment %s/%s: %v", namespace, deploymentName, err)), nil
+	}
+
+	pods, err := c.clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: selector.String(),
+	})
+	if err != nil {
+		return mcp.NewToolResultError("Failed to list pods: " + err.Error()), nil
+	}
+
+	controlPlaneVersion := c.controlPlaneVersion(ctx)
+
+	podReports := []map[string]interface{}{}
+	for _, pod := range pods.Items {
+		hasProxy := hasProxyContainer(pod)
+		version := proxyVersion(pod)
+		versionMatches := !hasProxy || controlPlaneVersion == "" || version == "" || proxyVersionCompatible(version, controlPlaneVersion)
+
+		if shouldInject && !hasProxy {
+			issues = append(issues, fmt.Sprintf("pod %s should be injected but has no proxy container", pod.Name))
+		}
+		if !shouldInject && hasProxy {
+			issues = append(issues, fmt.Sprintf("pod %s has a proxy container but injection is not enabled", pod.Name))
+		}
+		if !versionMatches {
+			issues = append(issues, fmt.Sprintf("pod %s proxy version %s does not match control plane version %s", pod.Name, version, controlPlaneVersion))
+		}
+
+		podReports = append(podReports, map[string]interface{}{
+			"name":           pod.Name,
+			"hasProxy":       hasProxy,
+			"proxyVersion":   version,
+			"versionMatches": versionMatches,
+		})
+	}
+
+	audit := map[string]interface{}{
+		"deployment": deploymentName,
+		"namespace":  namespace,
+		"injectDecision": map[string]interface{}{
+			"inject": shouldInject,
+			"value":  injectValue,
+			"source": injectSource,
+		},
+		"controlPlaneVersion":  controlPlaneVersion,
+		"pods":                 podReports,
+		"annotationValidation": annotationResult,
+		"correctlyInjected":    len(issues) == 0,
+		"issues":               issues,
+	}
+
+	result, _ := json.MarshalIndent(audit, "", "  ")
+	return mcp.NewToolResultText(string(result)), nil
+}
@@ -0,0 +1,96 @@
+package health_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/christianhuening/linkerd-mcp/internal/health"
+	"github.com/christianhuening/linkerd-mcp/internal/testutil"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+var _ = Describe("AuditDeploymentInjection", func() {
+	var (
+		ctx     context.Context
+		checker *health.Checker
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+
+		controlPlane := testutil.CreateLinkerdControlPlanePod("destination-1", "linkerd", "destination", corev1.PodRunning, true)
+		controlPlane.Spec.Containers = append(controlPlane.Spec.Containers, corev1.Container{
+			Name:  "linkerd-proxy",
+			Image: "cr.l5d.io/linkerd/proxy:stable-2.14.0",
+		})
+
+		namespace := &corev1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "prod",
+				Annotations: map[string]string{"linkerd.io/inject": "enabled"},
+			},
+		}
+
+		outdated := testutil.CreateMeshedPod("worker-1", "prod", "worker")
+		outdated.Spec.Containers[1].Image = "cr.l5d.io/linkerd/proxy:stable-2.13.0"
+
+		clientset := fake.NewSimpleClientset(
+			controlPlane,
+			namespace,
+			testutil.CreateDeployment("frontend", "prod", map[string]string{"app": "frontend"}, nil),
+			testutil.CreateMeshedPod("frontend-1", "prod", "frontend"),
+			testutil.CreateDeployment("worker", "prod", map[string]string{"app": "worker"}, map[string]string{
+				"config.linkerd.io/proxy-log-level": "verbose",
+			}),
+			outdated,
+			testutil.CreatePod("worker-2", "prod", "default", map[string]string{"app": "worker"}, corev1.PodRunning, true),
+		)
+		checker = health.NewChecker(clientset)
+	})
+
+	It("should report a correctly injected deployment", func() {
+		result, err := checker.AuditDeploymentInjection(ctx, "prod", "frontend")
+		Expect(err).NotTo(HaveOccurred())
+
+		var audit map[string]interface{}
+		err = testutil.ParseJSONResult(result, &audit)
+		Expect(err).NotTo(HaveOccurred())
+
+		decision := audit["injectDecision"].(map[string]interface{})
+		Expect(decision["inject"]).To(BeTrue())
+		Expect(decision["source"]).To(Equal("namespace"))
+
+		Expect(audit["correctlyInjected"]).To(BeTrue())
+		Expect(audit["issues"]).To(BeEmpty())
+		Expect(audit["pods"]).To(HaveLen(1))
+	})
+
+	It("should report every problem with an incorrectly injected deployment", func() {
+		result, err := checker.AuditDeploymentInjection(ctx, "prod", "worker")
+		Expect(err).NotTo(HaveOccurred())
+
+		var audit map[string]interface{}
+		err = testutil.ParseJSONResult(result, &audit)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(audit["correctlyInjected"]).To(BeFalse())
+		Expect(audit["issues"]).To(ContainElements(
+			ContainSubstring("worker-2 should be injected but has no proxy container"),
+			ContainSubstring("does not match control plane version stable-2.14.0"),
+			ContainSubstring("invalid pod template annotation"),
+		))
+
+		annotationValidation := audit["annotationValidation"].(map[string]interface{})
+		Expect(annotationValidation["valid"]).To(BeFalse())
+	})
+
+	It("should return an error for an unknown deployment", func() {
+		result, err := checker.AuditDeploymentInjection(ctx, "prod", "missing")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.IsError).To(BeTrue())
+	})
+})
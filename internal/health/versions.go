@@ -0,0 +1,225 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/christianhuening/linkerd-mcp/internal/kube"
+	"github.com/mark3labs/mcp-go/mcp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// proxyVersionPattern matches Linkerd release versions such as stable-2.14.1 and edge-24.3.2
+var proxyVersionPattern = regexp.MustCompile(`^(stable|edge)-(\d+)\.(\d+)\.(\d+)$`)
+
+// ProxyVersion is a Linkerd release version. Stable and edge releases are separate tracks,
+// numbered independently, so only versions of the same channel can be compared.
+type ProxyVersion struct {
+	Channel string
+	Major   int
+	Minor   int
+	Patch   int
+}
+
+// ParseProxyVersion parses a version of the form <channel>-<major>.<minor>.<patch>, where the
+// channel is stable or edge
+func ParseProxyVersion(version string) (ProxyVersion, error) {
+	match := proxyVersionPattern.FindStringSubmatch(version)
+	if match == nil {
+		return ProxyVersion{}, fmt.Errorf("version %q is not of the form stable-X.Y.Z or edge-X.Y.Z", version)
+	}
+	major, _ := strconv.Atoi(match[2])
+	minor, _ := strconv.Atoi(match[3])
+	patch, _ := strconv.Atoi(match[4])
+	return ProxyVersion{Channel: match[1], Major: major, Minor: minor, Patch: patch}, nil
+}
+
+// Compare returns -1, 0 or 1 when v is older than, equal to or newer than other. ok is false
+// when the versions are of different channels and can't be compared.
+func (v ProxyVersion) Compare(other ProxyVersion) (result int, ok bool) {
+	if v.Channel != other.Channel {
+		return 0, false
+	}
+	for _, diff := range []int{v.Major - other.Major, v.Minor - other.Minor, v.Patch - other.Patch} {
+		if diff < 0 {
+			return -1, true
+		}
+		if diff > 0 {
+			return 1, true
+		}
+	}
+	return 0, true
+}
+
+func (v ProxyVersion) String() string {
+	return fmt.Sprintf("%s-%d.%d.%d", v.Channel, v.Major, v.Minor, v.Patch)
+}
+
+// proxyReleasePattern matches a Linkerd release version followed by an optional build suffix,
+// such as stable-2.14.1-rc.1 or edge-24.3.2+3a5b1c
+var proxyReleasePattern = regexp.MustCompile(`^((?:stable|edge)-\d+\.\d+\.\d+)(?:[-+].*)?$`)
+
+// proxyVersionCompatible reports whether a proxy of the given version is compatible with a
+// control plane of controlPlaneVersion. A stable proxy only has to run the control plane's
+// major.minor release and an edge proxy its edge release, so patch and build suffix drift is
+// tolerated. Versions that aren't Linkerd releases have to match exactly.
+func proxyVersionCompatible(version, controlPlaneVersion string) bool {
+	proxy := proxyReleasePattern.FindStringSubmatch(version)
+	controlPlane := proxyReleasePattern.FindStringSubmatch(controlPlaneVersion)
+	if proxy == nil || controlPlane == nil {
+		return version == controlPlaneVersion
+	}
+	proxyRelease, _ := ParseProxyVersion(proxy[1])
+	controlPlaneRelease, _ := ParseProxyVersion(controlPlane[1])
+	if proxyRelease.Channel != controlPlaneRelease.Channel ||
+		proxyRelease.Major != controlPlaneRelease.Major ||
+		proxyRelease.Minor != controlPlaneRelease.Minor {
+		return false
+	}
+	return proxyRelease.Channel == "stable" || proxyRelease.Patch == controlPlaneRelease.Patch
+}
+
+// workloadVersions collects the proxy versions of the pods of a workload
+type workloadVersions struct {
+	namespace string
+	kind      string
+	name      string
+	pods      int
+	versions  map[string]int
+}
+
+// CheckVersionCompliance reports the meshed workloads whose proxies run a version older than
+// minVersion, grouped by namespace. Workloads whose proxy version is unknown, or of another
+// channel than minVersion, can't be checked and are reported as unverified. An empty namespace
+// checks the namespaces of the whole cluster the namespace filter covers.
+func (c *Checker) CheckVersionCompliance(ctx context.Context, minVersion, namespace string) (*mcp.CallToolResult, error) {
+	minimum, err := ParseProxyVersion(minVersion)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Invalid minimum version: %v", err)), nil
+	}
+
+	pods, err := kube.ListPods(ctx, c.clientset, namespace, metav1.ListOptions{})
+	if err != nil {
+		return mcp.NewToolResultError("Failed to list pods: " + err.Error()), nil
+	}
+	if namespace == "" {
+		pods.Items = c.namespaceFilter.Pods(pods.Items)
+	}
+
+	workloads := map[string]*workloadVersions{}
+	for _, pod := range pods.Items {
+		if !hasProxyContainer(pod) {
+			continue
+		}
+		// Control plane proxies are upgraded with the control plane
+		if _, ok := pod.Labels["linkerd.io/control-plane-component"]; ok {
+			continue
+		}
+
+		kind, name := podWorkload(pod)
+		key := pod.Namespace + "/" + kind + "/" + name
+		workload, ok := workloads[key]
+		if !ok {
+			workload = &workloadVersions{namespace: pod.Namespace, kind: kind, name: name, versions: map[string]int{}}
+			workloads[key] = workload
+		}
+		workload.pods++
+		version := proxyVersion(pod)
+		if version == "" {
+			version = "unknown"
+		}
+		workload.versions[version]++
+	}
+
+	keys := make([]string, 0, len(workloads))
+	for key := range workloads {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	namespaces := map[string]map[string]int{}
+	nonCompliant := []map[string]interface{}{}
+	unverified := []map[string]interface{}{}
+	compliantWorkloads := 0
+	for _, key := range keys {
+		workload := workloads[key]
+		if _, ok := namespaces[workload.namespace]; !ok {
+			namespaces[workload.namespace] = map[string]int{"compliant": 0, "nonCompliant": 0, "unverified": 0}
+		}
+
+		outdated, unchecked := []string{}, []string{}
+		for version := range workload.versions {
+			parsed, err := ParseProxyVersion(version)
+			if err != nil {
+				unchecked = append(unchecked, version)
+				continue
+			}
+			if cmp, ok := parsed.Compare(minimum); !ok {
+				unchecked = append(unchecked, version)
+			} else if cmp < 0 {
+				outdated = append(outdated, version)
+			}
+		}
+		sort.Strings(outdated)
+		sort.Strings(unchecked)
+
+		entry := map[string]interface{}{
+			"namespace":     workload.namespace,
+			"kind":          workload.kind,
+			"name":          workload.name,
+			"pods":          workload.pods,
+			"proxyVersions": workload.versions,
+		}
+		switch {
+		case len(outdated) > 0:
+			entry["outdatedVersions"] = outdated
+			nonCompliant = append(nonCompliant, entry)
+			namespaces[workload.namespace]["nonCompliant"]++
+		case len(unchecked) > 0:
+			entry["reason"] = fmt.Sprintf("proxy version %s can't be compared with %s", strings.Join(unchecked, ", "), minimum)
+			unverified = append(unverified, entry)
+			namespaces[workload.namespace]["unverified"]++
+		default:
+			compliantWorkloads++
+			namespaces[workload.namespace]["compliant"]++
+		}
+	}
+
+	compliance := map[string]interface{}{
+		"compliant":             len(nonCompliant) == 0,
+		"minimumVersion":        minimum.String(),
+		"namespace":             namespace,
+		"totalWorkloads":        len(workloads),
+		"compliantWorkloads":    compliantWorkloads,
+		"nonCompliantWorkloads": len(nonCompliant),
+		"unverifiedWorkloads":   len(unverified),
+		"namespaces":            namespaces,
+		"nonCompliant":          nonCompliant,
+		"unverified":            unverified,
+	}
+
+	result, _ := json.MarshalIndent(compliance, "", "  ")
+	return mcp.NewToolResultText(string(result)), nil
+}
+
+// podWorkload returns the kind and name of the workload a pod belongs to. Pods of a Deployment
+// are owned by one of its ReplicaSets, named after it with the pod template hash appended. Pods
+// without an owner are their own workload.
+func podWorkload(pod corev1.Pod) (kind, name string) {
+	owner := metav1.GetControllerOf(&pod)
+	if owner == nil {
+		return "Pod", pod.Name
+	}
+	if hash := pod.Labels["pod-template-hash"]; owner.Kind == "ReplicaSet" && hash != "" {
+		if deployment, found := strings.CutSuffix(owner.Name, "-"+hash); found {
+			return "Deployment", deployment
+		}
+	}
+	return owner.Kind, owner.Name
+}
@@ -0,0 +1,137 @@
+package health_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/christianhuening/linkerd-mcp/internal/health"
+	"github.com/christianhuening/linkerd-mcp/internal/testutil"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// ownedPod creates a meshed pod of a Deployment's ReplicaSet running the given proxy version
+func ownedPod(name, namespace, deployment, version string) *corev1.Pod {
+	pod := testutil.CreateMeshedPod(name, namespace, deployment)
+	pod.Labels["pod-template-hash"] = "5d8f7c"
+	controller := true
+	pod.OwnerReferences = []metav1.OwnerReference{{
+		APIVersion: "apps/v1",
+		Kind:       "ReplicaSet",
+		Name:       deployment + "-5d8f7c",
+		Controller: &controller,
+	}}
+	pod.Spec.Containers[1].Image = "cr.l5d.io/linkerd/proxy:" + version
+	return pod
+}
+
+var _ = Describe("ParseProxyVersion", func() {
+	It("should parse stable and edge versions", func() {
+		version, err := health.ParseProxyVersion("stable-2.14.1")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(version).To(Equal(health.ProxyVersion{Channel: "stable", Major: 2, Minor: 14, Patch: 1}))
+
+		version, err = health.ParseProxyVersion("edge-24.3.2")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(version.String()).To(Equal("edge-24.3.2"))
+	})
+
+	It("should reject other formats", func() {
+		_, err := health.ParseProxyVersion("v2.14.1")
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should compare versions numerically within a channel", func() {
+		older, _ := health.ParseProxyVersion("stable-2.9.10")
+		newer, _ := health.ParseProxyVersion("stable-2.14.1")
+
+		cmp, ok := older.Compare(newer)
+		Expect(ok).To(BeTrue())
+		Expect(cmp).To(Equal(-1))
+
+		cmp, ok = newer.Compare(newer)
+		Expect(ok).To(BeTrue())
+		Expect(cmp).To(Equal(0))
+	})
+
+	It("should not compare versions of different channels", func() {
+		stable, _ := health.ParseProxyVersion("stable-2.14.1")
+		edge, _ := health.ParseProxyVersion("edge-24.3.2")
+
+		_, ok := edge.Compare(stable)
+		Expect(ok).To(BeFalse())
+	})
+})
+
+var _ = Describe("CheckVersionCompliance", func() {
+	var (
+		ctx     context.Context
+		checker *health.Checker
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+
+		clientset := fake.NewSimpleClientset(
+			ownedPod("api-5d8f7c-a", "prod", "api", "stable-2.14.1"),
+			ownedPod("api-5d8f7c-b", "prod", "api", "stable-2.13.4"),
+			ownedPod("web-5d8f7c-a", "prod", "web", "stable-2.14.2"),
+			ownedPod("worker-5d8f7c-a", "jobs", "worker", "edge-24.3.2"),
+			testutil.CreateMeshedPod("debug", "jobs", "debug"),
+			testutil.CreateLinkerdControlPlanePod("destination-1", "linkerd", "destination", corev1.PodRunning, true),
+		)
+		checker = health.NewChecker(clientset)
+	})
+
+	It("should report workloads running an older proxy, grouped by namespace", func() {
+		result, err := checker.CheckVersionCompliance(ctx, "stable-2.14.1", "")
+		Expect(err).NotTo(HaveOccurred())
+
+		var compliance map[string]interface{}
+		Expect(testutil.ParseJSONResult(result, &compliance)).To(Succeed())
+
+		Expect(compliance["compliant"]).To(BeFalse())
+		Expect(compliance["totalWorkloads"]).To(BeNumerically("==", 4))
+		Expect(compliance["compliantWorkloads"]).To(BeNumerically("==", 1))
+		Expect(compliance["nonCompliantWorkloads"]).To(BeNumerically("==", 2))
+		Expect(compliance["unverifiedWorkloads"]).To(BeNumerically("==", 1))
+
+		nonCompliant := compliance["nonCompliant"].([]interface{})
+		Expect(nonCompliant).To(HaveLen(2))
+		api := nonCompliant[1].(map[string]interface{})
+		Expect(api["kind"]).To(Equal("Deployment"))
+		Expect(api["name"]).To(Equal("api"))
+		Expect(api["pods"]).To(BeNumerically("==", 2))
+		Expect(api["outdatedVersions"]).To(ConsistOf("stable-2.13.4"))
+		debug := nonCompliant[0].(map[string]interface{})
+		Expect(debug["kind"]).To(Equal("Pod"))
+		Expect(debug["outdatedVersions"]).To(ConsistOf("stable-2.14.0"))
+
+		unverified := compliance["unverified"].([]interface{})
+		Expect(unverified[0]).To(HaveKeyWithValue("name", "worker"))
+
+		namespaces := compliance["namespaces"].(map[string]interface{})
+		Expect(namespaces["prod"]).To(HaveKeyWithValue("nonCompliant", BeNumerically("==", 1)))
+		Expect(namespaces["prod"]).To(HaveKeyWithValue("compliant", BeNumerically("==", 1)))
+		Expect(namespaces).NotTo(HaveKey("linkerd"))
+	})
+
+	It("should be compliant when every workload of the namespace is up to date", func() {
+		result, err := checker.CheckVersionCompliance(ctx, "stable-2.13.0", "prod")
+		Expect(err).NotTo(HaveOccurred())
+
+		var compliance map[string]interface{}
+		Expect(testutil.ParseJSONResult(result, &compliance)).To(Succeed())
+		Expect(compliance["compliant"]).To(BeTrue())
+		Expect(compliance["compliantWorkloads"]).To(BeNumerically("==", 2))
+	})
+
+	It("should reject an invalid minimum version", func() {
+		result, err := checker.CheckVersionCompliance(ctx, "2.14", "")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.IsError).To(BeTrue())
+	})
+})
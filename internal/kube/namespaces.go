@@ -0,0 +1,134 @@
+package kube
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// DefaultNamespaceDenylist are the Kubernetes system namespaces left out of cluster-wide
+// operations unless a denylist is configured
+var DefaultNamespaceDenylist = []string{"kube-system", "kube-public", "kube-node-lease"}
+
+// NamespaceFilter restricts the namespaces covered by cluster-wide operations, such as listing
+// or validating resources of all namespaces. A namespace is covered when it matches the
+// allowlist, if there is one, and doesn't match the denylist. Operations on a namespace named
+// explicitly are never filtered. The nil filter covers every namespace.
+type NamespaceFilter struct {
+	allow []namespacePattern
+	deny  []namespacePattern
+}
+
+// namespacePattern matches a namespace by name or, when set, by regular expression
+type namespacePattern struct {
+	name   string
+	regexp *regexp.Regexp
+}
+
+func (p namespacePattern) matches(namespace string) bool {
+	if p.regexp != nil {
+		return p.regexp.MatchString(namespace)
+	}
+	return p.name == namespace
+}
+
+// NewNamespaceFilter builds a filter from allowlist and denylist entries. An entry is a namespace
+// name or, between slashes, a regular expression matched against the name, such as /^team-/.
+// Empty entries are ignored.
+func NewNamespaceFilter(allow, deny []string) (*NamespaceFilter, error) {
+	filter := &NamespaceFilter{}
+	var err error
+	if filter.allow, err = parseNamespacePatterns(allow); err != nil {
+		return nil, err
+	}
+	if filter.deny, err = parseNamespacePatterns(deny); err != nil {
+		return nil, err
+	}
+	return filter, nil
+}
+
+func parseNamespacePatterns(entries []string) ([]namespacePattern, error) {
+	patterns := []namespacePattern{}
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if len(entry) > 2 && strings.HasPrefix(entry, "/") && strings.HasSuffix(entry, "/") {
+			re, err := regexp.Compile(entry[1 : len(entry)-1])
+			if err != nil {
+				return nil, fmt.Errorf("invalid namespace pattern %s: %w", entry, err)
+			}
+			patterns = append(patterns, namespacePattern{regexp: re})
+			continue
+		}
+		patterns = append(patterns, namespacePattern{name: entry})
+	}
+	return patterns, nil
+}
+
+// Allows reports whether a namespace is covered by cluster-wide operations
+func (f *NamespaceFilter) Allows(namespace string) bool {
+	if f == nil {
+		return true
+	}
+	for _, pattern := range f.deny {
+		if pattern.matches(namespace) {
+			return false
+		}
+	}
+	if len(f.allow) == 0 {
+		return true
+	}
+	for _, pattern := range f.allow {
+		if pattern.matches(namespace) {
+			return true
+		}
+	}
+	return false
+}
+
+// Namespaces returns the namespaces the filter covers
+func (f *NamespaceFilter) Namespaces(namespaces []corev1.Namespace) []corev1.Namespace {
+	if f == nil {
+		return namespaces
+	}
+	allowed := []corev1.Namespace{}
+	for _, ns := range namespaces {
+		if f.Allows(ns.Name) {
+			allowed = append(allowed, ns)
+		}
+	}
+	return allowed
+}
+
+// Pods returns the pods of the namespaces the filter covers
+func (f *NamespaceFilter) Pods(pods []corev1.Pod) []corev1.Pod {
+	if f == nil {
+		return pods
+	}
+	allowed := []corev1.Pod{}
+	for _, pod := range pods {
+		if f.Allows(pod.Namespace) {
+			allowed = append(allowed, pod)
+		}
+	}
+	return allowed
+}
+
+// Unstructured returns the resources of the namespaces the filter covers
+func (f *NamespaceFilter) Unstructured(items []unstructured.Unstructured) []unstructured.Unstructured {
+	if f == nil {
+		return items
+	}
+	allowed := []unstructured.Unstructured{}
+	for _, item := range items {
+		if f.Allows(item.GetNamespace()) {
+			allowed = append(allowed, item)
+		}
+	}
+	return allowed
+}
@@ -0,0 +1,80 @@
+package kube_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/christianhuening/linkerd-mcp/internal/kube"
+	"github.com/christianhuening/linkerd-mcp/internal/testutil"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("NamespaceFilter", func() {
+	It("should allow every namespace without a filter", func() {
+		var filter *kube.NamespaceFilter
+		Expect(filter.Allows("kube-system")).To(BeTrue())
+
+		pods := []corev1.Pod{*testutil.CreateMeshedPod("api-1", "prod", "api")}
+		Expect(filter.Pods(pods)).To(Equal(pods))
+	})
+
+	It("should leave out denied namespaces", func() {
+		filter, err := kube.NewNamespaceFilter(nil, kube.DefaultNamespaceDenylist)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(filter.Allows("kube-system")).To(BeFalse())
+		Expect(filter.Allows("kube-node-lease")).To(BeFalse())
+		Expect(filter.Allows("prod")).To(BeTrue())
+	})
+
+	It("should only allow namespaces of the allowlist", func() {
+		filter, err := kube.NewNamespaceFilter([]string{"prod", " staging ", ""}, nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(filter.Allows("prod")).To(BeTrue())
+		Expect(filter.Allows("staging")).To(BeTrue())
+		Expect(filter.Allows("dev")).To(BeFalse())
+	})
+
+	It("should match entries between slashes as regular expressions", func() {
+		filter, err := kube.NewNamespaceFilter([]string{"/^team-/"}, []string{"/-sandbox$/"})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(filter.Allows("team-payments")).To(BeTrue())
+		Expect(filter.Allows("team-payments-sandbox")).To(BeFalse())
+		Expect(filter.Allows("payments")).To(BeFalse())
+	})
+
+	It("should let the denylist win over the allowlist", func() {
+		filter, err := kube.NewNamespaceFilter([]string{"prod", "kube-system"}, []string{"kube-system"})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(filter.Allows("prod")).To(BeTrue())
+		Expect(filter.Allows("kube-system")).To(BeFalse())
+	})
+
+	It("should reject invalid regular expressions", func() {
+		_, err := kube.NewNamespaceFilter([]string{"/team-(/"}, nil)
+		Expect(err).To(MatchError(ContainSubstring("invalid namespace pattern /team-(/")))
+	})
+
+	It("should filter namespaces and pods", func() {
+		filter, err := kube.NewNamespaceFilter(nil, []string{"kube-system"})
+		Expect(err).NotTo(HaveOccurred())
+
+		namespaces := filter.Namespaces([]corev1.Namespace{
+			{ObjectMeta: metav1.ObjectMeta{Name: "kube-system"}},
+			{ObjectMeta: metav1.ObjectMeta{Name: "prod"}},
+		})
+		Expect(namespaces).To(HaveLen(1))
+		Expect(namespaces[0].Name).To(Equal("prod"))
+
+		pods := filter.Pods([]corev1.Pod{
+			*testutil.CreatePod("coredns-1", "kube-system", "coredns", nil, corev1.PodRunning, true),
+			*testutil.CreateMeshedPod("api-1", "prod", "api"),
+		})
+		Expect(pods).To(HaveLen(1))
+		Expect(pods[0].Name).To(Equal("api-1"))
+	})
+})
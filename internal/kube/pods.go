@@ -0,0 +1,43 @@
+// Package kube holds Kubernetes API helpers shared by the packages that read cluster state
+package kube
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// PodPageSize is the number of pods requested per page when listing pods
+const PodPageSize = 500
+
+// ListPods lists the pods matching opts in a namespace, or in all namespaces when it is empty.
+// Pods are fetched PodPageSize at a time, so that the API server never has to return a large
+// cluster in a single response, and the pages are merged into one list. When the continue token
+// expires between pages, the listing restarts without pagination.
+func ListPods(ctx context.Context, clientset kubernetes.Interface, namespace string, opts metav1.ListOptions) (*corev1.PodList, error) {
+	opts.Limit = PodPageSize
+	opts.Continue = ""
+
+	pods := &corev1.PodList{}
+	for {
+		page, err := clientset.CoreV1().Pods(namespace).List(ctx, opts)
+		if errors.IsResourceExpired(err) {
+			opts.Limit = 0
+			opts.Continue = ""
+			return clientset.CoreV1().Pods(namespace).List(ctx, opts)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		pods.Items = append(pods.Items, page.Items...)
+		if page.Continue == "" {
+			pods.ResourceVersion = page.ResourceVersion
+			return pods, nil
+		}
+		opts.Continue = page.Continue
+	}
+}
@@ -0,0 +1,103 @@
+package kube_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/christianhuening/linkerd-mcp/internal/kube"
+	"github.com/christianhuening/linkerd-mcp/internal/testutil"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+var _ = Describe("ListPods", func() {
+	var (
+		ctx       context.Context
+		clientset *fake.Clientset
+		requests  []metav1.ListOptions
+	)
+
+	// pagedList serves the given pages in order, chaining them with continue tokens
+	pagedList := func(pages ...[]corev1.Pod) k8stesting.ReactionFunc {
+		return func(action k8stesting.Action) (bool, runtime.Object, error) {
+			opts := action.(k8stesting.ListActionImpl).GetListOptions()
+			requests = append(requests, opts)
+
+			index := 0
+			if opts.Continue != "" {
+				index = int(opts.Continue[0] - '0')
+			}
+			list := &corev1.PodList{Items: pages[index]}
+			if index+1 < len(pages) {
+				list.Continue = string(rune('0' + index + 1))
+			}
+			return true, list, nil
+		}
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		requests = nil
+		clientset = fake.NewSimpleClientset(
+			testutil.CreateMeshedPod("web-1", "prod", "web"),
+			testutil.CreateMeshedPod("web-2", "prod", "web"),
+			testutil.CreateMeshedPod("api-1", "prod", "api"),
+		)
+	})
+
+	It("should merge all pages into one list", func() {
+		clientset.PrependReactor("list", "pods", pagedList(
+			[]corev1.Pod{*testutil.CreateMeshedPod("web-1", "prod", "web"), *testutil.CreateMeshedPod("web-2", "prod", "web")},
+			[]corev1.Pod{*testutil.CreateMeshedPod("api-1", "prod", "api")},
+		))
+
+		pods, err := kube.ListPods(ctx, clientset, "prod", metav1.ListOptions{LabelSelector: "app"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(pods.Items).To(HaveLen(3))
+		Expect(pods.Items[2].Name).To(Equal("api-1"))
+
+		Expect(requests).To(HaveLen(2))
+		for _, opts := range requests {
+			Expect(opts.Limit).To(BeNumerically("==", kube.PodPageSize))
+			Expect(opts.LabelSelector).To(Equal("app"))
+		}
+		Expect(requests[1].Continue).To(Equal("1"))
+	})
+
+	It("should return a single page as is", func() {
+		pods, err := kube.ListPods(ctx, clientset, "", metav1.ListOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(pods.Items).To(HaveLen(3))
+	})
+
+	It("should restart without pagination when the continue token expires", func() {
+		clientset.PrependReactor("list", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+			opts := action.(k8stesting.ListActionImpl).GetListOptions()
+			requests = append(requests, opts)
+			switch {
+			case opts.Continue != "":
+				return true, nil, apierrors.NewResourceExpired("continue token expired")
+			case opts.Limit > 0:
+				return true, &corev1.PodList{
+					ListMeta: metav1.ListMeta{Continue: "1"},
+					Items:    []corev1.Pod{*testutil.CreateMeshedPod("web-1", "prod", "web")},
+				}, nil
+			default:
+				// The unpaginated retry is served by the object tracker
+				return false, nil, nil
+			}
+		})
+
+		pods, err := kube.ListPods(ctx, clientset, "prod", metav1.ListOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(pods.Items).To(HaveLen(3))
+		Expect(requests).To(HaveLen(3))
+		Expect(requests[2].Limit).To(BeZero())
+	})
+})
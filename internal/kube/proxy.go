@@ -0,0 +1,25 @@
+package kube
+
+// DefaultProxyContainerName is the name of the container the Linkerd proxy injector adds to pods
+const DefaultProxyContainerName = "linkerd-proxy"
+
+// proxyContainerName is the name of the proxy container every package looks for in pods
+var proxyContainerName = DefaultProxyContainerName
+
+// SetProxyContainerName sets the name of the proxy container, for installs that rename it. Empty
+// values are ignored. Call it before serving requests; it isn't safe to change concurrently.
+func SetProxyContainerName(name string) {
+	if name != "" {
+		proxyContainerName = name
+	}
+}
+
+// ProxyContainerName returns the name of the proxy container
+func ProxyContainerName() string {
+	return proxyContainerName
+}
+
+// IsProxyContainer reports whether a container, or container status, of that name is the proxy
+func IsProxyContainer(name string) bool {
+	return name == proxyContainerName
+}
@@ -0,0 +1,34 @@
+package kube_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/christianhuening/linkerd-mcp/internal/kube"
+)
+
+var _ = Describe("ProxyContainerName", func() {
+	AfterEach(func() {
+		kube.SetProxyContainerName(kube.DefaultProxyContainerName)
+	})
+
+	It("should default to linkerd-proxy", func() {
+		Expect(kube.ProxyContainerName()).To(Equal("linkerd-proxy"))
+		Expect(kube.IsProxyContainer("linkerd-proxy")).To(BeTrue())
+		Expect(kube.IsProxyContainer("app")).To(BeFalse())
+	})
+
+	It("should recognize a renamed proxy container", func() {
+		kube.SetProxyContainerName("mesh-proxy")
+
+		Expect(kube.ProxyContainerName()).To(Equal("mesh-proxy"))
+		Expect(kube.IsProxyContainer("mesh-proxy")).To(BeTrue())
+		Expect(kube.IsProxyContainer("linkerd-proxy")).To(BeFalse())
+	})
+
+	It("should ignore an empty name", func() {
+		kube.SetProxyContainerName("")
+
+		Expect(kube.ProxyContainerName()).To(Equal("linkerd-proxy"))
+	})
+})
@@ -0,0 +1,214 @@
+package mesh
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+var serviceProfileGVR = schema.GroupVersionResource{
+	Group:    "linkerd.io",
+	Version:  "v1alpha2",
+	Resource: "serviceprofiles",
+}
+
+// ServiceProfileLister provides functionality for inspecting Linkerd ServiceProfiles
+type ServiceProfileLister struct {
+	dynamicClient dynamic.Interface
+}
+
+// NewServiceProfileLister creates a new ServiceProfile lister
+func NewServiceProfileLister(dynamicClient dynamic.Interface) *ServiceProfileLister {
+	return &ServiceProfileLister{
+		dynamicClient: dynamicClient,
+	}
+}
+
+// ServiceProfileRoute summarizes a route of a ServiceProfile
+type ServiceProfileRoute struct {
+	Name        string `json:"name"`
+	Method      string `json:"method,omitempty"`
+	PathRegex   string `json:"pathRegex,omitempty"`
+	IsRetryable bool   `json:"isRetryable"`
+	Timeout     string `json:"timeout,omitempty"`
+}
+
+// RetryBudget is the retry budget of a ServiceProfile
+type RetryBudget struct {
+	RetryRatio          float64 `json:"retryRatio"`
+	MinRetriesPerSecond int64   `json:"minRetriesPerSecond"`
+	TTL                 string  `json:"ttl"`
+}
+
+// ServiceProfileSummary summarizes a ServiceProfile and the problems found in it
+type ServiceProfileSummary struct {
+	Name        string                `json:"name"`
+	Namespace   string                `json:"namespace"`
+	Routes      []ServiceProfileRoute `json:"routes"`
+	RetryBudget *RetryBudget          `json:"retryBudget,omitempty"`
+	Valid       bool                  `json:"valid"`
+	Issues      []string              `json:"issues"`
+}
+
+// ListServiceProfiles lists the ServiceProfiles in a namespace (all namespaces when empty),
+// summarizing their routes, retry budgets and timeouts and validating route regexes and durations
+func (l *ServiceProfileLister) ListServiceProfiles(ctx context.Context, namespace string) (*mcp.CallToolResult, error) {
+	list, err := l.dynamicClient.Resource(serviceProfileGVR).Namespace(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to list ServiceProfiles: %v (ensure the Linkerd ServiceProfile CRD is installed)", err)), nil
+	}
+
+	profiles := []ServiceProfileSummary{}
+	invalid := 0
+	for _, item := range list.Items {
+		profile := summarizeServiceProfile(item)
+		if !profile.Valid {
+			invalid++
+		}
+		profiles = append(profiles, profile)
+	}
+
+	sort.Slice(profiles, func(i, j int) bool {
+		if profiles[i].Namespace != profiles[j].Namespace {
+			return profiles[i].Namespace < profiles[j].Namespace
+		}
+		return profiles[i].Name < profiles[j].Name
+	})
+
+	result := map[string]interface{}{
+		"namespace":       namespace,
+		"serviceProfiles": profiles,
+		"totalProfiles":   len(profiles),
+		"invalidProfiles": invalid,
+	}
+
+	resultJSON, _ := json.MarshalIndent(result, "", "  ")
+	return mcp.NewToolResultText(string(resultJSON)), nil
+}
+
+// summarizeServiceProfile extracts the routes and retry budget of a ServiceProfile and validates them
+func summarizeServiceProfile(profile unstructured.Unstructured) ServiceProfileSummary {
+	summary := ServiceProfileSummary{
+		Name:      profile.GetName(),
+		Namespace: profile.GetNamespace(),
+		Routes:    []ServiceProfileRoute{},
+		Issues:    []string{},
+	}
+
+	// ServiceProfiles only apply when named after the FQDN of a Service
+	if !strings.Contains(summary.Name, "."+summary.Namespace+".svc.") {
+		summary.Issues = append(summary.Issues,
+			fmt.Sprintf("name %q is not the FQDN of a Service in namespace %s (<service>.%s.svc.<cluster-domain>)", summary.Name, summary.Namespace, summary.Namespace))
+	}
+
+	routes, _, _ := unstructured.NestedSlice(profile.Object, "spec", "routes")
+	for i, r := range routes {
+		routeMap, ok := r.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		route := ServiceProfileRoute{}
+		route.Name, _, _ = unstructured.NestedString(routeMap, "name")
+		route.Method, _, _ = unstructured.NestedString(routeMap, "condition", "method")
+		route.PathRegex, _, _ = unstructured.NestedString(routeMap, "condition", "pathRegex")
+		route.IsRetryable, _, _ = unstructured.NestedBool(routeMap, "isRetryable")
+		route.Timeout, _, _ = unstructured.NestedString(routeMap, "timeout")
+
+		label := route.Name
+		if label == "" {
+			label = fmt.Sprintf("#%d", i)
+			summary.Issues = append(summary.Issues, fmt.Sprintf("route %s has no name", label))
+		}
+
+		condition, found, _ := unstructured.NestedMap(routeMap, "condition")
+		if !found {
+			summary.Issues = append(summary.Issues, fmt.Sprintf("route %s has no condition", label))
+		}
+		for _, pathRegex := range conditionPathRegexes(condition) {
+			if _, err := regexp.Compile(pathRegex); err != nil {
+				summary.Issues = append(summary.Issues, fmt.Sprintf("route %s has an invalid pathRegex %q: %v", label, pathRegex, err))
+			}
+		}
+
+		if route.Timeout != "" {
+			if issue := validateDuration(route.Timeout); issue != "" {
+				summary.Issues = append(summary.Issues, fmt.Sprintf("route %s timeout %s", label, issue))
+			}
+		}
+
+		summary.Routes = append(summary.Routes, route)
+	}
+
+	if budget, found, _ := unstructured.NestedMap(profile.Object, "spec", "retryBudget"); found {
+		retryBudget := &RetryBudget{}
+		switch ratio := budget["retryRatio"].(type) {
+		case float64:
+			retryBudget.RetryRatio = ratio
+		case int64:
+			retryBudget.RetryRatio = float64(ratio)
+		}
+		retryBudget.MinRetriesPerSecond, _, _ = unstructured.NestedInt64(budget, "minRetriesPerSecond")
+		retryBudget.TTL, _, _ = unstructured.NestedString(budget, "ttl")
+
+		if retryBudget.RetryRatio < 0 {
+			summary.Issues = append(summary.Issues, fmt.Sprintf("retryBudget retryRatio %v is negative", retryBudget.RetryRatio))
+		}
+		if retryBudget.MinRetriesPerSecond < 0 {
+			summary.Issues = append(summary.Issues, fmt.Sprintf("retryBudget minRetriesPerSecond %d is negative", retryBudget.MinRetriesPerSecond))
+		}
+		if retryBudget.TTL != "" {
+			if issue := validateDuration(retryBudget.TTL); issue != "" {
+				summary.Issues = append(summary.Issues, fmt.Sprintf("retryBudget ttl %s", issue))
+			}
+		}
+
+		summary.RetryBudget = retryBudget
+	}
+
+	summary.Valid = len(summary.Issues) == 0
+	return summary
+}
+
+// conditionPathRegexes collects the pathRegex values of a route condition, including
+// those nested in all, any and not conditions
+func conditionPathRegexes(condition map[string]interface{}) []string {
+	regexes := []string{}
+	if pathRegex, ok := condition["pathRegex"].(string); ok {
+		regexes = append(regexes, pathRegex)
+	}
+	for _, key := range []string{"all", "any"} {
+		nested, _ := condition[key].([]interface{})
+		for _, n := range nested {
+			if nestedMap, ok := n.(map[string]interface{}); ok {
+				regexes = append(regexes, conditionPathRegexes(nestedMap)...)
+			}
+		}
+	}
+	if not, ok := condition["not"].(map[string]interface{}); ok {
+		regexes = append(regexes, conditionPathRegexes(not)...)
+	}
+	return regexes
+}
+
+// validateDuration describes why a ServiceProfile duration is invalid, or returns "" when it is valid
+func validateDuration(value string) string {
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		return fmt.Sprintf("%q is not a valid duration", value)
+	}
+	if duration < 0 {
+		return fmt.Sprintf("%q is negative", value)
+	}
+	return ""
+}
@@ -0,0 +1,161 @@
+package mesh_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/christianhuening/linkerd-mcp/internal/mesh"
+	"github.com/christianhuening/linkerd-mcp/internal/testutil"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/fake"
+)
+
+var serviceProfileGVR = schema.GroupVersionResource{Group: "linkerd.io", Version: "v1alpha2", Resource: "serviceprofiles"}
+
+var _ = Describe("ServiceProfileLister", func() {
+	var (
+		ctx           context.Context
+		dynamicClient *fake.FakeDynamicClient
+		lister        *mesh.ServiceProfileLister
+	)
+
+	profilesByName := func(namespace string) map[string]map[string]interface{} {
+		result, err := lister.ListServiceProfiles(ctx, namespace)
+		Expect(err).NotTo(HaveOccurred())
+
+		var response map[string]interface{}
+		err = testutil.ParseJSONResult(result, &response)
+		Expect(err).NotTo(HaveOccurred())
+
+		byName := map[string]map[string]interface{}{}
+		for _, entry := range response["serviceProfiles"].([]interface{}) {
+			profile := entry.(map[string]interface{})
+			byName[profile["name"].(string)] = profile
+		}
+		return byName
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+
+		scheme := runtime.NewScheme()
+		gvrToListKind := map[schema.GroupVersionResource]string{
+			serviceProfileGVR: "ServiceProfileList",
+		}
+		dynamicClient = fake.NewSimpleDynamicClientWithCustomListKinds(scheme, gvrToListKind)
+		lister = mesh.NewServiceProfileLister(dynamicClient)
+	})
+
+	Context("with a valid ServiceProfile", func() {
+		BeforeEach(func() {
+			profile := testutil.CreateServiceProfile("web.prod.svc.cluster.local", "prod",
+				[]map[string]interface{}{
+					{
+						"name":        "GET /api/list",
+						"condition":   map[string]interface{}{"method": "GET", "pathRegex": "/api/list"},
+						"isRetryable": true,
+						"timeout":     "300ms",
+					},
+					{
+						"name":      "POST /api/vote/{id}",
+						"condition": map[string]interface{}{"method": "POST", "pathRegex": "/api/vote/[^/]*"},
+					},
+				},
+				map[string]interface{}{"retryRatio": 0.2, "minRetriesPerSecond": int64(10), "ttl": "10s"})
+			_, err := dynamicClient.Resource(serviceProfileGVR).Namespace("prod").Create(ctx, profile, metav1.CreateOptions{})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("should summarize routes, timeouts and retry budget", func() {
+			profile := profilesByName("prod")["web.prod.svc.cluster.local"]
+			Expect(profile).NotTo(BeNil())
+			Expect(profile["valid"]).To(BeTrue())
+			Expect(profile["issues"]).To(BeEmpty())
+
+			routes := profile["routes"].([]interface{})
+			Expect(routes).To(HaveLen(2))
+			Expect(routes[0]).To(Equal(map[string]interface{}{
+				"name":        "GET /api/list",
+				"method":      "GET",
+				"pathRegex":   "/api/list",
+				"isRetryable": true,
+				"timeout":     "300ms",
+			}))
+
+			Expect(profile["retryBudget"]).To(Equal(map[string]interface{}{
+				"retryRatio":          0.2,
+				"minRetriesPerSecond": float64(10),
+				"ttl":                 "10s",
+			}))
+		})
+	})
+
+	Context("with an invalid ServiceProfile", func() {
+		BeforeEach(func() {
+			profile := testutil.CreateServiceProfile("web", "prod",
+				[]map[string]interface{}{
+					{
+						"name":      "broken regex",
+						"condition": map[string]interface{}{"pathRegex": "/api/(list"},
+						"timeout":   "-5s",
+					},
+					{
+						"name": "nested",
+						"condition": map[string]interface{}{
+							"all": []interface{}{
+								map[string]interface{}{"method": "GET"},
+								map[string]interface{}{"not": map[string]interface{}{"pathRegex": "[z-a]"}},
+							},
+						},
+					},
+				},
+				map[string]interface{}{"retryRatio": -0.5, "minRetriesPerSecond": int64(10), "ttl": "ten seconds"})
+			_, err := dynamicClient.Resource(serviceProfileGVR).Namespace("prod").Create(ctx, profile, metav1.CreateOptions{})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("should report every issue", func() {
+			profile := profilesByName("prod")["web"]
+			Expect(profile).NotTo(BeNil())
+			Expect(profile["valid"]).To(BeFalse())
+
+			issues := profile["issues"].([]interface{})
+			Expect(issues).To(ContainElement(ContainSubstring("is not the FQDN")))
+			Expect(issues).To(ContainElement(ContainSubstring("route broken regex has an invalid pathRegex")))
+			Expect(issues).To(ContainElement(ContainSubstring("route broken regex timeout \"-5s\" is negative")))
+			Expect(issues).To(ContainElement(ContainSubstring("route nested has an invalid pathRegex \"[z-a]\"")))
+			Expect(issues).To(ContainElement(ContainSubstring("retryRatio -0.5 is negative")))
+			Expect(issues).To(ContainElement(ContainSubstring("ttl \"ten seconds\" is not a valid duration")))
+		})
+	})
+
+	Context("with ServiceProfiles in several namespaces", func() {
+		BeforeEach(func() {
+			for _, namespace := range []string{"prod", "staging"} {
+				profile := testutil.CreateServiceProfile("api."+namespace+".svc.cluster.local", namespace, nil, nil)
+				_, err := dynamicClient.Resource(serviceProfileGVR).Namespace(namespace).Create(ctx, profile, metav1.CreateOptions{})
+				Expect(err).NotTo(HaveOccurred())
+			}
+		})
+
+		It("should filter by namespace", func() {
+			Expect(profilesByName("staging")).To(HaveLen(1))
+			Expect(profilesByName("")).To(HaveLen(2))
+		})
+
+		It("should count profiles", func() {
+			result, err := lister.ListServiceProfiles(ctx, "")
+			Expect(err).NotTo(HaveOccurred())
+
+			var response map[string]interface{}
+			err = testutil.ParseJSONResult(result, &response)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(response["totalProfiles"]).To(BeNumerically("==", 2))
+			Expect(response["invalidProfiles"]).To(BeNumerically("==", 0))
+		})
+	})
+})
@@ -5,30 +5,70 @@ import (
 	"encoding/json"
 	"fmt"
 
+	"github.com/christianhuening/linkerd-mcp/internal/kube"
 	"github.com/mark3labs/mcp-go/mcp"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/client-go/kubernetes"
 )
 
+const (
+	// defaultControlPlaneNamespace is the namespace of the Linkerd control plane unless configured otherwise
+	defaultControlPlaneNamespace = "linkerd"
+	// controlPlaneComponentLabel marks the pods of the Linkerd control plane
+	controlPlaneComponentLabel = "linkerd.io/control-plane-component"
+	// extensionLabel marks the pods of Linkerd extensions, such as linkerd-viz
+	extensionLabel = "linkerd.io/extension"
+)
+
 // ServiceLister provides functionality for listing meshed services
 type ServiceLister struct {
-	clientset kubernetes.Interface
+	clientset             kubernetes.Interface
+	namespaceFilter       *kube.NamespaceFilter
+	controlPlaneNamespace string
 }
 
 // NewServiceLister creates a new service lister
 func NewServiceLister(clientset kubernetes.Interface) *ServiceLister {
 	return &ServiceLister{
-		clientset: clientset,
+		clientset:             clientset,
+		controlPlaneNamespace: defaultControlPlaneNamespace,
+	}
+}
+
+// SetControlPlaneNamespace sets the namespace of the Linkerd control plane, whose pods are left
+// out of listings unless requested. Empty values are ignored.
+func (s *ServiceLister) SetControlPlaneNamespace(namespace string) {
+	if namespace != "" {
+		s.controlPlaneNamespace = namespace
 	}
 }
 
-// ListMeshedServices lists all services that are part of the Linkerd mesh
-func (s *ServiceLister) ListMeshedServices(ctx context.Context, namespace string) (*mcp.CallToolResult, error) {
-	listOptions := metav1.ListOptions{}
-	pods, err := s.clientset.CoreV1().Pods(namespace).List(ctx, listOptions)
+// SetNamespaceFilter restricts the namespaces listed when listing services of all namespaces
+func (s *ServiceLister) SetNamespaceFilter(filter *kube.NamespaceFilter) {
+	s.namespaceFilter = filter
+}
+
+// ListMeshedServices lists all services that are part of the Linkerd mesh, with how many of
+// their pods are ready. Pods that aren't ready are left out of the Service endpoints, so a
+// service without ready pods receives no traffic. A non-empty labelSelector restricts the pods
+// considered, and is evaluated by the API server. An empty namespace lists the namespaces the
+// namespace filter covers. The Linkerd control plane and extensions, which are meshed too, are
+// only listed with includeControlPlane.
+func (s *ServiceLister) ListMeshedServices(ctx context.Context, namespace, labelSelector string, includeControlPlane bool) (*mcp.CallToolResult, error) {
+	if _, err := labels.Parse(labelSelector); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Invalid label selector: %v", err)), nil
+	}
+
+	listOptions := metav1.ListOptions{LabelSelector: labelSelector}
+	pods, err := kube.ListPods(ctx, s.clientset, namespace, listOptions)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("Failed to list pods: %v", err)), nil
 	}
+	if namespace == "" {
+		pods.Items = s.namespaceFilter.Pods(pods.Items)
+	}
 
 	meshedServices := make(map[string]map[string]interface{})
 
@@ -36,7 +76,7 @@ func (s *ServiceLister) ListMeshedServices(ctx context.Context, namespace string
 		// Check if pod has Linkerd proxy injected
 		hasProxy := false
 		for _, container := range pod.Spec.Containers {
-			if container.Name == "linkerd-proxy" {
+			if kube.IsProxyContainer(container.Name) {
 				hasProxy = true
 				break
 			}
@@ -46,6 +86,10 @@ func (s *ServiceLister) ListMeshedServices(ctx context.Context, namespace string
 			continue
 		}
 
+		if !includeControlPlane && s.isControlPlanePod(pod) {
+			continue
+		}
+
 		serviceName := pod.Labels["app"]
 		if serviceName == "" {
 			serviceName = pod.Labels["k8s-app"]
@@ -60,13 +104,22 @@ func (s *ServiceLister) ListMeshedServices(ctx context.Context, namespace string
 				"namespace": pod.Namespace,
 				"service":   serviceName,
 				"pods":      []string{},
+				"podReady":  map[string]bool{},
+				"readyPods": 0,
+				"totalPods": 0,
 			}
 		}
 
+		ready := podReady(pod)
 		meshedServices[key]["pods"] = append(
 			meshedServices[key]["pods"].([]string),
 			pod.Name,
 		)
+		meshedServices[key]["podReady"].(map[string]bool)[pod.Name] = ready
+		meshedServices[key]["totalPods"] = meshedServices[key]["totalPods"].(int) + 1
+		if ready {
+			meshedServices[key]["readyPods"] = meshedServices[key]["readyPods"].(int) + 1
+		}
 	}
 
 	result := map[string]interface{}{
@@ -77,3 +130,23 @@ func (s *ServiceLister) ListMeshedServices(ctx context.Context, namespace string
 	resultJSON, _ := json.MarshalIndent(result, "", "  ")
 	return mcp.NewToolResultText(string(resultJSON)), nil
 }
+
+// podReady reports whether a pod's Ready condition is true
+func podReady(pod corev1.Pod) bool {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// isControlPlanePod reports whether a pod belongs to the Linkerd control plane or an extension
+func (s *ServiceLister) isControlPlanePod(pod corev1.Pod) bool {
+	if pod.Namespace == s.controlPlaneNamespace {
+		return true
+	}
+	_, component := pod.Labels[controlPlaneComponentLabel]
+	_, extension := pod.Labels[extensionLabel]
+	return component || extension
+}
@@ -6,8 +6,10 @@ import (
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 
+	"github.com/christianhuening/linkerd-mcp/internal/kube"
 	"github.com/christianhuening/linkerd-mcp/internal/mesh"
 	"github.com/christianhuening/linkerd-mcp/internal/testutil"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/client-go/kubernetes/fake"
 )
 
@@ -44,7 +46,7 @@ var _ = Describe("ServiceLister", func() {
 			})
 
 			It("should list all meshed services across namespaces", func() {
-				result, err := lister.ListMeshedServices(ctx, "")
+				result, err := lister.ListMeshedServices(ctx, "", "", false)
 				Expect(err).NotTo(HaveOccurred())
 
 				var response map[string]interface{}
@@ -83,7 +85,7 @@ var _ = Describe("ServiceLister", func() {
 			})
 
 			It("should only return services in the specified namespace", func() {
-				result, err := lister.ListMeshedServices(ctx, "prod")
+				result, err := lister.ListMeshedServices(ctx, "prod", "", false)
 				Expect(err).NotTo(HaveOccurred())
 
 				var response map[string]interface{}
@@ -109,7 +111,7 @@ var _ = Describe("ServiceLister", func() {
 			})
 
 			It("should return zero services", func() {
-				result, err := lister.ListMeshedServices(ctx, "")
+				result, err := lister.ListMeshedServices(ctx, "", "", false)
 				Expect(err).NotTo(HaveOccurred())
 
 				var response map[string]interface{}
@@ -120,6 +122,31 @@ var _ = Describe("ServiceLister", func() {
 			})
 		})
 
+		Context("with a renamed proxy container", func() {
+			BeforeEach(func() {
+				renamed := testutil.CreateMeshedPod("frontend-1", "prod", "frontend")
+				renamed.Spec.Containers[1].Name = "mesh-proxy"
+				clientset = fake.NewSimpleClientset(renamed, testutil.CreateMeshedPod("backend-1", "prod", "backend"))
+				lister = mesh.NewServiceLister(clientset)
+				kube.SetProxyContainerName("mesh-proxy")
+			})
+
+			AfterEach(func() {
+				kube.SetProxyContainerName(kube.DefaultProxyContainerName)
+			})
+
+			It("should only count pods with the configured proxy container as meshed", func() {
+				result, err := lister.ListMeshedServices(ctx, "", "", false)
+				Expect(err).NotTo(HaveOccurred())
+
+				var response map[string]interface{}
+				Expect(testutil.ParseJSONResult(result, &response)).To(Succeed())
+
+				Expect(response["totalServices"]).To(BeNumerically("==", 1))
+				Expect(response["services"]).To(HaveKey("prod/frontend"))
+			})
+		})
+
 		Context("with pods without app label", func() {
 			BeforeEach(func() {
 				podWithoutLabel := testutil.CreateMeshedPod("no-label-1", "default", "")
@@ -129,7 +156,7 @@ var _ = Describe("ServiceLister", func() {
 			})
 
 			It("should not list pods without app labels", func() {
-				result, err := lister.ListMeshedServices(ctx, "")
+				result, err := lister.ListMeshedServices(ctx, "", "", false)
 				Expect(err).NotTo(HaveOccurred())
 
 				var response map[string]interface{}
@@ -149,7 +176,7 @@ var _ = Describe("ServiceLister", func() {
 			})
 
 			It("should recognize k8s-app label as service name", func() {
-				result, err := lister.ListMeshedServices(ctx, "")
+				result, err := lister.ListMeshedServices(ctx, "", "", false)
 				Expect(err).NotTo(HaveOccurred())
 
 				var response map[string]interface{}
@@ -175,7 +202,7 @@ var _ = Describe("ServiceLister", func() {
 			})
 
 			It("should aggregate all pods under the same service", func() {
-				result, err := lister.ListMeshedServices(ctx, "prod")
+				result, err := lister.ListMeshedServices(ctx, "prod", "", false)
 				Expect(err).NotTo(HaveOccurred())
 
 				var response map[string]interface{}
@@ -197,5 +224,128 @@ var _ = Describe("ServiceLister", func() {
 				Expect(podNames).To(HaveKey("web-3"))
 			})
 		})
+
+		Context("with the Linkerd control plane and extensions", func() {
+			BeforeEach(func() {
+				destination := testutil.CreateMeshedPod("linkerd-destination-1", "linkerd", "destination")
+				destination.Labels["linkerd.io/control-plane-component"] = "destination"
+				web := testutil.CreateMeshedPod("web-1", "linkerd-viz", "web")
+				web.Labels["linkerd.io/extension"] = "viz"
+				clientset = fake.NewSimpleClientset(
+					destination,
+					web,
+					testutil.CreateMeshedPod("identity-1", "linkerd", "identity"),
+					testutil.CreateMeshedPod("frontend-1", "prod", "frontend"),
+				)
+				lister = mesh.NewServiceLister(clientset)
+			})
+
+			It("should only list application services by default", func() {
+				result, err := lister.ListMeshedServices(ctx, "", "", false)
+				Expect(err).NotTo(HaveOccurred())
+
+				var response map[string]interface{}
+				err = testutil.ParseJSONResult(result, &response)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(response["totalServices"]).To(BeNumerically("==", 1))
+				Expect(response["services"]).To(HaveKey("prod/frontend"))
+			})
+
+			It("should list every meshed service with includeControlPlane", func() {
+				result, err := lister.ListMeshedServices(ctx, "", "", true)
+				Expect(err).NotTo(HaveOccurred())
+
+				var response map[string]interface{}
+				err = testutil.ParseJSONResult(result, &response)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(response["totalServices"]).To(BeNumerically("==", 4))
+			})
+
+			It("should recognize a custom control plane namespace", func() {
+				clientset = fake.NewSimpleClientset(
+					testutil.CreateMeshedPod("identity-1", "linkerd-control-plane", "identity"),
+					testutil.CreateMeshedPod("frontend-1", "prod", "frontend"),
+				)
+				lister = mesh.NewServiceLister(clientset)
+				lister.SetControlPlaneNamespace("linkerd-control-plane")
+
+				result, err := lister.ListMeshedServices(ctx, "", "", false)
+				Expect(err).NotTo(HaveOccurred())
+
+				var response map[string]interface{}
+				err = testutil.ParseJSONResult(result, &response)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(response["services"]).NotTo(HaveKey("linkerd-control-plane/identity"))
+				Expect(response["services"]).To(HaveKey("prod/frontend"))
+			})
+		})
+
+		Context("with pods that aren't ready", func() {
+			BeforeEach(func() {
+				ready := testutil.CreateMeshedPod("web-1", "prod", "web")
+				ready.Status.Conditions = []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}}
+				notReady := testutil.CreateMeshedPod("web-2", "prod", "web")
+				notReady.Status.Conditions = []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionFalse}}
+				clientset = fake.NewSimpleClientset(ready, notReady, testutil.CreateMeshedPod("api-1", "prod", "api"))
+				lister = mesh.NewServiceLister(clientset)
+			})
+
+			It("should report the ready and total pods of each service", func() {
+				result, err := lister.ListMeshedServices(ctx, "prod", "", false)
+				Expect(err).NotTo(HaveOccurred())
+
+				var response map[string]interface{}
+				err = testutil.ParseJSONResult(result, &response)
+				Expect(err).NotTo(HaveOccurred())
+
+				services := response["services"].(map[string]interface{})
+				web := services["prod/web"].(map[string]interface{})
+				Expect(web["readyPods"]).To(BeNumerically("==", 1))
+				Expect(web["totalPods"]).To(BeNumerically("==", 2))
+				Expect(web["podReady"]).To(Equal(map[string]interface{}{"web-1": true, "web-2": false}))
+
+				// Without a Ready condition, the pod is not ready
+				api := services["prod/api"].(map[string]interface{})
+				Expect(api["readyPods"]).To(BeNumerically("==", 0))
+				Expect(api["totalPods"]).To(BeNumerically("==", 1))
+			})
+		})
+
+		Context("when filtering by label selector", func() {
+			BeforeEach(func() {
+				payments := testutil.CreateMeshedPod("checkout-1", "prod", "checkout")
+				payments.Labels["team"] = "payments"
+				clientset = fake.NewSimpleClientset(
+					payments,
+					testutil.CreateMeshedPod("web-1", "prod", "web"),
+				)
+				lister = mesh.NewServiceLister(clientset)
+			})
+
+			It("should only return services whose pods match the selector", func() {
+				result, err := lister.ListMeshedServices(ctx, "prod", "team=payments", false)
+				Expect(err).NotTo(HaveOccurred())
+
+				var response map[string]interface{}
+				err = testutil.ParseJSONResult(result, &response)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(response["totalServices"]).To(BeNumerically("==", 1))
+				Expect(response["services"]).To(HaveKey("prod/checkout"))
+			})
+
+			It("should reject an invalid selector", func() {
+				result, err := lister.ListMeshedServices(ctx, "prod", "team in (payments", false)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result.IsError).To(BeTrue())
+
+				var text string
+				Expect(testutil.GetTextFromResult(result, &text)).To(Succeed())
+				Expect(text).To(ContainSubstring("Invalid label selector"))
+			})
+		})
 	})
 })
@@ -0,0 +1,177 @@
+package metrics
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/prometheus/common/model"
+)
+
+// DefaultAnomalyStdDevThreshold is the number of standard deviations above the mean
+// a sample must reach to be reported as a spike
+const DefaultAnomalyStdDevThreshold = 3.0
+
+// minRateWindow is the smallest rate() window used for range queries, so that each
+// point covers enough scrapes to produce a value
+const minRateWindow = time.Minute
+
+// AnomalyType classifies a detected anomaly
+type AnomalyType string
+
+const (
+	AnomalyTypeDrop  AnomalyType = "drop"
+	AnomalyTypeSpike AnomalyType = "spike"
+)
+
+// Anomaly describes a single anomalous point in a time series
+type Anomaly struct {
+	Timestamp time.Time   `json:"timestamp"`
+	Type      AnomalyType `json:"type"`
+	Value     float64     `json:"value"`     // observed value
+	Expected  float64     `json:"expected"`  // previous value for drops, series mean for spikes
+	Magnitude float64     `json:"magnitude"` // absolute change for drops, standard deviations from the mean for spikes
+}
+
+// TrafficAnomalyReport contains the anomalies detected in a service's request rate
+type TrafficAnomalyReport struct {
+	Service         string    `json:"service"`
+	Namespace       string    `json:"namespace"`
+	Deployment      string    `json:"deployment,omitempty"`
+	TimeRange       TimeRange `json:"timeRange"`
+	Points          int       `json:"points"`
+	Mean            float64   `json:"mean"`
+	StdDev          float64   `json:"stdDev"`
+	StdDevThreshold float64   `json:"stdDevThreshold"`
+	Anomalies       []Anomaly `json:"anomalies"`
+}
+
+// DetectTrafficAnomalies fetches the request-rate series of a service and flags drops to zero
+// and spikes beyond stdDevThreshold standard deviations. An empty stepStr uses the step
+// computed from the time range.
+func (c *MetricsCollector) DetectTrafficAnomalies(ctx context.Context, namespace, service, timeRangeStr, stepStr string, stdDevThreshold float64) (*mcp.CallToolResult, error) {
+	if !c.Available() {
+		return unavailableResult(), nil
+	}
+
+	tr, err := ParseTimeRangeWithStep(timeRangeStr, stepStr)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Invalid time range: %v", err)), nil
+	}
+
+	if stdDevThreshold <= 0 {
+		stdDevThreshold = DefaultAnomalyStdDevThreshold
+	}
+
+	deployment, err := c.findDeploymentForService(ctx, namespace, service)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to find deployment: %v", err)), nil
+	}
+
+	rateWindow := tr.Step
+	if rateWindow < minRateWindow {
+		rateWindow = minRateWindow
+	}
+
+	query := c.queryBuilder.BuildServiceRequestRateQuery(deployment, namespace, rateWindow)
+	result, err := c.promClient.QueryRange(ctx, query, tr)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to query request rate: %v", err)), nil
+	}
+
+	series := extractSeries(result)
+	mean, stdDev := meanAndStdDev(series)
+
+	report := TrafficAnomalyReport{
+		Service:         service,
+		Namespace:       namespace,
+		Deployment:      deployment,
+		TimeRange:       tr,
+		Points:          len(series),
+		Mean:            mean,
+		StdDev:          stdDev,
+		StdDevThreshold: stdDevThreshold,
+		Anomalies:       DetectAnomalies(series, stdDevThreshold),
+	}
+
+	data, err := json.Marshal(report)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal anomalies: %v", err)), nil
+	}
+
+	return mcp.NewToolResultText(string(data)), nil
+}
+
+// DetectAnomalies flags points in a series that drop to zero after non-zero traffic,
+// or that exceed the series mean by more than stdDevThreshold standard deviations
+func DetectAnomalies(series []model.SamplePair, stdDevThreshold float64) []Anomaly {
+	anomalies := []Anomaly{}
+	mean, stdDev := meanAndStdDev(series)
+
+	for i, sample := range series {
+		value := float64(sample.Value)
+
+		if i > 0 && value == 0 {
+			previous := float64(series[i-1].Value)
+			if previous > 0 {
+				anomalies = append(anomalies, Anomaly{
+					Timestamp: sample.Timestamp.Time(),
+					Type:      AnomalyTypeDrop,
+					Value:     value,
+					Expected:  previous,
+					Magnitude: previous,
+				})
+				continue
+			}
+		}
+
+		if stdDev > 0 {
+			deviation := (value - mean) / stdDev
+			if deviation > stdDevThreshold {
+				anomalies = append(anomalies, Anomaly{
+					Timestamp: sample.Timestamp.Time(),
+					Type:      AnomalyTypeSpike,
+					Value:     value,
+					Expected:  mean,
+					Magnitude: deviation,
+				})
+			}
+		}
+	}
+
+	return anomalies
+}
+
+// meanAndStdDev computes the mean and population standard deviation of a series
+func meanAndStdDev(series []model.SamplePair) (float64, float64) {
+	if len(series) == 0 {
+		return 0, 0
+	}
+
+	sum := 0.0
+	for _, sample := range series {
+		sum += float64(sample.Value)
+	}
+	mean := sum / float64(len(series))
+
+	variance := 0.0
+	for _, sample := range series {
+		diff := float64(sample.Value) - mean
+		variance += diff * diff
+	}
+	variance /= float64(len(series))
+
+	return mean, math.Sqrt(variance)
+}
+
+// extractSeries extracts the first time series from a Prometheus range query result
+func extractSeries(value model.Value) []model.SamplePair {
+	matrix, ok := value.(model.Matrix)
+	if !ok || len(matrix) == 0 {
+		return []model.SamplePair{}
+	}
+	return matrix[0].Values
+}
@@ -0,0 +1,66 @@
+package metrics_test
+
+import (
+	"time"
+
+	"github.com/christianhuening/linkerd-mcp/internal/metrics"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/common/model"
+)
+
+func buildSeries(start time.Time, step time.Duration, values ...float64) []model.SamplePair {
+	series := make([]model.SamplePair, len(values))
+	for i, v := range values {
+		series[i] = model.SamplePair{
+			Timestamp: model.TimeFromUnixNano(start.Add(time.Duration(i) * step).UnixNano()),
+			Value:     model.SampleValue(v),
+		}
+	}
+	return series
+}
+
+var _ = Describe("DetectAnomalies", func() {
+	start := time.Unix(1700000000, 0)
+	step := 30 * time.Second
+
+	It("should flag a drop to zero and a spike", func() {
+		values := []float64{}
+		for i := 0; i < 20; i++ {
+			values = append(values, 10)
+		}
+		values[8] = 0   // traffic drops out
+		values[15] = 95 // burst of traffic
+		series := buildSeries(start, step, values...)
+
+		anomalies := metrics.DetectAnomalies(series, metrics.DefaultAnomalyStdDevThreshold)
+
+		Expect(anomalies).To(HaveLen(2))
+
+		Expect(anomalies[0].Type).To(Equal(metrics.AnomalyTypeDrop))
+		Expect(anomalies[0].Timestamp.Equal(start.Add(8 * step))).To(BeTrue())
+		Expect(anomalies[0].Value).To(Equal(0.0))
+		Expect(anomalies[0].Expected).To(Equal(10.0))
+
+		Expect(anomalies[1].Type).To(Equal(metrics.AnomalyTypeSpike))
+		Expect(anomalies[1].Timestamp.Equal(start.Add(15 * step))).To(BeTrue())
+		Expect(anomalies[1].Value).To(Equal(95.0))
+		Expect(anomalies[1].Magnitude).To(BeNumerically(">", metrics.DefaultAnomalyStdDevThreshold))
+	})
+
+	It("should not flag a steady series", func() {
+		series := buildSeries(start, step, 10, 11, 9, 10, 10, 11, 9, 10)
+
+		Expect(metrics.DetectAnomalies(series, metrics.DefaultAnomalyStdDevThreshold)).To(BeEmpty())
+	})
+
+	It("should not flag a service that never received traffic", func() {
+		series := buildSeries(start, step, 0, 0, 0, 0)
+
+		Expect(metrics.DetectAnomalies(series, metrics.DefaultAnomalyStdDevThreshold)).To(BeEmpty())
+	})
+
+	It("should handle an empty series", func() {
+		Expect(metrics.DetectAnomalies(nil, metrics.DefaultAnomalyStdDevThreshold)).To(BeEmpty())
+	})
+})
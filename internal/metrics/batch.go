@@ -0,0 +1,127 @@
+package metrics
+
+import (
+	"context"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/common/model"
+)
+
+// DefaultQueryConcurrency is the number of services whose metrics are queried at once by the
+// namespace-wide tools
+const DefaultQueryConcurrency = 8
+
+// serviceSnapshot holds the basic metrics of one service: the request rate, the success and
+// error rates (0-1) and the p95 latency. Metrics whose query failed or returned no data are nil.
+// The 4xx and 5xx rates (0-1) are only queried when requested.
+type serviceSnapshot struct {
+	requestRate     *float64
+	successRate     *float64
+	errorRate       *float64
+	latencyP95      *float64
+	clientErrorRate *float64
+	serverErrorRate *float64
+}
+
+// SetQueryConcurrency sets how many services the namespace-wide tools query at once. Values
+// below 1 restore DefaultQueryConcurrency.
+func (c *MetricsCollector) SetQueryConcurrency(concurrency int) {
+	if concurrency < 1 {
+		concurrency = DefaultQueryConcurrency
+	}
+	c.queryConcurrency = concurrency
+}
+
+// serviceSnapshots queries the basic metrics of each deployment of a namespace, up to the query
+// concurrency at a time. Snapshots are in the order of deployments, however the queries
+// complete. Failed queries are recorded in warnings. Once ctx is done no further deployment is
+// queried and its error is returned. statusClasses adds the rates of 4xx and 5xx responses.
+func (c *MetricsCollector) serviceSnapshots(ctx context.Context, warnings *queryWarnings, namespace string, deployments []string, tr TimeRange, statusClasses bool) ([]serviceSnapshot, error) {
+	snapshots := make([]serviceSnapshot, len(deployments))
+	window := tr.End.Sub(tr.Start)
+
+	workers := c.queryConcurrency
+	if workers < 1 {
+		workers = DefaultQueryConcurrency
+	}
+	workers = min(workers, len(deployments))
+
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				snapshots[i] = c.serviceSnapshot(ctx, warnings, namespace, deployments[i], window, tr.End, statusClasses)
+			}
+		}()
+	}
+
+dispatch:
+	for i := range deployments {
+		select {
+		case indexes <- i:
+		case <-ctx.Done():
+			break dispatch
+		}
+	}
+	close(indexes)
+	wg.Wait()
+
+	return snapshots, ctx.Err()
+}
+
+// serviceSnapshot queries the basic metrics of a deployment at ts over the given window. The
+// warnings of failed queries name the deployment.
+func (c *MetricsCollector) serviceSnapshot(ctx context.Context, warnings *queryWarnings, namespace, deployment string, window time.Duration, ts time.Time, statusClasses bool) serviceSnapshot {
+	query := func(metric, promQL string) *float64 {
+		return extractOptionalValue(c.optionalQuery(ctx, warnings, deployment+" "+metric, promQL, ts))
+	}
+
+	snapshot := serviceSnapshot{
+		requestRate: query("request rate", c.queryBuilder.BuildServiceRequestRateQuery(deployment, namespace, window)),
+		successRate: query("success rate", c.queryBuilder.BuildServiceSuccessRateQuery(deployment, namespace, window)),
+		errorRate:   query("error rate", c.queryBuilder.BuildServiceErrorRateQuery(deployment, namespace, window)),
+		latencyP95:  query("p95 latency", c.queryBuilder.BuildServiceLatencyQuery(deployment, namespace, 0.95, window)),
+	}
+	if statusClasses {
+		result := c.optionalQuery(ctx, warnings, deployment+" responses by status", c.queryBuilder.BuildResponsesByStatusQuery(deployment, namespace, window), ts)
+		snapshot.clientErrorRate, snapshot.serverErrorRate = statusClassRates(result)
+	}
+	return snapshot
+}
+
+// statusClassRates returns the shares (0-1) of 4xx and 5xx responses among the responses
+// grouped by HTTP status, or nil without responses. Responses without a status count towards the
+// total only.
+func statusClassRates(value model.Value) (*float64, *float64) {
+	vector, ok := value.(model.Vector)
+	if !ok {
+		return nil, nil
+	}
+
+	var total, clientErrors, serverErrors float64
+	for _, sample := range vector {
+		rate := float64(sample.Value)
+		if math.IsNaN(rate) {
+			continue
+		}
+		total += rate
+		switch status := string(sample.Metric["http_status"]); {
+		case strings.HasPrefix(status, "4"):
+			clientErrors += rate
+		case strings.HasPrefix(status, "5"):
+			serverErrors += rate
+		}
+	}
+
+	if total == 0 {
+		return nil, nil
+	}
+	clientErrorRate, serverErrorRate := clientErrors/total, serverErrors/total
+	return &clientErrorRate, &serverErrorRate
+}
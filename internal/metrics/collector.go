@@ -4,125 +4,233 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"math"
+	"os"
+	"strconv"
 	"time"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/prometheus/common/model"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 )
 
 // MetricsCollector collects and analyzes Linkerd traffic metrics
 type MetricsCollector struct {
-	promClient   *PrometheusClient
-	queryBuilder *QueryBuilder
-	clientset    kubernetes.Interface
+	promClient    *PrometheusClient
+	queryBuilder  *QueryBuilder
+	clientset     kubernetes.Interface
+	dynamicClient dynamic.Interface
+
+	// queryConcurrency bounds the services queried at once by the namespace-wide tools
+	queryConcurrency int
 }
 
 // NewMetricsCollector creates a new metrics collector
-func NewMetricsCollector(config *rest.Config, clientset kubernetes.Interface, namespace string) (*MetricsCollector, error) {
+func NewMetricsCollector(config *rest.Config, clientset kubernetes.Interface, dynamicClient dynamic.Interface, namespace string) (*MetricsCollector, error) {
 	promClient, err := NewPrometheusClient(config, clientset, namespace)
 	if err != nil {
 		return nil, err
 	}
 
-	return &MetricsCollector{
-		promClient:   promClient,
-		queryBuilder: NewQueryBuilder(namespace),
-		clientset:    clientset,
-	}, nil
+	queryBuilder := NewQueryBuilder(namespace)
+	if exclude, err := strconv.ParseBool(os.Getenv("LINKERD_METRICS_EXCLUDE_ADMIN_TRAFFIC")); err == nil {
+		queryBuilder.SetExcludeAdminTraffic(exclude)
+	}
+
+	collector := &MetricsCollector{
+		promClient:    promClient,
+		queryBuilder:  queryBuilder,
+		clientset:     clientset,
+		dynamicClient: dynamicClient,
+	}
+	concurrency, _ := strconv.Atoi(os.Getenv("LINKERD_METRICS_QUERY_CONCURRENCY"))
+	collector.SetQueryConcurrency(concurrency)
+
+	return collector, nil
+}
+
+// Available reports whether the collector is configured. All collector methods are safe to
+// call on a nil collector and return an "unavailable" result instead of panicking.
+func (c *MetricsCollector) Available() bool {
+	return c != nil && c.promClient != nil
+}
+
+// Close releases the connection to Prometheus
+func (c *MetricsCollector) Close() {
+	if c == nil {
+		return
+	}
+	c.promClient.Close()
+}
+
+// CheckHealth probes Prometheus with a query bounded by timeout
+func (c *MetricsCollector) CheckHealth(ctx context.Context, timeout time.Duration) error {
+	if !c.Available() {
+		return fmt.Errorf("metrics collector is not configured")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	return c.promClient.CheckHealth(ctx)
 }
 
-// GetServiceMetrics retrieves comprehensive metrics for a service
-func (c *MetricsCollector) GetServiceMetrics(ctx context.Context, namespace, service, timeRangeStr string) (*mcp.CallToolResult, error) {
+// unavailableResult is returned by collector methods when no Prometheus connection is configured
+func unavailableResult() *mcp.CallToolResult {
+	data, _ := json.Marshal(map[string]interface{}{
+		"status": "unavailable",
+		"reason": "metrics collector is not configured (Prometheus unreachable or disabled)",
+	})
+	return mcp.NewToolResultError(string(data))
+}
+
+// GetServiceMetrics retrieves comprehensive metrics for a service, measured on the requests it
+// receives (inbound, the default) or on the requests it sends to its dependencies (outbound)
+func (c *MetricsCollector) GetServiceMetrics(ctx context.Context, namespace, service, timeRangeStr, directionStr string) (*mcp.CallToolResult, error) {
+	if !c.Available() {
+		return unavailableResult(), nil
+	}
+
 	// Parse time range
 	tr, err := ParseTimeRange(timeRangeStr)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("Invalid time range: %v", err)), nil
 	}
 
+	direction, err := ParseDirection(directionStr)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Invalid direction: %v", err)), nil
+	}
+
+	metrics, err := c.ServiceMetrics(ctx, namespace, service, tr, direction)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to collect service metrics: %v", err)), nil
+	}
+
+	data, err := json.Marshal(metrics)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal metrics: %v", err)), nil
+	}
+
+	return mcp.NewToolResultText(string(data)), nil
+}
+
+// ServiceMetrics collects the metrics of a service over the given time range, in the given
+// direction. The top destinations and sources are reported either way.
+func (c *MetricsCollector) ServiceMetrics(ctx context.Context, namespace, service string, tr TimeRange, direction Direction) (*ServiceMetrics, error) {
+	if !c.Available() {
+		return nil, fmt.Errorf("metrics collector is not configured")
+	}
+
 	// Find deployment for service
 	deployment, err := c.findDeploymentForService(ctx, namespace, service)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to find deployment: %v", err)), nil
+		return nil, fmt.Errorf("failed to find deployment: %v", err)
 	}
 
 	// Build and execute queries
 	window := tr.End.Sub(tr.Start)
+	qb := c.queryBuilder.WithDirection(direction)
+	warnings := &queryWarnings{}
 
 	// Request rate
-	reqRateQuery := c.queryBuilder.BuildServiceRequestRateQuery(deployment, namespace, window)
+	reqRateQuery := qb.BuildServiceRequestRateQuery(deployment, namespace, window)
 	reqRateResult, err := c.promClient.Query(ctx, reqRateQuery, tr.End)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to query request rate: %v", err)), nil
+		return nil, fmt.Errorf("failed to query request rate: %v", err)
 	}
-	requestRate, _ := extractScalarValue(reqRateResult)
+	requestRate := extractOptionalValue(reqRateResult)
 
 	// Success rate
-	successRateQuery := c.queryBuilder.BuildServiceSuccessRateQuery(deployment, namespace, window)
+	successRateQuery := qb.BuildServiceSuccessRateQuery(deployment, namespace, window)
 	successRateResult, err := c.promClient.Query(ctx, successRateQuery, tr.End)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to query success rate: %v", err)), nil
+		return nil, fmt.Errorf("failed to query success rate: %v", err)
 	}
-	successRate, _ := extractScalarValue(successRateResult)
+	successRate := extractOptionalValue(successRateResult)
 
 	// Error rate
-	errorRateQuery := c.queryBuilder.BuildServiceErrorRateQuery(deployment, namespace, window)
+	errorRateQuery := qb.BuildServiceErrorRateQuery(deployment, namespace, window)
 	errorRateResult, err := c.promClient.Query(ctx, errorRateQuery, tr.End)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to query error rate: %v", err)), nil
+		return nil, fmt.Errorf("failed to query error rate: %v", err)
 	}
-	errorRate, _ := extractScalarValue(errorRateResult)
+	errorRate := extractOptionalValue(errorRateResult)
+
+	// Retries and timeouts are only reported for services with routes configuring them
+	retryRateResult := c.optionalQuery(ctx, warnings, "retry rate", qb.BuildServiceRetryRateQuery(deployment, namespace, window), tr.End)
+	retryRate := extractOptionalValue(retryRateResult)
+
+	timeoutRateResult := c.optionalQuery(ctx, warnings, "timeout rate", qb.BuildServiceTimeoutRateQuery(deployment, namespace, window), tr.End)
+	timeoutRate := extractOptionalValue(timeoutRateResult)
 
 	// Latency metrics
-	p50Query := c.queryBuilder.BuildServiceLatencyQuery(deployment, namespace, 0.50, window)
-	p50Result, _ := c.promClient.Query(ctx, p50Query, tr.End)
-	p50, _ := extractScalarValue(p50Result)
+	p50Query := qb.BuildServiceLatencyQuery(deployment, namespace, 0.50, window)
+	p50Result := c.optionalQuery(ctx, warnings, "p50 latency", p50Query, tr.End)
+	p50 := extractOptionalValue(p50Result)
 
-	p95Query := c.queryBuilder.BuildServiceLatencyQuery(deployment, namespace, 0.95, window)
-	p95Result, _ := c.promClient.Query(ctx, p95Query, tr.End)
-	p95, _ := extractScalarValue(p95Result)
+	p95Query := qb.BuildServiceLatencyQuery(deployment, namespace, 0.95, window)
+	p95Result := c.optionalQuery(ctx, warnings, "p95 latency", p95Query, tr.End)
+	p95 := extractOptionalValue(p95Result)
 
-	p99Query := c.queryBuilder.BuildServiceLatencyQuery(deployment, namespace, 0.99, window)
-	p99Result, _ := c.promClient.Query(ctx, p99Query, tr.End)
-	p99, _ := extractScalarValue(p99Result)
+	p99Query := qb.BuildServiceLatencyQuery(deployment, namespace, 0.99, window)
+	p99Result := c.optionalQuery(ctx, warnings, "p99 latency", p99Query, tr.End)
+	p99 := extractOptionalValue(p99Result)
 
-	meanQuery := c.queryBuilder.BuildServiceMeanLatencyQuery(deployment, namespace, window)
-	meanResult, _ := c.promClient.Query(ctx, meanQuery, tr.End)
-	mean, _ := extractScalarValue(meanResult)
+	meanQuery := qb.BuildServiceMeanLatencyQuery(deployment, namespace, window)
+	meanResult := c.optionalQuery(ctx, warnings, "mean latency", meanQuery, tr.End)
+	mean := extractOptionalValue(meanResult)
 
 	// Errors by status
-	errorsByStatusQuery := c.queryBuilder.BuildErrorsByStatusQuery(deployment, namespace, window)
-	errorsByStatusResult, _ := c.promClient.Query(ctx, errorsByStatusQuery, tr.End)
+	errorsByStatusQuery := qb.BuildErrorsByStatusQuery(deployment, namespace, window)
+	errorsByStatusResult := c.optionalQuery(ctx, warnings, "errors by status", errorsByStatusQuery, tr.End)
 	errorsByStatus := c.extractErrorsByStatus(errorsByStatusResult)
 
+	grpcErrorsByStatusQuery := qb.BuildGRPCErrorsByStatusQuery(deployment, namespace, window)
+	grpcErrorsByStatusResult := c.optionalQuery(ctx, warnings, "gRPC errors by status", grpcErrorsByStatusQuery, tr.End)
+	grpcErrorsByStatus := c.extractGRPCErrorsByStatus(grpcErrorsByStatusResult)
+
+	// Per-dependency traffic
+	topDestinations := c.topDestinations(ctx, warnings, deployment, namespace, window, tr.End)
+	topSources := c.topSources(ctx, warnings, deployment, namespace, window, tr.End)
+
 	metrics := ServiceMetrics{
-		Service:     service,
-		Namespace:   namespace,
-		Deployment:  deployment,
-		TimeRange:   tr,
-		RequestRate: requestRate,
-		SuccessRate: successRate * 100, // Convert to percentage
-		ErrorRate:   errorRate * 100,   // Convert to percentage
+		Service:       service,
+		Namespace:     namespace,
+		Deployment:    deployment,
+		Direction:     direction,
+		TimeRange:     tr,
+		DataAvailable: requestRate != nil,
+		RequestRate:   requestRate,
+		SuccessRate:   scaleOptional(successRate, 100), // Convert to percentage
+		ErrorRate:     scaleOptional(errorRate, 100),   // Convert to percentage
+		RetryRate:     retryRate,
+		TimeoutRate:   timeoutRate,
 		Latency: LatencyMetrics{
 			P50:  p50,
 			P95:  p95,
 			P99:  p99,
 			Mean: mean,
 		},
-		ErrorsByStatus: errorsByStatus,
-	}
-
-	data, err := json.Marshal(metrics)
-	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal metrics: %v", err)), nil
+		TopDestinations:    topDestinations,
+		TopSources:         topSources,
+		ErrorsByStatus:     errorsByStatus,
+		GRPCErrorsByStatus: grpcErrorsByStatus,
+		Warnings:           warnings.list(),
 	}
+	metrics.Partial = len(metrics.Warnings) > 0
 
-	return mcp.NewToolResultText(string(data)), nil
+	return &metrics, nil
 }
 
 // AnalyzeTrafficFlow analyzes traffic between two services
 func (c *MetricsCollector) AnalyzeTrafficFlow(ctx context.Context, sourceNs, sourceService, targetNs, targetService, timeRangeStr string) (*mcp.CallToolResult, error) {
+	if !c.Available() {
+		return unavailableResult(), nil
+	}
+
 	// Parse time range
 	tr, err := ParseTimeRange(timeRangeStr)
 	if err != nil {
@@ -214,73 +322,147 @@ func (c *MetricsCollector) AnalyzeTrafficFlow(ctx context.Context, sourceNs, sou
 
 // GetServiceHealthSummary gets health summary for services in a namespace
 func (c *MetricsCollector) GetServiceHealthSummary(ctx context.Context, namespace, timeRangeStr string, thresholds HealthThresholds) (*mcp.CallToolResult, error) {
+	if !c.Available() {
+		return unavailableResult(), nil
+	}
+
 	// Parse time range
 	tr, err := ParseTimeRange(timeRangeStr)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("Invalid time range: %v", err)), nil
 	}
 
-	// Get all services in namespace
-	services, err := c.findAllServicesInNamespace(ctx, namespace)
+	if err := thresholds.Validate(); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Invalid health thresholds: %v", err)), nil
+	}
+
+	// Without Prometheus every service would look like it is failing all of its requests
+	if err := c.CheckHealth(ctx, healthPreflightTimeout); err != nil {
+		return c.metricsUnavailableSummary(ctx, namespace, tr, err)
+	}
+
+	summaries, warnings, err := c.ServiceHealthSummaries(ctx, namespace, tr, thresholds)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to find services: %v", err)), nil
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to collect service health: %v", err)), nil
+	}
+
+	result := map[string]interface{}{
+		"namespace":        namespace,
+		"timeRange":        tr,
+		"metricsAvailable": true,
+		"services":         summaries,
+		"partial":          len(warnings) > 0,
+	}
+	if len(warnings) > 0 {
+		result["warnings"] = warnings
+	}
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal summary: %v", err)), nil
 	}
 
+	return mcp.NewToolResultText(string(data)), nil
+}
+
+// healthPreflightTimeout bounds the Prometheus probe preceding the health summary
+const healthPreflightTimeout = 5 * time.Second
+
+// metricsUnavailableSummary is the health summary when Prometheus can't be queried: the
+// namespace's Kubernetes services, all of unknown health, rather than verdicts made up from
+// missing metrics
+func (c *MetricsCollector) metricsUnavailableSummary(ctx context.Context, namespace string, tr TimeRange, probeErr error) (*mcp.CallToolResult, error) {
+	reason := fmt.Sprintf("Prometheus is unreachable, service health can't be assessed: %v", probeErr)
+
 	summaries := []ServiceHealthSummary{}
-	window := tr.End.Sub(tr.Start)
+	if c.clientset != nil {
+		services, err := c.clientset.CoreV1().Services(namespace).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("%s; failed to list services: %v", reason, err)), nil
+		}
+		for _, svc := range services.Items {
+			summaries = append(summaries, ServiceHealthSummary{
+				Service:      svc.Name,
+				Namespace:    namespace,
+				HealthStatus: HealthStatusUnknown,
+				Issues: []HealthIssue{{
+					Severity:    "info",
+					Description: "Metrics are unavailable",
+					Metric:      "prometheus",
+				}},
+			})
+		}
+	}
 
-	for _, svc := range services {
-		deployment := svc // For Linkerd, deployment name often matches service name
+	data, err := json.Marshal(map[string]interface{}{
+		"namespace":        namespace,
+		"timeRange":        tr,
+		"metricsAvailable": false,
+		"reason":           reason,
+		"services":         summaries,
+	})
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal summary: %v", err)), nil
+	}
+
+	return mcp.NewToolResultText(string(data)), nil
+}
 
-		// Get basic metrics
-		reqRateQuery := c.queryBuilder.BuildServiceRequestRateQuery(deployment, namespace, window)
-		reqRateResult, _ := c.promClient.Query(ctx, reqRateQuery, tr.End)
-		requestRate, _ := extractScalarValue(reqRateResult)
+// ServiceHealthSummaries assesses the health of every service with inbound traffic in a
+// namespace. Queries failing for single services don't fail the summaries; they are returned as
+// warnings and the metrics they would have provided are null and left unchecked.
+func (c *MetricsCollector) ServiceHealthSummaries(ctx context.Context, namespace string, tr TimeRange, thresholds HealthThresholds) ([]ServiceHealthSummary, []string, error) {
+	if !c.Available() {
+		return nil, nil, fmt.Errorf("metrics collector is not configured")
+	}
 
-		successRateQuery := c.queryBuilder.BuildServiceSuccessRateQuery(deployment, namespace, window)
-		successRateResult, _ := c.promClient.Query(ctx, successRateQuery, tr.End)
-		successRate, _ := extractScalarValue(successRateResult)
+	// Get all services in namespace
+	services, err := c.findAllServicesInNamespace(ctx, namespace)
+	if err != nil {
+		return nil, nil, err
+	}
 
-		errorRateQuery := c.queryBuilder.BuildServiceErrorRateQuery(deployment, namespace, window)
-		errorRateResult, _ := c.promClient.Query(ctx, errorRateQuery, tr.End)
-		errorRate, _ := extractScalarValue(errorRateResult)
+	warnings := &queryWarnings{}
+	snapshots, err := c.serviceSnapshots(ctx, warnings, namespace, services, tr, thresholds.SplitsStatusClasses())
+	if err != nil {
+		return nil, nil, err
+	}
 
-		p95Query := c.queryBuilder.BuildServiceLatencyQuery(deployment, namespace, 0.95, window)
-		p95Result, _ := c.promClient.Query(ctx, p95Query, tr.End)
-		p95, _ := extractScalarValue(p95Result)
+	summaries := []ServiceHealthSummary{}
+	for i, svc := range services {
+		snapshot := snapshots[i]
 
 		// Assess health
-		status, issues := c.assessHealth(requestRate, successRate*100, errorRate*100, p95, thresholds)
+		status, issues := c.assessHealth(snapshot, thresholds)
 
 		summary := ServiceHealthSummary{
 			Service:      svc,
 			Namespace:    namespace,
-			Deployment:   deployment,
+			Deployment:   svc, // For Linkerd, deployment name often matches service name
 			HealthStatus: status,
-			RequestRate:  requestRate,
-			SuccessRate:  successRate * 100,
-			ErrorRate:    errorRate * 100,
-			LatencyP95:   p95,
+			RequestRate:  snapshot.requestRate,
+			SuccessRate:  scaleOptional(snapshot.successRate, 100),
+			ErrorRate:    scaleOptional(snapshot.errorRate, 100),
+			LatencyP95:   snapshot.latencyP95,
 			Issues:       issues,
 		}
+		if thresholds.SplitsStatusClasses() {
+			summary.ClientErrorRate = scaleOptional(snapshot.clientErrorRate, 100)
+			summary.ServerErrorRate = scaleOptional(snapshot.serverErrorRate, 100)
+		}
 
 		summaries = append(summaries, summary)
 	}
 
-	data, err := json.Marshal(map[string]interface{}{
-		"namespace": namespace,
-		"timeRange": tr,
-		"services":  summaries,
-	})
-	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal summary: %v", err)), nil
-	}
-
-	return mcp.NewToolResultText(string(data)), nil
+	return summaries, warnings.list(), nil
 }
 
 // GetTopServices returns top services ranked by a metric
 func (c *MetricsCollector) GetTopServices(ctx context.Context, namespace, sortBy, timeRangeStr string, limit int) (*mcp.CallToolResult, error) {
+	if !c.Available() {
+		return unavailableResult(), nil
+	}
+
 	// Parse time range
 	tr, err := ParseTimeRange(timeRangeStr)
 	if err != nil {
@@ -293,37 +475,24 @@ func (c *MetricsCollector) GetTopServices(ctx context.Context, namespace, sortBy
 		return mcp.NewToolResultError(fmt.Sprintf("Failed to find services: %v", err)), nil
 	}
 
-	summaries := []ServiceMetricSummary{}
-	window := tr.End.Sub(tr.Start)
-
-	for _, svc := range services {
-		deployment := svc
-
-		// Get metrics
-		reqRateQuery := c.queryBuilder.BuildServiceRequestRateQuery(deployment, namespace, window)
-		reqRateResult, _ := c.promClient.Query(ctx, reqRateQuery, tr.End)
-		requestRate, _ := extractScalarValue(reqRateResult)
-
-		successRateQuery := c.queryBuilder.BuildServiceSuccessRateQuery(deployment, namespace, window)
-		successRateResult, _ := c.promClient.Query(ctx, successRateQuery, tr.End)
-		successRate, _ := extractScalarValue(successRateResult)
-
-		errorRateQuery := c.queryBuilder.BuildServiceErrorRateQuery(deployment, namespace, window)
-		errorRateResult, _ := c.promClient.Query(ctx, errorRateQuery, tr.End)
-		errorRate, _ := extractScalarValue(errorRateResult)
+	warnings := &queryWarnings{}
+	snapshots, err := c.serviceSnapshots(ctx, warnings, namespace, services, tr, false)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to collect service metrics: %v", err)), nil
+	}
 
-		p95Query := c.queryBuilder.BuildServiceLatencyQuery(deployment, namespace, 0.95, window)
-		p95Result, _ := c.promClient.Query(ctx, p95Query, tr.End)
-		p95, _ := extractScalarValue(p95Result)
+	summaries := []ServiceMetricSummary{}
+	for i, svc := range services {
+		snapshot := snapshots[i]
 
 		summary := ServiceMetricSummary{
 			Service:     svc,
 			Namespace:   namespace,
-			Deployment:  deployment,
-			RequestRate: requestRate,
-			SuccessRate: successRate * 100,
-			ErrorRate:   errorRate * 100,
-			LatencyP95:  p95,
+			Deployment:  svc,
+			RequestRate: snapshot.requestRate,
+			SuccessRate: scaleOptional(snapshot.successRate, 100),
+			ErrorRate:   scaleOptional(snapshot.errorRate, 100),
+			LatencyP95:  snapshot.latencyP95,
 		}
 
 		summaries = append(summaries, summary)
@@ -337,7 +506,9 @@ func (c *MetricsCollector) GetTopServices(ctx context.Context, namespace, sortBy
 	ranking := ServiceRanking{
 		SortBy:   sortBy,
 		Services: summaries,
+		Warnings: warnings.list(),
 	}
+	ranking.Partial = len(ranking.Warnings) > 0
 
 	data, err := json.Marshal(ranking)
 	if err != nil {
@@ -394,62 +565,122 @@ func (c *MetricsCollector) extractErrorsByStatus(value model.Value) map[string]i
 	return errors
 }
 
-func (c *MetricsCollector) assessHealth(requestRate, successRate, errorRate, latencyP95 float64, thresholds HealthThresholds) (HealthStatus, []HealthIssue) {
+// extractGRPCErrorsByStatus maps gRPC status codes to their number of failed responses.
+// increase() extrapolates, so counts are rounded to the nearest integer.
+func (c *MetricsCollector) extractGRPCErrorsByStatus(value model.Value) map[string]int64 {
+	vector, ok := value.(model.Vector)
+	if !ok {
+		return map[string]int64{}
+	}
+
+	errors := map[string]int64{}
+	for _, sample := range vector {
+		status, ok := sample.Metric["grpc_status"]
+		if !ok || math.IsNaN(float64(sample.Value)) {
+			continue
+		}
+		if count := int64(math.Round(float64(sample.Value))); count > 0 {
+			errors[string(status)] = count
+		}
+	}
+
+	return errors
+}
+
+// assessHealth compares a service's metrics against the thresholds. Without a request rate, or
+// below the minimum request rate where a handful of failures would dominate the rates, the status
+// is unknown. Any other metric without data is reported as an info issue and left unchecked, and
+// unless a checked metric exceeds its thresholds the status is unknown rather than healthy. When
+// the thresholds split errors by status class, the 4xx and 5xx rates are checked against their
+// own thresholds in place of the combined error rate.
+func (c *MetricsCollector) assessHealth(snapshot serviceSnapshot, thresholds HealthThresholds) (HealthStatus, []HealthIssue) {
 	issues := []HealthIssue{}
+	successRate, errorRate := scaleOptional(snapshot.successRate, 100), scaleOptional(snapshot.errorRate, 100)
+	latencyP95 := snapshot.latencyP95
+
+	if snapshot.requestRate == nil {
+		return HealthStatusUnknown, append(issues, unavailableIssue("request_rate", "Request rate is unavailable, so health can't be assessed"))
+	}
+	if requestRate := *snapshot.requestRate; requestRate < thresholds.MinRequestRate {
+		issues = append(issues, HealthIssue{
+			Severity:    "info",
+			Description: "Request rate is below the minimum needed to assess health",
+			Metric:      "request_rate",
+			Value:       requestRate,
+			Threshold:   thresholds.MinRequestRate,
+		})
+		return HealthStatusUnknown, issues
+	}
 
 	// Check error rate
-	if errorRate >= thresholds.ErrorRateCritical {
+	switch {
+	case thresholds.SplitsStatusClasses() && (snapshot.clientErrorRate == nil || snapshot.serverErrorRate == nil):
+		issues = append(issues, unavailableIssue("response_status", "Responses by status are unavailable, so the 4xx and 5xx rates weren't checked"))
+	case thresholds.SplitsStatusClasses():
+		issues = append(issues, statusClassIssues("client_error_rate", "Client error (4xx) rate", *snapshot.clientErrorRate*100,
+			thresholds.ClientErrorRateWarning, thresholds.ClientErrorRateCritical)...)
+		issues = append(issues, statusClassIssues("server_error_rate", "Server error (5xx) rate", *snapshot.serverErrorRate*100,
+			thresholds.ServerErrorRateWarning, thresholds.ServerErrorRateCritical)...)
+	case errorRate == nil:
+		issues = append(issues, unavailableIssue("error_rate", "Error rate is unavailable, so it wasn't checked"))
+	case *errorRate >= thresholds.ErrorRateCritical:
 		issues = append(issues, HealthIssue{
 			Severity:    "critical",
 			Description: "Error rate exceeds critical threshold",
 			Metric:      "error_rate",
-			Value:       errorRate,
+			Value:       *errorRate,
 			Threshold:   thresholds.ErrorRateCritical,
 		})
-	} else if errorRate >= thresholds.ErrorRateWarning {
+	case *errorRate >= thresholds.ErrorRateWarning:
 		issues = append(issues, HealthIssue{
 			Severity:    "warning",
 			Description: "Error rate exceeds warning threshold",
 			Metric:      "error_rate",
-			Value:       errorRate,
+			Value:       *errorRate,
 			Threshold:   thresholds.ErrorRateWarning,
 		})
 	}
 
 	// Check latency
-	if latencyP95 >= thresholds.LatencyP95Critical {
+	switch {
+	case latencyP95 == nil:
+		issues = append(issues, unavailableIssue("latency_p95", "P95 latency is unavailable, so it wasn't checked"))
+	case *latencyP95 >= thresholds.LatencyP95Critical:
 		issues = append(issues, HealthIssue{
 			Severity:    "critical",
 			Description: "P95 latency exceeds critical threshold",
 			Metric:      "latency_p95",
-			Value:       latencyP95,
+			Value:       *latencyP95,
 			Threshold:   thresholds.LatencyP95Critical,
 		})
-	} else if latencyP95 >= thresholds.LatencyP95Warning {
+	case *latencyP95 >= thresholds.LatencyP95Warning:
 		issues = append(issues, HealthIssue{
 			Severity:    "warning",
 			Description: "P95 latency exceeds warning threshold",
 			Metric:      "latency_p95",
-			Value:       latencyP95,
+			Value:       *latencyP95,
 			Threshold:   thresholds.LatencyP95Warning,
 		})
 	}
 
 	// Check success rate
-	if successRate <= thresholds.SuccessRateCritical {
+	switch {
+	case successRate == nil:
+		issues = append(issues, unavailableIssue("success_rate", "Success rate is unavailable, so it wasn't checked"))
+	case *successRate <= thresholds.SuccessRateCritical:
 		issues = append(issues, HealthIssue{
 			Severity:    "critical",
 			Description: "Success rate below critical threshold",
 			Metric:      "success_rate",
-			Value:       successRate,
+			Value:       *successRate,
 			Threshold:   thresholds.SuccessRateCritical,
 		})
-	} else if successRate <= thresholds.SuccessRateWarning {
+	case *successRate <= thresholds.SuccessRateWarning:
 		issues = append(issues, HealthIssue{
 			Severity:    "warning",
 			Description: "Success rate below warning threshold",
 			Metric:      "success_rate",
-			Value:       successRate,
+			Value:       *successRate,
 			Threshold:   thresholds.SuccessRateWarning,
 		})
 	}
@@ -457,12 +688,15 @@ func (c *MetricsCollector) assessHealth(requestRate, successRate, errorRate, lat
 	// Determine overall status
 	hasCritical := false
 	hasWarning := false
+	hasUnchecked := false
 	for _, issue := range issues {
 		switch issue.Severity {
 		case "critical":
 			hasCritical = true
 		case "warning":
 			hasWarning = true
+		case "info":
+			hasUnchecked = true
 		}
 	}
 
@@ -470,7 +704,38 @@ func (c *MetricsCollector) assessHealth(requestRate, successRate, errorRate, lat
 		return HealthStatusUnhealthy, issues
 	} else if hasWarning {
 		return HealthStatusDegraded, issues
+	} else if hasUnchecked {
+		return HealthStatusUnknown, issues
 	}
 
 	return HealthStatusHealthy, issues
 }
+
+// unavailableIssue reports a metric whose query failed or returned no data
+func unavailableIssue(metric, description string) HealthIssue {
+	return HealthIssue{Severity: "info", Description: description, Metric: metric}
+}
+
+// statusClassIssues checks the rate of a status class against its thresholds. Unset (zero)
+// thresholds aren't checked.
+func statusClassIssues(metric, label string, rate, warning, critical float64) []HealthIssue {
+	if critical > 0 && rate >= critical {
+		return []HealthIssue{{
+			Severity:    "critical",
+			Description: label + " exceeds critical threshold",
+			Metric:      metric,
+			Value:       rate,
+			Threshold:   critical,
+		}}
+	}
+	if warning > 0 && rate >= warning {
+		return []HealthIssue{{
+			Severity:    "warning",
+			Description: label + " exceeds warning threshold",
+			Metric:      metric,
+			Value:       rate,
+			Threshold:   warning,
+		}}
+	}
+	return nil
+}
@@ -0,0 +1,589 @@
+package metrics_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/christianhuening/linkerd-mcp/internal/metrics"
+	"github.com/christianhuening/linkerd-mcp/internal/tap"
+	"github.com/christianhuening/linkerd-mcp/internal/testutil"
+	"github.com/mark3labs/mcp-go/mcp"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+var _ = Describe("MetricsCollector", func() {
+	Context("when the collector is nil", func() {
+		var (
+			ctx       context.Context
+			collector *metrics.MetricsCollector
+		)
+
+		BeforeEach(func() {
+			ctx = context.Background()
+			collector = nil
+		})
+
+		expectUnavailable := func(result *mcp.CallToolResult, err error) {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result).NotTo(BeNil())
+			Expect(result.IsError).To(BeTrue())
+
+			var status map[string]interface{}
+			Expect(testutil.ParseJSONResult(result, &status)).To(Succeed())
+			Expect(status["status"]).To(Equal("unavailable"))
+		}
+
+		It("should report itself as unavailable", func() {
+			Expect(collector.Available()).To(BeFalse())
+		})
+
+		It("should not panic in GetServiceMetrics", func() {
+			expectUnavailable(collector.GetServiceMetrics(ctx, "default", "frontend", "5m", ""))
+		})
+
+		It("should not panic in GetRouteMetrics", func() {
+			expectUnavailable(collector.GetRouteMetrics(ctx, "default", "frontend", "5m"))
+		})
+
+		It("should not panic in GetErrorsDetail", func() {
+			expectUnavailable(collector.GetErrorsDetail(ctx, "default", "frontend", "5m", 0, nil, 0))
+		})
+
+		It("should not panic in GetMTLSCoverage", func() {
+			expectUnavailable(collector.GetMTLSCoverage(ctx, "default", "", "5m", true))
+		})
+
+		It("should not panic in AnalyzeTrafficFlow", func() {
+			expectUnavailable(collector.AnalyzeTrafficFlow(ctx, "default", "frontend", "default", "backend", "5m"))
+		})
+
+		It("should not panic in GetServiceHealthSummary", func() {
+			expectUnavailable(collector.GetServiceHealthSummary(ctx, "default", "5m", metrics.DefaultHealthThresholds()))
+		})
+
+		It("should not panic in GetTopServices", func() {
+			expectUnavailable(collector.GetTopServices(ctx, "default", "request_rate", "5m", 10))
+		})
+
+		It("should not panic in DetectTrafficAnomalies", func() {
+			expectUnavailable(collector.DetectTrafficAnomalies(ctx, "default", "frontend", "1h", "", 3))
+		})
+
+		It("should not panic in GetTrafficSplit", func() {
+			expectUnavailable(collector.GetTrafficSplit(ctx, "default", "frontend", "5m", 10))
+		})
+
+		It("should not panic in GetProxyResourceUsage", func() {
+			expectUnavailable(collector.GetProxyResourceUsage(ctx, "default", "5m"))
+		})
+
+		It("should not panic in EvaluateSLO", func() {
+			expectUnavailable(collector.EvaluateSLO(ctx, "default", "frontend", "1h", "", metrics.SLOTargets{}))
+		})
+
+		It("should not panic in CompareServiceMetrics", func() {
+			expectUnavailable(collector.CompareServiceMetrics(ctx, "default", "frontend", "1h", "", "", metrics.DefaultRegressionThreshold))
+		})
+
+		It("should not panic in GetTrafficThroughput", func() {
+			expectUnavailable(collector.GetTrafficThroughput(ctx, "default", "frontend", "default", "backend", "5m"))
+		})
+	})
+
+	Context("with a Prometheus backend", func() {
+		var ctx context.Context
+
+		// collectorFor serves every query from values, keyed by a substring of the PromQL. Values
+		// are either a single unlabeled sample, starting with "[" a raw vector result, or "error" to
+		// fail the query. Queries without a matching key get an empty vector.
+		collectorFor := func(values map[string]string) *metrics.MetricsCollector {
+			prometheus := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				Expect(r.ParseForm()).To(Succeed())
+				result := "[]"
+				for fragment, value := range values {
+					if !strings.Contains(r.Form.Get("query"), fragment) {
+						continue
+					}
+					result = value
+					if !strings.HasPrefix(value, "[") && value != "error" {
+						result = `[{"metric":{},"value":[1700000000,"` + value + `"]}]`
+					}
+				}
+				w.Header().Set("Content-Type", "application/json")
+				if result == "error" {
+					w.WriteHeader(http.StatusUnprocessableEntity)
+					_, _ = w.Write([]byte(`{"status":"error","errorType":"execution","error":"query timed out"}`))
+					return
+				}
+				_, _ = w.Write([]byte(`{"status":"success","data":{"resultType":"vector","result":` + result + `}}`))
+			}))
+			DeferCleanup(prometheus.Close)
+
+			os.Setenv("LINKERD_PROMETHEUS_URL", prometheus.URL)
+			DeferCleanup(os.Unsetenv, "LINKERD_PROMETHEUS_URL")
+
+			collector, err := metrics.NewMetricsCollector(nil, nil, nil, "linkerd")
+			Expect(err).NotTo(HaveOccurred())
+			return collector
+		}
+
+		parseServiceMetrics := func(collector *metrics.MetricsCollector) map[string]interface{} {
+			result, err := collector.GetServiceMetrics(ctx, "default", "frontend", "5m", "")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.IsError).To(BeFalse())
+
+			var serviceMetrics map[string]interface{}
+			Expect(testutil.ParseJSONResult(result, &serviceMetrics)).To(Succeed())
+			return serviceMetrics
+		}
+
+		BeforeEach(func() {
+			ctx = context.Background()
+		})
+
+		It("should report missing data as null rather than zero", func() {
+			serviceMetrics := parseServiceMetrics(collectorFor(map[string]string{}))
+
+			Expect(serviceMetrics["dataAvailable"]).To(BeFalse())
+			Expect(serviceMetrics).To(HaveKeyWithValue("requestRate", BeNil()))
+			Expect(serviceMetrics).To(HaveKeyWithValue("successRate", BeNil()))
+			Expect(serviceMetrics).To(HaveKeyWithValue("errorRate", BeNil()))
+			Expect(serviceMetrics["latency"]).To(HaveKeyWithValue("p95", BeNil()))
+			Expect(serviceMetrics).NotTo(HaveKey("retryRate"))
+			Expect(serviceMetrics).NotTo(HaveKey("timeoutRate"))
+		})
+
+		It("should report retries and timeouts when routes configure them", func() {
+			serviceMetrics := parseServiceMetrics(collectorFor(map[string]string{
+				"sum(rate(request_total":     "5",
+				"route_actual_request_total": "1.5",
+				`status_code="504"`:          "0.2",
+			}))
+
+			Expect(serviceMetrics["retryRate"]).To(BeNumerically("==", 1.5))
+			Expect(serviceMetrics["timeoutRate"]).To(BeNumerically("==", 0.2))
+		})
+
+		It("should keep real zeros and drop NaN latencies", func() {
+			serviceMetrics := parseServiceMetrics(collectorFor(map[string]string{
+				"sum(rate(request_total":  "2",
+				"classification":          "0",
+				"histogram_quantile":      "NaN",
+				"response_latency_ms_sum": "12.5",
+			}))
+
+			Expect(serviceMetrics["dataAvailable"]).To(BeTrue())
+			Expect(serviceMetrics["requestRate"]).To(BeNumerically("==", 2))
+			Expect(serviceMetrics["errorRate"]).To(BeNumerically("==", 0))
+			latency := serviceMetrics["latency"].(map[string]interface{})
+			Expect(latency).To(HaveKeyWithValue("p50", BeNil()))
+			Expect(latency).To(HaveKeyWithValue("p99", BeNil()))
+			Expect(latency["mean"]).To(BeNumerically("==", 12.5))
+		})
+
+		It("should break down gRPC failures by status code", func() {
+			serviceMetrics := parseServiceMetrics(collectorFor(map[string]string{
+				"sum(rate(request_total": "5",
+				"by (grpc_status)": `[{"metric":{"grpc_status":"14"},"value":[1700000000,"41.8"]},` +
+					`{"metric":{"grpc_status":"4"},"value":[1700000000,"3"]},` +
+					`{"metric":{"grpc_status":"2"},"value":[1700000000,"0.2"]}]`,
+			}))
+
+			Expect(serviceMetrics["grpcErrorsByStatus"]).To(Equal(map[string]interface{}{"14": float64(42), "4": float64(3)}))
+			Expect(serviceMetrics).NotTo(HaveKey("errorsByStatus"))
+		})
+
+		It("should report failed optional queries as warnings", func() {
+			serviceMetrics := parseServiceMetrics(collectorFor(map[string]string{
+				"sum(rate(request_total": "5",
+				"histogram_quantile":     "error",
+			}))
+
+			Expect(serviceMetrics["partial"]).To(BeTrue())
+			Expect(serviceMetrics["warnings"]).To(ContainElements(
+				ContainSubstring("failed to query p95 latency"),
+				ContainSubstring("failed to query destination latencies"),
+			))
+			Expect(serviceMetrics["requestRate"]).To(BeNumerically("==", 5))
+		})
+
+		It("should not flag complete metrics as partial", func() {
+			serviceMetrics := parseServiceMetrics(collectorFor(map[string]string{"sum(rate(request_total": "5"}))
+
+			Expect(serviceMetrics["partial"]).To(BeFalse())
+			Expect(serviceMetrics).NotTo(HaveKey("warnings"))
+		})
+
+		It("should fail when the request rate can't be queried", func() {
+			result, err := collectorFor(map[string]string{"request_total": "error"}).GetServiceMetrics(ctx, "default", "frontend", "5m", "")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.IsError).To(BeTrue())
+		})
+
+		It("should measure outbound requests when asked to", func() {
+			collector := collectorFor(map[string]string{
+				`direction="inbound"`:  "1",
+				`direction="outbound"`: "7",
+			})
+
+			result, err := collector.GetServiceMetrics(ctx, "default", "frontend", "5m", "outbound")
+			Expect(err).NotTo(HaveOccurred())
+
+			var serviceMetrics map[string]interface{}
+			Expect(testutil.ParseJSONResult(result, &serviceMetrics)).To(Succeed())
+			Expect(serviceMetrics["direction"]).To(Equal("outbound"))
+			Expect(serviceMetrics["requestRate"]).To(BeNumerically("==", 7))
+		})
+
+		It("should reject an unknown direction", func() {
+			result, err := collectorFor(map[string]string{}).GetServiceMetrics(ctx, "default", "frontend", "5m", "sideways")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.IsError).To(BeTrue())
+		})
+
+		It("should report the throughput between two services with totals over the window", func() {
+			collector := collectorFor(map[string]string{
+				"request_bytes_total":  "1024",
+				"response_bytes_total": "4096",
+			})
+
+			result, err := collector.GetTrafficThroughput(ctx, "default", "frontend", "default", "backend", "5m")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.IsError).To(BeFalse())
+
+			var throughput map[string]interface{}
+			Expect(testutil.ParseJSONResult(result, &throughput)).To(Succeed())
+			Expect(throughput["dataAvailable"]).To(BeTrue())
+			Expect(throughput["bytesSentPerSecond"]).To(BeNumerically("==", 1024))
+			Expect(throughput["bytesReceivedPerSecond"]).To(BeNumerically("==", 4096))
+			Expect(throughput["totalBytesSent"]).To(BeNumerically("~", 1024*300, 1))
+			Expect(throughput["totalBytesReceived"]).To(BeNumerically("~", 4096*300, 1))
+		})
+
+		It("should report throughput without traffic as null", func() {
+			result, err := collectorFor(map[string]string{}).GetTrafficThroughput(ctx, "default", "frontend", "default", "backend", "5m")
+			Expect(err).NotTo(HaveOccurred())
+
+			var throughput map[string]interface{}
+			Expect(testutil.ParseJSONResult(result, &throughput)).To(Succeed())
+			Expect(throughput["dataAvailable"]).To(BeFalse())
+			Expect(throughput).To(HaveKeyWithValue("bytesSentPerSecond", BeNil()))
+			Expect(throughput).To(HaveKeyWithValue("totalBytesReceived", BeNil()))
+		})
+
+		Context("when assessing service health", func() {
+			healthSummary := func(requestRate string) metrics.ServiceHealthSummary {
+				collector := collectorFor(map[string]string{
+					"count(request_total":       `[{"metric":{"deployment":"frontend"},"value":[1700000000,"1"]}]`,
+					"sum(rate(request_total":    requestRate,
+					`classification!="failure"`: "0.67",
+					`classification="failure"`:  "0.33",
+				})
+
+				summaries, _, err := collector.ServiceHealthSummaries(ctx, "default", metrics.TimeRange{}, metrics.DefaultHealthThresholds())
+				Expect(err).NotTo(HaveOccurred())
+				Expect(summaries).To(HaveLen(1))
+				return summaries[0]
+			}
+
+			It("should report failures of a busy service as unhealthy", func() {
+				summary := healthSummary("5")
+				Expect(summary.HealthStatus).To(Equal(metrics.HealthStatusUnhealthy))
+				Expect(summary.ClientErrorRate).To(BeNil())
+				Expect(summary.ServerErrorRate).To(BeNil())
+			})
+
+			It("should check 4xx and 5xx rates against their own thresholds when they are set", func() {
+				collector := collectorFor(map[string]string{
+					"count(request_total":       `[{"metric":{"deployment":"frontend"},"value":[1700000000,"1"]}]`,
+					"sum(rate(request_total":    "5",
+					`classification!="failure"`: "0.98",
+					`classification="failure"`:  "0.02",
+					"histogram_quantile":        "40",
+					"by (http_status)": `[{"metric":{"http_status":"200"},"value":[1700000000,"80"]},` +
+						`{"metric":{"http_status":"404"},"value":[1700000000,"18"]},` +
+						`{"metric":{"http_status":"503"},"value":[1700000000,"2"]}]`,
+				})
+				thresholds := metrics.DefaultHealthThresholds()
+				thresholds.ClientErrorRateWarning = 20
+				thresholds.ClientErrorRateCritical = 50
+				thresholds.ServerErrorRateWarning = 1
+				thresholds.ServerErrorRateCritical = 5
+
+				summaries, _, err := collector.ServiceHealthSummaries(ctx, "default", metrics.TimeRange{}, thresholds)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(summaries).To(HaveLen(1))
+
+				summary := summaries[0]
+				Expect(*summary.ClientErrorRate).To(BeNumerically("~", 18, 1e-9))
+				Expect(*summary.ServerErrorRate).To(BeNumerically("~", 2, 1e-9))
+				Expect(summary.HealthStatus).To(Equal(metrics.HealthStatusDegraded))
+				Expect(summary.Issues).To(ConsistOf(And(
+					HaveField("Severity", "warning"),
+					HaveField("Metric", "server_error_rate"),
+					HaveField("Threshold", 1.0),
+				)))
+			})
+
+			It("should report a service with too little traffic as unknown", func() {
+				summary := healthSummary("0.01")
+				Expect(summary.HealthStatus).To(Equal(metrics.HealthStatusUnknown))
+				Expect(summary.Issues).To(ConsistOf(metrics.HealthIssue{
+					Severity:    "info",
+					Description: "Request rate is below the minimum needed to assess health",
+					Metric:      "request_rate",
+					Value:       0.01,
+					Threshold:   0.1,
+				}))
+			})
+
+			It("should leave metrics whose query failed unchecked instead of counting them as zero", func() {
+				collector := collectorFor(map[string]string{
+					"count(request_total":       `[{"metric":{"deployment":"frontend"},"value":[1700000000,"1"]}]`,
+					"sum(rate(request_total":    "5",
+					`classification!="failure"`: "error",
+					`classification="failure"`:  "0",
+					"histogram_quantile":        "40",
+				})
+
+				summaries, warnings, err := collector.ServiceHealthSummaries(ctx, "default", metrics.TimeRange{}, metrics.DefaultHealthThresholds())
+				Expect(err).NotTo(HaveOccurred())
+				Expect(warnings).To(ContainElement(ContainSubstring("frontend success rate")))
+				Expect(summaries).To(HaveLen(1))
+
+				summary := summaries[0]
+				Expect(summary.SuccessRate).To(BeNil())
+				Expect(*summary.ErrorRate).To(BeZero())
+				Expect(summary.HealthStatus).To(Equal(metrics.HealthStatusUnknown))
+				Expect(summary.Issues).To(ConsistOf(metrics.HealthIssue{
+					Severity:    "info",
+					Description: "Success rate is unavailable, so it wasn't checked",
+					Metric:      "success_rate",
+				}))
+			})
+
+			It("should still report a service whose available metrics exceed a threshold", func() {
+				collector := collectorFor(map[string]string{
+					"count(request_total":       `[{"metric":{"deployment":"frontend"},"value":[1700000000,"1"]}]`,
+					"sum(rate(request_total":    "5",
+					`classification!="failure"`: "0.5",
+					`classification="failure"`:  "0.5",
+				})
+
+				summaries, _, err := collector.ServiceHealthSummaries(ctx, "default", metrics.TimeRange{}, metrics.DefaultHealthThresholds())
+				Expect(err).NotTo(HaveOccurred())
+				Expect(summaries[0].LatencyP95).To(BeNil())
+				Expect(summaries[0].HealthStatus).To(Equal(metrics.HealthStatusUnhealthy))
+				Expect(summaries[0].Issues).To(ContainElement(HaveField("Metric", "latency_p95")))
+			})
+
+			It("should report every service as unknown when Prometheus is down", func() {
+				prometheus := httptest.NewServer(http.NotFoundHandler())
+				prometheus.Close()
+				os.Setenv("LINKERD_PROMETHEUS_URL", prometheus.URL)
+				DeferCleanup(os.Unsetenv, "LINKERD_PROMETHEUS_URL")
+
+				clientset := fake.NewSimpleClientset(
+					testutil.CreateService("frontend", "default", map[string]string{"app": "frontend"}),
+					testutil.CreateService("backend", "default", map[string]string{"app": "backend"}),
+				)
+				collector, err := metrics.NewMetricsCollector(nil, clientset, nil, "linkerd")
+				Expect(err).NotTo(HaveOccurred())
+
+				result, err := collector.GetServiceHealthSummary(ctx, "default", "5m", metrics.DefaultHealthThresholds())
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result.IsError).To(BeFalse())
+
+				var summary map[string]interface{}
+				Expect(testutil.ParseJSONResult(result, &summary)).To(Succeed())
+				Expect(summary["metricsAvailable"]).To(BeFalse())
+				Expect(summary["reason"]).To(ContainSubstring("Prometheus is unreachable"))
+				services := summary["services"].([]interface{})
+				Expect(services).To(HaveLen(2))
+				for _, service := range services {
+					Expect(service).To(HaveKeyWithValue("healthStatus", "unknown"))
+				}
+			})
+
+			It("should report failed queries of a service as warnings", func() {
+				collector := collectorFor(map[string]string{
+					"count(request_total":    `[{"metric":{"deployment":"frontend"},"value":[1700000000,"1"]}]`,
+					"sum(rate(request_total": "5",
+					"histogram_quantile":     "error",
+				})
+
+				result, err := collector.GetServiceHealthSummary(ctx, "default", "5m", metrics.DefaultHealthThresholds())
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result.IsError).To(BeFalse())
+
+				var summary map[string]interface{}
+				Expect(testutil.ParseJSONResult(result, &summary)).To(Succeed())
+				Expect(summary["partial"]).To(BeTrue())
+				Expect(summary["warnings"]).To(ConsistOf(ContainSubstring("failed to query frontend p95 latency")))
+			})
+		})
+
+		Describe("GetErrorsDetail", func() {
+			parseErrorsDetail := func(collector *metrics.MetricsCollector, sampler metrics.FailureSampler) metrics.ErrorsDetail {
+				result, err := collector.GetErrorsDetail(ctx, "default", "frontend", "1h", 2, sampler, time.Second)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result.IsError).To(BeFalse())
+
+				var detail metrics.ErrorsDetail
+				Expect(testutil.ParseJSONResult(result, &detail)).To(Succeed())
+				return detail
+			}
+
+			It("should break failures down by route and status code", func() {
+				collector := collectorFor(map[string]string{
+					"by (rt_route, status_code)": `[{"metric":{"rt_route":"POST /checkout","status_code":"503"},"value":[1700000000,"41.6"]},` +
+						`{"metric":{"rt_route":"GET /books","status_code":"500"},"value":[1700000000,"3"]},` +
+						`{"metric":{"rt_route":"GET /books","status_code":"502"},"value":[1700000000,"1"]}]`,
+				})
+
+				detail := parseErrorsDetail(collector, nil)
+
+				Expect(detail.ByRoute).To(BeTrue())
+				Expect(detail.TotalFailures).To(Equal(46))
+				Expect(detail.Failures).To(Equal([]metrics.RouteFailure{
+					{Route: "POST /checkout", StatusCode: "503", Count: 42},
+					{Route: "GET /books", StatusCode: "500", Count: 3},
+				}))
+				Expect(detail.TapUnavailable).NotTo(BeEmpty())
+			})
+
+			It("should fall back to HTTP statuses without route metrics", func() {
+				collector := collectorFor(map[string]string{
+					"by (http_status)": `[{"metric":{"http_status":"503"},"value":[1700000000,"7"]}]`,
+				})
+
+				detail := parseErrorsDetail(collector, nil)
+
+				Expect(detail.ByRoute).To(BeFalse())
+				Expect(detail.Failures).To(Equal([]metrics.RouteFailure{{Route: metrics.DefaultRouteName, StatusCode: "503", Count: 7}}))
+			})
+
+			It("should include the failing requests sampled through tap", func() {
+				sampler := &fakeFailureSampler{failures: []tap.FailedRequest{{Method: "POST", Path: "/checkout", HTTPStatus: 503, Count: 4}}}
+
+				detail := parseErrorsDetail(collectorFor(map[string]string{}), sampler)
+
+				Expect(sampler.deployment).To(Equal("frontend"))
+				Expect(detail.Samples).To(Equal(sampler.failures))
+				Expect(detail.TapUnavailable).To(BeEmpty())
+			})
+
+			It("should report why tap couldn't sample", func() {
+				sampler := &fakeFailureSampler{err: errors.New("tap API returned 403 Forbidden")}
+
+				detail := parseErrorsDetail(collectorFor(map[string]string{}), sampler)
+
+				Expect(detail.Samples).To(BeEmpty())
+				Expect(detail.TapUnavailable).To(ContainSubstring("403 Forbidden"))
+			})
+		})
+	})
+
+	Context("when querying many services", func() {
+		var (
+			ctx       context.Context
+			inFlight  atomic.Int32
+			maxFlight atomic.Int32
+		)
+
+		// collectorFor serves ten deployments, each with a request rate derived from its name,
+		// and tracks how many queries are in flight at once
+		collectorFor := func(concurrency int) *metrics.MetricsCollector {
+			deployments := []string{}
+			for _, name := range []string{"a", "b", "c", "d", "e", "f", "g", "h", "i", "j"} {
+				deployments = append(deployments, `{"metric":{"deployment":"`+name+`"},"value":[1700000000,"1"]}`)
+			}
+
+			prometheus := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				Expect(r.ParseForm()).To(Succeed())
+				query := r.Form.Get("query")
+
+				current := inFlight.Add(1)
+				defer inFlight.Add(-1)
+				for {
+					previous := maxFlight.Load()
+					if current <= previous || maxFlight.CompareAndSwap(previous, current) {
+						break
+					}
+				}
+				time.Sleep(5 * time.Millisecond)
+
+				result := "[]"
+				switch {
+				case strings.HasPrefix(query, "count(request_total"):
+					result = "[" + strings.Join(deployments, ",") + "]"
+				case strings.HasPrefix(query, "sum(rate(request_total"):
+					name := query[strings.Index(query, `deployment="`)+len(`deployment="`)]
+					result = `[{"metric":{},"value":[1700000000,"` + string(rune('0'+name-'a')) + `"]}]`
+				}
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = w.Write([]byte(`{"status":"success","data":{"resultType":"vector","result":` + result + `}}`))
+			}))
+			DeferCleanup(prometheus.Close)
+
+			os.Setenv("LINKERD_PROMETHEUS_URL", prometheus.URL)
+			DeferCleanup(os.Unsetenv, "LINKERD_PROMETHEUS_URL")
+
+			collector, err := metrics.NewMetricsCollector(nil, nil, nil, "linkerd")
+			Expect(err).NotTo(HaveOccurred())
+			collector.SetQueryConcurrency(concurrency)
+			return collector
+		}
+
+		BeforeEach(func() {
+			ctx = context.Background()
+			inFlight.Store(0)
+			maxFlight.Store(0)
+		})
+
+		It("should keep the order of the services", func() {
+			summaries, _, err := collectorFor(4).ServiceHealthSummaries(ctx, "default", metrics.TimeRange{}, metrics.DefaultHealthThresholds())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(summaries).To(HaveLen(10))
+			for i, summary := range summaries {
+				Expect(summary.Service).To(Equal(string(rune('a' + i))))
+				Expect(*summary.RequestRate).To(BeNumerically("==", i))
+			}
+		})
+
+		It("should query several services at once, up to the concurrency", func() {
+			_, _, err := collectorFor(3).ServiceHealthSummaries(ctx, "default", metrics.TimeRange{}, metrics.DefaultHealthThresholds())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(maxFlight.Load()).To(BeNumerically(">", 1))
+			Expect(maxFlight.Load()).To(BeNumerically("<=", 3))
+		})
+
+		It("should keep the ranking order of GetTopServices", func() {
+			result, err := collectorFor(8).GetTopServices(ctx, "default", "request_rate", "5m", 3)
+			Expect(err).NotTo(HaveOccurred())
+
+			var ranking metrics.ServiceRanking
+			Expect(testutil.ParseJSONResult(result, &ranking)).To(Succeed())
+			Expect(ranking.Services).To(HaveLen(3))
+			Expect(ranking.Services[0].Service).To(Equal("a"))
+			Expect(ranking.Services[2].Service).To(Equal("c"))
+		})
+
+		It("should stop when the context is done", func() {
+			collector := collectorFor(2)
+			ctx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+			defer cancel()
+
+			_, _, err := collector.ServiceHealthSummaries(ctx, "default", metrics.TimeRange{}, metrics.DefaultHealthThresholds())
+			Expect(err).To(MatchError(context.DeadlineExceeded))
+		})
+	})
+})
@@ -0,0 +1,154 @@
+package metrics
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const (
+	// DefaultComparisonRange is the length of the compared windows when none is given
+	DefaultComparisonRange = "1h"
+	// DefaultRegressionThreshold is the change, in percent of the baseline, beyond which a metric
+	// moving in the wrong direction counts as a regression
+	DefaultRegressionThreshold = 10.0
+)
+
+// MetricDelta compares one metric across the baseline and current windows. Change and
+// percentChange are null when either value is missing; percentChange is also null when the
+// baseline is zero.
+type MetricDelta struct {
+	Metric        string   `json:"metric"`
+	Baseline      *float64 `json:"baseline"`
+	Current       *float64 `json:"current"`
+	Change        *float64 `json:"change"`        // current - baseline
+	PercentChange *float64 `json:"percentChange"` // change relative to the baseline
+	Regression    bool     `json:"regression"`
+}
+
+// ServiceMetricsComparison holds the metrics of a service in two time windows and their deltas
+type ServiceMetricsComparison struct {
+	Service             string         `json:"service"`
+	Namespace           string         `json:"namespace"`
+	RegressionThreshold float64        `json:"regressionThreshold"` // percent
+	Baseline            ServiceMetrics `json:"baseline"`
+	Current             ServiceMetrics `json:"current"`
+	Deltas              []MetricDelta  `json:"deltas"`
+	Regressions         []string       `json:"regressions"` // metrics flagged as regressed
+}
+
+// CompareServiceMetrics collects the metrics of a service over the current window, ending now,
+// and over the baseline window, ending baselineOffset ago, and reports how they changed. Both the
+// baseline window and its offset default to the length of the current window, which makes the
+// window immediately preceding the current one the baseline.
+func (c *MetricsCollector) CompareServiceMetrics(ctx context.Context, namespace, service, currentRange, baselineRange, baselineOffset string, threshold float64) (*mcp.CallToolResult, error) {
+	if !c.Available() {
+		return unavailableResult(), nil
+	}
+
+	if currentRange == "" {
+		currentRange = DefaultComparisonRange
+	}
+	if baselineRange == "" {
+		baselineRange = currentRange
+	}
+
+	current, err := ParseTimeRange(currentRange)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Invalid current time range: %v", err)), nil
+	}
+	baseline, err := ParseTimeRange(baselineRange)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Invalid baseline time range: %v", err)), nil
+	}
+
+	offset := current.End.Sub(current.Start)
+	if baselineOffset != "" {
+		if offset, err = time.ParseDuration(baselineOffset); err != nil || offset < 0 {
+			return mcp.NewToolResultError(fmt.Sprintf("Invalid baseline offset: %s", baselineOffset)), nil
+		}
+	}
+	baseline.Start = baseline.Start.Add(-offset)
+	baseline.End = baseline.End.Add(-offset)
+
+	if threshold <= 0 {
+		return mcp.NewToolResultError(fmt.Sprintf("Invalid regression threshold: %g (must be positive)", threshold)), nil
+	}
+
+	currentMetrics, err := c.ServiceMetrics(ctx, namespace, service, current, DirectionInbound)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to collect current metrics: %v", err)), nil
+	}
+	baselineMetrics, err := c.ServiceMetrics(ctx, namespace, service, baseline, DirectionInbound)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to collect baseline metrics: %v", err)), nil
+	}
+
+	data, err := json.Marshal(BuildServiceMetricsComparison(*baselineMetrics, *currentMetrics, threshold))
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal comparison: %v", err)), nil
+	}
+
+	return mcp.NewToolResultText(string(data)), nil
+}
+
+// BuildServiceMetricsComparison computes the request rate, success rate and p95 latency deltas
+// between two metric sets. A metric regresses when it moves in the wrong direction by more
+// than threshold percent of its baseline: the request rate or success rate drops, or the p95
+// latency rises.
+func BuildServiceMetricsComparison(baseline, current ServiceMetrics, threshold float64) ServiceMetricsComparison {
+	comparison := ServiceMetricsComparison{
+		Service:             current.Service,
+		Namespace:           current.Namespace,
+		RegressionThreshold: threshold,
+		Baseline:            baseline,
+		Current:             current,
+		Regressions:         []string{},
+	}
+
+	deltas := []struct {
+		metric         string
+		baseline       *float64
+		current        *float64
+		higherIsBetter bool
+	}{
+		{"request_rate", baseline.RequestRate, current.RequestRate, true},
+		{"success_rate", baseline.SuccessRate, current.SuccessRate, true},
+		{"latency_p95", baseline.Latency.P95, current.Latency.P95, false},
+	}
+
+	for _, d := range deltas {
+		delta := metricDelta(d.metric, d.baseline, d.current, d.higherIsBetter, threshold)
+		if delta.Regression {
+			comparison.Regressions = append(comparison.Regressions, delta.Metric)
+		}
+		comparison.Deltas = append(comparison.Deltas, delta)
+	}
+
+	return comparison
+}
+
+func metricDelta(metric string, baseline, current *float64, higherIsBetter bool, threshold float64) MetricDelta {
+	delta := MetricDelta{Metric: metric, Baseline: baseline, Current: current}
+	if baseline == nil || current == nil {
+		return delta
+	}
+
+	change := *current - *baseline
+	delta.Change = &change
+	if *baseline == 0 {
+		return delta
+	}
+
+	percent := change / *baseline * 100
+	delta.PercentChange = &percent
+	if higherIsBetter {
+		delta.Regression = percent < -threshold
+	} else {
+		delta.Regression = percent > threshold
+	}
+	return delta
+}
@@ -0,0 +1,100 @@
+package metrics_test
+
+import (
+	"github.com/christianhuening/linkerd-mcp/internal/metrics"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func value(v float64) *float64 {
+	return &v
+}
+
+var _ = Describe("BuildServiceMetricsComparison", func() {
+	var baseline, current metrics.ServiceMetrics
+
+	BeforeEach(func() {
+		baseline = metrics.ServiceMetrics{
+			Service:     "api",
+			Namespace:   "prod",
+			RequestRate: value(100),
+			SuccessRate: value(99.5),
+			Latency:     metrics.LatencyMetrics{P95: value(200)},
+		}
+		current = metrics.ServiceMetrics{
+			Service:     "api",
+			Namespace:   "prod",
+			RequestRate: value(95),
+			SuccessRate: value(99),
+			Latency:     metrics.LatencyMetrics{P95: value(200)},
+		}
+	})
+
+	deltaOf := func(comparison metrics.ServiceMetricsComparison, metric string) metrics.MetricDelta {
+		for _, delta := range comparison.Deltas {
+			if delta.Metric == metric {
+				return delta
+			}
+		}
+		Fail("no delta for " + metric)
+		return metrics.MetricDelta{}
+	}
+
+	It("should compute absolute and percentage changes", func() {
+		comparison := metrics.BuildServiceMetricsComparison(baseline, current, 10)
+
+		Expect(comparison.Service).To(Equal("api"))
+		Expect(comparison.Deltas).To(HaveLen(3))
+
+		requestRate := deltaOf(comparison, "request_rate")
+		Expect(*requestRate.Change).To(BeNumerically("~", -5, 0.001))
+		Expect(*requestRate.PercentChange).To(BeNumerically("~", -5, 0.001))
+		Expect(requestRate.Regression).To(BeFalse())
+		Expect(comparison.Regressions).To(BeEmpty())
+	})
+
+	It("should flag latency rising beyond the threshold", func() {
+		current.Latency.P95 = value(260)
+
+		comparison := metrics.BuildServiceMetricsComparison(baseline, current, 10)
+
+		latency := deltaOf(comparison, "latency_p95")
+		Expect(*latency.PercentChange).To(BeNumerically("~", 30, 0.001))
+		Expect(latency.Regression).To(BeTrue())
+		Expect(comparison.Regressions).To(ConsistOf("latency_p95"))
+	})
+
+	It("should flag drops in request and success rate but not increases", func() {
+		current.RequestRate = value(50)
+		current.SuccessRate = value(80)
+		current.Latency.P95 = value(100)
+
+		comparison := metrics.BuildServiceMetricsComparison(baseline, current, 10)
+
+		Expect(comparison.Regressions).To(ConsistOf("request_rate", "success_rate"))
+		Expect(deltaOf(comparison, "latency_p95").Regression).To(BeFalse())
+	})
+
+	It("should honor the threshold", func() {
+		current.Latency.P95 = value(230)
+
+		Expect(metrics.BuildServiceMetricsComparison(baseline, current, 10).Regressions).To(ConsistOf("latency_p95"))
+		Expect(metrics.BuildServiceMetricsComparison(baseline, current, 20).Regressions).To(BeEmpty())
+	})
+
+	It("should leave changes unset when a window has no data", func() {
+		current.RequestRate = nil
+		baseline.Latency.P95 = value(0)
+
+		comparison := metrics.BuildServiceMetricsComparison(baseline, current, 10)
+
+		requestRate := deltaOf(comparison, "request_rate")
+		Expect(requestRate.Change).To(BeNil())
+		Expect(requestRate.PercentChange).To(BeNil())
+		Expect(requestRate.Regression).To(BeFalse())
+
+		latency := deltaOf(comparison, "latency_p95")
+		Expect(*latency.Change).To(BeNumerically("==", 200))
+		Expect(latency.PercentChange).To(BeNil())
+	})
+})
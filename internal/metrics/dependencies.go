@@ -0,0 +1,124 @@
+package metrics
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/prometheus/common/model"
+)
+
+// ServiceDependency is the outbound traffic a service sends to one destination deployment
+type ServiceDependency struct {
+	Deployment  string   `json:"deployment"`
+	Namespace   string   `json:"namespace"`
+	RequestRate float64  `json:"requestRate"` // requests per second
+	SuccessRate *float64 `json:"successRate"` // percentage (0-100), null without responses
+}
+
+// GetServiceDependencies lists the destinations a service actually sends requests to, from the
+// outbound metrics of its proxies, with the request rate and success rate of each. Unlike the top
+// destinations of get_service_metrics, every destination is returned, busiest first, unless
+// limit is positive.
+func (c *MetricsCollector) GetServiceDependencies(ctx context.Context, namespace, service, timeRangeStr string, limit int) (*mcp.CallToolResult, error) {
+	if !c.Available() {
+		return unavailableResult(), nil
+	}
+
+	tr, err := ParseTimeRange(timeRangeStr)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Invalid time range: %v", err)), nil
+	}
+	if limit < 0 {
+		return mcp.NewToolResultError(fmt.Sprintf("Invalid limit: %d (must be positive)", limit)), nil
+	}
+
+	deployment, err := c.findDeploymentForService(ctx, namespace, service)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to find deployment: %v", err)), nil
+	}
+
+	window := tr.End.Sub(tr.Start)
+	requestRates, err := c.promClient.Query(ctx, c.queryBuilder.BuildDestinationRequestRateQuery(deployment, namespace, window), tr.End)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to query request rate by destination: %v", err)), nil
+	}
+	successRates, err := c.promClient.Query(ctx, c.queryBuilder.BuildDestinationSuccessRateQuery(deployment, namespace, window), tr.End)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to query success rate by destination: %v", err)), nil
+	}
+
+	dependencies := BuildServiceDependencies(requestRates, successRates)
+	total := len(dependencies)
+	if limit > 0 && total > limit {
+		dependencies = dependencies[:limit]
+	}
+
+	data, err := json.Marshal(map[string]interface{}{
+		"service":           service,
+		"namespace":         namespace,
+		"timeRange":         tr,
+		"dependencies":      dependencies,
+		"totalDependencies": total,
+	})
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal dependencies: %v", err)), nil
+	}
+
+	return mcp.NewToolResultText(string(data)), nil
+}
+
+// BuildServiceDependencies joins per-destination request rates and success rates (0-1), grouped
+// by dst_deployment and dst_namespace. Dependencies are sorted busiest first; ties are broken by
+// namespace and deployment.
+func BuildServiceDependencies(requestRates, successRates model.Value) []ServiceDependency {
+	destinationKey := func(metric model.Metric) string {
+		return string(metric["dst_namespace"]) + "/" + string(metric["dst_deployment"])
+	}
+
+	successByDestination := map[string]float64{}
+	if vector, ok := successRates.(model.Vector); ok {
+		for _, sample := range vector {
+			// The ratio is NaN for destinations without responses
+			if !math.IsNaN(float64(sample.Value)) {
+				successByDestination[destinationKey(sample.Metric)] = float64(sample.Value) * 100
+			}
+		}
+	}
+
+	dependencies := []ServiceDependency{}
+	vector, ok := requestRates.(model.Vector)
+	if !ok {
+		return dependencies
+	}
+	for _, sample := range vector {
+		if math.IsNaN(float64(sample.Value)) {
+			continue
+		}
+		dependency := ServiceDependency{
+			Deployment:  string(sample.Metric["dst_deployment"]),
+			Namespace:   string(sample.Metric["dst_namespace"]),
+			RequestRate: float64(sample.Value),
+		}
+		if successRate, ok := successByDestination[destinationKey(sample.Metric)]; ok {
+			dependency.SuccessRate = &successRate
+		}
+		dependencies = append(dependencies, dependency)
+	}
+
+	sort.SliceStable(dependencies, func(i, j int) bool {
+		a, b := dependencies[i], dependencies[j]
+		if a.RequestRate != b.RequestRate {
+			return a.RequestRate > b.RequestRate
+		}
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		return a.Deployment < b.Deployment
+	})
+
+	return dependencies
+}
@@ -0,0 +1,45 @@
+package metrics_test
+
+import (
+	"math"
+
+	"github.com/christianhuening/linkerd-mcp/internal/metrics"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/common/model"
+)
+
+var _ = Describe("BuildServiceDependencies", func() {
+	It("should return every destination, busiest first", func() {
+		requestRates := model.Vector{
+			destinationSample("cache", "prod", 2),
+			destinationSample("api", "prod", 10),
+			destinationSample("payments", "billing", 2),
+			destinationSample("audit", "prod", 0.1),
+		}
+		successRates := model.Vector{
+			destinationSample("cache", "prod", 1),
+			destinationSample("api", "prod", 0.95),
+			destinationSample("payments", "billing", 0.5),
+			destinationSample("audit", "prod", math.NaN()),
+		}
+
+		dependencies := metrics.BuildServiceDependencies(requestRates, successRates)
+
+		Expect(dependencies).To(HaveLen(4))
+		Expect(dependencies[0].Deployment).To(Equal("api"))
+		Expect(*dependencies[0].SuccessRate).To(BeNumerically("~", 95, 0.001))
+
+		// Equal request rates are ordered by namespace
+		Expect(dependencies[1].Deployment).To(Equal("payments"))
+		Expect(dependencies[1].Namespace).To(Equal("billing"))
+		Expect(dependencies[2].Deployment).To(Equal("cache"))
+
+		Expect(dependencies[3].Deployment).To(Equal("audit"))
+		Expect(dependencies[3].SuccessRate).To(BeNil())
+	})
+
+	It("should return no dependencies for a non-vector result", func() {
+		Expect(metrics.BuildServiceDependencies(nil, nil)).To(BeEmpty())
+	})
+})
@@ -0,0 +1,22 @@
+package metrics
+
+import (
+	"context"
+	"net/http"
+)
+
+// PortForward is the port-forward to Prometheus, for testing how it reconnects
+type PortForward = prometheusPortForward
+
+// NewPortForward creates a port-forward whose forwards are started by connect, which returns
+// the local address of a forward and a channel closed when it ends
+func NewPortForward(connect func() (string, chan struct{}, error)) (*PortForward, error) {
+	target := PortForwardTarget{Namespace: "linkerd-viz", Service: "prometheus", Port: 9090}
+	return newPrometheusPortForward(context.Background(), target, http.DefaultTransport, func(ctx context.Context) (*portForwardSession, error) {
+		host, doneCh, err := connect()
+		if err != nil {
+			return nil, err
+		}
+		return &portForwardSession{host: host, stopCh: make(chan struct{}), doneCh: doneCh}, nil
+	})
+}
@@ -0,0 +1,154 @@
+package metrics
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/christianhuening/linkerd-mcp/internal/tap"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/prometheus/common/model"
+)
+
+// DefaultFailureLimit is the number of failing routes returned when no limit is given
+const DefaultFailureLimit = 10
+
+// FailureSampler samples live failing requests of a deployment, as the tap API does
+type FailureSampler interface {
+	SampleFailures(ctx context.Context, namespace, deployment string, duration time.Duration) ([]tap.FailedRequest, error)
+}
+
+// RouteFailure is the number of failed responses of one route with one status code over the window
+type RouteFailure struct {
+	Route      string `json:"route"`
+	StatusCode string `json:"statusCode"` // the HTTP status; empty for failures without a response
+	Count      int    `json:"count"`
+}
+
+// ErrorsDetail breaks the failures of a service down by route and status code over the window,
+// with live failing requests sampled through tap when it is available
+type ErrorsDetail struct {
+	Service        string              `json:"service"`
+	Namespace      string              `json:"namespace"`
+	Deployment     string              `json:"deployment,omitempty"`
+	TimeRange      TimeRange           `json:"timeRange"`
+	TotalFailures  int                 `json:"totalFailures"`
+	ByRoute        bool                `json:"byRoute"` // false when the service has no route metrics
+	Failures       []RouteFailure      `json:"failures"`
+	Samples        []tap.FailedRequest `json:"samples"`
+	TapUnavailable string              `json:"tapUnavailable,omitempty"` // why no samples were taken
+}
+
+// GetErrorsDetail reports what is failing for a service: the failed responses over the window by
+// route and status code, busiest first and at most limit of them, from the route metrics of
+// its ServiceProfile. Without route metrics, failures are broken down by HTTP status only.
+// When sampler is set, failing requests are also sampled live for sampleDuration and grouped
+// by method and path, which points at the exact endpoint even without routes.
+func (c *MetricsCollector) GetErrorsDetail(ctx context.Context, namespace, service, timeRangeStr string, limit int, sampler FailureSampler, sampleDuration time.Duration) (*mcp.CallToolResult, error) {
+	if !c.Available() {
+		return unavailableResult(), nil
+	}
+
+	tr, err := ParseTimeRange(timeRangeStr)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Invalid time range: %v", err)), nil
+	}
+	if limit < 0 {
+		return mcp.NewToolResultError(fmt.Sprintf("Invalid limit: %d (must be positive)", limit)), nil
+	}
+	if limit == 0 {
+		limit = DefaultFailureLimit
+	}
+
+	deployment, err := c.findDeploymentForService(ctx, namespace, service)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to find deployment: %v", err)), nil
+	}
+
+	window := tr.End.Sub(tr.Start)
+	routeFailures, err := c.promClient.Query(ctx, c.queryBuilder.BuildRouteFailuresQuery(deployment, namespace, window), tr.End)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to query failures by route: %v", err)), nil
+	}
+
+	detail := ErrorsDetail{
+		Service:    service,
+		Namespace:  namespace,
+		Deployment: deployment,
+		TimeRange:  tr,
+		ByRoute:    true,
+		Failures:   BuildRouteFailures(routeFailures, "status_code"),
+		Samples:    []tap.FailedRequest{},
+	}
+	if len(detail.Failures) == 0 {
+		statusFailures, err := c.promClient.Query(ctx, c.queryBuilder.BuildFailuresByStatusQuery(deployment, namespace, window), tr.End)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to query failures by status: %v", err)), nil
+		}
+		detail.ByRoute = false
+		detail.Failures = BuildRouteFailures(statusFailures, "http_status")
+	}
+
+	for _, failure := range detail.Failures {
+		detail.TotalFailures += failure.Count
+	}
+	if len(detail.Failures) > limit {
+		detail.Failures = detail.Failures[:limit]
+	}
+
+	if sampler == nil {
+		detail.TapUnavailable = "tap sampling was not requested"
+	} else if samples, err := sampler.SampleFailures(ctx, namespace, deployment, sampleDuration); err != nil {
+		detail.TapUnavailable = err.Error()
+	} else {
+		detail.Samples = samples
+	}
+
+	data, err := json.Marshal(detail)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal errors detail: %v", err)), nil
+	}
+
+	return mcp.NewToolResultText(string(data)), nil
+}
+
+// BuildRouteFailures reads failure counts grouped by rt_route and the given status label.
+// Counts are rounded, since increase() extrapolates, and those rounding to zero are dropped.
+// Failures are sorted by count, then route and status.
+func BuildRouteFailures(value model.Value, statusLabel model.LabelName) []RouteFailure {
+	failures := []RouteFailure{}
+	vector, ok := value.(model.Vector)
+	if !ok {
+		return failures
+	}
+
+	for _, sample := range vector {
+		count := float64(sample.Value)
+		if math.IsNaN(count) || math.Round(count) < 1 {
+			continue
+		}
+		route := string(sample.Metric["rt_route"])
+		if route == "" {
+			route = DefaultRouteName
+		}
+		failures = append(failures, RouteFailure{
+			Route:      route,
+			StatusCode: string(sample.Metric[statusLabel]),
+			Count:      int(math.Round(count)),
+		})
+	}
+
+	sort.Slice(failures, func(i, j int) bool {
+		if failures[i].Count != failures[j].Count {
+			return failures[i].Count > failures[j].Count
+		}
+		if failures[i].Route != failures[j].Route {
+			return failures[i].Route < failures[j].Route
+		}
+		return failures[i].StatusCode < failures[j].StatusCode
+	})
+	return failures
+}
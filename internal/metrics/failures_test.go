@@ -0,0 +1,55 @@
+package metrics_test
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/christianhuening/linkerd-mcp/internal/metrics"
+	"github.com/christianhuening/linkerd-mcp/internal/tap"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/common/model"
+)
+
+// fakeFailureSampler returns canned tap samples, recording the deployment it was asked for
+type fakeFailureSampler struct {
+	failures   []tap.FailedRequest
+	err        error
+	deployment string
+}
+
+func (s *fakeFailureSampler) SampleFailures(ctx context.Context, namespace, deployment string, duration time.Duration) ([]tap.FailedRequest, error) {
+	s.deployment = deployment
+	return s.failures, s.err
+}
+
+func failureSample(route, status string, value float64) *model.Sample {
+	metric := model.Metric{"status_code": model.LabelValue(status)}
+	if route != "" {
+		metric["rt_route"] = model.LabelValue(route)
+	}
+	return &model.Sample{Metric: metric, Value: model.SampleValue(value)}
+}
+
+var _ = Describe("BuildRouteFailures", func() {
+	It("should round counts and sort them, most frequent first", func() {
+		failures := metrics.BuildRouteFailures(model.Vector{
+			failureSample("GET /books", "500", 2.4),
+			failureSample("POST /checkout", "503", 9.7),
+			failureSample("", "502", 2),
+			failureSample("GET /books", "504", 0.3),
+			failureSample("GET /authors", "500", math.NaN()),
+		}, "status_code")
+
+		Expect(failures).To(Equal([]metrics.RouteFailure{
+			{Route: "POST /checkout", StatusCode: "503", Count: 10},
+			{Route: "GET /books", StatusCode: "500", Count: 2},
+			{Route: metrics.DefaultRouteName, StatusCode: "502", Count: 2},
+		}))
+	})
+
+	It("should return no failures for a non-vector result", func() {
+		Expect(metrics.BuildRouteFailures(nil, "status_code")).To(BeEmpty())
+	})
+})
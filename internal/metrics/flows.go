@@ -0,0 +1,96 @@
+package metrics
+
+import (
+	"context"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/prometheus/common/model"
+)
+
+// TopTrafficFlowsLimit is the number of destinations and sources reported for a service
+const TopTrafficFlowsLimit = 10
+
+// topDestinations returns the busiest destinations of a deployment with per-destination
+// success rate and p95 latency. Failed queries are recorded in warnings.
+func (c *MetricsCollector) topDestinations(ctx context.Context, warnings *queryWarnings, deployment, namespace string, window time.Duration, ts time.Time) []TrafficFlow {
+	rates := c.optionalQuery(ctx, warnings, "top destinations", c.queryBuilder.BuildTopDestinationsQuery(deployment, namespace, window, TopTrafficFlowsLimit), ts)
+	if rates == nil {
+		return nil
+	}
+	successRates := c.optionalQuery(ctx, warnings, "destination success rates", c.queryBuilder.BuildDestinationSuccessRateQuery(deployment, namespace, window), ts)
+	latencies := c.optionalQuery(ctx, warnings, "destination latencies", c.queryBuilder.BuildDestinationLatencyQuery(deployment, namespace, 0.95, window), ts)
+
+	return BuildTrafficFlows(rates, successRates, latencies, "dst_deployment", "dst_namespace", window)
+}
+
+// topSources returns the busiest sources calling a deployment with per-source
+// success rate and p95 latency. Failed queries are recorded in warnings.
+func (c *MetricsCollector) topSources(ctx context.Context, warnings *queryWarnings, deployment, namespace string, window time.Duration, ts time.Time) []TrafficFlow {
+	rates := c.optionalQuery(ctx, warnings, "top sources", c.queryBuilder.BuildTopSourcesQuery(deployment, namespace, window, TopTrafficFlowsLimit), ts)
+	if rates == nil {
+		return nil
+	}
+	successRates := c.optionalQuery(ctx, warnings, "source success rates", c.queryBuilder.BuildSourceSuccessRateQuery(deployment, namespace, window), ts)
+	latencies := c.optionalQuery(ctx, warnings, "source latencies", c.queryBuilder.BuildSourceLatencyQuery(deployment, namespace, 0.95, window), ts)
+
+	return BuildTrafficFlows(rates, successRates, latencies, "deployment", "namespace", window)
+}
+
+// BuildTrafficFlows joins grouped request rate, success rate (0-1) and p95 latency results on the
+// given deployment and namespace labels. Flows are sorted by request rate, busiest first.
+func BuildTrafficFlows(requestRates, successRates, latencyP95 model.Value, deploymentLabel, namespaceLabel model.LabelName, window time.Duration) []TrafficFlow {
+	flowKey := func(metric model.Metric) string {
+		return string(metric[namespaceLabel]) + "/" + string(metric[deploymentLabel])
+	}
+	index := func(value model.Value) map[string]float64 {
+		values := map[string]float64{}
+		vector, ok := value.(model.Vector)
+		if !ok {
+			return values
+		}
+		for _, sample := range vector {
+			// histogram_quantile and ratios yield NaN for flows without responses
+			if math.IsNaN(float64(sample.Value)) {
+				continue
+			}
+			values[flowKey(sample.Metric)] = float64(sample.Value)
+		}
+		return values
+	}
+
+	flows := []TrafficFlow{}
+	vector, ok := requestRates.(model.Vector)
+	if !ok {
+		return flows
+	}
+
+	successByFlow := index(successRates)
+	latencyByFlow := index(latencyP95)
+
+	for _, sample := range vector {
+		deployment := string(sample.Metric[deploymentLabel])
+		if deployment == "" {
+			continue
+		}
+		key := flowKey(sample.Metric)
+		requestRate := float64(sample.Value)
+
+		flows = append(flows, TrafficFlow{
+			Service:      deployment, // For Linkerd, deployment name often matches service name
+			Namespace:    string(sample.Metric[namespaceLabel]),
+			Deployment:   deployment,
+			RequestCount: int64(requestRate * window.Seconds()),
+			RequestRate:  requestRate,
+			SuccessRate:  successByFlow[key] * 100,
+			LatencyP95:   latencyByFlow[key],
+		})
+	}
+
+	sort.SliceStable(flows, func(i, j int) bool {
+		return flows[i].RequestRate > flows[j].RequestRate
+	})
+
+	return flows
+}
@@ -0,0 +1,68 @@
+package metrics_test
+
+import (
+	"math"
+	"time"
+
+	"github.com/christianhuening/linkerd-mcp/internal/metrics"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/common/model"
+)
+
+func destinationSample(deployment, namespace string, value float64) *model.Sample {
+	return &model.Sample{
+		Metric: model.Metric{
+			"dst_deployment": model.LabelValue(deployment),
+			"dst_namespace":  model.LabelValue(namespace),
+		},
+		Value: model.SampleValue(value),
+	}
+}
+
+var _ = Describe("BuildTrafficFlows", func() {
+	It("should populate the success rate and latency of each destination", func() {
+		rates := model.Vector{
+			destinationSample("payments", "prod", 2),
+			destinationSample("catalog", "prod", 10),
+		}
+		successRates := model.Vector{
+			destinationSample("catalog", "prod", 0.999),
+			destinationSample("payments", "prod", 0.5),
+		}
+		latencies := model.Vector{
+			destinationSample("catalog", "prod", 12),
+			destinationSample("payments", "prod", 480),
+		}
+
+		flows := metrics.BuildTrafficFlows(rates, successRates, latencies, "dst_deployment", "dst_namespace", 5*time.Minute)
+
+		Expect(flows).To(HaveLen(2))
+
+		Expect(flows[0].Deployment).To(Equal("catalog"))
+		Expect(flows[0].RequestRate).To(BeNumerically("==", 10))
+		Expect(flows[0].RequestCount).To(BeNumerically("==", 3000))
+		Expect(flows[0].SuccessRate).To(BeNumerically("~", 99.9, 0.001))
+		Expect(flows[0].LatencyP95).To(BeNumerically("==", 12))
+
+		Expect(flows[1].Deployment).To(Equal("payments"))
+		Expect(flows[1].Namespace).To(Equal("prod"))
+		Expect(flows[1].SuccessRate).To(BeNumerically("~", 50, 0.001))
+		Expect(flows[1].LatencyP95).To(BeNumerically("==", 480))
+	})
+
+	It("should leave metrics without data at zero", func() {
+		rates := model.Vector{destinationSample("cache", "prod", 1)}
+		latencies := model.Vector{destinationSample("cache", "prod", math.NaN())}
+
+		flows := metrics.BuildTrafficFlows(rates, model.Vector{}, latencies, "dst_deployment", "dst_namespace", time.Minute)
+
+		Expect(flows).To(HaveLen(1))
+		Expect(flows[0].SuccessRate).To(BeZero())
+		Expect(flows[0].LatencyP95).To(BeZero())
+	})
+
+	It("should return no flows for a non-vector result", func() {
+		Expect(metrics.BuildTrafficFlows(nil, nil, nil, "dst_deployment", "dst_namespace", time.Minute)).To(BeEmpty())
+	})
+})
@@ -0,0 +1,170 @@
+package metrics
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/prometheus/common/model"
+)
+
+// SourceMTLSCoverage is the inbound request rate of a service from one client identity. Plaintext
+// requests carry no identity and are grouped under an empty ClientID.
+type SourceMTLSCoverage struct {
+	ClientID             string  `json:"clientId"`
+	MTLSRequestRate      float64 `json:"mtlsRequestRate"`      // requests per second
+	PlaintextRequestRate float64 `json:"plaintextRequestRate"` // requests per second
+}
+
+// ServiceMTLSCoverage is the share of inbound requests a service received over mTLS
+type ServiceMTLSCoverage struct {
+	Service              string               `json:"service"`
+	MTLSRequestRate      float64              `json:"mtlsRequestRate"`      // requests per second
+	PlaintextRequestRate float64              `json:"plaintextRequestRate"` // requests per second
+	TotalRequestRate     float64              `json:"totalRequestRate"`     // requests per second
+	CoveragePercent      float64              `json:"coveragePercent"`      // percentage (0-100)
+	FullyCovered         bool                 `json:"fullyCovered"`
+	Sources              []SourceMTLSCoverage `json:"sources,omitempty"`
+}
+
+// GetMTLSCoverage reports, for each service in a namespace with inbound traffic, how much of it
+// arrived over mTLS. Services receiving plaintext requests, typically from unmeshed clients, are
+// flagged. With bySource, each service's traffic is also broken down by client identity.
+func (c *MetricsCollector) GetMTLSCoverage(ctx context.Context, namespace, service, timeRangeStr string, bySource bool) (*mcp.CallToolResult, error) {
+	if !c.Available() {
+		return unavailableResult(), nil
+	}
+
+	tr, err := ParseTimeRange(timeRangeStr)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Invalid time range: %v", err)), nil
+	}
+
+	deployment := ""
+	if service != "" {
+		if deployment, err = c.findDeploymentForService(ctx, namespace, service); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to find deployment: %v", err)), nil
+		}
+	}
+
+	query := c.queryBuilder.BuildTLSRequestRateQuery(deployment, namespace, bySource, tr.End.Sub(tr.Start))
+	result, err := c.promClient.Query(ctx, query, tr.End)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to query mTLS coverage: %v", err)), nil
+	}
+
+	services := BuildMTLSCoverage(result, bySource)
+	notCovered := []string{}
+	for _, svc := range services {
+		if !svc.FullyCovered {
+			notCovered = append(notCovered, svc.Service)
+		}
+	}
+
+	data, err := json.Marshal(map[string]interface{}{
+		"namespace":          namespace,
+		"timeRange":          tr,
+		"services":           services,
+		"servicesNotCovered": notCovered,
+	})
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal mTLS coverage: %v", err)), nil
+	}
+
+	return mcp.NewToolResultText(string(data)), nil
+}
+
+// MTLSCoverage returns, per deployment in a namespace, the percentage (0-100) of inbound
+// requests received over mTLS. Deployments without inbound traffic are left out.
+func (c *MetricsCollector) MTLSCoverage(ctx context.Context, namespace string, tr TimeRange) (map[string]float64, error) {
+	if !c.Available() {
+		return nil, fmt.Errorf("metrics collector is not configured")
+	}
+
+	result, err := c.promClient.Query(ctx, c.queryBuilder.BuildTLSRequestRateQuery("", namespace, false, tr.End.Sub(tr.Start)), tr.End)
+	if err != nil {
+		return nil, err
+	}
+
+	coverage := map[string]float64{}
+	for _, svc := range BuildMTLSCoverage(result, false) {
+		coverage[svc.Service] = svc.CoveragePercent
+	}
+	return coverage, nil
+}
+
+// BuildMTLSCoverage sums inbound request rates grouped by deployment and tls (and client_id when
+// bySource is set) into per-service coverage. Only tls="true" counts as mTLS. Services without
+// traffic are left out; the rest are sorted by coverage, least covered first.
+func BuildMTLSCoverage(requestRates model.Value, bySource bool) []ServiceMTLSCoverage {
+	byService := map[string]*ServiceMTLSCoverage{}
+	sources := map[string]map[string]*SourceMTLSCoverage{}
+
+	if vector, ok := requestRates.(model.Vector); ok {
+		for _, sample := range vector {
+			deployment := string(sample.Metric["deployment"])
+			rate := float64(sample.Value)
+			if deployment == "" || math.IsNaN(rate) || rate <= 0 {
+				continue
+			}
+
+			svc, ok := byService[deployment]
+			if !ok {
+				svc = &ServiceMTLSCoverage{Service: deployment}
+				byService[deployment] = svc
+				sources[deployment] = map[string]*SourceMTLSCoverage{}
+			}
+
+			mtls := sample.Metric["tls"] == "true"
+			if mtls {
+				svc.MTLSRequestRate += rate
+			} else {
+				svc.PlaintextRequestRate += rate
+			}
+
+			if !bySource {
+				continue
+			}
+			clientID := string(sample.Metric["client_id"])
+			source, ok := sources[deployment][clientID]
+			if !ok {
+				source = &SourceMTLSCoverage{ClientID: clientID}
+				sources[deployment][clientID] = source
+			}
+			if mtls {
+				source.MTLSRequestRate += rate
+			} else {
+				source.PlaintextRequestRate += rate
+			}
+		}
+	}
+
+	services := make([]ServiceMTLSCoverage, 0, len(byService))
+	for deployment, svc := range byService {
+		svc.TotalRequestRate = svc.MTLSRequestRate + svc.PlaintextRequestRate
+		svc.CoveragePercent = svc.MTLSRequestRate / svc.TotalRequestRate * 100
+		svc.FullyCovered = svc.PlaintextRequestRate == 0
+
+		if bySource {
+			svc.Sources = []SourceMTLSCoverage{}
+			for _, source := range sources[deployment] {
+				svc.Sources = append(svc.Sources, *source)
+			}
+			sort.Slice(svc.Sources, func(i, j int) bool {
+				return svc.Sources[i].ClientID < svc.Sources[j].ClientID
+			})
+		}
+		services = append(services, *svc)
+	}
+
+	sort.Slice(services, func(i, j int) bool {
+		if services[i].CoveragePercent != services[j].CoveragePercent {
+			return services[i].CoveragePercent < services[j].CoveragePercent
+		}
+		return services[i].Service < services[j].Service
+	})
+	return services
+}
@@ -0,0 +1,66 @@
+package metrics_test
+
+import (
+	"math"
+
+	"github.com/christianhuening/linkerd-mcp/internal/metrics"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/common/model"
+)
+
+func tlsSample(deployment, tls, clientID string, value float64) *model.Sample {
+	metric := model.Metric{"deployment": model.LabelValue(deployment), "tls": model.LabelValue(tls)}
+	if clientID != "" {
+		metric["client_id"] = model.LabelValue(clientID)
+	}
+	return &model.Sample{Metric: metric, Value: model.SampleValue(value)}
+}
+
+var _ = Describe("BuildMTLSCoverage", func() {
+	var rates model.Vector
+
+	BeforeEach(func() {
+		rates = model.Vector{
+			tlsSample("api", "true", "web.prod.serviceaccount.identity.linkerd.cluster.local", 6),
+			tlsSample("api", "true", "worker.prod.serviceaccount.identity.linkerd.cluster.local", 1.5),
+			tlsSample("api", "no_identity", "", 2.5),
+			tlsSample("web", "true", "ingress.prod.serviceaccount.identity.linkerd.cluster.local", 4),
+			tlsSample("idle", "true", "", 0),
+			tlsSample("broken", "true", "", math.NaN()),
+		}
+	})
+
+	It("should compute coverage per service, least covered first", func() {
+		services := metrics.BuildMTLSCoverage(rates, false)
+
+		Expect(services).To(HaveLen(2))
+
+		Expect(services[0].Service).To(Equal("api"))
+		Expect(services[0].MTLSRequestRate).To(BeNumerically("==", 7.5))
+		Expect(services[0].PlaintextRequestRate).To(BeNumerically("==", 2.5))
+		Expect(services[0].TotalRequestRate).To(BeNumerically("==", 10))
+		Expect(services[0].CoveragePercent).To(BeNumerically("~", 75, 0.001))
+		Expect(services[0].FullyCovered).To(BeFalse())
+		Expect(services[0].Sources).To(BeNil())
+
+		Expect(services[1].Service).To(Equal("web"))
+		Expect(services[1].CoveragePercent).To(BeNumerically("==", 100))
+		Expect(services[1].FullyCovered).To(BeTrue())
+	})
+
+	It("should break traffic down by client identity", func() {
+		services := metrics.BuildMTLSCoverage(rates, true)
+
+		Expect(services[0].Sources).To(Equal([]metrics.SourceMTLSCoverage{
+			{ClientID: "", PlaintextRequestRate: 2.5},
+			{ClientID: "web.prod.serviceaccount.identity.linkerd.cluster.local", MTLSRequestRate: 6},
+			{ClientID: "worker.prod.serviceaccount.identity.linkerd.cluster.local", MTLSRequestRate: 1.5},
+		}))
+	})
+
+	It("should return no services without traffic", func() {
+		Expect(metrics.BuildMTLSCoverage(model.Vector{}, true)).To(BeEmpty())
+		Expect(metrics.BuildMTLSCoverage(nil, false)).To(BeEmpty())
+	})
+})
@@ -0,0 +1,135 @@
+package metrics
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/prometheus/common/model"
+)
+
+const (
+	// DefaultMulticlusterNamespace is the namespace the linkerd-multicluster extension is installed in
+	DefaultMulticlusterNamespace = "linkerd-multicluster"
+	// GatewayDeployment is the deployment receiving traffic from remote clusters
+	GatewayDeployment = "linkerd-gateway"
+)
+
+// ClusterTraffic is the outbound traffic sent to one remote cluster. The success rate is null
+// when no responses were observed.
+type ClusterTraffic struct {
+	Cluster     string   `json:"cluster"`
+	RequestRate float64  `json:"requestRate"` // requests per second
+	SuccessRate *float64 `json:"successRate"` // percentage (0-100)
+}
+
+// GatewayTraffic is the inbound traffic received from remote clusters through the multicluster
+// gateway. Both rates are null when the gateway reported no traffic.
+type GatewayTraffic struct {
+	Gateway     string   `json:"gateway"`
+	Namespace   string   `json:"namespace"`
+	RequestRate *float64 `json:"requestRate"` // requests per second
+	SuccessRate *float64 `json:"successRate"` // percentage (0-100)
+}
+
+// MulticlusterTrafficReport summarizes cross-cluster traffic in both directions
+type MulticlusterTrafficReport struct {
+	Namespace string           `json:"namespace,omitempty"`
+	Cluster   string           `json:"cluster,omitempty"`
+	TimeRange TimeRange        `json:"timeRange"`
+	Outbound  []ClusterTraffic `json:"outbound"`
+	Inbound   GatewayTraffic   `json:"inbound"`
+}
+
+// GetMulticlusterTraffic reports the request and success rate of traffic sent to each remote
+// cluster, and of traffic received from remote clusters through the gateway in
+// gatewayNamespace. Outbound traffic can be restricted to a source namespace and to a single
+// destination cluster; inbound gateway traffic does not identify its source cluster.
+func (c *MetricsCollector) GetMulticlusterTraffic(ctx context.Context, namespace, cluster, gatewayNamespace, timeRangeStr string) (*mcp.CallToolResult, error) {
+	if !c.Available() {
+		return unavailableResult(), nil
+	}
+
+	tr, err := ParseTimeRange(timeRangeStr)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Invalid time range: %v", err)), nil
+	}
+
+	if gatewayNamespace == "" {
+		gatewayNamespace = DefaultMulticlusterNamespace
+	}
+
+	window := tr.End.Sub(tr.Start)
+
+	requestRates, err := c.promClient.Query(ctx, c.queryBuilder.BuildClusterRequestRateQuery(namespace, cluster, window), tr.End)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to query cross-cluster request rates: %v", err)), nil
+	}
+	successRates, _ := c.promClient.Query(ctx, c.queryBuilder.BuildClusterSuccessRateQuery(namespace, cluster, window), tr.End)
+	gatewayRequestRate, _ := c.promClient.Query(ctx, c.queryBuilder.BuildGatewayRequestRateQuery(gatewayNamespace, window), tr.End)
+	gatewaySuccessRate, _ := c.promClient.Query(ctx, c.queryBuilder.BuildGatewaySuccessRateQuery(gatewayNamespace, window), tr.End)
+
+	report := MulticlusterTrafficReport{
+		Namespace: namespace,
+		Cluster:   cluster,
+		TimeRange: tr,
+		Outbound:  BuildClusterTraffic(requestRates, successRates),
+		Inbound: GatewayTraffic{
+			Gateway:     GatewayDeployment,
+			Namespace:   gatewayNamespace,
+			RequestRate: extractOptionalValue(gatewayRequestRate),
+			SuccessRate: scaleOptional(extractOptionalValue(gatewaySuccessRate), 100),
+		},
+	}
+
+	data, err := json.Marshal(report)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal multicluster traffic: %v", err)), nil
+	}
+
+	return mcp.NewToolResultText(string(data)), nil
+}
+
+// BuildClusterTraffic joins outbound request rates with success rates (0-1) on the dst_cluster
+// label. Clusters without traffic are left out; the rest are sorted by request rate, busiest first.
+func BuildClusterTraffic(requestRates, successRates model.Value) []ClusterTraffic {
+	index := func(value model.Value) map[string]float64 {
+		values := map[string]float64{}
+		vector, ok := value.(model.Vector)
+		if !ok {
+			return values
+		}
+		for _, sample := range vector {
+			cluster := string(sample.Metric["dst_cluster"])
+			if cluster == "" || math.IsNaN(float64(sample.Value)) {
+				continue
+			}
+			values[cluster] = float64(sample.Value)
+		}
+		return values
+	}
+
+	successes := index(successRates)
+	clusters := []ClusterTraffic{}
+	for cluster, rate := range index(requestRates) {
+		if rate <= 0 {
+			continue
+		}
+		traffic := ClusterTraffic{Cluster: cluster, RequestRate: rate}
+		if success, ok := successes[cluster]; ok {
+			traffic.SuccessRate = scaleOptional(&success, 100)
+		}
+		clusters = append(clusters, traffic)
+	}
+
+	sort.Slice(clusters, func(i, j int) bool {
+		if clusters[i].RequestRate != clusters[j].RequestRate {
+			return clusters[i].RequestRate > clusters[j].RequestRate
+		}
+		return clusters[i].Cluster < clusters[j].Cluster
+	})
+	return clusters
+}
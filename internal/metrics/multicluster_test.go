@@ -0,0 +1,49 @@
+package metrics_test
+
+import (
+	"math"
+
+	"github.com/christianhuening/linkerd-mcp/internal/metrics"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/common/model"
+)
+
+func clusterSample(cluster string, value float64) *model.Sample {
+	return &model.Sample{
+		Metric: model.Metric{"dst_cluster": model.LabelValue(cluster)},
+		Value:  model.SampleValue(value),
+	}
+}
+
+var _ = Describe("BuildClusterTraffic", func() {
+	It("should join request and success rates per cluster, busiest first", func() {
+		requestRates := model.Vector{
+			clusterSample("east", 2),
+			clusterSample("west", 8),
+			clusterSample("idle", 0),
+		}
+		successRates := model.Vector{
+			clusterSample("west", 0.95),
+			clusterSample("east", math.NaN()),
+		}
+
+		clusters := metrics.BuildClusterTraffic(requestRates, successRates)
+
+		Expect(clusters).To(HaveLen(2))
+		Expect(clusters[0].Cluster).To(Equal("west"))
+		Expect(clusters[0].RequestRate).To(BeNumerically("==", 8))
+		Expect(clusters[0].SuccessRate).NotTo(BeNil())
+		Expect(*clusters[0].SuccessRate).To(BeNumerically("~", 95, 0.001))
+
+		Expect(clusters[1].Cluster).To(Equal("east"))
+		Expect(clusters[1].SuccessRate).To(BeNil())
+	})
+
+	It("should ignore samples without a cluster", func() {
+		clusters := metrics.BuildClusterTraffic(model.Vector{clusterSample("", 3)}, nil)
+
+		Expect(clusters).To(BeEmpty())
+		Expect(metrics.BuildClusterTraffic(nil, nil)).To(BeEmpty())
+	})
+})
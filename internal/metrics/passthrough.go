@@ -0,0 +1,170 @@
+package metrics
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/prometheus/common/model"
+)
+
+// linkerdMetricPatterns are the metric names a restricted raw query may reference: the request,
+// response and TCP metrics of the Linkerd proxy
+var linkerdMetricPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`^request_total$`),
+	regexp.MustCompile(`^response_total$`),
+	regexp.MustCompile(`^response_latency_ms_[a-z_]+$`),
+	regexp.MustCompile(`^tcp_[a-z_]+$`),
+}
+
+// promQLKeywords are the identifiers of PromQL that aren't metric names
+var promQLKeywords = map[string]bool{
+	"and": true, "or": true, "unless": true, "atan2": true, "bool": true, "offset": true,
+	"group_left": true, "group_right": true, "inf": true, "nan": true,
+}
+
+var (
+	// promQLStrings matches string literals, whose contents are never metric names
+	promQLStrings = regexp.MustCompile("\"(?:[^\"\\\\]|\\\\.)*\"|'(?:[^'\\\\]|\\\\.)*'|`[^`]*`")
+	// promQLMatchers matches label matchers and range or subquery durations
+	promQLMatchers = regexp.MustCompile(`\{[^}]*\}|\[[^\]]*\]`)
+	// promQLGrouping matches grouping and vector matching clauses, which list label names
+	promQLGrouping = regexp.MustCompile(`(?i)\b(?:by|without|on|ignoring|group_left|group_right)\s*\([^)]*\)`)
+	// promQLIdentifier matches an identifier that isn't part of a number or duration
+	promQLIdentifier = regexp.MustCompile(`(?:^|[^\w:.])([a-zA-Z_:][\w:]*)`)
+)
+
+// RawQuerySample is one sample of a raw query result. Value is null for NaN and infinite
+// values, which JSON can't represent.
+type RawQuerySample struct {
+	Metric map[string]string `json:"metric"`
+	Value  *float64          `json:"value"`
+}
+
+// RawQueryResult is the result of a raw PromQL query
+type RawQueryResult struct {
+	Query      string           `json:"query"`
+	Time       time.Time        `json:"time"`
+	ResultType string           `json:"resultType"` // vector or scalar
+	Result     []RawQuerySample `json:"result"`
+}
+
+// ValidateLinkerdQuery checks that a PromQL query only selects Linkerd proxy metrics:
+// request_total, response_total, response_latency_ms_* and tcp_*. Selecting series by their
+// __name__ label is rejected, since the metric name can't be checked.
+func ValidateLinkerdQuery(query string) error {
+	if strings.TrimSpace(query) == "" {
+		return fmt.Errorf("query is empty")
+	}
+
+	stripped := promQLStrings.ReplaceAllString(query, `""`)
+	if strings.Contains(stripped, "__name__") {
+		return fmt.Errorf("selecting metrics by __name__ is not allowed")
+	}
+	stripped = promQLMatchers.ReplaceAllString(stripped, " ")
+	stripped = promQLGrouping.ReplaceAllString(stripped, " ")
+
+	for _, match := range promQLIdentifier.FindAllStringSubmatchIndex(stripped, -1) {
+		name := stripped[match[2]:match[3]]
+		// Functions and aggregations are followed by their arguments
+		call := strings.HasPrefix(strings.TrimLeft(stripped[match[3]:], " \t\r\n"), "(")
+		if call || promQLKeywords[strings.ToLower(name)] {
+			continue
+		}
+		if !isLinkerdMetric(name) {
+			return fmt.Errorf("metric %s is not a Linkerd proxy metric (allowed: request_total, response_total, response_latency_ms_*, tcp_*)", name)
+		}
+	}
+	return nil
+}
+
+func isLinkerdMetric(name string) bool {
+	for _, pattern := range linkerdMetricPatterns {
+		if pattern.MatchString(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseQueryTime parses the evaluation time of a raw query: RFC 3339 or Unix seconds, and now
+// when empty
+func parseQueryTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Now(), nil
+	}
+	if ts, err := time.Parse(time.RFC3339, value); err == nil {
+		return ts, nil
+	}
+	seconds, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%s is neither an RFC 3339 time nor Unix seconds", value)
+	}
+	return time.Unix(0, int64(seconds*float64(time.Second))), nil
+}
+
+// QueryMetrics runs a raw PromQL instant query at timestamp, now when empty, and returns its
+// vector or scalar result. With linkerdOnly, the query may only select Linkerd proxy metrics.
+func (c *MetricsCollector) QueryMetrics(ctx context.Context, query, timestamp string, linkerdOnly bool) (*mcp.CallToolResult, error) {
+	if !c.Available() {
+		return unavailableResult(), nil
+	}
+
+	if strings.TrimSpace(query) == "" {
+		return mcp.NewToolResultError("Invalid query: query is empty"), nil
+	}
+	if linkerdOnly {
+		if err := ValidateLinkerdQuery(query); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Query not allowed: %v", err)), nil
+		}
+	}
+
+	ts, err := parseQueryTime(timestamp)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Invalid time: %v", err)), nil
+	}
+
+	value, err := c.promClient.Query(ctx, query, ts)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to run query: %v", err)), nil
+	}
+
+	result := RawQueryResult{Query: query, Time: ts, Result: []RawQuerySample{}}
+	switch value := value.(type) {
+	case model.Vector:
+		result.ResultType = "vector"
+		for _, sample := range value {
+			labels := map[string]string{}
+			for name, labelValue := range sample.Metric {
+				labels[string(name)] = string(labelValue)
+			}
+			result.Result = append(result.Result, RawQuerySample{Metric: labels, Value: finiteValue(float64(sample.Value))})
+		}
+	case *model.Scalar:
+		result.ResultType = "scalar"
+		result.Result = append(result.Result, RawQuerySample{Metric: map[string]string{}, Value: finiteValue(float64(value.Value))})
+	default:
+		return mcp.NewToolResultError(fmt.Sprintf("Unsupported result type %s: the query must return an instant vector or a scalar", value.Type())), nil
+	}
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal query result: %v", err)), nil
+	}
+
+	return mcp.NewToolResultText(string(data)), nil
+}
+
+// finiteValue returns a value, or nil when it is NaN or infinite
+func finiteValue(v float64) *float64 {
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return nil
+	}
+	return &v
+}
@@ -0,0 +1,128 @@
+package metrics_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+
+	"github.com/christianhuening/linkerd-mcp/internal/metrics"
+	"github.com/christianhuening/linkerd-mcp/internal/testutil"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Raw PromQL queries", func() {
+	Describe("ValidateLinkerdQuery", func() {
+		It("should accept queries over Linkerd proxy metrics", func() {
+			for _, query := range []string{
+				`sum(rate(request_total{namespace="prod", direction="inbound"}[5m])) by (deployment)`,
+				`histogram_quantile(0.99, sum by (le) (rate(response_latency_ms_bucket{deployment="web"}[1m])))`,
+				`sum(rate(response_total{classification="failure"}[5m])) / sum(rate(response_total[5m])) * 100`,
+				`tcp_open_connections{namespace="prod"} offset 1h > bool 10`,
+				`sum(rate(tcp_read_bytes_total[5m])) * on(pod) group_left(node) max(rate(tcp_write_bytes_total[5m])) by (pod)`,
+			} {
+				Expect(metrics.ValidateLinkerdQuery(query)).To(Succeed(), query)
+			}
+		})
+
+		It("should reject other metrics", func() {
+			Expect(metrics.ValidateLinkerdQuery(`sum(rate(container_cpu_usage_seconds_total[5m]))`)).
+				To(MatchError(ContainSubstring("container_cpu_usage_seconds_total")))
+			Expect(metrics.ValidateLinkerdQuery(`request_total / up`)).To(MatchError(ContainSubstring("up")))
+		})
+
+		It("should not mistake label names and values for metrics", func() {
+			Expect(metrics.ValidateLinkerdQuery(`request_total{pod=~"kube_.*"} unless on(pod) response_total`)).To(Succeed())
+		})
+
+		It("should reject selecting metrics by name", func() {
+			Expect(metrics.ValidateLinkerdQuery(`{__name__="node_cpu_seconds_total"}`)).To(MatchError(ContainSubstring("__name__")))
+		})
+	})
+
+	Describe("QueryMetrics", func() {
+		var (
+			ctx       context.Context
+			collector *metrics.MetricsCollector
+			queries   []string
+		)
+
+		BeforeEach(func() {
+			ctx = context.Background()
+			queries = nil
+			prometheus := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				Expect(r.ParseForm()).To(Succeed())
+				queries = append(queries, r.Form.Get("query"))
+				w.Header().Set("Content-Type", "application/json")
+				switch r.Form.Get("query") {
+				case "scalar(request_total)":
+					_, _ = w.Write([]byte(`{"status":"success","data":{"resultType":"scalar","result":[1700000000,"NaN"]}}`))
+				case "request_total[5m]":
+					_, _ = w.Write([]byte(`{"status":"success","data":{"resultType":"matrix","result":[]}}`))
+				default:
+					_, _ = w.Write([]byte(`{"status":"success","data":{"resultType":"vector","result":[` +
+						`{"metric":{"deployment":"web"},"value":[1700000000,"12.5"]}]}}`))
+				}
+			}))
+			DeferCleanup(prometheus.Close)
+
+			os.Setenv("LINKERD_PROMETHEUS_URL", prometheus.URL)
+			DeferCleanup(os.Unsetenv, "LINKERD_PROMETHEUS_URL")
+
+			var err error
+			collector, err = metrics.NewMetricsCollector(nil, nil, nil, "linkerd")
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("should return the samples of a vector", func() {
+			result, err := collector.QueryMetrics(ctx, `sum(rate(request_total[5m])) by (deployment)`, "1700000000", true)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.IsError).To(BeFalse())
+
+			var response metrics.RawQueryResult
+			Expect(testutil.ParseJSONResult(result, &response)).To(Succeed())
+			Expect(response.ResultType).To(Equal("vector"))
+			Expect(response.Time.Unix()).To(BeNumerically("==", 1700000000))
+			Expect(response.Result).To(HaveLen(1))
+			Expect(response.Result[0].Metric).To(Equal(map[string]string{"deployment": "web"}))
+			Expect(*response.Result[0].Value).To(BeNumerically("==", 12.5))
+		})
+
+		It("should report a NaN scalar as null", func() {
+			result, err := collector.QueryMetrics(ctx, "scalar(request_total)", "", true)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.IsError).To(BeFalse())
+
+			var response metrics.RawQueryResult
+			Expect(testutil.ParseJSONResult(result, &response)).To(Succeed())
+			Expect(response.ResultType).To(Equal("scalar"))
+			Expect(response.Result).To(HaveLen(1))
+			Expect(response.Result[0].Value).To(BeNil())
+		})
+
+		It("should reject range vector results", func() {
+			result, err := collector.QueryMetrics(ctx, "request_total[5m]", "", true)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.IsError).To(BeTrue())
+		})
+
+		It("should not send disallowed queries to Prometheus", func() {
+			result, err := collector.QueryMetrics(ctx, "up", "", true)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.IsError).To(BeTrue())
+			Expect(queries).To(BeEmpty())
+
+			result, err = collector.QueryMetrics(ctx, "up", "", false)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.IsError).To(BeFalse())
+			Expect(queries).To(Equal([]string{"up"}))
+		})
+
+		It("should reject an invalid time", func() {
+			result, err := collector.QueryMetrics(ctx, "request_total", "yesterday", true)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.IsError).To(BeTrue())
+		})
+	})
+})
@@ -0,0 +1,320 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/christianhuening/linkerd-mcp/internal/kube"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/portforward"
+	"k8s.io/client-go/transport/spdy"
+)
+
+// portForwardReadyTimeout bounds how long establishing the port-forward may take
+const portForwardReadyTimeout = 30 * time.Second
+
+// PortForwardTarget is the Service a port-forward to Prometheus goes to, as configured by
+// LINKERD_PROMETHEUS_PORTFORWARD
+type PortForwardTarget struct {
+	Namespace string
+	Service   string
+	Port      int32
+}
+
+// ParsePortForwardTarget parses a port-forward target of the form namespace/service:port
+func ParsePortForwardTarget(value string) (PortForwardTarget, error) {
+	namespace, rest, ok := strings.Cut(strings.TrimSpace(value), "/")
+	if !ok || namespace == "" {
+		return PortForwardTarget{}, fmt.Errorf("invalid port-forward target %q: must be namespace/service:port", value)
+	}
+	service, portStr, ok := strings.Cut(rest, ":")
+	if !ok || service == "" {
+		return PortForwardTarget{}, fmt.Errorf("invalid port-forward target %q: must be namespace/service:port", value)
+	}
+	port, err := strconv.ParseInt(portStr, 10, 32)
+	if err != nil || port <= 0 || port > 65535 {
+		return PortForwardTarget{}, fmt.Errorf("invalid port-forward target %q: port must be between 1 and 65535", value)
+	}
+	return PortForwardTarget{Namespace: namespace, Service: service, Port: int32(port)}, nil
+}
+
+// ResolvePortForwardTarget finds a running, ready pod behind the Service of a target and the
+// container port its service port targets. Port-forwards go to pods, not Services.
+func ResolvePortForwardTarget(ctx context.Context, clientset kubernetes.Interface, target PortForwardTarget) (string, int32, error) {
+	svc, err := clientset.CoreV1().Services(target.Namespace).Get(ctx, target.Service, metav1.GetOptions{})
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to get service %s/%s: %w", target.Namespace, target.Service, err)
+	}
+
+	var servicePort *corev1.ServicePort
+	for i := range svc.Spec.Ports {
+		if svc.Spec.Ports[i].Port == target.Port {
+			servicePort = &svc.Spec.Ports[i]
+			break
+		}
+	}
+	if servicePort == nil {
+		return "", 0, fmt.Errorf("service %s/%s has no port %d", target.Namespace, target.Service, target.Port)
+	}
+	if len(svc.Spec.Selector) == 0 {
+		return "", 0, fmt.Errorf("service %s/%s has no selector", target.Namespace, target.Service)
+	}
+
+	pods, err := kube.ListPods(ctx, clientset, target.Namespace, metav1.ListOptions{
+		LabelSelector: labels.SelectorFromSet(svc.Spec.Selector).String(),
+	})
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to list pods of service %s/%s: %w", target.Namespace, target.Service, err)
+	}
+
+	for _, pod := range pods.Items {
+		if pod.Status.Phase != corev1.PodRunning || !podReady(pod) {
+			continue
+		}
+		if port, ok := containerPort(pod, *servicePort); ok {
+			return pod.Name, port, nil
+		}
+	}
+	return "", 0, fmt.Errorf("no running pod of service %s/%s serves port %d", target.Namespace, target.Service, target.Port)
+}
+
+// containerPort maps a service port to the container port of a pod: a numeric target port as
+// is, a named one by the container port of that name, and no target port to the service port
+func containerPort(pod corev1.Pod, servicePort corev1.ServicePort) (int32, bool) {
+	targetPort := servicePort.TargetPort
+	if targetPort.StrVal == "" {
+		if targetPort.IntVal == 0 {
+			return servicePort.Port, true
+		}
+		return targetPort.IntVal, true
+	}
+	for _, container := range pod.Spec.Containers {
+		for _, port := range container.Ports {
+			if port.Name == targetPort.StrVal {
+				return port.ContainerPort, true
+			}
+		}
+	}
+	return 0, false
+}
+
+func podReady(pod corev1.Pod) bool {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// prometheusPortForward forwards a local port to a Prometheus pod through the API server. It is
+// the round tripper of the Prometheus client: queries go to the local port of the current
+// forward, and when the pod goes away the target is resolved again and a new forward started.
+type prometheusPortForward struct {
+	target    PortForwardTarget
+	transport http.RoundTripper
+	// connect resolves the target and starts a forward to its pod
+	connect func(ctx context.Context) (*portForwardSession, error)
+
+	mu      sync.Mutex
+	session *portForwardSession
+	closed  bool
+}
+
+// portForwardSession is one forward to a pod, on the local address host
+type portForwardSession struct {
+	host   string
+	stopCh chan struct{}
+	doneCh chan struct{} // closed when the forward ends, e.g. because the pod was deleted
+}
+
+// startPortForward forwards a random local port to the pod behind a target and returns once the
+// forward is ready. Queries are sent through transport. The forward runs until it is closed.
+func startPortForward(ctx context.Context, config *rest.Config, clientset kubernetes.Interface, target PortForwardTarget, transport http.RoundTripper) (*prometheusPortForward, error) {
+	if config == nil {
+		return nil, fmt.Errorf("port-forward needs a Kubernetes REST config")
+	}
+
+	return newPrometheusPortForward(ctx, target, transport, func(ctx context.Context) (*portForwardSession, error) {
+		return forwardToPod(ctx, config, clientset, target)
+	})
+}
+
+func newPrometheusPortForward(ctx context.Context, target PortForwardTarget, transport http.RoundTripper, connect func(ctx context.Context) (*portForwardSession, error)) (*prometheusPortForward, error) {
+	session, err := connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &prometheusPortForward{target: target, transport: transport, connect: connect, session: session}, nil
+}
+
+// forwardToPod resolves the pod of a target and forwards a random local port to it
+func forwardToPod(ctx context.Context, config *rest.Config, clientset kubernetes.Interface, target PortForwardTarget) (*portForwardSession, error) {
+	pod, port, err := ResolvePortForwardTarget(ctx, clientset, target)
+	if err != nil {
+		return nil, err
+	}
+
+	transport, upgrader, err := spdy.RoundTripperFor(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create port-forward transport: %w", err)
+	}
+	forwardURL := clientset.CoreV1().RESTClient().Post().
+		Resource("pods").
+		Namespace(target.Namespace).
+		Name(pod).
+		SubResource("portforward").
+		URL()
+	dialer := spdy.NewDialer(upgrader, &http.Client{Transport: transport}, http.MethodPost, forwardURL)
+
+	stopCh := make(chan struct{})
+	readyCh := make(chan struct{})
+	forwarder, err := portforward.NewOnAddresses(dialer, []string{"127.0.0.1"}, []string{fmt.Sprintf("0:%d", port)}, stopCh, readyCh, io.Discard, io.Discard)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create port-forward: %w", err)
+	}
+
+	errCh := make(chan error, 1)
+	doneCh := make(chan struct{})
+	go func() {
+		errCh <- forwarder.ForwardPorts()
+		close(doneCh)
+	}()
+
+	select {
+	case <-readyCh:
+	case err := <-errCh:
+		return nil, fmt.Errorf("port-forward to pod %s/%s failed: %w", target.Namespace, pod, err)
+	case <-ctx.Done():
+		close(stopCh)
+		return nil, fmt.Errorf("port-forward to pod %s/%s not ready: %w", target.Namespace, pod, ctx.Err())
+	case <-time.After(portForwardReadyTimeout):
+		close(stopCh)
+		return nil, fmt.Errorf("port-forward to pod %s/%s not ready after %s", target.Namespace, pod, portForwardReadyTimeout)
+	}
+
+	ports, err := forwarder.GetPorts()
+	if err != nil || len(ports) == 0 {
+		close(stopCh)
+		return nil, fmt.Errorf("failed to get the local port of the port-forward: %v", err)
+	}
+
+	return &portForwardSession{
+		host:   fmt.Sprintf("127.0.0.1:%d", ports[0].Local),
+		stopCh: stopCh,
+		doneCh: doneCh,
+	}, nil
+}
+
+// localURL is the address the Prometheus client is created with. RoundTrip sends every request
+// to the current forward, whatever its port.
+func (f *prometheusPortForward) localURL() string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return "http://" + f.session.host
+}
+
+// RoundTrip sends a request to the local port of the current forward. When the forward has
+// ended or the request fails on the connection, the forward is restarted to a pod resolved
+// again and the request retried once.
+func (f *prometheusPortForward) RoundTrip(req *http.Request) (*http.Response, error) {
+	session, err := f.current(req.Context(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := f.send(session, req)
+	if err == nil || req.Context().Err() != nil {
+		return resp, err
+	}
+	if req.Body != nil && req.GetBody == nil {
+		return nil, err
+	}
+
+	session, restartErr := f.current(req.Context(), session)
+	if restartErr != nil {
+		return nil, fmt.Errorf("%w; re-establishing the port-forward to %s/%s failed: %v", err, f.target.Namespace, f.target.Service, restartErr)
+	}
+	return f.send(session, req)
+}
+
+// current returns the running forward. It restarts the forward when it has ended, or when it is
+// failed, the session a request just failed on.
+func (f *prometheusPortForward) current(ctx context.Context, failed *portForwardSession) (*portForwardSession, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.closed {
+		return nil, fmt.Errorf("port-forward to %s/%s is closed", f.target.Namespace, f.target.Service)
+	}
+	if f.session != failed && !f.session.ended() {
+		return f.session, nil
+	}
+
+	f.session.stop()
+	if idle, ok := f.transport.(interface{ CloseIdleConnections() }); ok {
+		idle.CloseIdleConnections()
+	}
+	session, err := f.connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	f.session = session
+	return session, nil
+}
+
+// send sends a copy of req to the local address of a session
+func (f *prometheusPortForward) send(session *portForwardSession, req *http.Request) (*http.Response, error) {
+	out := req.Clone(req.Context())
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		out.Body = body
+	}
+	out.URL.Host = session.host
+	out.Host = session.host
+	return f.transport.RoundTrip(out)
+}
+
+// Close stops the port-forward
+func (f *prometheusPortForward) Close() {
+	if f == nil {
+		return
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.closed = true
+	f.session.stop()
+}
+
+// ended reports whether the forward ended or was stopped
+func (s *portForwardSession) ended() bool {
+	select {
+	case <-s.doneCh:
+		return true
+	case <-s.stopCh:
+		return true
+	default:
+		return false
+	}
+}
+
+func (s *portForwardSession) stop() {
+	select {
+	case <-s.stopCh:
+	default:
+		close(s.stopCh)
+	}
+}
@@ -0,0 +1,214 @@
+package metrics_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/christianhuening/linkerd-mcp/internal/metrics"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+var _ = Describe("ParsePortForwardTarget", func() {
+	It("should parse namespace/service:port", func() {
+		target, err := metrics.ParsePortForwardTarget("linkerd-viz/prometheus:9090")
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(target).To(Equal(metrics.PortForwardTarget{Namespace: "linkerd-viz", Service: "prometheus", Port: 9090}))
+	})
+
+	It("should reject malformed targets", func() {
+		for _, value := range []string{"prometheus:9090", "linkerd-viz/prometheus", "/prometheus:9090", "linkerd-viz/:9090", "linkerd-viz/prometheus:http", "linkerd-viz/prometheus:0", "linkerd-viz/prometheus:70000"} {
+			_, err := metrics.ParsePortForwardTarget(value)
+			Expect(err).To(HaveOccurred(), value)
+		}
+	})
+})
+
+var _ = Describe("ResolvePortForwardTarget", func() {
+	var (
+		ctx    context.Context
+		target metrics.PortForwardTarget
+	)
+
+	prometheusService := func(targetPort intstr.IntOrString) *corev1.Service {
+		return &corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: "prometheus", Namespace: "linkerd-viz"},
+			Spec: corev1.ServiceSpec{
+				Selector: map[string]string{"component": "prometheus"},
+				Ports:    []corev1.ServicePort{{Name: "admin-http", Port: 9090, TargetPort: targetPort}},
+			},
+		}
+	}
+
+	prometheusPod := func(name string, phase corev1.PodPhase, ready corev1.ConditionStatus) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "linkerd-viz", Labels: map[string]string{"component": "prometheus"}},
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{{
+					Name:  "prometheus",
+					Ports: []corev1.ContainerPort{{Name: "admin-http", ContainerPort: 9091}},
+				}},
+			},
+			Status: corev1.PodStatus{
+				Phase:      phase,
+				Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: ready}},
+			},
+		}
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		target = metrics.PortForwardTarget{Namespace: "linkerd-viz", Service: "prometheus", Port: 9090}
+	})
+
+	It("should pick a running, ready pod and map a named target port", func() {
+		clientset := fake.NewSimpleClientset(
+			prometheusService(intstr.FromString("admin-http")),
+			prometheusPod("prometheus-pending", corev1.PodPending, corev1.ConditionFalse),
+			prometheusPod("prometheus-unready", corev1.PodRunning, corev1.ConditionFalse),
+			prometheusPod("prometheus-ready", corev1.PodRunning, corev1.ConditionTrue),
+		)
+
+		pod, port, err := metrics.ResolvePortForwardTarget(ctx, clientset, target)
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(pod).To(Equal("prometheus-ready"))
+		Expect(port).To(Equal(int32(9091)))
+	})
+
+	It("should use a numeric target port as is", func() {
+		clientset := fake.NewSimpleClientset(
+			prometheusService(intstr.FromInt32(9092)),
+			prometheusPod("prometheus-ready", corev1.PodRunning, corev1.ConditionTrue),
+		)
+
+		_, port, err := metrics.ResolvePortForwardTarget(ctx, clientset, target)
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(port).To(Equal(int32(9092)))
+	})
+
+	It("should fail when the service has no such port", func() {
+		clientset := fake.NewSimpleClientset(prometheusService(intstr.FromInt32(9090)))
+		target.Port = 8080
+
+		_, _, err := metrics.ResolvePortForwardTarget(ctx, clientset, target)
+
+		Expect(err).To(MatchError(ContainSubstring("has no port 8080")))
+	})
+
+	It("should fail when no pod is ready", func() {
+		clientset := fake.NewSimpleClientset(
+			prometheusService(intstr.FromInt32(9090)),
+			prometheusPod("prometheus-unready", corev1.PodRunning, corev1.ConditionFalse),
+		)
+
+		_, _, err := metrics.ResolvePortForwardTarget(ctx, clientset, target)
+
+		Expect(err).To(MatchError(ContainSubstring("no running pod")))
+	})
+
+	It("should fail when the service doesn't exist", func() {
+		_, _, err := metrics.ResolvePortForwardTarget(ctx, fake.NewSimpleClientset(), target)
+
+		Expect(err).To(MatchError(ContainSubstring("failed to get service linkerd-viz/prometheus")))
+	})
+})
+
+var _ = Describe("Port-forward to Prometheus", func() {
+	var (
+		connects int
+		hosts    []string
+		doneChs  []chan struct{}
+	)
+
+	prometheus := func(name string) string {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = io.WriteString(w, name)
+		}))
+		DeferCleanup(server.Close)
+		return strings.TrimPrefix(server.URL, "http://")
+	}
+
+	BeforeEach(func() {
+		connects = 0
+		hosts = nil
+		doneChs = []chan struct{}{make(chan struct{}), make(chan struct{})}
+	})
+
+	newForward := func() *metrics.PortForward {
+		forward, err := metrics.NewPortForward(func() (string, chan struct{}, error) {
+			connects++
+			if connects > len(hosts) {
+				return "", nil, errors.New("no running pod of service linkerd-viz/prometheus serves port 9090")
+			}
+			return hosts[connects-1], doneChs[connects-1], nil
+		})
+		Expect(err).NotTo(HaveOccurred())
+		return forward
+	}
+
+	get := func(forward *metrics.PortForward) (string, error) {
+		resp, err := (&http.Client{Transport: forward}).Get("http://prometheus/api/v1/query")
+		if err != nil {
+			return "", err
+		}
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		return string(body), err
+	}
+
+	It("should send queries to the current forward", func() {
+		hosts = []string{prometheus("first")}
+
+		Expect(get(newForward())).To(Equal("first"))
+		Expect(connects).To(Equal(1))
+	})
+
+	It("should forward to a pod resolved again once the forward ended", func() {
+		hosts = []string{prometheus("first"), prometheus("second")}
+		forward := newForward()
+		close(doneChs[0])
+
+		Expect(get(forward)).To(Equal("second"))
+		Expect(connects).To(Equal(2))
+	})
+
+	It("should restart the forward when a query fails on the connection", func() {
+		server := httptest.NewServer(http.NotFoundHandler())
+		server.Close()
+		hosts = []string{strings.TrimPrefix(server.URL, "http://"), prometheus("second")}
+		forward := newForward()
+
+		Expect(get(forward)).To(Equal("second"))
+		Expect(get(forward)).To(Equal("second"))
+		Expect(connects).To(Equal(2))
+	})
+
+	It("should report when the forward can't be re-established", func() {
+		server := httptest.NewServer(http.NotFoundHandler())
+		server.Close()
+		hosts = []string{strings.TrimPrefix(server.URL, "http://")}
+
+		_, err := get(newForward())
+		Expect(err).To(MatchError(ContainSubstring("re-establishing the port-forward to linkerd-viz/prometheus failed")))
+	})
+
+	It("should fail queries once closed", func() {
+		hosts = []string{prometheus("first")}
+		forward := newForward()
+		forward.Close()
+
+		_, err := get(forward)
+		Expect(err).To(MatchError(ContainSubstring("closed")))
+	})
+})
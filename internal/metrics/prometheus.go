@@ -3,10 +3,13 @@ package metrics
 import (
 	"context"
 	"fmt"
+	"log"
+	"math"
 	"net/http"
 	"os"
 	"time"
 
+	"github.com/christianhuening/linkerd-mcp/internal/telemetry"
 	"github.com/prometheus/client_golang/api"
 	prometheusv1 "github.com/prometheus/client_golang/api/prometheus/v1"
 	"github.com/prometheus/common/model"
@@ -18,10 +21,14 @@ import (
 type PrometheusClient struct {
 	api       prometheusv1.API
 	namespace string
+	// portForward carries the queries when LINKERD_PROMETHEUS_PORTFORWARD is set
+	portForward *prometheusPortForward
 }
 
 // NewPrometheusClient creates a new Prometheus client
-// It attempts to connect to the Linkerd Prometheus instance
+// It attempts to connect to the Linkerd Prometheus instance. LINKERD_PROMETHEUS_URL takes
+// precedence; otherwise LINKERD_PROMETHEUS_PORTFORWARD (namespace/service:port) forwards a local
+// port to a pod of that Service, for running outside the cluster. Close stops the forward.
 func NewPrometheusClient(config *rest.Config, clientset kubernetes.Interface, namespace string) (*PrometheusClient, error) {
 	if namespace == "" {
 		namespace = "linkerd" // default Linkerd namespace
@@ -29,6 +36,25 @@ func NewPrometheusClient(config *rest.Config, clientset kubernetes.Interface, na
 
 	// Get Prometheus URL from environment or use default
 	promURL := os.Getenv("LINKERD_PROMETHEUS_URL")
+	var transport http.RoundTripper = &http.Transport{
+		MaxIdleConns:       10,
+		IdleConnTimeout:    30 * time.Second,
+		DisableCompression: true,
+	}
+	var forward *prometheusPortForward
+	if target := os.Getenv("LINKERD_PROMETHEUS_PORTFORWARD"); promURL == "" && target != "" {
+		parsed, err := ParsePortForwardTarget(target)
+		if err != nil {
+			return nil, fmt.Errorf("invalid LINKERD_PROMETHEUS_PORTFORWARD: %w", err)
+		}
+		forward, err = startPortForward(context.Background(), config, clientset, parsed, transport)
+		if err != nil {
+			return nil, fmt.Errorf("failed to port-forward to Prometheus: %w", err)
+		}
+		// The forward picks the pod again when its pod goes away, so queries go through it
+		promURL = forward.localURL()
+		transport = forward
+	}
 	if promURL == "" {
 		// Default to in-cluster service
 		promURL = fmt.Sprintf("http://prometheus.%s.svc.cluster.local:9090", namespace)
@@ -36,25 +62,32 @@ func NewPrometheusClient(config *rest.Config, clientset kubernetes.Interface, na
 
 	// Create Prometheus API client
 	client, err := api.NewClient(api.Config{
-		Address: promURL,
-		RoundTripper: &http.Transport{
-			MaxIdleConns:       10,
-			IdleConnTimeout:    30 * time.Second,
-			DisableCompression: true,
-		},
+		Address:      promURL,
+		RoundTripper: transport,
 	})
 	if err != nil {
+		forward.Close()
 		return nil, fmt.Errorf("failed to create Prometheus client: %w", err)
 	}
 
 	return &PrometheusClient{
-		api:       prometheusv1.NewAPI(client),
-		namespace: namespace,
+		api:         prometheusv1.NewAPI(client),
+		namespace:   namespace,
+		portForward: forward,
 	}, nil
 }
 
+// Close releases the port-forward to Prometheus, if any
+func (c *PrometheusClient) Close() {
+	if c == nil {
+		return
+	}
+	c.portForward.Close()
+}
+
 // Query executes an instant Prometheus query
 func (c *PrometheusClient) Query(ctx context.Context, query string, ts time.Time) (model.Value, error) {
+	defer telemetry.ObservePrometheusQuery("instant", time.Now())
 	result, warnings, err := c.api.Query(ctx, query, ts)
 	if err != nil {
 		return nil, fmt.Errorf("prometheus query failed: %w", err)
@@ -63,7 +96,7 @@ func (c *PrometheusClient) Query(ctx context.Context, query string, ts time.Time
 	if len(warnings) > 0 {
 		// Log warnings but don't fail
 		for _, w := range warnings {
-			fmt.Printf("Prometheus warning: %s\n", w)
+			log.Printf("Prometheus warning: %s", w)
 		}
 	}
 
@@ -78,6 +111,7 @@ func (c *PrometheusClient) QueryRange(ctx context.Context, query string, tr Time
 		Step:  tr.Step,
 	}
 
+	defer telemetry.ObservePrometheusQuery("range", time.Now())
 	result, warnings, err := c.api.QueryRange(ctx, query, r)
 	if err != nil {
 		return nil, fmt.Errorf("prometheus range query failed: %w", err)
@@ -85,7 +119,7 @@ func (c *PrometheusClient) QueryRange(ctx context.Context, query string, tr Time
 
 	if len(warnings) > 0 {
 		for _, w := range warnings {
-			fmt.Printf("Prometheus warning: %s\n", w)
+			log.Printf("Prometheus warning: %s", w)
 		}
 	}
 
@@ -95,6 +129,7 @@ func (c *PrometheusClient) QueryRange(ctx context.Context, query string, tr Time
 // GetLabelValues returns all values for a given label
 func (c *PrometheusClient) GetLabelValues(ctx context.Context, label string, startTime, endTime time.Time) ([]string, error) {
 	matches := []string{}
+	defer telemetry.ObservePrometheusQuery("label_values", time.Now())
 	labelValues, warnings, err := c.api.LabelValues(ctx, label, matches, startTime, endTime)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get label values: %w", err)
@@ -102,7 +137,7 @@ func (c *PrometheusClient) GetLabelValues(ctx context.Context, label string, sta
 
 	if len(warnings) > 0 {
 		for _, w := range warnings {
-			fmt.Printf("Prometheus warning: %s\n", w)
+			log.Printf("Prometheus warning: %s", w)
 		}
 	}
 
@@ -137,3 +172,32 @@ func extractScalarValue(value model.Value) (float64, error) {
 	}
 }
 
+// extractOptionalValue is like extractScalarValue, but returns nil instead of zero when the
+// query returned no samples or NaN (histogram_quantile over empty buckets)
+func extractOptionalValue(value model.Value) *float64 {
+	var v float64
+	switch result := value.(type) {
+	case model.Vector:
+		if len(result) == 0 {
+			return nil
+		}
+		v = float64(result[0].Value)
+	case *model.Scalar:
+		v = float64(result.Value)
+	default:
+		return nil
+	}
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return nil
+	}
+	return &v
+}
+
+// scaleOptional multiplies an optional value, keeping nil as nil
+func scaleOptional(v *float64, factor float64) *float64 {
+	if v == nil {
+		return nil
+	}
+	scaled := *v * factor
+	return &scaled
+}
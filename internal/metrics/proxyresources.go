@@ -0,0 +1,244 @@
+package metrics
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/christianhuening/linkerd-mcp/internal/kube"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/prometheus/common/model"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	proxyCPULimitAnnotation    = "config.linkerd.io/proxy-cpu-limit"
+	proxyMemoryLimitAnnotation = "config.linkerd.io/proxy-memory-limit"
+)
+
+// ProxyLimits are the CPU (cores) and memory (bytes) limits of a pod's proxy container. A nil
+// limit means the proxy is unbounded.
+type ProxyLimits struct {
+	CPUCores    *float64
+	MemoryBytes *float64
+}
+
+// ProxyResourceUsage is the resource consumption of a single proxy. CPU or memory usage is null
+// when the proxy didn't report the metric.
+type ProxyResourceUsage struct {
+	Pod                string   `json:"pod"`
+	Service            string   `json:"service"`
+	CPUCores           *float64 `json:"cpuCores"`
+	MemoryBytes        *float64 `json:"memoryBytes"`
+	CPULimitCores      *float64 `json:"cpuLimitCores,omitempty"`
+	MemoryLimitBytes   *float64 `json:"memoryLimitBytes,omitempty"`
+	ExceedsCPULimit    bool     `json:"exceedsCpuLimit"`
+	ExceedsMemoryLimit bool     `json:"exceedsMemoryLimit"`
+}
+
+// ServiceProxyUsage aggregates the proxies of one service
+type ServiceProxyUsage struct {
+	Service          string   `json:"service"`
+	Proxies          int      `json:"proxies"`
+	CPUCores         float64  `json:"cpuCores"`       // total over all proxies
+	MaxCPUCores      float64  `json:"maxCpuCores"`    // busiest single proxy
+	MemoryBytes      float64  `json:"memoryBytes"`    // total over all proxies
+	MaxMemoryBytes   float64  `json:"maxMemoryBytes"` // largest single proxy
+	ProxiesOverLimit []string `json:"proxiesOverLimit"`
+}
+
+// GetProxyResourceUsage reports the CPU and memory consumed by the proxies of a namespace, per
+// service and per proxy, and flags proxies exceeding the limits of their proxy container. Limits
+// come from the container resources, or from the proxy-cpu-limit and proxy-memory-limit
+// annotations when the container has none.
+func (c *MetricsCollector) GetProxyResourceUsage(ctx context.Context, namespace, timeRangeStr string) (*mcp.CallToolResult, error) {
+	if !c.Available() {
+		return unavailableResult(), nil
+	}
+
+	tr, err := ParseTimeRange(timeRangeStr)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Invalid time range: %v", err)), nil
+	}
+
+	window := tr.End.Sub(tr.Start)
+
+	cpuUsage, err := c.promClient.Query(ctx, c.queryBuilder.BuildProxyCPUUsageQuery(namespace, window), tr.End)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to query proxy CPU usage: %v", err)), nil
+	}
+	memoryUsage, err := c.promClient.Query(ctx, c.queryBuilder.BuildProxyMemoryUsageQuery(namespace), tr.End)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to query proxy memory usage: %v", err)), nil
+	}
+
+	proxies := BuildProxyResourceUsage(cpuUsage, memoryUsage, c.proxyLimits(ctx, namespace))
+	services := SummarizeProxyUsage(proxies)
+
+	proxiesOverLimit := 0
+	for _, proxy := range proxies {
+		if proxy.ExceedsCPULimit || proxy.ExceedsMemoryLimit {
+			proxiesOverLimit++
+		}
+	}
+
+	data, err := json.Marshal(map[string]interface{}{
+		"namespace":        namespace,
+		"timeRange":        tr,
+		"services":         services,
+		"proxies":          proxies,
+		"totalProxies":     len(proxies),
+		"proxiesOverLimit": proxiesOverLimit,
+	})
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal proxy resource usage: %v", err)), nil
+	}
+
+	return mcp.NewToolResultText(string(data)), nil
+}
+
+// proxyLimits reads the proxy limits of every pod in a namespace, keyed by pod name. Without a
+// Kubernetes client no limits are known.
+func (c *MetricsCollector) proxyLimits(ctx context.Context, namespace string) map[string]ProxyLimits {
+	limits := map[string]ProxyLimits{}
+	if c.clientset == nil {
+		return limits
+	}
+
+	pods, err := kube.ListPods(ctx, c.clientset, namespace, metav1.ListOptions{})
+	if err != nil {
+		return limits
+	}
+	for _, pod := range pods.Items {
+		limits[pod.Name] = PodProxyLimits(pod)
+	}
+	return limits
+}
+
+// PodProxyLimits returns the limits of a pod's proxy container, falling back to the proxy limit
+// annotations for limits the container doesn't set
+func PodProxyLimits(pod corev1.Pod) ProxyLimits {
+	limits := ProxyLimits{}
+	for _, container := range pod.Spec.Containers {
+		if !kube.IsProxyContainer(container.Name) {
+			continue
+		}
+		if cpu, ok := container.Resources.Limits[corev1.ResourceCPU]; ok {
+			limits.CPUCores = quantityValue(cpu)
+		}
+		if memory, ok := container.Resources.Limits[corev1.ResourceMemory]; ok {
+			limits.MemoryBytes = quantityValue(memory)
+		}
+	}
+
+	if limits.CPUCores == nil {
+		limits.CPUCores = annotationQuantity(pod.Annotations[proxyCPULimitAnnotation])
+	}
+	if limits.MemoryBytes == nil {
+		limits.MemoryBytes = annotationQuantity(pod.Annotations[proxyMemoryLimitAnnotation])
+	}
+	return limits
+}
+
+func quantityValue(quantity resource.Quantity) *float64 {
+	value := quantity.AsApproximateFloat64()
+	return &value
+}
+
+// annotationQuantity parses a resource quantity annotation; missing or invalid values are unset
+func annotationQuantity(value string) *float64 {
+	if value == "" {
+		return nil
+	}
+	quantity, err := resource.ParseQuantity(value)
+	if err != nil {
+		return nil
+	}
+	return quantityValue(quantity)
+}
+
+// BuildProxyResourceUsage joins the per-pod CPU and memory samples of the proxies with their
+// limits. The service of a proxy is its deployment label, or the pod name if it has none.
+// Proxies are sorted by service and pod.
+func BuildProxyResourceUsage(cpuUsage, memoryUsage model.Value, limits map[string]ProxyLimits) []ProxyResourceUsage {
+	proxies := map[string]*ProxyResourceUsage{}
+	collect := func(value model.Value, set func(proxy *ProxyResourceUsage, usage float64)) {
+		vector, ok := value.(model.Vector)
+		if !ok {
+			return
+		}
+		for _, sample := range vector {
+			pod := string(sample.Metric["pod"])
+			if pod == "" || math.IsNaN(float64(sample.Value)) {
+				continue
+			}
+			proxy, ok := proxies[pod]
+			if !ok {
+				proxy = &ProxyResourceUsage{Pod: pod, Service: string(sample.Metric["deployment"])}
+				if proxy.Service == "" {
+					proxy.Service = pod
+				}
+				proxies[pod] = proxy
+			}
+			set(proxy, float64(sample.Value))
+		}
+	}
+	collect(cpuUsage, func(proxy *ProxyResourceUsage, usage float64) { proxy.CPUCores = &usage })
+	collect(memoryUsage, func(proxy *ProxyResourceUsage, usage float64) { proxy.MemoryBytes = &usage })
+
+	result := make([]ProxyResourceUsage, 0, len(proxies))
+	for pod, proxy := range proxies {
+		if limit, ok := limits[pod]; ok {
+			proxy.CPULimitCores = limit.CPUCores
+			proxy.MemoryLimitBytes = limit.MemoryBytes
+		}
+		proxy.ExceedsCPULimit = exceeds(proxy.CPUCores, proxy.CPULimitCores)
+		proxy.ExceedsMemoryLimit = exceeds(proxy.MemoryBytes, proxy.MemoryLimitBytes)
+		result = append(result, *proxy)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Service != result[j].Service {
+			return result[i].Service < result[j].Service
+		}
+		return result[i].Pod < result[j].Pod
+	})
+	return result
+}
+
+func exceeds(usage, limit *float64) bool {
+	return usage != nil && limit != nil && *usage > *limit
+}
+
+// SummarizeProxyUsage aggregates proxy usage per service, in the order of the proxies
+func SummarizeProxyUsage(proxies []ProxyResourceUsage) []ServiceProxyUsage {
+	services := []ServiceProxyUsage{}
+	index := map[string]int{}
+	for _, proxy := range proxies {
+		i, ok := index[proxy.Service]
+		if !ok {
+			i = len(services)
+			index[proxy.Service] = i
+			services = append(services, ServiceProxyUsage{Service: proxy.Service, ProxiesOverLimit: []string{}})
+		}
+
+		service := &services[i]
+		service.Proxies++
+		if proxy.CPUCores != nil {
+			service.CPUCores += *proxy.CPUCores
+			service.MaxCPUCores = math.Max(service.MaxCPUCores, *proxy.CPUCores)
+		}
+		if proxy.MemoryBytes != nil {
+			service.MemoryBytes += *proxy.MemoryBytes
+			service.MaxMemoryBytes = math.Max(service.MaxMemoryBytes, *proxy.MemoryBytes)
+		}
+		if proxy.ExceedsCPULimit || proxy.ExceedsMemoryLimit {
+			service.ProxiesOverLimit = append(service.ProxiesOverLimit, proxy.Pod)
+		}
+	}
+	return services
+}
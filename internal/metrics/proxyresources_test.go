@@ -0,0 +1,113 @@
+package metrics_test
+
+import (
+	"github.com/christianhuening/linkerd-mcp/internal/metrics"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/common/model"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func proxySample(deployment, pod string, value float64) *model.Sample {
+	return &model.Sample{
+		Metric: model.Metric{"deployment": model.LabelValue(deployment), "pod": model.LabelValue(pod)},
+		Value:  model.SampleValue(value),
+	}
+}
+
+func limit(value float64) *float64 {
+	return &value
+}
+
+var _ = Describe("Proxy resource usage", func() {
+	Describe("PodProxyLimits", func() {
+		It("should read the limits of the proxy container", func() {
+			pod := corev1.Pod{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{Name: "app", Resources: corev1.ResourceRequirements{Limits: corev1.ResourceList{
+							corev1.ResourceCPU: resource.MustParse("4"),
+						}}},
+						{Name: "linkerd-proxy", Resources: corev1.ResourceRequirements{Limits: corev1.ResourceList{
+							corev1.ResourceCPU:    resource.MustParse("500m"),
+							corev1.ResourceMemory: resource.MustParse("128Mi"),
+						}}},
+					},
+				},
+			}
+
+			limits := metrics.PodProxyLimits(pod)
+			Expect(*limits.CPUCores).To(BeNumerically("~", 0.5, 0.001))
+			Expect(*limits.MemoryBytes).To(BeNumerically("==", 128*1024*1024))
+		})
+
+		It("should fall back to the proxy limit annotations", func() {
+			pod := corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{
+					"config.linkerd.io/proxy-cpu-limit":    "1",
+					"config.linkerd.io/proxy-memory-limit": "not-a-quantity",
+				}},
+				Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "linkerd-proxy"}}},
+			}
+
+			limits := metrics.PodProxyLimits(pod)
+			Expect(*limits.CPUCores).To(BeNumerically("==", 1))
+			Expect(limits.MemoryBytes).To(BeNil())
+		})
+	})
+
+	Describe("BuildProxyResourceUsage", func() {
+		It("should join usage with limits and flag proxies over their limits", func() {
+			cpuUsage := model.Vector{
+				proxySample("api", "api-1", 0.2),
+				proxySample("api", "api-2", 0.9),
+				proxySample("", "job-1", 0.01),
+			}
+			memoryUsage := model.Vector{
+				proxySample("api", "api-1", 200e6),
+				proxySample("api", "api-2", 20e6),
+			}
+			limits := map[string]metrics.ProxyLimits{
+				"api-1": {CPUCores: limit(0.5), MemoryBytes: limit(100e6)},
+				"api-2": {CPUCores: limit(0.5)},
+			}
+
+			proxies := metrics.BuildProxyResourceUsage(cpuUsage, memoryUsage, limits)
+			Expect(proxies).To(HaveLen(3))
+
+			Expect(proxies[0].Pod).To(Equal("api-1"))
+			Expect(proxies[0].ExceedsCPULimit).To(BeFalse())
+			Expect(proxies[0].ExceedsMemoryLimit).To(BeTrue())
+
+			Expect(proxies[1].Pod).To(Equal("api-2"))
+			Expect(proxies[1].ExceedsCPULimit).To(BeTrue())
+			Expect(proxies[1].MemoryLimitBytes).To(BeNil())
+
+			Expect(proxies[2].Service).To(Equal("job-1"))
+			Expect(proxies[2].MemoryBytes).To(BeNil())
+			Expect(proxies[2].ExceedsCPULimit).To(BeFalse())
+		})
+	})
+
+	Describe("SummarizeProxyUsage", func() {
+		It("should total usage per service and list proxies over their limits", func() {
+			proxies := metrics.BuildProxyResourceUsage(
+				model.Vector{proxySample("api", "api-1", 0.2), proxySample("api", "api-2", 0.9)},
+				model.Vector{proxySample("api", "api-1", 30e6), proxySample("api", "api-2", 20e6)},
+				map[string]metrics.ProxyLimits{"api-2": {CPUCores: limit(0.5)}},
+			)
+
+			services := metrics.SummarizeProxyUsage(proxies)
+			Expect(services).To(HaveLen(1))
+			Expect(services[0].Service).To(Equal("api"))
+			Expect(services[0].Proxies).To(Equal(2))
+			Expect(services[0].CPUCores).To(BeNumerically("~", 1.1, 0.001))
+			Expect(services[0].MaxCPUCores).To(BeNumerically("~", 0.9, 0.001))
+			Expect(services[0].MemoryBytes).To(BeNumerically("==", 50e6))
+			Expect(services[0].MaxMemoryBytes).To(BeNumerically("==", 30e6))
+			Expect(services[0].ProxiesOverLimit).To(ConsistOf("api-2"))
+		})
+	})
+})
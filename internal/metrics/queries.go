@@ -2,12 +2,47 @@ package metrics
 
 import (
 	"fmt"
+	"strings"
 	"time"
 )
 
+const (
+	// ProxyAdminPort is the port of the Linkerd proxy's admin server (metrics, readiness and liveness)
+	ProxyAdminPort = "4191"
+	// probeRouteName is the default inbound route Linkerd assigns to kubelet probe requests
+	probeRouteName = "probe"
+	// ProxyJob is the Prometheus scrape job of the proxies' admin endpoints
+	ProxyJob = "linkerd-proxy"
+)
+
+// Direction selects which side of a service's traffic the service queries measure
+type Direction string
+
+const (
+	// DirectionInbound measures the requests a service receives
+	DirectionInbound Direction = "inbound"
+	// DirectionOutbound measures the requests a service sends to its dependencies, as its own
+	// proxy experiences them
+	DirectionOutbound Direction = "outbound"
+)
+
+// ParseDirection parses a traffic direction, defaulting to inbound
+func ParseDirection(value string) (Direction, error) {
+	switch direction := Direction(value); direction {
+	case "":
+		return DirectionInbound, nil
+	case DirectionInbound, DirectionOutbound:
+		return direction, nil
+	default:
+		return "", fmt.Errorf("unsupported direction %q (must be one of: %s, %s)", value, DirectionInbound, DirectionOutbound)
+	}
+}
+
 // QueryBuilder helps construct PromQL queries for Linkerd metrics
 type QueryBuilder struct {
-	namespace string
+	namespace           string
+	excludeAdminTraffic bool
+	direction           Direction
 }
 
 // NewQueryBuilder creates a new query builder
@@ -15,16 +50,47 @@ func NewQueryBuilder(namespace string) *QueryBuilder {
 	return &QueryBuilder{namespace: namespace}
 }
 
+// SetExcludeAdminTraffic toggles filtering proxy admin port and probe traffic out of inbound
+// queries, so low-traffic services report real application traffic only
+func (qb *QueryBuilder) SetExcludeAdminTraffic(exclude bool) {
+	qb.excludeAdminTraffic = exclude
+}
+
+// WithDirection returns a copy of the query builder whose service queries (request, success and
+// error rates, latency, errors by status, retries and timeouts) measure the given direction
+func (qb *QueryBuilder) WithDirection(direction Direction) *QueryBuilder {
+	directed := *qb
+	directed.direction = direction
+	return &directed
+}
+
+// serviceDirection is the direction of the service queries, inbound unless set otherwise
+func (qb *QueryBuilder) serviceDirection() Direction {
+	if qb.direction == "" {
+		return DirectionInbound
+	}
+	return qb.direction
+}
+
+// filterInbound adds the admin traffic exclusion to the inbound selectors of a query when enabled
+func (qb *QueryBuilder) filterInbound(query string) string {
+	if !qb.excludeAdminTraffic {
+		return query
+	}
+	return strings.ReplaceAll(query, `direction="inbound"`,
+		fmt.Sprintf(`direction="inbound", target_port!="%s", route_name!="%s"`, ProxyAdminPort, probeRouteName))
+}
+
 // BuildServiceRequestRateQuery builds a query for service request rate (requests/sec)
 // Measures inbound requests to the service
 func (qb *QueryBuilder) BuildServiceRequestRateQuery(deployment, namespace string, window time.Duration) string {
 	if namespace == "" {
 		namespace = qb.namespace
 	}
-	return fmt.Sprintf(
-		`sum(rate(request_total{deployment="%s", namespace="%s", direction="inbound"}[%s]))`,
-		deployment, namespace, formatDuration(window),
-	)
+	return qb.filterInbound(fmt.Sprintf(
+		`sum(rate(request_total{deployment="%s", namespace="%s", direction="%s"}[%s]))`,
+		deployment, namespace, qb.serviceDirection(), formatDuration(window),
+	))
 }
 
 // BuildServiceSuccessRateQuery builds a query for service success rate (0-1)
@@ -33,11 +99,11 @@ func (qb *QueryBuilder) BuildServiceSuccessRateQuery(deployment, namespace strin
 	if namespace == "" {
 		namespace = qb.namespace
 	}
-	return fmt.Sprintf(
-		`sum(rate(response_total{deployment="%s", namespace="%s", classification!="failure", direction="inbound"}[%s])) / sum(rate(response_total{deployment="%s", namespace="%s", direction="inbound"}[%s]))`,
-		deployment, namespace, formatDuration(window),
-		deployment, namespace, formatDuration(window),
-	)
+	return qb.filterInbound(fmt.Sprintf(
+		`sum(rate(response_total{deployment="%s", namespace="%s", classification!="failure", direction="%s"}[%s])) / sum(rate(response_total{deployment="%s", namespace="%s", direction="%s"}[%s]))`,
+		deployment, namespace, qb.serviceDirection(), formatDuration(window),
+		deployment, namespace, qb.serviceDirection(), formatDuration(window),
+	))
 }
 
 // BuildServiceErrorRateQuery builds a query for service error rate (0-1)
@@ -46,11 +112,11 @@ func (qb *QueryBuilder) BuildServiceErrorRateQuery(deployment, namespace string,
 	if namespace == "" {
 		namespace = qb.namespace
 	}
-	return fmt.Sprintf(
-		`sum(rate(response_total{deployment="%s", namespace="%s", classification="failure", direction="inbound"}[%s])) / sum(rate(response_total{deployment="%s", namespace="%s", direction="inbound"}[%s]))`,
-		deployment, namespace, formatDuration(window),
-		deployment, namespace, formatDuration(window),
-	)
+	return qb.filterInbound(fmt.Sprintf(
+		`sum(rate(response_total{deployment="%s", namespace="%s", classification="failure", direction="%s"}[%s])) / sum(rate(response_total{deployment="%s", namespace="%s", direction="%s"}[%s]))`,
+		deployment, namespace, qb.serviceDirection(), formatDuration(window),
+		deployment, namespace, qb.serviceDirection(), formatDuration(window),
+	))
 }
 
 // BuildServiceLatencyQuery builds a query for service latency at a given quantile
@@ -59,10 +125,10 @@ func (qb *QueryBuilder) BuildServiceLatencyQuery(deployment, namespace string, q
 	if namespace == "" {
 		namespace = qb.namespace
 	}
-	return fmt.Sprintf(
-		`histogram_quantile(%.2f, sum(rate(response_latency_ms_bucket{deployment="%s", namespace="%s", direction="inbound"}[%s])) by (le))`,
-		quantile, deployment, namespace, formatDuration(window),
-	)
+	return qb.filterInbound(fmt.Sprintf(
+		`histogram_quantile(%.2f, sum(rate(response_latency_ms_bucket{deployment="%s", namespace="%s", direction="%s"}[%s])) by (le))`,
+		quantile, deployment, namespace, qb.serviceDirection(), formatDuration(window),
+	))
 }
 
 // BuildServiceMeanLatencyQuery builds a query for mean latency
@@ -70,13 +136,99 @@ func (qb *QueryBuilder) BuildServiceMeanLatencyQuery(deployment, namespace strin
 	if namespace == "" {
 		namespace = qb.namespace
 	}
-	return fmt.Sprintf(
-		`sum(rate(response_latency_ms_sum{deployment="%s", namespace="%s", direction="inbound"}[%s])) / sum(rate(response_latency_ms_count{deployment="%s", namespace="%s", direction="inbound"}[%s]))`,
+	return qb.filterInbound(fmt.Sprintf(
+		`sum(rate(response_latency_ms_sum{deployment="%s", namespace="%s", direction="%s"}[%s])) / sum(rate(response_latency_ms_count{deployment="%s", namespace="%s", direction="%s"}[%s]))`,
+		deployment, namespace, qb.serviceDirection(), formatDuration(window),
+		deployment, namespace, qb.serviceDirection(), formatDuration(window),
+	))
+}
+
+// BuildRouteRequestRateQuery builds a query for the inbound request rate of each route of a
+// deployment, as defined by its ServiceProfile or HTTPRoutes
+func (qb *QueryBuilder) BuildRouteRequestRateQuery(deployment, namespace string, window time.Duration) string {
+	if namespace == "" {
+		namespace = qb.namespace
+	}
+	return qb.filterInbound(fmt.Sprintf(
+		`sum(rate(route_request_total{deployment="%s", namespace="%s", direction="inbound"}[%s])) by (rt_route)`,
+		deployment, namespace, formatDuration(window),
+	))
+}
+
+// BuildRouteSuccessRateQuery builds a query for the success rate (0-1) of each route of a deployment
+func (qb *QueryBuilder) BuildRouteSuccessRateQuery(deployment, namespace string, window time.Duration) string {
+	if namespace == "" {
+		namespace = qb.namespace
+	}
+	return qb.filterInbound(fmt.Sprintf(
+		`sum(rate(route_response_total{deployment="%s", namespace="%s", classification!="failure", direction="inbound"}[%s])) by (rt_route) / sum(rate(route_response_total{deployment="%s", namespace="%s", direction="inbound"}[%s])) by (rt_route)`,
 		deployment, namespace, formatDuration(window),
 		deployment, namespace, formatDuration(window),
+	))
+}
+
+// BuildRouteFailuresQuery builds a query for the number of failed inbound responses of each route
+// of a deployment in the window, by status code
+func (qb *QueryBuilder) BuildRouteFailuresQuery(deployment, namespace string, window time.Duration) string {
+	if namespace == "" {
+		namespace = qb.namespace
+	}
+	return qb.filterInbound(fmt.Sprintf(
+		`sum(increase(route_response_total{deployment="%s", namespace="%s", classification="failure", direction="inbound"}[%s])) by (rt_route, status_code)`,
+		deployment, namespace, formatDuration(window),
+	))
+}
+
+// BuildRouteLatencyQuery builds a query for the latency of each route of a deployment at a given quantile
+func (qb *QueryBuilder) BuildRouteLatencyQuery(deployment, namespace string, quantile float64, window time.Duration) string {
+	if namespace == "" {
+		namespace = qb.namespace
+	}
+	return qb.filterInbound(fmt.Sprintf(
+		`histogram_quantile(%.2f, sum(rate(route_response_latency_ms_bucket{deployment="%s", namespace="%s", direction="inbound"}[%s])) by (le, rt_route))`,
+		quantile, deployment, namespace, formatDuration(window),
+	))
+}
+
+// BuildServiceRetryRateQuery builds a query for the retries per second clients send to a
+// deployment, i.e. the actual requests including retries minus the original requests. Retries are
+// made by the client proxies, so the query selects their outbound route metrics. Outbound, it is
+// the retries the deployment sends to its dependencies.
+func (qb *QueryBuilder) BuildServiceRetryRateQuery(deployment, namespace string, window time.Duration) string {
+	if namespace == "" {
+		namespace = qb.namespace
+	}
+	selector := qb.routeClientSelector(deployment, namespace)
+	return fmt.Sprintf(
+		`clamp_min(sum(rate(route_actual_request_total{%s, direction="outbound"}[%s])) - sum(rate(route_request_total{%s, direction="outbound"}[%s])), 0)`,
+		selector, formatDuration(window),
+		selector, formatDuration(window),
+	)
+}
+
+// BuildServiceTimeoutRateQuery builds a query for the requests per second to a deployment that hit
+// a route timeout. Client proxies answer those requests with a 504. Outbound, it is the requests
+// of the deployment to its dependencies that timed out.
+func (qb *QueryBuilder) BuildServiceTimeoutRateQuery(deployment, namespace string, window time.Duration) string {
+	if namespace == "" {
+		namespace = qb.namespace
+	}
+	return fmt.Sprintf(
+		`sum(rate(route_response_total{%s, direction="outbound", status_code="504"}[%s]))`,
+		qb.routeClientSelector(deployment, namespace), formatDuration(window),
 	)
 }
 
+// routeClientSelector selects the outbound route metrics of the service queries: those of the
+// clients calling the deployment, or for outbound queries those of the deployment itself calling
+// its dependencies
+func (qb *QueryBuilder) routeClientSelector(deployment, namespace string) string {
+	if qb.serviceDirection() == DirectionOutbound {
+		return fmt.Sprintf(`deployment="%s", namespace="%s"`, deployment, namespace)
+	}
+	return fmt.Sprintf(`dst_deployment="%s", dst_namespace="%s"`, deployment, namespace)
+}
+
 // BuildTrafficBetweenServicesQuery builds a query for traffic from source to target
 func (qb *QueryBuilder) BuildTrafficBetweenServicesQuery(srcDeployment, srcNamespace, dstDeployment, dstNamespace string, window time.Duration) string {
 	if srcNamespace == "" {
@@ -131,6 +283,18 @@ func (qb *QueryBuilder) BuildTopDestinationsQuery(srcDeployment, srcNamespace st
 	)
 }
 
+// BuildDestinationRequestRateQuery builds a query for the outbound request rate of a deployment,
+// grouped by every destination deployment and namespace
+func (qb *QueryBuilder) BuildDestinationRequestRateQuery(srcDeployment, srcNamespace string, window time.Duration) string {
+	if srcNamespace == "" {
+		srcNamespace = qb.namespace
+	}
+	return fmt.Sprintf(
+		`sum(rate(request_total{deployment="%s", namespace="%s", direction="outbound"}[%s])) by (dst_deployment, dst_namespace)`,
+		srcDeployment, srcNamespace, formatDuration(window),
+	)
+}
+
 // BuildTopSourcesQuery builds a query to find top sources to a destination
 func (qb *QueryBuilder) BuildTopSourcesQuery(dstDeployment, dstNamespace string, window time.Duration, limit int) string {
 	if dstNamespace == "" {
@@ -142,17 +306,249 @@ func (qb *QueryBuilder) BuildTopSourcesQuery(dstDeployment, dstNamespace string,
 	)
 }
 
-// BuildErrorsByStatusQuery builds a query for errors grouped by HTTP status code
-func (qb *QueryBuilder) BuildErrorsByStatusQuery(deployment, namespace string, window time.Duration) string {
+// BuildDestinationSuccessRateQuery builds a query for the success rate (0-1) from a source to each of its destinations
+func (qb *QueryBuilder) BuildDestinationSuccessRateQuery(srcDeployment, srcNamespace string, window time.Duration) string {
+	if srcNamespace == "" {
+		srcNamespace = qb.namespace
+	}
+	return fmt.Sprintf(
+		`sum(rate(response_total{deployment="%s", namespace="%s", classification!="failure", direction="outbound"}[%s])) by (dst_deployment, dst_namespace) / sum(rate(response_total{deployment="%s", namespace="%s", direction="outbound"}[%s])) by (dst_deployment, dst_namespace)`,
+		srcDeployment, srcNamespace, formatDuration(window),
+		srcDeployment, srcNamespace, formatDuration(window),
+	)
+}
+
+// BuildDestinationLatencyQuery builds a query for latency from a source to each of its destinations at a given quantile
+func (qb *QueryBuilder) BuildDestinationLatencyQuery(srcDeployment, srcNamespace string, quantile float64, window time.Duration) string {
+	if srcNamespace == "" {
+		srcNamespace = qb.namespace
+	}
+	return fmt.Sprintf(
+		`histogram_quantile(%.2f, sum(rate(response_latency_ms_bucket{deployment="%s", namespace="%s", direction="outbound"}[%s])) by (le, dst_deployment, dst_namespace))`,
+		quantile, srcDeployment, srcNamespace, formatDuration(window),
+	)
+}
+
+// BuildSourceSuccessRateQuery builds a query for the success rate (0-1) from each source to a destination
+func (qb *QueryBuilder) BuildSourceSuccessRateQuery(dstDeployment, dstNamespace string, window time.Duration) string {
+	if dstNamespace == "" {
+		dstNamespace = qb.namespace
+	}
+	return fmt.Sprintf(
+		`sum(rate(response_total{dst_deployment="%s", dst_namespace="%s", classification!="failure", direction="outbound"}[%s])) by (deployment, namespace) / sum(rate(response_total{dst_deployment="%s", dst_namespace="%s", direction="outbound"}[%s])) by (deployment, namespace)`,
+		dstDeployment, dstNamespace, formatDuration(window),
+		dstDeployment, dstNamespace, formatDuration(window),
+	)
+}
+
+// BuildSourceLatencyQuery builds a query for latency from each source to a destination at a given quantile
+func (qb *QueryBuilder) BuildSourceLatencyQuery(dstDeployment, dstNamespace string, quantile float64, window time.Duration) string {
+	if dstNamespace == "" {
+		dstNamespace = qb.namespace
+	}
+	return fmt.Sprintf(
+		`histogram_quantile(%.2f, sum(rate(response_latency_ms_bucket{dst_deployment="%s", dst_namespace="%s", direction="outbound"}[%s])) by (le, deployment, namespace))`,
+		quantile, dstDeployment, dstNamespace, formatDuration(window),
+	)
+}
+
+// BuildInboundSourceRequestRateQuery builds a query for the inbound response rate of a deployment,
+// grouped by the source deployment and namespace
+func (qb *QueryBuilder) BuildInboundSourceRequestRateQuery(deployment, namespace string, window time.Duration) string {
 	if namespace == "" {
 		namespace = qb.namespace
 	}
-	return fmt.Sprintf(
-		`sum(rate(response_total{deployment="%s", namespace="%s", direction="inbound", http_status=~"5.."}[%s])) by (http_status)`,
+	return qb.filterInbound(fmt.Sprintf(
+		`sum(rate(response_total{deployment="%s", namespace="%s", direction="inbound"}[%s])) by (src_deployment, src_namespace)`,
+		deployment, namespace, formatDuration(window),
+	))
+}
+
+// BuildInboundSourceSuccessRateQuery builds a query for the inbound success rate (0-1) of a
+// deployment, grouped by the source deployment and namespace
+func (qb *QueryBuilder) BuildInboundSourceSuccessRateQuery(deployment, namespace string, window time.Duration) string {
+	if namespace == "" {
+		namespace = qb.namespace
+	}
+	return qb.filterInbound(fmt.Sprintf(
+		`sum(rate(response_total{deployment="%s", namespace="%s", classification!="failure", direction="inbound"}[%s])) by (src_deployment, src_namespace) / sum(rate(response_total{deployment="%s", namespace="%s", direction="inbound"}[%s])) by (src_deployment, src_namespace)`,
+		deployment, namespace, formatDuration(window),
 		deployment, namespace, formatDuration(window),
+	))
+}
+
+// BuildBackendRequestRateQuery builds a query for the outbound request rate to each of a set of
+// backend services, grouped by dst_service and dst_namespace
+func (qb *QueryBuilder) BuildBackendRequestRateQuery(backends []ServiceIdentifier, window time.Duration) string {
+	namespaces := []string{}
+	services := []string{}
+	seen := map[string]bool{}
+	for _, backend := range backends {
+		namespace := backend.Namespace
+		if namespace == "" {
+			namespace = qb.namespace
+		}
+		if !seen["ns/"+namespace] {
+			seen["ns/"+namespace] = true
+			namespaces = append(namespaces, namespace)
+		}
+		if !seen["svc/"+backend.Service] {
+			seen["svc/"+backend.Service] = true
+			services = append(services, backend.Service)
+		}
+	}
+	return fmt.Sprintf(
+		`sum(rate(request_total{dst_service=~"%s", dst_namespace=~"%s", direction="outbound"}[%s])) by (dst_service, dst_namespace)`,
+		strings.Join(services, "|"), strings.Join(namespaces, "|"), formatDuration(window),
+	)
+}
+
+// BuildTLSRequestRateQuery builds a query for the inbound response rate of each deployment in a
+// namespace grouped by the tls label, and by the client identity when bySource is set. An empty
+// deployment selects every deployment in the namespace.
+func (qb *QueryBuilder) BuildTLSRequestRateQuery(deployment, namespace string, bySource bool, window time.Duration) string {
+	if namespace == "" {
+		namespace = qb.namespace
+	}
+	selector := fmt.Sprintf(`namespace="%s", direction="inbound"`, namespace)
+	if deployment != "" {
+		selector = fmt.Sprintf(`deployment="%s", %s`, deployment, selector)
+	}
+	groupBy := "deployment, tls"
+	if bySource {
+		groupBy += ", client_id"
+	}
+	return qb.filterInbound(fmt.Sprintf(
+		`sum(rate(response_total{%s}[%s])) by (%s)`,
+		selector, formatDuration(window), groupBy,
+	))
+}
+
+// clusterSelector selects outbound traffic to remote clusters, optionally restricted to one
+// source namespace and one destination cluster. Requests to local services carry no dst_cluster.
+func clusterSelector(namespace, cluster string) string {
+	selector := `direction="outbound", dst_cluster!=""`
+	if cluster != "" {
+		selector = fmt.Sprintf(`direction="outbound", dst_cluster="%s"`, cluster)
+	}
+	if namespace != "" {
+		selector = fmt.Sprintf(`namespace="%s", %s`, namespace, selector)
+	}
+	return selector
+}
+
+// BuildClusterRequestRateQuery builds a query for the outbound request rate to remote clusters,
+// grouped by dst_cluster. An empty namespace covers every namespace and an empty cluster every
+// remote cluster.
+func (qb *QueryBuilder) BuildClusterRequestRateQuery(namespace, cluster string, window time.Duration) string {
+	return fmt.Sprintf(
+		`sum(rate(request_total{%s}[%s])) by (dst_cluster)`,
+		clusterSelector(namespace, cluster), formatDuration(window),
+	)
+}
+
+// BuildClusterSuccessRateQuery builds a query for the outbound success rate (0-1) to remote
+// clusters, grouped by dst_cluster
+func (qb *QueryBuilder) BuildClusterSuccessRateQuery(namespace, cluster string, window time.Duration) string {
+	selector := clusterSelector(namespace, cluster)
+	return fmt.Sprintf(
+		`sum(rate(response_total{%s, classification!="failure"}[%s])) by (dst_cluster) / sum(rate(response_total{%s}[%s])) by (dst_cluster)`,
+		selector, formatDuration(window),
+		selector, formatDuration(window),
 	)
 }
 
+// BuildGatewayRequestRateQuery builds a query for the inbound request rate of the multicluster
+// gateway, i.e. the traffic remote clusters send to this one
+func (qb *QueryBuilder) BuildGatewayRequestRateQuery(gatewayNamespace string, window time.Duration) string {
+	return qb.filterInbound(fmt.Sprintf(
+		`sum(rate(request_total{deployment="%s", namespace="%s", direction="inbound"}[%s]))`,
+		GatewayDeployment, gatewayNamespace, formatDuration(window),
+	))
+}
+
+// BuildGatewaySuccessRateQuery builds a query for the inbound success rate (0-1) of the
+// multicluster gateway
+func (qb *QueryBuilder) BuildGatewaySuccessRateQuery(gatewayNamespace string, window time.Duration) string {
+	return qb.filterInbound(fmt.Sprintf(
+		`sum(rate(response_total{deployment="%s", namespace="%s", classification!="failure", direction="inbound"}[%s])) / sum(rate(response_total{deployment="%s", namespace="%s", direction="inbound"}[%s]))`,
+		GatewayDeployment, gatewayNamespace, formatDuration(window),
+		GatewayDeployment, gatewayNamespace, formatDuration(window),
+	))
+}
+
+// BuildProxyCPUUsageQuery builds a query for the CPU usage (cores) of each proxy in a namespace,
+// from the process metrics the proxies expose on their admin port
+func (qb *QueryBuilder) BuildProxyCPUUsageQuery(namespace string, window time.Duration) string {
+	if namespace == "" {
+		namespace = qb.namespace
+	}
+	return fmt.Sprintf(
+		`sum(rate(process_cpu_seconds_total{job="%s", namespace="%s"}[%s])) by (deployment, pod)`,
+		ProxyJob, namespace, formatDuration(window),
+	)
+}
+
+// BuildProxyMemoryUsageQuery builds a query for the resident memory (bytes) of each proxy in a
+// namespace
+func (qb *QueryBuilder) BuildProxyMemoryUsageQuery(namespace string) string {
+	if namespace == "" {
+		namespace = qb.namespace
+	}
+	return fmt.Sprintf(
+		`sum(process_resident_memory_bytes{job="%s", namespace="%s"}) by (deployment, pod)`,
+		ProxyJob, namespace,
+	)
+}
+
+// BuildResponsesByStatusQuery builds a query for the rate of responses grouped by HTTP status
+// code, from which the rates of each status class are derived
+func (qb *QueryBuilder) BuildResponsesByStatusQuery(deployment, namespace string, window time.Duration) string {
+	if namespace == "" {
+		namespace = qb.namespace
+	}
+	return qb.filterInbound(fmt.Sprintf(
+		`sum(rate(response_total{deployment="%s", namespace="%s", direction="%s"}[%s])) by (http_status)`,
+		deployment, namespace, qb.serviceDirection(), formatDuration(window),
+	))
+}
+
+// BuildErrorsByStatusQuery builds a query for errors grouped by HTTP status code
+func (qb *QueryBuilder) BuildErrorsByStatusQuery(deployment, namespace string, window time.Duration) string {
+	if namespace == "" {
+		namespace = qb.namespace
+	}
+	return qb.filterInbound(fmt.Sprintf(
+		`sum(rate(response_total{deployment="%s", namespace="%s", direction="%s", http_status=~"5.."}[%s])) by (http_status)`,
+		deployment, namespace, qb.serviceDirection(), formatDuration(window),
+	))
+}
+
+// BuildGRPCErrorsByStatusQuery builds a query for the number of failed gRPC responses in the
+// window grouped by gRPC status code. gRPC failures usually carry HTTP status 200, so they don't
+// show up in the HTTP breakdown.
+func (qb *QueryBuilder) BuildGRPCErrorsByStatusQuery(deployment, namespace string, window time.Duration) string {
+	if namespace == "" {
+		namespace = qb.namespace
+	}
+	return qb.filterInbound(fmt.Sprintf(
+		`sum(increase(response_total{deployment="%s", namespace="%s", direction="%s", classification="failure", grpc_status!=""}[%s])) by (grpc_status)`,
+		deployment, namespace, qb.serviceDirection(), formatDuration(window),
+	))
+}
+
+// BuildFailuresByStatusQuery builds a query for the number of failed responses of a deployment in
+// the window by HTTP status, for services without route metrics
+func (qb *QueryBuilder) BuildFailuresByStatusQuery(deployment, namespace string, window time.Duration) string {
+	if namespace == "" {
+		namespace = qb.namespace
+	}
+	return qb.filterInbound(fmt.Sprintf(
+		`sum(increase(response_total{deployment="%s", namespace="%s", direction="%s", classification="failure"}[%s])) by (http_status)`,
+		deployment, namespace, qb.serviceDirection(), formatDuration(window),
+	))
+}
+
 // BuildTrafficErrorsByStatusQuery builds a query for errors between services grouped by HTTP status
 func (qb *QueryBuilder) BuildTrafficErrorsByStatusQuery(srcDeployment, srcNamespace, dstDeployment, dstNamespace string, window time.Duration) string {
 	if srcNamespace == "" {
@@ -172,10 +568,10 @@ func (qb *QueryBuilder) BuildAllServicesQuery(namespace string) string {
 	if namespace == "" {
 		namespace = qb.namespace
 	}
-	return fmt.Sprintf(
+	return qb.filterInbound(fmt.Sprintf(
 		`count(request_total{namespace="%s", direction="inbound"}) by (deployment)`,
 		namespace,
-	)
+	))
 }
 
 // BuildByteSentQuery builds a query for bytes sent
@@ -206,16 +602,30 @@ func (qb *QueryBuilder) BuildByteReceivedQuery(srcDeployment, srcNamespace, dstD
 	)
 }
 
-// formatDuration formats a time.Duration for use in PromQL (e.g., "5m", "1h")
+// promQLDurationUnits are the units of PromQL durations, largest first
+var promQLDurationUnits = []struct {
+	suffix string
+	unit   time.Duration
+}{
+	{"w", 7 * 24 * time.Hour},
+	{"d", 24 * time.Hour},
+	{"h", time.Hour},
+	{"m", time.Minute},
+	{"s", time.Second},
+}
+
+// formatDuration formats a time.Duration for use in PromQL (e.g., "5m", "90s", "2w"), in the
+// largest unit that represents it exactly, so that the window queried is the one asked for.
+// Durations are rounded to the millisecond, the precision of PromQL.
 func formatDuration(d time.Duration) string {
-	if d < time.Minute {
-		return fmt.Sprintf("%ds", int(d.Seconds()))
-	}
-	if d < time.Hour {
-		return fmt.Sprintf("%dm", int(d.Minutes()))
+	d = d.Round(time.Millisecond)
+	for _, u := range promQLDurationUnits {
+		if d >= u.unit && d%u.unit == 0 {
+			return fmt.Sprintf("%d%s", d/u.unit, u.suffix)
+		}
 	}
-	if d < 24*time.Hour {
-		return fmt.Sprintf("%dh", int(d.Hours()))
+	if d <= 0 {
+		return "0s"
 	}
-	return fmt.Sprintf("%dd", int(d.Hours()/24))
+	return fmt.Sprintf("%dms", d.Milliseconds())
 }
@@ -1,6 +1,7 @@
 package metrics_test
 
 import (
+	"strings"
 	"time"
 
 	"github.com/christianhuening/linkerd-mcp/internal/metrics"
@@ -32,6 +33,27 @@ var _ = Describe("QueryBuilder", func() {
 		})
 	})
 
+	Describe("Rate windows", func() {
+		window := func(d time.Duration) string {
+			return qb.BuildServiceRequestRateQuery("frontend", "default", d)
+		}
+
+		It("should keep windows that aren't whole minutes in seconds", func() {
+			Expect(window(45 * time.Second)).To(ContainSubstring("[45s]"))
+			Expect(window(90 * time.Second)).To(ContainSubstring("[90s]"))
+		})
+
+		It("should keep sub-second precision in milliseconds", func() {
+			Expect(window(30*time.Second + 500*time.Millisecond)).To(ContainSubstring("[30500ms]"))
+		})
+
+		It("should use the largest exact unit for long windows", func() {
+			Expect(window(36 * time.Hour)).To(ContainSubstring("[36h]"))
+			Expect(window(10 * 24 * time.Hour)).To(ContainSubstring("[10d]"))
+			Expect(window(14 * 24 * time.Hour)).To(ContainSubstring("[2w]"))
+		})
+	})
+
 	Describe("BuildServiceSuccessRateQuery", func() {
 		It("should build correct PromQL query", func() {
 			query := qb.BuildServiceSuccessRateQuery("backend", "prod", 10*time.Minute)
@@ -126,6 +148,64 @@ var _ = Describe("QueryBuilder", func() {
 		})
 	})
 
+	Describe("BuildDestinationSuccessRateQuery", func() {
+		It("should group the success rate by destination", func() {
+			query := qb.BuildDestinationSuccessRateQuery("frontend", "default", 5*time.Minute)
+
+			Expect(query).To(ContainSubstring(`deployment="frontend"`))
+			Expect(query).To(ContainSubstring(`classification!="failure"`))
+			Expect(query).To(ContainSubstring("by (dst_deployment, dst_namespace) /"))
+		})
+	})
+
+	Describe("BuildDestinationLatencyQuery", func() {
+		It("should keep the destination labels in the histogram aggregation", func() {
+			query := qb.BuildDestinationLatencyQuery("frontend", "default", 0.95, 5*time.Minute)
+
+			Expect(query).To(ContainSubstring("histogram_quantile(0.95"))
+			Expect(query).To(ContainSubstring("by (le, dst_deployment, dst_namespace)"))
+		})
+	})
+
+	Describe("BuildSourceSuccessRateQuery", func() {
+		It("should group the success rate by source", func() {
+			query := qb.BuildSourceSuccessRateQuery("backend", "default", 5*time.Minute)
+
+			Expect(query).To(ContainSubstring(`dst_deployment="backend"`))
+			Expect(query).To(ContainSubstring("by (deployment, namespace) /"))
+		})
+	})
+
+	Describe("BuildInboundSourceSuccessRateQuery", func() {
+		It("should group the inbound success rate by source deployment", func() {
+			query := qb.BuildInboundSourceSuccessRateQuery("backend", "default", 5*time.Minute)
+
+			Expect(query).To(ContainSubstring(`deployment="backend", namespace="default", classification!="failure", direction="inbound"`))
+			Expect(query).To(ContainSubstring("by (src_deployment, src_namespace) /"))
+			Expect(query).To(HaveSuffix("by (src_deployment, src_namespace)"))
+		})
+	})
+
+	Describe("BuildSourceLatencyQuery", func() {
+		It("should keep the source labels in the histogram aggregation", func() {
+			query := qb.BuildSourceLatencyQuery("backend", "default", 0.95, 5*time.Minute)
+
+			Expect(query).To(ContainSubstring(`dst_deployment="backend"`))
+			Expect(query).To(ContainSubstring("by (le, deployment, namespace)"))
+		})
+	})
+
+	Describe("BuildDestinationRequestRateQuery", func() {
+		It("should group every destination without a topk", func() {
+			query := qb.BuildDestinationRequestRateQuery("frontend", "default", 5*time.Minute)
+
+			Expect(query).To(HavePrefix("sum(rate(request_total{"))
+			Expect(query).To(ContainSubstring(`deployment="frontend"`))
+			Expect(query).To(ContainSubstring(`direction="outbound"`))
+			Expect(query).To(ContainSubstring("by (dst_deployment, dst_namespace)"))
+		})
+	})
+
 	Describe("BuildTopSourcesQuery", func() {
 		It("should build correct PromQL query", func() {
 			query := qb.BuildTopSourcesQuery("backend", "default", 5*time.Minute, 5)
@@ -136,6 +216,185 @@ var _ = Describe("QueryBuilder", func() {
 		})
 	})
 
+	Describe("BuildBackendRequestRateQuery", func() {
+		It("should select each backend once and group by backend", func() {
+			query := qb.BuildBackendRequestRateQuery([]metrics.ServiceIdentifier{
+				{Service: "web-stable", Namespace: "prod"},
+				{Service: "web-canary", Namespace: "prod"},
+				{Service: "web-stable"},
+			}, 5*time.Minute)
+
+			Expect(query).To(ContainSubstring(`dst_service=~"web-stable|web-canary"`))
+			Expect(query).To(ContainSubstring(`dst_namespace=~"prod|linkerd"`))
+			Expect(query).To(ContainSubstring(`direction="outbound"`))
+			Expect(query).To(ContainSubstring("by (dst_service, dst_namespace)"))
+			Expect(query).To(ContainSubstring("[5m]"))
+		})
+	})
+
+	Describe("Retry and timeout queries", func() {
+		It("should subtract original requests from actual requests sent to the service", func() {
+			query := qb.BuildServiceRetryRateQuery("api", "prod", 5*time.Minute)
+
+			Expect(query).To(ContainSubstring(`route_actual_request_total{dst_deployment="api", dst_namespace="prod", direction="outbound"}[5m]`))
+			Expect(query).To(ContainSubstring(`- sum(rate(route_request_total{dst_deployment="api", dst_namespace="prod", direction="outbound"}[5m]))`))
+			Expect(query).To(HavePrefix("clamp_min("))
+		})
+
+		It("should count timed out responses", func() {
+			query := qb.BuildServiceTimeoutRateQuery("api", "prod", 5*time.Minute)
+
+			Expect(query).To(Equal(`sum(rate(route_response_total{dst_deployment="api", dst_namespace="prod", direction="outbound", status_code="504"}[5m]))`))
+		})
+
+		It("should select the client deployment outbound", func() {
+			query := qb.WithDirection(metrics.DirectionOutbound).BuildServiceRetryRateQuery("api", "prod", 5*time.Minute)
+
+			Expect(query).To(ContainSubstring(`route_actual_request_total{deployment="api", namespace="prod", direction="outbound"}[5m]`))
+			Expect(query).NotTo(ContainSubstring("dst_deployment"))
+		})
+	})
+
+	Describe("WithDirection", func() {
+		It("should select outbound requests of the service", func() {
+			outbound := qb.WithDirection(metrics.DirectionOutbound)
+
+			Expect(outbound.BuildServiceRequestRateQuery("frontend", "default", 5*time.Minute)).To(ContainSubstring(`direction="outbound"`))
+			Expect(outbound.BuildServiceLatencyQuery("frontend", "default", 0.95, 5*time.Minute)).To(ContainSubstring(`direction="outbound"`))
+			Expect(outbound.BuildErrorsByStatusQuery("frontend", "default", 5*time.Minute)).To(ContainSubstring(`direction="outbound"`))
+		})
+
+		It("should leave the original builder inbound", func() {
+			qb.WithDirection(metrics.DirectionOutbound)
+
+			Expect(qb.BuildServiceRequestRateQuery("frontend", "default", 5*time.Minute)).To(ContainSubstring(`direction="inbound"`))
+		})
+	})
+
+	Describe("ParseDirection", func() {
+		It("should default to inbound", func() {
+			direction, err := metrics.ParseDirection("")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(direction).To(Equal(metrics.DirectionInbound))
+		})
+
+		It("should accept outbound", func() {
+			direction, err := metrics.ParseDirection("outbound")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(direction).To(Equal(metrics.DirectionOutbound))
+		})
+
+		It("should reject other directions", func() {
+			_, err := metrics.ParseDirection("sideways")
+			Expect(err).To(MatchError(ContainSubstring("unsupported direction")))
+		})
+	})
+
+	Describe("Route queries", func() {
+		It("should group request rates by route", func() {
+			query := qb.BuildRouteRequestRateQuery("api", "prod", 5*time.Minute)
+
+			Expect(query).To(ContainSubstring(`route_request_total{deployment="api", namespace="prod", direction="inbound"}`))
+			Expect(query).To(ContainSubstring("by (rt_route)"))
+			Expect(query).To(ContainSubstring("[5m]"))
+		})
+
+		It("should group success rates by route", func() {
+			query := qb.BuildRouteSuccessRateQuery("api", "prod", 5*time.Minute)
+
+			Expect(query).To(ContainSubstring(`route_response_total{deployment="api", namespace="prod", classification!="failure"`))
+			Expect(query).To(ContainSubstring(") by (rt_route) / sum("))
+		})
+
+		It("should group latency buckets by route", func() {
+			query := qb.BuildRouteLatencyQuery("api", "prod", 0.95, 5*time.Minute)
+
+			Expect(query).To(HavePrefix("histogram_quantile(0.95"))
+			Expect(query).To(ContainSubstring("route_response_latency_ms_bucket"))
+			Expect(query).To(ContainSubstring("by (le, rt_route)"))
+		})
+
+		It("should count failures by route and status code", func() {
+			query := qb.BuildRouteFailuresQuery("api", "prod", time.Hour)
+
+			Expect(query).To(Equal(`sum(increase(route_response_total{deployment="api", namespace="prod", classification="failure", direction="inbound"}[1h])) by (rt_route, status_code)`))
+		})
+
+		It("should count failures by HTTP status without routes", func() {
+			query := qb.BuildFailuresByStatusQuery("api", "prod", time.Hour)
+
+			Expect(query).To(Equal(`sum(increase(response_total{deployment="api", namespace="prod", direction="inbound", classification="failure"}[1h])) by (http_status)`))
+		})
+	})
+
+	Describe("BuildTLSRequestRateQuery", func() {
+		It("should group inbound responses by deployment and tls", func() {
+			query := qb.BuildTLSRequestRateQuery("", "prod", false, 5*time.Minute)
+
+			Expect(query).To(ContainSubstring(`response_total{namespace="prod", direction="inbound"}[5m]`))
+			Expect(query).To(HaveSuffix("by (deployment, tls)"))
+		})
+
+		It("should select a deployment and group by client identity", func() {
+			query := qb.BuildTLSRequestRateQuery("api", "prod", true, 5*time.Minute)
+
+			Expect(query).To(ContainSubstring(`deployment="api", namespace="prod", direction="inbound"`))
+			Expect(query).To(HaveSuffix("by (deployment, tls, client_id)"))
+		})
+	})
+
+	Describe("BuildClusterRequestRateQuery", func() {
+		It("should group outbound traffic to any remote cluster by dst_cluster", func() {
+			query := qb.BuildClusterRequestRateQuery("", "", 5*time.Minute)
+
+			Expect(query).To(Equal(`sum(rate(request_total{direction="outbound", dst_cluster!=""}[5m])) by (dst_cluster)`))
+		})
+
+		It("should restrict the source namespace and destination cluster", func() {
+			query := qb.BuildClusterRequestRateQuery("prod", "west", 5*time.Minute)
+
+			Expect(query).To(ContainSubstring(`request_total{namespace="prod", direction="outbound", dst_cluster="west"}`))
+		})
+	})
+
+	Describe("BuildClusterSuccessRateQuery", func() {
+		It("should divide non-failure responses by all responses per cluster", func() {
+			query := qb.BuildClusterSuccessRateQuery("prod", "west", 5*time.Minute)
+
+			Expect(query).To(ContainSubstring(`response_total{namespace="prod", direction="outbound", dst_cluster="west", classification!="failure"}`))
+			Expect(query).To(ContainSubstring(`by (dst_cluster) / sum(rate(response_total{namespace="prod", direction="outbound", dst_cluster="west"}[5m])) by (dst_cluster)`))
+		})
+	})
+
+	Describe("BuildGatewayRequestRateQuery", func() {
+		It("should select inbound traffic of the multicluster gateway", func() {
+			query := qb.BuildGatewayRequestRateQuery("linkerd-multicluster", 5*time.Minute)
+
+			Expect(query).To(ContainSubstring(`deployment="linkerd-gateway", namespace="linkerd-multicluster", direction="inbound"`))
+		})
+
+		It("should not affect single-cluster queries", func() {
+			Expect(qb.BuildServiceRequestRateQuery("api", "prod", 5*time.Minute)).NotTo(ContainSubstring("dst_cluster"))
+			Expect(qb.BuildGatewaySuccessRateQuery("linkerd-multicluster", 5*time.Minute)).NotTo(ContainSubstring("dst_cluster"))
+		})
+	})
+
+	Describe("BuildProxyCPUUsageQuery", func() {
+		It("should rate proxy CPU time per pod", func() {
+			query := qb.BuildProxyCPUUsageQuery("prod", 5*time.Minute)
+
+			Expect(query).To(Equal(`sum(rate(process_cpu_seconds_total{job="linkerd-proxy", namespace="prod"}[5m])) by (deployment, pod)`))
+		})
+	})
+
+	Describe("BuildProxyMemoryUsageQuery", func() {
+		It("should select proxy resident memory per pod", func() {
+			query := qb.BuildProxyMemoryUsageQuery("prod")
+
+			Expect(query).To(Equal(`sum(process_resident_memory_bytes{job="linkerd-proxy", namespace="prod"}) by (deployment, pod)`))
+		})
+	})
+
 	Describe("BuildErrorsByStatusQuery", func() {
 		It("should build correct PromQL query", func() {
 			query := qb.BuildErrorsByStatusQuery("api", "default", 5*time.Minute)
@@ -146,6 +405,17 @@ var _ = Describe("QueryBuilder", func() {
 		})
 	})
 
+	Describe("BuildGRPCErrorsByStatusQuery", func() {
+		It("should count failed gRPC responses by status code", func() {
+			query := qb.BuildGRPCErrorsByStatusQuery("api", "default", 5*time.Minute)
+
+			Expect(query).To(HavePrefix("sum(increase(response_total{"))
+			Expect(query).To(ContainSubstring(`deployment="api"`))
+			Expect(query).To(ContainSubstring(`classification="failure", grpc_status!=""`))
+			Expect(query).To(ContainSubstring("by (grpc_status)"))
+		})
+	})
+
 	Describe("BuildTrafficErrorsByStatusQuery", func() {
 		It("should build correct PromQL query", func() {
 			query := qb.BuildTrafficErrorsByStatusQuery("frontend", "default", "api", "default", 5*time.Minute)
@@ -190,4 +460,37 @@ var _ = Describe("QueryBuilder", func() {
 			Expect(query).To(ContainSubstring(`dst_deployment="backend"`))
 		})
 	})
+
+	Describe("admin traffic exclusion", func() {
+		It("should not filter queries by default", func() {
+			query := qb.BuildServiceRequestRateQuery("frontend", "default", 5*time.Minute)
+
+			Expect(query).NotTo(ContainSubstring("target_port"))
+			Expect(query).NotTo(ContainSubstring("route_name"))
+		})
+
+		It("should exclude the proxy admin port and probe route from inbound queries when enabled", func() {
+			qb.SetExcludeAdminTraffic(true)
+
+			for _, query := range []string{
+				qb.BuildServiceRequestRateQuery("frontend", "default", 5*time.Minute),
+				qb.BuildServiceLatencyQuery("frontend", "default", 0.95, 5*time.Minute),
+				qb.BuildErrorsByStatusQuery("frontend", "default", 5*time.Minute),
+				qb.BuildGRPCErrorsByStatusQuery("frontend", "default", 5*time.Minute),
+			} {
+				Expect(query).To(ContainSubstring(`target_port!="4191"`))
+				Expect(query).To(ContainSubstring(`route_name!="probe"`))
+			}
+
+			successRate := qb.BuildServiceSuccessRateQuery("frontend", "default", 5*time.Minute)
+			Expect(strings.Count(successRate, `target_port!="4191"`)).To(Equal(2))
+		})
+
+		It("should leave outbound queries untouched", func() {
+			qb.SetExcludeAdminTraffic(true)
+
+			query := qb.BuildTopDestinationsQuery("frontend", "default", 5*time.Minute, 10)
+			Expect(query).NotTo(ContainSubstring("target_port"))
+		})
+	})
 })
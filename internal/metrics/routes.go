@@ -0,0 +1,150 @@
+package metrics
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/prometheus/common/model"
+)
+
+// DefaultRouteName is the route Linkerd reports requests under when they match no route of a
+// ServiceProfile or HTTPRoute, or when the service defines no routes at all
+const DefaultRouteName = "[DEFAULT]"
+
+// RouteMetrics contains the metrics of a single route of a service. Success rate and latencies
+// are null when no responses were observed for the route.
+type RouteMetrics struct {
+	Route       string   `json:"route"`
+	RequestRate float64  `json:"requestRate"` // requests per second
+	SuccessRate *float64 `json:"successRate"` // percentage (0-100)
+	LatencyP50  *float64 `json:"latencyP50"`  // milliseconds
+	LatencyP95  *float64 `json:"latencyP95"`  // milliseconds
+	LatencyP99  *float64 `json:"latencyP99"`  // milliseconds
+}
+
+// RouteMetricsReport contains the per-route metrics of a service
+type RouteMetricsReport struct {
+	Service       string         `json:"service"`
+	Namespace     string         `json:"namespace"`
+	Deployment    string         `json:"deployment,omitempty"`
+	TimeRange     TimeRange      `json:"timeRange"`
+	RoutesDefined bool           `json:"routesDefined"` // whether any traffic matched a named route
+	Routes        []RouteMetrics `json:"routes"`
+}
+
+// GetRouteMetrics retrieves success rate and latency for each route of a service, so that a slow
+// or failing endpoint stands out instead of being averaged with the rest of the service
+func (c *MetricsCollector) GetRouteMetrics(ctx context.Context, namespace, service, timeRangeStr string) (*mcp.CallToolResult, error) {
+	if !c.Available() {
+		return unavailableResult(), nil
+	}
+
+	tr, err := ParseTimeRange(timeRangeStr)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Invalid time range: %v", err)), nil
+	}
+
+	deployment, err := c.findDeploymentForService(ctx, namespace, service)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to find deployment: %v", err)), nil
+	}
+
+	window := tr.End.Sub(tr.Start)
+
+	requestRates, err := c.promClient.Query(ctx, c.queryBuilder.BuildRouteRequestRateQuery(deployment, namespace, window), tr.End)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to query route request rates: %v", err)), nil
+	}
+	successRates, _ := c.promClient.Query(ctx, c.queryBuilder.BuildRouteSuccessRateQuery(deployment, namespace, window), tr.End)
+	p50, _ := c.promClient.Query(ctx, c.queryBuilder.BuildRouteLatencyQuery(deployment, namespace, 0.50, window), tr.End)
+	p95, _ := c.promClient.Query(ctx, c.queryBuilder.BuildRouteLatencyQuery(deployment, namespace, 0.95, window), tr.End)
+	p99, _ := c.promClient.Query(ctx, c.queryBuilder.BuildRouteLatencyQuery(deployment, namespace, 0.99, window), tr.End)
+
+	routes := BuildRouteMetrics(requestRates, successRates, p50, p95, p99)
+	report := RouteMetricsReport{
+		Service:    service,
+		Namespace:  namespace,
+		Deployment: deployment,
+		TimeRange:  tr,
+		Routes:     routes,
+	}
+	for _, route := range routes {
+		if route.Route != DefaultRouteName {
+			report.RoutesDefined = true
+		}
+	}
+
+	data, err := json.Marshal(report)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal route metrics: %v", err)), nil
+	}
+
+	return mcp.NewToolResultText(string(data)), nil
+}
+
+// BuildRouteMetrics joins route request rates with success rates (0-1) and latency quantiles on
+// the rt_route label. Requests without a route are reported under DefaultRouteName, which is
+// also returned on its own when there is no route traffic at all. Routes are sorted by request
+// rate, busiest first.
+func BuildRouteMetrics(requestRates, successRates, p50, p95, p99 model.Value) []RouteMetrics {
+	routeName := func(metric model.Metric) string {
+		if route := string(metric["rt_route"]); route != "" {
+			return route
+		}
+		return DefaultRouteName
+	}
+	index := func(value model.Value) map[string]float64 {
+		values := map[string]float64{}
+		vector, ok := value.(model.Vector)
+		if !ok {
+			return values
+		}
+		for _, sample := range vector {
+			// histogram_quantile and ratios yield NaN for routes without responses
+			if math.IsNaN(float64(sample.Value)) {
+				continue
+			}
+			values[routeName(sample.Metric)] = float64(sample.Value)
+		}
+		return values
+	}
+	lookup := func(values map[string]float64, route string, factor float64) *float64 {
+		v, ok := values[route]
+		if !ok {
+			return nil
+		}
+		return scaleOptional(&v, factor)
+	}
+
+	rates := index(requestRates)
+	successes := index(successRates)
+	p50s, p95s, p99s := index(p50), index(p95), index(p99)
+
+	if len(rates) == 0 {
+		rates[DefaultRouteName] = 0
+	}
+
+	routes := make([]RouteMetrics, 0, len(rates))
+	for route, rate := range rates {
+		routes = append(routes, RouteMetrics{
+			Route:       route,
+			RequestRate: rate,
+			SuccessRate: lookup(successes, route, 100),
+			LatencyP50:  lookup(p50s, route, 1),
+			LatencyP95:  lookup(p95s, route, 1),
+			LatencyP99:  lookup(p99s, route, 1),
+		})
+	}
+
+	sort.Slice(routes, func(i, j int) bool {
+		if routes[i].RequestRate != routes[j].RequestRate {
+			return routes[i].RequestRate > routes[j].RequestRate
+		}
+		return routes[i].Route < routes[j].Route
+	})
+	return routes
+}
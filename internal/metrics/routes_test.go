@@ -0,0 +1,63 @@
+package metrics_test
+
+import (
+	"math"
+
+	"github.com/christianhuening/linkerd-mcp/internal/metrics"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/common/model"
+)
+
+func routeSample(route string, value float64) *model.Sample {
+	metric := model.Metric{}
+	if route != "" {
+		metric["rt_route"] = model.LabelValue(route)
+	}
+	return &model.Sample{Metric: metric, Value: model.SampleValue(value)}
+}
+
+var _ = Describe("BuildRouteMetrics", func() {
+	It("should report the metrics of each route, busiest first", func() {
+		rates := model.Vector{
+			routeSample("GET /api/books", 8),
+			routeSample("POST /api/orders", 2),
+			routeSample("", 0.5),
+		}
+		successRates := model.Vector{
+			routeSample("GET /api/books", 1),
+			routeSample("POST /api/orders", 0.75),
+			routeSample("", 1),
+		}
+		p50 := model.Vector{routeSample("GET /api/books", 5), routeSample("POST /api/orders", 40)}
+		p95 := model.Vector{routeSample("GET /api/books", 12), routeSample("POST /api/orders", 900)}
+		p99 := model.Vector{routeSample("GET /api/books", 20), routeSample("POST /api/orders", math.NaN())}
+
+		routes := metrics.BuildRouteMetrics(rates, successRates, p50, p95, p99)
+
+		Expect(routes).To(HaveLen(3))
+		Expect(routes[0].Route).To(Equal("GET /api/books"))
+		Expect(routes[0].RequestRate).To(BeNumerically("==", 8))
+		Expect(*routes[0].SuccessRate).To(BeNumerically("==", 100))
+		Expect(*routes[0].LatencyP95).To(BeNumerically("==", 12))
+
+		Expect(routes[1].Route).To(Equal("POST /api/orders"))
+		Expect(*routes[1].SuccessRate).To(BeNumerically("==", 75))
+		Expect(*routes[1].LatencyP50).To(BeNumerically("==", 40))
+		Expect(*routes[1].LatencyP95).To(BeNumerically("==", 900))
+		Expect(routes[1].LatencyP99).To(BeNil())
+
+		Expect(routes[2].Route).To(Equal(metrics.DefaultRouteName))
+		Expect(routes[2].RequestRate).To(BeNumerically("==", 0.5))
+		Expect(routes[2].LatencyP95).To(BeNil())
+	})
+
+	It("should return the default route when no routes are defined", func() {
+		routes := metrics.BuildRouteMetrics(model.Vector{}, model.Vector{}, nil, nil, nil)
+
+		Expect(routes).To(HaveLen(1))
+		Expect(routes[0].Route).To(Equal(metrics.DefaultRouteName))
+		Expect(routes[0].RequestRate).To(BeZero())
+		Expect(routes[0].SuccessRate).To(BeNil())
+	})
+})
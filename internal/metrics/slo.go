@@ -0,0 +1,211 @@
+package metrics
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/prometheus/common/model"
+)
+
+// DefaultSLOWindow is the window an SLO is evaluated over when none is given
+const DefaultSLOWindow = "1h"
+
+// SLOTargets are the objectives of an SLO. A nil target is not evaluated.
+type SLOTargets struct {
+	SuccessRate *float64 // minimum success rate in percent, e.g. 99.9
+	LatencyP95  *float64 // maximum p95 latency in milliseconds
+}
+
+// Validate checks that at least one objective is set and that the objectives are achievable
+func (t SLOTargets) Validate() error {
+	if t.SuccessRate == nil && t.LatencyP95 == nil {
+		return fmt.Errorf("at least one of the success rate and p95 latency targets is required")
+	}
+	if t.SuccessRate != nil && (*t.SuccessRate <= 0 || *t.SuccessRate >= 100) {
+		return fmt.Errorf("success rate target (%g) must be between 0 and 100, exclusive", *t.SuccessRate)
+	}
+	if t.LatencyP95 != nil && *t.LatencyP95 <= 0 {
+		return fmt.Errorf("p95 latency target (%g) must be positive", *t.LatencyP95)
+	}
+	return nil
+}
+
+// SLOObjective is the evaluation of one objective over the SLO window. Actual is null when the
+// service received no requests. ViolationPercent is the share of the window, in steps, during
+// which the objective was violated; steps without requests don't count.
+type SLOObjective struct {
+	Metric           string   `json:"metric"` // success_rate or latency_p95
+	Target           float64  `json:"target"` // percent for the success rate, milliseconds for the latency
+	Actual           *float64 `json:"actual"` // over the whole window
+	Met              bool     `json:"met"`
+	ViolationPercent *float64 `json:"violationPercent"`
+	// Success rate objectives budget the failures the target allows: the share of the budget
+	// spent, which exceeds 100 once the objective is missed, and the share left
+	ErrorBudgetConsumed  *float64 `json:"errorBudgetConsumed,omitempty"`
+	ErrorBudgetRemaining *float64 `json:"errorBudgetRemaining,omitempty"`
+}
+
+// SLOReport is the SLO verdict of a service. The SLO is met when every objective is.
+type SLOReport struct {
+	Service              string         `json:"service"`
+	Namespace            string         `json:"namespace"`
+	Deployment           string         `json:"deployment,omitempty"`
+	TimeRange            TimeRange      `json:"timeRange"`
+	DataAvailable        bool           `json:"dataAvailable"`
+	Met                  bool           `json:"met"`
+	Objectives           []SLOObjective `json:"objectives"`
+	ErrorBudgetRemaining *float64       `json:"errorBudgetRemaining"` // percent, of the success rate objective
+}
+
+// EvaluateSLO evaluates the success rate and p95 latency objectives of a service over a
+// window. Each objective is checked against its value over the whole window, and against a
+// range query at the given step to find how much of the window was in violation. An empty
+// windowStr evaluates the last hour; an empty stepStr uses the step computed from the window.
+func (c *MetricsCollector) EvaluateSLO(ctx context.Context, namespace, service, windowStr, stepStr string, targets SLOTargets) (*mcp.CallToolResult, error) {
+	if !c.Available() {
+		return unavailableResult(), nil
+	}
+
+	if windowStr == "" {
+		windowStr = DefaultSLOWindow
+	}
+	tr, err := ParseTimeRangeWithStep(windowStr, stepStr)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Invalid time range: %v", err)), nil
+	}
+
+	if err := targets.Validate(); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Invalid SLO targets: %v", err)), nil
+	}
+
+	deployment, err := c.findDeploymentForService(ctx, namespace, service)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to find deployment: %v", err)), nil
+	}
+
+	window := tr.End.Sub(tr.Start)
+	rateWindow := tr.Step
+	if rateWindow < minRateWindow {
+		rateWindow = minRateWindow
+	}
+
+	requestRate, err := c.promClient.Query(ctx, c.queryBuilder.BuildServiceRequestRateQuery(deployment, namespace, window), tr.End)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to query request rate: %v", err)), nil
+	}
+
+	report := SLOReport{
+		Service:       service,
+		Namespace:     namespace,
+		Deployment:    deployment,
+		TimeRange:     tr,
+		DataAvailable: extractOptionalValue(requestRate) != nil,
+		Met:           true,
+		Objectives:    []SLOObjective{},
+	}
+
+	if targets.SuccessRate != nil {
+		actual, series, err := c.sloSignal(ctx, tr,
+			c.queryBuilder.BuildServiceSuccessRateQuery(deployment, namespace, window),
+			c.queryBuilder.BuildServiceSuccessRateQuery(deployment, namespace, rateWindow))
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to query success rate: %v", err)), nil
+		}
+		objective := EvaluateSuccessRateObjective(*targets.SuccessRate, actual, series)
+		report.Objectives = append(report.Objectives, objective)
+		report.ErrorBudgetRemaining = objective.ErrorBudgetRemaining
+	}
+
+	if targets.LatencyP95 != nil {
+		actual, series, err := c.sloSignal(ctx, tr,
+			c.queryBuilder.BuildServiceLatencyQuery(deployment, namespace, 0.95, window),
+			c.queryBuilder.BuildServiceLatencyQuery(deployment, namespace, 0.95, rateWindow))
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to query p95 latency: %v", err)), nil
+		}
+		report.Objectives = append(report.Objectives, EvaluateLatencyObjective(*targets.LatencyP95, actual, series))
+	}
+
+	for _, objective := range report.Objectives {
+		report.Met = report.Met && objective.Met
+	}
+
+	data, err := json.Marshal(report)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal SLO report: %v", err)), nil
+	}
+
+	return mcp.NewToolResultText(string(data)), nil
+}
+
+// sloSignal queries a signal over the whole window and as a series over the window
+func (c *MetricsCollector) sloSignal(ctx context.Context, tr TimeRange, windowQuery, stepQuery string) (*float64, []model.SamplePair, error) {
+	overall, err := c.promClient.Query(ctx, windowQuery, tr.End)
+	if err != nil {
+		return nil, nil, err
+	}
+	series, err := c.promClient.QueryRange(ctx, stepQuery, tr)
+	if err != nil {
+		return nil, nil, err
+	}
+	return extractOptionalValue(overall), extractSeries(series), nil
+}
+
+// EvaluateSuccessRateObjective evaluates a success rate target (percent) against the success
+// rate (0-1) over the window and its series. The error budget is the share of requests the
+// target allows to fail.
+func EvaluateSuccessRateObjective(target float64, actual *float64, series []model.SamplePair) SLOObjective {
+	objective := SLOObjective{
+		Metric:           "success_rate",
+		Target:           target,
+		Actual:           scaleOptional(actual, 100),
+		Met:              true,
+		ViolationPercent: violationPercent(series, func(value float64) bool { return value*100 < target }),
+	}
+
+	consumed := 0.0
+	if objective.Actual != nil {
+		objective.Met = *objective.Actual >= target
+		consumed = (100 - *objective.Actual) / (100 - target) * 100
+	}
+	remaining := math.Max(0, 100-consumed)
+	objective.ErrorBudgetConsumed = &consumed
+	objective.ErrorBudgetRemaining = &remaining
+	return objective
+}
+
+// EvaluateLatencyObjective evaluates a p95 latency target (milliseconds) against the p95 latency
+// over the window and its series
+func EvaluateLatencyObjective(target float64, actual *float64, series []model.SamplePair) SLOObjective {
+	return SLOObjective{
+		Metric:           "latency_p95",
+		Target:           target,
+		Actual:           actual,
+		Met:              actual == nil || *actual <= target,
+		ViolationPercent: violationPercent(series, func(value float64) bool { return value > target }),
+	}
+}
+
+// violationPercent returns the percentage of the points of a series that violate an objective.
+// NaN points, from steps without requests, are skipped; without any other point it is nil.
+func violationPercent(series []model.SamplePair, violates func(value float64) bool) *float64 {
+	points, violations := 0, 0
+	for _, sample := range series {
+		value := float64(sample.Value)
+		if math.IsNaN(value) {
+			continue
+		}
+		points++
+		if violates(value) {
+			violations++
+		}
+	}
+	if points == 0 {
+		return nil
+	}
+	percent := float64(violations) / float64(points) * 100
+	return &percent
+}
@@ -0,0 +1,96 @@
+package metrics_test
+
+import (
+	"math"
+	"time"
+
+	"github.com/christianhuening/linkerd-mcp/internal/metrics"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("SLO evaluation", func() {
+	start := time.Unix(1700000000, 0)
+	step := time.Minute
+
+	value := func(v float64) *float64 { return &v }
+
+	Describe("SLOTargets", func() {
+		It("should require at least one target", func() {
+			Expect(metrics.SLOTargets{}.Validate()).To(MatchError(ContainSubstring("at least one")))
+		})
+
+		It("should reject a success rate target of 100 percent", func() {
+			Expect(metrics.SLOTargets{SuccessRate: value(100)}.Validate()).To(HaveOccurred())
+		})
+
+		It("should reject a non-positive latency target", func() {
+			Expect(metrics.SLOTargets{LatencyP95: value(0)}.Validate()).To(HaveOccurred())
+		})
+
+		It("should accept valid targets", func() {
+			Expect(metrics.SLOTargets{SuccessRate: value(99.9), LatencyP95: value(300)}.Validate()).To(Succeed())
+		})
+	})
+
+	Describe("EvaluateSuccessRateObjective", func() {
+		It("should report the error budget consumed by a service meeting its target", func() {
+			series := buildSeries(start, step, 1, 1, 0.997, 1)
+
+			objective := metrics.EvaluateSuccessRateObjective(99, value(0.9975), series)
+
+			Expect(objective.Met).To(BeTrue())
+			Expect(*objective.Actual).To(BeNumerically("~", 99.75, 1e-9))
+			Expect(*objective.ErrorBudgetConsumed).To(BeNumerically("~", 25, 1e-9))
+			Expect(*objective.ErrorBudgetRemaining).To(BeNumerically("~", 75, 1e-9))
+			Expect(*objective.ViolationPercent).To(BeNumerically("==", 0))
+		})
+
+		It("should report an exhausted budget and the share of the window in violation", func() {
+			series := buildSeries(start, step, 1, 0.9, 0.95, 1)
+
+			objective := metrics.EvaluateSuccessRateObjective(99, value(0.97), series)
+
+			Expect(objective.Met).To(BeFalse())
+			Expect(*objective.ErrorBudgetConsumed).To(BeNumerically("~", 300, 1e-9))
+			Expect(*objective.ErrorBudgetRemaining).To(BeNumerically("==", 0))
+			Expect(*objective.ViolationPercent).To(BeNumerically("==", 50))
+		})
+
+		It("should skip steps without requests", func() {
+			series := buildSeries(start, step, math.NaN(), 0.5, math.NaN(), 1)
+
+			objective := metrics.EvaluateSuccessRateObjective(99, value(0.75), series)
+
+			Expect(*objective.ViolationPercent).To(BeNumerically("==", 50))
+		})
+
+		It("should leave the budget untouched without traffic", func() {
+			objective := metrics.EvaluateSuccessRateObjective(99.9, nil, nil)
+
+			Expect(objective.Met).To(BeTrue())
+			Expect(objective.Actual).To(BeNil())
+			Expect(objective.ViolationPercent).To(BeNil())
+			Expect(*objective.ErrorBudgetRemaining).To(BeNumerically("==", 100))
+		})
+	})
+
+	Describe("EvaluateLatencyObjective", func() {
+		It("should flag steps above the target", func() {
+			series := buildSeries(start, step, 120, 450, 200, 310)
+
+			objective := metrics.EvaluateLatencyObjective(300, value(280), series)
+
+			Expect(objective.Met).To(BeTrue())
+			Expect(*objective.ViolationPercent).To(BeNumerically("==", 50))
+			Expect(objective.ErrorBudgetRemaining).To(BeNil())
+		})
+
+		It("should miss a target exceeded over the window", func() {
+			objective := metrics.EvaluateLatencyObjective(300, value(420), buildSeries(start, step, 420))
+
+			Expect(objective.Met).To(BeFalse())
+			Expect(*objective.ViolationPercent).To(BeNumerically("==", 100))
+		})
+	})
+})
@@ -0,0 +1,117 @@
+package metrics
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/prometheus/common/model"
+)
+
+// InboundSourceHealth is the inbound traffic a service receives from one source deployment.
+// Requests from clients the proxy can't attribute have an empty deployment and namespace.
+type InboundSourceHealth struct {
+	Deployment  string   `json:"deployment"`
+	Namespace   string   `json:"namespace"`
+	RequestRate float64  `json:"requestRate"` // requests per second
+	SuccessRate *float64 `json:"successRate"` // percentage (0-100), null without responses
+}
+
+// GetInboundSourcesHealth breaks the inbound success rate of a service down by source
+// deployment, so a single misbehaving caller stands out from the aggregate of
+// get_service_metrics. Sources are sorted worst success rate first.
+func (c *MetricsCollector) GetInboundSourcesHealth(ctx context.Context, namespace, service, timeRangeStr string) (*mcp.CallToolResult, error) {
+	if !c.Available() {
+		return unavailableResult(), nil
+	}
+
+	tr, err := ParseTimeRange(timeRangeStr)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Invalid time range: %v", err)), nil
+	}
+
+	deployment, err := c.findDeploymentForService(ctx, namespace, service)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to find deployment: %v", err)), nil
+	}
+
+	window := tr.End.Sub(tr.Start)
+	requestRates, err := c.promClient.Query(ctx, c.queryBuilder.BuildInboundSourceRequestRateQuery(deployment, namespace, window), tr.End)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to query request rate by source: %v", err)), nil
+	}
+	successRates, err := c.promClient.Query(ctx, c.queryBuilder.BuildInboundSourceSuccessRateQuery(deployment, namespace, window), tr.End)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to query success rate by source: %v", err)), nil
+	}
+
+	sources := BuildInboundSourcesHealth(requestRates, successRates)
+
+	data, err := json.Marshal(map[string]interface{}{
+		"service":      service,
+		"namespace":    namespace,
+		"timeRange":    tr,
+		"sources":      sources,
+		"totalSources": len(sources),
+	})
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal sources health: %v", err)), nil
+	}
+
+	return mcp.NewToolResultText(string(data)), nil
+}
+
+// BuildInboundSourcesHealth joins per-source request rates and success rates (0-1), grouped by
+// src_deployment and src_namespace. Sources are sorted by success rate, worst first, with sources
+// without a success rate last; ties go to the busiest source.
+func BuildInboundSourcesHealth(requestRates, successRates model.Value) []InboundSourceHealth {
+	sourceKey := func(metric model.Metric) string {
+		return string(metric["src_namespace"]) + "/" + string(metric["src_deployment"])
+	}
+
+	successBySource := map[string]float64{}
+	if vector, ok := successRates.(model.Vector); ok {
+		for _, sample := range vector {
+			// The ratio is NaN for sources without responses
+			if !math.IsNaN(float64(sample.Value)) {
+				successBySource[sourceKey(sample.Metric)] = float64(sample.Value) * 100
+			}
+		}
+	}
+
+	sources := []InboundSourceHealth{}
+	vector, ok := requestRates.(model.Vector)
+	if !ok {
+		return sources
+	}
+	for _, sample := range vector {
+		if math.IsNaN(float64(sample.Value)) {
+			continue
+		}
+		source := InboundSourceHealth{
+			Deployment:  string(sample.Metric["src_deployment"]),
+			Namespace:   string(sample.Metric["src_namespace"]),
+			RequestRate: float64(sample.Value),
+		}
+		if successRate, ok := successBySource[sourceKey(sample.Metric)]; ok {
+			source.SuccessRate = &successRate
+		}
+		sources = append(sources, source)
+	}
+
+	sort.SliceStable(sources, func(i, j int) bool {
+		a, b := sources[i], sources[j]
+		if (a.SuccessRate == nil) != (b.SuccessRate == nil) {
+			return a.SuccessRate != nil
+		}
+		if a.SuccessRate != nil && *a.SuccessRate != *b.SuccessRate {
+			return *a.SuccessRate < *b.SuccessRate
+		}
+		return a.RequestRate > b.RequestRate
+	})
+
+	return sources
+}
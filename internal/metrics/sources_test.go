@@ -0,0 +1,61 @@
+package metrics_test
+
+import (
+	"math"
+
+	"github.com/christianhuening/linkerd-mcp/internal/metrics"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/common/model"
+)
+
+func sourceSample(namespace, deployment string, value float64) *model.Sample {
+	return &model.Sample{
+		Metric: model.Metric{"src_namespace": model.LabelValue(namespace), "src_deployment": model.LabelValue(deployment)},
+		Value:  model.SampleValue(value),
+	}
+}
+
+var _ = Describe("BuildInboundSourcesHealth", func() {
+	It("should sort sources worst success rate first", func() {
+		requestRates := model.Vector{
+			sourceSample("prod", "web", 10),
+			sourceSample("prod", "worker", 2),
+			sourceSample("batch", "cron", 0.5),
+			sourceSample("prod", "idle", 1),
+		}
+		successRates := model.Vector{
+			sourceSample("prod", "web", 0.999),
+			sourceSample("prod", "worker", 0.6),
+			sourceSample("batch", "cron", 0.999),
+			sourceSample("prod", "idle", math.NaN()),
+		}
+
+		sources := metrics.BuildInboundSourcesHealth(requestRates, successRates)
+
+		Expect(sources).To(HaveLen(4))
+		Expect(sources[0].Deployment).To(Equal("worker"))
+		Expect(*sources[0].SuccessRate).To(BeNumerically("~", 60, 0.001))
+		Expect(sources[0].RequestRate).To(BeNumerically("==", 2))
+
+		// Equal success rates go to the busiest source
+		Expect(sources[1].Deployment).To(Equal("web"))
+		Expect(sources[2].Deployment).To(Equal("cron"))
+		Expect(sources[2].Namespace).To(Equal("batch"))
+
+		Expect(sources[3].Deployment).To(Equal("idle"))
+		Expect(sources[3].SuccessRate).To(BeNil())
+	})
+
+	It("should keep traffic without source labels", func() {
+		sources := metrics.BuildInboundSourcesHealth(model.Vector{sourceSample("", "", 3)}, model.Vector{sourceSample("", "", 1)})
+
+		Expect(sources).To(HaveLen(1))
+		Expect(sources[0].Deployment).To(BeEmpty())
+		Expect(*sources[0].SuccessRate).To(BeNumerically("==", 100))
+	})
+
+	It("should return no sources for a non-vector result", func() {
+		Expect(metrics.BuildInboundSourcesHealth(nil, nil)).To(BeEmpty())
+	})
+})
@@ -0,0 +1,77 @@
+package metrics
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// TrafficThroughput is the volume of traffic from a source to a target service, as seen by the
+// source's outbound proxy. Rates are in bytes per second and totals in bytes over the window;
+// they are null when the source sent no traffic to the target.
+type TrafficThroughput struct {
+	Source                 ServiceIdentifier `json:"source"`
+	Target                 ServiceIdentifier `json:"target"`
+	TimeRange              TimeRange         `json:"timeRange"`
+	DataAvailable          bool              `json:"dataAvailable"`
+	BytesSentPerSecond     *float64          `json:"bytesSentPerSecond"`     // request bytes
+	BytesReceivedPerSecond *float64          `json:"bytesReceivedPerSecond"` // response bytes
+	TotalBytesSent         *float64          `json:"totalBytesSent"`
+	TotalBytesReceived     *float64          `json:"totalBytesReceived"`
+}
+
+// GetTrafficThroughput reports the bytes a source service sends to and receives from a target
+// service over a time range
+func (c *MetricsCollector) GetTrafficThroughput(ctx context.Context, sourceNs, sourceService, targetNs, targetService, timeRangeStr string) (*mcp.CallToolResult, error) {
+	if !c.Available() {
+		return unavailableResult(), nil
+	}
+
+	tr, err := ParseTimeRange(timeRangeStr)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Invalid time range: %v", err)), nil
+	}
+
+	srcDeployment, err := c.findDeploymentForService(ctx, sourceNs, sourceService)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to find source deployment: %v", err)), nil
+	}
+	dstDeployment, err := c.findDeploymentForService(ctx, targetNs, targetService)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to find target deployment: %v", err)), nil
+	}
+
+	window := tr.End.Sub(tr.Start)
+
+	sentResult, err := c.promClient.Query(ctx, c.queryBuilder.BuildByteSentQuery(srcDeployment, sourceNs, dstDeployment, targetNs, window), tr.End)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to query bytes sent: %v", err)), nil
+	}
+	receivedResult, err := c.promClient.Query(ctx, c.queryBuilder.BuildByteReceivedQuery(srcDeployment, sourceNs, dstDeployment, targetNs, window), tr.End)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to query bytes received: %v", err)), nil
+	}
+
+	sent := extractOptionalValue(sentResult)
+	received := extractOptionalValue(receivedResult)
+	throughput := TrafficThroughput{
+		Source:                 ServiceIdentifier{Service: sourceService, Namespace: sourceNs, Deployment: srcDeployment},
+		Target:                 ServiceIdentifier{Service: targetService, Namespace: targetNs, Deployment: dstDeployment},
+		TimeRange:              tr,
+		DataAvailable:          sent != nil || received != nil,
+		BytesSentPerSecond:     sent,
+		BytesReceivedPerSecond: received,
+		// The rates are averaged over the whole window, so they scale to its totals
+		TotalBytesSent:     scaleOptional(sent, window.Seconds()),
+		TotalBytesReceived: scaleOptional(received, window.Seconds()),
+	}
+
+	data, err := json.Marshal(throughput)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal throughput: %v", err)), nil
+	}
+
+	return mcp.NewToolResultText(string(data)), nil
+}
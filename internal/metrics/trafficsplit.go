@@ -0,0 +1,223 @@
+package metrics
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/prometheus/common/model"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// DefaultTrafficSplitDriftThreshold is the difference, in percentage points, between the
+// configured and observed share of a backend above which the split is reported as drifting
+const DefaultTrafficSplitDriftThreshold = 10.0
+
+// httpRouteGVRs lists the HTTPRoute flavours looked up by name, in order: Linkerd's own
+// policy.linkerd.io HTTPRoute and the Gateway API HTTPRoute
+var httpRouteGVRs = []schema.GroupVersionResource{
+	{Group: "policy.linkerd.io", Version: "v1alpha1", Resource: "httproutes"},
+	{Group: "gateway.networking.k8s.io", Version: "v1", Resource: "httproutes"},
+}
+
+// BackendSplit compares the configured and observed traffic share of one backendRef
+type BackendSplit struct {
+	Service           string  `json:"service"`
+	Namespace         string  `json:"namespace"`
+	Port              int64   `json:"port,omitempty"`
+	Weight            int64   `json:"weight"`
+	ConfiguredPercent float64 `json:"configuredPercent"` // share of the rule's total weight (0-100)
+	RequestRate       float64 `json:"requestRate"`       // requests per second
+	ObservedPercent   float64 `json:"observedPercent"`   // share of the rule's observed requests (0-100)
+	Drift             float64 `json:"drift"`             // observed minus configured, in percentage points
+	Drifting          bool    `json:"drifting"`
+}
+
+// RuleSplit is the traffic split of a single HTTPRoute rule
+type RuleSplit struct {
+	Rule             int            `json:"rule"` // index of the rule in spec.rules
+	Backends         []BackendSplit `json:"backends"`
+	TotalRequestRate float64        `json:"totalRequestRate"`
+	Drifting         bool           `json:"drifting"`
+}
+
+// TrafficSplitReport contains the configured and observed traffic split of an HTTPRoute
+type TrafficSplitReport struct {
+	Route          string      `json:"route"`
+	Namespace      string      `json:"namespace"`
+	APIGroup       string      `json:"apiGroup"`
+	TimeRange      TimeRange   `json:"timeRange"`
+	DriftThreshold float64     `json:"driftThreshold"`
+	Rules          []RuleSplit `json:"rules"`
+	Drifting       bool        `json:"drifting"`
+}
+
+// GetTrafficSplit reports the backendRef weights of an HTTPRoute next to the split observed in
+// the outbound request rate of each backend, and flags backends whose observed share differs
+// from the configured one by more than driftThreshold percentage points
+func (c *MetricsCollector) GetTrafficSplit(ctx context.Context, namespace, route, timeRangeStr string, driftThreshold float64) (*mcp.CallToolResult, error) {
+	if !c.Available() {
+		return unavailableResult(), nil
+	}
+
+	tr, err := ParseTimeRange(timeRangeStr)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Invalid time range: %v", err)), nil
+	}
+
+	if driftThreshold <= 0 {
+		driftThreshold = DefaultTrafficSplitDriftThreshold
+	}
+
+	httpRoute, err := c.getHTTPRoute(ctx, namespace, route)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to get HTTPRoute: %v", err)), nil
+	}
+
+	backends := []ServiceIdentifier{}
+	for _, rule := range parseRouteRules(httpRoute) {
+		for _, backend := range rule.Backends {
+			backends = append(backends, ServiceIdentifier{Service: backend.Service, Namespace: backend.Namespace})
+		}
+	}
+
+	var requestRates model.Value
+	if len(backends) > 0 {
+		query := c.queryBuilder.BuildBackendRequestRateQuery(backends, tr.End.Sub(tr.Start))
+		requestRates, err = c.promClient.Query(ctx, query, tr.End)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to query backend request rates: %v", err)), nil
+		}
+	}
+
+	report := TrafficSplitReport{
+		Route:          route,
+		Namespace:      namespace,
+		APIGroup:       httpRoute.GroupVersionKind().Group,
+		TimeRange:      tr,
+		DriftThreshold: driftThreshold,
+		Rules:          BuildTrafficSplit(httpRoute, requestRates, driftThreshold),
+	}
+	for _, rule := range report.Rules {
+		if rule.Drifting {
+			report.Drifting = true
+		}
+	}
+
+	data, err := json.Marshal(report)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal traffic split: %v", err)), nil
+	}
+
+	return mcp.NewToolResultText(string(data)), nil
+}
+
+// getHTTPRoute looks up an HTTPRoute by name, trying each supported API group in turn
+func (c *MetricsCollector) getHTTPRoute(ctx context.Context, namespace, name string) (*unstructured.Unstructured, error) {
+	var lastErr error
+	for _, gvr := range httpRouteGVRs {
+		route, err := c.dynamicClient.Resource(gvr).Namespace(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err == nil {
+			return route, nil
+		}
+		if !errors.IsNotFound(err) {
+			return nil, err
+		}
+		lastErr = err
+	}
+	return nil, lastErr
+}
+
+// BuildTrafficSplit joins the backendRef weights of each HTTPRoute rule with the request rates
+// grouped by dst_service and dst_namespace. Rules without backendRefs are left out. Drift is only
+// flagged for rules that received traffic.
+func BuildTrafficSplit(route *unstructured.Unstructured, requestRates model.Value, driftThreshold float64) []RuleSplit {
+	ratesByBackend := map[string]float64{}
+	if vector, ok := requestRates.(model.Vector); ok {
+		for _, sample := range vector {
+			if math.IsNaN(float64(sample.Value)) {
+				continue
+			}
+			key := string(sample.Metric["dst_namespace"]) + "/" + string(sample.Metric["dst_service"])
+			ratesByBackend[key] += float64(sample.Value)
+		}
+	}
+
+	rules := parseRouteRules(route)
+	for i := range rules {
+		rule := &rules[i]
+		for j := range rule.Backends {
+			backend := &rule.Backends[j]
+			backend.RequestRate = ratesByBackend[backend.Namespace+"/"+backend.Service]
+			rule.TotalRequestRate += backend.RequestRate
+		}
+
+		if rule.TotalRequestRate == 0 {
+			continue
+		}
+		for j := range rule.Backends {
+			backend := &rule.Backends[j]
+			backend.ObservedPercent = backend.RequestRate / rule.TotalRequestRate * 100
+			backend.Drift = backend.ObservedPercent - backend.ConfiguredPercent
+			if math.Abs(backend.Drift) > driftThreshold {
+				backend.Drifting = true
+				rule.Drifting = true
+			}
+		}
+	}
+
+	return rules
+}
+
+// parseRouteRules extracts the backendRefs of each rule with their configured share. As in the
+// Gateway API, backendRefs without a weight have a weight of 1 and default to the route's namespace.
+func parseRouteRules(route *unstructured.Unstructured) []RuleSplit {
+	rules := []RuleSplit{}
+	ruleList, _, _ := unstructured.NestedSlice(route.Object, "spec", "rules")
+
+	for i, r := range ruleList {
+		ruleMap, ok := r.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		backendRefs, _, _ := unstructured.NestedSlice(ruleMap, "backendRefs")
+		if len(backendRefs) == 0 {
+			continue
+		}
+
+		rule := RuleSplit{Rule: i, Backends: []BackendSplit{}}
+		totalWeight := int64(0)
+		for _, ref := range backendRefs {
+			refMap, ok := ref.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			backend := BackendSplit{Weight: 1}
+			backend.Service, _, _ = unstructured.NestedString(refMap, "name")
+			backend.Namespace, _, _ = unstructured.NestedString(refMap, "namespace")
+			if backend.Namespace == "" {
+				backend.Namespace = route.GetNamespace()
+			}
+			backend.Port, _, _ = unstructured.NestedInt64(refMap, "port")
+			if weight, found, _ := unstructured.NestedInt64(refMap, "weight"); found {
+				backend.Weight = weight
+			}
+			totalWeight += backend.Weight
+			rule.Backends = append(rule.Backends, backend)
+		}
+
+		if totalWeight > 0 {
+			for j := range rule.Backends {
+				rule.Backends[j].ConfiguredPercent = float64(rule.Backends[j].Weight) / float64(totalWeight) * 100
+			}
+		}
+		rules = append(rules, rule)
+	}
+
+	return rules
+}
@@ -0,0 +1,106 @@
+package metrics_test
+
+import (
+	"github.com/christianhuening/linkerd-mcp/internal/metrics"
+	"github.com/christianhuening/linkerd-mcp/internal/testutil"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/common/model"
+)
+
+func backendSample(service, namespace string, value float64) *model.Sample {
+	return &model.Sample{
+		Metric: model.Metric{
+			"dst_service":   model.LabelValue(service),
+			"dst_namespace": model.LabelValue(namespace),
+		},
+		Value: model.SampleValue(value),
+	}
+}
+
+var _ = Describe("BuildTrafficSplit", func() {
+	canaryRoute := testutil.CreateHTTPRoute("web-canary", "prod", "web", [][]map[string]interface{}{
+		{
+			{"name": "web-stable", "port": int64(8080), "weight": int64(90)},
+			{"name": "web-canary", "port": int64(8080), "weight": int64(10)},
+		},
+	})
+
+	It("should report a split that matches the configured weights", func() {
+		rates := model.Vector{
+			backendSample("web-stable", "prod", 91),
+			backendSample("web-canary", "prod", 9),
+		}
+
+		rules := metrics.BuildTrafficSplit(canaryRoute, rates, metrics.DefaultTrafficSplitDriftThreshold)
+
+		Expect(rules).To(HaveLen(1))
+		Expect(rules[0].TotalRequestRate).To(BeNumerically("~", 100, 0.001))
+		Expect(rules[0].Drifting).To(BeFalse())
+
+		stable := rules[0].Backends[0]
+		Expect(stable.Service).To(Equal("web-stable"))
+		Expect(stable.Namespace).To(Equal("prod"))
+		Expect(stable.Port).To(BeNumerically("==", 8080))
+		Expect(stable.ConfiguredPercent).To(BeNumerically("~", 90, 0.001))
+		Expect(stable.ObservedPercent).To(BeNumerically("~", 91, 0.001))
+		Expect(stable.Drift).To(BeNumerically("~", 1, 0.001))
+
+		canary := rules[0].Backends[1]
+		Expect(canary.ConfiguredPercent).To(BeNumerically("~", 10, 0.001))
+		Expect(canary.ObservedPercent).To(BeNumerically("~", 9, 0.001))
+		Expect(canary.Drifting).To(BeFalse())
+	})
+
+	It("should flag drift between configured and observed split", func() {
+		rates := model.Vector{
+			backendSample("web-stable", "prod", 50),
+			backendSample("web-canary", "prod", 50),
+		}
+
+		rules := metrics.BuildTrafficSplit(canaryRoute, rates, metrics.DefaultTrafficSplitDriftThreshold)
+
+		Expect(rules[0].Drifting).To(BeTrue())
+		Expect(rules[0].Backends[0].Drift).To(BeNumerically("~", -40, 0.001))
+		Expect(rules[0].Backends[0].Drifting).To(BeTrue())
+		Expect(rules[0].Backends[1].Drift).To(BeNumerically("~", 40, 0.001))
+		Expect(rules[0].Backends[1].Drifting).To(BeTrue())
+	})
+
+	It("should not flag drift for a rule without traffic", func() {
+		rules := metrics.BuildTrafficSplit(canaryRoute, model.Vector{}, metrics.DefaultTrafficSplitDriftThreshold)
+
+		Expect(rules).To(HaveLen(1))
+		Expect(rules[0].TotalRequestRate).To(BeZero())
+		Expect(rules[0].Drifting).To(BeFalse())
+		Expect(rules[0].Backends[1].ConfiguredPercent).To(BeNumerically("~", 10, 0.001))
+		Expect(rules[0].Backends[1].ObservedPercent).To(BeZero())
+	})
+
+	It("should default weights to 1 and namespaces to the route's namespace", func() {
+		route := testutil.CreateHTTPRoute("api", "prod", "api", [][]map[string]interface{}{
+			{},
+			{
+				{"name": "api-v1"},
+				{"name": "api-v2", "namespace": "staging"},
+			},
+		})
+		rates := model.Vector{
+			backendSample("api-v1", "prod", 30),
+			backendSample("api-v2", "staging", 10),
+			backendSample("api-v2", "prod", 60),
+		}
+
+		rules := metrics.BuildTrafficSplit(route, rates, 20)
+
+		Expect(rules).To(HaveLen(1))
+		Expect(rules[0].Rule).To(Equal(1))
+		Expect(rules[0].Backends[0].Weight).To(BeNumerically("==", 1))
+		Expect(rules[0].Backends[0].Namespace).To(Equal("prod"))
+		Expect(rules[0].Backends[0].ConfiguredPercent).To(BeNumerically("~", 50, 0.001))
+		Expect(rules[0].Backends[0].ObservedPercent).To(BeNumerically("~", 75, 0.001))
+		Expect(rules[0].Backends[1].Namespace).To(Equal("staging"))
+		Expect(rules[0].Backends[1].RequestRate).To(BeNumerically("==", 10))
+		Expect(rules[0].Drifting).To(BeTrue())
+	})
+})
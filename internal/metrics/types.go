@@ -1,6 +1,7 @@
 package metrics
 
 import (
+	"fmt"
 	"time"
 )
 
@@ -11,27 +12,36 @@ type TimeRange struct {
 	Step  time.Duration // Step duration for range queries
 }
 
-// ServiceMetrics contains comprehensive metrics for a single service
+// ServiceMetrics contains comprehensive metrics for a single service. Rates and latencies are
+// null when Prometheus returned no samples for them, so that a service without traffic isn't
+// mistaken for an idle but healthy one.
 type ServiceMetrics struct {
-	Service         string              `json:"service"`
-	Namespace       string              `json:"namespace"`
-	Deployment      string              `json:"deployment,omitempty"`
-	TimeRange       TimeRange           `json:"timeRange"`
-	RequestRate     float64             `json:"requestRate"`     // requests per second
-	SuccessRate     float64             `json:"successRate"`     // percentage (0-100)
-	ErrorRate       float64             `json:"errorRate"`       // percentage (0-100)
-	Latency         LatencyMetrics      `json:"latency"`
-	TopDestinations []TrafficFlow       `json:"topDestinations,omitempty"`
-	TopSources      []TrafficFlow       `json:"topSources,omitempty"`
-	ErrorsByStatus  map[string]int64    `json:"errorsByStatus,omitempty"` // HTTP status code -> count
-}
-
-// LatencyMetrics contains latency percentiles
+	Service            string           `json:"service"`
+	Namespace          string           `json:"namespace"`
+	Deployment         string           `json:"deployment,omitempty"`
+	Direction          Direction        `json:"direction,omitempty"` // inbound or outbound
+	TimeRange          TimeRange        `json:"timeRange"`
+	DataAvailable      bool             `json:"dataAvailable"`         // whether any request metrics were found
+	RequestRate        *float64         `json:"requestRate"`           // requests per second
+	SuccessRate        *float64         `json:"successRate"`           // percentage (0-100)
+	ErrorRate          *float64         `json:"errorRate"`             // percentage (0-100)
+	RetryRate          *float64         `json:"retryRate,omitempty"`   // retries per second, when retries are configured
+	TimeoutRate        *float64         `json:"timeoutRate,omitempty"` // timed out requests per second, when timeouts are configured
+	Latency            LatencyMetrics   `json:"latency"`
+	TopDestinations    []TrafficFlow    `json:"topDestinations,omitempty"`
+	TopSources         []TrafficFlow    `json:"topSources,omitempty"`
+	ErrorsByStatus     map[string]int64 `json:"errorsByStatus,omitempty"`     // HTTP status code -> count
+	GRPCErrorsByStatus map[string]int64 `json:"grpcErrorsByStatus,omitempty"` // gRPC status code -> failed responses in the time range
+	Partial            bool             `json:"partial"`                      // whether optional queries failed
+	Warnings           []string         `json:"warnings,omitempty"`           // errors of the failed optional queries
+}
+
+// LatencyMetrics contains latency percentiles. Each is null when no requests were observed.
 type LatencyMetrics struct {
-	P50  float64 `json:"p50"`  // 50th percentile in milliseconds
-	P95  float64 `json:"p95"`  // 95th percentile in milliseconds
-	P99  float64 `json:"p99"`  // 99th percentile in milliseconds
-	Mean float64 `json:"mean"` // mean latency in milliseconds
+	P50  *float64 `json:"p50"`  // 50th percentile in milliseconds
+	P95  *float64 `json:"p95"`  // 95th percentile in milliseconds
+	P99  *float64 `json:"p99"`  // 99th percentile in milliseconds
+	Mean *float64 `json:"mean"` // mean latency in milliseconds
 }
 
 // TrafficMetrics contains metrics for traffic between two services
@@ -65,19 +75,26 @@ type TrafficFlow struct {
 	Deployment   string  `json:"deployment,omitempty"`
 	RequestCount int64   `json:"requestCount"`
 	RequestRate  float64 `json:"requestRate"` // requests per second
+	SuccessRate  float64 `json:"successRate"` // percentage (0-100)
+	LatencyP95   float64 `json:"latencyP95"`  // milliseconds
 }
 
-// ServiceHealthSummary contains health status based on metrics
+// ServiceHealthSummary contains health status based on metrics. Rates and latencies are null
+// when their query failed or returned no data.
 type ServiceHealthSummary struct {
-	Service        string         `json:"service"`
-	Namespace      string         `json:"namespace"`
-	Deployment     string         `json:"deployment,omitempty"`
-	HealthStatus   HealthStatus   `json:"healthStatus"`
-	RequestRate    float64        `json:"requestRate"`
-	SuccessRate    float64        `json:"successRate"`
-	ErrorRate      float64        `json:"errorRate"`
-	LatencyP95     float64        `json:"latencyP95"`
-	Issues         []HealthIssue  `json:"issues,omitempty"`
+	Service      string       `json:"service"`
+	Namespace    string       `json:"namespace"`
+	Deployment   string       `json:"deployment,omitempty"`
+	HealthStatus HealthStatus `json:"healthStatus"`
+	RequestRate  *float64     `json:"requestRate"`
+	SuccessRate  *float64     `json:"successRate"`
+	ErrorRate    *float64     `json:"errorRate"`
+	// ClientErrorRate and ServerErrorRate are the percentages of 4xx and 5xx responses, set only
+	// when the thresholds split errors by status class
+	ClientErrorRate *float64      `json:"clientErrorRate,omitempty"`
+	ServerErrorRate *float64      `json:"serverErrorRate,omitempty"`
+	LatencyP95      *float64      `json:"latencyP95"`
+	Issues          []HealthIssue `json:"issues,omitempty"`
 }
 
 // HealthStatus represents the overall health of a service
@@ -101,19 +118,22 @@ type HealthIssue struct {
 
 // ServiceRanking represents a ranked list of services by a metric
 type ServiceRanking struct {
-	SortBy   string                     `json:"sortBy"`
-	Services []ServiceMetricSummary     `json:"services"`
+	SortBy   string                 `json:"sortBy"`
+	Services []ServiceMetricSummary `json:"services"`
+	Partial  bool                   `json:"partial"`            // whether queries of some services failed
+	Warnings []string               `json:"warnings,omitempty"` // errors of the failed queries
 }
 
-// ServiceMetricSummary contains summary metrics for ranking
+// ServiceMetricSummary contains summary metrics for ranking. Rates and latencies are null when
+// their query failed or returned no data.
 type ServiceMetricSummary struct {
-	Service     string  `json:"service"`
-	Namespace   string  `json:"namespace"`
-	Deployment  string  `json:"deployment,omitempty"`
-	RequestRate float64 `json:"requestRate"`
-	SuccessRate float64 `json:"successRate"`
-	ErrorRate   float64 `json:"errorRate"`
-	LatencyP95  float64 `json:"latencyP95"`
+	Service     string   `json:"service"`
+	Namespace   string   `json:"namespace"`
+	Deployment  string   `json:"deployment,omitempty"`
+	RequestRate *float64 `json:"requestRate"`
+	SuccessRate *float64 `json:"successRate"`
+	ErrorRate   *float64 `json:"errorRate"`
+	LatencyP95  *float64 `json:"latencyP95"`
 }
 
 // HealthThresholds defines thresholds for health assessment
@@ -124,18 +144,110 @@ type HealthThresholds struct {
 	LatencyP95Critical  float64 // P95 latency ms that triggers critical
 	SuccessRateWarning  float64 // Success rate % below which triggers warning
 	SuccessRateCritical float64 // Success rate % below which triggers critical
+	MinRequestRate      float64 // Requests per second below which health is unknown
+
+	// Thresholds per HTTP status class, zero when unset. Setting any of them assesses the 4xx
+	// and 5xx rates separately instead of the combined error rate.
+	ClientErrorRateWarning  float64 // 4xx rate % that triggers warning
+	ClientErrorRateCritical float64 // 4xx rate % that triggers critical
+	ServerErrorRateWarning  float64 // 5xx rate % that triggers warning
+	ServerErrorRateCritical float64 // 5xx rate % that triggers critical
+}
+
+// SplitsStatusClasses reports whether the thresholds assess 4xx and 5xx rates separately
+func (t HealthThresholds) SplitsStatusClasses() bool {
+	return t.ClientErrorRateWarning > 0 || t.ClientErrorRateCritical > 0 ||
+		t.ServerErrorRateWarning > 0 || t.ServerErrorRateCritical > 0
 }
 
 // DefaultHealthThresholds returns sensible default thresholds
 func DefaultHealthThresholds() HealthThresholds {
 	return HealthThresholds{
-		ErrorRateWarning:    5.0,   // 5% error rate
-		ErrorRateCritical:   10.0,  // 10% error rate
-		LatencyP95Warning:   1000,  // 1 second
-		LatencyP95Critical:  5000,  // 5 seconds
-		SuccessRateWarning:  95.0,  // 95% success rate
-		SuccessRateCritical: 90.0,  // 90% success rate
+		ErrorRateWarning:    5.0,  // 5% error rate
+		ErrorRateCritical:   10.0, // 10% error rate
+		LatencyP95Warning:   1000, // 1 second
+		LatencyP95Critical:  5000, // 5 seconds
+		SuccessRateWarning:  95.0, // 95% success rate
+		SuccessRateCritical: 90.0, // 90% success rate
+		MinRequestRate:      0.1,  // 6 requests per minute
+	}
+}
+
+// Validate checks that every critical threshold is stricter than its warning threshold: a higher
+// error rate and latency, and a lower success rate
+func (t HealthThresholds) Validate() error {
+	if t.ErrorRateCritical <= t.ErrorRateWarning {
+		return fmt.Errorf("error rate critical threshold (%g) must be greater than the warning threshold (%g)", t.ErrorRateCritical, t.ErrorRateWarning)
+	}
+	if t.LatencyP95Critical <= t.LatencyP95Warning {
+		return fmt.Errorf("p95 latency critical threshold (%g) must be greater than the warning threshold (%g)", t.LatencyP95Critical, t.LatencyP95Warning)
+	}
+	if t.SuccessRateCritical >= t.SuccessRateWarning {
+		return fmt.Errorf("success rate critical threshold (%g) must be less than the warning threshold (%g)", t.SuccessRateCritical, t.SuccessRateWarning)
+	}
+	if t.MinRequestRate < 0 {
+		return fmt.Errorf("minimum request rate (%g) must not be negative", t.MinRequestRate)
+	}
+	for _, class := range []struct {
+		name              string
+		warning, critical float64
+	}{
+		{"client error rate", t.ClientErrorRateWarning, t.ClientErrorRateCritical},
+		{"server error rate", t.ServerErrorRateWarning, t.ServerErrorRateCritical},
+	} {
+		if class.warning < 0 || class.critical < 0 {
+			return fmt.Errorf("%s thresholds must not be negative", class.name)
+		}
+		if class.warning > 0 && class.critical > 0 && class.critical <= class.warning {
+			return fmt.Errorf("%s critical threshold (%g) must be greater than the warning threshold (%g)", class.name, class.critical, class.warning)
+		}
+	}
+	return nil
+}
+
+// MaxRangeQueryPoints caps the number of points a range query may return per series
+const MaxRangeQueryPoints = 1000
+
+// MinRangeQueryStep is the finest step accepted for range queries
+const MinRangeQueryStep = time.Second
+
+// ParseTimeRangeWithStep parses a time range and overrides the computed step with stepStr
+// when it is set. The step is validated against the range so that the query returns at most
+// MaxRangeQueryPoints points.
+func ParseTimeRangeWithStep(rangeStr, stepStr string) (TimeRange, error) {
+	tr, err := ParseTimeRange(rangeStr)
+	if err != nil {
+		return TimeRange{}, err
 	}
+
+	window := tr.End.Sub(tr.Start)
+
+	if stepStr == "" {
+		// Coarsen the computed step for very long ranges to stay within the point cap
+		if window/tr.Step > MaxRangeQueryPoints {
+			tr.Step = (window + MaxRangeQueryPoints - 1) / MaxRangeQueryPoints
+		}
+		return tr, nil
+	}
+
+	step, err := time.ParseDuration(stepStr)
+	if err != nil {
+		return TimeRange{}, fmt.Errorf("invalid step: %w", err)
+	}
+	if step < MinRangeQueryStep {
+		return TimeRange{}, fmt.Errorf("step %s is below the minimum of %s", step, MinRangeQueryStep)
+	}
+
+	if step > window {
+		return TimeRange{}, fmt.Errorf("step %s is larger than the time range %s", step, window)
+	}
+	if points := int(window / step); points > MaxRangeQueryPoints {
+		return TimeRange{}, fmt.Errorf("step %s over %s would return %d points, the maximum is %d (use a step of at least %s)",
+			step, window, points, MaxRangeQueryPoints, window/MaxRangeQueryPoints)
+	}
+
+	tr.Step = step
+	return tr, nil
 }
 
 // ParseTimeRange parses a string like "5m", "1h", "24h" into a TimeRange
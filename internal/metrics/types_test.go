@@ -52,6 +52,56 @@ var _ = Describe("Types", func() {
 		})
 	})
 
+	Describe("ParseTimeRangeWithStep", func() {
+		It("should keep the computed step when none is given", func() {
+			tr, err := metrics.ParseTimeRangeWithStep("1h", "")
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(tr.Step).To(Equal(30 * time.Second))
+		})
+
+		It("should override the step with a custom value", func() {
+			tr, err := metrics.ParseTimeRangeWithStep("1h", "5s")
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(tr.End.Sub(tr.Start)).To(Equal(1 * time.Hour))
+			Expect(tr.Step).To(Equal(5 * time.Second))
+		})
+
+		It("should reject a step that exceeds the point cap", func() {
+			_, err := metrics.ParseTimeRangeWithStep("24h", "10s")
+
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("maximum is 1000"))
+		})
+
+		It("should accept a step exactly at the point cap", func() {
+			tr, err := metrics.ParseTimeRangeWithStep("1000m", "1m")
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(tr.Step).To(Equal(time.Minute))
+		})
+
+		It("should coarsen the computed step for long ranges", func() {
+			tr, err := metrics.ParseTimeRangeWithStep("720h", "")
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(int(tr.End.Sub(tr.Start) / tr.Step)).To(BeNumerically("<=", metrics.MaxRangeQueryPoints))
+		})
+
+		It("should reject a step larger than the range", func() {
+			_, err := metrics.ParseTimeRangeWithStep("5m", "10m")
+
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("should reject an invalid step", func() {
+			_, err := metrics.ParseTimeRangeWithStep("5m", "fast")
+
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
 	Describe("DefaultHealthThresholds", func() {
 		It("should return sensible defaults", func() {
 			thresholds := metrics.DefaultHealthThresholds()
@@ -62,6 +112,50 @@ var _ = Describe("Types", func() {
 			Expect(thresholds.LatencyP95Critical).To(Equal(5000.0))
 			Expect(thresholds.SuccessRateWarning).To(Equal(95.0))
 			Expect(thresholds.SuccessRateCritical).To(Equal(90.0))
+			Expect(thresholds.MinRequestRate).To(Equal(0.1))
+			Expect(thresholds.SplitsStatusClasses()).To(BeFalse())
+		})
+	})
+
+	Describe("HealthThresholds.Validate", func() {
+		It("should accept the defaults", func() {
+			Expect(metrics.DefaultHealthThresholds().Validate()).To(Succeed())
+		})
+
+		It("should reject an error rate critical threshold below the warning threshold", func() {
+			thresholds := metrics.DefaultHealthThresholds()
+			thresholds.ErrorRateCritical = 2
+
+			Expect(thresholds.Validate()).To(MatchError(ContainSubstring("error rate critical threshold (2)")))
+		})
+
+		It("should reject a latency critical threshold equal to the warning threshold", func() {
+			thresholds := metrics.DefaultHealthThresholds()
+			thresholds.LatencyP95Warning = 200
+			thresholds.LatencyP95Critical = 200
+
+			Expect(thresholds.Validate()).To(MatchError(ContainSubstring("p95 latency")))
+		})
+
+		It("should reject a success rate critical threshold above the warning threshold", func() {
+			thresholds := metrics.DefaultHealthThresholds()
+			thresholds.SuccessRateCritical = 99
+
+			Expect(thresholds.Validate()).To(MatchError(ContainSubstring("success rate critical threshold (99)")))
+		})
+
+		It("should check status class thresholds only when both are set", func() {
+			thresholds := metrics.DefaultHealthThresholds()
+			thresholds.ClientErrorRateCritical = 30
+			Expect(thresholds.Validate()).To(Succeed())
+			Expect(thresholds.SplitsStatusClasses()).To(BeTrue())
+
+			thresholds.ClientErrorRateWarning = 40
+			Expect(thresholds.Validate()).To(MatchError(ContainSubstring("client error rate critical threshold (30)")))
+
+			thresholds = metrics.DefaultHealthThresholds()
+			thresholds.ServerErrorRateWarning = -1
+			Expect(thresholds.Validate()).To(MatchError(ContainSubstring("server error rate thresholds must not be negative")))
 		})
 	})
 
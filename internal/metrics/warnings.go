@@ -0,0 +1,45 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/prometheus/common/model"
+)
+
+// queryWarnings collects the errors of optional queries. A failed optional query leaves its
+// metric without data; instead of failing the result, its error becomes a warning and the result
+// is flagged as partial. It is safe for concurrent use.
+type queryWarnings struct {
+	mu       sync.Mutex
+	messages []string
+}
+
+func (w *queryWarnings) add(format string, args ...interface{}) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.messages = append(w.messages, fmt.Sprintf(format, args...))
+}
+
+// list returns the warnings in a stable order, regardless of the order the queries failed in
+func (w *queryWarnings) list() []string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	messages := append([]string(nil), w.messages...)
+	sort.Strings(messages)
+	return messages
+}
+
+// optionalQuery executes a query whose failure leaves the result incomplete rather than failing
+// it. On error it records a warning naming the metric and returns nil.
+func (c *MetricsCollector) optionalQuery(ctx context.Context, warnings *queryWarnings, metric, query string, ts time.Time) model.Value {
+	result, err := c.promClient.Query(ctx, query, ts)
+	if err != nil {
+		warnings.add("failed to query %s: %v", metric, err)
+		return nil
+	}
+	return result
+}
@@ -0,0 +1,332 @@
+// Package overview summarizes the whole mesh in one view: control plane health, meshed
+// workloads, proxy versions, validation errors and, when Prometheus is reachable, traffic
+package overview
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/christianhuening/linkerd-mcp/internal/health"
+	"github.com/christianhuening/linkerd-mcp/internal/mesh"
+	"github.com/christianhuening/linkerd-mcp/internal/metrics"
+	"github.com/christianhuening/linkerd-mcp/internal/validation"
+	"github.com/christianhuening/linkerd-mcp/internal/validation/validators"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// MaxValidationErrors caps the validation errors listed in the overview; the total is always
+// reported
+const MaxValidationErrors = 20
+
+// metricsTimeRange is the window the metrics section looks at
+const metricsTimeRange = "5m"
+
+// Sections of the overview, as named in MeshOverview.Unavailable
+const (
+	SectionControlPlane = "controlPlane"
+	SectionDataPlane    = "dataPlane"
+	SectionServices     = "services"
+	SectionValidation   = "validation"
+	SectionMetrics      = "metrics"
+)
+
+// MeshOverview is the summary of the whole mesh. A section that couldn't be built is left out
+// and the reason recorded in Unavailable.
+type MeshOverview struct {
+	Healthy      bool                  `json:"healthy"` // no section reports a problem
+	Issues       []string              `json:"issues"`
+	ControlPlane *ControlPlaneOverview `json:"controlPlane,omitempty"`
+	DataPlane    *DataPlaneOverview    `json:"dataPlane,omitempty"`
+	Services     *ServicesOverview     `json:"services,omitempty"`
+	Validation   *ValidationOverview   `json:"validation,omitempty"`
+	Metrics      *MetricsOverview      `json:"metrics,omitempty"`
+	Unavailable  map[string]string     `json:"unavailable"` // section -> why it is missing
+}
+
+// ControlPlaneOverview summarizes check_mesh_health
+type ControlPlaneOverview struct {
+	Namespace     string `json:"namespace"`
+	TotalPods     int    `json:"totalPods"`
+	HealthyPods   int    `json:"healthyPods"`
+	UnhealthyPods int    `json:"unhealthyPods"`
+	DegradedPods  int    `json:"degradedPods"`
+}
+
+// DataPlaneOverview summarizes check_data_plane_health over the whole cluster
+type DataPlaneOverview struct {
+	ControlPlaneVersion string         `json:"controlPlaneVersion"`
+	TotalProxies        int            `json:"totalProxies"`
+	HealthyProxies      int            `json:"healthyProxies"`
+	UnhealthyProxies    int            `json:"unhealthyProxies"`
+	MeshedNamespaces    int            `json:"meshedNamespaces"`
+	ProxyVersions       map[string]int `json:"proxyVersions"`
+}
+
+// ServicesOverview summarizes list_meshed_services over the whole cluster
+type ServicesOverview struct {
+	MeshedServices int            `json:"meshedServices"`
+	ByNamespace    map[string]int `json:"byNamespace"`
+}
+
+// ValidationOverview lists the error-level findings of validate_mesh_config
+type ValidationOverview struct {
+	Errors   int               `json:"errors"`
+	Warnings int               `json:"warnings"`
+	Findings []ValidationError `json:"findings"` // at most MaxValidationErrors
+}
+
+// ValidationError is one error-level validation finding
+type ValidationError struct {
+	ResourceType string `json:"resourceType"`
+	Name         string `json:"name"`
+	Namespace    string `json:"namespace"`
+	Code         string `json:"code,omitempty"`
+	Message      string `json:"message"`
+}
+
+// MetricsOverview reports inbound traffic seen by Prometheus across the cluster
+type MetricsOverview struct {
+	TimeRange              string   `json:"timeRange"`
+	ServicesWithTraffic    int      `json:"servicesWithTraffic"`
+	ServicesWithoutMTLS    []string `json:"servicesWithoutFullMtls"` // inbound traffic not entirely over mTLS
+	LowestMTLSCoverage     *float64 `json:"lowestMtlsCoverage"`      // percent; null without traffic
+	LowestMTLSCoverageName string   `json:"lowestMtlsCoverageService,omitempty"`
+}
+
+// Builder assembles the mesh overview from the components behind the individual tools
+type Builder struct {
+	healthChecker    *health.Checker
+	serviceLister    *mesh.ServiceLister
+	configValidator  *validation.ConfigValidator
+	metricsCollector *metrics.MetricsCollector
+}
+
+// NewBuilder creates a new overview builder. The metrics collector may be nil, in which case
+// the metrics section is reported as unavailable.
+func NewBuilder(healthChecker *health.Checker, serviceLister *mesh.ServiceLister, configValidator *validation.ConfigValidator, metricsCollector *metrics.MetricsCollector) *Builder {
+	return &Builder{
+		healthChecker:    healthChecker,
+		serviceLister:    serviceLister,
+		configValidator:  configValidator,
+		metricsCollector: metricsCollector,
+	}
+}
+
+// GetMeshOverview summarizes the whole mesh. Each section is built independently, so a failing
+// component only leaves its own section out.
+func (b *Builder) GetMeshOverview(ctx context.Context) (*mcp.CallToolResult, error) {
+	overview := b.Build(ctx)
+
+	data, err := json.MarshalIndent(overview, "", "  ")
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal mesh overview: %v", err)), nil
+	}
+	return mcp.NewToolResultText(string(data)), nil
+}
+
+// Build collects every section of the overview and derives its issues
+func (b *Builder) Build(ctx context.Context) MeshOverview {
+	overview := MeshOverview{Issues: []string{}, Unavailable: map[string]string{}}
+	unavailable := func(section string, err error) {
+		overview.Unavailable[section] = err.Error()
+	}
+
+	if controlPlane, err := b.controlPlane(ctx); err != nil {
+		unavailable(SectionControlPlane, err)
+	} else {
+		overview.ControlPlane = controlPlane
+	}
+	if dataPlane, err := b.dataPlane(ctx); err != nil {
+		unavailable(SectionDataPlane, err)
+	} else {
+		overview.DataPlane = dataPlane
+	}
+	if services, err := b.services(ctx); err != nil {
+		unavailable(SectionServices, err)
+	} else {
+		overview.Services = services
+	}
+	if validation, err := b.validation(ctx); err != nil {
+		unavailable(SectionValidation, err)
+	} else {
+		overview.Validation = validation
+	}
+	if metricsOverview, err := b.metrics(ctx); err != nil {
+		unavailable(SectionMetrics, err)
+	} else {
+		overview.Metrics = metricsOverview
+	}
+
+	overview.Issues = Issues(overview)
+	overview.Healthy = len(overview.Issues) == 0
+	return overview
+}
+
+// Issues lists the problems the sections of an overview report. A missing control plane
+// section is a problem, since the control plane may be down; other missing sections aren't.
+func Issues(overview MeshOverview) []string {
+	issues := []string{}
+
+	switch cp := overview.ControlPlane; {
+	case cp == nil:
+		issues = append(issues, "control plane health is unknown")
+	case cp.TotalPods == 0:
+		issues = append(issues, fmt.Sprintf("no control plane pods found in namespace %s", cp.Namespace))
+	case cp.UnhealthyPods > 0 || cp.DegradedPods > 0:
+		issues = append(issues, fmt.Sprintf("control plane has %d unhealthy and %d degraded pods", cp.UnhealthyPods, cp.DegradedPods))
+	}
+
+	if dp := overview.DataPlane; dp != nil {
+		if dp.UnhealthyProxies > 0 {
+			issues = append(issues, fmt.Sprintf("%d of %d proxies are unhealthy", dp.UnhealthyProxies, dp.TotalProxies))
+		}
+		if len(dp.ProxyVersions) > 1 {
+			issues = append(issues, fmt.Sprintf("proxies run %d different versions", len(dp.ProxyVersions)))
+		}
+	}
+
+	if v := overview.Validation; v != nil && v.Errors > 0 {
+		issues = append(issues, fmt.Sprintf("configuration validation found %d errors", v.Errors))
+	}
+
+	if m := overview.Metrics; m != nil && len(m.ServicesWithoutMTLS) > 0 {
+		issues = append(issues, fmt.Sprintf("%d services receive traffic without mTLS", len(m.ServicesWithoutMTLS)))
+	}
+
+	return issues
+}
+
+func (b *Builder) controlPlane(ctx context.Context) (*ControlPlaneOverview, error) {
+	var controlPlane ControlPlaneOverview
+	result, err := b.healthChecker.CheckMeshHealth(ctx, "")
+	if err := decodeResult(result, err, &controlPlane); err != nil {
+		return nil, err
+	}
+	return &controlPlane, nil
+}
+
+func (b *Builder) dataPlane(ctx context.Context) (*DataPlaneOverview, error) {
+	var status struct {
+		DataPlaneOverview
+		Namespaces map[string]json.RawMessage `json:"namespaces"`
+	}
+	result, err := b.healthChecker.CheckDataPlaneHealth(ctx, "")
+	if err := decodeResult(result, err, &status); err != nil {
+		return nil, err
+	}
+
+	dataPlane := status.DataPlaneOverview
+	dataPlane.MeshedNamespaces = len(status.Namespaces)
+	if dataPlane.ProxyVersions == nil {
+		dataPlane.ProxyVersions = map[string]int{}
+	}
+	return &dataPlane, nil
+}
+
+func (b *Builder) services(ctx context.Context) (*ServicesOverview, error) {
+	var listing struct {
+		TotalServices int `json:"totalServices"`
+		Services      map[string]struct {
+			Namespace string `json:"namespace"`
+		} `json:"services"`
+	}
+	result, err := b.serviceLister.ListMeshedServices(ctx, "", "", false)
+	if err := decodeResult(result, err, &listing); err != nil {
+		return nil, err
+	}
+
+	services := &ServicesOverview{MeshedServices: listing.TotalServices, ByNamespace: map[string]int{}}
+	for _, service := range listing.Services {
+		services.ByNamespace[service.Namespace]++
+	}
+	return services, nil
+}
+
+func (b *Builder) validation(ctx context.Context) (*ValidationOverview, error) {
+	var report validators.ClusterValidationReport
+	result, err := b.configValidator.ValidateConfig(ctx, "", "all", "", "", "json", 0)
+	if err := decodeResult(result, err, &report); err != nil {
+		return nil, err
+	}
+
+	validation := &ValidationOverview{
+		Errors:   report.Summary.Errors,
+		Warnings: report.Summary.Warnings,
+		Findings: []ValidationError{},
+	}
+	for _, result := range report.Results {
+		for _, issue := range result.Issues {
+			if issue.Severity != validators.SeverityError || len(validation.Findings) == MaxValidationErrors {
+				continue
+			}
+			validation.Findings = append(validation.Findings, ValidationError{
+				ResourceType: result.ResourceType,
+				Name:         result.Name,
+				Namespace:    result.Namespace,
+				Code:         issue.Code,
+				Message:      issue.Message,
+			})
+		}
+	}
+	return validation, nil
+}
+
+func (b *Builder) metrics(ctx context.Context) (*MetricsOverview, error) {
+	if !b.metricsCollector.Available() {
+		return nil, fmt.Errorf("prometheus is not configured")
+	}
+
+	tr, err := metrics.ParseTimeRange(metricsTimeRange)
+	if err != nil {
+		return nil, err
+	}
+	coverage, err := b.metricsCollector.MTLSCoverage(ctx, "", tr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query mTLS coverage: %w", err)
+	}
+	return BuildMetricsOverview(metricsTimeRange, coverage), nil
+}
+
+// BuildMetricsOverview summarizes the per-service mTLS coverage of inbound traffic
+func BuildMetricsOverview(timeRange string, coverage map[string]float64) *MetricsOverview {
+	overview := &MetricsOverview{
+		TimeRange:           timeRange,
+		ServicesWithTraffic: len(coverage),
+		ServicesWithoutMTLS: []string{},
+	}
+
+	for service, percent := range coverage {
+		if percent < 100 {
+			overview.ServicesWithoutMTLS = append(overview.ServicesWithoutMTLS, service)
+		}
+		if overview.LowestMTLSCoverage == nil || percent < *overview.LowestMTLSCoverage ||
+			(percent == *overview.LowestMTLSCoverage && service < overview.LowestMTLSCoverageName) {
+			lowest := percent
+			overview.LowestMTLSCoverage = &lowest
+			overview.LowestMTLSCoverageName = service
+		}
+	}
+	sort.Strings(overview.ServicesWithoutMTLS)
+	return overview
+}
+
+// decodeResult decodes the JSON of a tool result into v. Tool errors are returned as errors.
+func decodeResult(result *mcp.CallToolResult, err error, v interface{}) error {
+	if err != nil {
+		return err
+	}
+
+	text := ""
+	for _, content := range result.Content {
+		if textContent, ok := content.(mcp.TextContent); ok {
+			text += textContent.Text
+		}
+	}
+	if result.IsError {
+		return errors.New(text)
+	}
+	return json.Unmarshal([]byte(text), v)
+}
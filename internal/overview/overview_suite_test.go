@@ -0,0 +1,13 @@
+package overview_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestOverview(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Overview Suite")
+}
@@ -0,0 +1,153 @@
+package overview_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/christianhuening/linkerd-mcp/internal/health"
+	"github.com/christianhuening/linkerd-mcp/internal/mesh"
+	"github.com/christianhuening/linkerd-mcp/internal/overview"
+	"github.com/christianhuening/linkerd-mcp/internal/testutil"
+	"github.com/christianhuening/linkerd-mcp/internal/validation"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/fake"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+)
+
+var _ = Describe("Overview", func() {
+	Describe("GetMeshOverview", func() {
+		var (
+			ctx     context.Context
+			builder *overview.Builder
+		)
+
+		BeforeEach(func() {
+			ctx = context.Background()
+
+			outdated := testutil.CreateMeshedPod("web-1", "staging", "web")
+			outdated.Spec.Containers[1].Image = "cr.l5d.io/linkerd/proxy:stable-2.13.0"
+
+			clientset := kubefake.NewSimpleClientset(
+				testutil.CreateLinkerdControlPlanePod("destination-1", "linkerd", "destination", corev1.PodRunning, true),
+				testutil.CreateMeshedPod("api-1", "prod", "api"),
+				outdated,
+				&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+					Name:        "prod",
+					Annotations: map[string]string{"linkerd.io/inject": "always"},
+				}},
+			)
+			gvrToListKind := map[schema.GroupVersionResource]string{
+				{Group: "policy.linkerd.io", Version: "v1beta3", Resource: "servers"}:                 "ServerList",
+				{Group: "policy.linkerd.io", Version: "v1alpha1", Resource: "authorizationpolicies"}:  "AuthorizationPolicyList",
+				{Group: "policy.linkerd.io", Version: "v1alpha1", Resource: "meshtlsauthentications"}: "MeshTLSAuthenticationList",
+				{Group: "policy.linkerd.io", Version: "v1alpha1", Resource: "networkauthentications"}: "NetworkAuthenticationList",
+				{Group: "linkerd.io", Version: "v1alpha2", Resource: "serviceprofiles"}:               "ServiceProfileList",
+				{Group: "policy.linkerd.io", Version: "v1alpha1", Resource: "egressnetworks"}:         "EgressNetworkList",
+				{Group: "gateway.networking.k8s.io", Version: "v1alpha2", Resource: "tlsroutes"}:      "TLSRouteList",
+			}
+			dynamicClient := fake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), gvrToListKind)
+
+			builder = overview.NewBuilder(
+				health.NewChecker(clientset),
+				mesh.NewServiceLister(clientset),
+				validation.NewConfigValidator(clientset, dynamicClient),
+				nil,
+			)
+		})
+
+		It("should summarize every section of the mesh", func() {
+			result, err := builder.GetMeshOverview(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.IsError).To(BeFalse())
+
+			var summary overview.MeshOverview
+			Expect(testutil.ParseJSONResult(result, &summary)).To(Succeed())
+
+			Expect(summary.ControlPlane).NotTo(BeNil())
+			Expect(summary.ControlPlane.TotalPods).To(Equal(1))
+			Expect(summary.ControlPlane.HealthyPods).To(Equal(1))
+
+			Expect(summary.DataPlane).NotTo(BeNil())
+			Expect(summary.DataPlane.TotalProxies).To(Equal(2))
+			Expect(summary.DataPlane.MeshedNamespaces).To(Equal(2))
+			Expect(summary.DataPlane.ProxyVersions).To(Equal(map[string]int{"stable-2.14.0": 1, "stable-2.13.0": 1}))
+
+			Expect(summary.Services).NotTo(BeNil())
+			Expect(summary.Services.MeshedServices).To(Equal(2))
+			Expect(summary.Services.ByNamespace).To(Equal(map[string]int{"prod": 1, "staging": 1}))
+
+			Expect(summary.Validation).NotTo(BeNil())
+			Expect(summary.Validation.Errors).To(BeNumerically(">=", 1))
+			Expect(summary.Validation.Findings).To(ContainElement(HaveField("Code", "LNKD-P003")))
+		})
+
+		It("should report the metrics section as unavailable without Prometheus", func() {
+			summary := builder.Build(ctx)
+
+			Expect(summary.Metrics).To(BeNil())
+			Expect(summary.Unavailable).To(HaveKey(overview.SectionMetrics))
+			Expect(summary.Unavailable).NotTo(HaveKey(overview.SectionControlPlane))
+		})
+
+		It("should collect the issues of every section", func() {
+			summary := builder.Build(ctx)
+
+			Expect(summary.Healthy).To(BeFalse())
+			Expect(summary.Issues).To(ContainElement("proxies run 2 different versions"))
+			Expect(summary.Issues).To(ContainElement(ContainSubstring("configuration validation found")))
+		})
+
+		It("should score the cluster from the same components", func() {
+			result, err := builder.GetHealthScore(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.IsError).To(BeFalse())
+
+			var score overview.HealthScore
+			Expect(testutil.ParseJSONResult(result, &score)).To(Succeed())
+
+			Expect(score.Score).NotTo(BeNil())
+			Expect(score.Signals).To(HaveLen(4))
+			Expect(score.Signals).To(HaveEach(HaveField("Available", true)))
+			// Neither trust anchor nor issuer is installed
+			Expect(score.TopIssues).To(ContainElement(HaveField("Tool", "check_certificates")))
+		})
+	})
+
+	Describe("Issues", func() {
+		It("should flag an unknown control plane", func() {
+			issues := overview.Issues(overview.MeshOverview{})
+			Expect(issues).To(ConsistOf("control plane health is unknown"))
+		})
+
+		It("should report nothing for a healthy mesh", func() {
+			issues := overview.Issues(overview.MeshOverview{
+				ControlPlane: &overview.ControlPlaneOverview{Namespace: "linkerd", TotalPods: 3, HealthyPods: 3},
+				DataPlane:    &overview.DataPlaneOverview{TotalProxies: 4, HealthyProxies: 4, ProxyVersions: map[string]int{"stable-2.14.0": 4}},
+				Validation:   &overview.ValidationOverview{},
+			})
+			Expect(issues).To(BeEmpty())
+		})
+	})
+
+	Describe("BuildMetricsOverview", func() {
+		It("should list services without full mTLS and the least covered one", func() {
+			metrics := overview.BuildMetricsOverview("5m", map[string]float64{"api": 100, "web": 80, "worker": 50})
+
+			Expect(metrics.ServicesWithTraffic).To(Equal(3))
+			Expect(metrics.ServicesWithoutMTLS).To(Equal([]string{"web", "worker"}))
+			Expect(*metrics.LowestMTLSCoverage).To(Equal(50.0))
+			Expect(metrics.LowestMTLSCoverageName).To(Equal("worker"))
+		})
+
+		It("should report no coverage without traffic", func() {
+			metrics := overview.BuildMetricsOverview("5m", map[string]float64{})
+			Expect(metrics.LowestMTLSCoverage).To(BeNil())
+			Expect(metrics.ServicesWithoutMTLS).To(BeEmpty())
+		})
+	})
+})
@@ -0,0 +1,275 @@
+package overview
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/christianhuening/linkerd-mcp/internal/health"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Weights of the signals of the cluster health score. A signal that can't be collected is left
+// out and the weights of the others are scaled up to keep the total at 100.
+const (
+	WeightControlPlane = 35
+	WeightCertificates = 25
+	WeightValidation   = 20
+	WeightDataPlane    = 20
+)
+
+// SectionCertificates names the certificate signal of the health score
+const SectionCertificates = "certificates"
+
+// ValidationErrorPenalty is what each validation error costs the validation signal, in points
+const ValidationErrorPenalty = 10
+
+// maxTopIssues caps the issues listed by the health score
+const maxTopIssues = 5
+
+// HealthScore is the aggregate health of the cluster: a 0-100 score, its letter grade and the
+// issues that cost the most points. Score and grade are null when no signal is available.
+type HealthScore struct {
+	Score     *int               `json:"score"`
+	Grade     string             `json:"grade,omitempty"`
+	Signals   []HealthSignal     `json:"signals"`
+	TopIssues []HealthScoreIssue `json:"topIssues"`
+}
+
+// HealthSignal is the score of one signal and how much it weighs in the total
+type HealthSignal struct {
+	Name            string  `json:"name"`
+	Tool            string  `json:"tool"` // the tool with the details
+	Weight          int     `json:"weight"`
+	EffectiveWeight float64 `json:"effectiveWeight"` // after scaling for unavailable signals
+	Available       bool    `json:"available"`
+	Score           *int    `json:"score,omitempty"`
+	Reason          string  `json:"reason,omitempty"` // why the signal is unavailable
+}
+
+// HealthScoreIssue is a problem that lowered the score. Impact is the points it cost.
+type HealthScoreIssue struct {
+	Signal  string  `json:"signal"`
+	Tool    string  `json:"tool"`
+	Message string  `json:"message"`
+	Impact  float64 `json:"impact"`
+}
+
+// HealthSignals are the inputs of the health score. A nil signal is unavailable, for the reason
+// in Unavailable. The data plane signal is collected only when the cluster has meshed proxies.
+type HealthSignals struct {
+	ControlPlane *ControlPlaneOverview
+	Certificates *health.CertificateReport
+	Validation   *ValidationOverview
+	DataPlane    *DataPlaneOverview
+	Unavailable  map[string]string
+}
+
+// GetHealthScore computes the cluster health score
+func (b *Builder) GetHealthScore(ctx context.Context) (*mcp.CallToolResult, error) {
+	score := ScoreHealth(b.healthSignals(ctx))
+
+	data, err := json.Marshal(score)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal health score: %v", err)), nil
+	}
+	return mcp.NewToolResultText(string(data)), nil
+}
+
+func (b *Builder) healthSignals(ctx context.Context) HealthSignals {
+	signals := HealthSignals{Unavailable: map[string]string{}}
+	var err error
+	if signals.ControlPlane, err = b.controlPlane(ctx); err != nil {
+		signals.Unavailable[SectionControlPlane] = err.Error()
+	}
+	if signals.Certificates, err = b.certificates(ctx); err != nil {
+		signals.Unavailable[SectionCertificates] = err.Error()
+	}
+	if signals.Validation, err = b.validation(ctx); err != nil {
+		signals.Unavailable[SectionValidation] = err.Error()
+	}
+	switch dataPlane, err := b.dataPlane(ctx); {
+	case err != nil:
+		signals.Unavailable[SectionDataPlane] = err.Error()
+	case dataPlane.TotalProxies == 0:
+		signals.Unavailable[SectionDataPlane] = "no meshed proxies"
+	default:
+		signals.DataPlane = dataPlane
+	}
+	return signals
+}
+
+func (b *Builder) certificates(ctx context.Context) (*health.CertificateReport, error) {
+	var report health.CertificateReport
+	result, err := b.healthChecker.CheckCertificates(ctx, 0)
+	if err := decodeResult(result, err, &report); err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
+
+// ScoreHealth combines the signals into the health score: each signal scores 0-100, and the
+// total is their average weighted by WeightControlPlane, WeightCertificates, WeightValidation
+// and WeightDataPlane over the available signals.
+//   - control plane: the share of healthy pods, degraded pods counting half; 0 without pods
+//   - certificates: 0 with an error, such as an expired or missing certificate; otherwise the
+//     share of the warning window left to the certificate expiring first, 100 when none is
+//   - validation: 100 less ValidationErrorPenalty per error; warnings don't count
+//   - data plane: the share of healthy proxies
+func ScoreHealth(signals HealthSignals) HealthScore {
+	type scored struct {
+		signal HealthSignal
+		score  float64
+		issues []string
+	}
+	all := []scored{}
+	add := func(name, tool string, weight int, available bool, compute func() (float64, []string)) {
+		s := scored{signal: HealthSignal{Name: name, Tool: tool, Weight: weight, Available: available}}
+		switch {
+		case available:
+			s.score, s.issues = compute()
+		case signals.Unavailable[name] != "":
+			s.signal.Reason = signals.Unavailable[name]
+		default:
+			s.signal.Reason = "not collected"
+		}
+		all = append(all, s)
+	}
+
+	add(SectionControlPlane, "check_mesh_health", WeightControlPlane, signals.ControlPlane != nil, func() (float64, []string) {
+		return scoreControlPlane(signals.ControlPlane)
+	})
+	add(SectionCertificates, "check_certificates", WeightCertificates, signals.Certificates != nil, func() (float64, []string) {
+		return scoreCertificates(signals.Certificates)
+	})
+	add(SectionValidation, "validate_mesh_config", WeightValidation, signals.Validation != nil, func() (float64, []string) {
+		return scoreValidation(signals.Validation)
+	})
+	add(SectionDataPlane, "check_data_plane_health", WeightDataPlane, signals.DataPlane != nil, func() (float64, []string) {
+		return scoreDataPlane(signals.DataPlane)
+	})
+
+	availableWeight := 0
+	for _, s := range all {
+		if s.signal.Available {
+			availableWeight += s.signal.Weight
+		}
+	}
+
+	result := HealthScore{Signals: []HealthSignal{}, TopIssues: []HealthScoreIssue{}}
+	total := 0.0
+	for _, s := range all {
+		if s.signal.Available {
+			s.signal.EffectiveWeight = roundTo(float64(s.signal.Weight)*100/float64(availableWeight), 1)
+			score := int(math.Round(s.score))
+			s.signal.Score = &score
+			total += s.score * float64(s.signal.Weight) / float64(availableWeight)
+
+			// The points a signal lost are shared between its issues
+			lost := (100 - s.score) * float64(s.signal.Weight) / float64(availableWeight)
+			for _, message := range s.issues {
+				result.TopIssues = append(result.TopIssues, HealthScoreIssue{
+					Signal:  s.signal.Name,
+					Tool:    s.signal.Tool,
+					Message: message,
+					Impact:  roundTo(lost/float64(len(s.issues)), 1),
+				})
+			}
+		}
+		result.Signals = append(result.Signals, s.signal)
+	}
+
+	sort.SliceStable(result.TopIssues, func(i, j int) bool {
+		return result.TopIssues[i].Impact > result.TopIssues[j].Impact
+	})
+	if len(result.TopIssues) > maxTopIssues {
+		result.TopIssues = result.TopIssues[:maxTopIssues]
+	}
+
+	if availableWeight > 0 {
+		score := int(math.Round(total))
+		result.Score = &score
+		result.Grade = Grade(score)
+	}
+	return result
+}
+
+// Grade maps a score to a letter: A from 90, B from 80, C from 70, D from 60, F below
+func Grade(score int) string {
+	switch {
+	case score >= 90:
+		return "A"
+	case score >= 80:
+		return "B"
+	case score >= 70:
+		return "C"
+	case score >= 60:
+		return "D"
+	default:
+		return "F"
+	}
+}
+
+func scoreControlPlane(cp *ControlPlaneOverview) (float64, []string) {
+	if cp.TotalPods == 0 {
+		return 0, []string{fmt.Sprintf("no control plane pods found in namespace %s", cp.Namespace)}
+	}
+	score := (float64(cp.HealthyPods) + float64(cp.DegradedPods)/2) / float64(cp.TotalPods) * 100
+	if cp.UnhealthyPods == 0 && cp.DegradedPods == 0 {
+		return score, nil
+	}
+	return score, []string{fmt.Sprintf("control plane has %d unhealthy and %d degraded pods", cp.UnhealthyPods, cp.DegradedPods)}
+}
+
+func scoreCertificates(report *health.CertificateReport) (float64, []string) {
+	errors, warnings := []string{}, []string{}
+	for _, issue := range report.Issues {
+		if issue.Severity == "error" {
+			errors = append(errors, issue.Message)
+		} else {
+			warnings = append(warnings, issue.Message)
+		}
+	}
+	if !report.Healthy {
+		return 0, errors
+	}
+
+	certificates := append([]health.CertificateInfo{}, report.TrustAnchors...)
+	if report.Issuer != nil {
+		certificates = append(certificates, *report.Issuer)
+	}
+	windowDays := health.DefaultCertificateExpiryWarning.Hours() / 24
+	score := 100.0
+	for _, certificate := range certificates {
+		if certificate.Expiring {
+			score = math.Min(score, math.Max(0, float64(certificate.DaysRemaining))/windowDays*100)
+		}
+	}
+	return score, warnings
+}
+
+func scoreValidation(validation *ValidationOverview) (float64, []string) {
+	if validation.Errors == 0 {
+		return 100, nil
+	}
+	score := math.Max(0, float64(100-ValidationErrorPenalty*validation.Errors))
+	return score, []string{fmt.Sprintf("configuration validation found %d errors", validation.Errors)}
+}
+
+func scoreDataPlane(dp *DataPlaneOverview) (float64, []string) {
+	if dp.TotalProxies == 0 {
+		return 100, nil
+	}
+	score := float64(dp.HealthyProxies) / float64(dp.TotalProxies) * 100
+	if dp.UnhealthyProxies == 0 {
+		return score, nil
+	}
+	return score, []string{fmt.Sprintf("%d of %d proxies are unhealthy", dp.UnhealthyProxies, dp.TotalProxies)}
+}
+
+func roundTo(value float64, decimals int) float64 {
+	factor := math.Pow(10, float64(decimals))
+	return math.Round(value*factor) / factor
+}
@@ -0,0 +1,129 @@
+package overview_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/christianhuening/linkerd-mcp/internal/health"
+	"github.com/christianhuening/linkerd-mcp/internal/overview"
+)
+
+var _ = Describe("ScoreHealth", func() {
+	var signals overview.HealthSignals
+
+	BeforeEach(func() {
+		signals = overview.HealthSignals{
+			ControlPlane: &overview.ControlPlaneOverview{Namespace: "linkerd", TotalPods: 4, HealthyPods: 4},
+			Certificates: &health.CertificateReport{Healthy: true, TrustAnchors: []health.CertificateInfo{{DaysRemaining: 300}}},
+			Validation:   &overview.ValidationOverview{Warnings: 3},
+			DataPlane:    &overview.DataPlaneOverview{TotalProxies: 10, HealthyProxies: 10},
+			Unavailable:  map[string]string{},
+		}
+	})
+
+	signal := func(score overview.HealthScore, name string) overview.HealthSignal {
+		for _, s := range score.Signals {
+			if s.Name == name {
+				return s
+			}
+		}
+		Fail("no signal " + name)
+		return overview.HealthSignal{}
+	}
+
+	It("should score a healthy cluster 100", func() {
+		score := overview.ScoreHealth(signals)
+
+		Expect(*score.Score).To(Equal(100))
+		Expect(score.Grade).To(Equal("A"))
+		Expect(score.TopIssues).To(BeEmpty())
+		Expect(score.Signals).To(HaveLen(4))
+		Expect(signal(score, overview.SectionControlPlane).EffectiveWeight).To(Equal(float64(overview.WeightControlPlane)))
+	})
+
+	It("should weigh each signal and rank the issues by the points they cost", func() {
+		signals.ControlPlane.HealthyPods = 2
+		signals.ControlPlane.UnhealthyPods = 2
+		signals.Validation.Errors = 3
+		signals.DataPlane.HealthyProxies = 9
+		signals.DataPlane.UnhealthyProxies = 1
+
+		score := overview.ScoreHealth(signals)
+
+		// 35*0.5 + 25 + 20*0.7 + 20*0.9
+		Expect(*score.Score).To(Equal(75))
+		Expect(score.Grade).To(Equal("C"))
+		Expect(score.TopIssues).To(HaveLen(3))
+		Expect(score.TopIssues[0].Signal).To(Equal(overview.SectionControlPlane))
+		Expect(score.TopIssues[0].Tool).To(Equal("check_mesh_health"))
+		Expect(score.TopIssues[0].Impact).To(Equal(17.5))
+		Expect(score.TopIssues[1].Message).To(Equal("configuration validation found 3 errors"))
+		Expect(score.TopIssues[2].Impact).To(Equal(2.0))
+	})
+
+	It("should count degraded control plane pods half", func() {
+		signals.ControlPlane.HealthyPods = 2
+		signals.ControlPlane.DegradedPods = 2
+
+		Expect(*signal(overview.ScoreHealth(signals), overview.SectionControlPlane).Score).To(Equal(75))
+	})
+
+	It("should score an invalid certificate chain 0 and an expiring one by the time left", func() {
+		signals.Certificates = &health.CertificateReport{
+			Issues: []health.CertificateIssue{{Problem: health.CertificateProblemIssuerExpired, Severity: "error", Message: "issuer expired"}},
+		}
+		score := overview.ScoreHealth(signals)
+		Expect(*signal(score, overview.SectionCertificates).Score).To(Equal(0))
+		Expect(score.TopIssues).To(ConsistOf(HaveField("Message", "issuer expired")))
+
+		signals.Certificates = &health.CertificateReport{
+			Healthy:      true,
+			TrustAnchors: []health.CertificateInfo{{DaysRemaining: 300}},
+			Issuer:       &health.CertificateInfo{DaysRemaining: 6, Expiring: true},
+			Issues:       []health.CertificateIssue{{Problem: health.CertificateProblemIssuerExpiring, Severity: "warning", Message: "issuer expires in 6 days"}},
+		}
+		Expect(*signal(overview.ScoreHealth(signals), overview.SectionCertificates).Score).To(Equal(20))
+	})
+
+	It("should floor the validation signal at 0", func() {
+		signals.Validation.Errors = 25
+
+		Expect(*signal(overview.ScoreHealth(signals), overview.SectionValidation).Score).To(Equal(0))
+	})
+
+	It("should leave unavailable signals out and scale the others up", func() {
+		signals.DataPlane = nil
+		signals.Unavailable[overview.SectionDataPlane] = "no meshed proxies"
+		signals.Validation.Errors = 5
+
+		score := overview.ScoreHealth(signals)
+
+		dataPlane := signal(score, overview.SectionDataPlane)
+		Expect(dataPlane.Available).To(BeFalse())
+		Expect(dataPlane.Score).To(BeNil())
+		Expect(dataPlane.Reason).To(Equal("no meshed proxies"))
+		Expect(signal(score, overview.SectionValidation).EffectiveWeight).To(Equal(25.0))
+		// 100 - 50% of the validation weight of 25
+		Expect(*score.Score).To(Equal(88))
+		Expect(score.Grade).To(Equal("B"))
+	})
+
+	It("should have no score without any signal", func() {
+		score := overview.ScoreHealth(overview.HealthSignals{})
+
+		Expect(score.Score).To(BeNil())
+		Expect(score.Grade).To(BeEmpty())
+		Expect(score.Signals).To(HaveEach(HaveField("Available", false)))
+	})
+})
+
+var _ = Describe("Grade", func() {
+	It("should map scores to letters", func() {
+		Expect(overview.Grade(100)).To(Equal("A"))
+		Expect(overview.Grade(90)).To(Equal("A"))
+		Expect(overview.Grade(89)).To(Equal("B"))
+		Expect(overview.Grade(70)).To(Equal("C"))
+		Expect(overview.Grade(60)).To(Equal("D"))
+		Expect(overview.Grade(59)).To(Equal("F"))
+	})
+})
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 
+	"github.com/christianhuening/linkerd-mcp/internal/kube"
 	"github.com/mark3labs/mcp-go/mcp"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
@@ -12,82 +13,240 @@ import (
 
 // Analyzer provides Linkerd policy analysis functionality
 type Analyzer struct {
-	clientset     kubernetes.Interface
-	dynamicClient dynamic.Interface
+	clientset             kubernetes.Interface
+	dynamicClient         dynamic.Interface
+	controlPlaneNamespace string
+	namespaceFilter       *kube.NamespaceFilter
 }
 
 // NewAnalyzer creates a new policy analyzer
 func NewAnalyzer(clientset kubernetes.Interface, dynamicClient dynamic.Interface) *Analyzer {
 	return &Analyzer{
-		clientset:     clientset,
-		dynamicClient: dynamicClient,
+		clientset:             clientset,
+		dynamicClient:         dynamicClient,
+		controlPlaneNamespace: defaultControlPlaneNamespace,
 	}
 }
 
-// AnalyzeConnectivity analyzes connectivity policies between source and target services
+// SetControlPlaneNamespace sets the namespace of the Linkerd control plane, whose
+// linkerd-config holds the cluster default inbound policy. Empty values are ignored.
+func (a *Analyzer) SetControlPlaneNamespace(namespace string) {
+	if namespace != "" {
+		a.controlPlaneNamespace = namespace
+	}
+}
+
+// SetNamespaceFilter restricts the namespaces whose resources are listed when analyzing the whole
+// cluster. Resources of a namespace given explicitly are always listed.
+func (a *Analyzer) SetNamespaceFilter(filter *kube.NamespaceFilter) {
+	a.namespaceFilter = filter
+}
+
+// AnalyzeConnectivity analyzes connectivity policies between source and target services.
+// When no Server selects the target, the verdict follows the default inbound policy resolved
+// from the target's pod or namespace annotation, or the cluster configuration. Sources no
+// AuthorizationPolicy authorizes fall back to the accessPolicy of the selecting Servers.
 func (a *Analyzer) AnalyzeConnectivity(ctx context.Context, sourceNamespace, sourceService, targetNamespace, targetService string) (*mcp.CallToolResult, error) {
 	if targetNamespace == "" {
 		targetNamespace = sourceNamespace
 	}
+	ctx = withLookupCache(ctx)
+
+	source := map[string]interface{}{
+		"namespace": sourceNamespace,
+		"service":   sourceService,
+	}
+	serviceAccount := ""
+	sourceMeshed := false
+	sourcePod, sourceErr := a.findSourcePod(ctx, sourceNamespace, sourceService)
+	if sourceErr == nil {
+		serviceAccount = podServiceAccount(sourcePod)
+		sourceMeshed = isMeshedPod(*sourcePod)
+		source["serviceAccount"] = serviceAccount
+		source["meshed"] = sourceMeshed
+	}
+
+	return a.connectivityVerdict(ctx, source, sourceNamespace, serviceAccount, sourceMeshed, sourceErr, targetNamespace, targetService)
+}
+
+// SimulateAccess decides whether a service account would be allowed to reach a target service,
+// without requiring a source pod. The source is assumed to be meshed, since only meshed clients
+// carry a service account identity; the verdict is otherwise the one AnalyzeConnectivity gives.
+func (a *Analyzer) SimulateAccess(ctx context.Context, sourceNamespace, sourceServiceAccount, targetNamespace, targetService string) (*mcp.CallToolResult, error) {
+	if targetNamespace == "" {
+		targetNamespace = sourceNamespace
+	}
+	if sourceServiceAccount == "" {
+		sourceServiceAccount = "default"
+	}
+	ctx = withLookupCache(ctx)
+
+	source := map[string]interface{}{
+		"namespace":      sourceNamespace,
+		"serviceAccount": sourceServiceAccount,
+		"meshed":         true,
+		"simulated":      true,
+	}
+
+	return a.connectivityVerdict(ctx, source, sourceNamespace, sourceServiceAccount, true, nil, targetNamespace, targetService)
+}
+
+// connectivityVerdict explains whether the source identity may reach the target service.
+// sourceErr is set when the identity of the source couldn't be resolved.
+func (a *Analyzer) connectivityVerdict(ctx context.Context, source map[string]interface{}, sourceNamespace, serviceAccount string, sourceMeshed bool, sourceErr error, targetNamespace, targetService string) (*mcp.CallToolResult, error) {
+	matchingServers, err := a.findServersForService(ctx, targetNamespace, targetService)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
 
-	// Query Linkerd policies (ServerAuthorizations, AuthorizationPolicies)
-	// This would integrate with Linkerd's policy CRDs
 	analysis := map[string]interface{}{
-		"source": map[string]string{
-			"namespace": sourceNamespace,
-			"service":   sourceService,
-		},
+		"source": source,
 		"target": map[string]string{
 			"namespace": targetNamespace,
 			"service":   targetService,
 		},
-		"allowed":      true,
-		"policies":     []string{},
-		"explanation":  "Policy analysis implementation pending - requires Linkerd CRD integration",
+		"matchingServers": matchingServers,
+	}
+
+	if len(matchingServers) == 0 {
+		// Without a Server, no policy restricts the target and the default inbound policy decides
+		defaultPolicy, policySource := a.resolveDefaultInboundPolicy(ctx, targetNamespace, targetService)
+		allowed, reason := defaultPolicyVerdict(defaultPolicy, sourceMeshed)
+
+		analysis["allowed"] = allowed
+		analysis["policies"] = []string{}
+		analysis["verdictBasis"] = verdictBasisDefaultInboundPolicy
+		analysis["auditMode"] = defaultPolicy == auditInboundPolicy
+		analysis["defaultInboundPolicy"] = map[string]string{
+			"policy": defaultPolicy,
+			"source": policySource,
+		}
+		analysis["explanation"] = fmt.Sprintf("No Server selects %s/%s, so the verdict derives from the %s default inbound policy %q: %s",
+			targetNamespace, targetService, policySource, defaultPolicy, reason)
+	} else {
+		policies := []string{}
+		if sourceErr == nil {
+			policies = a.authorizingPolicies(ctx, targetNamespace, matchingServers, sourceNamespace, serviceAccount)
+		}
+
+		analysis["allowed"] = len(policies) > 0
+		analysis["policies"] = policies
+		analysis["verdictBasis"] = verdictBasisAuthorizationPolicy
+		analysis["auditMode"] = false
+		analysis["explanation"] = connectivityExplanation(policies, matchingServers, sourceErr)
+
+		// Traffic no policy authorizes falls back to the Server's accessPolicy, deny by default
+		if len(policies) == 0 {
+			for _, serverName := range matchingServers {
+				accessPolicy := a.serverAccessPolicy(ctx, targetNamespace, serverName)
+				allowed, reason := defaultPolicyVerdict(accessPolicy, sourceMeshed)
+				if accessPolicy == denyInboundPolicy || !allowed {
+					continue
+				}
+
+				analysis["allowed"] = true
+				analysis["verdictBasis"] = verdictBasisServerAccessPolicy
+				analysis["auditMode"] = accessPolicy == auditInboundPolicy
+				analysis["serverAccessPolicy"] = map[string]string{
+					"server": serverName,
+					"policy": accessPolicy,
+				}
+				analysis["explanation"] = fmt.Sprintf("No AuthorizationPolicy authorizes the source, but Server %s has the access policy %q: %s",
+					serverName, accessPolicy, reason)
+				break
+			}
+		}
 	}
 
 	result, _ := json.MarshalIndent(analysis, "", "  ")
 	return mcp.NewToolResultText(string(result)), nil
 }
 
-// GetAllowedTargets finds all services that a given source service can communicate with
-func (a *Analyzer) GetAllowedTargets(ctx context.Context, sourceNamespace, sourceService string) (*mcp.CallToolResult, error) {
-	serviceAccount, err := a.getServiceAccountForService(ctx, sourceNamespace, sourceService)
+// GetAllowedTargets finds all services that a given source service can communicate with.
+// When explain is set, the result also lists the Servers the source can't reach and why. A
+// source without pods is analyzed with the service account of its pod template, or the
+// default service account, and the result notes it.
+func (a *Analyzer) GetAllowedTargets(ctx context.Context, sourceNamespace, sourceService string, explain bool) (*mcp.CallToolResult, error) {
+	ctx = withLookupCache(ctx)
+
+	serviceAccount, serviceAccountNote, err := a.getServiceAccountForService(ctx, sourceNamespace, sourceService)
 	if err != nil {
 		return mcp.NewToolResultError(err.Error()), nil
 	}
 
-	allowedTargets, err := a.findAllowedTargets(ctx, sourceNamespace, serviceAccount)
+	sourceIPs := a.sourcePodIPs(ctx, sourceNamespace, sourceService)
+	allowedTargets, deniedTargets, err := a.findAllowedTargets(ctx, sourceNamespace, serviceAccount, sourceIPs, explain)
 	if err != nil {
 		return mcp.NewToolResultError(err.Error()), nil
 	}
 
+	source := map[string]string{
+		"namespace":      sourceNamespace,
+		"service":        sourceService,
+		"serviceAccount": serviceAccount,
+	}
+	if serviceAccountNote != "" {
+		source["note"] = serviceAccountNote
+	}
+
 	result := map[string]interface{}{
-		"source": map[string]string{
-			"namespace":      sourceNamespace,
-			"service":        sourceService,
-			"serviceAccount": serviceAccount,
-		},
+		"source":         source,
 		"allowedTargets": allowedTargets,
 		"totalTargets":   len(allowedTargets),
 	}
+	if explain {
+		result["deniedTargets"] = deniedTargets
+	}
 
 	resultJSON, _ := json.MarshalIndent(result, "", "  ")
 	return mcp.NewToolResultText(string(resultJSON)), nil
 }
 
-// GetAllowedSources finds all services that can communicate with a given target service
-func (a *Analyzer) GetAllowedSources(ctx context.Context, targetNamespace, targetService string) (*mcp.CallToolResult, error) {
+// GetAllowedSources finds all services that can communicate with a given target service.
+// When no Server selects the target, the sources admitted by its default inbound policy are
+// reported instead. Otherwise sources are also grouped by the port each Server governs, next to
+// the sources admitted on any port. When explain is set, the result also lists why matching
+// Servers grant nothing, fully or in part.
+func (a *Analyzer) GetAllowedSources(ctx context.Context, targetNamespace, targetService string, explain bool) (*mcp.CallToolResult, error) {
+	ctx = withLookupCache(ctx)
+
 	matchingServers, err := a.findServersForService(ctx, targetNamespace, targetService)
 	if err != nil {
 		return mcp.NewToolResultError(err.Error()), nil
 	}
 
 	if len(matchingServers) == 0 {
-		return mcp.NewToolResultText(fmt.Sprintf("No Linkerd Servers found for service %s in namespace %s", targetService, targetNamespace)), nil
+		// Without a Server, the default inbound policy decides which clients are admitted
+		defaultPolicy, policySource := a.resolveDefaultInboundPolicy(ctx, targetNamespace, targetService)
+		allowedSources := defaultPolicySources(defaultPolicy)
+
+		result := map[string]interface{}{
+			"target": map[string]string{
+				"namespace": targetNamespace,
+				"service":   targetService,
+			},
+			"matchingServers": matchingServers,
+			"verdictBasis":    verdictBasisDefaultInboundPolicy,
+			"auditMode":       defaultPolicy == auditInboundPolicy,
+			"defaultInboundPolicy": map[string]string{
+				"policy": defaultPolicy,
+				"source": policySource,
+			},
+			"allowedSources": allowedSources,
+			"totalSources":   len(allowedSources),
+		}
+
+		resultJSON, _ := json.MarshalIndent(result, "", "  ")
+		return mcp.NewToolResultText(string(resultJSON)), nil
 	}
 
-	allowedSources, err := a.findAllowedSources(ctx, targetNamespace, matchingServers)
+	allowedSources, deniedSources, auditMode, err := a.serverSources(ctx, targetNamespace, matchingServers, explain)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	servers := a.serverPorts(ctx, targetNamespace, matchingServers)
+	ports, err := a.portSources(ctx, targetNamespace, servers, explain)
 	if err != nil {
 		return mcp.NewToolResultError(err.Error()), nil
 	}
@@ -97,10 +256,16 @@ func (a *Analyzer) GetAllowedSources(ctx context.Context, targetNamespace, targe
 			"namespace": targetNamespace,
 			"service":   targetService,
 		},
-		"matchingServers": matchingServers,
+		"matchingServers": servers,
+		"verdictBasis":    verdictBasisAuthorizationPolicy,
+		"auditMode":       auditMode,
+		"ports":           ports,
 		"allowedSources":  allowedSources,
 		"totalSources":    len(allowedSources),
 	}
+	if explain {
+		result["deniedSources"] = deniedSources
+	}
 
 	resultJSON, _ := json.MarshalIndent(result, "", "  ")
 	return mcp.NewToolResultText(string(resultJSON)), nil
@@ -8,6 +8,8 @@ import (
 
 	"github.com/christianhuening/linkerd-mcp/internal/policy"
 	"github.com/christianhuening/linkerd-mcp/internal/testutil"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -53,6 +55,7 @@ var _ = Describe("Analyzer", func() {
 			{Group: "policy.linkerd.io", Version: "v1alpha1", Resource: "meshtlsauthentications"}: "MeshTLSAuthenticationList",
 			{Group: "policy.linkerd.io", Version: "v1alpha1", Resource: "networkauthentications"}: "NetworkAuthenticationList",
 			{Group: "policy.linkerd.io", Version: "v1alpha1", Resource: "httproutes"}:             "HTTPRouteList",
+			{Group: "policy.linkerd.io", Version: "v1beta1", Resource: "serverauthorizations"}:    "ServerAuthorizationList",
 		}
 
 		kubeClient = kubefake.NewSimpleClientset()
@@ -101,15 +104,62 @@ var _ = Describe("Analyzer", func() {
 
 	Describe("GetAllowedTargets", func() {
 		Context("when no pods are found", func() {
-			It("should return an error result", func() {
-				result, err := analyzer.GetAllowedTargets(ctx, "prod", "nonexistent")
+			It("should assume the default service account and say so", func() {
+				result, err := analyzer.GetAllowedTargets(ctx, "prod", "nonexistent", false)
 				Expect(err).NotTo(HaveOccurred())
-				Expect(result.IsError).To(BeTrue())
+				Expect(result.IsError).To(BeFalse())
 
-				var textContent string
-				err = testutil.GetTextFromResult(result, &textContent)
+				var response map[string]interface{}
+				err = testutil.ParseJSONResult(result, &response)
+				Expect(err).NotTo(HaveOccurred())
+
+				source := response["source"].(map[string]interface{})
+				Expect(source["serviceAccount"]).To(Equal("default"))
+				Expect(source["note"]).To(ContainSubstring("No pods or pod template found for service nonexistent"))
+			})
+
+			It("should read the service account from the Deployment pod template", func() {
+				deployment := testutil.CreateDeployment("frontend", "prod", map[string]string{"app": "frontend"}, nil)
+				deployment.Spec.Replicas = new(int32)
+				deployment.Spec.Template.Spec.ServiceAccountName = "frontend-sa"
+				_, err := kubeClient.AppsV1().Deployments("prod").Create(ctx, deployment, metav1.CreateOptions{})
+				Expect(err).NotTo(HaveOccurred())
+
+				result, err := analyzer.GetAllowedTargets(ctx, "prod", "frontend", false)
+				Expect(err).NotTo(HaveOccurred())
+
+				var response map[string]interface{}
+				err = testutil.ParseJSONResult(result, &response)
+				Expect(err).NotTo(HaveOccurred())
+
+				source := response["source"].(map[string]interface{})
+				Expect(source["serviceAccount"]).To(Equal("frontend-sa"))
+				Expect(source["note"]).To(ContainSubstring("pod template of Deployment frontend"))
+			})
+
+			It("should fall back to a ReplicaSet pod template", func() {
+				replicaSet := &appsv1.ReplicaSet{
+					ObjectMeta: metav1.ObjectMeta{Name: "frontend-7d9f", Namespace: "prod"},
+					Spec: appsv1.ReplicaSetSpec{
+						Template: corev1.PodTemplateSpec{
+							ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": "frontend"}},
+							Spec:       corev1.PodSpec{ServiceAccountName: "frontend-sa"},
+						},
+					},
+				}
+				_, err := kubeClient.AppsV1().ReplicaSets("prod").Create(ctx, replicaSet, metav1.CreateOptions{})
+				Expect(err).NotTo(HaveOccurred())
+
+				result, err := analyzer.GetAllowedTargets(ctx, "prod", "frontend", false)
+				Expect(err).NotTo(HaveOccurred())
+
+				var response map[string]interface{}
+				err = testutil.ParseJSONResult(result, &response)
 				Expect(err).NotTo(HaveOccurred())
-				Expect(textContent).To(Equal("no pods found for service nonexistent in namespace prod"))
+
+				source := response["source"].(map[string]interface{})
+				Expect(source["serviceAccount"]).To(Equal("frontend-sa"))
+				Expect(source["note"]).To(ContainSubstring("ReplicaSet frontend-7d9f"))
 			})
 		})
 
@@ -147,7 +197,7 @@ var _ = Describe("Analyzer", func() {
 			})
 
 			It("should return allowed targets for the source service", func() {
-				result, err := analyzer.GetAllowedTargets(ctx, "prod", "frontend")
+				result, err := analyzer.GetAllowedTargets(ctx, "prod", "frontend", false)
 				Expect(err).NotTo(HaveOccurred())
 
 				var response map[string]interface{}
@@ -162,18 +212,80 @@ var _ = Describe("Analyzer", func() {
 				Expect(totalTargets).To(Equal(len(allowedTargets)))
 			})
 		})
+
+		Context("with a NetworkAuthentication", func() {
+			networkAuthGVR := schema.GroupVersionResource{Group: "policy.linkerd.io", Version: "v1alpha1", Resource: "networkauthentications"}
+
+			// allowedTargets returns the allowed targets, and the denied ones, of frontend running on podIP
+			allowedTargets := func(podIP string) ([]interface{}, []interface{}) {
+				pod := testutil.CreatePod("frontend-1", "prod", "frontend-sa", map[string]string{"app": "frontend"}, "Running", true)
+				pod.Status.PodIP = podIP
+				_, err := kubeClient.CoreV1().Pods("prod").Create(ctx, pod, metav1.CreateOptions{})
+				Expect(err).NotTo(HaveOccurred())
+
+				result, err := analyzer.GetAllowedTargets(ctx, "prod", "frontend", true)
+				Expect(err).NotTo(HaveOccurred())
+
+				var response map[string]interface{}
+				Expect(testutil.ParseJSONResult(result, &response)).To(Succeed())
+				return response["allowedTargets"].([]interface{}), response["deniedTargets"].([]interface{})
+			}
+
+			BeforeEach(func() {
+				server := testutil.CreateServer("backend-server", "prod", map[string]string{"app": "backend"}, 8080)
+				_, err := dynamicClient.Resource(serverGVR).Namespace("prod").Create(ctx, server, metav1.CreateOptions{})
+				Expect(err).NotTo(HaveOccurred())
+
+				authPolicy := testutil.CreateAuthorizationPolicy("allow-cluster-network", "prod", "backend-server",
+					[]map[string]string{{"name": "cluster-network", "kind": "NetworkAuthentication"}})
+				_, err = dynamicClient.Resource(authPolicyGVR).Namespace("prod").Create(ctx, authPolicy, metav1.CreateOptions{})
+				Expect(err).NotTo(HaveOccurred())
+
+				networkAuth := testutil.CreateNetworkAuthentication("cluster-network", "prod", []map[string]interface{}{
+					{"cidr": "10.0.0.0/16", "except": []interface{}{"10.0.99.0/24"}},
+				})
+				_, err = dynamicClient.Resource(networkAuthGVR).Namespace("prod").Create(ctx, networkAuth, metav1.CreateOptions{})
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("should allow targets when the source pod IP is in an authenticated network", func() {
+				allowed, _ := allowedTargets("10.0.3.7")
+				Expect(allowed).To(ConsistOf(HaveKeyWithValue("server", "backend-server")))
+			})
+
+			It("should deny targets when the source pod IP is excepted from the network", func() {
+				allowed, denied := allowedTargets("10.0.99.4")
+				Expect(allowed).To(BeEmpty())
+				Expect(denied).To(HaveLen(1))
+				reasons := denied[0].(map[string]interface{})["reasons"].([]interface{})
+				Expect(reasons).To(ConsistOf(HaveKeyWithValue("reason", "NetworkNotAuthorized")))
+			})
+
+			It("should deny targets when the source pod IP is outside the networks", func() {
+				allowed, _ := allowedTargets("192.168.1.5")
+				Expect(allowed).To(BeEmpty())
+			})
+		})
 	})
 
 	Describe("GetAllowedSources", func() {
 		Context("when no servers are found", func() {
-			It("should return a message about no servers", func() {
-				result, err := analyzer.GetAllowedSources(ctx, "prod", "backend")
+			It("should report the sources admitted by the default inbound policy", func() {
+				result, err := analyzer.GetAllowedSources(ctx, "prod", "backend", false)
 				Expect(err).NotTo(HaveOccurred())
 
-				var textContent string
-				err = testutil.GetTextFromResult(result, &textContent)
+				var response map[string]interface{}
+				err = testutil.ParseJSONResult(result, &response)
 				Expect(err).NotTo(HaveOccurred())
-				Expect(textContent).To(Equal("No Linkerd Servers found for service backend in namespace prod"))
+
+				Expect(response["matchingServers"]).To(BeEmpty())
+				Expect(response["verdictBasis"]).To(Equal("defaultInboundPolicy"))
+				Expect(response["defaultInboundPolicy"]).To(Equal(map[string]interface{}{
+					"policy": "all-unauthenticated",
+					"source": "cluster",
+				}))
+				Expect(response["totalSources"]).To(BeNumerically("==", 1))
+				Expect(response["allowedSources"]).To(ConsistOf(HaveKeyWithValue("policy", "all-unauthenticated")))
 			})
 		})
 
@@ -206,7 +318,7 @@ var _ = Describe("Analyzer", func() {
 			})
 
 			It("should return allowed sources including wildcard", func() {
-				result, err := analyzer.GetAllowedSources(ctx, "prod", "backend")
+				result, err := analyzer.GetAllowedSources(ctx, "prod", "backend", false)
 				Expect(err).NotTo(HaveOccurred())
 
 				var response map[string]interface{}
@@ -264,7 +376,7 @@ var _ = Describe("Analyzer", func() {
 			})
 
 			It("should return service accounts as allowed sources", func() {
-				result, err := analyzer.GetAllowedSources(ctx, "prod", "api")
+				result, err := analyzer.GetAllowedSources(ctx, "prod", "api", false)
 				Expect(err).NotTo(HaveOccurred())
 
 				var response map[string]interface{}
@@ -295,5 +407,40 @@ var _ = Describe("Analyzer", func() {
 				Expect(foundAdmin).To(BeTrue(), "should find admin-sa in allowed sources")
 			})
 		})
+
+		Context("when the Service selector differs from the app label", func() {
+			BeforeEach(func() {
+				_, err := kubeClient.CoreV1().Services("prod").Create(ctx,
+					testutil.CreateService("api", "prod", map[string]string{"app.kubernetes.io/name": "api"}), metav1.CreateOptions{})
+				Expect(err).NotTo(HaveOccurred())
+				_, err = kubeClient.CoreV1().Pods("prod").Create(ctx,
+					testutil.CreatePod("api-1", "prod", "api-sa", map[string]string{"app.kubernetes.io/name": "api", "tier": "backend"}, "Running", true), metav1.CreateOptions{})
+				Expect(err).NotTo(HaveOccurred())
+
+				for name, podLabels := range map[string]map[string]string{
+					"backend-server":  {"tier": "backend"},
+					"frontend-server": {"tier": "frontend"},
+					"legacy-server":   {"app": "api"},
+				} {
+					server := testutil.CreateServer(name, "prod", podLabels, 8080)
+					_, err = dynamicClient.Resource(serverGVR).Namespace("prod").Create(ctx, server, metav1.CreateOptions{})
+					Expect(err).NotTo(HaveOccurred())
+				}
+			})
+
+			It("should match Servers that select the Service's pods", func() {
+				result, err := analyzer.GetAllowedSources(ctx, "prod", "api", false)
+				Expect(err).NotTo(HaveOccurred())
+
+				var response map[string]interface{}
+				err = testutil.ParseJSONResult(result, &response)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(response["matchingServers"]).To(ConsistOf(map[string]interface{}{
+					"name": "backend-server",
+					"port": float64(8080),
+				}))
+			})
+		})
 	})
 })
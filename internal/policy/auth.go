@@ -3,14 +3,100 @@ package policy
 import (
 	"context"
 	"fmt"
+	"net"
 
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 )
 
-// checkSourceAllowed checks if a source is allowed by an authorization policy
-func (a *Analyzer) checkSourceAllowed(ctx context.Context, policy unstructured.Unstructured, serverNamespace, sourceNamespace, sourceServiceAccount string) bool {
+// policiesTargetingNamespace returns the AuthorizationPolicies that may govern Servers and
+// HTTPRoutes in a namespace. A policy may target a resource in another namespace, so these are
+// the policies of the namespace and those of any namespace whose targetRef points into it, even
+// where the namespace filter doesn't cover the policy's namespace: a namespace named explicitly
+// is never filtered. An empty namespace stands for all namespaces the filter covers.
+func (a *Analyzer) policiesTargetingNamespace(ctx context.Context, namespace string) ([]unstructured.Unstructured, error) {
+	list, err := a.listCached(ctx, authPolicyGVR, "")
+	if err != nil {
+		return nil, err
+	}
+
+	covered := func(ns string) bool {
+		if namespace == "" {
+			return a.namespaceFilter.Allows(ns)
+		}
+		return ns == namespace
+	}
+
+	policies := []unstructured.Unstructured{}
+	for _, policy := range list.Items {
+		_, targetNamespace, _, ok := policyTargetRef(policy)
+		if covered(policy.GetNamespace()) || (ok && covered(targetNamespace)) {
+			policies = append(policies, policy)
+		}
+	}
+	return policies, nil
+}
+
+// policyTargetsServer reports whether an AuthorizationPolicy targets the given Server.
+// targetRef.namespace defaults to the policy's own namespace.
+func policyTargetsServer(policy unstructured.Unstructured, serverNamespace, serverName string) bool {
+	targetNamespace, targetName, ok := PolicyTargetServer(policy)
+	return ok && targetName == serverName && targetNamespace == serverNamespace
+}
+
+// PolicyTargetServer returns the namespace and name of the Server an AuthorizationPolicy
+// targets. targetRef.namespace defaults to the policy's own namespace; policies targeting
+// anything other than a Server report false.
+func PolicyTargetServer(policy unstructured.Unstructured) (string, string, bool) {
+	kind, targetNamespace, targetName, ok := policyTargetRef(policy)
+	if !ok || (kind != "" && kind != "Server") {
+		return "", "", false
+	}
+	return targetNamespace, targetName, true
+}
+
+// policyTargetRoute returns the namespace and name of the HTTPRoute an AuthorizationPolicy
+// targets, with the same defaulting as PolicyTargetServer
+func policyTargetRoute(policy unstructured.Unstructured) (string, string, bool) {
+	kind, targetNamespace, targetName, ok := policyTargetRef(policy)
+	if !ok || kind != "HTTPRoute" {
+		return "", "", false
+	}
+	return targetNamespace, targetName, true
+}
+
+// policyTargetRef returns the kind, namespace and name of an AuthorizationPolicy's targetRef.
+// The namespace defaults to the policy's own namespace.
+func policyTargetRef(policy unstructured.Unstructured) (string, string, string, bool) {
+	targetRef, found, err := unstructured.NestedMap(policy.Object, "spec", "targetRef")
+	if err != nil || !found {
+		return "", "", "", false
+	}
+
+	targetName, _, _ := unstructured.NestedString(targetRef, "name")
+	if targetName == "" {
+		return "", "", "", false
+	}
+	kind, _, _ := unstructured.NestedString(targetRef, "kind")
+	targetNamespace, _, _ := unstructured.NestedString(targetRef, "namespace")
+	if targetNamespace == "" {
+		targetNamespace = policy.GetNamespace()
+	}
+
+	return kind, targetNamespace, targetName, true
+}
+
+// authRefNamespace returns the namespace of an authentication reference, defaulting to the policy's namespace
+func authRefNamespace(authRef map[string]interface{}, policy unstructured.Unstructured) string {
+	if namespace, _, _ := unstructured.NestedString(authRef, "namespace"); namespace != "" {
+		return namespace
+	}
+	return policy.GetNamespace()
+}
+
+// checkSourceAllowed checks if a source is allowed by an authorization policy. NetworkAuthentications
+// are matched against sourceIPs, the pod IPs of the source; without them they never match.
+func (a *Analyzer) checkSourceAllowed(ctx context.Context, policy unstructured.Unstructured, sourceNamespace, sourceServiceAccount string, sourceIPs []net.IP) bool {
 	requiredAuths, found, err := unstructured.NestedSlice(policy.Object, "spec", "requiredAuthenticationRefs")
 	if err != nil || !found {
 		// If no authentication required, it's allowed by default (depending on mode)
@@ -28,7 +114,7 @@ func (a *Analyzer) checkSourceAllowed(ctx context.Context, policy unstructured.U
 		authKind, _, _ := unstructured.NestedString(authMap, "kind")
 
 		if authKind == "MeshTLSAuthentication" || authKind == "NetworkAuthentication" {
-			if a.checkAuthenticationMatch(ctx, serverNamespace, authName, authKind, sourceNamespace, sourceServiceAccount) {
+			if a.checkAuthenticationMatch(ctx, authRefNamespace(authMap, policy), authName, authKind, sourceNamespace, sourceServiceAccount, sourceIPs) {
 				return true
 			}
 		}
@@ -38,7 +124,7 @@ func (a *Analyzer) checkSourceAllowed(ctx context.Context, policy unstructured.U
 }
 
 // checkAuthenticationMatch checks if an authentication resource matches the source
-func (a *Analyzer) checkAuthenticationMatch(ctx context.Context, serverNamespace, authName, authKind, sourceNamespace, sourceServiceAccount string) bool {
+func (a *Analyzer) checkAuthenticationMatch(ctx context.Context, authNamespace, authName, authKind, sourceNamespace, sourceServiceAccount string, sourceIPs []net.IP) bool {
 	authGVR := schema.GroupVersionResource{
 		Group:    "policy.linkerd.io",
 		Version:  "v1alpha1",
@@ -48,11 +134,15 @@ func (a *Analyzer) checkAuthenticationMatch(ctx context.Context, serverNamespace
 		authGVR.Resource = "networkauthentications"
 	}
 
-	auth, err := a.dynamicClient.Resource(authGVR).Namespace(serverNamespace).Get(ctx, authName, metav1.GetOptions{})
+	auth, err := a.getResource(ctx, authGVR, authNamespace, authName)
 	if err != nil {
 		return false
 	}
 
+	if authKind == "NetworkAuthentication" {
+		return networksContainAll(*auth, sourceIPs)
+	}
+
 	// Check identities
 	identities, found, err := unstructured.NestedSlice(auth.Object, "spec", "identities")
 	if err == nil && found {
@@ -61,9 +151,7 @@ func (a *Analyzer) checkAuthenticationMatch(ctx context.Context, serverNamespace
 			if !ok {
 				continue
 			}
-			// Linkerd identities are in the format: {serviceaccount}.{namespace}.serviceaccount.identity.linkerd.cluster.local
-			expectedIdentity := fmt.Sprintf("%s.%s.serviceaccount.identity.linkerd.cluster.local", sourceServiceAccount, sourceNamespace)
-			if identityStr == expectedIdentity || identityStr == "*" {
+			if identityMatches(identityStr, sourceNamespace, sourceServiceAccount) {
 				return true
 			}
 		}
@@ -80,7 +168,7 @@ func (a *Analyzer) checkAuthenticationMatch(ctx context.Context, serverNamespace
 			saName, _, _ := unstructured.NestedString(saMap, "name")
 			saNamespace, _, _ := unstructured.NestedString(saMap, "namespace")
 			if saNamespace == "" {
-				saNamespace = serverNamespace
+				saNamespace = authNamespace
 			}
 			if saName == sourceServiceAccount && saNamespace == sourceNamespace {
 				return true
@@ -90,3 +178,58 @@ func (a *Analyzer) checkAuthenticationMatch(ctx context.Context, serverNamespace
 
 	return false
 }
+
+// networksContainAll reports whether every IP lies within one of the networks of a
+// NetworkAuthentication, outside its except entries. A source is only reliably allowed when all
+// of its pods are, so a single uncovered pod IP fails the match, as does an empty list.
+func networksContainAll(auth unstructured.Unstructured, ips []net.IP) bool {
+	networks, _, _ := unstructured.NestedSlice(auth.Object, "spec", "networks")
+	if len(ips) == 0 || len(networks) == 0 {
+		return false
+	}
+
+	for _, ip := range ips {
+		covered := false
+		for _, network := range networks {
+			networkMap, ok := network.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			cidr, _, _ := unstructured.NestedString(networkMap, "cidr")
+			except, _, _ := unstructured.NestedStringSlice(networkMap, "except")
+			if networkContains(cidr, ip) && !anyNetworkContains(except, ip) {
+				covered = true
+				break
+			}
+		}
+		if !covered {
+			return false
+		}
+	}
+	return true
+}
+
+// networkContains reports whether a CIDR, or like Linkerd a single IP address, contains an IP
+func networkContains(cidr string, ip net.IP) bool {
+	if host := net.ParseIP(cidr); host != nil {
+		return host.Equal(ip)
+	}
+	_, network, err := net.ParseCIDR(cidr)
+	return err == nil && network.Contains(ip)
+}
+
+func anyNetworkContains(cidrs []string, ip net.IP) bool {
+	for _, cidr := range cidrs {
+		if networkContains(cidr, ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// identityMatches checks if an identity entry covers the source service account
+func identityMatches(identity, sourceNamespace, sourceServiceAccount string) bool {
+	// Linkerd identities are in the format: {serviceaccount}.{namespace}.serviceaccount.identity.linkerd.cluster.local
+	expectedIdentity := fmt.Sprintf("%s.%s.serviceaccount.identity.linkerd.cluster.local", sourceServiceAccount, sourceNamespace)
+	return identity == expectedIdentity || identity == "*"
+}
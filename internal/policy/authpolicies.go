@@ -0,0 +1,123 @@
+package policy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// AuthorizationPolicyEntry is one AuthorizationPolicy with its target and the sources its
+// authentication refs resolve to
+type AuthorizationPolicyEntry struct {
+	Policy     string `json:"policy"`
+	Namespace  string `json:"namespace"`
+	TargetKind string `json:"targetKind"`
+	// TargetServer is the namespace/name of the targeted Server
+	TargetServer string `json:"targetServer,omitempty"`
+	// HTTPRoute is the namespace/name of the targeted HTTPRoute, and RouteServers the
+	// namespace/name of the Servers it is attached to
+	HTTPRoute    string   `json:"httpRoute,omitempty"`
+	RouteServers []string `json:"routeServers,omitempty"`
+	// Target is the namespace/name of any other targetRef, such as a Namespace
+	Target          string                   `json:"target,omitempty"`
+	ResolvedSources []map[string]interface{} `json:"resolvedSources"`
+	// Issues are the reasons some or all of the policy's authentication refs grant nothing
+	Issues []map[string]interface{} `json:"issues,omitempty"`
+}
+
+// ListAuthorizationPolicies lists the AuthorizationPolicies in a namespace (or all namespaces),
+// each with its target Server and the identities, service accounts and networks its
+// requiredAuthenticationRefs allow
+func (a *Analyzer) ListAuthorizationPolicies(ctx context.Context, namespace string) (*mcp.CallToolResult, error) {
+	ctx = withLookupCache(ctx)
+
+	list, err := a.listResources(ctx, authPolicyGVR, namespace)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to list AuthorizationPolicies: %v", err)), nil
+	}
+
+	items := append([]unstructured.Unstructured{}, list.Items...)
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].GetNamespace() != items[j].GetNamespace() {
+			return items[i].GetNamespace() < items[j].GetNamespace()
+		}
+		return items[i].GetName() < items[j].GetName()
+	})
+
+	policies := []AuthorizationPolicyEntry{}
+	for _, policy := range items {
+		policies = append(policies, a.authorizationPolicyEntry(ctx, policy))
+	}
+
+	result := map[string]interface{}{
+		"namespace":     namespace,
+		"policies":      policies,
+		"totalPolicies": len(policies),
+	}
+
+	resultJSON, _ := json.MarshalIndent(result, "", "  ")
+	return mcp.NewToolResultText(string(resultJSON)), nil
+}
+
+func (a *Analyzer) authorizationPolicyEntry(ctx context.Context, policy unstructured.Unstructured) AuthorizationPolicyEntry {
+	entry := AuthorizationPolicyEntry{
+		Policy:          policy.GetName(),
+		Namespace:       policy.GetNamespace(),
+		ResolvedSources: []map[string]interface{}{},
+	}
+
+	kind, targetNamespace, targetName, ok := policyTargetRef(policy)
+	if ok {
+		if kind == "" {
+			kind = "Server"
+		}
+		entry.TargetKind = kind
+		target := fmt.Sprintf("%s/%s", targetNamespace, targetName)
+		switch kind {
+		case "Server":
+			entry.TargetServer = target
+		case "HTTPRoute":
+			entry.HTTPRoute = target
+			entry.RouteServers = []string{}
+			if grant, ok := a.policyRouteGrant(ctx, policy); ok {
+				for _, server := range grant.servers {
+					entry.RouteServers = append(entry.RouteServers, fmt.Sprintf("%s/%s", grant.namespace, server))
+				}
+			}
+		default:
+			entry.Target = target
+		}
+	}
+
+	sources := map[string]map[string]interface{}{}
+	requiredAuths, _, _ := unstructured.NestedSlice(policy.Object, "spec", "requiredAuthenticationRefs")
+	for _, authRef := range requiredAuths {
+		authMap, ok := authRef.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		authName, _, _ := unstructured.NestedString(authMap, "name")
+		authKind, _, _ := unstructured.NestedString(authMap, "kind")
+
+		for key, source := range a.extractSourcesFromAuth(ctx, authRefNamespace(authMap, policy), authName, authKind, policy.GetName()) {
+			source["authenticationKind"] = authKind
+			sources[authKind+"|"+key] = source
+		}
+	}
+
+	keys := make([]string, 0, len(sources))
+	for key := range sources {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		entry.ResolvedSources = append(entry.ResolvedSources, sources[key])
+	}
+
+	entry.Issues = a.explainAuthRefs(ctx, policy, false)
+	return entry
+}
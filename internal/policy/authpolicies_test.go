@@ -0,0 +1,105 @@
+package policy_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/christianhuening/linkerd-mcp/internal/policy"
+	"github.com/christianhuening/linkerd-mcp/internal/testutil"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/fake"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+)
+
+var _ = Describe("ListAuthorizationPolicies", func() {
+	var (
+		ctx      context.Context
+		analyzer *policy.Analyzer
+	)
+
+	listPolicies := func(namespace string) []policy.AuthorizationPolicyEntry {
+		result, err := analyzer.ListAuthorizationPolicies(ctx, namespace)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.IsError).To(BeFalse())
+
+		var response struct {
+			Policies      []policy.AuthorizationPolicyEntry `json:"policies"`
+			TotalPolicies int                               `json:"totalPolicies"`
+		}
+		Expect(testutil.ParseJSONResult(result, &response)).To(Succeed())
+		Expect(response.Policies).To(HaveLen(response.TotalPolicies))
+		return response.Policies
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+
+		scheme := runtime.NewScheme()
+		gvrToListKind := map[schema.GroupVersionResource]string{
+			serverGVR:      "ServerList",
+			authPolicyGVR:  "AuthorizationPolicyList",
+			meshTLSAuthGVR: "MeshTLSAuthenticationList",
+			{Group: "policy.linkerd.io", Version: "v1alpha1", Resource: "networkauthentications"}: "NetworkAuthenticationList",
+			{Group: "policy.linkerd.io", Version: "v1alpha1", Resource: "httproutes"}:             "HTTPRouteList",
+		}
+
+		routePolicy := testutil.CreateAuthorizationPolicy("admin-route", "prod", "admin",
+			[]map[string]string{{"name": "internal", "kind": "NetworkAuthentication"}})
+		Expect(unstructured.SetNestedField(routePolicy.Object, "HTTPRoute", "spec", "targetRef", "kind")).To(Succeed())
+
+		dynamicClient := fake.NewSimpleDynamicClientWithCustomListKinds(scheme, gvrToListKind,
+			testutil.CreateServer("api-http", "prod", map[string]string{"app": "api"}, 8080),
+			testutil.CreateAuthorizationPolicy("api-clients", "prod", "api-http", []map[string]string{
+				{"name": "web-clients", "kind": "MeshTLSAuthentication"},
+				{"name": "missing", "kind": "MeshTLSAuthentication"},
+			}),
+			testutil.CreateMeshTLSAuthentication("web-clients", "prod",
+				[]string{"web.staging.serviceaccount.identity.linkerd.cluster.local"},
+				[]map[string]string{{"name": "frontend", "namespace": "web"}}),
+			routePolicy,
+			testutil.CreateServerHTTPRoute("admin", "prod", "api-http", nil),
+			testutil.CreateNetworkAuthentication("internal", "prod", []map[string]interface{}{{"cidr": "10.0.0.0/8"}}),
+			testutil.CreateAuthorizationPolicy("web-open", "staging", "web-http", nil),
+		)
+
+		analyzer = policy.NewAnalyzer(kubefake.NewSimpleClientset(), dynamicClient)
+	})
+
+	It("should resolve the sources of each AuthorizationPolicy in a namespace", func() {
+		policies := listPolicies("prod")
+
+		Expect(policies).To(HaveLen(2))
+		Expect(policies[0].Policy).To(Equal("admin-route"))
+		Expect(policies[0].TargetKind).To(Equal("HTTPRoute"))
+		Expect(policies[0].HTTPRoute).To(Equal("prod/admin"))
+		Expect(policies[0].RouteServers).To(ConsistOf("prod/api-http"))
+		Expect(policies[0].ResolvedSources).To(ConsistOf(And(
+			HaveKeyWithValue("type", "network"),
+			HaveKeyWithValue("cidr", "10.0.0.0/8"),
+			HaveKeyWithValue("authenticationKind", "NetworkAuthentication"),
+		)))
+
+		apiClients := policies[1]
+		Expect(apiClients.Policy).To(Equal("api-clients"))
+		Expect(apiClients.TargetKind).To(Equal("Server"))
+		Expect(apiClients.TargetServer).To(Equal("prod/api-http"))
+		Expect(apiClients.ResolvedSources).To(ConsistOf(
+			HaveKeyWithValue("identity", "web.staging.serviceaccount.identity.linkerd.cluster.local"),
+			And(HaveKeyWithValue("serviceAccount", "frontend"), HaveKeyWithValue("namespace", "web")),
+		))
+		Expect(apiClients.Issues).To(ConsistOf(HaveKeyWithValue("reason", "AuthenticationNotFound")))
+	})
+
+	It("should list the AuthorizationPolicies of all namespaces", func() {
+		policies := listPolicies("")
+
+		Expect(policies).To(HaveLen(3))
+		Expect(policies[2].Namespace).To(Equal("staging"))
+		Expect(policies[2].ResolvedSources).To(BeEmpty())
+		Expect(policies[2].Issues).To(ConsistOf(HaveKeyWithValue("reason", "NoAuthenticationRefs")))
+	})
+})
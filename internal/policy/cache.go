@@ -0,0 +1,100 @@
+package policy
+
+import (
+	"context"
+	"sync"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+type lookupCacheKey struct{}
+
+// lookupCache memoizes policy CRD lists and gets for the duration of a single analysis call,
+// so each namespace's resources and each referenced resource are fetched at most once.
+// Cached objects are shared and must not be mutated. A nil cache disables caching.
+type lookupCache struct {
+	mu    sync.Mutex
+	lists map[string]listResult
+	gets  map[string]getResult
+}
+
+type listResult struct {
+	list *unstructured.UnstructuredList
+	err  error
+}
+
+type getResult struct {
+	obj *unstructured.Unstructured
+	err error
+}
+
+// withLookupCache returns a context carrying a fresh lookup cache, unless one is already set
+func withLookupCache(ctx context.Context) context.Context {
+	if _, ok := ctx.Value(lookupCacheKey{}).(*lookupCache); ok {
+		return ctx
+	}
+	return context.WithValue(ctx, lookupCacheKey{}, &lookupCache{
+		lists: map[string]listResult{},
+		gets:  map[string]getResult{},
+	})
+}
+
+// cacheFrom returns the lookup cache of the context, or nil when there is none
+func cacheFrom(ctx context.Context) *lookupCache {
+	cache, _ := ctx.Value(lookupCacheKey{}).(*lookupCache)
+	return cache
+}
+
+// listResources lists a policy resource in a namespace ("" for all namespaces the namespace
+// filter covers), through the call's cache
+func (a *Analyzer) listResources(ctx context.Context, gvr schema.GroupVersionResource, namespace string) (*unstructured.UnstructuredList, error) {
+	list, err := a.listCached(ctx, gvr, namespace)
+	if err != nil || namespace != "" {
+		return list, err
+	}
+
+	filtered := *list
+	filtered.Items = a.namespaceFilter.Unstructured(list.Items)
+	return &filtered, nil
+}
+
+// listCached lists a policy resource in a namespace ("" for all namespaces, whether or not the
+// namespace filter covers them), through the call's cache
+func (a *Analyzer) listCached(ctx context.Context, gvr schema.GroupVersionResource, namespace string) (*unstructured.UnstructuredList, error) {
+	cache := cacheFrom(ctx)
+	if cache == nil {
+		return a.dynamicClient.Resource(gvr).Namespace(namespace).List(ctx, metav1.ListOptions{})
+	}
+
+	key := gvr.String() + "|" + namespace
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	if cached, ok := cache.lists[key]; ok {
+		return cached.list, cached.err
+	}
+
+	list, err := a.dynamicClient.Resource(gvr).Namespace(namespace).List(ctx, metav1.ListOptions{})
+	cache.lists[key] = listResult{list: list, err: err}
+	return list, err
+}
+
+// getResource gets a single policy resource, through the call's cache
+func (a *Analyzer) getResource(ctx context.Context, gvr schema.GroupVersionResource, namespace, name string) (*unstructured.Unstructured, error) {
+	cache := cacheFrom(ctx)
+	if cache == nil {
+		return a.dynamicClient.Resource(gvr).Namespace(namespace).Get(ctx, name, metav1.GetOptions{})
+	}
+
+	key := gvr.String() + "|" + namespace + "/" + name
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	if cached, ok := cache.gets[key]; ok {
+		return cached.obj, cached.err
+	}
+
+	obj, err := a.dynamicClient.Resource(gvr).Namespace(namespace).Get(ctx, name, metav1.GetOptions{})
+	cache.gets[key] = getResult{obj: obj, err: err}
+	return obj, err
+}
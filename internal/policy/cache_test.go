@@ -0,0 +1,158 @@
+package policy_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/christianhuening/linkerd-mcp/internal/policy"
+	"github.com/christianhuening/linkerd-mcp/internal/testutil"
+	"github.com/mark3labs/mcp-go/mcp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/fake"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+var _ = Describe("Lookup cache", func() {
+	var (
+		ctx           context.Context
+		dynamicClient *fake.FakeDynamicClient
+		analyzer      *policy.Analyzer
+	)
+
+	// countCalls runs call and returns its result with the number of CRD requests it made
+	countCalls := func(call func() (*mcp.CallToolResult, error)) (*mcp.CallToolResult, int) {
+		dynamicClient.ClearActions()
+		result, err := call()
+		Expect(err).NotTo(HaveOccurred())
+		return result, len(dynamicClient.Actions())
+	}
+
+	// authGets counts the MeshTLSAuthentication fetches recorded by the fake client
+	authGets := func() int {
+		count := 0
+		for _, action := range dynamicClient.Actions() {
+			if action.Matches("get", "meshtlsauthentications") {
+				count++
+			}
+		}
+		return count
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+
+		scheme := runtime.NewScheme()
+		gvrToListKind := map[schema.GroupVersionResource]string{
+			serverGVR:              "ServerList",
+			authPolicyGVR:          "AuthorizationPolicyList",
+			meshTLSAuthGVR:         "MeshTLSAuthenticationList",
+			serverAuthorizationGVR: "ServerAuthorizationList",
+		}
+
+		kubeClient := kubefake.NewSimpleClientset(
+			testutil.CreatePod("frontend-1", "prod", "frontend-sa", map[string]string{"app": "frontend"}, "Running", true),
+			testutil.CreatePod("api-1", "prod", "api-sa", map[string]string{"app": "api"}, "Running", true),
+		)
+		dynamicClient = fake.NewSimpleDynamicClientWithCustomListKinds(scheme, gvrToListKind)
+		analyzer = policy.NewAnalyzer(kubeClient, dynamicClient)
+
+		// Several Servers share one authentication resource through separate policies
+		for _, name := range []string{"api-http", "api-grpc", "api-admin"} {
+			server := testutil.CreateServer(name, "prod", map[string]string{"app": "api"}, 8080)
+			_, err := dynamicClient.Resource(serverGVR).Namespace("prod").Create(ctx, server, metav1.CreateOptions{})
+			Expect(err).NotTo(HaveOccurred())
+
+			authPolicy := testutil.CreateAuthorizationPolicy(name+"-policy", "prod", name,
+				[]map[string]string{{"name": "mesh-clients", "kind": "MeshTLSAuthentication"}})
+			_, err = dynamicClient.Resource(authPolicyGVR).Namespace("prod").Create(ctx, authPolicy, metav1.CreateOptions{})
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		_, err := dynamicClient.Resource(meshTLSAuthGVR).Namespace("prod").Create(ctx,
+			testutil.CreateMeshTLSAuthentication("mesh-clients", "prod", nil,
+				[]map[string]string{{"name": "frontend-sa", "namespace": "prod"}}), metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred())
+
+		serverAuth := testutil.CreateServerAuthorization("admin-access", "prod", "api-admin",
+			[]string{"ops.prod.serviceaccount.identity.linkerd.cluster.local"}, nil)
+		_, err = dynamicClient.Resource(serverAuthorizationGVR).Namespace("prod").Create(ctx, serverAuth, metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	Describe("GetAllowedSources", func() {
+		It("should return the same result as the uncached path with fewer lookups", func() {
+			uncached, uncachedCalls := countCalls(func() (*mcp.CallToolResult, error) {
+				return analyzer.GetAllowedSources(policy.DisableLookupCache(ctx), "prod", "api", true)
+			})
+			cached, cachedCalls := countCalls(func() (*mcp.CallToolResult, error) {
+				return analyzer.GetAllowedSources(ctx, "prod", "api", true)
+			})
+			Expect(authGets()).To(Equal(1))
+			Expect(cachedCalls).To(BeNumerically("<", uncachedCalls))
+
+			var uncachedResponse, cachedResponse map[string]interface{}
+			Expect(testutil.ParseJSONResult(uncached, &uncachedResponse)).To(Succeed())
+			Expect(testutil.ParseJSONResult(cached, &cachedResponse)).To(Succeed())
+
+			// Sources are collected from a map, so only the contents are comparable
+			Expect(cachedResponse["allowedSources"]).To(ConsistOf(uncachedResponse["allowedSources"]))
+			Expect(cachedResponse["deniedSources"]).To(ConsistOf(uncachedResponse["deniedSources"]))
+			Expect(cachedResponse["matchingServers"]).To(ConsistOf(uncachedResponse["matchingServers"]))
+			Expect(cachedResponse["totalSources"]).To(Equal(uncachedResponse["totalSources"]))
+		})
+	})
+
+	Describe("GetAllowedTargets", func() {
+		It("should return the same result as the uncached path with fewer lookups", func() {
+			uncached, uncachedCalls := countCalls(func() (*mcp.CallToolResult, error) {
+				return analyzer.GetAllowedTargets(policy.DisableLookupCache(ctx), "prod", "frontend", true)
+			})
+			cached, cachedCalls := countCalls(func() (*mcp.CallToolResult, error) {
+				return analyzer.GetAllowedTargets(ctx, "prod", "frontend", true)
+			})
+			Expect(authGets()).To(Equal(1))
+			Expect(cachedCalls).To(BeNumerically("<", uncachedCalls))
+
+			var uncachedResponse, cachedResponse map[string]interface{}
+			Expect(testutil.ParseJSONResult(uncached, &uncachedResponse)).To(Succeed())
+			Expect(testutil.ParseJSONResult(cached, &cachedResponse)).To(Succeed())
+			Expect(cachedResponse).To(Equal(uncachedResponse))
+		})
+	})
+
+	Describe("ExportPolicyGraph", func() {
+		It("should return the same graph as the uncached path with fewer lookups", func() {
+			uncached, uncachedCalls := countCalls(func() (*mcp.CallToolResult, error) {
+				return analyzer.ExportPolicyGraph(policy.DisableLookupCache(ctx), "prod", "dot")
+			})
+			cached, cachedCalls := countCalls(func() (*mcp.CallToolResult, error) {
+				return analyzer.ExportPolicyGraph(ctx, "prod", "dot")
+			})
+			Expect(authGets()).To(Equal(1))
+			Expect(cachedCalls).To(BeNumerically("<", uncachedCalls))
+
+			var uncachedDOT, cachedDOT string
+			Expect(testutil.GetTextFromResult(uncached, &uncachedDOT)).To(Succeed())
+			Expect(testutil.GetTextFromResult(cached, &cachedDOT)).To(Succeed())
+			Expect(cachedDOT).To(Equal(uncachedDOT))
+		})
+	})
+
+	It("should not share lookups between calls", func() {
+		_, err := analyzer.GetAllowedTargets(ctx, "prod", "frontend", false)
+		Expect(err).NotTo(HaveOccurred())
+
+		dynamicClient.ClearActions()
+		_, err = analyzer.GetAllowedTargets(ctx, "prod", "frontend", false)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(dynamicClient.Actions()).To(ContainElement(WithTransform(func(action k8stesting.Action) bool {
+			return action.Matches("list", "servers")
+		}, BeTrue())))
+	})
+})
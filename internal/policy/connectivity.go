@@ -0,0 +1,190 @@
+package policy
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/christianhuening/linkerd-mcp/internal/kube"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// Sources of the default inbound policy applied to a workload, most specific first
+const (
+	defaultPolicySourcePod       = "pod"
+	defaultPolicySourceNamespace = "namespace"
+	defaultPolicySourceCluster   = "cluster"
+)
+
+// Bases of a connectivity verdict
+const (
+	verdictBasisAuthorizationPolicy  = "authorizationPolicy"
+	verdictBasisDefaultInboundPolicy = "defaultInboundPolicy"
+	verdictBasisServerAccessPolicy   = "serverAccessPolicy"
+)
+
+// Inbound policies with special handling: audit admits everything but logs would-be denials, and
+// deny is the access policy of a Server unless spec.accessPolicy says otherwise
+const (
+	auditInboundPolicy = "audit"
+	denyInboundPolicy  = "deny"
+)
+
+// authorizingPolicies returns the AuthorizationPolicies and legacy ServerAuthorizations on the
+// given Servers that admit the source identity
+func (a *Analyzer) authorizingPolicies(ctx context.Context, namespace string, matchingServers []string, sourceNamespace, sourceServiceAccount string) []string {
+	policies := []string{}
+	seen := map[string]bool{}
+	add := func(name string) {
+		if !seen[name] {
+			seen[name] = true
+			policies = append(policies, name)
+		}
+	}
+
+	if authPolicies, err := a.policiesTargetingNamespace(ctx, namespace); err == nil {
+		for _, policy := range authPolicies {
+			for _, serverName := range matchingServers {
+				if policyTargetsServer(policy, namespace, serverName) &&
+					a.checkSourceAllowed(ctx, policy, sourceNamespace, sourceServiceAccount, nil) {
+					add(policy.GetName())
+					break
+				}
+			}
+		}
+	}
+
+	serverAuths := a.listServerAuthorizations(ctx, namespace)
+	for _, serverName := range matchingServers {
+		if len(serverAuths) == 0 {
+			break
+		}
+		server, err := a.getResource(ctx, serverGVR, namespace, serverName)
+		if err != nil {
+			continue
+		}
+		for _, serverAuth := range serverAuths {
+			if serverAuthorizationTargets(serverAuth, *server) &&
+				checkServerAuthorizationAllowed(serverAuth, sourceNamespace, sourceServiceAccount) {
+				add(serverAuth.GetName())
+			}
+		}
+	}
+
+	return policies
+}
+
+// resolveDefaultInboundPolicy returns the default inbound policy that applies to a service and
+// where it is set: a pod annotation, the namespace annotation or the cluster configuration
+func (a *Analyzer) resolveDefaultInboundPolicy(ctx context.Context, namespace, service string) (string, string) {
+	for _, pod := range a.findServicePods(ctx, namespace, service) {
+		if policy := pod.Annotations[defaultInboundPolicyAnnotation]; policy != "" {
+			return policy, defaultPolicySourcePod
+		}
+	}
+
+	ns, err := a.clientset.CoreV1().Namespaces().Get(ctx, namespace, metav1.GetOptions{})
+	if err == nil {
+		if policy := ns.Annotations[defaultInboundPolicyAnnotation]; policy != "" {
+			return policy, defaultPolicySourceNamespace
+		}
+	}
+
+	return a.clusterDefaultInboundPolicy(ctx), defaultPolicySourceCluster
+}
+
+// findServicePods returns the pods backing a service, selected by the Service selector or,
+// when the Service can't be resolved, by the app label
+func (a *Analyzer) findServicePods(ctx context.Context, namespace, service string) []corev1.Pod {
+	selector := labels.SelectorFromSet(labels.Set{"app": service})
+	if svc, err := a.clientset.CoreV1().Services(namespace).Get(ctx, service, metav1.GetOptions{}); err == nil && len(svc.Spec.Selector) > 0 {
+		selector = labels.SelectorFromSet(svc.Spec.Selector)
+	}
+
+	pods, err := kube.ListPods(ctx, a.clientset, namespace, metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return nil
+	}
+	return pods.Items
+}
+
+// defaultPolicyVerdict decides whether a default inbound policy admits a source, and describes why
+func defaultPolicyVerdict(policy string, sourceMeshed bool) (bool, string) {
+	meshedSuffix := ", and the source is meshed"
+	if !sourceMeshed {
+		meshedSuffix = ", but the source is not meshed"
+	}
+
+	switch policy {
+	case "all-unauthenticated":
+		return true, "all traffic is allowed"
+	case "cluster-unauthenticated":
+		return true, "all traffic from within the cluster is allowed"
+	case "all-authenticated":
+		return sourceMeshed, "only meshed clients are allowed" + meshedSuffix
+	case "cluster-authenticated":
+		return sourceMeshed, "only meshed clients from within the cluster are allowed" + meshedSuffix
+	case auditInboundPolicy:
+		return true, "all traffic is allowed in audit mode; requests that would be denied are only logged"
+	case denyInboundPolicy:
+		return false, "all traffic is denied unless explicitly authorized"
+	default:
+		return false, "the policy is not recognized and is treated as deny"
+	}
+}
+
+// defaultPolicySources describes the clients a default inbound policy admits
+func defaultPolicySources(policy string) []map[string]interface{} {
+	descriptions := map[string]string{
+		"all-unauthenticated":     "All clients, meshed or not",
+		"cluster-unauthenticated": "All clients from within the cluster, meshed or not",
+		"all-authenticated":       "All meshed clients",
+		"cluster-authenticated":   "All meshed clients from within the cluster",
+		auditInboundPolicy:        "All clients (audit mode: requests that would be denied are only logged)",
+	}
+
+	description, ok := descriptions[policy]
+	if !ok {
+		return []map[string]interface{}{}
+	}
+	return []map[string]interface{}{{
+		"type":        "defaultPolicy",
+		"policy":      policy,
+		"description": description,
+	}}
+}
+
+// serverAccessPolicy returns the policy a Server applies to traffic that no AuthorizationPolicy
+// authorizes, set by spec.accessPolicy and deny by default
+func (a *Analyzer) serverAccessPolicy(ctx context.Context, namespace, serverName string) string {
+	server, err := a.getResource(ctx, serverGVR, namespace, serverName)
+	if err != nil {
+		return denyInboundPolicy
+	}
+	if policy, _, _ := unstructured.NestedString(server.Object, "spec", "accessPolicy"); policy != "" {
+		return policy
+	}
+	return denyInboundPolicy
+}
+
+// podServiceAccount returns the service account a pod runs as
+func podServiceAccount(pod *corev1.Pod) string {
+	if pod.Spec.ServiceAccountName == "" {
+		return "default"
+	}
+	return pod.Spec.ServiceAccountName
+}
+
+// connectivityExplanation describes a verdict based on explicit policies
+func connectivityExplanation(policies, matchingServers []string, sourceErr error) string {
+	switch {
+	case len(policies) > 0:
+		return fmt.Sprintf("Allowed by %v on Servers %v", policies, matchingServers)
+	case sourceErr != nil:
+		return fmt.Sprintf("Denied: Servers %v restrict the target and the source identity could not be resolved: %v", matchingServers, sourceErr)
+	default:
+		return fmt.Sprintf("Denied: no AuthorizationPolicy or ServerAuthorization on Servers %v authorizes the source identity", matchingServers)
+	}
+}
@@ -0,0 +1,216 @@
+package policy_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/christianhuening/linkerd-mcp/internal/policy"
+	"github.com/christianhuening/linkerd-mcp/internal/testutil"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/fake"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+)
+
+var _ = Describe("AnalyzeConnectivity verdicts", func() {
+	var (
+		ctx           context.Context
+		kubeClient    *kubefake.Clientset
+		dynamicClient *fake.FakeDynamicClient
+	)
+
+	analyze := func() map[string]interface{} {
+		result, err := policy.NewAnalyzer(kubeClient, dynamicClient).AnalyzeConnectivity(ctx, "prod", "frontend", "prod", "backend")
+		Expect(err).NotTo(HaveOccurred())
+
+		var analysis map[string]interface{}
+		err = testutil.ParseJSONResult(result, &analysis)
+		Expect(err).NotTo(HaveOccurred())
+		return analysis
+	}
+
+	setClusterDefault := func(defaultPolicy string) {
+		_, err := kubeClient.CoreV1().ConfigMaps("linkerd").Create(ctx, &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "linkerd-config", Namespace: "linkerd"},
+			Data:       map[string]string{"values": "proxy:\n  defaultInboundPolicy: " + defaultPolicy + "\n"},
+		}, metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred())
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+
+		scheme := runtime.NewScheme()
+		gvrToListKind := map[schema.GroupVersionResource]string{
+			serverGVR:              "ServerList",
+			authPolicyGVR:          "AuthorizationPolicyList",
+			meshTLSAuthGVR:         "MeshTLSAuthenticationList",
+			serverAuthorizationGVR: "ServerAuthorizationList",
+		}
+
+		kubeClient = kubefake.NewSimpleClientset(
+			testutil.CreatePod("frontend-1", "prod", "frontend-sa", map[string]string{"app": "frontend"}, corev1.PodRunning, true),
+			testutil.CreateMeshedPod("backend-1", "prod", "backend"),
+		)
+		dynamicClient = fake.NewSimpleDynamicClientWithCustomListKinds(scheme, gvrToListKind)
+	})
+
+	Context("when no Server selects the target", func() {
+		It("should allow traffic in a default-allow cluster", func() {
+			analysis := analyze()
+
+			Expect(analysis["allowed"]).To(BeTrue())
+			Expect(analysis["verdictBasis"]).To(Equal("defaultInboundPolicy"))
+			Expect(analysis["defaultInboundPolicy"]).To(Equal(map[string]interface{}{
+				"policy": "all-unauthenticated",
+				"source": "cluster",
+			}))
+			Expect(analysis["explanation"]).To(ContainSubstring("default inbound policy"))
+		})
+
+		It("should deny traffic in a default-deny cluster", func() {
+			setClusterDefault("deny")
+
+			analysis := analyze()
+
+			Expect(analysis["allowed"]).To(BeFalse())
+			Expect(analysis["verdictBasis"]).To(Equal("defaultInboundPolicy"))
+			Expect(analysis["defaultInboundPolicy"]).To(HaveKeyWithValue("policy", "deny"))
+			Expect(analysis["defaultInboundPolicy"]).To(HaveKeyWithValue("source", "cluster"))
+		})
+
+		It("should prefer the namespace annotation over the cluster default", func() {
+			setClusterDefault("deny")
+			_, err := kubeClient.CoreV1().Namespaces().Create(ctx, namespaceWithDefaultPolicy("prod", "all-unauthenticated"), metav1.CreateOptions{})
+			Expect(err).NotTo(HaveOccurred())
+
+			analysis := analyze()
+
+			Expect(analysis["allowed"]).To(BeTrue())
+			Expect(analysis["defaultInboundPolicy"]).To(HaveKeyWithValue("source", "namespace"))
+		})
+
+		It("should prefer the target pod annotation over the namespace annotation", func() {
+			_, err := kubeClient.CoreV1().Namespaces().Create(ctx, namespaceWithDefaultPolicy("prod", "all-unauthenticated"), metav1.CreateOptions{})
+			Expect(err).NotTo(HaveOccurred())
+
+			pod, err := kubeClient.CoreV1().Pods("prod").Get(ctx, "backend-1", metav1.GetOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			pod.Annotations = map[string]string{"config.linkerd.io/default-inbound-policy": "deny"}
+			_, err = kubeClient.CoreV1().Pods("prod").Update(ctx, pod, metav1.UpdateOptions{})
+			Expect(err).NotTo(HaveOccurred())
+
+			analysis := analyze()
+
+			Expect(analysis["allowed"]).To(BeFalse())
+			Expect(analysis["defaultInboundPolicy"]).To(HaveKeyWithValue("source", "pod"))
+		})
+
+		It("should allow traffic in audit mode", func() {
+			setClusterDefault("audit")
+
+			analysis := analyze()
+
+			Expect(analysis["allowed"]).To(BeTrue())
+			Expect(analysis["auditMode"]).To(BeTrue())
+			Expect(analysis["explanation"]).To(ContainSubstring("audit mode"))
+		})
+
+		It("should report no allowed sources in a default-deny namespace", func() {
+			_, err := kubeClient.CoreV1().Namespaces().Create(ctx, namespaceWithDefaultPolicy("prod", "deny"), metav1.CreateOptions{})
+			Expect(err).NotTo(HaveOccurred())
+
+			result, err := policy.NewAnalyzer(kubeClient, dynamicClient).GetAllowedSources(ctx, "prod", "backend", false)
+			Expect(err).NotTo(HaveOccurred())
+
+			var response map[string]interface{}
+			Expect(testutil.ParseJSONResult(result, &response)).To(Succeed())
+			Expect(response["defaultInboundPolicy"]).To(HaveKeyWithValue("source", "namespace"))
+			Expect(response["allowedSources"]).To(BeEmpty())
+		})
+
+		It("should deny unmeshed sources when the default requires authentication", func() {
+			setClusterDefault("all-authenticated")
+
+			analysis := analyze()
+
+			Expect(analysis["allowed"]).To(BeFalse())
+			Expect(analysis["explanation"]).To(ContainSubstring("not meshed"))
+		})
+	})
+
+	Context("when a Server selects the target", func() {
+		BeforeEach(func() {
+			_, err := dynamicClient.Resource(serverGVR).Namespace("prod").Create(ctx,
+				testutil.CreateServer("backend-server", "prod", map[string]string{"app": "backend"}, 8080), metav1.CreateOptions{})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("should deny sources no policy authorizes, regardless of the default", func() {
+			analysis := analyze()
+
+			Expect(analysis["allowed"]).To(BeFalse())
+			Expect(analysis["verdictBasis"]).To(Equal("authorizationPolicy"))
+			Expect(analysis).NotTo(HaveKey("defaultInboundPolicy"))
+		})
+
+		It("should allow sources authorized by an AuthorizationPolicy", func() {
+			_, err := dynamicClient.Resource(authPolicyGVR).Namespace("prod").Create(ctx,
+				testutil.CreateAuthorizationPolicy("backend-clients", "prod", "backend-server",
+					[]map[string]string{{"name": "frontend-clients", "kind": "MeshTLSAuthentication"}}), metav1.CreateOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			_, err = dynamicClient.Resource(meshTLSAuthGVR).Namespace("prod").Create(ctx,
+				testutil.CreateMeshTLSAuthentication("frontend-clients", "prod", nil,
+					[]map[string]string{{"name": "frontend-sa", "namespace": "prod"}}), metav1.CreateOptions{})
+			Expect(err).NotTo(HaveOccurred())
+
+			analysis := analyze()
+
+			Expect(analysis["allowed"]).To(BeTrue())
+			Expect(analysis["verdictBasis"]).To(Equal("authorizationPolicy"))
+			Expect(analysis["policies"]).To(ConsistOf("backend-clients"))
+			Expect(analysis["matchingServers"]).To(ConsistOf("backend-server"))
+		})
+
+		Context("with an audit access policy on the Server", func() {
+			BeforeEach(func() {
+				server, err := dynamicClient.Resource(serverGVR).Namespace("prod").Get(ctx, "backend-server", metav1.GetOptions{})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(unstructured.SetNestedField(server.Object, "audit", "spec", "accessPolicy")).To(Succeed())
+				_, err = dynamicClient.Resource(serverGVR).Namespace("prod").Update(ctx, server, metav1.UpdateOptions{})
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("should allow unauthorized sources and flag audit mode", func() {
+				analysis := analyze()
+
+				Expect(analysis["allowed"]).To(BeTrue())
+				Expect(analysis["verdictBasis"]).To(Equal("serverAccessPolicy"))
+				Expect(analysis["auditMode"]).To(BeTrue())
+				Expect(analysis["serverAccessPolicy"]).To(Equal(map[string]interface{}{
+					"server": "backend-server",
+					"policy": "audit",
+				}))
+			})
+
+			It("should list the access policy among the allowed sources", func() {
+				result, err := policy.NewAnalyzer(kubeClient, dynamicClient).GetAllowedSources(ctx, "prod", "backend", false)
+				Expect(err).NotTo(HaveOccurred())
+
+				var response map[string]interface{}
+				Expect(testutil.ParseJSONResult(result, &response)).To(Succeed())
+				Expect(response["auditMode"]).To(BeTrue())
+				Expect(response["allowedSources"]).To(ConsistOf(SatisfyAll(
+					HaveKeyWithValue("type", "serverAccessPolicy"),
+					HaveKeyWithValue("server", "backend-server"),
+					HaveKeyWithValue("policy", "audit"),
+				)))
+			})
+		})
+	})
+})
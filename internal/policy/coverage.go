@@ -0,0 +1,77 @@
+package policy
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ServicePolicyCoverage describes whether the pods behind a Service are protected by Linkerd policy
+type ServicePolicyCoverage struct {
+	Service              string   `json:"service"`
+	Namespace            string   `json:"namespace"`
+	Servers              []string `json:"servers"`           // Servers selecting the service's pods
+	AuthorizedServers    []string `json:"authorizedServers"` // Servers also targeted by an AuthorizationPolicy or ServerAuthorization
+	DefaultInboundPolicy string   `json:"defaultInboundPolicy"`
+	TotalPods            int      `json:"totalPods"`
+	MeshedPods           int      `json:"meshedPods"`
+	Protected            bool     `json:"protected"` // selected by an authorized Server, or denied by default
+}
+
+// GetServicePolicyCoverage reports the policy coverage of every Service in a namespace
+func (a *Analyzer) GetServicePolicyCoverage(ctx context.Context, namespace string) ([]ServicePolicyCoverage, error) {
+	ctx = withLookupCache(ctx)
+
+	services, err := a.clientset.CoreV1().Services(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list services in namespace %s: %v", namespace, err)
+	}
+
+	authPolicies, err := a.policiesTargetingNamespace(ctx, namespace)
+	if err != nil {
+		log.Printf("Warning: Failed to list AuthorizationPolicies: %v", err)
+	}
+
+	authorized := map[string]bool{}
+	for _, server := range a.authorizedServers(ctx, namespace, authPolicies) {
+		authorized[server.GetName()] = true
+	}
+
+	coverage := []ServicePolicyCoverage{}
+	for _, svc := range services.Items {
+		servers, err := a.findServersForService(ctx, namespace, svc.Name)
+		if err != nil {
+			// Without the policy CRDs no Server can select the service
+			servers = []string{}
+		}
+
+		entry := ServicePolicyCoverage{
+			Service:           svc.Name,
+			Namespace:         namespace,
+			Servers:           servers,
+			AuthorizedServers: []string{},
+		}
+		for _, server := range servers {
+			if authorized[server] {
+				entry.AuthorizedServers = append(entry.AuthorizedServers, server)
+			}
+		}
+
+		entry.DefaultInboundPolicy, _ = a.resolveDefaultInboundPolicy(ctx, namespace, svc.Name)
+		for _, pod := range a.findServicePods(ctx, namespace, svc.Name) {
+			entry.TotalPods++
+			if isMeshedPod(pod) {
+				entry.MeshedPods++
+			}
+		}
+
+		entry.Protected = len(entry.AuthorizedServers) > 0 || entry.DefaultInboundPolicy == "deny"
+		coverage = append(coverage, entry)
+	}
+
+	sort.Slice(coverage, func(i, j int) bool { return coverage[i].Service < coverage[j].Service })
+	return coverage, nil
+}
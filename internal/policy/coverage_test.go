@@ -0,0 +1,98 @@
+package policy_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/christianhuening/linkerd-mcp/internal/policy"
+	"github.com/christianhuening/linkerd-mcp/internal/testutil"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/fake"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+)
+
+var _ = Describe("GetServicePolicyCoverage", func() {
+	var (
+		ctx           context.Context
+		kubeClient    *kubefake.Clientset
+		dynamicClient *fake.FakeDynamicClient
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+
+		scheme := runtime.NewScheme()
+		gvrToListKind := map[schema.GroupVersionResource]string{
+			serverGVR:              "ServerList",
+			authPolicyGVR:          "AuthorizationPolicyList",
+			meshTLSAuthGVR:         "MeshTLSAuthenticationList",
+			serverAuthorizationGVR: "ServerAuthorizationList",
+		}
+
+		kubeClient = kubefake.NewSimpleClientset(
+			namespaceWithDefaultPolicy("prod", ""),
+			testutil.CreateService("api", "prod", map[string]string{"app": "api"}),
+			testutil.CreateService("web", "prod", map[string]string{"app": "web"}),
+			testutil.CreateService("legacy", "prod", map[string]string{"app": "legacy"}),
+			testutil.CreateMeshedPod("api-1", "prod", "api"),
+			testutil.CreateMeshedPod("web-1", "prod", "web"),
+			testutil.CreatePod("legacy-1", "prod", "default", map[string]string{"app": "legacy"}, corev1.PodRunning, true),
+		)
+		dynamicClient = fake.NewSimpleDynamicClientWithCustomListKinds(scheme, gvrToListKind)
+
+		_, err := dynamicClient.Resource(serverGVR).Namespace("prod").Create(ctx,
+			testutil.CreateServer("api-server", "prod", map[string]string{"app": "api"}, 8080), metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		_, err = dynamicClient.Resource(authPolicyGVR).Namespace("prod").Create(ctx,
+			testutil.CreateAuthorizationPolicy("api-clients", "prod", "api-server",
+				[]map[string]string{{"name": "clients", "kind": "MeshTLSAuthentication"}}), metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred())
+
+		// A Server without any authorization doesn't protect the service
+		_, err = dynamicClient.Resource(serverGVR).Namespace("prod").Create(ctx,
+			testutil.CreateServer("web-server", "prod", map[string]string{"app": "web"}, 8080), metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should report the servers, authorization and meshed pods of each service", func() {
+		coverage, err := policy.NewAnalyzer(kubeClient, dynamicClient).GetServicePolicyCoverage(ctx, "prod")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(coverage).To(HaveLen(3))
+
+		api, legacy, web := coverage[0], coverage[1], coverage[2]
+		Expect(api.Service).To(Equal("api"))
+		Expect(api.Servers).To(ConsistOf("api-server"))
+		Expect(api.AuthorizedServers).To(ConsistOf("api-server"))
+		Expect(api.Protected).To(BeTrue())
+		Expect(api.MeshedPods).To(Equal(1))
+
+		Expect(legacy.Service).To(Equal("legacy"))
+		Expect(legacy.Servers).To(BeEmpty())
+		Expect(legacy.Protected).To(BeFalse())
+		Expect(legacy.TotalPods).To(Equal(1))
+		Expect(legacy.MeshedPods).To(BeZero())
+
+		Expect(web.Service).To(Equal("web"))
+		Expect(web.Servers).To(ConsistOf("web-server"))
+		Expect(web.AuthorizedServers).To(BeEmpty())
+		Expect(web.DefaultInboundPolicy).To(Equal("all-unauthenticated"))
+		Expect(web.Protected).To(BeFalse())
+	})
+
+	It("should count services as protected when the default policy denies traffic", func() {
+		_, err := kubeClient.CoreV1().Namespaces().Update(ctx, namespaceWithDefaultPolicy("prod", "deny"), metav1.UpdateOptions{})
+		Expect(err).NotTo(HaveOccurred())
+
+		coverage, err := policy.NewAnalyzer(kubeClient, dynamicClient).GetServicePolicyCoverage(ctx, "prod")
+		Expect(err).NotTo(HaveOccurred())
+		for _, entry := range coverage {
+			Expect(entry.DefaultInboundPolicy).To(Equal("deny"))
+			Expect(entry.Protected).To(BeTrue())
+		}
+	})
+})
@@ -0,0 +1,146 @@
+package policy_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/christianhuening/linkerd-mcp/internal/kube"
+	"github.com/christianhuening/linkerd-mcp/internal/policy"
+	"github.com/christianhuening/linkerd-mcp/internal/testutil"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/fake"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+)
+
+var _ = Describe("Cross-namespace policy targets", func() {
+	var (
+		ctx      context.Context
+		analyzer *policy.Analyzer
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+
+		scheme := runtime.NewScheme()
+		gvrToListKind := map[schema.GroupVersionResource]string{
+			serverGVR:              "ServerList",
+			authPolicyGVR:          "AuthorizationPolicyList",
+			meshTLSAuthGVR:         "MeshTLSAuthenticationList",
+			serverAuthorizationGVR: "ServerAuthorizationList",
+		}
+
+		kubeClient := kubefake.NewSimpleClientset(
+			testutil.CreatePod("frontend-1", "prod", "frontend-sa", map[string]string{"app": "frontend"}, "Running", true),
+		)
+		dynamicClient := fake.NewSimpleDynamicClientWithCustomListKinds(scheme, gvrToListKind)
+		analyzer = policy.NewAnalyzer(kubeClient, dynamicClient)
+
+		// Same-named Servers in two namespaces
+		for _, namespace := range []string{"prod", "staging"} {
+			server := testutil.CreateServer("api-server", namespace, map[string]string{"app": "api"}, 8080)
+			_, err := dynamicClient.Resource(serverGVR).Namespace(namespace).Create(ctx, server, metav1.CreateOptions{})
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		// A policy managed in the platform namespace grants frontend access to the prod Server
+		platformPolicy := testutil.CreateAuthorizationPolicy("prod-api-clients", "platform", "api-server",
+			[]map[string]string{{"name": "frontend-clients", "kind": "MeshTLSAuthentication"}})
+		Expect(unstructured.SetNestedField(platformPolicy.Object, "prod", "spec", "targetRef", "namespace")).To(Succeed())
+		_, err := dynamicClient.Resource(authPolicyGVR).Namespace("platform").Create(ctx, platformPolicy, metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = dynamicClient.Resource(meshTLSAuthGVR).Namespace("platform").Create(ctx,
+			testutil.CreateMeshTLSAuthentication("frontend-clients", "platform",
+				[]string{"frontend-sa.prod.serviceaccount.identity.linkerd.cluster.local"}, nil), metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred())
+
+		// A staging policy for its own api-server must not leak into prod
+		stagingPolicy := testutil.CreateAuthorizationPolicy("staging-api-clients", "staging", "api-server",
+			[]map[string]string{{"name": "tester-clients", "kind": "MeshTLSAuthentication"}})
+		_, err = dynamicClient.Resource(authPolicyGVR).Namespace("staging").Create(ctx, stagingPolicy, metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = dynamicClient.Resource(meshTLSAuthGVR).Namespace("staging").Create(ctx,
+			testutil.CreateMeshTLSAuthentication("tester-clients", "staging",
+				[]string{"*"}, nil), metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should associate a policy from another namespace with the Server it targets", func() {
+		result, err := analyzer.GetAllowedSources(ctx, "prod", "api", false)
+		Expect(err).NotTo(HaveOccurred())
+
+		var response map[string]interface{}
+		err = testutil.ParseJSONResult(result, &response)
+		Expect(err).NotTo(HaveOccurred())
+
+		allowedSources := response["allowedSources"].([]interface{})
+		Expect(allowedSources).To(HaveLen(1))
+		source := allowedSources[0].(map[string]interface{})
+		Expect(source["identity"]).To(Equal("frontend-sa.prod.serviceaccount.identity.linkerd.cluster.local"))
+		Expect(source["authorizationPolicy"]).To(Equal("prod-api-clients"))
+	})
+
+	It("should not confuse same-named Servers in different namespaces", func() {
+		result, err := analyzer.GetAllowedTargets(ctx, "prod", "frontend", false)
+		Expect(err).NotTo(HaveOccurred())
+
+		var response map[string]interface{}
+		err = testutil.ParseJSONResult(result, &response)
+		Expect(err).NotTo(HaveOccurred())
+
+		allowedTargets := response["allowedTargets"].([]interface{})
+		Expect(allowedTargets).To(HaveLen(2))
+
+		policiesByNamespace := map[string]string{}
+		for _, t := range allowedTargets {
+			target := t.(map[string]interface{})
+			policiesByNamespace[target["namespace"].(string)] = target["authorizationPolicy"].(string)
+		}
+		Expect(policiesByNamespace).To(Equal(map[string]string{
+			"prod":    "prod-api-clients",
+			"staging": "staging-api-clients",
+		}))
+	})
+
+	It("should keep the policies governing a namespace named explicitly, even where the namespace filter drops them", func() {
+		filter, err := kube.NewNamespaceFilter(nil, []string{"platform", "prod"})
+		Expect(err).NotTo(HaveOccurred())
+		analyzer.SetNamespaceFilter(filter)
+
+		result, err := analyzer.GetAllowedSources(ctx, "prod", "api", false)
+		Expect(err).NotTo(HaveOccurred())
+
+		var response map[string]interface{}
+		err = testutil.ParseJSONResult(result, &response)
+		Expect(err).NotTo(HaveOccurred())
+
+		allowedSources := response["allowedSources"].([]interface{})
+		Expect(allowedSources).To(HaveLen(1))
+		Expect(allowedSources[0].(map[string]interface{})["authorizationPolicy"]).To(Equal("prod-api-clients"))
+	})
+
+	It("should keep policies of filtered namespaces that target a covered one when analyzing all namespaces", func() {
+		filter, err := kube.NewNamespaceFilter(nil, []string{"platform", "staging"})
+		Expect(err).NotTo(HaveOccurred())
+		analyzer.SetNamespaceFilter(filter)
+
+		result, err := analyzer.GetAllowedTargets(ctx, "prod", "frontend", false)
+		Expect(err).NotTo(HaveOccurred())
+
+		var response map[string]interface{}
+		err = testutil.ParseJSONResult(result, &response)
+		Expect(err).NotTo(HaveOccurred())
+
+		allowedTargets := response["allowedTargets"].([]interface{})
+		Expect(allowedTargets).To(HaveLen(1))
+		target := allowedTargets[0].(map[string]interface{})
+		Expect(target["namespace"]).To(Equal("prod"))
+		Expect(target["authorizationPolicy"]).To(Equal("prod-api-clients"))
+	})
+})
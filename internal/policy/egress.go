@@ -0,0 +1,236 @@
+package policy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/yaml"
+)
+
+var egressNetworkGVR = schema.GroupVersionResource{
+	Group:    "policy.linkerd.io",
+	Version:  "v1alpha1",
+	Resource: "egressnetworks",
+}
+
+// egressRouteKinds are the Gateway API routes that can attach to an EgressNetwork
+var egressRouteKinds = []struct {
+	kind string
+	gvr  schema.GroupVersionResource
+}{
+	{"HTTPRoute", schema.GroupVersionResource{Group: "gateway.networking.k8s.io", Version: "v1", Resource: "httproutes"}},
+	{"GRPCRoute", schema.GroupVersionResource{Group: "gateway.networking.k8s.io", Version: "v1", Resource: "grpcroutes"}},
+	{"TLSRoute", schema.GroupVersionResource{Group: "gateway.networking.k8s.io", Version: "v1alpha2", Resource: "tlsroutes"}},
+	{"TCPRoute", schema.GroupVersionResource{Group: "gateway.networking.k8s.io", Version: "v1alpha2", Resource: "tcproutes"}},
+}
+
+const (
+	// defaultGlobalEgressNamespace holds the EgressNetworks that apply to clients in every
+	// namespace, unless linkerd-config sets egress.globalEgressNetworkNamespace
+	defaultGlobalEgressNamespace = "linkerd-egress"
+
+	egressScopeNamespace = "namespace"
+	egressScopeGlobal    = "global"
+)
+
+// EgressPolicy is what the meshed clients of a namespace may reach outside the cluster, from the
+// EgressNetworks of the namespace and of the global egress namespace
+type EgressPolicy struct {
+	Namespace             string              `json:"namespace"`
+	GlobalEgressNamespace string              `json:"globalEgressNamespace"`
+	EgressNetworks        []EgressNetworkInfo `json:"egressNetworks"`
+	// AllowedNetworks are the networks of Allow EgressNetworks, where all traffic is allowed
+	AllowedNetworks []string `json:"allowedNetworks"`
+	// DeniedNetworks are the networks of Deny EgressNetworks, where only the traffic routes match
+	// is allowed
+	DeniedNetworks []string `json:"deniedNetworks"`
+	// AllowedHosts are the hostnames of the routes attached to Deny EgressNetworks
+	AllowedHosts []string `json:"allowedHosts"`
+	// Unrestricted is set when no EgressNetwork applies, so Linkerd doesn't control the egress
+	// traffic of the namespace
+	Unrestricted bool `json:"unrestricted"`
+}
+
+// EgressNetworkInfo is one EgressNetwork that applies to a namespace
+type EgressNetworkInfo struct {
+	Name          string        `json:"name"`
+	Namespace     string        `json:"namespace"`
+	Scope         string        `json:"scope"` // "namespace" or "global"
+	TrafficPolicy string        `json:"trafficPolicy"`
+	Networks      []EgressCIDR  `json:"networks"` // every address outside the cluster when empty
+	Routes        []EgressRoute `json:"routes"`
+}
+
+// EgressCIDR is a network of an EgressNetwork and the subnets it leaves out
+type EgressCIDR struct {
+	CIDR   string   `json:"cidr"`
+	Except []string `json:"except,omitempty"`
+}
+
+// EgressRoute is a route attached to an EgressNetwork
+type EgressRoute struct {
+	Kind      string   `json:"kind"`
+	Name      string   `json:"name"`
+	Hostnames []string `json:"hostnames"` // every host when empty
+	Port      int64    `json:"port,omitempty"`
+}
+
+// GetEgressPolicy reports the external networks and hosts the meshed clients of a namespace may
+// reach. EgressNetworks in the namespace apply to its clients, those in the global egress
+// namespace to the clients of every namespace. An Allow EgressNetwork allows all traffic to its
+// networks; a Deny one only the traffic its routes match.
+func (a *Analyzer) GetEgressPolicy(ctx context.Context, namespace string) (*mcp.CallToolResult, error) {
+	ctx = withLookupCache(ctx)
+	globalNamespace := a.globalEgressNamespace(ctx)
+
+	report := EgressPolicy{
+		Namespace:             namespace,
+		GlobalEgressNamespace: globalNamespace,
+		EgressNetworks:        []EgressNetworkInfo{},
+		AllowedNetworks:       []string{},
+		DeniedNetworks:        []string{},
+		AllowedHosts:          []string{},
+	}
+
+	scopes := []struct{ namespace, scope string }{{namespace, egressScopeNamespace}}
+	if globalNamespace != namespace {
+		scopes = append(scopes, struct{ namespace, scope string }{globalNamespace, egressScopeGlobal})
+	}
+
+	for _, scope := range scopes {
+		networks, err := a.listResources(ctx, egressNetworkGVR, scope.namespace)
+		if apierrors.IsNotFound(err) {
+			return mcp.NewToolResultError("EgressNetworks aren't supported by this cluster: the egressnetworks.policy.linkerd.io CRD, added in Linkerd 2.17, isn't installed"), nil
+		}
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to list EgressNetworks in namespace %s: %v", scope.namespace, err)), nil
+		}
+		for _, network := range networks.Items {
+			report.EgressNetworks = append(report.EgressNetworks, a.egressNetworkInfo(ctx, network, scope.scope))
+		}
+	}
+
+	hosts := map[string]bool{}
+	for _, network := range report.EgressNetworks {
+		cidrs := []string{}
+		for _, cidr := range network.Networks {
+			cidrs = append(cidrs, cidr.CIDR)
+		}
+		if len(cidrs) == 0 {
+			cidrs = []string{"0.0.0.0/0", "::/0"}
+		}
+
+		switch network.TrafficPolicy {
+		case "Allow":
+			report.AllowedNetworks = append(report.AllowedNetworks, cidrs...)
+		case "Deny":
+			report.DeniedNetworks = append(report.DeniedNetworks, cidrs...)
+			for _, route := range network.Routes {
+				for _, hostname := range route.Hostnames {
+					hosts[hostname] = true
+				}
+			}
+		}
+	}
+	for hostname := range hosts {
+		report.AllowedHosts = append(report.AllowedHosts, hostname)
+	}
+	sort.Strings(report.AllowedHosts)
+	report.Unrestricted = len(report.EgressNetworks) == 0
+
+	resultJSON, _ := json.MarshalIndent(report, "", "  ")
+	return mcp.NewToolResultText(string(resultJSON)), nil
+}
+
+func (a *Analyzer) egressNetworkInfo(ctx context.Context, network unstructured.Unstructured, scope string) EgressNetworkInfo {
+	info := EgressNetworkInfo{
+		Name:      network.GetName(),
+		Namespace: network.GetNamespace(),
+		Scope:     scope,
+		Networks:  []EgressCIDR{},
+		Routes:    []EgressRoute{},
+	}
+	info.TrafficPolicy, _, _ = unstructured.NestedString(network.Object, "spec", "trafficPolicy")
+
+	networks, _, _ := unstructured.NestedSlice(network.Object, "spec", "networks")
+	for _, entry := range networks {
+		entryMap, ok := entry.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		cidr, _, _ := unstructured.NestedString(entryMap, "cidr")
+		except, _, _ := unstructured.NestedStringSlice(entryMap, "except")
+		info.Networks = append(info.Networks, EgressCIDR{CIDR: cidr, Except: except})
+	}
+
+	// Which route kinds are installed varies in Gateway API versions; the others have no routes
+	for _, routeKind := range egressRouteKinds {
+		routes, err := a.listResources(ctx, routeKind.gvr, network.GetNamespace())
+		if err != nil {
+			continue
+		}
+		for _, route := range routes.Items {
+			port, ok := egressParentPort(route, network.GetNamespace(), network.GetName())
+			if !ok {
+				continue
+			}
+			hostnames, _, _ := unstructured.NestedStringSlice(route.Object, "spec", "hostnames")
+			if hostnames == nil {
+				hostnames = []string{}
+			}
+			info.Routes = append(info.Routes, EgressRoute{Kind: routeKind.kind, Name: route.GetName(), Hostnames: hostnames, Port: port})
+		}
+	}
+	return info
+}
+
+// egressParentPort reports whether a route has the given EgressNetwork as a parent, and the port
+// of that parentRef, zero when it has none. A parentRef without a namespace refers to the route's
+// own namespace.
+func egressParentPort(route unstructured.Unstructured, namespace, name string) (int64, bool) {
+	parentRefs, _, _ := unstructured.NestedSlice(route.Object, "spec", "parentRefs")
+	for _, parentRef := range parentRefs {
+		refMap, ok := parentRef.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		kind, _, _ := unstructured.NestedString(refMap, "kind")
+		group, _, _ := unstructured.NestedString(refMap, "group")
+		refName, _, _ := unstructured.NestedString(refMap, "name")
+		refNamespace, _, _ := unstructured.NestedString(refMap, "namespace")
+		if refNamespace == "" {
+			refNamespace = route.GetNamespace()
+		}
+		if kind == "EgressNetwork" && group == egressNetworkGVR.Group && refName == name && refNamespace == namespace {
+			port, _, _ := unstructured.NestedInt64(refMap, "port")
+			return port, true
+		}
+	}
+	return 0, false
+}
+
+// globalEgressNamespace returns the namespace of the EgressNetworks that apply to every
+// namespace, from the egress.globalEgressNetworkNamespace value in linkerd-config
+func (a *Analyzer) globalEgressNamespace(ctx context.Context) string {
+	cm, err := a.clientset.CoreV1().ConfigMaps(a.controlPlaneNamespace).Get(ctx, linkerdConfigMapName, metav1.GetOptions{})
+	if err != nil {
+		return defaultGlobalEgressNamespace
+	}
+
+	var values struct {
+		Egress struct {
+			GlobalEgressNetworkNamespace string `json:"globalEgressNetworkNamespace"`
+		} `json:"egress"`
+	}
+	if err := yaml.Unmarshal([]byte(cm.Data["values"]), &values); err != nil || values.Egress.GlobalEgressNetworkNamespace == "" {
+		return defaultGlobalEgressNamespace
+	}
+	return values.Egress.GlobalEgressNetworkNamespace
+}
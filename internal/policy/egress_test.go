@@ -0,0 +1,123 @@
+package policy_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/christianhuening/linkerd-mcp/internal/policy"
+	"github.com/christianhuening/linkerd-mcp/internal/testutil"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/fake"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+var _ = Describe("GetEgressPolicy", func() {
+	var (
+		ctx           context.Context
+		kubeClient    *kubefake.Clientset
+		dynamicClient *fake.FakeDynamicClient
+	)
+
+	egressNetworkGVR := schema.GroupVersionResource{Group: "policy.linkerd.io", Version: "v1alpha1", Resource: "egressnetworks"}
+	tlsRouteGVR := schema.GroupVersionResource{Group: "gateway.networking.k8s.io", Version: "v1alpha2", Resource: "tlsroutes"}
+
+	egressPolicy := func(namespace string) policy.EgressPolicy {
+		result, err := policy.NewAnalyzer(kubeClient, dynamicClient).GetEgressPolicy(ctx, namespace)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.IsError).To(BeFalse())
+
+		var report policy.EgressPolicy
+		Expect(testutil.ParseJSONResult(result, &report)).To(Succeed())
+		return report
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+
+		gvrToListKind := map[schema.GroupVersionResource]string{
+			egressNetworkGVR: "EgressNetworkList",
+			tlsRouteGVR:      "TLSRouteList",
+			{Group: "gateway.networking.k8s.io", Version: "v1", Resource: "httproutes"}:      "HTTPRouteList",
+			{Group: "gateway.networking.k8s.io", Version: "v1", Resource: "grpcroutes"}:      "GRPCRouteList",
+			{Group: "gateway.networking.k8s.io", Version: "v1alpha2", Resource: "tcproutes"}: "TCPRouteList",
+		}
+		kubeClient = kubefake.NewSimpleClientset()
+		dynamicClient = fake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), gvrToListKind)
+	})
+
+	It("should report no restriction without EgressNetworks", func() {
+		report := egressPolicy("prod")
+
+		Expect(report.Unrestricted).To(BeTrue())
+		Expect(report.GlobalEgressNamespace).To(Equal("linkerd-egress"))
+		Expect(report.EgressNetworks).To(BeEmpty())
+	})
+
+	It("should combine the EgressNetworks of the namespace and the global namespace", func() {
+		_, err := dynamicClient.Resource(egressNetworkGVR).Namespace("prod").Create(ctx,
+			testutil.CreateEgressNetwork("payments", "prod", "Deny", nil), metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		_, err = dynamicClient.Resource(egressNetworkGVR).Namespace("linkerd-egress").Create(ctx,
+			testutil.CreateEgressNetwork("partners", "linkerd-egress", "Allow", []map[string]interface{}{
+				{"cidr": "203.0.113.0/24", "except": []interface{}{"203.0.113.128/25"}},
+			}), metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		_, err = dynamicClient.Resource(egressNetworkGVR).Namespace("staging").Create(ctx,
+			testutil.CreateEgressNetwork("staging-only", "staging", "Allow", nil), metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		_, err = dynamicClient.Resource(tlsRouteGVR).Namespace("prod").Create(ctx,
+			testutil.CreateTLSRoute("stripe", "prod", "payments", 443, []string{"api.stripe.com"}), metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred())
+
+		report := egressPolicy("prod")
+
+		Expect(report.Unrestricted).To(BeFalse())
+		Expect(report.EgressNetworks).To(HaveLen(2))
+		Expect(report.EgressNetworks[0]).To(Equal(policy.EgressNetworkInfo{
+			Name:          "payments",
+			Namespace:     "prod",
+			Scope:         "namespace",
+			TrafficPolicy: "Deny",
+			Networks:      []policy.EgressCIDR{},
+			Routes:        []policy.EgressRoute{{Kind: "TLSRoute", Name: "stripe", Hostnames: []string{"api.stripe.com"}, Port: 443}},
+		}))
+		Expect(report.EgressNetworks[1].Scope).To(Equal("global"))
+		Expect(report.EgressNetworks[1].Networks).To(Equal([]policy.EgressCIDR{{CIDR: "203.0.113.0/24", Except: []string{"203.0.113.128/25"}}}))
+		Expect(report.AllowedNetworks).To(Equal([]string{"203.0.113.0/24"}))
+		Expect(report.DeniedNetworks).To(Equal([]string{"0.0.0.0/0", "::/0"}))
+		Expect(report.AllowedHosts).To(Equal([]string{"api.stripe.com"}))
+	})
+
+	It("should read the global egress namespace from linkerd-config", func() {
+		kubeClient = kubefake.NewSimpleClientset(&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "linkerd-config", Namespace: "linkerd"},
+			Data:       map[string]string{"values": "egress:\n  globalEgressNetworkNamespace: egress-global\n"},
+		})
+		_, err := dynamicClient.Resource(egressNetworkGVR).Namespace("egress-global").Create(ctx,
+			testutil.CreateEgressNetwork("everything", "egress-global", "Allow", nil), metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred())
+
+		report := egressPolicy("prod")
+
+		Expect(report.GlobalEgressNamespace).To(Equal("egress-global"))
+		Expect(report.EgressNetworks).To(ConsistOf(HaveField("Name", "everything")))
+		Expect(report.AllowedNetworks).To(Equal([]string{"0.0.0.0/0", "::/0"}))
+	})
+
+	It("should explain a missing EgressNetwork CRD", func() {
+		dynamicClient.PrependReactor("list", "egressnetworks", func(action k8stesting.Action) (bool, runtime.Object, error) {
+			return true, nil, apierrors.NewNotFound(egressNetworkGVR.GroupResource(), "")
+		})
+
+		result, err := policy.NewAnalyzer(kubeClient, dynamicClient).GetEgressPolicy(ctx, "prod")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.IsError).To(BeTrue())
+	})
+})
@@ -0,0 +1,99 @@
+package policy
+
+import (
+	"context"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// Denial reasons reported when explain is requested
+const (
+	denialNoMatchingAuthorizationPolicy = "NoMatchingAuthorizationPolicy"
+	denialNoAuthenticationRefs          = "NoAuthenticationRefs"
+	denialIdentityNotAuthorized         = "IdentityNotAuthorized"
+	denialNetworkNotAuthorized          = "NetworkNotAuthorized"
+	denialAuthenticationNotFound        = "AuthenticationNotFound"
+	denialUnsupportedAuthenticationKind = "UnsupportedAuthenticationKind"
+)
+
+// authenticationGVR returns the resource for an authentication kind referenced by an AuthorizationPolicy
+func authenticationGVR(kind string) (schema.GroupVersionResource, bool) {
+	switch kind {
+	case "MeshTLSAuthentication":
+		return schema.GroupVersionResource{Group: "policy.linkerd.io", Version: "v1alpha1", Resource: "meshtlsauthentications"}, true
+	case "NetworkAuthentication":
+		return schema.GroupVersionResource{Group: "policy.linkerd.io", Version: "v1alpha1", Resource: "networkauthentications"}, true
+	default:
+		return schema.GroupVersionResource{}, false
+	}
+}
+
+// newDenial builds a single denial reason entry
+func newDenial(reason, message string) map[string]interface{} {
+	return map[string]interface{}{
+		"reason":  reason,
+		"message": message,
+	}
+}
+
+// noMatchingPolicyDenial explains that nothing authorizes traffic to a Server
+func noMatchingPolicyDenial(server string) map[string]interface{} {
+	return newDenial(denialNoMatchingAuthorizationPolicy,
+		fmt.Sprintf("No AuthorizationPolicy or ServerAuthorization targets Server %s", server))
+}
+
+// explainAuthRefs explains why an AuthorizationPolicy grants nothing to a source. When
+// matchSource is false only missing or unusable auth refs are reported, since there is no
+// specific source to compare against.
+func (a *Analyzer) explainAuthRefs(ctx context.Context, policy unstructured.Unstructured, matchSource bool) []map[string]interface{} {
+	policyName := policy.GetName()
+
+	requiredAuths, found, err := unstructured.NestedSlice(policy.Object, "spec", "requiredAuthenticationRefs")
+	if err != nil || !found || len(requiredAuths) == 0 {
+		denial := newDenial(denialNoAuthenticationRefs,
+			fmt.Sprintf("AuthorizationPolicy %s has no requiredAuthenticationRefs", policyName))
+		denial["authorizationPolicy"] = policyName
+		return []map[string]interface{}{denial}
+	}
+
+	denials := []map[string]interface{}{}
+	for _, authRef := range requiredAuths {
+		authMap, ok := authRef.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		authName, _, _ := unstructured.NestedString(authMap, "name")
+		authKind, _, _ := unstructured.NestedString(authMap, "kind")
+		authNamespace := authRefNamespace(authMap, policy)
+
+		var denial map[string]interface{}
+		authGVR, ok := authenticationGVR(authKind)
+		if !ok {
+			denial = newDenial(denialUnsupportedAuthenticationKind,
+				fmt.Sprintf("Authentication kind %q is not evaluated", authKind))
+		} else if _, err := a.getResource(ctx, authGVR, authNamespace, authName); err != nil {
+			if !apierrors.IsNotFound(err) {
+				continue
+			}
+			denial = newDenial(denialAuthenticationNotFound,
+				fmt.Sprintf("%s %s/%s does not exist", authKind, authNamespace, authName))
+		} else if matchSource && authKind == "NetworkAuthentication" {
+			denial = newDenial(denialNetworkNotAuthorized,
+				fmt.Sprintf("%s %s does not include the IPs of all source pods", authKind, authName))
+		} else if matchSource {
+			denial = newDenial(denialIdentityNotAuthorized,
+				fmt.Sprintf("%s %s does not include the source identity", authKind, authName))
+		} else {
+			continue
+		}
+
+		denial["authorizationPolicy"] = policyName
+		denial["authenticationRef"] = map[string]string{"kind": authKind, "name": authName}
+		denials = append(denials, denial)
+	}
+
+	return denials
+}
@@ -0,0 +1,153 @@
+package policy_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/christianhuening/linkerd-mcp/internal/policy"
+	"github.com/christianhuening/linkerd-mcp/internal/testutil"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/fake"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+)
+
+// reasonsByServer indexes the denial reasons of a deniedTargets/deniedSources array by Server name
+func reasonsByServer(denied []interface{}) map[string][]string {
+	reasons := map[string][]string{}
+	for _, entry := range denied {
+		deniedEntry := entry.(map[string]interface{})
+		server := deniedEntry["server"].(string)
+		for _, reason := range deniedEntry["reasons"].([]interface{}) {
+			reasons[server] = append(reasons[server], reason.(map[string]interface{})["reason"].(string))
+		}
+	}
+	return reasons
+}
+
+var _ = Describe("Denial explanations", func() {
+	var (
+		ctx      context.Context
+		analyzer *policy.Analyzer
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+
+		scheme := runtime.NewScheme()
+		gvrToListKind := map[schema.GroupVersionResource]string{
+			serverGVR:              "ServerList",
+			authPolicyGVR:          "AuthorizationPolicyList",
+			meshTLSAuthGVR:         "MeshTLSAuthenticationList",
+			serverAuthorizationGVR: "ServerAuthorizationList",
+		}
+
+		kubeClient := kubefake.NewSimpleClientset(
+			testutil.CreatePod("frontend-1", "prod", "frontend-sa", map[string]string{"app": "frontend"}, "Running", true),
+		)
+		dynamicClient := fake.NewSimpleDynamicClientWithCustomListKinds(scheme, gvrToListKind)
+		analyzer = policy.NewAnalyzer(kubeClient, dynamicClient)
+
+		for _, app := range []string{"web", "api", "db", "cache"} {
+			server := testutil.CreateServer(app+"-server", "prod", map[string]string{"app": app}, 8080)
+			_, err := dynamicClient.Resource(serverGVR).Namespace("prod").Create(ctx, server, metav1.CreateOptions{})
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		for server, auth := range map[string]string{
+			"web-server": "frontend-auth",
+			"api-server": "admin-auth",
+			"db-server":  "missing-auth",
+		} {
+			authPolicy := testutil.CreateAuthorizationPolicy(
+				"allow-"+server,
+				"prod",
+				server,
+				[]map[string]string{{"name": auth, "kind": "MeshTLSAuthentication"}},
+			)
+			_, err := dynamicClient.Resource(authPolicyGVR).Namespace("prod").Create(ctx, authPolicy, metav1.CreateOptions{})
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		for name, identity := range map[string]string{
+			"frontend-auth": "frontend-sa.prod.serviceaccount.identity.linkerd.cluster.local",
+			"admin-auth":    "admin-sa.prod.serviceaccount.identity.linkerd.cluster.local",
+		} {
+			meshAuth := testutil.CreateMeshTLSAuthentication(name, "prod", []string{identity}, nil)
+			_, err := dynamicClient.Resource(meshTLSAuthGVR).Namespace("prod").Create(ctx, meshAuth, metav1.CreateOptions{})
+			Expect(err).NotTo(HaveOccurred())
+		}
+	})
+
+	Describe("GetAllowedTargets", func() {
+		It("should explain why each unreachable Server denies the source", func() {
+			result, err := analyzer.GetAllowedTargets(ctx, "prod", "frontend", true)
+			Expect(err).NotTo(HaveOccurred())
+
+			var response map[string]interface{}
+			err = testutil.ParseJSONResult(result, &response)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(response["allowedTargets"]).To(HaveLen(1))
+
+			reasons := reasonsByServer(response["deniedTargets"].([]interface{}))
+			Expect(reasons).To(HaveLen(3))
+			Expect(reasons["api-server"]).To(ConsistOf("IdentityNotAuthorized"))
+			Expect(reasons["db-server"]).To(ConsistOf("AuthenticationNotFound"))
+			Expect(reasons["cache-server"]).To(ConsistOf("NoMatchingAuthorizationPolicy"))
+		})
+
+		It("should omit denied targets when explain is not set", func() {
+			result, err := analyzer.GetAllowedTargets(ctx, "prod", "frontend", false)
+			Expect(err).NotTo(HaveOccurred())
+
+			var response map[string]interface{}
+			err = testutil.ParseJSONResult(result, &response)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(response).NotTo(HaveKey("deniedTargets"))
+		})
+	})
+
+	Describe("GetAllowedSources", func() {
+		It("should report a missing authentication resource", func() {
+			result, err := analyzer.GetAllowedSources(ctx, "prod", "db", true)
+			Expect(err).NotTo(HaveOccurred())
+
+			var response map[string]interface{}
+			err = testutil.ParseJSONResult(result, &response)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(response["allowedSources"]).To(BeEmpty())
+			reasons := reasonsByServer(response["deniedSources"].([]interface{}))
+			Expect(reasons["db-server"]).To(ConsistOf("AuthenticationNotFound"))
+		})
+
+		It("should report a Server without any authorization", func() {
+			result, err := analyzer.GetAllowedSources(ctx, "prod", "cache", true)
+			Expect(err).NotTo(HaveOccurred())
+
+			var response map[string]interface{}
+			err = testutil.ParseJSONResult(result, &response)
+			Expect(err).NotTo(HaveOccurred())
+
+			reasons := reasonsByServer(response["deniedSources"].([]interface{}))
+			Expect(reasons["cache-server"]).To(ConsistOf("NoMatchingAuthorizationPolicy"))
+		})
+
+		It("should not report denials for a fully authorized Server", func() {
+			result, err := analyzer.GetAllowedSources(ctx, "prod", "web", true)
+			Expect(err).NotTo(HaveOccurred())
+
+			var response map[string]interface{}
+			err = testutil.ParseJSONResult(result, &response)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(response["allowedSources"]).To(HaveLen(1))
+			Expect(response["deniedSources"]).To(BeEmpty())
+		})
+	})
+})
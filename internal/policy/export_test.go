@@ -0,0 +1,8 @@
+package policy
+
+import "context"
+
+// DisableLookupCache returns a context on which analysis calls bypass the per-call lookup cache
+func DisableLookupCache(ctx context.Context) context.Context {
+	return context.WithValue(ctx, lookupCacheKey{}, (*lookupCache)(nil))
+}
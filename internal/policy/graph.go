@@ -0,0 +1,201 @@
+package policy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// GraphNode is a source identity or a target Server in the authorization graph
+type GraphNode struct {
+	ID        string `json:"id"`
+	Kind      string `json:"kind"` // "server", "identity", "serviceAccount", "wildcard", "network" or "unauthenticated"
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name,omitempty"`
+}
+
+// GraphEdge is an authorization from a source to a target Server
+type GraphEdge struct {
+	Source               string `json:"source"`
+	Target               string `json:"target"`
+	AuthorizationPolicy  string `json:"authorizationPolicy,omitempty"`
+	ServerAuthorization  string `json:"serverAuthorization,omitempty"`
+	AuthenticationPolicy string `json:"authenticationPolicy,omitempty"`
+	AuthKind             string `json:"authKind"`
+	Wildcard             bool   `json:"wildcard"` // true when the edge admits all (authenticated) clients
+}
+
+// PolicyGraph is the directed source → Server authorization graph
+type PolicyGraph struct {
+	Namespace  string      `json:"namespace"`
+	Nodes      []GraphNode `json:"nodes"`
+	Edges      []GraphEdge `json:"edges"`
+	TotalEdges int         `json:"totalEdges"`
+}
+
+// ExportPolicyGraph walks all Servers, AuthorizationPolicies, legacy ServerAuthorizations and
+// authentication resources in a namespace (or the whole cluster when namespace is empty) and
+// returns the authorization graph as JSON, or as Graphviz DOT when format is "dot"
+func (a *Analyzer) ExportPolicyGraph(ctx context.Context, namespace, format string) (*mcp.CallToolResult, error) {
+	if format != "" && format != "json" && format != "dot" {
+		return mcp.NewToolResultError("Invalid format. Must be one of: json, dot"), nil
+	}
+
+	ctx = withLookupCache(ctx)
+	graph, err := a.buildPolicyGraph(ctx, namespace)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	if format == "dot" {
+		return mcp.NewToolResultText(graph.DOT()), nil
+	}
+
+	resultJSON, _ := json.MarshalIndent(graph, "", "  ")
+	return mcp.NewToolResultText(string(resultJSON)), nil
+}
+
+// buildPolicyGraph collects the authorization edges of every Server in scope
+func (a *Analyzer) buildPolicyGraph(ctx context.Context, namespace string) (*PolicyGraph, error) {
+	servers, err := a.listResources(ctx, serverGVR, namespace)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list Servers: %v (ensure Linkerd policy CRDs are installed)", err)
+	}
+
+	authPolicies, _ := a.policiesTargetingNamespace(ctx, namespace)
+
+	nodes := map[string]GraphNode{}
+	edges := []GraphEdge{}
+
+	for _, server := range servers.Items {
+		serverNamespace := server.GetNamespace()
+		serverID := fmt.Sprintf("%s/%s", serverNamespace, server.GetName())
+		nodes[serverID] = GraphNode{
+			ID:        serverID,
+			Kind:      "server",
+			Namespace: serverNamespace,
+			Name:      server.GetName(),
+		}
+
+		for _, policy := range authPolicies {
+			if !policyTargetsServer(policy, serverNamespace, server.GetName()) {
+				continue
+			}
+
+			requiredAuths, _, _ := unstructured.NestedSlice(policy.Object, "spec", "requiredAuthenticationRefs")
+			for _, authRef := range requiredAuths {
+				authMap, ok := authRef.(map[string]interface{})
+				if !ok {
+					continue
+				}
+				authName, _, _ := unstructured.NestedString(authMap, "name")
+				authKind, _, _ := unstructured.NestedString(authMap, "kind")
+
+				sources := a.extractSourcesFromAuth(ctx, authRefNamespace(authMap, policy), authName, authKind, policy.GetName())
+				for key, source := range sources {
+					node := sourceNode(key, source)
+					nodes[node.ID] = node
+					edges = append(edges, GraphEdge{
+						Source:               node.ID,
+						Target:               serverID,
+						AuthorizationPolicy:  policy.GetName(),
+						AuthenticationPolicy: authName,
+						AuthKind:             authKind,
+						Wildcard:             isWildcardNode(node),
+					})
+				}
+			}
+		}
+
+		for _, serverAuth := range a.listServerAuthorizations(ctx, serverNamespace) {
+			if !serverAuthorizationTargets(serverAuth, server) {
+				continue
+			}
+			for key, source := range extractSourcesFromServerAuthorization(serverAuth) {
+				node := sourceNode(key, source)
+				nodes[node.ID] = node
+				edges = append(edges, GraphEdge{
+					Source:              node.ID,
+					Target:              serverID,
+					ServerAuthorization: serverAuth.GetName(),
+					AuthKind:            "ServerAuthorization",
+					Wildcard:            isWildcardNode(node),
+				})
+			}
+		}
+	}
+
+	graph := &PolicyGraph{
+		Namespace:  namespace,
+		Nodes:      make([]GraphNode, 0, len(nodes)),
+		Edges:      edges,
+		TotalEdges: len(edges),
+	}
+	for _, node := range nodes {
+		graph.Nodes = append(graph.Nodes, node)
+	}
+
+	sort.Slice(graph.Nodes, func(i, j int) bool { return graph.Nodes[i].ID < graph.Nodes[j].ID })
+	sort.SliceStable(graph.Edges, func(i, j int) bool {
+		if graph.Edges[i].Target != graph.Edges[j].Target {
+			return graph.Edges[i].Target < graph.Edges[j].Target
+		}
+		return graph.Edges[i].Source < graph.Edges[j].Source
+	})
+
+	return graph, nil
+}
+
+// sourceNode converts a source extracted by extractSourcesFromAuth or
+// extractSourcesFromServerAuthorization into a graph node
+func sourceNode(key string, source map[string]interface{}) GraphNode {
+	if sourceType, ok := source["type"].(string); ok {
+		return GraphNode{ID: key, Kind: sourceType}
+	}
+	if sa, ok := source["serviceAccount"].(string); ok {
+		namespace, _ := source["namespace"].(string)
+		return GraphNode{ID: key, Kind: "serviceAccount", Namespace: namespace, Name: sa}
+	}
+	return GraphNode{ID: key, Kind: "identity"}
+}
+
+// isWildcardNode reports whether a source admits every client rather than a specific identity
+func isWildcardNode(node GraphNode) bool {
+	return node.Kind == "wildcard" || node.Kind == "unauthenticated"
+}
+
+// DOT renders the graph in Graphviz DOT format. Wildcard edges are drawn dashed and red.
+func (g *PolicyGraph) DOT() string {
+	var b strings.Builder
+	b.WriteString("digraph linkerd_policy {\n")
+	b.WriteString("  rankdir=LR;\n")
+
+	for _, node := range g.Nodes {
+		shape := "ellipse"
+		if node.Kind == "server" {
+			shape = "box"
+		}
+		fmt.Fprintf(&b, "  %s [shape=%s];\n", strconv.Quote(node.ID), shape)
+	}
+
+	for _, edge := range g.Edges {
+		policy := edge.AuthorizationPolicy
+		if policy == "" {
+			policy = edge.ServerAuthorization
+		}
+		attrs := fmt.Sprintf("label=%s", strconv.Quote(fmt.Sprintf("%s (%s)", policy, edge.AuthKind)))
+		if edge.Wildcard {
+			attrs += ", style=dashed, color=red"
+		}
+		fmt.Fprintf(&b, "  %s -> %s [%s];\n", strconv.Quote(edge.Source), strconv.Quote(edge.Target), attrs)
+	}
+
+	b.WriteString("}\n")
+	return b.String()
+}
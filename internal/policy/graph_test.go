@@ -0,0 +1,117 @@
+package policy_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/christianhuening/linkerd-mcp/internal/policy"
+	"github.com/christianhuening/linkerd-mcp/internal/testutil"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/fake"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+)
+
+var _ = Describe("ExportPolicyGraph", func() {
+	var (
+		ctx      context.Context
+		analyzer *policy.Analyzer
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+
+		scheme := runtime.NewScheme()
+		gvrToListKind := map[schema.GroupVersionResource]string{
+			serverGVR:              "ServerList",
+			authPolicyGVR:          "AuthorizationPolicyList",
+			meshTLSAuthGVR:         "MeshTLSAuthenticationList",
+			serverAuthorizationGVR: "ServerAuthorizationList",
+		}
+
+		dynamicClient := fake.NewSimpleDynamicClientWithCustomListKinds(scheme, gvrToListKind)
+		analyzer = policy.NewAnalyzer(kubefake.NewSimpleClientset(), dynamicClient)
+
+		for _, server := range []struct{ name, app string }{{"api-server", "api"}, {"public-server", "public"}, {"legacy-server", "legacy"}} {
+			_, err := dynamicClient.Resource(serverGVR).Namespace("prod").Create(ctx,
+				testutil.CreateServer(server.name, "prod", map[string]string{"app": server.app}, 8080), metav1.CreateOptions{})
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		for server, auth := range map[string]string{"api-server": "frontend-auth", "public-server": "any-auth"} {
+			_, err := dynamicClient.Resource(authPolicyGVR).Namespace("prod").Create(ctx,
+				testutil.CreateAuthorizationPolicy("allow-"+server, "prod", server,
+					[]map[string]string{{"name": auth, "kind": "MeshTLSAuthentication"}}), metav1.CreateOptions{})
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		_, err := dynamicClient.Resource(meshTLSAuthGVR).Namespace("prod").Create(ctx,
+			testutil.CreateMeshTLSAuthentication("frontend-auth", "prod",
+				[]string{"frontend-sa.prod.serviceaccount.identity.linkerd.cluster.local"},
+				[]map[string]string{{"name": "batch-sa", "namespace": "jobs"}}), metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = dynamicClient.Resource(meshTLSAuthGVR).Namespace("prod").Create(ctx,
+			testutil.CreateMeshTLSAuthentication("any-auth", "prod", []string{"*"}, nil), metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = dynamicClient.Resource(serverAuthorizationGVR).Namespace("prod").Create(ctx,
+			testutil.CreateServerAuthorization("legacy-clients", "prod", "legacy-server",
+				[]string{"frontend-sa.prod.serviceaccount.identity.linkerd.cluster.local"}, nil), metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should emit one annotated edge per authorized source and Server", func() {
+		result, err := analyzer.ExportPolicyGraph(ctx, "prod", "")
+		Expect(err).NotTo(HaveOccurred())
+
+		var graph map[string]interface{}
+		err = testutil.ParseJSONResult(result, &graph)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(graph["totalEdges"]).To(BeNumerically("==", 4))
+		Expect(graph["nodes"]).To(HaveLen(6))
+
+		edgesByTarget := map[string][]map[string]interface{}{}
+		for _, e := range graph["edges"].([]interface{}) {
+			edge := e.(map[string]interface{})
+			edgesByTarget[edge["target"].(string)] = append(edgesByTarget[edge["target"].(string)], edge)
+		}
+
+		Expect(edgesByTarget["prod/api-server"]).To(HaveLen(2))
+		for _, edge := range edgesByTarget["prod/api-server"] {
+			Expect(edge["authorizationPolicy"]).To(Equal("allow-api-server"))
+			Expect(edge["authKind"]).To(Equal("MeshTLSAuthentication"))
+			Expect(edge["wildcard"]).To(BeFalse())
+		}
+
+		Expect(edgesByTarget["prod/public-server"]).To(HaveLen(1))
+		Expect(edgesByTarget["prod/public-server"][0]["source"]).To(Equal("all-authenticated"))
+		Expect(edgesByTarget["prod/public-server"][0]["wildcard"]).To(BeTrue())
+
+		Expect(edgesByTarget["prod/legacy-server"]).To(HaveLen(1))
+		Expect(edgesByTarget["prod/legacy-server"][0]["serverAuthorization"]).To(Equal("legacy-clients"))
+		Expect(edgesByTarget["prod/legacy-server"][0]["authKind"]).To(Equal("ServerAuthorization"))
+	})
+
+	It("should render DOT with wildcard edges marked", func() {
+		result, err := analyzer.ExportPolicyGraph(ctx, "prod", "dot")
+		Expect(err).NotTo(HaveOccurred())
+
+		var dot string
+		err = testutil.GetTextFromResult(result, &dot)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(dot).To(HavePrefix("digraph linkerd_policy {"))
+		Expect(dot).To(ContainSubstring(`"jobs/batch-sa" -> "prod/api-server" [label="allow-api-server (MeshTLSAuthentication)"];`))
+		Expect(dot).To(ContainSubstring(`"all-authenticated" -> "prod/public-server" [label="allow-public-server (MeshTLSAuthentication)", style=dashed, color=red];`))
+	})
+
+	It("should reject an unknown format", func() {
+		result, err := analyzer.ExportPolicyGraph(ctx, "prod", "yaml")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.IsError).To(BeTrue())
+	})
+})
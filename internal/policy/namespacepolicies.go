@@ -0,0 +1,102 @@
+package policy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// injectAnnotation enables or disables proxy injection for the workloads of a namespace
+const injectAnnotation = "linkerd.io/inject"
+
+// NamespacePolicies is the policy configuration of a namespace: its default inbound policy, its
+// proxy injection setting and how many policy resources it holds
+type NamespacePolicies struct {
+	Namespace             string `json:"namespace"`
+	DefaultInboundPolicy  string `json:"defaultInboundPolicy"`
+	DefaultPolicySource   string `json:"defaultPolicySource"` // "namespace" or "cluster"
+	Audit                 bool   `json:"audit"`               // traffic the policy would deny is only logged
+	ProxyInject           string `json:"proxyInject"`         // the linkerd.io/inject annotation, empty when unset
+	Servers               int    `json:"servers"`
+	AuthorizationPolicies int    `json:"authorizationPolicies"`
+	ServerAuthorizations  int    `json:"serverAuthorizations"`
+}
+
+// GetNamespacePolicies lists, for each namespace (or only the given one), the default inbound
+// policy from its config.linkerd.io/default-inbound-policy annotation or else the cluster
+// default, its linkerd.io/inject setting, and its number of Servers, AuthorizationPolicies and
+// ServerAuthorizations
+func (a *Analyzer) GetNamespacePolicies(ctx context.Context, namespace string) (*mcp.CallToolResult, error) {
+	ctx = withLookupCache(ctx)
+	clusterDefault := a.clusterDefaultInboundPolicy(ctx)
+
+	namespaces := []corev1.Namespace{}
+	if namespace != "" {
+		ns, err := a.clientset.CoreV1().Namespaces().Get(ctx, namespace, metav1.GetOptions{})
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to get namespace %s: %v", namespace, err)), nil
+		}
+		namespaces = append(namespaces, *ns)
+	} else {
+		list, err := a.clientset.CoreV1().Namespaces().List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to list namespaces: %v", err)), nil
+		}
+		namespaces = a.namespaceFilter.Namespaces(list.Items)
+	}
+
+	servers := a.countByNamespace(ctx, serverGVR, namespace, "Servers")
+	authPolicies := a.countByNamespace(ctx, authPolicyGVR, namespace, "AuthorizationPolicies")
+	serverAuths := a.countByNamespace(ctx, serverAuthorizationGVR, namespace, "ServerAuthorizations")
+
+	policies := []NamespacePolicies{}
+	for _, ns := range namespaces {
+		entry := NamespacePolicies{
+			Namespace:             ns.Name,
+			DefaultInboundPolicy:  clusterDefault,
+			DefaultPolicySource:   defaultPolicySourceCluster,
+			ProxyInject:           ns.Annotations[injectAnnotation],
+			Servers:               servers[ns.Name],
+			AuthorizationPolicies: authPolicies[ns.Name],
+			ServerAuthorizations:  serverAuths[ns.Name],
+		}
+		if policy := ns.Annotations[defaultInboundPolicyAnnotation]; policy != "" {
+			entry.DefaultInboundPolicy = policy
+			entry.DefaultPolicySource = defaultPolicySourceNamespace
+		}
+		entry.Audit = entry.DefaultInboundPolicy == auditInboundPolicy
+		policies = append(policies, entry)
+	}
+
+	sort.Slice(policies, func(i, j int) bool { return policies[i].Namespace < policies[j].Namespace })
+
+	result := map[string]interface{}{
+		"clusterDefaultInboundPolicy": clusterDefault,
+		"namespaces":                  policies,
+	}
+
+	resultJSON, _ := json.MarshalIndent(result, "", "  ")
+	return mcp.NewToolResultText(string(resultJSON)), nil
+}
+
+// countByNamespace counts a policy resource per namespace. A resource that can't be listed, such
+// as one whose CRD isn't installed, counts as none.
+func (a *Analyzer) countByNamespace(ctx context.Context, gvr schema.GroupVersionResource, namespace, kind string) map[string]int {
+	counts := map[string]int{}
+	list, err := a.listResources(ctx, gvr, namespace)
+	if err != nil {
+		log.Printf("Warning: Failed to list %s: %v", kind, err)
+		return counts
+	}
+	for _, item := range list.Items {
+		counts[item.GetNamespace()]++
+	}
+	return counts
+}
@@ -0,0 +1,112 @@
+package policy_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/christianhuening/linkerd-mcp/internal/policy"
+	"github.com/christianhuening/linkerd-mcp/internal/testutil"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/fake"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+)
+
+var _ = Describe("GetNamespacePolicies", func() {
+	var (
+		ctx           context.Context
+		kubeClient    *kubefake.Clientset
+		dynamicClient *fake.FakeDynamicClient
+	)
+
+	namespacePolicies := func(namespace string) map[string]policy.NamespacePolicies {
+		result, err := policy.NewAnalyzer(kubeClient, dynamicClient).GetNamespacePolicies(ctx, namespace)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.IsError).To(BeFalse())
+
+		var response struct {
+			Namespaces []policy.NamespacePolicies `json:"namespaces"`
+		}
+		Expect(testutil.ParseJSONResult(result, &response)).To(Succeed())
+
+		byNamespace := map[string]policy.NamespacePolicies{}
+		for _, entry := range response.Namespaces {
+			byNamespace[entry.Namespace] = entry
+		}
+		return byNamespace
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+
+		gvrToListKind := map[schema.GroupVersionResource]string{
+			serverGVR:              "ServerList",
+			authPolicyGVR:          "AuthorizationPolicyList",
+			serverAuthorizationGVR: "ServerAuthorizationList",
+		}
+
+		prod := namespaceWithDefaultPolicy("prod", "deny")
+		prod.Annotations["linkerd.io/inject"] = "enabled"
+		kubeClient = kubefake.NewSimpleClientset(
+			prod,
+			namespaceWithDefaultPolicy("staging", "audit"),
+			namespaceWithDefaultPolicy("sandbox", ""),
+		)
+		dynamicClient = fake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), gvrToListKind)
+
+		for _, server := range []string{"api-server", "admin-server"} {
+			_, err := dynamicClient.Resource(serverGVR).Namespace("prod").Create(ctx,
+				testutil.CreateServer(server, "prod", map[string]string{"app": "api"}, 8080), metav1.CreateOptions{})
+			Expect(err).NotTo(HaveOccurred())
+		}
+		_, err := dynamicClient.Resource(authPolicyGVR).Namespace("prod").Create(ctx,
+			testutil.CreateAuthorizationPolicy("api-clients", "prod", "api-server",
+				[]map[string]string{{"name": "clients", "kind": "MeshTLSAuthentication"}}), metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should report the default policy, injection and policy resources of each namespace", func() {
+		byNamespace := namespacePolicies("")
+
+		Expect(byNamespace).To(HaveLen(3))
+		Expect(byNamespace["prod"]).To(Equal(policy.NamespacePolicies{
+			Namespace:             "prod",
+			DefaultInboundPolicy:  "deny",
+			DefaultPolicySource:   "namespace",
+			ProxyInject:           "enabled",
+			Servers:               2,
+			AuthorizationPolicies: 1,
+		}))
+
+		Expect(byNamespace["staging"].DefaultInboundPolicy).To(Equal("audit"))
+		Expect(byNamespace["staging"].Audit).To(BeTrue())
+
+		Expect(byNamespace["sandbox"].DefaultInboundPolicy).To(Equal("all-unauthenticated"))
+		Expect(byNamespace["sandbox"].DefaultPolicySource).To(Equal("cluster"))
+		Expect(byNamespace["sandbox"].ProxyInject).To(BeEmpty())
+		Expect(byNamespace["sandbox"].Servers).To(BeZero())
+	})
+
+	It("should fall back to the cluster default from linkerd-config", func() {
+		_, err := kubeClient.CoreV1().ConfigMaps("linkerd").Create(ctx, &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "linkerd-config", Namespace: "linkerd"},
+			Data:       map[string]string{"values": "proxy:\n  defaultInboundPolicy: cluster-authenticated\n"},
+		}, metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred())
+
+		byNamespace := namespacePolicies("sandbox")
+
+		Expect(byNamespace).To(HaveLen(1))
+		Expect(byNamespace["sandbox"].DefaultInboundPolicy).To(Equal("cluster-authenticated"))
+	})
+
+	It("should fail for a namespace that doesn't exist", func() {
+		result, err := policy.NewAnalyzer(kubeClient, dynamicClient).GetNamespacePolicies(ctx, "missing")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.IsError).To(BeTrue())
+	})
+})
@@ -0,0 +1,166 @@
+package policy
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// serverPort returns the port a Server governs: a port number, or the name of a container port
+func serverPort(server unstructured.Unstructured) interface{} {
+	port, found, _ := unstructured.NestedFieldNoCopy(server.Object, "spec", "port")
+	if !found {
+		return nil
+	}
+	switch value := port.(type) {
+	case int64:
+		return value
+	case float64:
+		return int64(value)
+	default:
+		return value
+	}
+}
+
+// serverPorts resolves the port of each Server, preserving the order of serverNames. Servers that
+// can't be fetched have no port.
+func (a *Analyzer) serverPorts(ctx context.Context, namespace string, serverNames []string) []map[string]interface{} {
+	servers := []map[string]interface{}{}
+	for _, serverName := range serverNames {
+		var port interface{}
+		if server, err := a.getResource(ctx, serverGVR, namespace, serverName); err == nil {
+			port = serverPort(*server)
+		}
+		servers = append(servers, map[string]interface{}{
+			"name": serverName,
+			"port": port,
+		})
+	}
+	return servers
+}
+
+// serverPolicies returns the AuthorizationPolicies and legacy ServerAuthorizations targeting any
+// of the given Servers, whether or not they grant anything
+func (a *Analyzer) serverPolicies(ctx context.Context, namespace string, serverNames []string) []string {
+	policies := []string{}
+	seen := map[string]bool{}
+	add := func(name string) {
+		if !seen[name] {
+			seen[name] = true
+			policies = append(policies, name)
+		}
+	}
+
+	if authPolicies, err := a.policiesTargetingNamespace(ctx, namespace); err == nil {
+		for _, policy := range authPolicies {
+			for _, serverName := range serverNames {
+				if policyTargetsServer(policy, namespace, serverName) {
+					add(policy.GetName())
+					break
+				}
+			}
+		}
+	}
+
+	serverAuths := a.listServerAuthorizations(ctx, namespace)
+	for _, serverName := range serverNames {
+		if len(serverAuths) == 0 {
+			break
+		}
+		server, err := a.getResource(ctx, serverGVR, namespace, serverName)
+		if err != nil {
+			continue
+		}
+		for _, serverAuth := range serverAuths {
+			if serverAuthorizationTargets(serverAuth, *server) {
+				add(serverAuth.GetName())
+			}
+		}
+	}
+
+	sort.Strings(policies)
+	return policies
+}
+
+// serverSources returns the sources the given Servers admit, through explicit policies and through
+// their accessPolicy, and whether any of them only admits traffic in audit mode
+func (a *Analyzer) serverSources(ctx context.Context, namespace string, serverNames []string, explain bool) ([]map[string]interface{}, []map[string]interface{}, bool, error) {
+	allowedSources, deniedSources, err := a.findAllowedSources(ctx, namespace, serverNames, explain)
+	if err != nil {
+		return nil, nil, false, err
+	}
+
+	// Servers whose accessPolicy isn't deny also admit clients no AuthorizationPolicy authorizes
+	auditMode := false
+	for _, serverName := range serverNames {
+		accessPolicy := a.serverAccessPolicy(ctx, namespace, serverName)
+		if accessPolicy == denyInboundPolicy {
+			continue
+		}
+		auditMode = auditMode || accessPolicy == auditInboundPolicy
+		for _, source := range defaultPolicySources(accessPolicy) {
+			source["type"] = "serverAccessPolicy"
+			source["server"] = serverName
+			allowedSources = append(allowedSources, source)
+		}
+	}
+
+	return allowedSources, deniedSources, auditMode, nil
+}
+
+// portSources groups the given Servers by the port they govern and reports the sources and
+// policies of each port, so that a locked-down port and an open port of the same service get
+// separate verdicts. Numbered ports come first, in ascending order, followed by named ports.
+func (a *Analyzer) portSources(ctx context.Context, namespace string, servers []map[string]interface{}, explain bool) ([]map[string]interface{}, error) {
+	serversByPort := map[string][]string{}
+	portValues := map[string]interface{}{}
+	for _, server := range servers {
+		key := fmt.Sprint(server["port"])
+		if _, ok := portValues[key]; !ok {
+			portValues[key] = server["port"]
+		}
+		serversByPort[key] = append(serversByPort[key], server["name"].(string))
+	}
+
+	keys := make([]string, 0, len(serversByPort))
+	for key := range serversByPort {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		left, leftNumbered := portValues[keys[i]].(int64)
+		right, rightNumbered := portValues[keys[j]].(int64)
+		if leftNumbered != rightNumbered {
+			return leftNumbered
+		}
+		if leftNumbered {
+			return left < right
+		}
+		return keys[i] < keys[j]
+	})
+
+	ports := []map[string]interface{}{}
+	for _, key := range keys {
+		serverNames := serversByPort[key]
+		allowedSources, deniedSources, auditMode, err := a.serverSources(ctx, namespace, serverNames, explain)
+		if err != nil {
+			return nil, err
+		}
+
+		port := map[string]interface{}{
+			"port":           portValues[key],
+			"servers":        serverNames,
+			"policies":       a.serverPolicies(ctx, namespace, serverNames),
+			"auditMode":      auditMode,
+			"allowedSources": allowedSources,
+			"totalSources":   len(allowedSources),
+		}
+		if explain {
+			port["deniedSources"] = deniedSources
+		}
+		ports = append(ports, port)
+	}
+
+	return ports, nil
+}
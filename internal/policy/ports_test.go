@@ -0,0 +1,105 @@
+package policy_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/christianhuening/linkerd-mcp/internal/policy"
+	"github.com/christianhuening/linkerd-mcp/internal/testutil"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/fake"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+)
+
+var _ = Describe("GetAllowedSources by port", func() {
+	var (
+		ctx      context.Context
+		analyzer *policy.Analyzer
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+
+		scheme := runtime.NewScheme()
+		gvrToListKind := map[schema.GroupVersionResource]string{
+			serverGVR:              "ServerList",
+			authPolicyGVR:          "AuthorizationPolicyList",
+			meshTLSAuthGVR:         "MeshTLSAuthenticationList",
+			serverAuthorizationGVR: "ServerAuthorizationList",
+		}
+
+		adminServer := testutil.CreateServer("api-admin", "prod", map[string]string{"app": "api"}, 9990)
+		metricsServer := testutil.CreateServer("api-metrics", "prod", map[string]string{"app": "api"}, 0)
+		Expect(unstructured.SetNestedField(metricsServer.Object, "metrics", "spec", "port")).To(Succeed())
+		Expect(unstructured.SetNestedField(metricsServer.Object, "all-unauthenticated", "spec", "accessPolicy")).To(Succeed())
+
+		dynamicClient := fake.NewSimpleDynamicClientWithCustomListKinds(scheme, gvrToListKind,
+			testutil.CreateServer("api-http", "prod", map[string]string{"app": "api"}, 8080),
+			adminServer,
+			metricsServer,
+			testutil.CreateAuthorizationPolicy("api-clients", "prod", "api-http",
+				[]map[string]string{{"name": "api-clients", "kind": "MeshTLSAuthentication"}}),
+			testutil.CreateAuthorizationPolicy("admin-clients", "prod", "api-admin",
+				[]map[string]string{{"name": "missing-auth", "kind": "MeshTLSAuthentication"}}),
+			testutil.CreateMeshTLSAuthentication("api-clients", "prod",
+				[]string{"web.prod.serviceaccount.identity.linkerd.cluster.local"}, nil),
+		)
+
+		analyzer = policy.NewAnalyzer(kubefake.NewSimpleClientset(), dynamicClient)
+	})
+
+	It("should report the sources and policies of each port", func() {
+		result, err := analyzer.GetAllowedSources(ctx, "prod", "api", false)
+		Expect(err).NotTo(HaveOccurred())
+
+		var response map[string]interface{}
+		Expect(testutil.ParseJSONResult(result, &response)).To(Succeed())
+
+		Expect(response["matchingServers"]).To(ConsistOf(
+			map[string]interface{}{"name": "api-http", "port": float64(8080)},
+			map[string]interface{}{"name": "api-admin", "port": float64(9990)},
+			map[string]interface{}{"name": "api-metrics", "port": "metrics"},
+		))
+		Expect(response["totalSources"]).To(BeNumerically("==", 2))
+
+		ports := response["ports"].([]interface{})
+		Expect(ports).To(HaveLen(3))
+
+		httpPort := ports[0].(map[string]interface{})
+		Expect(httpPort["port"]).To(BeNumerically("==", 8080))
+		Expect(httpPort["servers"]).To(ConsistOf("api-http"))
+		Expect(httpPort["policies"]).To(ConsistOf("api-clients"))
+		Expect(httpPort["allowedSources"]).To(ConsistOf(
+			HaveKeyWithValue("identity", "web.prod.serviceaccount.identity.linkerd.cluster.local")))
+
+		adminPort := ports[1].(map[string]interface{})
+		Expect(adminPort["port"]).To(BeNumerically("==", 9990))
+		Expect(adminPort["policies"]).To(ConsistOf("admin-clients"))
+		Expect(adminPort["allowedSources"]).To(BeEmpty())
+		Expect(adminPort["totalSources"]).To(BeNumerically("==", 0))
+
+		metricsPort := ports[2].(map[string]interface{})
+		Expect(metricsPort["port"]).To(Equal("metrics"))
+		Expect(metricsPort["policies"]).To(BeEmpty())
+		Expect(metricsPort["allowedSources"]).To(ConsistOf(SatisfyAll(
+			HaveKeyWithValue("type", "serverAccessPolicy"),
+			HaveKeyWithValue("policy", "all-unauthenticated"),
+		)))
+	})
+
+	It("should explain denials per port", func() {
+		result, err := analyzer.GetAllowedSources(ctx, "prod", "api", true)
+		Expect(err).NotTo(HaveOccurred())
+
+		var response map[string]interface{}
+		Expect(testutil.ParseJSONResult(result, &response)).To(Succeed())
+
+		ports := response["ports"].([]interface{})
+		adminPort := ports[1].(map[string]interface{})
+		Expect(adminPort["deniedSources"]).To(ConsistOf(HaveKeyWithValue("server", "api-admin")))
+	})
+})
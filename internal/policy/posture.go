@@ -0,0 +1,238 @@
+package policy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+
+	"github.com/christianhuening/linkerd-mcp/internal/kube"
+	"github.com/mark3labs/mcp-go/mcp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// defaultInboundPolicyAnnotation overrides the cluster default inbound policy for a namespace or workload
+	defaultInboundPolicyAnnotation = "config.linkerd.io/default-inbound-policy"
+	// clusterDefaultInboundPolicy is Linkerd's default when the install doesn't configure one
+	clusterDefaultInboundPolicy = "all-unauthenticated"
+	// linkerdConfigMapName holds the install values of the control plane
+	linkerdConfigMapName = "linkerd-config"
+	// defaultControlPlaneNamespace is the namespace of the Linkerd control plane unless configured otherwise
+	defaultControlPlaneNamespace = "linkerd"
+)
+
+// Namespace policy postures
+const (
+	PostureLockedDown       = "locked-down"
+	PosturePartiallyCovered = "partially-covered"
+	PostureOpen             = "open"
+)
+
+// NamespacePosture summarizes how well a namespace's meshed workloads are protected by policy
+type NamespacePosture struct {
+	Namespace            string   `json:"namespace"`
+	Posture              string   `json:"posture"`
+	DefaultInboundPolicy string   `json:"defaultInboundPolicy"`
+	DefaultPolicySource  string   `json:"defaultPolicySource"` // "namespace" or "cluster"
+	MeshedPods           int      `json:"meshedPods"`
+	ProtectedPods        int      `json:"protectedPods"` // denied by default or selected by an authorized Server
+	UnprotectedPods      []string `json:"unprotectedPods"`
+}
+
+// GetPolicyPosture classifies each namespace (or only the given one) as locked-down,
+// partially-covered or open, based on its default inbound policy and whether its meshed
+// workloads are selected by Servers with an AuthorizationPolicy or ServerAuthorization
+func (a *Analyzer) GetPolicyPosture(ctx context.Context, namespace string) (*mcp.CallToolResult, error) {
+	ctx = withLookupCache(ctx)
+	clusterDefault := a.clusterDefaultInboundPolicy(ctx)
+
+	namespaces := []corev1.Namespace{}
+	if namespace != "" {
+		ns, err := a.clientset.CoreV1().Namespaces().Get(ctx, namespace, metav1.GetOptions{})
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to get namespace %s: %v", namespace, err)), nil
+		}
+		namespaces = append(namespaces, *ns)
+	} else {
+		list, err := a.clientset.CoreV1().Namespaces().List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to list namespaces: %v", err)), nil
+		}
+		namespaces = a.namespaceFilter.Namespaces(list.Items)
+	}
+
+	authPolicies, err := a.policiesTargetingNamespace(ctx, namespace)
+	if err != nil {
+		log.Printf("Warning: Failed to list AuthorizationPolicies: %v", err)
+	}
+
+	postures := []NamespacePosture{}
+	summary := map[string]int{PostureLockedDown: 0, PosturePartiallyCovered: 0, PostureOpen: 0}
+
+	for _, ns := range namespaces {
+		posture, err := a.namespacePosture(ctx, ns, clusterDefault, authPolicies)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		postures = append(postures, posture)
+		summary[posture.Posture]++
+	}
+
+	sort.Slice(postures, func(i, j int) bool { return postures[i].Namespace < postures[j].Namespace })
+
+	result := map[string]interface{}{
+		"clusterDefaultInboundPolicy": clusterDefault,
+		"namespaces":                  postures,
+		"summary":                     summary,
+	}
+
+	resultJSON, _ := json.MarshalIndent(result, "", "  ")
+	return mcp.NewToolResultText(string(resultJSON)), nil
+}
+
+// namespacePosture evaluates the policy coverage of the meshed pods in a namespace
+func (a *Analyzer) namespacePosture(ctx context.Context, ns corev1.Namespace, clusterDefault string, authPolicies []unstructured.Unstructured) (NamespacePosture, error) {
+	posture := NamespacePosture{
+		Namespace:            ns.Name,
+		DefaultInboundPolicy: clusterDefault,
+		DefaultPolicySource:  defaultPolicySourceCluster,
+		UnprotectedPods:      []string{},
+	}
+	if policy := ns.Annotations[defaultInboundPolicyAnnotation]; policy != "" {
+		posture.DefaultInboundPolicy = policy
+		posture.DefaultPolicySource = defaultPolicySourceNamespace
+	}
+
+	pods, err := kube.ListPods(ctx, a.clientset, ns.Name, metav1.ListOptions{})
+	if err != nil {
+		return posture, fmt.Errorf("failed to list pods in namespace %s: %v", ns.Name, err)
+	}
+
+	authorizedSelectors := a.authorizedServerSelectors(ctx, ns.Name, authPolicies)
+
+	for _, pod := range pods.Items {
+		if !isMeshedPod(pod) {
+			continue
+		}
+		posture.MeshedPods++
+
+		defaultPolicy := posture.DefaultInboundPolicy
+		if policy := pod.Annotations[defaultInboundPolicyAnnotation]; policy != "" {
+			defaultPolicy = policy
+		}
+
+		protected := defaultPolicy == "deny"
+		for _, selector := range authorizedSelectors {
+			if protected {
+				break
+			}
+			protected = selector.Matches(labels.Set(pod.Labels))
+		}
+
+		if protected {
+			posture.ProtectedPods++
+		} else {
+			posture.UnprotectedPods = append(posture.UnprotectedPods, pod.Name)
+		}
+	}
+
+	switch {
+	case posture.MeshedPods > 0 && posture.ProtectedPods == posture.MeshedPods:
+		posture.Posture = PostureLockedDown
+	case posture.ProtectedPods > 0:
+		posture.Posture = PosturePartiallyCovered
+	default:
+		posture.Posture = PostureOpen
+	}
+
+	return posture, nil
+}
+
+// authorizedServerSelectors returns the pod selectors of the Servers in a namespace that are
+// targeted by at least one AuthorizationPolicy or legacy ServerAuthorization
+func (a *Analyzer) authorizedServerSelectors(ctx context.Context, namespace string, authPolicies []unstructured.Unstructured) []labels.Selector {
+	selectors := []labels.Selector{}
+	for _, server := range a.authorizedServers(ctx, namespace, authPolicies) {
+		podSelector, found, err := unstructured.NestedMap(server.Object, "spec", "podSelector")
+		if err != nil || !found {
+			continue
+		}
+		selector, err := podSelectorAsSelector(podSelector)
+		if err != nil {
+			log.Printf("Warning: Invalid podSelector on Server %s/%s: %v", namespace, server.GetName(), err)
+			continue
+		}
+		selectors = append(selectors, selector)
+	}
+
+	return selectors
+}
+
+// authorizedServers returns the Servers in a namespace that are targeted by at least one
+// AuthorizationPolicy or legacy ServerAuthorization
+func (a *Analyzer) authorizedServers(ctx context.Context, namespace string, authPolicies []unstructured.Unstructured) []unstructured.Unstructured {
+	servers, err := a.listResources(ctx, serverGVR, namespace)
+	if err != nil {
+		log.Printf("Warning: Failed to list Servers in namespace %s: %v", namespace, err)
+		return nil
+	}
+
+	serverAuths := a.listServerAuthorizations(ctx, namespace)
+
+	authorizedServers := []unstructured.Unstructured{}
+	for _, server := range servers.Items {
+		authorized := false
+		for _, policy := range authPolicies {
+			if policyTargetsServer(policy, namespace, server.GetName()) {
+				authorized = true
+				break
+			}
+		}
+		for _, serverAuth := range serverAuths {
+			if authorized {
+				break
+			}
+			authorized = serverAuthorizationTargets(serverAuth, server)
+		}
+		if authorized {
+			authorizedServers = append(authorizedServers, server)
+		}
+	}
+
+	return authorizedServers
+}
+
+// clusterDefaultInboundPolicy reads the cluster-wide default inbound policy from the
+// linkerd-config install values, falling back to Linkerd's built-in default
+func (a *Analyzer) clusterDefaultInboundPolicy(ctx context.Context) string {
+	cm, err := a.clientset.CoreV1().ConfigMaps(a.controlPlaneNamespace).Get(ctx, linkerdConfigMapName, metav1.GetOptions{})
+	if err != nil {
+		return clusterDefaultInboundPolicy
+	}
+
+	var values struct {
+		Proxy struct {
+			DefaultInboundPolicy string `json:"defaultInboundPolicy"`
+		} `json:"proxy"`
+	}
+	if err := yaml.Unmarshal([]byte(cm.Data["values"]), &values); err != nil || values.Proxy.DefaultInboundPolicy == "" {
+		return clusterDefaultInboundPolicy
+	}
+	return values.Proxy.DefaultInboundPolicy
+}
+
+// isMeshedPod reports whether the pod has the Linkerd proxy injected
+func isMeshedPod(pod corev1.Pod) bool {
+	for _, container := range pod.Spec.Containers {
+		if kube.IsProxyContainer(container.Name) {
+			return true
+		}
+	}
+	return false
+}
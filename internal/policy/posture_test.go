@@ -0,0 +1,135 @@
+package policy_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/christianhuening/linkerd-mcp/internal/policy"
+	"github.com/christianhuening/linkerd-mcp/internal/testutil"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/fake"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+)
+
+func namespaceWithDefaultPolicy(name, defaultPolicy string) *corev1.Namespace {
+	ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name}}
+	if defaultPolicy != "" {
+		ns.Annotations = map[string]string{"config.linkerd.io/default-inbound-policy": defaultPolicy}
+	}
+	return ns
+}
+
+var _ = Describe("GetPolicyPosture", func() {
+	var (
+		ctx           context.Context
+		kubeClient    *kubefake.Clientset
+		dynamicClient *fake.FakeDynamicClient
+	)
+
+	postures := func(namespace string) map[string]map[string]interface{} {
+		result, err := policy.NewAnalyzer(kubeClient, dynamicClient).GetPolicyPosture(ctx, namespace)
+		Expect(err).NotTo(HaveOccurred())
+
+		var response map[string]interface{}
+		err = testutil.ParseJSONResult(result, &response)
+		Expect(err).NotTo(HaveOccurred())
+
+		byNamespace := map[string]map[string]interface{}{}
+		for _, entry := range response["namespaces"].([]interface{}) {
+			posture := entry.(map[string]interface{})
+			byNamespace[posture["namespace"].(string)] = posture
+		}
+		return byNamespace
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+
+		scheme := runtime.NewScheme()
+		gvrToListKind := map[schema.GroupVersionResource]string{
+			serverGVR:              "ServerList",
+			authPolicyGVR:          "AuthorizationPolicyList",
+			meshTLSAuthGVR:         "MeshTLSAuthenticationList",
+			serverAuthorizationGVR: "ServerAuthorizationList",
+		}
+
+		kubeClient = kubefake.NewSimpleClientset(
+			namespaceWithDefaultPolicy("locked", "deny"),
+			namespaceWithDefaultPolicy("partial", ""),
+			namespaceWithDefaultPolicy("open", ""),
+			testutil.CreateMeshedPod("vault-1", "locked", "vault"),
+			testutil.CreateMeshedPod("api-1", "partial", "api"),
+			testutil.CreateMeshedPod("worker-1", "partial", "worker"),
+			testutil.CreateMeshedPod("web-1", "open", "web"),
+			testutil.CreatePod("legacy-1", "open", "default", map[string]string{"app": "legacy"}, corev1.PodRunning, true),
+		)
+		dynamicClient = fake.NewSimpleDynamicClientWithCustomListKinds(scheme, gvrToListKind)
+
+		_, err := dynamicClient.Resource(serverGVR).Namespace("partial").Create(ctx,
+			testutil.CreateServer("api-server", "partial", map[string]string{"app": "api"}, 8080), metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		_, err = dynamicClient.Resource(authPolicyGVR).Namespace("partial").Create(ctx,
+			testutil.CreateAuthorizationPolicy("api-clients", "partial", "api-server",
+				[]map[string]string{{"name": "clients", "kind": "MeshTLSAuthentication"}}), metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred())
+
+		// A Server without any authorization doesn't count as coverage
+		_, err = dynamicClient.Resource(serverGVR).Namespace("open").Create(ctx,
+			testutil.CreateServer("web-server", "open", map[string]string{"app": "web"}, 8080), metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should classify namespaces in each posture", func() {
+		byNamespace := postures("")
+
+		Expect(byNamespace["locked"]["posture"]).To(Equal("locked-down"))
+		Expect(byNamespace["locked"]["defaultInboundPolicy"]).To(Equal("deny"))
+		Expect(byNamespace["locked"]["defaultPolicySource"]).To(Equal("namespace"))
+
+		Expect(byNamespace["partial"]["posture"]).To(Equal("partially-covered"))
+		Expect(byNamespace["partial"]["meshedPods"]).To(BeNumerically("==", 2))
+		Expect(byNamespace["partial"]["protectedPods"]).To(BeNumerically("==", 1))
+		Expect(byNamespace["partial"]["unprotectedPods"]).To(ConsistOf("worker-1"))
+
+		Expect(byNamespace["open"]["posture"]).To(Equal("open"))
+		Expect(byNamespace["open"]["defaultInboundPolicy"]).To(Equal("all-unauthenticated"))
+		Expect(byNamespace["open"]["meshedPods"]).To(BeNumerically("==", 1))
+	})
+
+	It("should honor the cluster default from linkerd-config", func() {
+		_, err := kubeClient.CoreV1().ConfigMaps("linkerd").Create(ctx, &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "linkerd-config", Namespace: "linkerd"},
+			Data:       map[string]string{"values": "proxy:\n  defaultInboundPolicy: deny\n"},
+		}, metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred())
+
+		byNamespace := postures("open")
+
+		Expect(byNamespace).To(HaveLen(1))
+		Expect(byNamespace["open"]["posture"]).To(Equal("locked-down"))
+		Expect(byNamespace["open"]["defaultPolicySource"]).To(Equal("cluster"))
+	})
+
+	It("should read linkerd-config from the configured control plane namespace", func() {
+		_, err := kubeClient.CoreV1().ConfigMaps("linkerd-control-plane").Create(ctx, &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "linkerd-config", Namespace: "linkerd-control-plane"},
+			Data:       map[string]string{"values": "proxy:\n  defaultInboundPolicy: deny\n"},
+		}, metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred())
+
+		analyzer := policy.NewAnalyzer(kubeClient, dynamicClient)
+		analyzer.SetControlPlaneNamespace("linkerd-control-plane")
+		result, err := analyzer.GetPolicyPosture(ctx, "open")
+		Expect(err).NotTo(HaveOccurred())
+
+		var response map[string]interface{}
+		err = testutil.ParseJSONResult(result, &response)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(response["clusterDefaultInboundPolicy"]).To(Equal("deny"))
+	})
+})
@@ -0,0 +1,117 @@
+package policy
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+var httpRouteGVR = schema.GroupVersionResource{
+	Group:    "policy.linkerd.io",
+	Version:  "v1alpha1",
+	Resource: "httproutes",
+}
+
+// routeGrant is what an AuthorizationPolicy targeting an HTTPRoute authorizes: the requests the
+// route matches, on the Servers the route is attached to
+type routeGrant struct {
+	namespace string
+	route     string
+	servers   []string
+	matches   []map[string]interface{}
+}
+
+// policyRouteGrant resolves an AuthorizationPolicy targeting an HTTPRoute to the route's parent
+// Servers, which must live in the route's namespace, and the requests the route matches. It
+// reports false for policies targeting anything else and for routes that can't be read.
+func (a *Analyzer) policyRouteGrant(ctx context.Context, policy unstructured.Unstructured) (routeGrant, bool) {
+	routeNamespace, routeName, ok := policyTargetRoute(policy)
+	if !ok {
+		return routeGrant{}, false
+	}
+
+	route, err := a.getResource(ctx, httpRouteGVR, routeNamespace, routeName)
+	if err != nil {
+		return routeGrant{}, false
+	}
+
+	grant := routeGrant{namespace: routeNamespace, route: routeName, servers: []string{}, matches: routeMatches(*route)}
+	parentRefs, _, _ := unstructured.NestedSlice(route.Object, "spec", "parentRefs")
+	for _, parentRef := range parentRefs {
+		refMap, ok := parentRef.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		kind, _, _ := unstructured.NestedString(refMap, "kind")
+		name, _, _ := unstructured.NestedString(refMap, "name")
+		namespace, _, _ := unstructured.NestedString(refMap, "namespace")
+		if kind != "Server" || name == "" || (namespace != "" && namespace != routeNamespace) {
+			continue
+		}
+		grant.servers = append(grant.servers, name)
+	}
+
+	return grant, true
+}
+
+// grantsServer reports whether the route is attached to the given Server
+func (g routeGrant) grantsServer(namespace, serverName string) bool {
+	if namespace != g.namespace {
+		return false
+	}
+	for _, server := range g.servers {
+		if server == serverName {
+			return true
+		}
+	}
+	return false
+}
+
+// annotate records the route and the requests it matches on a source or target entry
+func (g routeGrant) annotate(entry map[string]interface{}) {
+	entry["httpRoute"] = g.route
+	entry["routeMatches"] = g.matches
+}
+
+// routeMatches lists the path, method and header matches of each rule of an HTTPRoute. A rule
+// without matches matches every request, which Gateway API defines as the path prefix "/".
+func routeMatches(route unstructured.Unstructured) []map[string]interface{} {
+	matches := []map[string]interface{}{}
+	rules, _, _ := unstructured.NestedSlice(route.Object, "spec", "rules")
+	for _, rule := range rules {
+		ruleMap, ok := rule.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		ruleMatches, _, _ := unstructured.NestedSlice(ruleMap, "matches")
+		if len(ruleMatches) == 0 {
+			matches = append(matches, map[string]interface{}{"pathType": "PathPrefix", "path": "/"})
+			continue
+		}
+
+		for _, match := range ruleMatches {
+			matchMap, ok := match.(map[string]interface{})
+			if !ok {
+				continue
+			}
+
+			entry := map[string]interface{}{"pathType": "PathPrefix", "path": "/"}
+			if pathType, found, _ := unstructured.NestedString(matchMap, "path", "type"); found {
+				entry["pathType"] = pathType
+			}
+			if path, found, _ := unstructured.NestedString(matchMap, "path", "value"); found {
+				entry["path"] = path
+			}
+			if method, found, _ := unstructured.NestedString(matchMap, "method"); found {
+				entry["method"] = method
+			}
+			if headers, found, _ := unstructured.NestedSlice(matchMap, "headers"); found {
+				entry["headers"] = headers
+			}
+			matches = append(matches, entry)
+		}
+	}
+	return matches
+}
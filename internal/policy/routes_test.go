@@ -0,0 +1,112 @@
+package policy_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/christianhuening/linkerd-mcp/internal/policy"
+	"github.com/christianhuening/linkerd-mcp/internal/testutil"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/fake"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+)
+
+var httpRouteGVR = schema.GroupVersionResource{
+	Group:    "policy.linkerd.io",
+	Version:  "v1alpha1",
+	Resource: "httproutes",
+}
+
+var _ = Describe("HTTPRoute-targeted AuthorizationPolicies", func() {
+	var (
+		ctx      context.Context
+		analyzer *policy.Analyzer
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+
+		scheme := runtime.NewScheme()
+		gvrToListKind := map[schema.GroupVersionResource]string{
+			serverGVR:              "ServerList",
+			authPolicyGVR:          "AuthorizationPolicyList",
+			meshTLSAuthGVR:         "MeshTLSAuthenticationList",
+			serverAuthorizationGVR: "ServerAuthorizationList",
+			httpRouteGVR:           "HTTPRouteList",
+		}
+
+		kubeClient := kubefake.NewSimpleClientset(
+			testutil.CreatePod("frontend-1", "prod", "frontend-sa", map[string]string{"app": "frontend"}, "Running", true),
+		)
+
+		// The frontend may only read the API; the admin Server is granted to nobody
+		routePolicy := testutil.CreateAuthorizationPolicy("api-read", "prod", "api-read-route",
+			[]map[string]string{{"name": "frontend-clients", "kind": "MeshTLSAuthentication"}})
+		Expect(unstructured.SetNestedField(routePolicy.Object, "HTTPRoute", "spec", "targetRef", "kind")).To(Succeed())
+		Expect(unstructured.SetNestedField(routePolicy.Object, "policy.linkerd.io", "spec", "targetRef", "group")).To(Succeed())
+
+		dynamicClient := fake.NewSimpleDynamicClientWithCustomListKinds(scheme, gvrToListKind,
+			testutil.CreateServer("api-http", "prod", map[string]string{"app": "api"}, 8080),
+			testutil.CreateServer("admin-http", "prod", map[string]string{"app": "admin"}, 8080),
+			testutil.CreateServerHTTPRoute("api-read-route", "prod", "api-http", []map[string]interface{}{
+				{"path": map[string]interface{}{"type": "PathPrefix", "value": "/api"}, "method": "GET"},
+			}),
+			routePolicy,
+			testutil.CreateMeshTLSAuthentication("frontend-clients", "prod",
+				[]string{"frontend-sa.prod.serviceaccount.identity.linkerd.cluster.local"}, nil),
+		)
+		analyzer = policy.NewAnalyzer(kubeClient, dynamicClient)
+	})
+
+	It("should report sources granted through a route with the matched requests", func() {
+		result, err := analyzer.GetAllowedSources(ctx, "prod", "api", false)
+		Expect(err).NotTo(HaveOccurred())
+
+		var response map[string]interface{}
+		Expect(testutil.ParseJSONResult(result, &response)).To(Succeed())
+
+		allowedSources := response["allowedSources"].([]interface{})
+		Expect(allowedSources).To(HaveLen(1))
+		source := allowedSources[0].(map[string]interface{})
+		Expect(source["identity"]).To(Equal("frontend-sa.prod.serviceaccount.identity.linkerd.cluster.local"))
+		Expect(source["authorizationPolicy"]).To(Equal("api-read"))
+		Expect(source["httpRoute"]).To(Equal("api-read-route"))
+		Expect(source["routeMatches"]).To(ConsistOf(map[string]interface{}{
+			"pathType": "PathPrefix",
+			"path":     "/api",
+			"method":   "GET",
+		}))
+	})
+
+	It("should report the route's parent Server as a target of the source", func() {
+		result, err := analyzer.GetAllowedTargets(ctx, "prod", "frontend", true)
+		Expect(err).NotTo(HaveOccurred())
+
+		var response map[string]interface{}
+		Expect(testutil.ParseJSONResult(result, &response)).To(Succeed())
+
+		allowedTargets := response["allowedTargets"].([]interface{})
+		Expect(allowedTargets).To(HaveLen(1))
+		target := allowedTargets[0].(map[string]interface{})
+		Expect(target["server"]).To(Equal("api-http"))
+		Expect(target["authorizationPolicy"]).To(Equal("api-read"))
+		Expect(target["httpRoute"]).To(Equal("api-read-route"))
+		Expect(target["routeMatches"]).To(HaveLen(1))
+
+		deniedTargets := response["deniedTargets"].([]interface{})
+		Expect(deniedTargets).To(ConsistOf(HaveKeyWithValue("server", "admin-http")))
+	})
+
+	It("should not grant Servers the route isn't attached to", func() {
+		result, err := analyzer.GetAllowedSources(ctx, "prod", "admin", false)
+		Expect(err).NotTo(HaveOccurred())
+
+		var response map[string]interface{}
+		Expect(testutil.ParseJSONResult(result, &response)).To(Succeed())
+		Expect(response["allowedSources"]).To(BeEmpty())
+	})
+})
@@ -0,0 +1,158 @@
+package policy
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// serverAuthorizationGVR is the legacy authorization resource superseded by AuthorizationPolicy
+var serverAuthorizationGVR = schema.GroupVersionResource{
+	Group:    "policy.linkerd.io",
+	Version:  "v1beta1",
+	Resource: "serverauthorizations",
+}
+
+// listServerAuthorizations lists legacy ServerAuthorizations in a namespace. Clusters that
+// don't have the CRD installed yield an empty list rather than an error.
+func (a *Analyzer) listServerAuthorizations(ctx context.Context, namespace string) []unstructured.Unstructured {
+	list, err := a.listResources(ctx, serverAuthorizationGVR, namespace)
+	if err != nil {
+		log.Printf("Skipping ServerAuthorizations in namespace %s: %v", namespace, err)
+		return nil
+	}
+	return list.Items
+}
+
+// serverAuthorizationTargets reports whether a ServerAuthorization applies to the given Server,
+// either by name or by a label selector over Server labels
+func serverAuthorizationTargets(serverAuth unstructured.Unstructured, server unstructured.Unstructured) bool {
+	name, found, _ := unstructured.NestedString(serverAuth.Object, "spec", "server", "name")
+	if found && name != "" {
+		return name == server.GetName()
+	}
+
+	matchLabels, found, _ := unstructured.NestedStringMap(serverAuth.Object, "spec", "server", "selector", "matchLabels")
+	if !found {
+		return false
+	}
+	return labels.SelectorFromSet(matchLabels).Matches(labels.Set(server.GetLabels()))
+}
+
+// checkServerAuthorizationAllowed checks if a source is allowed by a ServerAuthorization
+func checkServerAuthorizationAllowed(serverAuth unstructured.Unstructured, sourceNamespace, sourceServiceAccount string) bool {
+	client, found, _ := unstructured.NestedMap(serverAuth.Object, "spec", "client")
+	if !found {
+		return false
+	}
+
+	if unauthenticated, _, _ := unstructured.NestedBool(client, "unauthenticated"); unauthenticated {
+		return true
+	}
+
+	identities, _, _ := unstructured.NestedStringSlice(client, "meshTLS", "identities")
+	for _, identity := range identities {
+		if identityMatches(identity, sourceNamespace, sourceServiceAccount) {
+			return true
+		}
+	}
+
+	serviceAccounts, _, _ := unstructured.NestedSlice(client, "meshTLS", "serviceAccounts")
+	for _, sa := range serviceAccounts {
+		saMap, ok := sa.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		saName, _, _ := unstructured.NestedString(saMap, "name")
+		saNamespace, _, _ := unstructured.NestedString(saMap, "namespace")
+		if saNamespace == "" {
+			saNamespace = serverAuth.GetNamespace()
+		}
+		if saName == sourceServiceAccount && saNamespace == sourceNamespace {
+			return true
+		}
+	}
+
+	return false
+}
+
+// extractSourcesFromServerAuthorization extracts sources from a legacy ServerAuthorization,
+// in the same shape as extractSourcesFromAuth
+func extractSourcesFromServerAuthorization(serverAuth unstructured.Unstructured) map[string]map[string]interface{} {
+	sources := make(map[string]map[string]interface{})
+	name := serverAuth.GetName()
+	namespace := serverAuth.GetNamespace()
+
+	client, found, _ := unstructured.NestedMap(serverAuth.Object, "spec", "client")
+	if !found {
+		return sources
+	}
+
+	if unauthenticated, _, _ := unstructured.NestedBool(client, "unauthenticated"); unauthenticated {
+		sources["unauthenticated"] = map[string]interface{}{
+			"type":                "unauthenticated",
+			"description":         "All clients, including unauthenticated ones",
+			"serverAuthorization": name,
+		}
+	}
+
+	identities, _, _ := unstructured.NestedStringSlice(client, "meshTLS", "identities")
+	for _, identity := range identities {
+		if identity == "*" {
+			sources["all-authenticated"] = map[string]interface{}{
+				"type":                "wildcard",
+				"description":         "All authenticated services",
+				"serverAuthorization": name,
+			}
+			continue
+		}
+		sources[identity] = map[string]interface{}{
+			"identity":            identity,
+			"serverAuthorization": name,
+		}
+	}
+
+	serviceAccounts, _, _ := unstructured.NestedSlice(client, "meshTLS", "serviceAccounts")
+	for _, sa := range serviceAccounts {
+		saMap, ok := sa.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		saName, _, _ := unstructured.NestedString(saMap, "name")
+		saNamespace, _, _ := unstructured.NestedString(saMap, "namespace")
+		if saNamespace == "" {
+			saNamespace = namespace
+		}
+
+		key := fmt.Sprintf("%s/%s", saNamespace, saName)
+		sources[key] = map[string]interface{}{
+			"serviceAccount":      saName,
+			"namespace":           saNamespace,
+			"serverAuthorization": name,
+		}
+	}
+
+	networks, _, _ := unstructured.NestedSlice(client, "networks")
+	for _, network := range networks {
+		networkMap, ok := network.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		cidr, _, _ := unstructured.NestedString(networkMap, "cidr")
+		except, _, _ := unstructured.NestedSlice(networkMap, "except")
+
+		key := fmt.Sprintf("network-%s", cidr)
+		sources[key] = map[string]interface{}{
+			"type":                "network",
+			"cidr":                cidr,
+			"except":              except,
+			"serverAuthorization": name,
+		}
+	}
+
+	return sources
+}
@@ -0,0 +1,114 @@
+package policy_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/christianhuening/linkerd-mcp/internal/policy"
+	"github.com/christianhuening/linkerd-mcp/internal/testutil"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/fake"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+var serverAuthorizationGVR = schema.GroupVersionResource{
+	Group:    "policy.linkerd.io",
+	Version:  "v1beta1",
+	Resource: "serverauthorizations",
+}
+
+var _ = Describe("Legacy ServerAuthorization support", func() {
+	var (
+		ctx           context.Context
+		analyzer      *policy.Analyzer
+		kubeClient    *kubefake.Clientset
+		dynamicClient *fake.FakeDynamicClient
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+
+		scheme := runtime.NewScheme()
+		gvrToListKind := map[schema.GroupVersionResource]string{
+			serverGVR:              "ServerList",
+			authPolicyGVR:          "AuthorizationPolicyList",
+			meshTLSAuthGVR:         "MeshTLSAuthenticationList",
+			serverAuthorizationGVR: "ServerAuthorizationList",
+		}
+
+		kubeClient = kubefake.NewSimpleClientset(
+			testutil.CreatePod("frontend-1", "prod", "frontend-sa", map[string]string{"app": "frontend"}, "Running", true),
+		)
+		dynamicClient = fake.NewSimpleDynamicClientWithCustomListKinds(scheme, gvrToListKind)
+		analyzer = policy.NewAnalyzer(kubeClient, dynamicClient)
+
+		server := testutil.CreateServer("backend-server", "prod", map[string]string{"app": "backend"}, 8080)
+		_, err := dynamicClient.Resource(serverGVR).Namespace("prod").Create(ctx, server, metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred())
+
+		serverAuth := testutil.CreateServerAuthorization(
+			"backend-clients",
+			"prod",
+			"backend-server",
+			[]string{"frontend-sa.prod.serviceaccount.identity.linkerd.cluster.local"},
+			[]map[string]string{{"name": "batch-sa", "namespace": "jobs"}},
+		)
+		_, err = dynamicClient.Resource(serverAuthorizationGVR).Namespace("prod").Create(ctx, serverAuth, metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should include ServerAuthorization clients in allowed sources", func() {
+		result, err := analyzer.GetAllowedSources(ctx, "prod", "backend", false)
+		Expect(err).NotTo(HaveOccurred())
+
+		var response map[string]interface{}
+		err = testutil.ParseJSONResult(result, &response)
+		Expect(err).NotTo(HaveOccurred())
+
+		allowedSources := response["allowedSources"].([]interface{})
+		Expect(allowedSources).To(HaveLen(2))
+		for _, src := range allowedSources {
+			Expect(src.(map[string]interface{})["serverAuthorization"]).To(Equal("backend-clients"))
+		}
+	})
+
+	It("should include Servers authorized by a ServerAuthorization in allowed targets", func() {
+		result, err := analyzer.GetAllowedTargets(ctx, "prod", "frontend", false)
+		Expect(err).NotTo(HaveOccurred())
+
+		var response map[string]interface{}
+		err = testutil.ParseJSONResult(result, &response)
+		Expect(err).NotTo(HaveOccurred())
+
+		allowedTargets := response["allowedTargets"].([]interface{})
+		Expect(allowedTargets).To(HaveLen(1))
+		target := allowedTargets[0].(map[string]interface{})
+		Expect(target["server"]).To(Equal("backend-server"))
+		Expect(target["serverAuthorization"]).To(Equal("backend-clients"))
+	})
+
+	Context("when the ServerAuthorization CRD is not installed", func() {
+		BeforeEach(func() {
+			dynamicClient.PrependReactor("list", "serverauthorizations", func(action k8stesting.Action) (bool, runtime.Object, error) {
+				return true, nil, apierrors.NewNotFound(serverAuthorizationGVR.GroupResource(), "")
+			})
+		})
+
+		It("should skip legacy resources without failing", func() {
+			result, err := analyzer.GetAllowedTargets(ctx, "prod", "frontend", false)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.IsError).To(BeFalse())
+
+			var response map[string]interface{}
+			err = testutil.ParseJSONResult(result, &response)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(response["allowedTargets"]).To(BeEmpty())
+		})
+	})
+})
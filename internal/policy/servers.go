@@ -0,0 +1,54 @@
+package policy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// ListServers lists the Servers in a namespace (or all namespaces) with the port and pods they
+// govern, their accessPolicy, and the AuthorizationPolicies and ServerAuthorizations targeting them
+func (a *Analyzer) ListServers(ctx context.Context, namespace string) (*mcp.CallToolResult, error) {
+	ctx = withLookupCache(ctx)
+
+	list, err := a.listResources(ctx, serverGVR, namespace)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to list servers: %v", err)), nil
+	}
+
+	items := append([]unstructured.Unstructured{}, list.Items...)
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].GetNamespace() != items[j].GetNamespace() {
+			return items[i].GetNamespace() < items[j].GetNamespace()
+		}
+		return items[i].GetName() < items[j].GetName()
+	})
+
+	servers := []map[string]interface{}{}
+	for _, server := range items {
+		podSelector, _, _ := unstructured.NestedMap(server.Object, "spec", "podSelector")
+		proxyProtocol, _, _ := unstructured.NestedString(server.Object, "spec", "proxyProtocol")
+		servers = append(servers, map[string]interface{}{
+			"name":          server.GetName(),
+			"namespace":     server.GetNamespace(),
+			"port":          serverPort(server),
+			"podSelector":   podSelector,
+			"proxyProtocol": proxyProtocol,
+			"accessPolicy":  a.serverAccessPolicy(ctx, server.GetNamespace(), server.GetName()),
+			"policies":      a.serverPolicies(ctx, server.GetNamespace(), []string{server.GetName()}),
+		})
+	}
+
+	result := map[string]interface{}{
+		"namespace":    namespace,
+		"servers":      servers,
+		"totalServers": len(servers),
+	}
+
+	resultJSON, _ := json.MarshalIndent(result, "", "  ")
+	return mcp.NewToolResultText(string(resultJSON)), nil
+}
@@ -0,0 +1,80 @@
+package policy_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/christianhuening/linkerd-mcp/internal/policy"
+	"github.com/christianhuening/linkerd-mcp/internal/testutil"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/fake"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+)
+
+var _ = Describe("ListServers", func() {
+	var (
+		ctx      context.Context
+		analyzer *policy.Analyzer
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+
+		scheme := runtime.NewScheme()
+		gvrToListKind := map[schema.GroupVersionResource]string{
+			serverGVR:              "ServerList",
+			authPolicyGVR:          "AuthorizationPolicyList",
+			meshTLSAuthGVR:         "MeshTLSAuthenticationList",
+			serverAuthorizationGVR: "ServerAuthorizationList",
+		}
+
+		metricsServer := testutil.CreateServer("api-metrics", "prod", map[string]string{"app": "api"}, 9990)
+		Expect(unstructured.SetNestedField(metricsServer.Object, "all-unauthenticated", "spec", "accessPolicy")).To(Succeed())
+
+		dynamicClient := fake.NewSimpleDynamicClientWithCustomListKinds(scheme, gvrToListKind,
+			testutil.CreateServer("api-http", "prod", map[string]string{"app": "api"}, 8080),
+			metricsServer,
+			testutil.CreateServer("web-http", "staging", map[string]string{"app": "web"}, 8080),
+			testutil.CreateAuthorizationPolicy("api-clients", "prod", "api-http",
+				[]map[string]string{{"name": "api-clients", "kind": "MeshTLSAuthentication"}}),
+		)
+
+		analyzer = policy.NewAnalyzer(kubefake.NewSimpleClientset(), dynamicClient)
+	})
+
+	It("should list the Servers of a namespace with their policies", func() {
+		result, err := analyzer.ListServers(ctx, "prod")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.IsError).To(BeFalse())
+
+		var response map[string]interface{}
+		Expect(testutil.ParseJSONResult(result, &response)).To(Succeed())
+		Expect(response["totalServers"]).To(BeNumerically("==", 2))
+
+		servers := response["servers"].([]interface{})
+		httpServer := servers[0].(map[string]interface{})
+		Expect(httpServer["name"]).To(Equal("api-http"))
+		Expect(httpServer["port"]).To(BeNumerically("==", 8080))
+		Expect(httpServer["accessPolicy"]).To(Equal("deny"))
+		Expect(httpServer["policies"]).To(ConsistOf("api-clients"))
+		Expect(httpServer["podSelector"]).To(HaveKeyWithValue("matchLabels", HaveKeyWithValue("app", "api")))
+
+		metrics := servers[1].(map[string]interface{})
+		Expect(metrics["name"]).To(Equal("api-metrics"))
+		Expect(metrics["accessPolicy"]).To(Equal("all-unauthenticated"))
+		Expect(metrics["policies"]).To(BeEmpty())
+	})
+
+	It("should list the Servers of all namespaces", func() {
+		result, err := analyzer.ListServers(ctx, "")
+		Expect(err).NotTo(HaveOccurred())
+
+		var response map[string]interface{}
+		Expect(testutil.ParseJSONResult(result, &response)).To(Succeed())
+		Expect(response["totalServers"]).To(BeNumerically("==", 3))
+	})
+})
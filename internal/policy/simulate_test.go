@@ -0,0 +1,90 @@
+package policy_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/christianhuening/linkerd-mcp/internal/policy"
+	"github.com/christianhuening/linkerd-mcp/internal/testutil"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/fake"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+)
+
+var _ = Describe("SimulateAccess", func() {
+	var (
+		ctx      context.Context
+		analyzer *policy.Analyzer
+	)
+
+	simulate := func(sourceNamespace, serviceAccount, targetService string) map[string]interface{} {
+		result, err := analyzer.SimulateAccess(ctx, sourceNamespace, serviceAccount, "prod", targetService)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.IsError).To(BeFalse())
+
+		var analysis map[string]interface{}
+		Expect(testutil.ParseJSONResult(result, &analysis)).To(Succeed())
+		return analysis
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+
+		scheme := runtime.NewScheme()
+		gvrToListKind := map[schema.GroupVersionResource]string{
+			serverGVR:              "ServerList",
+			authPolicyGVR:          "AuthorizationPolicyList",
+			meshTLSAuthGVR:         "MeshTLSAuthenticationList",
+			serverAuthorizationGVR: "ServerAuthorizationList",
+		}
+
+		// No pod runs as the simulated service accounts
+		kubeClient := kubefake.NewSimpleClientset(
+			testutil.CreateMeshedPod("backend-1", "prod", "backend"),
+			testutil.CreateMeshedPod("web-1", "prod", "web"),
+			&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: "linkerd-config", Namespace: "linkerd"},
+				Data:       map[string]string{"values": "proxy:\n  defaultInboundPolicy: all-authenticated\n"},
+			},
+		)
+		dynamicClient := fake.NewSimpleDynamicClientWithCustomListKinds(scheme, gvrToListKind,
+			testutil.CreateServer("backend-server", "prod", map[string]string{"app": "backend"}, 8080),
+			testutil.CreateAuthorizationPolicy("backend-clients", "prod", "backend-server",
+				[]map[string]string{{"name": "frontend-clients", "kind": "MeshTLSAuthentication"}}),
+			testutil.CreateMeshTLSAuthentication("frontend-clients", "prod", nil,
+				[]map[string]string{{"name": "frontend-sa", "namespace": "staging"}}),
+		)
+
+		analyzer = policy.NewAnalyzer(kubeClient, dynamicClient)
+	})
+
+	It("should allow an authorized service account without a source pod", func() {
+		analysis := simulate("staging", "frontend-sa", "backend")
+
+		Expect(analysis["allowed"]).To(BeTrue())
+		Expect(analysis["verdictBasis"]).To(Equal("authorizationPolicy"))
+		Expect(analysis["policies"]).To(ConsistOf("backend-clients"))
+		Expect(analysis["source"]).To(HaveKeyWithValue("serviceAccount", "frontend-sa"))
+		Expect(analysis["source"]).To(HaveKeyWithValue("simulated", true))
+	})
+
+	It("should deny a service account no policy authorizes", func() {
+		analysis := simulate("staging", "other-sa", "backend")
+
+		Expect(analysis["allowed"]).To(BeFalse())
+		Expect(analysis["explanation"]).To(ContainSubstring("authorizes the source identity"))
+	})
+
+	It("should treat the source as meshed under the default inbound policy", func() {
+		analysis := simulate("staging", "", "web")
+
+		Expect(analysis["allowed"]).To(BeTrue())
+		Expect(analysis["verdictBasis"]).To(Equal("defaultInboundPolicy"))
+		Expect(analysis["source"]).To(HaveKeyWithValue("serviceAccount", "default"))
+	})
+})
@@ -5,24 +5,38 @@ import (
 	"fmt"
 	"log"
 
+	"github.com/christianhuening/linkerd-mcp/internal/kube"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 )
 
-// findServersForService finds all Server resources for a given service
-func (a *Analyzer) findServersForService(ctx context.Context, namespace, service string) ([]string, error) {
-	serverGVR := schema.GroupVersionResource{
-		Group:    "policy.linkerd.io",
-		Version:  "v1beta3",
-		Resource: "servers",
-	}
+var serverGVR = schema.GroupVersionResource{
+	Group:    "policy.linkerd.io",
+	Version:  "v1beta3",
+	Resource: "servers",
+}
 
-	servers, err := a.dynamicClient.Resource(serverGVR).Namespace(namespace).List(ctx, metav1.ListOptions{})
+var authPolicyGVR = schema.GroupVersionResource{
+	Group:    "policy.linkerd.io",
+	Version:  "v1alpha1",
+	Resource: "authorizationpolicies",
+}
+
+// findServersForService finds all Server resources for a given service.
+// The service's pods are resolved through the Service selector and a Server matches when its
+// podSelector selects any of them. When the Service can't be resolved, Servers are matched by
+// their `app` label instead.
+func (a *Analyzer) findServersForService(ctx context.Context, namespace, service string) ([]string, error) {
+	servers, err := a.listResources(ctx, serverGVR, namespace)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list Servers: %v (ensure Linkerd policy CRDs are installed)", err)
 	}
 
+	podLabelSets, resolved := a.resolveServicePodLabels(ctx, namespace, service)
+
 	matchingServers := []string{}
 
 	for _, server := range servers.Items {
@@ -31,55 +45,118 @@ func (a *Analyzer) findServersForService(ctx context.Context, namespace, service
 			continue
 		}
 
-		matchLabels, found, err := unstructured.NestedMap(podSelector, "matchLabels")
-		if err != nil || !found {
+		if !resolved {
+			// Fall back to matching the target service by app label
+			matchLabels, found, err := unstructured.NestedMap(podSelector, "matchLabels")
+			if err != nil || !found {
+				continue
+			}
+			if appLabel, ok := matchLabels["app"].(string); ok && appLabel == service {
+				matchingServers = append(matchingServers, server.GetName())
+			}
+			continue
+		}
+
+		selector, err := podSelectorAsSelector(podSelector)
+		if err != nil {
+			log.Printf("Warning: Invalid podSelector on Server %s/%s: %v", namespace, server.GetName(), err)
 			continue
 		}
 
-		// Check if the server matches our target service (by app label)
-		if appLabel, ok := matchLabels["app"].(string); ok && appLabel == service {
-			matchingServers = append(matchingServers, server.GetName())
+		for _, podLabels := range podLabelSets {
+			if selector.Matches(podLabels) {
+				matchingServers = append(matchingServers, server.GetName())
+				break
+			}
 		}
 	}
 
 	return matchingServers, nil
 }
 
-// findAllowedSources finds all sources that can access the given servers
-func (a *Analyzer) findAllowedSources(ctx context.Context, namespace string, matchingServers []string) ([]map[string]interface{}, error) {
-	authPolicyGVR := schema.GroupVersionResource{
-		Group:    "policy.linkerd.io",
-		Version:  "v1alpha1",
-		Resource: "authorizationpolicies",
+// resolveServicePodLabels returns the label sets of the pods backing a Service. When the Service
+// exists but has no pods, its selector is used as the only label set. The second return value is
+// false when the Service can't be resolved.
+func (a *Analyzer) resolveServicePodLabels(ctx context.Context, namespace, service string) ([]labels.Set, bool) {
+	svc, err := a.clientset.CoreV1().Services(namespace).Get(ctx, service, metav1.GetOptions{})
+	if err != nil || len(svc.Spec.Selector) == 0 {
+		return nil, false
+	}
+
+	pods, err := kube.ListPods(ctx, a.clientset, namespace, metav1.ListOptions{
+		LabelSelector: labels.SelectorFromSet(svc.Spec.Selector).String(),
+	})
+	if err != nil {
+		return nil, false
+	}
+
+	if len(pods.Items) == 0 {
+		return []labels.Set{svc.Spec.Selector}, true
+	}
+
+	labelSets := make([]labels.Set, 0, len(pods.Items))
+	for _, pod := range pods.Items {
+		labelSets = append(labelSets, pod.Labels)
+	}
+	return labelSets, true
+}
+
+// podSelectorAsSelector converts a Server podSelector (matchLabels and matchExpressions) into a label selector
+func podSelectorAsSelector(podSelector map[string]interface{}) (labels.Selector, error) {
+	var labelSelector metav1.LabelSelector
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(podSelector, &labelSelector); err != nil {
+		return nil, err
 	}
+	return metav1.LabelSelectorAsSelector(&labelSelector)
+}
 
-	authPolicies, err := a.dynamicClient.Resource(authPolicyGVR).Namespace(namespace).List(ctx, metav1.ListOptions{})
+// findAllowedSources finds all sources that can access the given servers. When explain is set,
+// the reasons why a Server grants nothing, fully or through some of its auth refs, are returned too.
+func (a *Analyzer) findAllowedSources(ctx context.Context, namespace string, matchingServers []string, explain bool) ([]map[string]interface{}, []map[string]interface{}, error) {
+	authPolicies, err := a.policiesTargetingNamespace(ctx, namespace)
 	if err != nil {
-		return nil, fmt.Errorf("failed to list AuthorizationPolicies: %v", err)
+		return nil, nil, fmt.Errorf("failed to list AuthorizationPolicies: %v", err)
 	}
 
 	// Map to track unique sources
 	sourcesMap := make(map[string]map[string]interface{})
 
-	for _, policy := range authPolicies.Items {
-		targetRef, found, err := unstructured.NestedMap(policy.Object, "spec", "targetRef")
-		if err != nil || !found {
-			continue
-		}
+	// Track which Servers are targeted by any policy, and why policies grant nothing
+	targetedServers := make(map[string]bool)
+	reasonsByServer := make(map[string][]map[string]interface{})
 
+	for _, policy := range authPolicies {
 		// Check if this policy targets one of our matching servers
-		targetName, _, _ := unstructured.NestedString(targetRef, "name")
-		isMatchingServer := false
+		targetName := ""
 		for _, serverName := range matchingServers {
-			if targetName == serverName {
-				isMatchingServer = true
+			if policyTargetsServer(policy, namespace, serverName) {
+				targetName = serverName
 				break
 			}
 		}
 
-		if !isMatchingServer {
+		// A policy targeting an HTTPRoute governs the route's parent Servers, for the requests
+		// the route matches only
+		grant, routeTargeted := routeGrant{}, false
+		if targetName == "" {
+			if grant, routeTargeted = a.policyRouteGrant(ctx, policy); routeTargeted {
+				for _, serverName := range matchingServers {
+					if grant.grantsServer(namespace, serverName) {
+						targetName = serverName
+						break
+					}
+				}
+			}
+		}
+
+		if targetName == "" {
 			continue
 		}
+		targetedServers[targetName] = true
+
+		if explain {
+			reasonsByServer[targetName] = append(reasonsByServer[targetName], a.explainAuthRefs(ctx, policy, false)...)
+		}
 
 		// Get the required authentications
 		requiredAuths, found, err := unstructured.NestedSlice(policy.Object, "spec", "requiredAuthenticationRefs")
@@ -97,20 +174,68 @@ func (a *Analyzer) findAllowedSources(ctx context.Context, namespace string, mat
 			authName, _, _ := unstructured.NestedString(authMap, "name")
 			authKind, _, _ := unstructured.NestedString(authMap, "kind")
 
-			sources := a.extractSourcesFromAuth(ctx, namespace, authName, authKind, policy.GetName())
+			sources := a.extractSourcesFromAuth(ctx, authRefNamespace(authMap, policy), authName, authKind, policy.GetName())
 			for key, source := range sources {
+				// Keep route grants apart from grants on the whole Server
+				if routeTargeted {
+					grant.annotate(source)
+					key += "@" + grant.namespace + "/" + grant.route
+				}
 				sourcesMap[key] = source
 			}
 		}
 	}
 
+	// Merge sources from legacy ServerAuthorizations targeting the same Servers
+	serverAuths := a.listServerAuthorizations(ctx, namespace)
+	if len(serverAuths) > 0 {
+		servers := []unstructured.Unstructured{}
+		for _, serverName := range matchingServers {
+			server, err := a.getResource(ctx, serverGVR, namespace, serverName)
+			if err != nil {
+				continue
+			}
+			servers = append(servers, *server)
+		}
+
+		for _, serverAuth := range serverAuths {
+			for _, server := range servers {
+				if !serverAuthorizationTargets(serverAuth, server) {
+					continue
+				}
+				targetedServers[server.GetName()] = true
+				for key, source := range extractSourcesFromServerAuthorization(serverAuth) {
+					sourcesMap[key] = source
+				}
+			}
+		}
+	}
+
 	// Convert map to slice
 	allowedSources := []map[string]interface{}{}
 	for _, source := range sourcesMap {
 		allowedSources = append(allowedSources, source)
 	}
 
-	return allowedSources, nil
+	deniedSources := []map[string]interface{}{}
+	if explain {
+		for _, serverName := range matchingServers {
+			reasons := reasonsByServer[serverName]
+			if !targetedServers[serverName] {
+				reasons = append(reasons, noMatchingPolicyDenial(serverName))
+			}
+			if len(reasons) == 0 {
+				continue
+			}
+			deniedSources = append(deniedSources, map[string]interface{}{
+				"namespace": namespace,
+				"server":    serverName,
+				"reasons":   reasons,
+			})
+		}
+	}
+
+	return allowedSources, deniedSources, nil
 }
 
 // extractSourcesFromAuth extracts sources from an authentication resource
@@ -135,7 +260,7 @@ func (a *Analyzer) extractSourcesFromAuth(ctx context.Context, namespace, authNa
 		return sources
 	}
 
-	auth, err := a.dynamicClient.Resource(authGVR).Namespace(namespace).Get(ctx, authName, metav1.GetOptions{})
+	auth, err := a.getResource(ctx, authGVR, namespace, authName)
 	if err != nil {
 		log.Printf("Warning: Failed to get authentication %s: %v", authName, err)
 		return sources
@@ -2,94 +2,208 @@ package policy
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
+	"net"
 
+	"github.com/christianhuening/linkerd-mcp/internal/kube"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
-	"k8s.io/apimachinery/pkg/runtime/schema"
 )
 
-// getServiceAccountForService retrieves the service account used by a service
-func (a *Analyzer) getServiceAccountForService(ctx context.Context, namespace, service string) (string, error) {
-	pods, err := a.clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{
+// errNoSourcePods is returned when a source service has no pods, e.g. while scaled to zero
+var errNoSourcePods = errors.New("no pods found")
+
+// getServiceAccountForService retrieves the service account used by a service. Without pods,
+// e.g. while the service is scaled to zero or rolling out, it is read from the pod template
+// of the service's Deployment or ReplicaSet, and defaults to "default" when there is none.
+// The note explains where a service account not read from a pod comes from.
+func (a *Analyzer) getServiceAccountForService(ctx context.Context, namespace, service string) (string, string, error) {
+	pod, err := a.findSourcePod(ctx, namespace, service)
+	if err == nil {
+		return podServiceAccount(pod), "", nil
+	}
+	if !errors.Is(err, errNoSourcePods) {
+		return "", "", err
+	}
+
+	if kind, name, template, ok := a.findSourcePodTemplate(ctx, namespace, service); ok {
+		serviceAccount := template.Spec.ServiceAccountName
+		if serviceAccount == "" {
+			serviceAccount = "default"
+		}
+		return serviceAccount, fmt.Sprintf("No pods found for service %s; the service account was read from the pod template of %s %s", service, kind, name), nil
+	}
+
+	return "default", fmt.Sprintf("No pods or pod template found for service %s; assuming the default service account", service), nil
+}
+
+// findSourcePodTemplate returns the pod template of the Deployment, or else the ReplicaSet, of
+// a source service: the one named after the service, or one whose pods carry its app label
+func (a *Analyzer) findSourcePodTemplate(ctx context.Context, namespace, service string) (string, string, *corev1.PodTemplateSpec, bool) {
+	matches := func(name string, template corev1.PodTemplateSpec) bool {
+		return name == service || template.Labels["app"] == service
+	}
+
+	if deployments, err := a.clientset.AppsV1().Deployments(namespace).List(ctx, metav1.ListOptions{}); err == nil {
+		for i := range deployments.Items {
+			deployment := &deployments.Items[i]
+			if matches(deployment.Name, deployment.Spec.Template) {
+				return "Deployment", deployment.Name, &deployment.Spec.Template, true
+			}
+		}
+	}
+
+	if replicaSets, err := a.clientset.AppsV1().ReplicaSets(namespace).List(ctx, metav1.ListOptions{}); err == nil {
+		for i := range replicaSets.Items {
+			replicaSet := &replicaSets.Items[i]
+			if matches(replicaSet.Name, replicaSet.Spec.Template) {
+				return "ReplicaSet", replicaSet.Name, &replicaSet.Spec.Template, true
+			}
+		}
+	}
+
+	return "", "", nil, false
+}
+
+// findSourcePod returns a pod of a source service, found by its app label
+func (a *Analyzer) findSourcePod(ctx context.Context, namespace, service string) (*corev1.Pod, error) {
+	pods, err := kube.ListPods(ctx, a.clientset, namespace, metav1.ListOptions{
 		LabelSelector: fmt.Sprintf("app=%s", service),
 	})
 	if err != nil {
-		return "", fmt.Errorf("failed to list source pods: %v", err)
+		return nil, fmt.Errorf("failed to list source pods: %v", err)
 	}
 
 	if len(pods.Items) == 0 {
-		return "", fmt.Errorf("no pods found for service %s in namespace %s", service, namespace)
-	}
-
-	serviceAccount := pods.Items[0].Spec.ServiceAccountName
-	if serviceAccount == "" {
-		serviceAccount = "default"
+		return nil, fmt.Errorf("%w for service %s in namespace %s", errNoSourcePods, service, namespace)
 	}
 
-	return serviceAccount, nil
+	return &pods.Items[0], nil
 }
 
-// findAllowedTargets finds all targets that a source with given service account can access
-func (a *Analyzer) findAllowedTargets(ctx context.Context, sourceNamespace, sourceServiceAccount string) ([]map[string]interface{}, error) {
-	serverGVR := schema.GroupVersionResource{
-		Group:    "policy.linkerd.io",
-		Version:  "v1beta3",
-		Resource: "servers",
+// sourcePodIPs returns the IPs of the running pods of a source service, found by its app label.
+// They are matched against the networks of NetworkAuthentications.
+func (a *Analyzer) sourcePodIPs(ctx context.Context, namespace, service string) []net.IP {
+	pods, err := kube.ListPods(ctx, a.clientset, namespace, metav1.ListOptions{
+		LabelSelector: fmt.Sprintf("app=%s", service),
+	})
+	if err != nil {
+		return nil
 	}
 
-	authPolicyGVR := schema.GroupVersionResource{
-		Group:    "policy.linkerd.io",
-		Version:  "v1alpha1",
-		Resource: "authorizationpolicies",
+	ips := []net.IP{}
+	for _, pod := range pods.Items {
+		if pod.Status.Phase != corev1.PodRunning {
+			continue
+		}
+		podIPs := pod.Status.PodIPs
+		if len(podIPs) == 0 && pod.Status.PodIP != "" {
+			podIPs = []corev1.PodIP{{IP: pod.Status.PodIP}}
+		}
+		for _, podIP := range podIPs {
+			if ip := net.ParseIP(podIP.IP); ip != nil {
+				ips = append(ips, ip)
+			}
+		}
 	}
+	return ips
+}
 
+// findAllowedTargets finds all targets that a source with given service account, and pod IPs
+// for NetworkAuthentications, can access. When explain is set, Servers the source can't reach
+// are returned with the denial reasons.
+func (a *Analyzer) findAllowedTargets(ctx context.Context, sourceNamespace, sourceServiceAccount string, sourceIPs []net.IP, explain bool) ([]map[string]interface{}, []map[string]interface{}, error) {
 	// Get all Servers in the cluster
-	serverList, err := a.dynamicClient.Resource(serverGVR).Namespace("").List(ctx, metav1.ListOptions{})
+	serverList, err := a.listResources(ctx, serverGVR, "")
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list Servers: %v (ensure Linkerd policy CRDs are installed)", err)
+	}
+
+	authPolicies, err := a.policiesTargetingNamespace(ctx, "")
 	if err != nil {
-		return nil, fmt.Errorf("failed to list Servers: %v (ensure Linkerd policy CRDs are installed)", err)
+		log.Printf("Warning: Failed to list AuthorizationPolicies: %v", err)
 	}
 
 	allowedTargets := []map[string]interface{}{}
+	deniedTargets := []map[string]interface{}{}
 
 	// For each Server, check if there's an AuthorizationPolicy allowing our source
 	for _, server := range serverList.Items {
 		serverNamespace := server.GetNamespace()
 		serverName := server.GetName()
-
-		// Get AuthorizationPolicies in the same namespace as the Server
-		authPolicies, err := a.dynamicClient.Resource(authPolicyGVR).Namespace(serverNamespace).List(ctx, metav1.ListOptions{})
-		if err != nil {
-			log.Printf("Warning: Failed to list AuthorizationPolicies in namespace %s: %v", serverNamespace, err)
-			continue
-		}
-
-		// Check each policy to see if it allows our source
-		for _, policy := range authPolicies.Items {
-			targetRef, found, err := unstructured.NestedMap(policy.Object, "spec", "targetRef")
-			if err != nil || !found {
-				continue
-			}
-
-			// Check if this policy targets our Server
-			targetName, _, _ := unstructured.NestedString(targetRef, "name")
-			if targetName != serverName {
-				continue
+		targeted := false
+		allowed := false
+		reasons := []map[string]interface{}{}
+
+		// Check each policy targeting this Server to see if it allows our source
+		for _, policy := range authPolicies {
+			grant, routeTargeted := routeGrant{}, false
+			if !policyTargetsServer(policy, serverNamespace, serverName) {
+				// A policy targeting an HTTPRoute governs the route's parent Servers
+				grant, routeTargeted = a.policyRouteGrant(ctx, policy)
+				if !routeTargeted || !grant.grantsServer(serverNamespace, serverName) {
+					continue
+				}
 			}
+			targeted = true
 
 			// Check if our source is allowed
-			if allowed := a.checkSourceAllowed(ctx, policy, serverNamespace, sourceNamespace, sourceServiceAccount); allowed {
+			if a.checkSourceAllowed(ctx, policy, sourceNamespace, sourceServiceAccount, sourceIPs) {
+				allowed = true
 				// Extract target service information from the Server
 				targetInfo := a.extractServerInfo(server, policy.GetName())
 				if targetInfo != nil {
+					if routeTargeted {
+						grant.annotate(targetInfo)
+					}
 					allowedTargets = append(allowedTargets, targetInfo)
 				}
+			} else if explain {
+				reasons = append(reasons, a.explainAuthRefs(ctx, policy, true)...)
+			}
+		}
+
+		// Check legacy ServerAuthorizations targeting this Server
+		for _, serverAuth := range a.listServerAuthorizations(ctx, serverNamespace) {
+			if !serverAuthorizationTargets(serverAuth, server) {
+				continue
+			}
+			targeted = true
+			if !checkServerAuthorizationAllowed(serverAuth, sourceNamespace, sourceServiceAccount) {
+				if explain {
+					denial := newDenial(denialIdentityNotAuthorized,
+						fmt.Sprintf("ServerAuthorization %s does not include the source identity", serverAuth.GetName()))
+					denial["serverAuthorization"] = serverAuth.GetName()
+					reasons = append(reasons, denial)
+				}
+				continue
 			}
+			allowed = true
+			targetInfo := a.extractServerInfo(server, "")
+			if targetInfo != nil {
+				delete(targetInfo, "authorizationPolicy")
+				targetInfo["serverAuthorization"] = serverAuth.GetName()
+				allowedTargets = append(allowedTargets, targetInfo)
+			}
+		}
+
+		if !explain || allowed {
+			continue
+		}
+		if !targeted {
+			reasons = append(reasons, noMatchingPolicyDenial(serverName))
 		}
+		deniedTargets = append(deniedTargets, map[string]interface{}{
+			"namespace": serverNamespace,
+			"server":    serverName,
+			"reasons":   reasons,
+		})
 	}
 
-	return allowedTargets, nil
+	return allowedTargets, deniedTargets, nil
 }
 
 // extractServerInfo extracts relevant information from a Server resource
@@ -0,0 +1,219 @@
+// Package scorecard combines metrics health and policy coverage into a per-service view that
+// answers whether a service is both healthy and secure
+package scorecard
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/christianhuening/linkerd-mcp/internal/metrics"
+	"github.com/christianhuening/linkerd-mcp/internal/policy"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// DefaultMinMTLSPercent is the share of inbound requests that must use mTLS for a service with
+// traffic to count as secure. It leaves room for plaintext kubelet probes.
+const DefaultMinMTLSPercent = 95.0
+
+// ServiceScorecard is the combined health and security assessment of a service
+type ServiceScorecard struct {
+	Service   string `json:"service"`
+	Namespace string `json:"namespace"`
+
+	HealthStatus metrics.HealthStatus  `json:"healthStatus"`
+	RequestRate  *float64              `json:"requestRate"` // requests per second; null without data
+	SuccessRate  *float64              `json:"successRate"` // percentage (0-100); null without data
+	LatencyP95   *float64              `json:"latencyP95"`  // milliseconds; null without data
+	HealthIssues []metrics.HealthIssue `json:"healthIssues,omitempty"`
+
+	PolicyProtected      bool     `json:"policyProtected"`
+	Servers              []string `json:"servers"`
+	AuthorizedServers    []string `json:"authorizedServers"`
+	DefaultInboundPolicy string   `json:"defaultInboundPolicy,omitempty"`
+	TotalPods            int      `json:"totalPods"`
+	MeshedPods           int      `json:"meshedPods"`
+	MTLSPercent          *float64 `json:"mtlsPercent"` // share of inbound requests over mTLS; null without traffic
+
+	Healthy bool     `json:"healthy"`
+	Secure  bool     `json:"secure"`
+	Issues  []string `json:"issues"`
+}
+
+// Builder assembles service scorecards from the metrics collector and the policy analyzer
+type Builder struct {
+	metricsCollector *metrics.MetricsCollector
+	policyAnalyzer   *policy.Analyzer
+}
+
+// NewBuilder creates a new scorecard builder. The metrics collector may be nil, in which case
+// every service's health is reported as unknown.
+func NewBuilder(metricsCollector *metrics.MetricsCollector, policyAnalyzer *policy.Analyzer) *Builder {
+	return &Builder{
+		metricsCollector: metricsCollector,
+		policyAnalyzer:   policyAnalyzer,
+	}
+}
+
+// GetServiceScorecard builds the scorecard of every service in a namespace
+func (b *Builder) GetServiceScorecard(ctx context.Context, namespace, timeRangeStr string, thresholds metrics.HealthThresholds) (*mcp.CallToolResult, error) {
+	tr, err := metrics.ParseTimeRange(timeRangeStr)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Invalid time range: %v", err)), nil
+	}
+
+	coverage, err := b.policyAnalyzer.GetServicePolicyCoverage(ctx, namespace)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	metricsAvailable := b.metricsCollector.Available()
+	health := []metrics.ServiceHealthSummary{}
+	mtls := map[string]float64{}
+	var warnings []string
+	if metricsAvailable {
+		if health, warnings, err = b.metricsCollector.ServiceHealthSummaries(ctx, namespace, tr, thresholds); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to get service health: %v", err)), nil
+		}
+		if mtls, err = b.metricsCollector.MTLSCoverage(ctx, namespace, tr); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to get mTLS coverage: %v", err)), nil
+		}
+	}
+
+	scorecards := BuildScorecards(namespace, health, coverage, mtls)
+	summary := map[string]int{"total": len(scorecards), "healthy": 0, "secure": 0, "healthyAndSecure": 0}
+	for _, scorecard := range scorecards {
+		if scorecard.Healthy {
+			summary["healthy"]++
+		}
+		if scorecard.Secure {
+			summary["secure"]++
+		}
+		if scorecard.Healthy && scorecard.Secure {
+			summary["healthyAndSecure"]++
+		}
+	}
+
+	result := map[string]interface{}{
+		"namespace":        namespace,
+		"timeRange":        tr,
+		"metricsAvailable": metricsAvailable,
+		"services":         scorecards,
+		"summary":          summary,
+	}
+	if len(warnings) > 0 {
+		result["warnings"] = warnings
+	}
+
+	resultJSON, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return mcp.NewToolResultError("Failed to serialize scorecard"), nil
+	}
+
+	return mcp.NewToolResultText(string(resultJSON)), nil
+}
+
+// BuildScorecards joins health summaries, policy coverage and mTLS coverage by service name.
+// Services only known to one source are included with the other fields left at their defaults;
+// services without metrics have an unknown health status.
+func BuildScorecards(namespace string, health []metrics.ServiceHealthSummary, coverage []policy.ServicePolicyCoverage, mtls map[string]float64) []ServiceScorecard {
+	scorecards := map[string]*ServiceScorecard{}
+	get := func(service string) *ServiceScorecard {
+		if scorecard, ok := scorecards[service]; ok {
+			return scorecard
+		}
+		scorecard := &ServiceScorecard{
+			Service:           service,
+			Namespace:         namespace,
+			HealthStatus:      metrics.HealthStatusUnknown,
+			Servers:           []string{},
+			AuthorizedServers: []string{},
+			Issues:            []string{},
+		}
+		scorecards[service] = scorecard
+		return scorecard
+	}
+
+	for _, summary := range health {
+		scorecard := get(summary.Service)
+		scorecard.HealthStatus = summary.HealthStatus
+		scorecard.RequestRate = summary.RequestRate
+		scorecard.SuccessRate = summary.SuccessRate
+		scorecard.LatencyP95 = summary.LatencyP95
+		scorecard.HealthIssues = summary.Issues
+	}
+
+	for _, entry := range coverage {
+		scorecard := get(entry.Service)
+		scorecard.PolicyProtected = entry.Protected
+		scorecard.Servers = entry.Servers
+		scorecard.AuthorizedServers = entry.AuthorizedServers
+		scorecard.DefaultInboundPolicy = entry.DefaultInboundPolicy
+		scorecard.TotalPods = entry.TotalPods
+		scorecard.MeshedPods = entry.MeshedPods
+	}
+
+	for service, percent := range mtls {
+		if scorecard, ok := scorecards[service]; ok {
+			percent := percent
+			scorecard.MTLSPercent = &percent
+		}
+	}
+
+	result := make([]ServiceScorecard, 0, len(scorecards))
+	for _, scorecard := range scorecards {
+		assess(scorecard)
+		result = append(result, *scorecard)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Service < result[j].Service })
+	return result
+}
+
+// belowMinimumRequestRate reports whether the health issues say the request rate is below the
+// minimum needed to assess health
+func belowMinimumRequestRate(issues []metrics.HealthIssue) bool {
+	for _, issue := range issues {
+		if issue.Metric == "request_rate" && issue.Value < issue.Threshold {
+			return true
+		}
+	}
+	return false
+}
+
+// assess decides whether a service is healthy and secure, and lists what stands in the way
+func assess(scorecard *ServiceScorecard) {
+	scorecard.Healthy = scorecard.HealthStatus == metrics.HealthStatusHealthy
+	switch scorecard.HealthStatus {
+	case metrics.HealthStatusHealthy:
+	case metrics.HealthStatusUnknown:
+		switch {
+		case scorecard.RequestRate == nil:
+			scorecard.Issues = append(scorecard.Issues, "no metrics for the service")
+		case belowMinimumRequestRate(scorecard.HealthIssues):
+			scorecard.Issues = append(scorecard.Issues, "too little traffic to assess health")
+		default:
+			scorecard.Issues = append(scorecard.Issues, "metrics needed to assess health are unavailable")
+		}
+	default:
+		scorecard.Issues = append(scorecard.Issues, fmt.Sprintf("service is %s", scorecard.HealthStatus))
+	}
+
+	scorecard.Secure = true
+	if !scorecard.PolicyProtected {
+		scorecard.Secure = false
+		if len(scorecard.Servers) > 0 {
+			scorecard.Issues = append(scorecard.Issues, "Servers select the service but no AuthorizationPolicy targets them")
+		} else {
+			scorecard.Issues = append(scorecard.Issues, "no Server selects the service")
+		}
+	}
+	if scorecard.MeshedPods < scorecard.TotalPods {
+		scorecard.Secure = false
+		scorecard.Issues = append(scorecard.Issues, fmt.Sprintf("%d of %d pods are not meshed", scorecard.TotalPods-scorecard.MeshedPods, scorecard.TotalPods))
+	}
+	if scorecard.MTLSPercent != nil && *scorecard.MTLSPercent < DefaultMinMTLSPercent {
+		scorecard.Secure = false
+		scorecard.Issues = append(scorecard.Issues, fmt.Sprintf("only %.1f%% of inbound requests use mTLS", *scorecard.MTLSPercent))
+	}
+}
@@ -0,0 +1,13 @@
+package scorecard_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestScorecard(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Scorecard Suite")
+}
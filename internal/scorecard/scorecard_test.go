@@ -0,0 +1,177 @@
+package scorecard_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/christianhuening/linkerd-mcp/internal/metrics"
+	"github.com/christianhuening/linkerd-mcp/internal/policy"
+	"github.com/christianhuening/linkerd-mcp/internal/scorecard"
+	"github.com/christianhuening/linkerd-mcp/internal/testutil"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/fake"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+)
+
+func value(v float64) *float64 {
+	return &v
+}
+
+var _ = Describe("Scorecard", func() {
+	Describe("BuildScorecards", func() {
+		var (
+			health   []metrics.ServiceHealthSummary
+			coverage []policy.ServicePolicyCoverage
+			mtls     map[string]float64
+		)
+
+		BeforeEach(func() {
+			health = []metrics.ServiceHealthSummary{
+				{Service: "api", HealthStatus: metrics.HealthStatusHealthy, RequestRate: value(12.5), SuccessRate: value(99.9), LatencyP95: value(40)},
+				{Service: "web", HealthStatus: metrics.HealthStatusDegraded, RequestRate: value(3), SuccessRate: value(97), LatencyP95: value(600),
+					Issues: []metrics.HealthIssue{{Severity: "warning", Description: "p95 latency is high"}}},
+				{Service: "worker", HealthStatus: metrics.HealthStatusHealthy, RequestRate: value(1), SuccessRate: value(100), LatencyP95: value(10)},
+			}
+			coverage = []policy.ServicePolicyCoverage{
+				{Service: "api", Servers: []string{"api-server"}, AuthorizedServers: []string{"api-server"}, DefaultInboundPolicy: "all-unauthenticated", TotalPods: 2, MeshedPods: 2, Protected: true},
+				{Service: "web", Servers: []string{"web-server"}, AuthorizedServers: []string{}, DefaultInboundPolicy: "all-unauthenticated", TotalPods: 1, MeshedPods: 1},
+				{Service: "worker", Servers: []string{}, AuthorizedServers: []string{}, DefaultInboundPolicy: "deny", TotalPods: 2, MeshedPods: 1, Protected: true},
+				{Service: "legacy", Servers: []string{}, AuthorizedServers: []string{}, DefaultInboundPolicy: "all-unauthenticated", TotalPods: 1},
+			}
+			mtls = map[string]float64{"api": 100, "web": 100, "worker": 50}
+		})
+
+		byService := func(scorecards []scorecard.ServiceScorecard) map[string]scorecard.ServiceScorecard {
+			result := map[string]scorecard.ServiceScorecard{}
+			for _, entry := range scorecards {
+				result[entry.Service] = entry
+			}
+			return result
+		}
+
+		It("should merge health, policy and mTLS coverage per service", func() {
+			scorecards := scorecard.BuildScorecards("prod", health, coverage, mtls)
+			Expect(scorecards).To(HaveLen(4))
+			Expect(scorecards[0].Service).To(Equal("api"))
+
+			api := byService(scorecards)["api"]
+			Expect(api.Namespace).To(Equal("prod"))
+			Expect(api.HealthStatus).To(Equal(metrics.HealthStatusHealthy))
+			Expect(*api.RequestRate).To(Equal(12.5))
+			Expect(api.PolicyProtected).To(BeTrue())
+			Expect(api.AuthorizedServers).To(ConsistOf("api-server"))
+			Expect(*api.MTLSPercent).To(Equal(100.0))
+			Expect(api.Healthy).To(BeTrue())
+			Expect(api.Secure).To(BeTrue())
+			Expect(api.Issues).To(BeEmpty())
+		})
+
+		It("should flag degraded services with unauthorized Servers", func() {
+			web := byService(scorecard.BuildScorecards("prod", health, coverage, mtls))["web"]
+			Expect(web.HealthStatus).To(Equal(metrics.HealthStatusDegraded))
+			Expect(web.HealthIssues).To(HaveLen(1))
+			Expect(web.Healthy).To(BeFalse())
+			Expect(web.PolicyProtected).To(BeFalse())
+			Expect(web.Secure).To(BeFalse())
+			Expect(web.Issues).To(ConsistOf(
+				"service is degraded",
+				"Servers select the service but no AuthorizationPolicy targets them",
+			))
+		})
+
+		It("should not count services with unmeshed pods or plaintext traffic as secure", func() {
+			worker := byService(scorecard.BuildScorecards("prod", health, coverage, mtls))["worker"]
+			Expect(worker.Healthy).To(BeTrue())
+			Expect(worker.PolicyProtected).To(BeTrue())
+			Expect(worker.Secure).To(BeFalse())
+			Expect(worker.Issues).To(ConsistOf(
+				"1 of 2 pods are not meshed",
+				"only 50.0% of inbound requests use mTLS",
+			))
+		})
+
+		It("should report services without metrics as unknown", func() {
+			legacy := byService(scorecard.BuildScorecards("prod", health, coverage, mtls))["legacy"]
+			Expect(legacy.HealthStatus).To(Equal(metrics.HealthStatusUnknown))
+			Expect(legacy.MTLSPercent).To(BeNil())
+			Expect(legacy.Healthy).To(BeFalse())
+			Expect(legacy.Secure).To(BeFalse())
+			Expect(legacy.Issues).To(ContainElements("no metrics for the service", "no Server selects the service"))
+		})
+
+		It("should report services with too little traffic as unknown", func() {
+			health = append(health, metrics.ServiceHealthSummary{
+				Service: "idle", HealthStatus: metrics.HealthStatusUnknown, RequestRate: value(0.01),
+				Issues: []metrics.HealthIssue{{Severity: "info", Metric: "request_rate", Value: 0.01, Threshold: 0.1}},
+			})
+
+			idle := byService(scorecard.BuildScorecards("prod", health, coverage, mtls))["idle"]
+			Expect(idle.Healthy).To(BeFalse())
+			Expect(idle.Issues).To(ContainElement("too little traffic to assess health"))
+			Expect(idle.Issues).NotTo(ContainElement("no metrics for the service"))
+		})
+
+		It("should report services whose metrics are partly unavailable as unknown", func() {
+			health = append(health, metrics.ServiceHealthSummary{
+				Service: "partial", HealthStatus: metrics.HealthStatusUnknown, RequestRate: value(5),
+				Issues: []metrics.HealthIssue{{Severity: "info", Metric: "success_rate"}},
+			})
+
+			partial := byService(scorecard.BuildScorecards("prod", health, coverage, mtls))["partial"]
+			Expect(partial.Healthy).To(BeFalse())
+			Expect(partial.SuccessRate).To(BeNil())
+			Expect(partial.Issues).To(ContainElement("metrics needed to assess health are unavailable"))
+		})
+	})
+
+	Describe("GetServiceScorecard", func() {
+		It("should report policy coverage when metrics are unavailable", func() {
+			ctx := context.Background()
+			serverGVR := schema.GroupVersionResource{Group: "policy.linkerd.io", Version: "v1beta3", Resource: "servers"}
+			authPolicyGVR := schema.GroupVersionResource{Group: "policy.linkerd.io", Version: "v1alpha1", Resource: "authorizationpolicies"}
+			gvrToListKind := map[schema.GroupVersionResource]string{
+				serverGVR:     "ServerList",
+				authPolicyGVR: "AuthorizationPolicyList",
+				{Group: "policy.linkerd.io", Version: "v1alpha1", Resource: "meshtlsauthentications"}: "MeshTLSAuthenticationList",
+				{Group: "policy.linkerd.io", Version: "v1beta1", Resource: "serverauthorizations"}:    "ServerAuthorizationList",
+			}
+
+			kubeClient := kubefake.NewSimpleClientset(
+				testutil.CreateService("api", "prod", map[string]string{"app": "api"}),
+				testutil.CreateMeshedPod("api-1", "prod", "api"),
+			)
+			dynamicClient := fake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), gvrToListKind)
+			_, err := dynamicClient.Resource(serverGVR).Namespace("prod").Create(ctx,
+				testutil.CreateServer("api-server", "prod", map[string]string{"app": "api"}, 8080), metav1.CreateOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			_, err = dynamicClient.Resource(authPolicyGVR).Namespace("prod").Create(ctx,
+				testutil.CreateAuthorizationPolicy("api-clients", "prod", "api-server",
+					[]map[string]string{{"name": "clients", "kind": "MeshTLSAuthentication"}}), metav1.CreateOptions{})
+			Expect(err).NotTo(HaveOccurred())
+
+			builder := scorecard.NewBuilder(nil, policy.NewAnalyzer(kubeClient, dynamicClient))
+			result, err := builder.GetServiceScorecard(ctx, "prod", "", metrics.DefaultHealthThresholds())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.IsError).To(BeFalse())
+
+			var response struct {
+				MetricsAvailable bool                         `json:"metricsAvailable"`
+				Services         []scorecard.ServiceScorecard `json:"services"`
+				Summary          map[string]int               `json:"summary"`
+			}
+			Expect(testutil.ParseJSONResult(result, &response)).To(Succeed())
+
+			Expect(response.MetricsAvailable).To(BeFalse())
+			Expect(response.Services).To(HaveLen(1))
+			Expect(response.Services[0].HealthStatus).To(Equal(metrics.HealthStatusUnknown))
+			Expect(response.Services[0].PolicyProtected).To(BeTrue())
+			Expect(response.Services[0].Secure).To(BeTrue())
+			Expect(response.Summary).To(HaveKeyWithValue("secure", 1))
+			Expect(response.Summary).To(HaveKeyWithValue("healthyAndSecure", 0))
+		})
+	})
+})
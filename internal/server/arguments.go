@@ -0,0 +1,188 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// withArgumentValidation checks the arguments of a call against the input schema of its tool
+// before invoking the handler, so a call with an argument of the wrong type, such as "limit": "10",
+// is rejected with the name of the tool rather than answered with its defaults.
+func withArgumentValidation(tool mcp.Tool, handler server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		if err := validateArguments(tool.InputSchema, request.GetArguments()); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Invalid arguments for %s: %v", tool.Name, err)), nil
+		}
+		return handler(ctx, request)
+	}
+}
+
+// validateArguments checks that the required arguments are present, and that every argument
+// declared by the schema has the declared JSON type and, for enums, one of the allowed values.
+// Arguments the schema doesn't declare are left to the handler. A null argument counts as absent.
+func validateArguments(schema mcp.ToolInputSchema, args map[string]interface{}) error {
+	for _, name := range schema.Required {
+		if args[name] == nil {
+			return fmt.Errorf("missing required argument '%s'", name)
+		}
+	}
+
+	names := make([]string, 0, len(args))
+	for name := range args {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		value := args[name]
+		property, ok := schema.Properties[name].(map[string]any)
+		if !ok || value == nil {
+			continue
+		}
+
+		expected, _ := property["type"].(string)
+		if actual := jsonType(value); expected != "" && actual != expected {
+			return fmt.Errorf("argument '%s' must be a %s, got %s", name, expected, actual)
+		}
+
+		if allowed, ok := property["enum"].([]string); ok {
+			if text, _ := value.(string); text != "" && !containsString(allowed, text) {
+				return fmt.Errorf("argument '%s' must be one of: %s, got '%s'", name, strings.Join(allowed, ", "), text)
+			}
+		}
+	}
+	return nil
+}
+
+// jsonType names the JSON schema type of a decoded JSON value
+func jsonType(value interface{}) string {
+	switch value.(type) {
+	case string:
+		return "string"
+	case float64, int, int64:
+		return "number"
+	case bool:
+		return "boolean"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// toolArguments reads the arguments of a tool call with typed getters. Absent and null arguments
+// return the given fallback; an argument of another type returns it too and records an error,
+// the first of which Err reports, so handlers check once after reading all their arguments.
+type toolArguments struct {
+	values map[string]interface{}
+	err    error
+}
+
+// toolArgumentsOf returns the arguments of a tool call
+func toolArgumentsOf(request mcp.CallToolRequest) *toolArguments {
+	return &toolArguments{values: request.GetArguments()}
+}
+
+// Err returns the first argument that couldn't be read as the requested type
+func (a *toolArguments) Err() error {
+	return a.err
+}
+
+// invalidArguments is the result of a call whose arguments couldn't be read
+func invalidArguments(err error) *mcp.CallToolResult {
+	return mcp.NewToolResultError(fmt.Sprintf("Invalid arguments: %v", err))
+}
+
+func (a *toolArguments) fail(name, expected string, value interface{}) {
+	if a.err == nil {
+		a.err = fmt.Errorf("argument '%s' must be %s, got %s", name, expected, jsonType(value))
+	}
+}
+
+// stringArg returns a string argument, or fallback when it is absent
+func (a *toolArguments) stringArg(name, fallback string) string {
+	value := a.values[name]
+	if value == nil {
+		return fallback
+	}
+	text, ok := value.(string)
+	if !ok {
+		a.fail(name, "a string", value)
+		return fallback
+	}
+	return text
+}
+
+// boolArg returns a boolean argument, or fallback when it is absent
+func (a *toolArguments) boolArg(name string, fallback bool) bool {
+	value := a.values[name]
+	if value == nil {
+		return fallback
+	}
+	flag, ok := value.(bool)
+	if !ok {
+		a.fail(name, "a boolean", value)
+		return fallback
+	}
+	return flag
+}
+
+// floatArg returns a number argument, or fallback when it is absent
+func (a *toolArguments) floatArg(name string, fallback float64) float64 {
+	switch value := a.values[name].(type) {
+	case nil:
+		return fallback
+	case float64:
+		return value
+	case int:
+		return float64(value)
+	case int64:
+		return float64(value)
+	default:
+		a.fail(name, "a number", value)
+		return fallback
+	}
+}
+
+// intArg returns an integer argument, or fallback when it is absent. Numbers with a fraction are
+// rejected rather than truncated.
+func (a *toolArguments) intArg(name string, fallback int) int {
+	value := a.values[name]
+	if value == nil {
+		return fallback
+	}
+	number := a.floatArg(name, float64(fallback))
+	if number != math.Trunc(number) {
+		if a.err == nil {
+			a.err = fmt.Errorf("argument '%s' must be an integer, got %g", name, number)
+		}
+		return fallback
+	}
+	return int(number)
+}
+
+// optionalFloatArg returns a number argument, or nil when it is absent
+func (a *toolArguments) optionalFloatArg(name string) *float64 {
+	if a.values[name] == nil {
+		return nil
+	}
+	number := a.floatArg(name, 0)
+	return &number
+}
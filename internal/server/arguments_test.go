@@ -0,0 +1,120 @@
+package server_test
+
+import (
+	"context"
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/christianhuening/linkerd-mcp/internal/server"
+	"github.com/christianhuening/linkerd-mcp/internal/testutil"
+	"github.com/mark3labs/mcp-go/mcp"
+	mcpserver "github.com/mark3labs/mcp-go/server"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/dynamic/fake"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+)
+
+var _ = Describe("Argument validation", func() {
+	Describe("ValidateArguments", func() {
+		tool := mcp.NewTool("get_things",
+			mcp.WithString("namespace", mcp.Required()),
+			mcp.WithNumber("limit"),
+			mcp.WithBoolean("verbose"),
+			mcp.WithString("direction", mcp.Enum("inbound", "outbound")),
+		)
+
+		It("should accept arguments of the declared types", func() {
+			Expect(server.ValidateArguments(tool.InputSchema, map[string]interface{}{
+				"namespace": "prod",
+				"limit":     float64(10),
+				"verbose":   true,
+				"direction": "outbound",
+				"unknown":   "left to the handler",
+			})).To(Succeed())
+		})
+
+		It("should reject a missing or null required argument", func() {
+			Expect(server.ValidateArguments(tool.InputSchema, map[string]interface{}{})).
+				To(MatchError("missing required argument 'namespace'"))
+			Expect(server.ValidateArguments(tool.InputSchema, map[string]interface{}{"namespace": nil})).
+				To(MatchError("missing required argument 'namespace'"))
+		})
+
+		It("should reject arguments of the wrong type", func() {
+			Expect(server.ValidateArguments(tool.InputSchema, map[string]interface{}{"namespace": "prod", "limit": "10"})).
+				To(MatchError("argument 'limit' must be a number, got string"))
+			Expect(server.ValidateArguments(tool.InputSchema, map[string]interface{}{"namespace": "prod", "verbose": "true"})).
+				To(MatchError("argument 'verbose' must be a boolean, got string"))
+		})
+
+		It("should reject values outside an enum", func() {
+			Expect(server.ValidateArguments(tool.InputSchema, map[string]interface{}{"namespace": "prod", "direction": "sideways"})).
+				To(MatchError(ContainSubstring("must be one of: inbound, outbound")))
+		})
+	})
+
+	Describe("ToolArguments", func() {
+		It("should return arguments of the requested type", func() {
+			args := server.NewToolArguments(map[string]interface{}{
+				"namespace": "prod",
+				"verbose":   true,
+				"limit":     float64(5),
+				"threshold": 0.25,
+			})
+			Expect(args.String("namespace", "")).To(Equal("prod"))
+			Expect(args.Bool("verbose", false)).To(BeTrue())
+			Expect(args.Int("limit", 10)).To(Equal(5))
+			Expect(args.Float("threshold", 0.1)).To(Equal(0.25))
+			Expect(*args.OptionalFloat("threshold")).To(Equal(0.25))
+			Expect(args.Err()).NotTo(HaveOccurred())
+		})
+
+		It("should return the fallback for absent or null arguments", func() {
+			args := server.NewToolArguments(map[string]interface{}{"limit": nil})
+			Expect(args.String("namespace", "default")).To(Equal("default"))
+			Expect(args.Bool("tap", true)).To(BeTrue())
+			Expect(args.Int("limit", 10)).To(Equal(10))
+			Expect(args.Float("threshold", 0.1)).To(Equal(0.1))
+			Expect(args.OptionalFloat("threshold")).To(BeNil())
+			Expect(args.Err()).NotTo(HaveOccurred())
+		})
+
+		It("should record the first argument of the wrong type", func() {
+			args := server.NewToolArguments(map[string]interface{}{"limit": "10", "verbose": "true"})
+			Expect(args.Int("limit", 10)).To(Equal(10))
+			Expect(args.Bool("verbose", false)).To(BeFalse())
+			Expect(args.Err()).To(MatchError("argument 'limit' must be a number, got string"))
+		})
+
+		It("should reject numbers with a fraction for integer arguments", func() {
+			args := server.NewToolArguments(map[string]interface{}{"limit": 2.5})
+			Expect(args.Int("limit", 10)).To(Equal(10))
+			Expect(args.Err()).To(MatchError("argument 'limit' must be an integer, got 2.5"))
+		})
+	})
+
+	It("should answer registered tools called with invalid arguments with a tool error", func() {
+		mcpSrv := mcpserver.NewMCPServer("test-server", "1.0.0", mcpserver.WithToolCapabilities(true))
+		server.NewWithClients(kubefake.NewSimpleClientset(), fake.NewSimpleDynamicClient(runtime.NewScheme())).RegisterTools(mcpSrv)
+
+		message, err := json.Marshal(map[string]interface{}{
+			"jsonrpc": "2.0",
+			"id":      1,
+			"method":  "tools/call",
+			"params": map[string]interface{}{
+				"name":      "list_meshed_services",
+				"arguments": map[string]interface{}{"include_control_plane": "yes"},
+			},
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		result := mcpSrv.HandleMessage(context.Background(), message).(mcp.JSONRPCResponse).Result.(mcp.CallToolResult)
+		Expect(result.IsError).To(BeTrue())
+
+		var text string
+		Expect(testutil.GetTextFromResult(&result, &text)).To(Succeed())
+		Expect(text).To(Equal("Invalid arguments for list_meshed_services: argument 'include_control_plane' must be a boolean, got string"))
+	})
+})
@@ -0,0 +1,109 @@
+package server
+
+import (
+	"time"
+
+	"github.com/christianhuening/linkerd-mcp/internal/config"
+	"github.com/christianhuening/linkerd-mcp/internal/health"
+	"github.com/christianhuening/linkerd-mcp/internal/mesh"
+	"github.com/christianhuening/linkerd-mcp/internal/metrics"
+	"github.com/christianhuening/linkerd-mcp/internal/policy"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+)
+
+// CheckPrometheus exposes the Prometheus startup probe to tests
+var CheckPrometheus = checkPrometheus
+
+// CheckKubernetes exposes the readiness check to tests
+var CheckKubernetes = checkKubernetes
+
+// HealthThresholds exposes health threshold argument parsing to tests
+func HealthThresholds(args map[string]interface{}) metrics.HealthThresholds {
+	return healthThresholds(&toolArguments{values: args})
+}
+
+// WithTimeout exposes the tool timeout wrapper to tests
+var WithTimeout = withTimeout
+
+// ValidateArguments exposes tool argument validation to tests
+var ValidateArguments = validateArguments
+
+// NewWithClients builds a server around the given clients, without Prometheus or tap support
+func NewWithClients(clientset kubernetes.Interface, dynamicClient dynamic.Interface) *LinkerdMCPServer {
+	return &LinkerdMCPServer{
+		healthChecker:  health.NewChecker(clientset),
+		serviceLister:  mesh.NewServiceLister(clientset),
+		policyAnalyzer: policy.NewAnalyzer(clientset, dynamicClient),
+	}
+}
+
+// NewWithMetrics builds a server around the given clients and metrics collector, defaulting the
+// namespace of metrics tools to metricsNamespace
+func NewWithMetrics(clientset kubernetes.Interface, dynamicClient dynamic.Interface, collector *metrics.MetricsCollector, metricsNamespace string) *LinkerdMCPServer {
+	s := NewWithClients(clientset, dynamicClient)
+	s.metricsCollector = collector
+	s.metricsNamespace = metricsNamespace
+	return s
+}
+
+// NewWithPromQLPassthrough builds a server around the given clients and metrics collector that
+// registers query_linkerd_metrics in the given mode
+func NewWithPromQLPassthrough(clientset kubernetes.Interface, dynamicClient dynamic.Interface, collector *metrics.MetricsCollector, mode config.PromQLPassthroughMode) *LinkerdMCPServer {
+	s := NewWithMetrics(clientset, dynamicClient, collector, "")
+	s.promQLMode = mode
+	return s
+}
+
+// NewWithToolFilter builds a server around the given clients that only registers the tools the
+// filter allows
+func NewWithToolFilter(clientset kubernetes.Interface, dynamicClient dynamic.Interface, filter config.ToolFilter) *LinkerdMCPServer {
+	s := NewWithClients(clientset, dynamicClient)
+	s.toolFilter = filter
+	return s
+}
+
+// NewWithKubernetesCheck builds a server that re-checks the API server every interval, starting
+// from the outcome of a startup check
+func NewWithKubernetesCheck(discoveryClient discovery.DiscoveryInterface, interval time.Duration, startupErr error) *LinkerdMCPServer {
+	return &LinkerdMCPServer{
+		discoveryClient: discoveryClient,
+		kubeStatus:      &clusterStatus{err: startupErr},
+		kubeInterval:    interval,
+	}
+}
+
+// ToolArguments exposes the typed tool argument getters to tests
+type ToolArguments struct {
+	args *toolArguments
+}
+
+// NewToolArguments reads the given tool call arguments
+func NewToolArguments(values map[string]interface{}) ToolArguments {
+	return ToolArguments{args: &toolArguments{values: values}}
+}
+
+func (a ToolArguments) String(name, fallback string) string {
+	return a.args.stringArg(name, fallback)
+}
+
+func (a ToolArguments) Bool(name string, fallback bool) bool {
+	return a.args.boolArg(name, fallback)
+}
+
+func (a ToolArguments) Int(name string, fallback int) int {
+	return a.args.intArg(name, fallback)
+}
+
+func (a ToolArguments) Float(name string, fallback float64) float64 {
+	return a.args.floatArg(name, fallback)
+}
+
+func (a ToolArguments) OptionalFloat(name string) *float64 {
+	return a.args.optionalFloatArg(name)
+}
+
+func (a ToolArguments) Err() error {
+	return a.args.Err()
+}
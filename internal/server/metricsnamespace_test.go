@@ -0,0 +1,164 @@
+package server_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/christianhuening/linkerd-mcp/internal/config"
+	"github.com/christianhuening/linkerd-mcp/internal/metrics"
+	"github.com/christianhuening/linkerd-mcp/internal/server"
+	"github.com/mark3labs/mcp-go/mcp"
+	mcpserver "github.com/mark3labs/mcp-go/server"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/dynamic/fake"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+)
+
+var _ = Describe("Default metrics namespace", func() {
+	var (
+		ctx     context.Context
+		mu      sync.Mutex
+		queries []string
+	)
+
+	// collector returns a metrics collector whose Prometheus records every query
+	collector := func() *metrics.MetricsCollector {
+		queries = nil
+		prometheus := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			Expect(r.ParseForm()).To(Succeed())
+			mu.Lock()
+			queries = append(queries, r.Form.Get("query"))
+			mu.Unlock()
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"status":"success","data":{"resultType":"vector","result":[]}}`))
+		}))
+		DeferCleanup(prometheus.Close)
+
+		os.Setenv("LINKERD_PROMETHEUS_URL", prometheus.URL)
+		DeferCleanup(os.Unsetenv, "LINKERD_PROMETHEUS_URL")
+		collector, err := metrics.NewMetricsCollector(nil, nil, nil, "linkerd")
+		Expect(err).NotTo(HaveOccurred())
+		return collector
+	}
+
+	// mcpServerFor registers the tools of a server with a Prometheus recording every query
+	mcpServerFor := func(metricsNamespace string) *mcpserver.MCPServer {
+		mcpSrv := mcpserver.NewMCPServer("test-server", "1.0.0", mcpserver.WithToolCapabilities(true))
+		server.NewWithMetrics(kubefake.NewSimpleClientset(), fake.NewSimpleDynamicClient(runtime.NewScheme()), collector(), metricsNamespace).RegisterTools(mcpSrv)
+		return mcpSrv
+	}
+
+	// tool returns a registered tool by name
+	tool := func(mcpSrv *mcpserver.MCPServer, name string) mcp.Tool {
+		message := []byte(`{"jsonrpc":"2.0","id":1,"method":"tools/list"}`)
+		tools := mcpSrv.HandleMessage(ctx, message).(mcp.JSONRPCResponse).Result.(mcp.ListToolsResult)
+		for _, t := range tools.Tools {
+			if t.Name == name {
+				return t
+			}
+		}
+		Fail("tool " + name + " is not registered")
+		return mcp.Tool{}
+	}
+
+	callTool := func(mcpSrv *mcpserver.MCPServer, name string, arguments map[string]interface{}) mcp.CallToolResult {
+		message, err := json.Marshal(map[string]interface{}{
+			"jsonrpc": "2.0",
+			"id":      1,
+			"method":  "tools/call",
+			"params":  map[string]interface{}{"name": name, "arguments": arguments},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		return mcpSrv.HandleMessage(ctx, message).(mcp.JSONRPCResponse).Result.(mcp.CallToolResult)
+	}
+
+	queriedNamespace := func(namespace string) bool {
+		mu.Lock()
+		defer mu.Unlock()
+		for _, query := range queries {
+			if !strings.Contains(query, `namespace="`+namespace+`"`) {
+				return false
+			}
+		}
+		return len(queries) > 0
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+	})
+
+	Context("when DEFAULT_METRICS_NAMESPACE is unset", func() {
+		It("should require the namespace argument", func() {
+			mcpSrv := mcpServerFor("")
+			for _, name := range []string{"get_service_metrics", "get_service_health_summary", "get_top_services"} {
+				Expect(tool(mcpSrv, name).InputSchema.Required).To(ContainElement("namespace"), name)
+			}
+		})
+	})
+
+	Context("when DEFAULT_METRICS_NAMESPACE is set", func() {
+		It("should make the namespace argument optional", func() {
+			mcpSrv := mcpServerFor("shop")
+			for _, name := range []string{"get_service_metrics", "get_service_health_summary", "get_top_services"} {
+				Expect(tool(mcpSrv, name).InputSchema.Required).NotTo(ContainElement("namespace"), name)
+			}
+		})
+
+		It("should query the default namespace without a namespace argument", func() {
+			mcpSrv := mcpServerFor("shop")
+			result := callTool(mcpSrv, "get_service_metrics", map[string]interface{}{"service": "web"})
+			Expect(result.IsError).To(BeFalse())
+			Expect(queriedNamespace("shop")).To(BeTrue())
+		})
+
+		It("should prefer the namespace argument", func() {
+			mcpSrv := mcpServerFor("shop")
+			result := callTool(mcpSrv, "get_top_services", map[string]interface{}{"namespace": "billing"})
+			Expect(result.IsError).To(BeFalse())
+			Expect(queriedNamespace("billing")).To(BeTrue())
+		})
+	})
+
+	Context("with the PromQL passthrough", func() {
+		registered := func(mcpSrv *mcpserver.MCPServer, name string) bool {
+			message := []byte(`{"jsonrpc":"2.0","id":1,"method":"tools/list"}`)
+			tools := mcpSrv.HandleMessage(ctx, message).(mcp.JSONRPCResponse).Result.(mcp.ListToolsResult)
+			for _, t := range tools.Tools {
+				if t.Name == name {
+					return true
+				}
+			}
+			return false
+		}
+
+		passthroughServer := func(mode config.PromQLPassthroughMode) *mcpserver.MCPServer {
+			mcpSrv := mcpserver.NewMCPServer("test-server", "1.0.0", mcpserver.WithToolCapabilities(true))
+			server.NewWithPromQLPassthrough(kubefake.NewSimpleClientset(), fake.NewSimpleDynamicClient(runtime.NewScheme()), collector(), mode).RegisterTools(mcpSrv)
+			return mcpSrv
+		}
+
+		It("should not register query_linkerd_metrics by default", func() {
+			Expect(registered(mcpServerFor(""), "query_linkerd_metrics")).To(BeFalse())
+			Expect(registered(passthroughServer(config.PromQLPassthroughOff), "query_linkerd_metrics")).To(BeFalse())
+		})
+
+		It("should only run queries over Linkerd metrics in linkerd mode", func() {
+			mcpSrv := passthroughServer(config.PromQLPassthroughLinkerd)
+			Expect(callTool(mcpSrv, "query_linkerd_metrics", map[string]interface{}{"query": "up"}).IsError).To(BeTrue())
+			Expect(callTool(mcpSrv, "query_linkerd_metrics", map[string]interface{}{"query": "sum(request_total)"}).IsError).To(BeFalse())
+		})
+
+		It("should run any query in any mode", func() {
+			mcpSrv := passthroughServer(config.PromQLPassthroughAny)
+			Expect(callTool(mcpSrv, "query_linkerd_metrics", map[string]interface{}{"query": "up"}).IsError).To(BeFalse())
+		})
+	})
+})
@@ -0,0 +1,84 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// troubleshootConnectivityArguments are the arguments of the troubleshoot_connectivity prompt,
+// named like those of analyze_connectivity
+var troubleshootConnectivityArguments = []string{"source_namespace", "source_service", "target_namespace", "target_service"}
+
+// RegisterPrompts registers MCP prompts packaging common debugging workflows as a sequence of
+// tool calls
+func (s *LinkerdMCPServer) RegisterPrompts(mcpServer *server.MCPServer) {
+	// Register prompt: Troubleshoot connectivity between two services
+	troubleshootConnectivityPrompt := mcp.NewPrompt("troubleshoot_connectivity",
+		mcp.WithPromptDescription("Guided troubleshooting of traffic between two meshed services: policy analysis, allowed sources and, when Prometheus is available, traffic metrics"),
+		mcp.WithArgument("source_namespace",
+			mcp.ArgumentDescription("Namespace of the source service"),
+			mcp.RequiredArgument(),
+		),
+		mcp.WithArgument("source_service",
+			mcp.ArgumentDescription("Name of the source service"),
+			mcp.RequiredArgument(),
+		),
+		mcp.WithArgument("target_namespace",
+			mcp.ArgumentDescription("Namespace of the target service"),
+			mcp.RequiredArgument(),
+		),
+		mcp.WithArgument("target_service",
+			mcp.ArgumentDescription("Name of the target service"),
+			mcp.RequiredArgument(),
+		),
+	)
+	mcpServer.AddPrompt(troubleshootConnectivityPrompt, func(ctx context.Context, request mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+		args := request.Params.Arguments
+		for _, name := range troubleshootConnectivityArguments {
+			if args[name] == "" {
+				return nil, fmt.Errorf("missing required argument: %s", name)
+			}
+		}
+
+		source := args["source_namespace"] + "/" + args["source_service"]
+		target := args["target_namespace"] + "/" + args["target_service"]
+		return mcp.NewGetPromptResult(
+			fmt.Sprintf("Troubleshoot connectivity from %s to %s", source, target),
+			[]mcp.PromptMessage{
+				mcp.NewPromptMessage(mcp.RoleUser, mcp.NewTextContent(s.troubleshootConnectivityText(args))),
+			},
+		), nil
+	})
+}
+
+// troubleshootConnectivityText is the workflow of the troubleshoot_connectivity prompt. The
+// metrics step is only included when the metrics tools are registered.
+func (s *LinkerdMCPServer) troubleshootConnectivityText(args map[string]string) string {
+	sourceNamespace, sourceService := args["source_namespace"], args["source_service"]
+	targetNamespace, targetService := args["target_namespace"], args["target_service"]
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Service %s in namespace %s can't reach service %s in namespace %s as expected. "+
+		"Find out why using the Linkerd tools, in this order:\n\n",
+		sourceService, sourceNamespace, targetService, targetNamespace)
+
+	fmt.Fprintf(&b, "1. Call `analyze_connectivity` with source_namespace=%q, source_service=%q, target_namespace=%q and target_service=%q. "+
+		"Check whether a Server selects the target's pods and port, and whether an AuthorizationPolicy or ServerAuthorization authorizes the source.\n",
+		sourceNamespace, sourceService, targetNamespace, targetService)
+	fmt.Fprintf(&b, "2. Call `get_allowed_sources` with target_namespace=%q, target_service=%q and explain=true. "+
+		"Check whether %s is among the allowed sources, and if not, which reasons deny it.\n",
+		targetNamespace, targetService, sourceService)
+	if s.metricsCollector != nil {
+		fmt.Fprintf(&b, "3. Call `get_service_metrics` with namespace=%q and service=%q. "+
+			"Check whether the target receives traffic and whether its success rate or latency points to failures beyond policy.\n",
+			targetNamespace, targetService)
+	}
+
+	b.WriteString("\nCombine the results: state whether the traffic is allowed, the policy or missing resource responsible if it isn't, " +
+		"and the change that would fix it. Don't call a tool again once its result answers the question.")
+	return b.String()
+}
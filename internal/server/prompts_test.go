@@ -0,0 +1,99 @@
+package server_test
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/christianhuening/linkerd-mcp/internal/metrics"
+	"github.com/christianhuening/linkerd-mcp/internal/server"
+	"github.com/mark3labs/mcp-go/mcp"
+	mcpserver "github.com/mark3labs/mcp-go/server"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/dynamic/fake"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+)
+
+var _ = Describe("RegisterPrompts", func() {
+	var ctx context.Context
+
+	// getPrompt sends a prompts/get request for troubleshoot_connectivity and returns the
+	// JSON-RPC response
+	getPrompt := func(linkerdServer *server.LinkerdMCPServer, arguments map[string]string) mcp.JSONRPCMessage {
+		mcpSrv := mcpserver.NewMCPServer("test-server", "1.0.0", mcpserver.WithPromptCapabilities(false))
+		linkerdServer.RegisterPrompts(mcpSrv)
+
+		message, err := json.Marshal(map[string]interface{}{
+			"jsonrpc": "2.0",
+			"id":      1,
+			"method":  "prompts/get",
+			"params":  map[string]interface{}{"name": "troubleshoot_connectivity", "arguments": arguments},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		return mcpSrv.HandleMessage(ctx, message)
+	}
+
+	// promptText returns the text of the single message of a prompt
+	promptText := func(response mcp.JSONRPCMessage) string {
+		Expect(response).To(BeAssignableToTypeOf(mcp.JSONRPCResponse{}))
+		result := response.(mcp.JSONRPCResponse).Result.(mcp.GetPromptResult)
+		Expect(result.Messages).To(HaveLen(1))
+		Expect(result.Messages[0].Role).To(Equal(mcp.RoleUser))
+		return result.Messages[0].Content.(mcp.TextContent).Text
+	}
+
+	arguments := map[string]string{
+		"source_namespace": "prod",
+		"source_service":   "frontend",
+		"target_namespace": "prod",
+		"target_service":   "api",
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+	})
+
+	newServer := func() *server.LinkerdMCPServer {
+		return server.NewWithClients(kubefake.NewSimpleClientset(), fake.NewSimpleDynamicClient(runtime.NewScheme()))
+	}
+
+	It("should list the troubleshoot_connectivity prompt with its arguments", func() {
+		mcpSrv := mcpserver.NewMCPServer("test-server", "1.0.0", mcpserver.WithPromptCapabilities(false))
+		newServer().RegisterPrompts(mcpSrv)
+
+		message := []byte(`{"jsonrpc":"2.0","id":1,"method":"prompts/list"}`)
+		prompts := mcpSrv.HandleMessage(ctx, message).(mcp.JSONRPCResponse).Result.(mcp.ListPromptsResult)
+		Expect(prompts.Prompts).To(HaveLen(1))
+		Expect(prompts.Prompts[0].Name).To(Equal("troubleshoot_connectivity"))
+		Expect(prompts.Prompts[0].Arguments).To(HaveLen(4))
+	})
+
+	It("should walk through the policy tools in order", func() {
+		text := promptText(getPrompt(newServer(), arguments))
+
+		Expect(text).To(ContainSubstring(`source_namespace="prod", source_service="frontend"`))
+		Expect(text).To(ContainSubstring(`target_namespace="prod", target_service="api" and explain=true`))
+		Expect(text).To(MatchRegexp("(?s)analyze_connectivity.*get_allowed_sources"))
+		Expect(text).NotTo(ContainSubstring("get_service_metrics"))
+	})
+
+	It("should include the metrics step when Prometheus is available", func() {
+		os.Setenv("LINKERD_PROMETHEUS_URL", "http://127.0.0.1:9090")
+		DeferCleanup(os.Unsetenv, "LINKERD_PROMETHEUS_URL")
+		collector, err := metrics.NewMetricsCollector(nil, nil, nil, "linkerd")
+		Expect(err).NotTo(HaveOccurred())
+
+		text := promptText(getPrompt(server.NewWithMetrics(kubefake.NewSimpleClientset(), nil, collector, ""), arguments))
+		Expect(text).To(MatchRegexp("(?s)get_allowed_sources.*get_service_metrics"))
+		Expect(text).To(ContainSubstring(`namespace="prod" and service="api"`))
+	})
+
+	It("should reject missing arguments", func() {
+		response := getPrompt(newServer(), map[string]string{"source_namespace": "prod"})
+		Expect(response).To(BeAssignableToTypeOf(mcp.JSONRPCError{}))
+		Expect(response.(mcp.JSONRPCError).Error.Message).To(ContainSubstring("missing required argument: source_service"))
+	})
+})
@@ -0,0 +1,103 @@
+package server_test
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/christianhuening/linkerd-mcp/internal/server"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/discovery/fake"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+var _ = Describe("Kubernetes readiness check", func() {
+	var (
+		ctx       context.Context
+		discovery *fake.FakeDiscovery
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		discovery = kubefake.NewSimpleClientset().Discovery().(*fake.FakeDiscovery)
+	})
+
+	It("should pass when the API server answers", func() {
+		Expect(server.CheckKubernetes(ctx, discovery, time.Second)).To(Succeed())
+	})
+
+	It("should fail with the reason when the API server errors", func() {
+		discovery.PrependReactor("get", "version", func(action k8stesting.Action) (bool, runtime.Object, error) {
+			return true, nil, errors.New("connection refused")
+		})
+
+		err := server.CheckKubernetes(ctx, discovery, time.Second)
+		Expect(err).To(MatchError(ContainSubstring("connection refused")))
+	})
+
+	It("should time out when the API server hangs", func() {
+		unblock := make(chan struct{})
+		DeferCleanup(func() { close(unblock) })
+		discovery.PrependReactor("get", "version", func(action k8stesting.Action) (bool, runtime.Object, error) {
+			<-unblock
+			return true, nil, nil
+		})
+
+		err := server.CheckKubernetes(ctx, discovery, 50*time.Millisecond)
+		Expect(err).To(MatchError(ContainSubstring("did not respond within 50ms")))
+	})
+
+	It("should fail without a client", func() {
+		Expect(server.CheckKubernetes(ctx, nil, time.Second)).NotTo(Succeed())
+	})
+})
+
+var _ = Describe("Periodic Kubernetes check", func() {
+	var (
+		ctx       context.Context
+		discovery *fake.FakeDiscovery
+	)
+
+	BeforeEach(func() {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithCancel(context.Background())
+		DeferCleanup(cancel)
+		discovery = kubefake.NewSimpleClientset().Discovery().(*fake.FakeDiscovery)
+	})
+
+	It("should report the startup check until the next check", func() {
+		srv := server.NewWithKubernetesCheck(discovery, time.Hour, errors.New("connection refused"))
+		Expect(srv.Ready(ctx)).To(MatchError("connection refused"))
+	})
+
+	It("should become ready once the API server is reachable again", func() {
+		srv := server.NewWithKubernetesCheck(discovery, 10*time.Millisecond, errors.New("connection refused"))
+		go srv.WatchKubernetes(ctx)
+
+		Eventually(func() error { return srv.Ready(ctx) }).Should(Succeed())
+	})
+
+	It("should become unready when the API server goes away", func() {
+		discovery.PrependReactor("get", "version", func(action k8stesting.Action) (bool, runtime.Object, error) {
+			return true, nil, errors.New("connection refused")
+		})
+		srv := server.NewWithKubernetesCheck(discovery, 10*time.Millisecond, nil)
+		go srv.WatchKubernetes(ctx)
+
+		Eventually(func() error { return srv.Ready(ctx) }).Should(MatchError(ContainSubstring("connection refused")))
+	})
+
+	It("should return at once when the periodic check is off", func() {
+		srv := server.NewWithKubernetesCheck(discovery, 0, nil)
+		done := make(chan struct{})
+		go func() {
+			srv.WatchKubernetes(ctx)
+			close(done)
+		}()
+		Eventually(done).Should(BeClosed())
+	})
+})
@@ -0,0 +1,90 @@
+package server
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// resourceMIMEType is the content type of every resource; they carry the same JSON as the tools
+const resourceMIMEType = "application/json"
+
+// RegisterResources registers read-only MCP resources exposing mesh state, so that clients can
+// browse it without calling a tool
+func (s *LinkerdMCPServer) RegisterResources(mcpServer *server.MCPServer) {
+	// Register resource: Mesh health
+	healthResource := mcp.NewResource("linkerd://health", "Linkerd mesh health",
+		mcp.WithResourceDescription("Health of the Linkerd control plane, as reported by check_mesh_health"),
+		mcp.WithMIMEType(resourceMIMEType),
+	)
+	mcpServer.AddResource(healthResource, func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		return resourceContents(request.Params.URI, func() (*mcp.CallToolResult, error) {
+			return s.healthChecker.CheckMeshHealth(ctx, "")
+		})
+	})
+
+	// Register resource: Servers of a namespace
+	serversTemplate := mcp.NewResourceTemplate("linkerd://servers/{namespace}", "Linkerd Servers",
+		mcp.WithTemplateDescription("Servers in a namespace with their port, accessPolicy and the policies targeting them"),
+		mcp.WithTemplateMIMEType(resourceMIMEType),
+	)
+	mcpServer.AddResourceTemplate(serversTemplate, func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		return resourceContents(request.Params.URI, func() (*mcp.CallToolResult, error) {
+			return s.policyAnalyzer.ListServers(ctx, resourceArgument(request, "namespace"))
+		})
+	})
+
+	// Register resource: Meshed services of a namespace
+	servicesTemplate := mcp.NewResourceTemplate("linkerd://services/{namespace}", "Linkerd meshed services",
+		mcp.WithTemplateDescription("Meshed services in a namespace, as reported by list_meshed_services"),
+		mcp.WithTemplateMIMEType(resourceMIMEType),
+	)
+	mcpServer.AddResourceTemplate(servicesTemplate, func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		return resourceContents(request.Params.URI, func() (*mcp.CallToolResult, error) {
+			return s.serviceLister.ListMeshedServices(ctx, resourceArgument(request, "namespace"), "", false)
+		})
+	})
+}
+
+// resourceArgument returns a URI template variable of a resource request. The template engine
+// matches a variable as a string, or as a string slice for list expansions.
+func resourceArgument(request mcp.ReadResourceRequest, name string) string {
+	switch value := request.Params.Arguments[name].(type) {
+	case string:
+		return value
+	case []string:
+		if len(value) > 0 {
+			return value[0]
+		}
+	}
+	return ""
+}
+
+// resourceContents wraps the JSON a tool produces as the contents of a resource. Tool errors
+// become resource read errors.
+func resourceContents(uri string, produce func() (*mcp.CallToolResult, error)) ([]mcp.ResourceContents, error) {
+	result, err := produce()
+	if err != nil {
+		return nil, err
+	}
+
+	text := ""
+	for _, content := range result.Content {
+		if textContent, ok := content.(mcp.TextContent); ok {
+			text += textContent.Text
+		}
+	}
+	if result.IsError {
+		return nil, fmt.Errorf("failed to read %s: %s", uri, text)
+	}
+
+	return []mcp.ResourceContents{
+		mcp.TextResourceContents{
+			URI:      uri,
+			MIMEType: resourceMIMEType,
+			Text:     text,
+		},
+	}, nil
+}
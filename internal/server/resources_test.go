@@ -0,0 +1,109 @@
+package server_test
+
+import (
+	"context"
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/christianhuening/linkerd-mcp/internal/server"
+	"github.com/christianhuening/linkerd-mcp/internal/testutil"
+	"github.com/mark3labs/mcp-go/mcp"
+	mcpserver "github.com/mark3labs/mcp-go/server"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/fake"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+)
+
+var _ = Describe("RegisterResources", func() {
+	var (
+		ctx    context.Context
+		mcpSrv *mcpserver.MCPServer
+	)
+
+	// readResource sends a resources/read request and returns the JSON-RPC response
+	readResource := func(uri string) map[string]interface{} {
+		message, err := json.Marshal(map[string]interface{}{
+			"jsonrpc": "2.0",
+			"id":      1,
+			"method":  "resources/read",
+			"params":  map[string]interface{}{"uri": uri},
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		data, err := json.Marshal(mcpSrv.HandleMessage(ctx, message))
+		Expect(err).NotTo(HaveOccurred())
+
+		var response map[string]interface{}
+		Expect(json.Unmarshal(data, &response)).To(Succeed())
+		return response
+	}
+
+	// resourceJSON decodes the JSON text of the single resource in a response
+	resourceJSON := func(response map[string]interface{}) map[string]interface{} {
+		Expect(response).To(HaveKey("result"))
+		contents := response["result"].(map[string]interface{})["contents"].([]interface{})
+		Expect(contents).To(HaveLen(1))
+
+		content := contents[0].(map[string]interface{})
+		Expect(content["mimeType"]).To(Equal("application/json"))
+
+		var body map[string]interface{}
+		Expect(json.Unmarshal([]byte(content["text"].(string)), &body)).To(Succeed())
+		return body
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+
+		clientset := kubefake.NewSimpleClientset(
+			testutil.CreateMeshedPod("api-1", "prod", "api"),
+			testutil.CreateMeshedPod("web-1", "staging", "web"),
+		)
+		gvrToListKind := map[schema.GroupVersionResource]string{
+			{Group: "policy.linkerd.io", Version: "v1beta3", Resource: "servers"}:                "ServerList",
+			{Group: "policy.linkerd.io", Version: "v1alpha1", Resource: "authorizationpolicies"}: "AuthorizationPolicyList",
+			{Group: "policy.linkerd.io", Version: "v1beta1", Resource: "serverauthorizations"}:   "ServerAuthorizationList",
+		}
+		dynamicClient := fake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), gvrToListKind,
+			testutil.CreateServer("api-http", "prod", map[string]string{"app": "api"}, 8080),
+		)
+
+		mcpSrv = mcpserver.NewMCPServer("test-server", "1.0.0",
+			mcpserver.WithResourceCapabilities(false, false),
+		)
+		server.NewWithClients(clientset, dynamicClient).RegisterResources(mcpSrv)
+	})
+
+	It("should list the health resource and the namespace templates", func() {
+		message := []byte(`{"jsonrpc":"2.0","id":1,"method":"resources/list"}`)
+		resources := mcpSrv.HandleMessage(ctx, message).(mcp.JSONRPCResponse).Result.(mcp.ListResourcesResult)
+		Expect(resources.Resources).To(ConsistOf(HaveField("URI", "linkerd://health")))
+
+		message = []byte(`{"jsonrpc":"2.0","id":2,"method":"resources/templates/list"}`)
+		templates := mcpSrv.HandleMessage(ctx, message).(mcp.JSONRPCResponse).Result.(mcp.ListResourceTemplatesResult)
+		Expect(templates.ResourceTemplates).To(HaveLen(2))
+	})
+
+	It("should serve the Servers of a namespace", func() {
+		body := resourceJSON(readResource("linkerd://servers/prod"))
+		Expect(body["namespace"]).To(Equal("prod"))
+		Expect(body["totalServers"]).To(BeNumerically("==", 1))
+	})
+
+	It("should serve the meshed services of a namespace", func() {
+		body := resourceJSON(readResource("linkerd://services/staging"))
+		Expect(body["totalServices"]).To(BeNumerically("==", 1))
+	})
+
+	It("should serve the mesh health", func() {
+		body := resourceJSON(readResource("linkerd://health"))
+		Expect(body).To(HaveKey("totalPods"))
+	})
+
+	It("should reject unknown resources", func() {
+		Expect(readResource("linkerd://unknown")).To(HaveKey("error"))
+	})
+})
@@ -2,64 +2,571 @@ package server
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"runtime/debug"
+	"strings"
+	"sync"
+	"time"
 
+	"github.com/christianhuening/linkerd-mcp/internal/buildinfo"
 	"github.com/christianhuening/linkerd-mcp/internal/config"
 	"github.com/christianhuening/linkerd-mcp/internal/health"
+	"github.com/christianhuening/linkerd-mcp/internal/kube"
 	"github.com/christianhuening/linkerd-mcp/internal/mesh"
 	"github.com/christianhuening/linkerd-mcp/internal/metrics"
+	"github.com/christianhuening/linkerd-mcp/internal/overview"
 	"github.com/christianhuening/linkerd-mcp/internal/policy"
+	"github.com/christianhuening/linkerd-mcp/internal/scorecard"
+	"github.com/christianhuening/linkerd-mcp/internal/tap"
+	"github.com/christianhuening/linkerd-mcp/internal/telemetry"
 	"github.com/christianhuening/linkerd-mcp/internal/validation"
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
+	"k8s.io/client-go/discovery"
 )
 
 // LinkerdMCPServer represents the MCP server for Linkerd
 type LinkerdMCPServer struct {
 	healthChecker    *health.Checker
+	crdChecker       *health.CRDChecker
 	serviceLister    *mesh.ServiceLister
+	profileLister    *mesh.ServiceProfileLister
 	policyAnalyzer   *policy.Analyzer
 	configValidator  *validation.ConfigValidator
 	metricsCollector *metrics.MetricsCollector
+	metricsNamespace string
+	scorecardBuilder *scorecard.Builder
+	overviewBuilder  *overview.Builder
+	tapper           *tap.Tapper
+	discoveryClient  discovery.DiscoveryInterface
+	kubeStatus       *clusterStatus
+	kubeInterval     time.Duration
+	buildInfo        buildinfo.Info
+	toolTimeouts     config.ToolTimeouts
+	promQLMode       config.PromQLPassthroughMode
+	toolFilter       config.ToolFilter
 }
 
+// slowToolTimeouts are the minimum timeouts of tools known to outlast most calls, such as those
+// walking every policy resource of the cluster. TOOL_TIMEOUT_<TOOL> still overrides them.
+var slowToolTimeouts = map[string]time.Duration{
+	"export_policy_graph":  2 * time.Minute,
+	"get_policy_posture":   2 * time.Minute,
+	"validate_mesh_config": 2 * time.Minute,
+	"mesh_overview":        2 * time.Minute,
+	"cluster_health_score": 2 * time.Minute,
+	"tap_service":          tap.MaxDuration + 15*time.Second,
+	"get_errors_detail":    tap.MaxDuration + 15*time.Second,
+}
+
+// readinessTimeout bounds the Kubernetes API check behind the readiness probe
+const readinessTimeout = 2 * time.Second
+
 // New creates a new LinkerdMCPServer
 func New() (*LinkerdMCPServer, error) {
+	startupCheck, err := config.PrometheusStartupCheckFromEnv()
+	if err != nil {
+		return nil, err
+	}
+
+	toolTimeouts, err := config.ToolTimeoutsFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	for tool, timeout := range slowToolTimeouts {
+		if _, ok := toolTimeouts.PerTool[tool]; !ok && timeout > toolTimeouts.Default {
+			toolTimeouts.PerTool[tool] = timeout
+		}
+	}
+
+	kubeInterval, err := config.KubernetesCheckIntervalFromEnv()
+	if err != nil {
+		return nil, err
+	}
+
+	namespaceFilter, err := config.NamespaceFilterFromEnv()
+	if err != nil {
+		return nil, err
+	}
+
+	promQLPassthrough, err := config.PromQLPassthroughFromEnv()
+	if err != nil {
+		return nil, err
+	}
+
+	proxyContainer, err := config.ProxyContainerFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	kube.SetProxyContainerName(proxyContainer)
+
 	clients, err := config.NewKubernetesClients()
 	if err != nil {
 		return nil, err
 	}
 
+	// An unreachable API server doesn't fail startup: the clients connect lazily, so tools start
+	// working once it comes up
+	kubeErr := checkKubernetes(context.Background(), clients.DiscoveryClient, readinessTimeout)
+	if kubeErr != nil {
+		log.Printf("Warning: Kubernetes connectivity check failed, tools will fail until the API server is reachable: %v", kubeErr)
+	} else {
+		log.Printf("Kubernetes connectivity check passed")
+	}
+	var kubeStatus *clusterStatus
+	if kubeInterval > 0 {
+		kubeStatus = &clusterStatus{err: kubeErr}
+	}
+
+	linkerdNamespace := config.LinkerdNamespace()
+
 	// Create metrics collector (gracefully handle errors - metrics are optional)
-	metricsCollector, err := metrics.NewMetricsCollector(clients.Config, clients.Clientset, "linkerd")
+	metricsCollector, err := metrics.NewMetricsCollector(clients.Config, clients.Clientset, clients.DynamicClient, linkerdNamespace)
 	if err != nil {
 		// Log warning but don't fail - Prometheus may not be available
+		log.Printf("Metrics tools disabled: %v", err)
 		metricsCollector = nil
 	}
+	if err := checkPrometheus(context.Background(), metricsCollector, startupCheck); err != nil {
+		metricsCollector.Close()
+		return nil, err
+	}
+
+	healthChecker := health.NewChecker(clients.Clientset)
+	healthChecker.SetControlPlaneNamespace(linkerdNamespace)
+	healthChecker.SetNamespaceFilter(namespaceFilter)
+
+	policyAnalyzer := policy.NewAnalyzer(clients.Clientset, clients.DynamicClient)
+	policyAnalyzer.SetControlPlaneNamespace(linkerdNamespace)
+	policyAnalyzer.SetNamespaceFilter(namespaceFilter)
+
+	serviceLister := mesh.NewServiceLister(clients.Clientset)
+	serviceLister.SetControlPlaneNamespace(linkerdNamespace)
+	serviceLister.SetNamespaceFilter(namespaceFilter)
+	configValidator := validation.NewConfigValidator(clients.Clientset, clients.DynamicClient)
+	configValidator.SetNamespaceFilter(namespaceFilter)
 
 	return &LinkerdMCPServer{
-		healthChecker:    health.NewChecker(clients.Clientset),
-		serviceLister:    mesh.NewServiceLister(clients.Clientset),
-		policyAnalyzer:   policy.NewAnalyzer(clients.Clientset, clients.DynamicClient),
-		configValidator:  validation.NewConfigValidator(clients.Clientset, clients.DynamicClient),
+		healthChecker:    healthChecker,
+		crdChecker:       health.NewCRDChecker(clients.DiscoveryClient),
+		serviceLister:    serviceLister,
+		profileLister:    mesh.NewServiceProfileLister(clients.DynamicClient),
+		policyAnalyzer:   policyAnalyzer,
+		configValidator:  configValidator,
 		metricsCollector: metricsCollector,
+		metricsNamespace: config.DefaultMetricsNamespace(),
+		scorecardBuilder: scorecard.NewBuilder(metricsCollector, policyAnalyzer),
+		overviewBuilder:  overview.NewBuilder(healthChecker, serviceLister, configValidator, metricsCollector),
+		tapper:           tap.NewTapper(clients.Config),
+		discoveryClient:  clients.DiscoveryClient,
+		kubeStatus:       kubeStatus,
+		kubeInterval:     kubeInterval,
+		toolTimeouts:     toolTimeouts,
+		promQLMode:       promQLPassthrough,
+		toolFilter:       config.ToolFilterFromEnv(),
 	}, nil
 }
 
+// metricsNamespaceArgument declares the namespace argument of a metrics tool. It is required
+// unless DEFAULT_METRICS_NAMESPACE sets a default.
+func (s *LinkerdMCPServer) metricsNamespaceArgument(description string) mcp.ToolOption {
+	if s.metricsNamespace == "" {
+		return mcp.WithString("namespace",
+			mcp.Required(),
+			mcp.Description(description),
+		)
+	}
+	return mcp.WithString("namespace",
+		mcp.Description(fmt.Sprintf("%s (default: %s)", description, s.metricsNamespace)),
+	)
+}
+
+// metricsNamespaceFrom returns the namespace argument of a metrics tool call, falling back to
+// DEFAULT_METRICS_NAMESPACE
+func (s *LinkerdMCPServer) metricsNamespaceFrom(args *toolArguments) string {
+	if namespace := args.stringArg("namespace", ""); namespace != "" {
+		return namespace
+	}
+	return s.metricsNamespace
+}
+
+// queryLinkerdMetricsDescription describes the query argument of query_linkerd_metrics, with the
+// metrics it may select
+func queryLinkerdMetricsDescription(mode config.PromQLPassthroughMode) string {
+	if mode == config.PromQLPassthroughLinkerd {
+		return "The PromQL query. It may only select Linkerd proxy metrics: request_total, response_total, response_latency_ms_* and tcp_*"
+	}
+	return "The PromQL query"
+}
+
+// checkPrometheus runs the optional Prometheus startup probe. It only returns an error when the
+// probe fails in require mode; in warn mode failures are logged and metrics tools stay registered,
+// since Prometheus may become reachable later.
+func checkPrometheus(ctx context.Context, collector *metrics.MetricsCollector, check config.PrometheusStartupCheck) error {
+	if check.Mode != config.PrometheusStartupCheckWarn && check.Mode != config.PrometheusStartupCheckRequire {
+		return nil
+	}
+
+	if err := collector.CheckHealth(ctx, check.Timeout); err != nil {
+		if check.Mode == config.PrometheusStartupCheckRequire {
+			return fmt.Errorf("prometheus startup check failed: %w", err)
+		}
+		log.Printf("Warning: Prometheus startup check failed, metrics tools may be unavailable: %v", err)
+		return nil
+	}
+
+	log.Printf("Prometheus startup check passed")
+	return nil
+}
+
+// SetBuildInfo sets the build the server_info tool reports
+func (s *LinkerdMCPServer) SetBuildInfo(info buildinfo.Info) {
+	s.buildInfo = info
+}
+
+// MaxToolTimeout returns the longest time a tool call may run, which the HTTP transport must
+// allow for writing responses
+func (s *LinkerdMCPServer) MaxToolTimeout() time.Duration {
+	return s.toolTimeouts.Max()
+}
+
+// Ready reports whether the Kubernetes API server is reachable, since no tool can be served
+// without it. With KUBE_CHECK_INTERVAL set, it reports the latest periodic check instead of
+// calling the API server on every probe.
+func (s *LinkerdMCPServer) Ready(ctx context.Context) error {
+	if s.kubeStatus != nil {
+		return s.kubeStatus.get()
+	}
+	return checkKubernetes(ctx, s.discoveryClient, readinessTimeout)
+}
+
+// Close releases the connections the server holds open, such as the port-forward to Prometheus
+func (s *LinkerdMCPServer) Close() {
+	s.metricsCollector.Close()
+}
+
+// WatchKubernetes re-checks the Kubernetes API server every KUBE_CHECK_INTERVAL until ctx is
+// done, logging when it becomes unreachable or reachable again, so that readiness recovers from
+// a cluster that was unreachable at startup. It returns at once when the periodic check is off.
+func (s *LinkerdMCPServer) WatchKubernetes(ctx context.Context) {
+	if s.kubeStatus == nil || s.kubeInterval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(s.kubeInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		err := checkKubernetes(ctx, s.discoveryClient, readinessTimeout)
+		if ctx.Err() != nil {
+			return
+		}
+		previous := s.kubeStatus.set(err)
+		switch {
+		case err != nil && previous == nil:
+			log.Printf("Warning: Kubernetes API server became unreachable: %v", err)
+		case err == nil && previous != nil:
+			log.Printf("Kubernetes API server is reachable again")
+		}
+	}
+}
+
+// clusterStatus holds the outcome of the latest Kubernetes connectivity check
+type clusterStatus struct {
+	mu  sync.RWMutex
+	err error
+}
+
+func (c *clusterStatus) get() error {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.err
+}
+
+// set records the outcome of a check and returns the previous one
+func (c *clusterStatus) set(err error) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	previous := c.err
+	c.err = err
+	return previous
+}
+
+// checkKubernetes fetches the API server version, giving up after timeout. The discovery client
+// takes no context, so the call is abandoned rather than cancelled when it times out.
+func checkKubernetes(ctx context.Context, discoveryClient discovery.DiscoveryInterface, timeout time.Duration) error {
+	if discoveryClient == nil {
+		return fmt.Errorf("kubernetes client is not configured")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	result := make(chan error, 1)
+	go func() {
+		_, err := discoveryClient.ServerVersion()
+		result <- err
+	}()
+
+	select {
+	case err := <-result:
+		if err != nil {
+			return fmt.Errorf("kubernetes API server is unreachable: %w", err)
+		}
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("kubernetes API server did not respond within %s", timeout)
+	}
+}
+
+// healthThresholds returns the default health thresholds with any threshold given in args
+// overriding its default
+func healthThresholds(args *toolArguments) metrics.HealthThresholds {
+	thresholds := metrics.DefaultHealthThresholds()
+	overrides := map[string]*float64{
+		"error_rate_warning":    &thresholds.ErrorRateWarning,
+		"error_rate_critical":   &thresholds.ErrorRateCritical,
+		"latency_p95_warning":   &thresholds.LatencyP95Warning,
+		"latency_p95_critical":  &thresholds.LatencyP95Critical,
+		"success_rate_warning":  &thresholds.SuccessRateWarning,
+		"success_rate_critical": &thresholds.SuccessRateCritical,
+		"min_request_rate":      &thresholds.MinRequestRate,
+
+		"client_error_rate_warning":  &thresholds.ClientErrorRateWarning,
+		"client_error_rate_critical": &thresholds.ClientErrorRateCritical,
+		"server_error_rate_warning":  &thresholds.ServerErrorRateWarning,
+		"server_error_rate_critical": &thresholds.ServerErrorRateCritical,
+	}
+	for name, threshold := range overrides {
+		*threshold = args.floatArg(name, *threshold)
+	}
+	return thresholds
+}
+
+// withTimeout cancels the context of a tool call after timeout and answers with a timeout error
+// instead of waiting for the handler, so an upstream that ignores the cancellation can't block
+// the client. A zero timeout leaves the handler unbounded.
+func withTimeout(name string, timeout time.Duration, handler server.ToolHandlerFunc) server.ToolHandlerFunc {
+	if timeout <= 0 {
+		return handler
+	}
+
+	type outcome struct {
+		result *mcp.CallToolResult
+		err    error
+	}
+
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+
+		done := make(chan outcome, 1)
+		go func() {
+			// The handler runs outside the goroutine of the request, where the HTTP server's
+			// panic recovery can't reach it, so a panic has to be recovered here
+			defer func() {
+				if r := recover(); r != nil {
+					log.Printf("tool_panic tool=%s error=%q\n%s", name, fmt.Sprint(r), debug.Stack())
+					done <- outcome{mcp.NewToolResultError(fmt.Sprintf("Tool %s panicked: %v", name, r)), nil}
+				}
+			}()
+			result, err := handler(ctx, request)
+			done <- outcome{result, err}
+		}()
+
+		select {
+		case o := <-done:
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) && (o.err != nil || (o.result != nil && o.result.IsError)) {
+				return timeoutResult(name, timeout), nil
+			}
+			return o.result, o.err
+		case <-ctx.Done():
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				return timeoutResult(name, timeout), nil
+			}
+			return nil, ctx.Err()
+		}
+	}
+}
+
+func timeoutResult(name string, timeout time.Duration) *mcp.CallToolResult {
+	return mcp.NewToolResultError(fmt.Sprintf("Tool %s timed out after %s; raise TOOL_TIMEOUT or TOOL_TIMEOUT_%s if the cluster or Prometheus is slow", name, timeout, strings.ToUpper(name)))
+}
+
 // RegisterTools registers all MCP tools with the server
 func (s *LinkerdMCPServer) RegisterTools(mcpServer *server.MCPServer) {
+	// Every tool call is bounded by its timeout, and counted and timed for the server's own
+	// /metrics endpoint. Tools left out by ENABLED_TOOLS or DISABLED_TOOLS aren't registered.
+	addTool := func(tool mcp.Tool, handler server.ToolHandlerFunc) {
+		if !s.toolFilter.Allows(tool.Name) {
+			return
+		}
+		handler = withArgumentValidation(tool, handler)
+		mcpServer.AddTool(tool, telemetry.InstrumentTool(tool.Name, withTimeout(tool.Name, s.toolTimeouts.For(tool.Name), handler)))
+	}
+
+	// Register tool: Server info
+	serverInfoTool := mcp.NewTool("server_info",
+		mcp.WithDescription("Reports the version, git commit, build date and Go version of this MCP server"),
+	)
+	addTool(serverInfoTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		data, err := json.Marshal(s.buildInfo)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal server info: %v", err)), nil
+		}
+		return mcp.NewToolResultText(string(data)), nil
+	})
+
+	// Register tool: Mesh overview
+	meshOverviewTool := mcp.NewTool("mesh_overview",
+		mcp.WithDescription("Summarizes the whole mesh in one call: control plane health, meshed namespaces and services, proxy version distribution, configuration errors and, when Prometheus is available, mTLS coverage. A good first tool to call"),
+	)
+	addTool(meshOverviewTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return s.overviewBuilder.GetMeshOverview(ctx)
+	})
+
+	// Register tool: Cluster health score
+	clusterHealthScoreTool := mcp.NewTool("cluster_health_score",
+		mcp.WithDescription("Scores the health of the cluster from 0 to 100, with a letter grade, from weighted signals: control plane health (35), certificate expiry (25), configuration validation errors (20) and data plane proxy health (20). Unavailable signals are left out and the others reweighted. Lists the issues that cost the most points with the tool giving their details"),
+	)
+	addTool(clusterHealthScoreTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return s.overviewBuilder.GetHealthScore(ctx)
+	})
+
 	// Register tool: Check mesh health
 	checkMeshHealthTool := mcp.NewTool("check_mesh_health",
 		mcp.WithDescription("Checks the health status of the Linkerd service mesh in the cluster"),
 		mcp.WithString("namespace",
-			mcp.Description("The namespace to check (defaults to 'linkerd')"),
+			mcp.Description("The namespace to check (defaults to LINKERD_NAMESPACE, or 'linkerd' when unset)"),
 		),
 	)
-	mcpServer.AddTool(checkMeshHealthTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-		args, _ := request.Params.Arguments.(map[string]interface{})
-		namespace, _ := args["namespace"].(string)
+	addTool(checkMeshHealthTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args := toolArgumentsOf(request)
+		namespace := args.stringArg("namespace", "")
+		if err := args.Err(); err != nil {
+			return invalidArguments(err), nil
+		}
 		return s.healthChecker.CheckMeshHealth(ctx, namespace)
 	})
 
+	// Register tool: Check data plane health
+	checkDataPlaneHealthTool := mcp.NewTool("check_data_plane_health",
+		mcp.WithDescription("Checks the health of Linkerd proxies in application pods (readiness, restarts, version skew against the control plane)"),
+		mcp.WithString("namespace",
+			mcp.Description("The namespace to check (optional, defaults to all namespaces)"),
+		),
+	)
+	addTool(checkDataPlaneHealthTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args := toolArgumentsOf(request)
+		namespace := args.stringArg("namespace", "")
+		if err := args.Err(); err != nil {
+			return invalidArguments(err), nil
+		}
+		return s.healthChecker.CheckDataPlaneHealth(ctx, namespace)
+	})
+
+	// Register tool: Check version compliance
+	checkVersionComplianceTool := mcp.NewTool("check_version_compliance",
+		mcp.WithDescription("Reports meshed workloads whose proxies run a version older than a required minimum, grouped by namespace, to find stragglers of an upgrade"),
+		mcp.WithString("min_version",
+			mcp.Required(),
+			mcp.Description("Minimum required proxy version, e.g. stable-2.14.1 or edge-24.3.2. Versions of the other channel can't be compared and are reported as unverified"),
+		),
+		mcp.WithString("namespace",
+			mcp.Description("The namespace to check (optional, defaults to all namespaces)"),
+		),
+	)
+	addTool(checkVersionComplianceTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args := toolArgumentsOf(request)
+		minVersion := args.stringArg("min_version", "")
+		namespace := args.stringArg("namespace", "")
+		if err := args.Err(); err != nil {
+			return invalidArguments(err), nil
+		}
+		return s.healthChecker.CheckVersionCompliance(ctx, minVersion, namespace)
+	})
+
+	// Register tool: Check identity reachability
+	checkIdentityReachabilityTool := mcp.NewTool("check_identity_reachability",
+		mcp.WithDescription("Checks that the linkerd-identity service has ready endpoints and reports meshed pods likely unable to obtain an identity certificate"),
+		mcp.WithString("namespace",
+			mcp.Description("The namespace of the pods to check (optional, defaults to all namespaces)"),
+		),
+		mcp.WithBoolean("check_proxies",
+			mcp.Description("Also query the admin endpoint of one ready proxy per namespace for its certificate expiry (default: false)"),
+		),
+	)
+	addTool(checkIdentityReachabilityTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args := toolArgumentsOf(request)
+		namespace := args.stringArg("namespace", "")
+		checkProxies := args.boolArg("check_proxies", false)
+		if err := args.Err(); err != nil {
+			return invalidArguments(err), nil
+		}
+		return s.healthChecker.CheckIdentityReachability(ctx, namespace, checkProxies)
+	})
+
+	// Register tool: Check certificates
+	checkCertificatesTool := mcp.NewTool("check_certificates",
+		mcp.WithDescription("Checks the expiry of the Linkerd trust anchors and issuer certificate, and that the issuer is signed by a trust anchor, with remediation for each failure"),
+		mcp.WithNumber("warn_within_days",
+			mcp.Description("Flag certificates expiring within this many days (default: 30)"),
+		),
+	)
+	addTool(checkCertificatesTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args := toolArgumentsOf(request)
+		days := args.optionalFloatArg("warn_within_days")
+		if err := args.Err(); err != nil {
+			return invalidArguments(err), nil
+		}
+		warnWithin := time.Duration(0)
+		if days != nil {
+			if *days <= 0 {
+				return mcp.NewToolResultError(fmt.Sprintf("Invalid warn_within_days: %g (must be positive)", *days)), nil
+			}
+			warnWithin = time.Duration(*days * float64(24*time.Hour))
+		}
+		return s.healthChecker.CheckCertificates(ctx, warnWithin)
+	})
+
+	// Register tool: Audit deployment injection
+	auditDeploymentInjectionTool := mcp.NewTool("audit_deployment_injection",
+		mcp.WithDescription("Audits proxy injection for a deployment: effective inject decision, proxy presence in its pods, version skew against the control plane, and pod template annotation validity"),
+		mcp.WithString("namespace",
+			mcp.Required(),
+			mcp.Description("The namespace of the deployment"),
+		),
+		mcp.WithString("deployment",
+			mcp.Required(),
+			mcp.Description("The name of the deployment"),
+		),
+	)
+	addTool(auditDeploymentInjectionTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args := toolArgumentsOf(request)
+		namespace := args.stringArg("namespace", "")
+		deployment := args.stringArg("deployment", "")
+		if err := args.Err(); err != nil {
+			return invalidArguments(err), nil
+		}
+		return s.healthChecker.AuditDeploymentInjection(ctx, namespace, deployment)
+	})
+
+	// Register tool: Check Linkerd CRDs
+	checkCRDsTool := mcp.NewTool("check_crds",
+		mcp.WithDescription("Checks that the Linkerd policy CRDs are installed and reports which versions are served"),
+	)
+	addTool(checkCRDsTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return s.crdChecker.CheckCRDs(ctx)
+	})
+
 	// Register tool: Analyze connectivity policies
 	analyzeConnectivityTool := mcp.NewTool("analyze_connectivity",
 		mcp.WithDescription("Analyzes Linkerd policies to determine allowed connectivity between services"),
@@ -79,26 +586,86 @@ func (s *LinkerdMCPServer) RegisterTools(mcpServer *server.MCPServer) {
 			mcp.Description("The name of the target service"),
 		),
 	)
-	mcpServer.AddTool(analyzeConnectivityTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-		args, _ := request.Params.Arguments.(map[string]interface{})
-		sourceNamespace, _ := args["source_namespace"].(string)
-		sourceService, _ := args["source_service"].(string)
-		targetNamespace, _ := args["target_namespace"].(string)
-		targetService, _ := args["target_service"].(string)
+	addTool(analyzeConnectivityTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args := toolArgumentsOf(request)
+		sourceNamespace := args.stringArg("source_namespace", "")
+		sourceService := args.stringArg("source_service", "")
+		targetNamespace := args.stringArg("target_namespace", "")
+		targetService := args.stringArg("target_service", "")
+		if err := args.Err(); err != nil {
+			return invalidArguments(err), nil
+		}
 		return s.policyAnalyzer.AnalyzeConnectivity(ctx, sourceNamespace, sourceService, targetNamespace, targetService)
 	})
 
+	// Register tool: Simulate access for an identity
+	simulateAccessTool := mcp.NewTool("simulate_access",
+		mcp.WithDescription("Determines whether a service account would be allowed to reach a target service, without requiring a running source pod"),
+		mcp.WithString("source_namespace",
+			mcp.Required(),
+			mcp.Description("The namespace of the source service account"),
+		),
+		mcp.WithString("source_service_account",
+			mcp.Description("The source service account (defaults to 'default')"),
+		),
+		mcp.WithString("target_namespace",
+			mcp.Description("The namespace of the target service (defaults to source_namespace)"),
+		),
+		mcp.WithString("target_service",
+			mcp.Required(),
+			mcp.Description("The name of the target service"),
+		),
+	)
+	addTool(simulateAccessTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args := toolArgumentsOf(request)
+		sourceNamespace := args.stringArg("source_namespace", "")
+		sourceServiceAccount := args.stringArg("source_service_account", "")
+		targetNamespace := args.stringArg("target_namespace", "")
+		targetService := args.stringArg("target_service", "")
+		if err := args.Err(); err != nil {
+			return invalidArguments(err), nil
+		}
+		return s.policyAnalyzer.SimulateAccess(ctx, sourceNamespace, sourceServiceAccount, targetNamespace, targetService)
+	})
+
 	// Register tool: List service mesh services
 	listMeshedServicesTool := mcp.NewTool("list_meshed_services",
 		mcp.WithDescription("Lists all services that are part of the Linkerd mesh"),
 		mcp.WithString("namespace",
 			mcp.Description("The namespace to filter services (optional, defaults to all namespaces)"),
 		),
+		mcp.WithString("label_selector",
+			mcp.Description("Only consider pods matching this Kubernetes label selector, e.g. 'team=payments' (optional)"),
+		),
+		mcp.WithBoolean("include_control_plane",
+			mcp.Description("Also list the Linkerd control plane and extensions such as linkerd-viz (default: false)"),
+		),
 	)
-	mcpServer.AddTool(listMeshedServicesTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-		args, _ := request.Params.Arguments.(map[string]interface{})
-		namespace, _ := args["namespace"].(string)
-		return s.serviceLister.ListMeshedServices(ctx, namespace)
+	addTool(listMeshedServicesTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args := toolArgumentsOf(request)
+		namespace := args.stringArg("namespace", "")
+		labelSelector := args.stringArg("label_selector", "")
+		includeControlPlane := args.boolArg("include_control_plane", false)
+		if err := args.Err(); err != nil {
+			return invalidArguments(err), nil
+		}
+		return s.serviceLister.ListMeshedServices(ctx, namespace, labelSelector, includeControlPlane)
+	})
+
+	// Register tool: List ServiceProfiles
+	listServiceProfilesTool := mcp.NewTool("list_service_profiles",
+		mcp.WithDescription("Lists Linkerd ServiceProfiles with their routes, retry budgets and timeouts, and validates route regexes and durations"),
+		mcp.WithString("namespace",
+			mcp.Description("The namespace to filter ServiceProfiles (optional, defaults to all namespaces)"),
+		),
+	)
+	addTool(listServiceProfilesTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args := toolArgumentsOf(request)
+		namespace := args.stringArg("namespace", "")
+		if err := args.Err(); err != nil {
+			return invalidArguments(err), nil
+		}
+		return s.profileLister.ListServiceProfiles(ctx, namespace)
 	})
 
 	// Register tool: Get allowed targets for a source
@@ -112,12 +679,19 @@ func (s *LinkerdMCPServer) RegisterTools(mcpServer *server.MCPServer) {
 			mcp.Required(),
 			mcp.Description("The name of the source service"),
 		),
+		mcp.WithBoolean("explain",
+			mcp.Description("Also list the Servers the source can't reach and why (default: false)"),
+		),
 	)
-	mcpServer.AddTool(getAllowedTargetsTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-		args, _ := request.Params.Arguments.(map[string]interface{})
-		sourceNamespace, _ := args["source_namespace"].(string)
-		sourceService, _ := args["source_service"].(string)
-		return s.policyAnalyzer.GetAllowedTargets(ctx, sourceNamespace, sourceService)
+	addTool(getAllowedTargetsTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args := toolArgumentsOf(request)
+		sourceNamespace := args.stringArg("source_namespace", "")
+		sourceService := args.stringArg("source_service", "")
+		explain := args.boolArg("explain", false)
+		if err := args.Err(); err != nil {
+			return invalidArguments(err), nil
+		}
+		return s.policyAnalyzer.GetAllowedTargets(ctx, sourceNamespace, sourceService, explain)
 	})
 
 	// Register tool: Get allowed sources for a target
@@ -131,12 +705,125 @@ func (s *LinkerdMCPServer) RegisterTools(mcpServer *server.MCPServer) {
 			mcp.Required(),
 			mcp.Description("The name of the target service"),
 		),
+		mcp.WithBoolean("explain",
+			mcp.Description("Also list why matching Servers deny sources, e.g. missing policies or authentication resources (default: false)"),
+		),
+	)
+	addTool(getAllowedSourcesTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args := toolArgumentsOf(request)
+		targetNamespace := args.stringArg("target_namespace", "")
+		targetService := args.stringArg("target_service", "")
+		explain := args.boolArg("explain", false)
+		if err := args.Err(); err != nil {
+			return invalidArguments(err), nil
+		}
+		return s.policyAnalyzer.GetAllowedSources(ctx, targetNamespace, targetService, explain)
+	})
+
+	// Register tool: Export policy graph
+	exportPolicyGraphTool := mcp.NewTool("export_policy_graph",
+		mcp.WithDescription("Export the authorization graph of source identities to Servers, with each edge annotated by its authorizing policy and authentication kind"),
+		mcp.WithString("namespace",
+			mcp.Description("The namespace to export (optional, defaults to all namespaces)"),
+		),
+		mcp.WithString("format",
+			mcp.Description("Output format: json or dot (default: json)"),
+		),
+	)
+	addTool(exportPolicyGraphTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args := toolArgumentsOf(request)
+		namespace := args.stringArg("namespace", "")
+		format := args.stringArg("format", "")
+		if err := args.Err(); err != nil {
+			return invalidArguments(err), nil
+		}
+		return s.policyAnalyzer.ExportPolicyGraph(ctx, namespace, format)
+	})
+
+	// Register tool: Get policy posture
+	getPolicyPostureTool := mcp.NewTool("get_policy_posture",
+		mcp.WithDescription("Classify namespaces as locked-down, partially-covered or open based on the default inbound policy and Server/AuthorizationPolicy coverage of meshed workloads"),
+		mcp.WithString("namespace",
+			mcp.Description("The namespace to check (optional, defaults to all namespaces)"),
+		),
+	)
+	addTool(getPolicyPostureTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args := toolArgumentsOf(request)
+		namespace := args.stringArg("namespace", "")
+		if err := args.Err(); err != nil {
+			return invalidArguments(err), nil
+		}
+		return s.policyAnalyzer.GetPolicyPosture(ctx, namespace)
+	})
+
+	// Register tool: Get namespace policies
+	getNamespacePoliciesTool := mcp.NewTool("get_namespace_policies",
+		mcp.WithDescription("List the security configuration of namespaces: the default inbound policy (from the config.linkerd.io/default-inbound-policy annotation, or the cluster default), the linkerd.io/inject setting, and the number of Servers, AuthorizationPolicies and ServerAuthorizations"),
+		mcp.WithString("namespace",
+			mcp.Description("The namespace to report (optional, defaults to all namespaces)"),
+		),
 	)
-	mcpServer.AddTool(getAllowedSourcesTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-		args, _ := request.Params.Arguments.(map[string]interface{})
-		targetNamespace, _ := args["target_namespace"].(string)
-		targetService, _ := args["target_service"].(string)
-		return s.policyAnalyzer.GetAllowedSources(ctx, targetNamespace, targetService)
+	addTool(getNamespacePoliciesTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args := toolArgumentsOf(request)
+		namespace := args.stringArg("namespace", "")
+		if err := args.Err(); err != nil {
+			return invalidArguments(err), nil
+		}
+		return s.policyAnalyzer.GetNamespacePolicies(ctx, namespace)
+	})
+
+	// Register tool: Get egress policy
+	getEgressPolicyTool := mcp.NewTool("get_egress_policy",
+		mcp.WithDescription("Report which destinations outside the cluster the meshed clients of a namespace may reach, from the EgressNetworks of the namespace and of the global egress namespace and the routes attached to them"),
+		mcp.WithString("namespace",
+			mcp.Required(),
+			mcp.Description("The namespace of the clients"),
+		),
+	)
+	addTool(getEgressPolicyTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args := toolArgumentsOf(request)
+		namespace := args.stringArg("namespace", "")
+		if err := args.Err(); err != nil {
+			return invalidArguments(err), nil
+		}
+		return s.policyAnalyzer.GetEgressPolicy(ctx, namespace)
+	})
+
+	// Register tool: List authorization policies
+	listAuthorizationPoliciesTool := mcp.NewTool("list_authorization_policies",
+		mcp.WithDescription("List AuthorizationPolicies with the Server or HTTPRoute each one targets and the identities, service accounts and networks its requiredAuthenticationRefs resolve to, for auditing who may reach what"),
+		mcp.WithString("namespace",
+			mcp.Description("The namespace of the AuthorizationPolicies (optional, defaults to all namespaces)"),
+		),
+	)
+	addTool(listAuthorizationPoliciesTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args := toolArgumentsOf(request)
+		namespace := args.stringArg("namespace", "")
+		if err := args.Err(); err != nil {
+			return invalidArguments(err), nil
+		}
+		return s.policyAnalyzer.ListAuthorizationPolicies(ctx, namespace)
+	})
+
+	// Register tool: Get service scorecard
+	getServiceScorecardTool := mcp.NewTool("get_service_scorecard",
+		mcp.WithDescription("Combine the metrics health status, policy protection and mTLS coverage of each service in a namespace to show which services are both healthy and secure"),
+		mcp.WithString("namespace",
+			mcp.Required(),
+			mcp.Description("The namespace to check"),
+		),
+		mcp.WithString("time_range",
+			mcp.Description("Time range for metrics (e.g., '5m', '1h', '24h'). Default: 5m"),
+		),
+	)
+	addTool(getServiceScorecardTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args := toolArgumentsOf(request)
+		namespace := args.stringArg("namespace", "")
+		timeRange := args.stringArg("time_range", "")
+		if err := args.Err(); err != nil {
+			return invalidArguments(err), nil
+		}
+		return s.scorecardBuilder.GetServiceScorecard(ctx, namespace, timeRange, metrics.DefaultHealthThresholds())
 	})
 
 	// Register tool: Validate mesh configuration
@@ -146,25 +833,110 @@ func (s *LinkerdMCPServer) RegisterTools(mcpServer *server.MCPServer) {
 			mcp.Description("Namespace to validate (empty for all namespaces)"),
 		),
 		mcp.WithString("resource_type",
-			mcp.Description("Resource type to validate (server|authpolicy|meshtls|all)"),
+			mcp.Description("Resource type to validate (server|authpolicy|meshtls|networkauth|serviceprofile|egress|all)"),
 		),
 		mcp.WithString("resource_name",
 			mcp.Description("Specific resource name to validate"),
 		),
 		mcp.WithBoolean("include_warnings",
-			mcp.Description("Include warnings in results (default: true)"),
+			mcp.Description("Include warnings in results (default: true). false is the same as min_severity 'error'"),
+		),
+		mcp.WithString("min_severity",
+			mcp.Description("Only report issues at or above this severity: 'info', 'warning' or 'error'. When set, any reported issue fails the report ('passed': false); otherwise only errors do"),
+		),
+		mcp.WithString("format",
+			mcp.Description("Output format: 'json' (default) or 'markdown' for a human-readable summary table"),
+		),
+		mcp.WithNumber("max_results",
+			mcp.Description("Maximum number of resources in the results, preferring those with errors, then warnings. The report is marked 'truncated' when capped; counts always cover every resource. Default: no limit"),
 		),
 	)
-	mcpServer.AddTool(validateMeshConfigTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-		args, _ := request.Params.Arguments.(map[string]interface{})
-		namespace, _ := args["namespace"].(string)
-		resourceType, _ := args["resource_type"].(string)
-		resourceName, _ := args["resource_name"].(string)
-		includeWarnings := true
-		if v, ok := args["include_warnings"].(bool); ok {
-			includeWarnings = v
-		}
-		return s.configValidator.ValidateConfig(ctx, namespace, resourceType, resourceName, includeWarnings)
+	addTool(validateMeshConfigTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args := toolArgumentsOf(request)
+		namespace := args.stringArg("namespace", "")
+		resourceType := args.stringArg("resource_type", "")
+		resourceName := args.stringArg("resource_name", "")
+		minSeverity := args.stringArg("min_severity", "")
+		if !args.boolArg("include_warnings", true) && minSeverity == "" {
+			minSeverity = "error"
+		}
+		format := args.stringArg("format", "")
+		maxResults := args.intArg("max_results", 0)
+		if err := args.Err(); err != nil {
+			return invalidArguments(err), nil
+		}
+		return s.configValidator.ValidateConfig(ctx, namespace, resourceType, resourceName, minSeverity, format, maxResults)
+	})
+
+	// Register tool: Validate a resource document
+	validateResourceTool := mcp.NewTool("validate_resource",
+		mcp.WithDescription("Validate a proposed Server, AuthorizationPolicy, MeshTLSAuthentication, NetworkAuthentication, ServiceProfile or EgressNetwork before applying it. References to other resources are checked against the live cluster."),
+		mcp.WithString("resource",
+			mcp.Required(),
+			mcp.Description("The resource as a YAML or JSON document"),
+		),
+	)
+	addTool(validateResourceTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args := toolArgumentsOf(request)
+		resource := args.stringArg("resource", "")
+		if err := args.Err(); err != nil {
+			return invalidArguments(err), nil
+		}
+		return s.configValidator.ValidateResource(ctx, resource)
+	})
+
+	// Register tool: Find missing service accounts
+	findMissingServiceAccountsTool := mcp.NewTool("find_missing_service_accounts",
+		mcp.WithDescription("List service accounts referenced by MeshTLSAuthentications that don't exist in the cluster"),
+		mcp.WithString("namespace",
+			mcp.Description("Namespace of the authentication resources to inspect (empty for all namespaces)"),
+		),
+	)
+	addTool(findMissingServiceAccountsTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args := toolArgumentsOf(request)
+		namespace := args.stringArg("namespace", "")
+		if err := args.Err(); err != nil {
+			return invalidArguments(err), nil
+		}
+		return s.configValidator.FindMissingServiceAccounts(ctx, namespace)
+	})
+
+	// Register tool: Tap a deployment
+	tapServiceTool := mcp.NewTool("tap_service",
+		mcp.WithDescription("Sample live requests to and from a deployment through the Linkerd viz tap API, like 'linkerd viz tap'. Returns a bounded sample of request and response events with source, destination, path, status and latency"),
+		mcp.WithString("namespace",
+			mcp.Required(),
+			mcp.Description("The namespace of the deployment"),
+		),
+		mcp.WithString("deployment",
+			mcp.Required(),
+			mcp.Description("The deployment to tap"),
+		),
+		mcp.WithString("method",
+			mcp.Description("Only report requests with this HTTP method (e.g., 'GET')"),
+		),
+		mcp.WithString("path",
+			mcp.Description("Only report requests whose path starts with this prefix"),
+		),
+		mcp.WithNumber("max_events",
+			mcp.Description(fmt.Sprintf("Stop after this many events. Default: %d, max: %d", tap.DefaultMaxEvents, tap.MaxEventsLimit)),
+		),
+		mcp.WithNumber("duration_seconds",
+			mcp.Description(fmt.Sprintf("Stop after this many seconds. Default: %.0f, max: %.0f", tap.DefaultDuration.Seconds(), tap.MaxDuration.Seconds())),
+		),
+	)
+	addTool(tapServiceTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args := toolArgumentsOf(request)
+		namespace := args.stringArg("namespace", "")
+		deployment := args.stringArg("deployment", "")
+		method := args.stringArg("method", "")
+		path := args.stringArg("path", "")
+		maxEvents := args.intArg("max_events", 0)
+		duration := time.Duration(args.floatArg("duration_seconds", 0) * float64(time.Second))
+		if err := args.Err(); err != nil {
+			return invalidArguments(err), nil
+		}
+		return s.tapper.TapDeployment(ctx, namespace, deployment, method, path, maxEvents, duration)
 	})
 
 	// Only register metrics tools if collector is available
@@ -172,6 +944,34 @@ func (s *LinkerdMCPServer) RegisterTools(mcpServer *server.MCPServer) {
 		// Register tool: Get service metrics
 		getServiceMetricsTool := mcp.NewTool("get_service_metrics",
 			mcp.WithDescription("Get traffic metrics for a service (request rate, latency, success rate)"),
+			s.metricsNamespaceArgument("The namespace of the service"),
+			mcp.WithString("service",
+				mcp.Required(),
+				mcp.Description("The name of the service"),
+			),
+			mcp.WithString("time_range",
+				mcp.Description("Time range for metrics (e.g., '5m', '1h', '24h'). Default: 5m"),
+			),
+			mcp.WithString("direction",
+				mcp.Description("'inbound' for the requests the service receives, or 'outbound' for the requests it sends to its dependencies (default: inbound)"),
+				mcp.Enum("inbound", "outbound"),
+			),
+		)
+		addTool(getServiceMetricsTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			args := toolArgumentsOf(request)
+			namespace := s.metricsNamespaceFrom(args)
+			service := args.stringArg("service", "")
+			timeRange := args.stringArg("time_range", "")
+			direction := args.stringArg("direction", "")
+			if err := args.Err(); err != nil {
+				return invalidArguments(err), nil
+			}
+			return s.metricsCollector.GetServiceMetrics(ctx, namespace, service, timeRange, direction)
+		})
+
+		// Register tool: Get inbound sources health
+		getInboundSourcesHealthTool := mcp.NewTool("get_inbound_sources_health",
+			mcp.WithDescription("Break a service's inbound success rate and request rate down by source deployment, worst success rate first, to find the caller behind a success rate drop"),
 			mcp.WithString("namespace",
 				mcp.Required(),
 				mcp.Description("The namespace of the service"),
@@ -184,12 +984,228 @@ func (s *LinkerdMCPServer) RegisterTools(mcpServer *server.MCPServer) {
 				mcp.Description("Time range for metrics (e.g., '5m', '1h', '24h'). Default: 5m"),
 			),
 		)
-		mcpServer.AddTool(getServiceMetricsTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-			args, _ := request.Params.Arguments.(map[string]interface{})
-			namespace, _ := args["namespace"].(string)
-			service, _ := args["service"].(string)
-			timeRange, _ := args["time_range"].(string)
-			return s.metricsCollector.GetServiceMetrics(ctx, namespace, service, timeRange)
+		addTool(getInboundSourcesHealthTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			args := toolArgumentsOf(request)
+			namespace := args.stringArg("namespace", "")
+			service := args.stringArg("service", "")
+			timeRange := args.stringArg("time_range", "")
+			if err := args.Err(); err != nil {
+				return invalidArguments(err), nil
+			}
+			return s.metricsCollector.GetInboundSourcesHealth(ctx, namespace, service, timeRange)
+		})
+
+		// Register tool: Get service dependencies
+		getServiceDependenciesTool := mcp.NewTool("get_service_dependencies",
+			mcp.WithDescription("List every destination a service actually sends requests to, from its outbound traffic, with the request rate and success rate of each. Reveals runtime dependencies no policy or configuration declares"),
+			mcp.WithString("namespace",
+				mcp.Required(),
+				mcp.Description("The namespace of the service"),
+			),
+			mcp.WithString("service",
+				mcp.Required(),
+				mcp.Description("The name of the service"),
+			),
+			mcp.WithString("time_range",
+				mcp.Description("Time range for metrics (e.g., '5m', '1h', '24h'). Default: 5m"),
+			),
+			mcp.WithNumber("limit",
+				mcp.Description("Maximum number of dependencies to return, busiest first (default: all)"),
+			),
+		)
+		addTool(getServiceDependenciesTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			args := toolArgumentsOf(request)
+			namespace := args.stringArg("namespace", "")
+			service := args.stringArg("service", "")
+			timeRange := args.stringArg("time_range", "")
+			limit := args.intArg("limit", 0)
+			if err := args.Err(); err != nil {
+				return invalidArguments(err), nil
+			}
+			return s.metricsCollector.GetServiceDependencies(ctx, namespace, service, timeRange, limit)
+		})
+
+		// Register tool: Get route metrics
+		getRouteMetricsTool := mcp.NewTool("get_route_metrics",
+			mcp.WithDescription("Get success rate and latency for each route of a service, as defined by its ServiceProfile or HTTPRoutes"),
+			mcp.WithString("namespace",
+				mcp.Required(),
+				mcp.Description("The namespace of the service"),
+			),
+			mcp.WithString("service",
+				mcp.Required(),
+				mcp.Description("The name of the service"),
+			),
+			mcp.WithString("time_range",
+				mcp.Description("Time range for metrics (e.g., '5m', '1h', '24h'). Default: 5m"),
+			),
+		)
+		addTool(getRouteMetricsTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			args := toolArgumentsOf(request)
+			namespace := args.stringArg("namespace", "")
+			service := args.stringArg("service", "")
+			timeRange := args.stringArg("time_range", "")
+			if err := args.Err(); err != nil {
+				return invalidArguments(err), nil
+			}
+			return s.metricsCollector.GetRouteMetrics(ctx, namespace, service, timeRange)
+		})
+
+		// Register tool: Get errors detail
+		getErrorsDetailTool := mcp.NewTool("get_errors_detail",
+			mcp.WithDescription("Show what is failing for a service: the routes and status codes of its failed responses over a window, most frequent first, plus live failing requests (method, path, status) sampled through the Linkerd viz tap API when it is available"),
+			mcp.WithString("namespace",
+				mcp.Required(),
+				mcp.Description("The namespace of the service"),
+			),
+			mcp.WithString("service",
+				mcp.Required(),
+				mcp.Description("The name of the service"),
+			),
+			mcp.WithString("time_range",
+				mcp.Description("Time range for metrics (e.g., '5m', '1h', '24h'). Default: 5m"),
+			),
+			mcp.WithNumber("limit",
+				mcp.Description("Maximum number of failing routes and status codes to return (default: 10)"),
+			),
+			mcp.WithBoolean("tap",
+				mcp.Description("Sample live failing requests through tap (default: true)"),
+			),
+			mcp.WithNumber("sample_seconds",
+				mcp.Description("How long to sample live requests, in seconds (default: 5, max: 30)"),
+			),
+		)
+		addTool(getErrorsDetailTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			args := toolArgumentsOf(request)
+			namespace := args.stringArg("namespace", "")
+			service := args.stringArg("service", "")
+			timeRange := args.stringArg("time_range", "")
+			limit := args.intArg("limit", 0)
+			var sampler metrics.FailureSampler
+			if args.boolArg("tap", true) {
+				sampler = s.tapper
+			}
+			sampleDuration := time.Duration(args.floatArg("sample_seconds", 0) * float64(time.Second))
+			if err := args.Err(); err != nil {
+				return invalidArguments(err), nil
+			}
+			return s.metricsCollector.GetErrorsDetail(ctx, namespace, service, timeRange, limit, sampler, sampleDuration)
+		})
+
+		// Register tool: Get mTLS coverage
+		getMTLSCoverageTool := mcp.NewTool("get_mtls_coverage",
+			mcp.WithDescription("Report the share of inbound requests each service received over mTLS, flagging services that receive plaintext traffic (e.g. from unmeshed clients)"),
+			mcp.WithString("namespace",
+				mcp.Required(),
+				mcp.Description("The namespace to check"),
+			),
+			mcp.WithString("service",
+				mcp.Description("Only report this service (optional, defaults to all services with traffic)"),
+			),
+			mcp.WithBoolean("by_source",
+				mcp.Description("Break each service's traffic down by client identity (default: false)"),
+			),
+			mcp.WithString("time_range",
+				mcp.Description("Time range for metrics (e.g., '5m', '1h', '24h'). Default: 5m"),
+			),
+		)
+		addTool(getMTLSCoverageTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			args := toolArgumentsOf(request)
+			namespace := args.stringArg("namespace", "")
+			service := args.stringArg("service", "")
+			bySource := args.boolArg("by_source", false)
+			timeRange := args.stringArg("time_range", "")
+			if err := args.Err(); err != nil {
+				return invalidArguments(err), nil
+			}
+			return s.metricsCollector.GetMTLSCoverage(ctx, namespace, service, timeRange, bySource)
+		})
+
+		// Register tool: Get multicluster traffic
+		getMulticlusterTrafficTool := mcp.NewTool("get_multicluster_traffic",
+			mcp.WithDescription("Summarize cross-cluster traffic: request and success rate sent to each remote cluster, and received from remote clusters through the multicluster gateway"),
+			mcp.WithString("namespace",
+				mcp.Description("Only count outbound traffic from this namespace (optional, defaults to all namespaces)"),
+			),
+			mcp.WithString("cluster",
+				mcp.Description("Only report outbound traffic to this remote cluster (optional, defaults to all linked clusters)"),
+			),
+			mcp.WithString("gateway_namespace",
+				mcp.Description("The namespace of the multicluster gateway (default: linkerd-multicluster)"),
+			),
+			mcp.WithString("time_range",
+				mcp.Description("Time range for metrics (e.g., '5m', '1h', '24h'). Default: 5m"),
+			),
+		)
+		addTool(getMulticlusterTrafficTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			args := toolArgumentsOf(request)
+			namespace := args.stringArg("namespace", "")
+			cluster := args.stringArg("cluster", "")
+			gatewayNamespace := args.stringArg("gateway_namespace", "")
+			timeRange := args.stringArg("time_range", "")
+			if err := args.Err(); err != nil {
+				return invalidArguments(err), nil
+			}
+			return s.metricsCollector.GetMulticlusterTraffic(ctx, namespace, cluster, gatewayNamespace, timeRange)
+		})
+
+		// Register tool: Get proxy resource usage
+		getProxyResourceUsageTool := mcp.NewTool("get_proxy_resource_usage",
+			mcp.WithDescription("Report the CPU and memory used by the Linkerd proxies of a namespace, per service and per proxy, flagging proxies that exceed their configured limits"),
+			mcp.WithString("namespace",
+				mcp.Required(),
+				mcp.Description("The namespace of the proxies"),
+			),
+			mcp.WithString("time_range",
+				mcp.Description("Time range the CPU usage is averaged over (e.g., '5m', '1h', '24h'). Default: 5m"),
+			),
+		)
+		addTool(getProxyResourceUsageTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			args := toolArgumentsOf(request)
+			namespace := args.stringArg("namespace", "")
+			timeRange := args.stringArg("time_range", "")
+			if err := args.Err(); err != nil {
+				return invalidArguments(err), nil
+			}
+			return s.metricsCollector.GetProxyResourceUsage(ctx, namespace, timeRange)
+		})
+
+		// Register tool: Compare service metrics
+		compareServiceMetricsTool := mcp.NewTool("compare_service_metrics",
+			mcp.WithDescription("Compare a service's metrics across a baseline and a current time window, e.g. before and after a deploy, and flag regressions in request rate, success rate and p95 latency"),
+			mcp.WithString("namespace",
+				mcp.Required(),
+				mcp.Description("The namespace of the service"),
+			),
+			mcp.WithString("service",
+				mcp.Required(),
+				mcp.Description("The name of the service"),
+			),
+			mcp.WithString("current",
+				mcp.Description(fmt.Sprintf("Length of the current window, ending now (e.g., '5m', '1h'). Default: %s", metrics.DefaultComparisonRange)),
+			),
+			mcp.WithString("baseline",
+				mcp.Description("Length of the baseline window. Default: the length of the current window"),
+			),
+			mcp.WithString("baseline_offset",
+				mcp.Description("How long ago the baseline window ends (e.g., '1h', '24h'). Default: the length of the current window, i.e. the baseline immediately precedes it"),
+			),
+			mcp.WithNumber("regression_threshold",
+				mcp.Description(fmt.Sprintf("Change in percent of the baseline beyond which a metric moving in the wrong direction is flagged as a regression. Default: %g", metrics.DefaultRegressionThreshold)),
+			),
+		)
+		addTool(compareServiceMetricsTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			args := toolArgumentsOf(request)
+			namespace := args.stringArg("namespace", "")
+			service := args.stringArg("service", "")
+			current := args.stringArg("current", "")
+			baseline := args.stringArg("baseline", "")
+			baselineOffset := args.stringArg("baseline_offset", "")
+			threshold := args.floatArg("regression_threshold", metrics.DefaultRegressionThreshold)
+			if err := args.Err(); err != nil {
+				return invalidArguments(err), nil
+			}
+			return s.metricsCollector.CompareServiceMetrics(ctx, namespace, service, current, baseline, baselineOffset, threshold)
 		})
 
 		// Register tool: Analyze traffic flow
@@ -214,45 +1230,243 @@ func (s *LinkerdMCPServer) RegisterTools(mcpServer *server.MCPServer) {
 				mcp.Description("Time range for metrics (e.g., '5m', '1h', '24h'). Default: 5m"),
 			),
 		)
-		mcpServer.AddTool(analyzeTrafficFlowTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-			args, _ := request.Params.Arguments.(map[string]interface{})
-			sourceNs, _ := args["source_namespace"].(string)
-			sourceService, _ := args["source_service"].(string)
-			targetNs, _ := args["target_namespace"].(string)
-			targetService, _ := args["target_service"].(string)
-			timeRange, _ := args["time_range"].(string)
+		addTool(analyzeTrafficFlowTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			args := toolArgumentsOf(request)
+			sourceNs := args.stringArg("source_namespace", "")
+			sourceService := args.stringArg("source_service", "")
+			targetNs := args.stringArg("target_namespace", "")
+			targetService := args.stringArg("target_service", "")
+			timeRange := args.stringArg("time_range", "")
 			if targetNs == "" {
 				targetNs = sourceNs
 			}
+			if err := args.Err(); err != nil {
+				return invalidArguments(err), nil
+			}
 			return s.metricsCollector.AnalyzeTrafficFlow(ctx, sourceNs, sourceService, targetNs, targetService, timeRange)
 		})
 
+		// Register tool: Get traffic throughput
+		getTrafficThroughputTool := mcp.NewTool("get_traffic_throughput",
+			mcp.WithDescription("Get the bytes per second a source service sends to and receives from a target service, with totals over the time range"),
+			mcp.WithString("source_namespace",
+				mcp.Required(),
+				mcp.Description("The namespace of the source service"),
+			),
+			mcp.WithString("source_service",
+				mcp.Required(),
+				mcp.Description("The name of the source service"),
+			),
+			mcp.WithString("target_namespace",
+				mcp.Description("The namespace of the target service (defaults to source_namespace)"),
+			),
+			mcp.WithString("target_service",
+				mcp.Required(),
+				mcp.Description("The name of the target service"),
+			),
+			mcp.WithString("time_range",
+				mcp.Description("Time range for metrics (e.g., '5m', '1h', '24h'). Default: 5m"),
+			),
+		)
+		addTool(getTrafficThroughputTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			args := toolArgumentsOf(request)
+			sourceNs := args.stringArg("source_namespace", "")
+			sourceService := args.stringArg("source_service", "")
+			targetNs := args.stringArg("target_namespace", "")
+			targetService := args.stringArg("target_service", "")
+			timeRange := args.stringArg("time_range", "")
+			if targetNs == "" {
+				targetNs = sourceNs
+			}
+			if err := args.Err(); err != nil {
+				return invalidArguments(err), nil
+			}
+			return s.metricsCollector.GetTrafficThroughput(ctx, sourceNs, sourceService, targetNs, targetService, timeRange)
+		})
+
+		// Register tool: Query Linkerd metrics, when raw PromQL is allowed
+		if s.promQLMode == config.PromQLPassthroughLinkerd || s.promQLMode == config.PromQLPassthroughAny {
+			queryLinkerdMetricsTool := mcp.NewTool("query_linkerd_metrics",
+				mcp.WithDescription("Run a raw PromQL instant query against the Linkerd Prometheus, for metrics the other tools don't compute. Returns the vector or scalar result"),
+				mcp.WithString("query",
+					mcp.Required(),
+					mcp.Description(queryLinkerdMetricsDescription(s.promQLMode)),
+				),
+				mcp.WithString("time",
+					mcp.Description("Evaluation time, as RFC 3339 or Unix seconds. Default: now"),
+				),
+			)
+			addTool(queryLinkerdMetricsTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				args := toolArgumentsOf(request)
+				query := args.stringArg("query", "")
+				ts := args.stringArg("time", "")
+				if err := args.Err(); err != nil {
+					return invalidArguments(err), nil
+				}
+				return s.metricsCollector.QueryMetrics(ctx, query, ts, s.promQLMode == config.PromQLPassthroughLinkerd)
+			})
+		}
+
 		// Register tool: Get service health summary
 		getServiceHealthSummaryTool := mcp.NewTool("get_service_health_summary",
 			mcp.WithDescription("Get health summary for all services in a namespace based on metrics"),
+			s.metricsNamespaceArgument("The namespace to check"),
+			mcp.WithString("time_range",
+				mcp.Description("Time range for metrics (e.g., '5m', '1h', '24h'). Default: 5m"),
+			),
+			mcp.WithNumber("error_rate_warning",
+				mcp.Description("Error rate percentage that triggers a warning. Default: 5"),
+			),
+			mcp.WithNumber("error_rate_critical",
+				mcp.Description("Error rate percentage that is critical. Must exceed the warning threshold. Default: 10"),
+			),
+			mcp.WithNumber("latency_p95_warning",
+				mcp.Description("P95 latency in milliseconds that triggers a warning. Default: 1000"),
+			),
+			mcp.WithNumber("latency_p95_critical",
+				mcp.Description("P95 latency in milliseconds that is critical. Must exceed the warning threshold. Default: 5000"),
+			),
+			mcp.WithNumber("success_rate_warning",
+				mcp.Description("Success rate percentage at or below which a warning is raised. Default: 95"),
+			),
+			mcp.WithNumber("success_rate_critical",
+				mcp.Description("Success rate percentage at or below which the service is critical. Must be below the warning threshold. Default: 90"),
+			),
+			mcp.WithNumber("min_request_rate",
+				mcp.Description("Requests per second below which there is too little traffic to assess health and the status is unknown. Default: 0.1"),
+			),
+			mcp.WithNumber("client_error_rate_warning",
+				mcp.Description("4xx response percentage that triggers a warning. Setting any status class threshold checks the 4xx and 5xx rates separately instead of the combined error rate. Default: unset"),
+			),
+			mcp.WithNumber("client_error_rate_critical",
+				mcp.Description("4xx response percentage that is critical. Must exceed the warning threshold when both are set. Default: unset"),
+			),
+			mcp.WithNumber("server_error_rate_warning",
+				mcp.Description("5xx response percentage that triggers a warning. Default: unset"),
+			),
+			mcp.WithNumber("server_error_rate_critical",
+				mcp.Description("5xx response percentage that is critical. Must exceed the warning threshold when both are set. Default: unset"),
+			),
+		)
+		addTool(getServiceHealthSummaryTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			args := toolArgumentsOf(request)
+			namespace := s.metricsNamespaceFrom(args)
+			timeRange := args.stringArg("time_range", "")
+			thresholds := healthThresholds(args)
+			if err := args.Err(); err != nil {
+				return invalidArguments(err), nil
+			}
+			return s.metricsCollector.GetServiceHealthSummary(ctx, namespace, timeRange, thresholds)
+		})
+
+		// Register tool: Detect traffic anomalies
+		detectTrafficAnomaliesTool := mcp.NewTool("detect_traffic_anomalies",
+			mcp.WithDescription("Detect anomalies (drops to zero, spikes) in a service's request rate over a time window"),
 			mcp.WithString("namespace",
 				mcp.Required(),
-				mcp.Description("The namespace to check"),
+				mcp.Description("The namespace of the service"),
+			),
+			mcp.WithString("service",
+				mcp.Required(),
+				mcp.Description("The name of the service"),
 			),
 			mcp.WithString("time_range",
-				mcp.Description("Time range for metrics (e.g., '5m', '1h', '24h'). Default: 5m"),
+				mcp.Description("Time range to analyze (e.g., '1h', '24h'). Default: 5m"),
+			),
+			mcp.WithString("step",
+				mcp.Description("Resolution of the series (e.g., '15s', '1m'). Default: computed from time_range"),
+			),
+			mcp.WithNumber("std_dev_threshold",
+				mcp.Description("Number of standard deviations above the mean that counts as a spike. Default: 3"),
 			),
 		)
-		mcpServer.AddTool(getServiceHealthSummaryTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-			args, _ := request.Params.Arguments.(map[string]interface{})
-			namespace, _ := args["namespace"].(string)
-			timeRange, _ := args["time_range"].(string)
-			thresholds := metrics.DefaultHealthThresholds()
-			return s.metricsCollector.GetServiceHealthSummary(ctx, namespace, timeRange, thresholds)
+		addTool(detectTrafficAnomaliesTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			args := toolArgumentsOf(request)
+			namespace := args.stringArg("namespace", "")
+			service := args.stringArg("service", "")
+			timeRange := args.stringArg("time_range", "")
+			step := args.stringArg("step", "")
+			stdDevThreshold := args.floatArg("std_dev_threshold", metrics.DefaultAnomalyStdDevThreshold)
+			if err := args.Err(); err != nil {
+				return invalidArguments(err), nil
+			}
+			return s.metricsCollector.DetectTrafficAnomalies(ctx, namespace, service, timeRange, step, stdDevThreshold)
 		})
 
-		// Register tool: Get top services
-		getTopServicesTool := mcp.NewTool("get_top_services",
-			mcp.WithDescription("Get services ranked by traffic metrics"),
+		// Register tool: Evaluate SLO
+		evaluateSLOTool := mcp.NewTool("evaluate_slo",
+			mcp.WithDescription("Evaluate a service against a success rate and/or p95 latency SLO over a window: whether it was met, how much of the window was in violation and how much error budget is left"),
+			mcp.WithString("namespace",
+				mcp.Required(),
+				mcp.Description("The namespace of the service"),
+			),
+			mcp.WithString("service",
+				mcp.Required(),
+				mcp.Description("The name of the service"),
+			),
+			mcp.WithNumber("success_rate_target",
+				mcp.Description("Minimum success rate in percent, e.g. 99.9"),
+			),
+			mcp.WithNumber("latency_p95_target",
+				mcp.Description("Maximum p95 latency in milliseconds, e.g. 300"),
+			),
+			mcp.WithString("time_range",
+				mcp.Description("SLO window (e.g., '1h', '24h', '168h'). Default: 1h"),
+			),
+			mcp.WithString("step",
+				mcp.Description("Resolution at which violations are counted (e.g., '1m', '5m'). Default: computed from time_range"),
+			),
+		)
+		addTool(evaluateSLOTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			args := toolArgumentsOf(request)
+			namespace := args.stringArg("namespace", "")
+			service := args.stringArg("service", "")
+			timeRange := args.stringArg("time_range", "")
+			step := args.stringArg("step", "")
+			targets := metrics.SLOTargets{
+				SuccessRate: args.optionalFloatArg("success_rate_target"),
+				LatencyP95:  args.optionalFloatArg("latency_p95_target"),
+			}
+			if err := args.Err(); err != nil {
+				return invalidArguments(err), nil
+			}
+			return s.metricsCollector.EvaluateSLO(ctx, namespace, service, timeRange, step, targets)
+		})
+
+		// Register tool: Get traffic split
+		getTrafficSplitTool := mcp.NewTool("get_traffic_split",
+			mcp.WithDescription("Compare the backend weights of an HTTPRoute with the traffic split observed in metrics, flagging drift (e.g., to confirm a canary receives its intended share)"),
 			mcp.WithString("namespace",
 				mcp.Required(),
-				mcp.Description("The namespace to query"),
+				mcp.Description("The namespace of the HTTPRoute"),
 			),
+			mcp.WithString("route",
+				mcp.Required(),
+				mcp.Description("The name of the HTTPRoute"),
+			),
+			mcp.WithString("time_range",
+				mcp.Description("Time range for metrics (e.g., '5m', '1h'). Default: 5m"),
+			),
+			mcp.WithNumber("drift_threshold",
+				mcp.Description("Difference in percentage points between configured and observed share that counts as drift. Default: 10"),
+			),
+		)
+		addTool(getTrafficSplitTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			args := toolArgumentsOf(request)
+			namespace := args.stringArg("namespace", "")
+			route := args.stringArg("route", "")
+			timeRange := args.stringArg("time_range", "")
+			driftThreshold := args.floatArg("drift_threshold", metrics.DefaultTrafficSplitDriftThreshold)
+			if err := args.Err(); err != nil {
+				return invalidArguments(err), nil
+			}
+			return s.metricsCollector.GetTrafficSplit(ctx, namespace, route, timeRange, driftThreshold)
+		})
+
+		// Register tool: Get top services
+		getTopServicesTool := mcp.NewTool("get_top_services",
+			mcp.WithDescription("Get services ranked by traffic metrics"),
+			s.metricsNamespaceArgument("The namespace to query"),
 			mcp.WithString("sort_by",
 				mcp.Description("Sort by metric: 'request_rate', 'error_rate', 'latency_p95'. Default: request_rate"),
 			),
@@ -263,18 +1477,18 @@ func (s *LinkerdMCPServer) RegisterTools(mcpServer *server.MCPServer) {
 				mcp.Description("Number of top services to return. Default: 10"),
 			),
 		)
-		mcpServer.AddTool(getTopServicesTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-			args, _ := request.Params.Arguments.(map[string]interface{})
-			namespace, _ := args["namespace"].(string)
-			sortBy, _ := args["sort_by"].(string)
-			timeRange, _ := args["time_range"].(string)
-			limit := 10
-			if l, ok := args["limit"].(float64); ok {
-				limit = int(l)
-			}
+		addTool(getTopServicesTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			args := toolArgumentsOf(request)
+			namespace := s.metricsNamespaceFrom(args)
+			sortBy := args.stringArg("sort_by", "")
+			timeRange := args.stringArg("time_range", "")
+			limit := args.intArg("limit", 10)
 			if sortBy == "" {
 				sortBy = "request_rate"
 			}
+			if err := args.Err(); err != nil {
+				return invalidArguments(err), nil
+			}
 			return s.metricsCollector.GetTopServices(ctx, namespace, sortBy, timeRange, limit)
 		})
 	}
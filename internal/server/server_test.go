@@ -4,6 +4,7 @@ import (
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 
+	"github.com/christianhuening/linkerd-mcp/internal/metrics"
 	"github.com/christianhuening/linkerd-mcp/internal/server"
 	"github.com/mark3labs/mcp-go/mcp"
 	mcpserver "github.com/mark3labs/mcp-go/server"
@@ -192,3 +193,23 @@ var _ = Describe("LinkerdMCPServer", func() {
 		})
 	})
 })
+
+var _ = Describe("Health threshold arguments", func() {
+	It("should use the defaults when no threshold is given", func() {
+		Expect(server.HealthThresholds(map[string]interface{}{})).To(Equal(metrics.DefaultHealthThresholds()))
+	})
+
+	It("should override only the given thresholds", func() {
+		thresholds := server.HealthThresholds(map[string]interface{}{
+			"latency_p95_warning":       float64(100),
+			"latency_p95_critical":      float64(200),
+			"server_error_rate_warning": float64(2),
+		})
+
+		expected := metrics.DefaultHealthThresholds()
+		expected.LatencyP95Warning = 100
+		expected.LatencyP95Critical = 200
+		expected.ServerErrorRateWarning = 2
+		Expect(thresholds).To(Equal(expected))
+	})
+})
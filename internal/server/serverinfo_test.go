@@ -0,0 +1,48 @@
+package server_test
+
+import (
+	"context"
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/christianhuening/linkerd-mcp/internal/buildinfo"
+	"github.com/christianhuening/linkerd-mcp/internal/server"
+	"github.com/christianhuening/linkerd-mcp/internal/testutil"
+	"github.com/mark3labs/mcp-go/mcp"
+	mcpserver "github.com/mark3labs/mcp-go/server"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/dynamic/fake"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+)
+
+var _ = Describe("server_info tool", func() {
+	It("should report the build info of the server", func() {
+		info := buildinfo.New("v1.2.3", "abc123", "2025-01-02T03:04:05Z")
+
+		linkerdServer := server.NewWithClients(kubefake.NewSimpleClientset(), fake.NewSimpleDynamicClient(runtime.NewScheme()))
+		linkerdServer.SetBuildInfo(info)
+
+		mcpSrv := mcpserver.NewMCPServer("test-server", "1.0.0", mcpserver.WithToolCapabilities(true))
+		linkerdServer.RegisterTools(mcpSrv)
+
+		message, err := json.Marshal(map[string]interface{}{
+			"jsonrpc": "2.0",
+			"id":      1,
+			"method":  "tools/call",
+			"params":  map[string]interface{}{"name": "server_info"},
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		response, ok := mcpSrv.HandleMessage(context.Background(), message).(mcp.JSONRPCResponse)
+		Expect(ok).To(BeTrue())
+		result, ok := response.Result.(mcp.CallToolResult)
+		Expect(ok).To(BeTrue())
+		Expect(result.IsError).To(BeFalse())
+
+		var reported buildinfo.Info
+		Expect(testutil.ParseJSONResult(&result, &reported)).To(Succeed())
+		Expect(reported).To(Equal(info))
+	})
+})
@@ -0,0 +1,95 @@
+package server_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/christianhuening/linkerd-mcp/internal/config"
+	"github.com/christianhuening/linkerd-mcp/internal/metrics"
+	"github.com/christianhuening/linkerd-mcp/internal/server"
+)
+
+var _ = Describe("Prometheus startup check", func() {
+	var ctx context.Context
+
+	collectorFor := func(url string) *metrics.MetricsCollector {
+		os.Setenv("LINKERD_PROMETHEUS_URL", url)
+		DeferCleanup(os.Unsetenv, "LINKERD_PROMETHEUS_URL")
+
+		collector, err := metrics.NewMetricsCollector(nil, nil, nil, "linkerd")
+		Expect(err).NotTo(HaveOccurred())
+		return collector
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+	})
+
+	Context("when Prometheus is reachable", func() {
+		var collector *metrics.MetricsCollector
+
+		BeforeEach(func() {
+			prometheus := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = w.Write([]byte(`{"status":"success","data":{"resultType":"vector","result":[]}}`))
+			}))
+			DeferCleanup(prometheus.Close)
+			collector = collectorFor(prometheus.URL)
+		})
+
+		It("should pass in require mode", func() {
+			check := config.PrometheusStartupCheck{Mode: config.PrometheusStartupCheckRequire, Timeout: time.Second}
+			Expect(server.CheckPrometheus(ctx, collector, check)).To(Succeed())
+		})
+	})
+
+	Context("when Prometheus is unreachable", func() {
+		var collector *metrics.MetricsCollector
+
+		BeforeEach(func() {
+			// Accept connections but never answer, so only the timeout ends the probe
+			unblock := make(chan struct{})
+			prometheus := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				<-unblock
+			}))
+			DeferCleanup(prometheus.Close)
+			DeferCleanup(func() { close(unblock) })
+			collector = collectorFor(prometheus.URL)
+		})
+
+		It("should fail fast in require mode", func() {
+			check := config.PrometheusStartupCheck{Mode: config.PrometheusStartupCheckRequire, Timeout: 50 * time.Millisecond}
+
+			start := time.Now()
+			err := server.CheckPrometheus(ctx, collector, check)
+			Expect(err).To(MatchError(ContainSubstring("prometheus startup check failed")))
+			Expect(time.Since(start)).To(BeNumerically("<", 2*time.Second))
+		})
+
+		It("should only log in warn mode", func() {
+			check := config.PrometheusStartupCheck{Mode: config.PrometheusStartupCheckWarn, Timeout: 50 * time.Millisecond}
+			Expect(server.CheckPrometheus(ctx, collector, check)).To(Succeed())
+		})
+
+		It("should not probe when the check is off", func() {
+			check := config.PrometheusStartupCheck{Mode: config.PrometheusStartupCheckOff, Timeout: 50 * time.Millisecond}
+			Expect(server.CheckPrometheus(ctx, collector, check)).To(Succeed())
+		})
+	})
+
+	Context("when the metrics collector could not be created", func() {
+		It("should fail in require mode and pass in warn mode", func() {
+			require := config.PrometheusStartupCheck{Mode: config.PrometheusStartupCheckRequire, Timeout: time.Second}
+			Expect(server.CheckPrometheus(ctx, nil, require)).NotTo(Succeed())
+
+			warn := config.PrometheusStartupCheck{Mode: config.PrometheusStartupCheckWarn, Timeout: time.Second}
+			Expect(server.CheckPrometheus(ctx, nil, warn)).To(Succeed())
+		})
+	})
+})
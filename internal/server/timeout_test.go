@@ -0,0 +1,89 @@
+package server_test
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/christianhuening/linkerd-mcp/internal/server"
+	"github.com/christianhuening/linkerd-mcp/internal/testutil"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+var _ = Describe("WithTimeout", func() {
+	var ctx context.Context
+
+	BeforeEach(func() {
+		ctx = context.Background()
+	})
+
+	It("should pass through calls finishing in time", func() {
+		handler := server.WithTimeout("fast_tool", time.Second, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return mcp.NewToolResultText("done"), nil
+		})
+
+		result, err := handler(ctx, mcp.CallToolRequest{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.IsError).To(BeFalse())
+	})
+
+	It("should answer with a timeout error when the handler ignores cancellation", func() {
+		release := make(chan struct{})
+		defer close(release)
+		handler := server.WithTimeout("stuck_tool", 20*time.Millisecond, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			<-release
+			return mcp.NewToolResultText("too late"), nil
+		})
+
+		result, err := handler(ctx, mcp.CallToolRequest{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.IsError).To(BeTrue())
+
+		var text string
+		Expect(testutil.GetTextFromResult(result, &text)).To(Succeed())
+		Expect(text).To(ContainSubstring("stuck_tool timed out after 20ms"))
+		Expect(text).To(ContainSubstring("TOOL_TIMEOUT_STUCK_TOOL"))
+	})
+
+	It("should report upstream errors caused by the deadline as a timeout", func() {
+		handler := server.WithTimeout("slow_tool", 20*time.Millisecond, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			<-ctx.Done()
+			return mcp.NewToolResultError("Failed to list pods: " + ctx.Err().Error()), nil
+		})
+
+		result, err := handler(ctx, mcp.CallToolRequest{})
+		Expect(err).NotTo(HaveOccurred())
+
+		var text string
+		Expect(testutil.GetTextFromResult(result, &text)).To(Succeed())
+		Expect(text).To(ContainSubstring("slow_tool timed out"))
+	})
+
+	It("should answer a panicking handler with a tool error", func() {
+		handler := server.WithTimeout("broken_tool", time.Second, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			panic("nil map")
+		})
+
+		result, err := handler(ctx, mcp.CallToolRequest{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.IsError).To(BeTrue())
+
+		var text string
+		Expect(testutil.GetTextFromResult(result, &text)).To(Succeed())
+		Expect(text).To(Equal("Tool broken_tool panicked: nil map"))
+	})
+
+	It("should leave handlers unbounded with a zero timeout", func() {
+		handler := server.WithTimeout("unbounded_tool", 0, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			_, hasDeadline := ctx.Deadline()
+			Expect(hasDeadline).To(BeFalse())
+			return mcp.NewToolResultText("done"), nil
+		})
+
+		result, err := handler(ctx, mcp.CallToolRequest{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.IsError).To(BeFalse())
+	})
+})
@@ -0,0 +1,58 @@
+package server_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/christianhuening/linkerd-mcp/internal/config"
+	"github.com/christianhuening/linkerd-mcp/internal/server"
+	"github.com/mark3labs/mcp-go/mcp"
+	mcpserver "github.com/mark3labs/mcp-go/server"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/dynamic/fake"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+)
+
+var _ = Describe("Tool filter", func() {
+	// registeredTools registers the tools a filter allows and returns their names
+	registeredTools := func(filter config.ToolFilter) []string {
+		mcpSrv := mcpserver.NewMCPServer("test-server", "1.0.0", mcpserver.WithToolCapabilities(true))
+		server.NewWithToolFilter(kubefake.NewSimpleClientset(), fake.NewSimpleDynamicClient(runtime.NewScheme()), filter).RegisterTools(mcpSrv)
+
+		message := []byte(`{"jsonrpc":"2.0","id":1,"method":"tools/list"}`)
+		tools := mcpSrv.HandleMessage(context.Background(), message).(mcp.JSONRPCResponse).Result.(mcp.ListToolsResult)
+		names := []string{}
+		for _, t := range tools.Tools {
+			names = append(names, t.Name)
+		}
+		return names
+	}
+
+	It("should register every tool without a filter", func() {
+		Expect(registeredTools(config.ToolFilter{})).To(ContainElements("server_info", "check_mesh_health", "analyze_connectivity"))
+	})
+
+	It("should only register the enabled tools", func() {
+		filter := config.ToolFilter{Enabled: map[string]bool{"server_info": true, "check_mesh_health": true}}
+		Expect(registeredTools(filter)).To(ConsistOf("server_info", "check_mesh_health"))
+	})
+
+	It("should leave out the disabled tools", func() {
+		all := registeredTools(config.ToolFilter{})
+		names := registeredTools(config.ToolFilter{Disabled: map[string]bool{"tap_service": true}})
+
+		Expect(all).To(ContainElement("tap_service"))
+		Expect(names).NotTo(ContainElement("tap_service"))
+		Expect(names).To(HaveLen(len(all) - 1))
+	})
+
+	It("should let the denylist win over the allowlist", func() {
+		filter := config.ToolFilter{
+			Enabled:  map[string]bool{"server_info": true, "tap_service": true},
+			Disabled: map[string]bool{"tap_service": true},
+		}
+		Expect(registeredTools(filter)).To(ConsistOf("server_info"))
+	})
+})
@@ -0,0 +1,122 @@
+package tap
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// FailedRequest is a group of sampled inbound requests to the same method and path that failed
+// the same way: with an HTTP 5xx, a gRPC error status or a stream reset
+type FailedRequest struct {
+	Method     string  `json:"method"`
+	Path       string  `json:"path"`
+	HTTPStatus uint32  `json:"httpStatus,omitempty"`
+	GRPCStatus *uint32 `json:"grpcStatus,omitempty"`
+	Reset      bool    `json:"reset,omitempty"` // the stream was reset before a response
+	Count      int     `json:"count"`
+}
+
+// SampleFailures taps a deployment for duration, DefaultDuration when zero and at most
+// MaxDuration, or until MaxEventsLimit events have arrived,
+// and returns the inbound requests that failed, most frequent first
+func (t *Tapper) SampleFailures(ctx context.Context, namespace, deployment string, duration time.Duration) ([]FailedRequest, error) {
+	if t == nil || t.config == nil {
+		return nil, fmt.Errorf("tap is not available: no Kubernetes REST config")
+	}
+	if duration <= 0 {
+		duration = DefaultDuration
+	}
+	if duration > MaxDuration {
+		duration = MaxDuration
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, duration)
+	defer cancel()
+
+	body, err := t.openStream(ctx, namespace, deployment, "", "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to tap deployment %s/%s: %w", namespace, deployment, err)
+	}
+	defer body.Close()
+
+	events, err := readEvents(ctx, body, MaxEventsLimit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tap events: %w", err)
+	}
+	return FailedRequests(events), nil
+}
+
+// FailedRequests joins the request and response events of each inbound stream and groups the
+// failed requests by method, path and failure, most frequent first. Streams whose request wasn't
+// sampled are skipped, since their path is unknown.
+func FailedRequests(events []Event) []FailedRequest {
+	type stream struct {
+		method, path string
+		httpStatus   uint32
+		grpcStatus   *uint32
+		reset        bool
+		seenRequest  bool
+	}
+	streams := map[string]*stream{}
+	order := []string{}
+	for _, event := range events {
+		if event.Direction != "inbound" || event.StreamID == "" {
+			continue
+		}
+		s, ok := streams[event.StreamID]
+		if !ok {
+			s = &stream{}
+			streams[event.StreamID] = s
+			order = append(order, event.StreamID)
+		}
+		switch event.Type {
+		case "requestInit":
+			s.method, s.path, s.seenRequest = event.Method, event.Path, true
+		case "responseInit":
+			s.httpStatus = event.HTTPStatus
+		case "responseEnd":
+			if event.GRPCStatus != nil {
+				s.grpcStatus = event.GRPCStatus
+			}
+			if event.ResetErrorCode != nil {
+				s.reset = true
+			}
+		}
+	}
+
+	index := map[string]int{}
+	failures := []FailedRequest{}
+	for _, id := range order {
+		s := streams[id]
+		failed := s.httpStatus >= 500 || (s.grpcStatus != nil && *s.grpcStatus != 0) || s.reset
+		if !s.seenRequest || !failed {
+			continue
+		}
+
+		grpcStatus := ""
+		if s.grpcStatus != nil {
+			grpcStatus = fmt.Sprint(*s.grpcStatus)
+		}
+		key := fmt.Sprintf("%s %s %d %s %t", s.method, s.path, s.httpStatus, grpcStatus, s.reset)
+		if i, ok := index[key]; ok {
+			failures[i].Count++
+			continue
+		}
+		index[key] = len(failures)
+		failures = append(failures, FailedRequest{
+			Method:     s.method,
+			Path:       s.path,
+			HTTPStatus: s.httpStatus,
+			GRPCStatus: s.grpcStatus,
+			Reset:      s.reset,
+			Count:      1,
+		})
+	}
+
+	sort.SliceStable(failures, func(i, j int) bool {
+		return failures[i].Count > failures[j].Count
+	})
+	return failures
+}
@@ -0,0 +1,65 @@
+package tap_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/christianhuening/linkerd-mcp/internal/tap"
+)
+
+var _ = Describe("FailedRequests", func() {
+	request := func(stream, method, path string) tap.Event {
+		return tap.Event{Type: "requestInit", StreamID: stream, Direction: "inbound", Method: method, Path: path}
+	}
+	response := func(stream string, status uint32) tap.Event {
+		return tap.Event{Type: "responseInit", StreamID: stream, Direction: "inbound", HTTPStatus: status}
+	}
+
+	It("should group failed requests by method, path and status, most frequent first", func() {
+		events := []tap.Event{
+			request("1", "GET", "/api/books"), response("1", 200),
+			request("2", "GET", "/api/books"), response("2", 500),
+			request("3", "POST", "/checkout"), response("3", 503),
+			request("4", "POST", "/checkout"), response("4", 503),
+			request("5", "POST", "/checkout"), response("5", 502),
+		}
+
+		failures := tap.FailedRequests(events)
+
+		Expect(failures).To(Equal([]tap.FailedRequest{
+			{Method: "POST", Path: "/checkout", HTTPStatus: 503, Count: 2},
+			{Method: "GET", Path: "/api/books", HTTPStatus: 500, Count: 1},
+			{Method: "POST", Path: "/checkout", HTTPStatus: 502, Count: 1},
+		}))
+	})
+
+	It("should count gRPC errors and resets as failures", func() {
+		unavailable, ok := uint32(14), uint32(0)
+		reset := uint32(2)
+		events := []tap.Event{
+			request("1", "POST", "/books.Books/Get"), response("1", 200),
+			{Type: "responseEnd", StreamID: "1", Direction: "inbound", GRPCStatus: &unavailable},
+			request("2", "POST", "/books.Books/Get"), response("2", 200),
+			{Type: "responseEnd", StreamID: "2", Direction: "inbound", GRPCStatus: &ok},
+			request("3", "GET", "/slow"),
+			{Type: "responseEnd", StreamID: "3", Direction: "inbound", ResetErrorCode: &reset},
+		}
+
+		failures := tap.FailedRequests(events)
+
+		Expect(failures).To(HaveLen(2))
+		Expect(*failures[0].GRPCStatus).To(Equal(uint32(14)))
+		Expect(failures[1].Path).To(Equal("/slow"))
+		Expect(failures[1].Reset).To(BeTrue())
+	})
+
+	It("should skip outbound requests and responses without a sampled request", func() {
+		events := []tap.Event{
+			{Type: "requestInit", StreamID: "1", Direction: "outbound", Method: "GET", Path: "/upstream"},
+			{Type: "responseInit", StreamID: "1", Direction: "outbound", HTTPStatus: 500},
+			response("2", 500),
+		}
+
+		Expect(tap.FailedRequests(events)).To(BeEmpty())
+	})
+})
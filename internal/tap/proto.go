@@ -0,0 +1,359 @@
+package tap
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"net"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// The tap API speaks protobuf only. Rather than depending on the whole linkerd2 module for its
+// generated types, the few messages needed here are encoded and decoded by hand following
+// viz/tap/proto/tap.proto, public.proto, net.proto and http_types.proto of linkerd2.
+
+// Field numbers of TapByResourceRequest and its nested messages
+const (
+	requestTargetField = 1 // public.ResourceSelection
+	requestMatchField  = 2 // Match
+	requestMaxRPSField = 3 // float
+
+	selectionResourceField = 1 // public.Resource
+	resourceNamespaceField = 1
+	resourceTypeField      = 2
+	resourceNameField      = 3
+
+	matchAllField   = 1 // Match.Seq
+	matchHTTPField  = 5 // Match.Http
+	seqMatchesField = 1
+
+	httpMatchMethodField = 3
+	httpMatchPathField   = 4
+)
+
+// encodeTapRequest builds a TapByResourceRequest for a workload. Method and path (a prefix) are
+// matched only when set; the proxy rate-limits events to maxRPS.
+func encodeTapRequest(namespace, resourceType, name, method, path string, maxRPS float32) []byte {
+	var resource []byte
+	resource = protowire.AppendTag(resource, resourceNamespaceField, protowire.BytesType)
+	resource = protowire.AppendString(resource, namespace)
+	resource = protowire.AppendTag(resource, resourceTypeField, protowire.BytesType)
+	resource = protowire.AppendString(resource, resourceType)
+	resource = protowire.AppendTag(resource, resourceNameField, protowire.BytesType)
+	resource = protowire.AppendString(resource, name)
+
+	var selection []byte
+	selection = protowire.AppendTag(selection, selectionResourceField, protowire.BytesType)
+	selection = protowire.AppendBytes(selection, resource)
+
+	httpMatches := [][]byte{}
+	for _, m := range []struct {
+		field protowire.Number
+		value string
+	}{{httpMatchMethodField, method}, {httpMatchPathField, path}} {
+		if m.value == "" {
+			continue
+		}
+		var httpMatch []byte
+		httpMatch = protowire.AppendTag(httpMatch, m.field, protowire.BytesType)
+		httpMatch = protowire.AppendString(httpMatch, m.value)
+
+		var match []byte
+		match = protowire.AppendTag(match, matchHTTPField, protowire.BytesType)
+		match = protowire.AppendBytes(match, httpMatch)
+		httpMatches = append(httpMatches, match)
+	}
+
+	// An empty "all" sequence matches every event
+	var seq []byte
+	for _, match := range httpMatches {
+		seq = protowire.AppendTag(seq, seqMatchesField, protowire.BytesType)
+		seq = protowire.AppendBytes(seq, match)
+	}
+	var match []byte
+	match = protowire.AppendTag(match, matchAllField, protowire.BytesType)
+	match = protowire.AppendBytes(match, seq)
+
+	var request []byte
+	request = protowire.AppendTag(request, requestTargetField, protowire.BytesType)
+	request = protowire.AppendBytes(request, selection)
+	request = protowire.AppendTag(request, requestMatchField, protowire.BytesType)
+	request = protowire.AppendBytes(request, match)
+	request = protowire.AppendTag(request, requestMaxRPSField, protowire.Fixed32Type)
+	request = protowire.AppendFixed32(request, math.Float32bits(maxRPS))
+	return request
+}
+
+// Event is a single tap event: the start of a request, the start of its response, or the end
+// of its response
+type Event struct {
+	Type            string            `json:"type"` // requestInit, responseInit or responseEnd
+	StreamID        string            `json:"streamId,omitempty"`
+	Direction       string            `json:"direction"`
+	Source          string            `json:"source,omitempty"`
+	SourceMeta      map[string]string `json:"sourceMeta,omitempty"`
+	Destination     string            `json:"destination,omitempty"`
+	DestinationMeta map[string]string `json:"destinationMeta,omitempty"`
+	RouteMeta       map[string]string `json:"routeMeta,omitempty"`
+	Method          string            `json:"method,omitempty"`
+	Scheme          string            `json:"scheme,omitempty"`
+	Authority       string            `json:"authority,omitempty"`
+	Path            string            `json:"path,omitempty"`
+	HTTPStatus      uint32            `json:"httpStatus,omitempty"`
+	LatencyMs       *float64          `json:"latencyMs,omitempty"` // time since the request started
+	ResponseBytes   uint64            `json:"responseBytes,omitempty"`
+	GRPCStatus      *uint32           `json:"grpcStatus,omitempty"`
+	ResetErrorCode  *uint32           `json:"resetErrorCode,omitempty"`
+}
+
+var (
+	proxyDirections = map[uint64]string{0: "unknown", 1: "inbound", 2: "outbound"}
+	httpMethods     = map[uint64]string{0: "GET", 1: "POST", 2: "PUT", 3: "DELETE", 4: "PATCH", 5: "OPTIONS", 6: "CONNECT", 7: "HEAD", 8: "TRACE"}
+	httpSchemes     = map[uint64]string{0: "http", 1: "https"}
+)
+
+// decodeEvent decodes a TapEvent
+func decodeEvent(data []byte) (Event, error) {
+	event := Event{Direction: proxyDirections[0]}
+	err := walk(data, func(num protowire.Number, typ protowire.Type, value []byte, scalar uint64) error {
+		switch num {
+		case 1:
+			event.Source = decodeTCPAddress(value)
+		case 2:
+			event.Destination = decodeTCPAddress(value)
+		case 3:
+			return decodeHTTPEvent(value, &event)
+		case 4:
+			event.DestinationMeta = decodeLabels(value)
+		case 5:
+			event.SourceMeta = decodeLabels(value)
+		case 6:
+			if name, ok := proxyDirections[scalar]; ok {
+				event.Direction = name
+			}
+		case 7:
+			event.RouteMeta = decodeLabels(value)
+		}
+		return nil
+	})
+	return event, err
+}
+
+// decodeHTTPEvent decodes a TapEvent.Http, whose oneof holds one of the three event kinds
+func decodeHTTPEvent(data []byte, event *Event) error {
+	return walk(data, func(num protowire.Number, typ protowire.Type, value []byte, _ uint64) error {
+		switch num {
+		case 1:
+			event.Type = "requestInit"
+			return walk(value, func(num protowire.Number, typ protowire.Type, value []byte, _ uint64) error {
+				switch num {
+				case 1:
+					event.StreamID = decodeStreamID(value)
+				case 2:
+					event.Method = decodeRegistered(value, httpMethods)
+				case 3:
+					event.Scheme = decodeRegistered(value, httpSchemes)
+				case 4:
+					event.Authority = string(value)
+				case 5:
+					event.Path = string(value)
+				}
+				return nil
+			})
+		case 2:
+			event.Type = "responseInit"
+			return walk(value, func(num protowire.Number, typ protowire.Type, value []byte, scalar uint64) error {
+				switch num {
+				case 1:
+					event.StreamID = decodeStreamID(value)
+				case 2:
+					event.LatencyMs = decodeDurationMs(value)
+				case 3:
+					event.HTTPStatus = uint32(scalar)
+				}
+				return nil
+			})
+		case 3:
+			event.Type = "responseEnd"
+			return walk(value, func(num protowire.Number, typ protowire.Type, value []byte, scalar uint64) error {
+				switch num {
+				case 1:
+					event.StreamID = decodeStreamID(value)
+				case 2:
+					event.LatencyMs = decodeDurationMs(value)
+				case 4:
+					event.ResponseBytes = scalar
+				case 5:
+					return walk(value, func(num protowire.Number, typ protowire.Type, _ []byte, scalar uint64) error {
+						code := uint32(scalar)
+						switch num {
+						case 1:
+							event.GRPCStatus = &code
+						case 2:
+							event.ResetErrorCode = &code
+						}
+						return nil
+					})
+				}
+				return nil
+			})
+		}
+		return nil
+	})
+}
+
+// decodeTCPAddress renders a net.TcpAddress as host:port
+func decodeTCPAddress(data []byte) string {
+	var ip net.IP
+	var port uint64
+	_ = walk(data, func(num protowire.Number, typ protowire.Type, value []byte, scalar uint64) error {
+		switch num {
+		case 1:
+			_ = walk(value, func(num protowire.Number, typ protowire.Type, value []byte, scalar uint64) error {
+				switch num {
+				case 1:
+					ip = make(net.IP, 4)
+					binary.BigEndian.PutUint32(ip, uint32(scalar))
+				case 2:
+					var first, last uint64
+					_ = walk(value, func(num protowire.Number, _ protowire.Type, _ []byte, scalar uint64) error {
+						if num == 1 {
+							first = scalar
+						} else if num == 2 {
+							last = scalar
+						}
+						return nil
+					})
+					ip = make(net.IP, 16)
+					binary.BigEndian.PutUint64(ip[:8], first)
+					binary.BigEndian.PutUint64(ip[8:], last)
+				}
+				return nil
+			})
+		case 2:
+			port = scalar
+		}
+		return nil
+	})
+	if ip == nil {
+		return ""
+	}
+	return net.JoinHostPort(ip.String(), fmt.Sprint(port))
+}
+
+// decodeLabels decodes the labels map of an EndpointMeta or RouteMeta
+func decodeLabels(data []byte) map[string]string {
+	labels := map[string]string{}
+	_ = walk(data, func(num protowire.Number, _ protowire.Type, entry []byte, _ uint64) error {
+		if num != 1 {
+			return nil
+		}
+		var key, value string
+		_ = walk(entry, func(num protowire.Number, _ protowire.Type, field []byte, _ uint64) error {
+			if num == 1 {
+				key = string(field)
+			} else if num == 2 {
+				value = string(field)
+			}
+			return nil
+		})
+		labels[key] = value
+		return nil
+	})
+	return labels
+}
+
+// decodeStreamID renders a TapEvent.Http.StreamId as base:stream
+func decodeStreamID(data []byte) string {
+	var base, stream uint64
+	_ = walk(data, func(num protowire.Number, _ protowire.Type, _ []byte, scalar uint64) error {
+		if num == 1 {
+			base = scalar
+		} else if num == 2 {
+			stream = scalar
+		}
+		return nil
+	})
+	return fmt.Sprintf("%d:%d", base, stream)
+}
+
+// decodeRegistered decodes an HttpMethod or Scheme, which hold either a registered enum value
+// or an unregistered string. An empty message is the zero enum value.
+func decodeRegistered(data []byte, registered map[uint64]string) string {
+	name := registered[0]
+	_ = walk(data, func(num protowire.Number, _ protowire.Type, value []byte, scalar uint64) error {
+		if num == 1 {
+			name = registered[scalar]
+		} else if num == 2 {
+			name = string(value)
+		}
+		return nil
+	})
+	return name
+}
+
+// decodeDurationMs converts a google.protobuf.Duration to milliseconds
+func decodeDurationMs(data []byte) *float64 {
+	var seconds, nanos int64
+	_ = walk(data, func(num protowire.Number, _ protowire.Type, _ []byte, scalar uint64) error {
+		if num == 1 {
+			seconds = int64(scalar)
+		} else if num == 2 {
+			nanos = int64(int32(scalar))
+		}
+		return nil
+	})
+	ms := float64(seconds)*1000 + float64(nanos)/1e6
+	return &ms
+}
+
+// decodeHTTPError extracts the message of a protohttp HttpError
+func decodeHTTPError(data []byte) string {
+	message := ""
+	_ = walk(data, func(num protowire.Number, _ protowire.Type, value []byte, _ uint64) error {
+		if num == 1 {
+			message = string(value)
+		}
+		return nil
+	})
+	return message
+}
+
+// walk calls fn for every field of a message. Length-delimited fields are passed as value, all
+// other wire types as scalar.
+func walk(data []byte, fn func(num protowire.Number, typ protowire.Type, value []byte, scalar uint64) error) error {
+	for len(data) > 0 {
+		num, typ, n := protowire.ConsumeTag(data)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		data = data[n:]
+
+		var value []byte
+		var scalar uint64
+		switch typ {
+		case protowire.VarintType:
+			scalar, n = protowire.ConsumeVarint(data)
+		case protowire.Fixed32Type:
+			var v uint32
+			v, n = protowire.ConsumeFixed32(data)
+			scalar = uint64(v)
+		case protowire.Fixed64Type:
+			scalar, n = protowire.ConsumeFixed64(data)
+		case protowire.BytesType:
+			value, n = protowire.ConsumeBytes(data)
+		default:
+			n = protowire.ConsumeFieldValue(num, typ, data)
+		}
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		data = data[n:]
+
+		if err := fn(num, typ, value, scalar); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,188 @@
+// Package tap samples live requests through the Linkerd viz tap API, like `linkerd viz tap`
+package tap
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"k8s.io/client-go/rest"
+)
+
+const (
+	// DefaultMaxEvents is the number of events sampled when no limit is given
+	DefaultMaxEvents = 20
+	// MaxEventsLimit caps the number of events returned by a single tap
+	MaxEventsLimit = 200
+	// DefaultDuration is how long the tap stream is read when no duration is given
+	DefaultDuration = 5 * time.Second
+	// MaxDuration caps how long a single tap keeps the stream open
+	MaxDuration = 30 * time.Second
+
+	// maxRPS limits the rate at which proxies report events, as done by the linkerd CLI
+	maxRPS = 100
+	// messageLengthBytes is the size of the little-endian length prefix of each streamed message
+	messageLengthBytes = 4
+	// maxMessageBytes guards against reading garbage as a huge length prefix
+	maxMessageBytes = 1 << 20
+	// errorHeader is set by the tap API when the body is an HttpError instead of events
+	errorHeader = "linkerd-error"
+)
+
+// Tapper streams tap events from the tap.linkerd.io APIService through the Kubernetes API server
+type Tapper struct {
+	config *rest.Config
+}
+
+// NewTapper creates a new tapper. The tap API is reached through the API server, so it needs the
+// REST config rather than a typed client.
+func NewTapper(config *rest.Config) *Tapper {
+	return &Tapper{config: config}
+}
+
+// TapDeployment samples live requests to and from a deployment. The stream is read until
+// maxEvents events have arrived or duration has passed, whichever comes first, and is closed
+// either way. Method and path (a prefix) filter the reported requests when set.
+func (t *Tapper) TapDeployment(ctx context.Context, namespace, deployment, method, path string, maxEvents int, duration time.Duration) (*mcp.CallToolResult, error) {
+	if t == nil || t.config == nil {
+		return mcp.NewToolResultError("tap is not available: no Kubernetes REST config"), nil
+	}
+
+	if maxEvents <= 0 {
+		maxEvents = DefaultMaxEvents
+	}
+	if maxEvents > MaxEventsLimit {
+		maxEvents = MaxEventsLimit
+	}
+	if duration <= 0 {
+		duration = DefaultDuration
+	}
+	if duration > MaxDuration {
+		duration = MaxDuration
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, duration)
+	defer cancel()
+
+	body, err := t.openStream(ctx, namespace, deployment, method, path)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to tap deployment %s/%s: %v", namespace, deployment, err)), nil
+	}
+	defer body.Close()
+
+	events, err := readEvents(ctx, body, maxEvents)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to read tap events: %v", err)), nil
+	}
+
+	result := map[string]interface{}{
+		"namespace":       namespace,
+		"deployment":      deployment,
+		"method":          method,
+		"path":            path,
+		"maxEvents":       maxEvents,
+		"durationSeconds": duration.Seconds(),
+		"totalEvents":     len(events),
+		"limitReached":    len(events) >= maxEvents,
+		"events":          events,
+	}
+
+	resultJSON, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return mcp.NewToolResultError("Failed to serialize tap events"), nil
+	}
+
+	return mcp.NewToolResultText(string(resultJSON)), nil
+}
+
+// openStream posts a TapByResourceRequest to the tap API and returns the event stream
+func (t *Tapper) openStream(ctx context.Context, namespace, deployment, method, path string) (io.ReadCloser, error) {
+	client, err := rest.HTTPClientFor(t.config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create HTTP client: %w", err)
+	}
+	host, _, err := rest.DefaultServerUrlFor(t.config)
+	if err != nil {
+		return nil, fmt.Errorf("invalid API server URL: %w", err)
+	}
+
+	url := fmt.Sprintf("%s/apis/tap.linkerd.io/v1alpha1/watch/namespaces/%s/deployments/%s/tap",
+		strings.TrimSuffix(host.String(), "/"), namespace, deployment)
+	payload := encodeTapRequest(namespace, "deployment", deployment, method, path, maxRPS)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/octet-stream")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		return nil, responseError(resp)
+	}
+	return resp.Body, nil
+}
+
+// responseError describes a failed tap request, decoding the HttpError sent by the tap API or
+// falling back to the raw body (typically a Kubernetes Status from the API server)
+func responseError(resp *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	if resp.Header.Get(errorHeader) != "" && len(body) > messageLengthBytes {
+		if message := decodeHTTPError(body[messageLengthBytes:]); message != "" {
+			return fmt.Errorf("tap API returned %s: %s", resp.Status, message)
+		}
+	}
+	return fmt.Errorf("tap API returned %s: %s", resp.Status, strings.TrimSpace(string(body)))
+}
+
+// readEvents decodes length-prefixed TapEvents until maxEvents have been read, the stream ends or
+// the context expires. Running out of time is the normal way a sample ends and is not an error.
+func readEvents(ctx context.Context, body io.Reader, maxEvents int) ([]Event, error) {
+	reader := bufio.NewReader(body)
+	events := []Event{}
+	prefix := make([]byte, messageLengthBytes)
+
+	for len(events) < maxEvents {
+		if _, err := io.ReadFull(reader, prefix); err != nil {
+			return events, streamError(ctx, err)
+		}
+		length := binary.LittleEndian.Uint32(prefix)
+		if length > maxMessageBytes {
+			return events, fmt.Errorf("tap event of %d bytes exceeds the %d byte limit", length, maxMessageBytes)
+		}
+
+		message := make([]byte, length)
+		if _, err := io.ReadFull(reader, message); err != nil {
+			return events, streamError(ctx, err)
+		}
+
+		event, err := decodeEvent(message)
+		if err != nil {
+			return events, fmt.Errorf("failed to decode tap event: %w", err)
+		}
+		events = append(events, event)
+	}
+
+	return events, nil
+}
+
+// streamError filters out the errors that mark the regular end of a stream
+func streamError(ctx context.Context, err error) error {
+	if errors.Is(err, io.EOF) || ctx.Err() != nil {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,219 @@
+package tap_test
+
+import (
+	"context"
+	"encoding/binary"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/christianhuening/linkerd-mcp/internal/tap"
+	"github.com/christianhuening/linkerd-mcp/internal/testutil"
+	"google.golang.org/protobuf/encoding/protowire"
+	"k8s.io/client-go/rest"
+)
+
+// message encodes length-delimited protobuf fields in order
+func message(fields ...func([]byte) []byte) []byte {
+	var b []byte
+	for _, field := range fields {
+		b = field(b)
+	}
+	return b
+}
+
+func bytesField(num protowire.Number, value []byte) func([]byte) []byte {
+	return func(b []byte) []byte {
+		b = protowire.AppendTag(b, num, protowire.BytesType)
+		return protowire.AppendBytes(b, value)
+	}
+}
+
+func varintField(num protowire.Number, value uint64) func([]byte) []byte {
+	return func(b []byte) []byte {
+		b = protowire.AppendTag(b, num, protowire.VarintType)
+		return protowire.AppendVarint(b, value)
+	}
+}
+
+func fixed32Field(num protowire.Number, value uint32) func([]byte) []byte {
+	return func(b []byte) []byte {
+		b = protowire.AppendTag(b, num, protowire.Fixed32Type)
+		return protowire.AppendFixed32(b, value)
+	}
+}
+
+// tcpAddress encodes a net.TcpAddress for an IPv4 address given as a number
+func tcpAddress(ipv4 uint32, port uint64) []byte {
+	return message(bytesField(1, message(fixed32Field(1, ipv4))), varintField(2, port))
+}
+
+func labels(key, value string) []byte {
+	return message(bytesField(1, message(bytesField(1, []byte(key)), bytesField(2, []byte(value)))))
+}
+
+var streamID = message(varintField(1, 0), varintField(2, 7))
+
+// requestInit is an inbound GET /api/books from 10.0.0.1 to 10.0.0.2:8080
+var requestInit = message(
+	bytesField(1, tcpAddress(0x0A000001, 43210)),
+	bytesField(2, tcpAddress(0x0A000002, 8080)),
+	bytesField(3, message(bytesField(1, message(
+		bytesField(1, streamID),
+		bytesField(2, message()), // registered method 0: GET
+		bytesField(4, []byte("books.prod.svc.cluster.local:8080")),
+		bytesField(5, []byte("/api/books")),
+	)))),
+	bytesField(5, labels("deployment", "web")),
+	varintField(6, 1),
+)
+
+// responseEnd carries a gRPC UNAVAILABLE status after 1.5ms
+var responseEnd = message(
+	bytesField(3, message(bytesField(3, message(
+		bytesField(1, streamID),
+		bytesField(2, message(varintField(2, 1500000))),
+		varintField(4, 512),
+		bytesField(5, message(varintField(1, 14))),
+	)))),
+	varintField(6, 1),
+)
+
+func frame(event []byte) []byte {
+	prefix := make([]byte, 4)
+	binary.LittleEndian.PutUint32(prefix, uint32(len(event)))
+	return append(prefix, event...)
+}
+
+var _ = Describe("Tapper", func() {
+	var (
+		ctx         context.Context
+		requestPath string
+		requestBody []byte
+	)
+
+	// tapperFor serves the given handler as the API server
+	tapperFor := func(handler func(w http.ResponseWriter)) *tap.Tapper {
+		apiServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requestPath = r.URL.Path
+			requestBody, _ = io.ReadAll(r.Body)
+			handler(w)
+		}))
+		DeferCleanup(apiServer.Close)
+		return tap.NewTapper(&rest.Config{Host: apiServer.URL})
+	}
+
+	parseResult := func(tapper *tap.Tapper, maxEvents int, duration time.Duration) map[string]interface{} {
+		result, err := tapper.TapDeployment(ctx, "prod", "web", "GET", "/api", maxEvents, duration)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.IsError).To(BeFalse())
+
+		var response map[string]interface{}
+		Expect(testutil.ParseJSONResult(result, &response)).To(Succeed())
+		return response
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+	})
+
+	It("should post the tap request for the deployment with its filters", func() {
+		tapper := tapperFor(func(w http.ResponseWriter) {})
+		parseResult(tapper, 1, time.Second)
+
+		Expect(requestPath).To(Equal("/apis/tap.linkerd.io/v1alpha1/watch/namespaces/prod/deployments/web/tap"))
+		for _, value := range []string{"prod", "deployment", "web", "GET", "/api"} {
+			Expect(string(requestBody)).To(ContainSubstring(value))
+		}
+	})
+
+	It("should decode events until the limit is reached", func() {
+		tapper := tapperFor(func(w http.ResponseWriter) {
+			_, _ = w.Write(frame(requestInit))
+			_, _ = w.Write(frame(responseEnd))
+			_, _ = w.Write(frame(requestInit))
+		})
+
+		response := parseResult(tapper, 2, 5*time.Second)
+		Expect(response["totalEvents"]).To(BeNumerically("==", 2))
+		Expect(response["limitReached"]).To(BeTrue())
+
+		events := response["events"].([]interface{})
+		request := events[0].(map[string]interface{})
+		Expect(request["type"]).To(Equal("requestInit"))
+		Expect(request["direction"]).To(Equal("inbound"))
+		Expect(request["source"]).To(Equal("10.0.0.1:43210"))
+		Expect(request["destination"]).To(Equal("10.0.0.2:8080"))
+		Expect(request["sourceMeta"]).To(HaveKeyWithValue("deployment", "web"))
+		Expect(request["method"]).To(Equal("GET"))
+		Expect(request["authority"]).To(Equal("books.prod.svc.cluster.local:8080"))
+		Expect(request["path"]).To(Equal("/api/books"))
+		Expect(request["streamId"]).To(Equal("0:7"))
+
+		end := events[1].(map[string]interface{})
+		Expect(end["type"]).To(Equal("responseEnd"))
+		Expect(end["latencyMs"]).To(BeNumerically("~", 1.5, 0.001))
+		Expect(end["responseBytes"]).To(BeNumerically("==", 512))
+		Expect(end["grpcStatus"]).To(BeNumerically("==", 14))
+	})
+
+	It("should close the stream and return the sample when the duration expires", func() {
+		unblock := make(chan struct{})
+		tapper := tapperFor(func(w http.ResponseWriter) {
+			_, _ = w.Write(frame(requestInit))
+			w.(http.Flusher).Flush()
+			<-unblock
+		})
+		DeferCleanup(func() { close(unblock) })
+
+		start := time.Now()
+		response := parseResult(tapper, 10, 200*time.Millisecond)
+		Expect(time.Since(start)).To(BeNumerically("<", 2*time.Second))
+		Expect(response["totalEvents"]).To(BeNumerically("==", 1))
+		Expect(response["limitReached"]).To(BeFalse())
+	})
+
+	It("should report errors returned by the tap API", func() {
+		tapper := tapperFor(func(w http.ResponseWriter) {
+			w.Header().Set("linkerd-error", "true")
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write(frame(message(bytesField(1, []byte("tap is disabled for deployment web")))))
+		})
+
+		result, err := tapper.TapDeployment(ctx, "prod", "web", "", "", 0, 0)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.IsError).To(BeTrue())
+
+		var text string
+		Expect(testutil.GetTextFromResult(result, &text)).To(Succeed())
+		Expect(text).To(ContainSubstring("403 Forbidden: tap is disabled for deployment web"))
+	})
+
+	It("should be unavailable without a REST config", func() {
+		result, err := tap.NewTapper(nil).TapDeployment(ctx, "prod", "web", "", "", 0, 0)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.IsError).To(BeTrue())
+
+		_, err = tap.NewTapper(nil).SampleFailures(ctx, "prod", "web", time.Second)
+		Expect(err).To(MatchError(ContainSubstring("tap is not available")))
+	})
+
+	It("should sample the failed requests of a deployment", func() {
+		tapper := tapperFor(func(w http.ResponseWriter) {
+			_, _ = w.Write(frame(requestInit))
+			_, _ = w.Write(frame(responseEnd))
+		})
+
+		failures, err := tapper.SampleFailures(ctx, "prod", "web", 200*time.Millisecond)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(requestPath).To(Equal("/apis/tap.linkerd.io/v1alpha1/watch/namespaces/prod/deployments/web/tap"))
+		Expect(failures).To(HaveLen(1))
+		Expect(failures[0].Method).To(Equal("GET"))
+		Expect(failures[0].Path).To(Equal("/api/books"))
+		Expect(*failures[0].GRPCStatus).To(Equal(uint32(14)))
+	})
+})
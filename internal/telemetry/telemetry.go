@@ -0,0 +1,63 @@
+// Package telemetry exposes Prometheus metrics about the MCP server itself: tool calls and the
+// queries it sends to the upstream Linkerd Prometheus
+package telemetry
+
+import (
+	"context"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	// ToolCalls counts tool invocations by tool name
+	ToolCalls = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "linkerd_mcp_tool_calls_total",
+		Help: "Total number of MCP tool calls.",
+	}, []string{"tool"})
+
+	// ToolErrors counts tool invocations that returned an error or an error result
+	ToolErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "linkerd_mcp_tool_errors_total",
+		Help: "Total number of MCP tool calls that failed.",
+	}, []string{"tool"})
+
+	// ToolDuration records how long each tool call took
+	ToolDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "linkerd_mcp_tool_duration_seconds",
+		Help:    "Duration of MCP tool calls.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"tool"})
+
+	// PrometheusQueryDuration records how long queries to the upstream Prometheus took, by
+	// query type (instant, range or label_values)
+	PrometheusQueryDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "linkerd_mcp_prometheus_query_duration_seconds",
+		Help:    "Duration of queries to the upstream Prometheus.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"type"})
+)
+
+// InstrumentTool wraps a tool handler so that each call is counted and timed under the tool's name
+func InstrumentTool(name string, handler server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		start := time.Now()
+		result, err := handler(ctx, request)
+
+		ToolCalls.WithLabelValues(name).Inc()
+		ToolDuration.WithLabelValues(name).Observe(time.Since(start).Seconds())
+		if err != nil || (result != nil && result.IsError) {
+			ToolErrors.WithLabelValues(name).Inc()
+		}
+
+		return result, err
+	}
+}
+
+// ObservePrometheusQuery records the duration of an upstream Prometheus query started at start
+func ObservePrometheusQuery(queryType string, start time.Time) {
+	PrometheusQueryDuration.WithLabelValues(queryType).Observe(time.Since(start).Seconds())
+}
@@ -0,0 +1,59 @@
+package telemetry_test
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/christianhuening/linkerd-mcp/internal/telemetry"
+	"github.com/mark3labs/mcp-go/mcp"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	promtestutil "github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+var _ = Describe("InstrumentTool", func() {
+	ctx := context.Background()
+
+	It("should count and time successful calls", func() {
+		handler := telemetry.InstrumentTool("test_success", func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return mcp.NewToolResultText("{}"), nil
+		})
+
+		for i := 0; i < 2; i++ {
+			result, err := handler(ctx, mcp.CallToolRequest{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.IsError).To(BeFalse())
+		}
+
+		Expect(promtestutil.ToFloat64(telemetry.ToolCalls.WithLabelValues("test_success"))).To(BeNumerically("==", 2))
+		Expect(promtestutil.ToFloat64(telemetry.ToolErrors.WithLabelValues("test_success"))).To(BeZero())
+		Expect(promtestutil.CollectAndCount(telemetry.ToolDuration)).To(BeNumerically(">=", 1))
+	})
+
+	It("should count error results and handler errors", func() {
+		failing := telemetry.InstrumentTool("test_failure", func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return mcp.NewToolResultError("boom"), nil
+		})
+		broken := telemetry.InstrumentTool("test_failure", func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return nil, errors.New("boom")
+		})
+
+		result, err := failing(ctx, mcp.CallToolRequest{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.IsError).To(BeTrue())
+		_, err = broken(ctx, mcp.CallToolRequest{})
+		Expect(err).To(HaveOccurred())
+
+		Expect(promtestutil.ToFloat64(telemetry.ToolCalls.WithLabelValues("test_failure"))).To(BeNumerically("==", 2))
+		Expect(promtestutil.ToFloat64(telemetry.ToolErrors.WithLabelValues("test_failure"))).To(BeNumerically("==", 2))
+	})
+})
+
+var _ = Describe("ObservePrometheusQuery", func() {
+	It("should record the query duration by type", func() {
+		telemetry.ObservePrometheusQuery("test", time.Now().Add(-50*time.Millisecond))
+
+		Expect(promtestutil.CollectAndCount(telemetry.PrometheusQueryDuration, "linkerd_mcp_prometheus_query_duration_seconds")).To(BeNumerically(">=", 1))
+	})
+})
@@ -1,6 +1,7 @@
 package testutil
 
 import (
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
@@ -84,6 +85,40 @@ func CreateLinkerdControlPlanePod(name, namespace, component string, phase corev
 	return CreatePod(name, namespace, "default", labels, phase, ready)
 }
 
+// CreateDeployment creates a deployment whose pod template carries the given labels and annotations
+func CreateDeployment(name, namespace string, labels, annotations map[string]string) *appsv1.Deployment {
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+		Spec: appsv1.DeploymentSpec{
+			Selector: &metav1.LabelSelector{
+				MatchLabels: labels,
+			},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels:      labels,
+					Annotations: annotations,
+				},
+			},
+		},
+	}
+}
+
+// CreateService creates a Kubernetes Service with the given pod selector
+func CreateService(name, namespace string, selector map[string]string) *corev1.Service {
+	return &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+		Spec: corev1.ServiceSpec{
+			Selector: selector,
+		},
+	}
+}
+
 // CreateServer creates a Linkerd Server CRD
 func CreateServer(name, namespace string, podLabels map[string]string, port int64) *unstructured.Unstructured {
 	// Convert podLabels to map[string]interface{} for proper deep copy support
@@ -115,10 +150,14 @@ func CreateServer(name, namespace string, podLabels map[string]string, port int6
 func CreateAuthorizationPolicy(name, namespace, targetServer string, authRefs []map[string]string) *unstructured.Unstructured {
 	requiredAuths := []interface{}{}
 	for _, ref := range authRefs {
-		requiredAuths = append(requiredAuths, map[string]interface{}{
+		authRef := map[string]interface{}{
 			"name": ref["name"],
 			"kind": ref["kind"],
-		})
+		}
+		if ref["namespace"] != "" {
+			authRef["namespace"] = ref["namespace"]
+		}
+		requiredAuths = append(requiredAuths, authRef)
 	}
 
 	policy := &unstructured.Unstructured{
@@ -204,7 +243,210 @@ func CreateNetworkAuthentication(name, namespace string, networks []map[string]i
 	return auth
 }
 
+// CreateServerAuthorization creates a legacy Linkerd ServerAuthorization CRD targeting a Server by name
+func CreateServerAuthorization(name, namespace, serverName string, identities []string, serviceAccounts []map[string]string) *unstructured.Unstructured {
+	meshTLS := map[string]interface{}{}
+
+	if len(identities) > 0 {
+		identityList := []interface{}{}
+		for _, id := range identities {
+			identityList = append(identityList, id)
+		}
+		meshTLS["identities"] = identityList
+	}
+
+	if len(serviceAccounts) > 0 {
+		saList := []interface{}{}
+		for _, sa := range serviceAccounts {
+			saMap := map[string]interface{}{
+				"name": sa["name"],
+			}
+			if ns, ok := sa["namespace"]; ok {
+				saMap["namespace"] = ns
+			}
+			saList = append(saList, saMap)
+		}
+		meshTLS["serviceAccounts"] = saList
+	}
+
+	serverAuth := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "policy.linkerd.io/v1beta1",
+			"kind":       "ServerAuthorization",
+			"metadata": map[string]interface{}{
+				"name":      name,
+				"namespace": namespace,
+			},
+			"spec": map[string]interface{}{
+				"server": map[string]interface{}{
+					"name": serverName,
+				},
+				"client": map[string]interface{}{
+					"meshTLS": meshTLS,
+				},
+			},
+		},
+	}
+	return serverAuth
+}
+
 // ToRuntimeObject converts unstructured to runtime.Object
 func ToRuntimeObject(u *unstructured.Unstructured) runtime.Object {
 	return u
 }
+
+// CreateServiceProfile creates a Linkerd ServiceProfile CRD. Each route is given as its spec
+// (name, condition, isRetryable, timeout); retryBudget may be nil.
+func CreateServiceProfile(name, namespace string, routes []map[string]interface{}, retryBudget map[string]interface{}) *unstructured.Unstructured {
+	routeList := []interface{}{}
+	for _, route := range routes {
+		routeList = append(routeList, route)
+	}
+
+	spec := map[string]interface{}{
+		"routes": routeList,
+	}
+	if retryBudget != nil {
+		spec["retryBudget"] = retryBudget
+	}
+
+	return &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "linkerd.io/v1alpha2",
+			"kind":       "ServiceProfile",
+			"metadata": map[string]interface{}{
+				"name":      name,
+				"namespace": namespace,
+			},
+			"spec": spec,
+		},
+	}
+}
+
+// CreateServerHTTPRoute creates a Linkerd HTTPRoute CRD attached to a parent Server, as used by
+// route-based authorization. The matches (path, method, headers) form a single rule.
+func CreateServerHTTPRoute(name, namespace, parentServer string, matches []map[string]interface{}) *unstructured.Unstructured {
+	matchList := []interface{}{}
+	for _, match := range matches {
+		matchList = append(matchList, match)
+	}
+
+	return &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "policy.linkerd.io/v1alpha1",
+			"kind":       "HTTPRoute",
+			"metadata": map[string]interface{}{
+				"name":      name,
+				"namespace": namespace,
+			},
+			"spec": map[string]interface{}{
+				"parentRefs": []interface{}{
+					map[string]interface{}{
+						"group": "policy.linkerd.io",
+						"kind":  "Server",
+						"name":  parentServer,
+					},
+				},
+				"rules": []interface{}{
+					map[string]interface{}{
+						"matches": matchList,
+					},
+				},
+			},
+		},
+	}
+}
+
+// CreateHTTPRoute creates a Linkerd HTTPRoute CRD attached to a parent Service. Each rule is
+// given as its list of backendRefs (name, namespace, port, weight).
+func CreateHTTPRoute(name, namespace, parentService string, rules [][]map[string]interface{}) *unstructured.Unstructured {
+	ruleList := []interface{}{}
+	for _, backendRefs := range rules {
+		refs := []interface{}{}
+		for _, ref := range backendRefs {
+			refs = append(refs, ref)
+		}
+		ruleList = append(ruleList, map[string]interface{}{
+			"backendRefs": refs,
+		})
+	}
+
+	return &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "policy.linkerd.io/v1alpha1",
+			"kind":       "HTTPRoute",
+			"metadata": map[string]interface{}{
+				"name":      name,
+				"namespace": namespace,
+			},
+			"spec": map[string]interface{}{
+				"parentRefs": []interface{}{
+					map[string]interface{}{
+						"group": "core",
+						"kind":  "Service",
+						"name":  parentService,
+					},
+				},
+				"rules": ruleList,
+			},
+		},
+	}
+}
+
+// CreateEgressNetwork creates a Linkerd EgressNetwork CRD. Without networks it covers every
+// address outside the cluster.
+func CreateEgressNetwork(name, namespace, trafficPolicy string, networks []map[string]interface{}) *unstructured.Unstructured {
+	spec := map[string]interface{}{
+		"trafficPolicy": trafficPolicy,
+	}
+	if len(networks) > 0 {
+		networkList := []interface{}{}
+		for _, network := range networks {
+			networkList = append(networkList, network)
+		}
+		spec["networks"] = networkList
+	}
+
+	return &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "policy.linkerd.io/v1alpha1",
+			"kind":       "EgressNetwork",
+			"metadata": map[string]interface{}{
+				"name":      name,
+				"namespace": namespace,
+			},
+			"spec": spec,
+		},
+	}
+}
+
+// CreateTLSRoute creates a Gateway API TLSRoute attached to an EgressNetwork on a port, matching
+// the given SNI hostnames
+func CreateTLSRoute(name, namespace, egressNetwork string, port int64, hostnames []string) *unstructured.Unstructured {
+	hostnameList := []interface{}{}
+	for _, hostname := range hostnames {
+		hostnameList = append(hostnameList, hostname)
+	}
+
+	return &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "gateway.networking.k8s.io/v1alpha2",
+			"kind":       "TLSRoute",
+			"metadata": map[string]interface{}{
+				"name":      name,
+				"namespace": namespace,
+			},
+			"spec": map[string]interface{}{
+				"parentRefs": []interface{}{
+					map[string]interface{}{
+						"group": "policy.linkerd.io",
+						"kind":  "EgressNetwork",
+						"name":  egressNetwork,
+						"port":  port,
+					},
+				},
+				"hostnames": hostnameList,
+			},
+		},
+	}
+}
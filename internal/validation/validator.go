@@ -3,83 +3,158 @@ package validation
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 
+	"github.com/christianhuening/linkerd-mcp/internal/kube"
 	"github.com/christianhuening/linkerd-mcp/internal/validation/validators"
 	"github.com/mark3labs/mcp-go/mcp"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/yaml"
 )
 
 // ConfigValidator orchestrates validation of Linkerd configuration
 type ConfigValidator struct {
-	serverValidator     *validators.ServerValidator
-	authPolicyValidator *validators.AuthPolicyValidator
-	meshTLSValidator    *validators.MeshTLSValidator
-	proxyValidator      *validators.ProxyValidator
+	serverValidator         *validators.ServerValidator
+	authPolicyValidator     *validators.AuthPolicyValidator
+	meshTLSValidator        *validators.MeshTLSValidator
+	networkAuthValidator    *validators.NetworkAuthValidator
+	egressNetworkValidator  *validators.EgressNetworkValidator
+	proxyValidator          *validators.ProxyValidator
+	serviceProfileValidator *validators.ServiceProfileValidator
+	namespaceFilter         *kube.NamespaceFilter
 }
 
 // NewConfigValidator creates a new configuration validator
 func NewConfigValidator(clientset kubernetes.Interface, dynamicClient dynamic.Interface) *ConfigValidator {
 	return &ConfigValidator{
-		serverValidator:     validators.NewServerValidator(clientset, dynamicClient),
-		authPolicyValidator: validators.NewAuthPolicyValidator(dynamicClient),
-		meshTLSValidator:    validators.NewMeshTLSValidator(clientset, dynamicClient),
-		proxyValidator:      validators.NewProxyValidator(clientset),
+		serverValidator:         validators.NewServerValidator(clientset, dynamicClient),
+		authPolicyValidator:     validators.NewAuthPolicyValidator(dynamicClient),
+		meshTLSValidator:        validators.NewMeshTLSValidator(clientset, dynamicClient),
+		networkAuthValidator:    validators.NewNetworkAuthValidator(dynamicClient),
+		egressNetworkValidator:  validators.NewEgressNetworkValidator(dynamicClient),
+		proxyValidator:          validators.NewProxyValidator(clientset),
+		serviceProfileValidator: validators.NewServiceProfileValidator(dynamicClient),
 	}
 }
 
-// ValidateConfig validates Linkerd configuration based on parameters
-func (cv *ConfigValidator) ValidateConfig(ctx context.Context, namespace, resourceType, resourceName string, includeWarnings bool) (*mcp.CallToolResult, error) {
+// SetNamespaceFilter restricts the namespaces covered when validating the whole cluster
+func (cv *ConfigValidator) SetNamespaceFilter(filter *kube.NamespaceFilter) {
+	cv.namespaceFilter = filter
+	cv.proxyValidator.SetNamespaceFilter(filter)
+}
+
+// ValidateConfig validates Linkerd configuration based on parameters. Issues below minSeverity
+// are left out; when minSeverity is set, any remaining issue fails the report, otherwise only
+// errors do. A positive maxResults caps the results returned, most severe first, without
+// changing the counts. The report is returned as JSON, or as a Markdown summary when format is
+// "markdown".
+func (cv *ConfigValidator) ValidateConfig(ctx context.Context, namespace, resourceType, resourceName, minSeverity, format string, maxResults int) (*mcp.CallToolResult, error) {
+	if format != "" && format != "json" && format != "markdown" {
+		return mcp.NewToolResultError("Invalid format. Must be one of: json, markdown"), nil
+	}
+	if maxResults < 0 {
+		return mcp.NewToolResultError("Invalid max_results. Must be a positive number"), nil
+	}
+
+	threshold := validators.SeverityInfo
+	failOn := validators.SeverityError
+	if minSeverity != "" {
+		severity, err := validators.ParseSeverity(minSeverity)
+		if err != nil {
+			return mcp.NewToolResultError("Invalid min_severity. Must be one of: info, warning, error"), nil
+		}
+		threshold = severity
+		failOn = severity
+	}
+
 	report := validators.ClusterValidationReport{
 		Results: []validators.ValidationResult{},
 		Summary: validators.ValidationSummary{},
+		FailOn:  failOn,
 	}
+	report.Start()
 
 	// Determine which validators to run
 	switch resourceType {
 	case "server":
 		results := cv.serverValidator.ValidateAll(ctx, namespace)
-		cv.addResultsToReport(&report, results, resourceName, includeWarnings)
+		cv.addResultsToReport(&report, results, namespace, resourceName, threshold)
 	case "authpolicy", "authorizationpolicy":
 		results := cv.authPolicyValidator.ValidateAll(ctx, namespace)
-		cv.addResultsToReport(&report, results, resourceName, includeWarnings)
+		cv.addResultsToReport(&report, results, namespace, resourceName, threshold)
 	case "meshtls", "meshtlsauthentication":
 		results := cv.meshTLSValidator.ValidateAll(ctx, namespace)
-		cv.addResultsToReport(&report, results, resourceName, includeWarnings)
+		cv.addResultsToReport(&report, results, namespace, resourceName, threshold)
+	case "networkauth", "networkauthentication":
+		results := cv.networkAuthValidator.ValidateAll(ctx, namespace)
+		cv.addResultsToReport(&report, results, namespace, resourceName, threshold)
+	case "serviceprofile":
+		results := cv.serviceProfileValidator.ValidateAll(ctx, namespace)
+		cv.addResultsToReport(&report, results, namespace, resourceName, threshold)
+	case "egress", "egressnetwork":
+		results := cv.egressNetworkValidator.ValidateAll(ctx, namespace)
+		cv.addResultsToReport(&report, results, namespace, resourceName, threshold)
 	case "proxy", "namespace":
 		// Validate proxy configuration on namespaces
 		if namespace == "" {
 			results := cv.proxyValidator.ValidateAllNamespaces(ctx)
-			cv.addResultsToReport(&report, results, resourceName, includeWarnings)
+			cv.addResultsToReport(&report, results, namespace, resourceName, threshold)
 		} else {
 			// Validate specific namespace and its pods
 			results := cv.proxyValidator.ValidateAllPodsInNamespace(ctx, namespace)
-			cv.addResultsToReport(&report, results, resourceName, includeWarnings)
+			cv.addResultsToReport(&report, results, namespace, resourceName, threshold)
 		}
+		// Validate failure accrual on services
+		results := cv.proxyValidator.ValidateAllServices(ctx, namespace)
+		cv.addResultsToReport(&report, results, namespace, resourceName, threshold)
 	case "all", "":
 		// Validate all resource types
 		serverResults := cv.serverValidator.ValidateAll(ctx, namespace)
-		cv.addResultsToReport(&report, serverResults, resourceName, includeWarnings)
+		cv.addResultsToReport(&report, serverResults, namespace, resourceName, threshold)
 
 		authPolicyResults := cv.authPolicyValidator.ValidateAll(ctx, namespace)
-		cv.addResultsToReport(&report, authPolicyResults, resourceName, includeWarnings)
+		cv.addResultsToReport(&report, authPolicyResults, namespace, resourceName, threshold)
 
 		meshTLSResults := cv.meshTLSValidator.ValidateAll(ctx, namespace)
-		cv.addResultsToReport(&report, meshTLSResults, resourceName, includeWarnings)
+		cv.addResultsToReport(&report, meshTLSResults, namespace, resourceName, threshold)
+
+		networkAuthResults := cv.networkAuthValidator.ValidateAll(ctx, namespace)
+		cv.addResultsToReport(&report, networkAuthResults, namespace, resourceName, threshold)
+
+		serviceProfileResults := cv.serviceProfileValidator.ValidateAll(ctx, namespace)
+		cv.addResultsToReport(&report, serviceProfileResults, namespace, resourceName, threshold)
+
+		// EgressNetworks need Linkerd 2.17 or later, so their CRD missing isn't an error here
+		egressResults := []validators.ValidationResult{}
+		for _, result := range cv.egressNetworkValidator.ValidateAll(ctx, namespace) {
+			if !result.MissingCRD() {
+				egressResults = append(egressResults, result)
+			}
+		}
+		cv.addResultsToReport(&report, egressResults, namespace, resourceName, threshold)
 
 		// Validate proxy configuration
 		if namespace == "" {
 			proxyResults := cv.proxyValidator.ValidateAllNamespaces(ctx)
-			cv.addResultsToReport(&report, proxyResults, resourceName, includeWarnings)
+			cv.addResultsToReport(&report, proxyResults, namespace, resourceName, threshold)
 		} else {
 			proxyResults := cv.proxyValidator.ValidateAllPodsInNamespace(ctx, namespace)
-			cv.addResultsToReport(&report, proxyResults, resourceName, includeWarnings)
+			cv.addResultsToReport(&report, proxyResults, namespace, resourceName, threshold)
 		}
+		serviceResults := cv.proxyValidator.ValidateAllServices(ctx, namespace)
+		cv.addResultsToReport(&report, serviceResults, namespace, resourceName, threshold)
 	default:
-		return mcp.NewToolResultError("Invalid resource_type. Must be one of: server, authpolicy, meshtls, proxy, all"), nil
+		return mcp.NewToolResultError("Invalid resource_type. Must be one of: server, authpolicy, meshtls, networkauth, serviceprofile, egress, proxy, all"), nil
 	}
 
 	report.Finalize()
+	report.Truncate(maxResults)
+
+	if format == "markdown" {
+		return mcp.NewToolResultText(report.Markdown()), nil
+	}
 
 	// Convert to JSON
 	resultJSON, err := json.MarshalIndent(report, "", "  ")
@@ -90,23 +165,110 @@ func (cv *ConfigValidator) ValidateConfig(ctx context.Context, namespace, resour
 	return mcp.NewToolResultText(string(resultJSON)), nil
 }
 
-func (cv *ConfigValidator) addResultsToReport(report *validators.ClusterValidationReport, results []validators.ValidationResult, resourceName string, includeWarnings bool) {
+// ValidateResource validates a single resource given as a YAML or JSON document, before it is
+// applied. The resource itself doesn't need to exist, but references to other resources (such as
+// the target Server of an AuthorizationPolicy) are checked against the live cluster.
+func (cv *ConfigValidator) ValidateResource(ctx context.Context, document string) (*mcp.CallToolResult, error) {
+	resource, err := parseResource(document)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to parse resource: %v", err)), nil
+	}
+
+	var result validators.ValidationResult
+	switch resource.GetKind() {
+	case "Server":
+		result = cv.serverValidator.Validate(ctx, resource)
+	case "AuthorizationPolicy":
+		result = cv.authPolicyValidator.Validate(ctx, resource)
+	case "MeshTLSAuthentication":
+		result = cv.meshTLSValidator.Validate(ctx, resource)
+	case "NetworkAuthentication":
+		result = cv.networkAuthValidator.Validate(ctx, resource)
+	case "ServiceProfile":
+		result = cv.serviceProfileValidator.Validate(ctx, resource)
+	case "EgressNetwork":
+		result = cv.egressNetworkValidator.Validate(ctx, resource)
+	default:
+		return mcp.NewToolResultError(fmt.Sprintf("Unsupported kind '%s'. Must be one of: Server, AuthorizationPolicy, MeshTLSAuthentication, NetworkAuthentication, ServiceProfile, EgressNetwork", resource.GetKind())), nil
+	}
+
+	resultJSON, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return mcp.NewToolResultError("Failed to serialize validation result"), nil
+	}
+
+	return mcp.NewToolResultText(string(resultJSON)), nil
+}
+
+// parseResource decodes a YAML or JSON document into a resource. Like kubectl, resources
+// without a namespace are placed in the default namespace.
+func parseResource(document string) (*unstructured.Unstructured, error) {
+	data, err := yaml.YAMLToJSON([]byte(document))
+	if err != nil {
+		return nil, err
+	}
+
+	resource := &unstructured.Unstructured{}
+	if err := resource.UnmarshalJSON(data); err != nil {
+		return nil, err
+	}
+	if resource.GetNamespace() == "" {
+		resource.SetNamespace("default")
+	}
+
+	return resource, nil
+}
+
+// FindMissingServiceAccounts reports service accounts referenced by authentication resources
+// that do not exist in the cluster
+func (cv *ConfigValidator) FindMissingServiceAccounts(ctx context.Context, namespace string) (*mcp.CallToolResult, error) {
+	totalReferenced, missing, err := cv.meshTLSValidator.FindMissingServiceAccounts(ctx, namespace)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	result := map[string]interface{}{
+		"namespace":                 namespace,
+		"referencedServiceAccounts": totalReferenced,
+		"missingServiceAccounts":    missing,
+		"totalMissing":              len(missing),
+	}
+
+	resultJSON, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return mcp.NewToolResultError("Failed to serialize missing service accounts"), nil
+	}
+
+	return mcp.NewToolResultText(string(resultJSON)), nil
+}
+
+func (cv *ConfigValidator) addResultsToReport(report *validators.ClusterValidationReport, results []validators.ValidationResult, namespace, resourceName string, minSeverity validators.Severity) {
 	for _, result := range results {
+		// Diagnostics aren't about a resource and are never filtered
+		if result.Diagnostic {
+			report.AddResult(result)
+			continue
+		}
+
 		// Filter by resource name if specified
 		if resourceName != "" && result.Name != resourceName {
 			continue
 		}
 
-		// Filter issues if warnings should not be included
-		if !includeWarnings {
+		// Cluster-wide validation leaves out the namespaces the namespace filter doesn't cover
+		if namespace == "" && !cv.namespaceFilter.Allows(result.Namespace) {
+			continue
+		}
+
+		// Filter out issues below the minimum severity
+		if minSeverity != validators.SeverityInfo {
 			filteredIssues := []validators.Issue{}
 			for _, issue := range result.Issues {
-				if issue.Severity == validators.SeverityError {
+				if issue.Severity.AtLeast(minSeverity) {
 					filteredIssues = append(filteredIssues, issue)
 				}
 			}
 			result.Issues = filteredIssues
-			result.Valid = len(filteredIssues) == 0
 		}
 
 		report.AddResult(result)
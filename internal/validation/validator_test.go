@@ -0,0 +1,359 @@
+package validation_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/christianhuening/linkerd-mcp/internal/testutil"
+	"github.com/christianhuening/linkerd-mcp/internal/validation"
+	"github.com/christianhuening/linkerd-mcp/internal/validation/validators"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/fake"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+var _ = Describe("ConfigValidator", func() {
+	var (
+		ctx           context.Context
+		validator     *validation.ConfigValidator
+		dynamicClient *fake.FakeDynamicClient
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+
+		scheme := runtime.NewScheme()
+		gvrToListKind := map[schema.GroupVersionResource]string{
+			{Group: "policy.linkerd.io", Version: "v1beta3", Resource: "servers"}:                 "ServerList",
+			{Group: "policy.linkerd.io", Version: "v1alpha1", Resource: "authorizationpolicies"}:  "AuthorizationPolicyList",
+			{Group: "policy.linkerd.io", Version: "v1alpha1", Resource: "meshtlsauthentications"}: "MeshTLSAuthenticationList",
+			{Group: "policy.linkerd.io", Version: "v1alpha1", Resource: "networkauthentications"}: "NetworkAuthenticationList",
+			{Group: "linkerd.io", Version: "v1alpha2", Resource: "serviceprofiles"}:               "ServiceProfileList",
+			{Group: "policy.linkerd.io", Version: "v1alpha1", Resource: "egressnetworks"}:         "EgressNetworkList",
+			{Group: "gateway.networking.k8s.io", Version: "v1alpha2", Resource: "tlsroutes"}:      "TLSRouteList",
+		}
+
+		kubeClient := kubefake.NewSimpleClientset()
+		dynamicClient = fake.NewSimpleDynamicClientWithCustomListKinds(scheme, gvrToListKind)
+		validator = validation.NewConfigValidator(kubeClient, dynamicClient)
+	})
+
+	Describe("ValidateConfig", func() {
+		It("should record start, completion and duration of the report", func() {
+			result, err := validator.ValidateConfig(ctx, "prod", "all", "", "", "", 0)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.IsError).To(BeFalse())
+
+			var report validators.ClusterValidationReport
+			err = testutil.ParseJSONResult(result, &report)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(report.StartedAt.IsZero()).To(BeFalse())
+			Expect(report.CompletedAt.IsZero()).To(BeFalse())
+			Expect(report.CompletedAt.Before(report.StartedAt)).To(BeFalse())
+			Expect(report.DurationMs).To(BeNumerically(">", 0))
+		})
+
+		It("should reject an invalid resource type", func() {
+			result, err := validator.ValidateConfig(ctx, "prod", "bogus", "", "", "", 0)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.IsError).To(BeTrue())
+		})
+
+		It("should reject an invalid format", func() {
+			result, err := validator.ValidateConfig(ctx, "prod", "all", "", "", "xml", 0)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.IsError).To(BeTrue())
+		})
+
+		Context("with a minimum severity", func() {
+			var serversGVR = schema.GroupVersionResource{Group: "policy.linkerd.io", Version: "v1beta3", Resource: "servers"}
+
+			parseReport := func(minSeverity string) validators.ClusterValidationReport {
+				result, err := validator.ValidateConfig(ctx, "prod", "server", "", minSeverity, "", 0)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result.IsError).To(BeFalse())
+
+				var report validators.ClusterValidationReport
+				Expect(testutil.ParseJSONResult(result, &report)).To(Succeed())
+				return report
+			}
+
+			severities := func(report validators.ClusterValidationReport) []validators.Severity {
+				found := []validators.Severity{}
+				for _, result := range report.Results {
+					for _, issue := range result.Issues {
+						found = append(found, issue.Severity)
+					}
+				}
+				return found
+			}
+
+			BeforeEach(func() {
+				// Valid, but without proxyProtocol: only a warning
+				server := testutil.CreateServer("api-server", "prod", map[string]string{"app": "api"}, 8080)
+				_, err := dynamicClient.Resource(serversGVR).Namespace("prod").Create(ctx, server, metav1.CreateOptions{})
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("should pass on warnings unless they are the threshold", func() {
+				report := parseReport("")
+				Expect(severities(report)).To(ContainElement(validators.SeverityWarning))
+				Expect(report.FailOn).To(Equal(validators.SeverityError))
+				Expect(report.Passed).To(BeTrue())
+
+				report = parseReport("warning")
+				Expect(severities(report)).To(ContainElement(validators.SeverityWarning))
+				Expect(severities(report)).NotTo(ContainElement(validators.SeverityInfo))
+				Expect(report.FailOn).To(Equal(validators.SeverityWarning))
+				Expect(report.Passed).To(BeFalse())
+			})
+
+			It("should drop issues below the threshold", func() {
+				report := parseReport("error")
+				Expect(severities(report)).To(BeEmpty())
+				Expect(report.Summary.Warnings).To(BeZero())
+				Expect(report.Passed).To(BeTrue())
+			})
+
+			It("should fail on errors", func() {
+				server := testutil.CreateServer("broken-server", "prod", map[string]string{"app": "api"}, 70000)
+				_, err := dynamicClient.Resource(serversGVR).Namespace("prod").Create(ctx, server, metav1.CreateOptions{})
+				Expect(err).NotTo(HaveOccurred())
+
+				report := parseReport("error")
+				Expect(severities(report)).To(ContainElement(validators.SeverityError))
+				Expect(report.Passed).To(BeFalse())
+			})
+
+			It("should reject an invalid severity", func() {
+				result, err := validator.ValidateConfig(ctx, "prod", "server", "", "critical", "", 0)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result.IsError).To(BeTrue())
+			})
+		})
+
+		It("should keep the most severe results within max_results", func() {
+			serversGVR := schema.GroupVersionResource{Group: "policy.linkerd.io", Version: "v1beta3", Resource: "servers"}
+			for _, server := range []*unstructured.Unstructured{
+				testutil.CreateServer("api-server", "prod", map[string]string{"app": "api"}, 8080),
+				testutil.CreateServer("broken-server", "prod", map[string]string{"app": "api"}, 70000),
+			} {
+				_, err := dynamicClient.Resource(serversGVR).Namespace("prod").Create(ctx, server, metav1.CreateOptions{})
+				Expect(err).NotTo(HaveOccurred())
+			}
+
+			result, err := validator.ValidateConfig(ctx, "prod", "server", "", "", "", 1)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.IsError).To(BeFalse())
+
+			var report validators.ClusterValidationReport
+			Expect(testutil.ParseJSONResult(result, &report)).To(Succeed())
+
+			Expect(report.Truncated).To(BeTrue())
+			Expect(report.OmittedResults).To(Equal(1))
+			Expect(report.TotalResources).To(Equal(2))
+			Expect(report.Results).To(HaveLen(1))
+			Expect(report.Results[0].Name).To(Equal("broken-server"))
+		})
+
+		It("should fail instead of passing when a CRD isn't installed", func() {
+			dynamicClient.PrependReactor("list", "authorizationpolicies", func(action k8stesting.Action) (bool, runtime.Object, error) {
+				return true, nil, apierrors.NewNotFound(schema.GroupResource{Group: "policy.linkerd.io", Resource: "authorizationpolicies"}, "")
+			})
+
+			result, err := validator.ValidateConfig(ctx, "", "all", "api-server", "", "", 0)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.IsError).To(BeFalse())
+
+			var report validators.ClusterValidationReport
+			Expect(testutil.ParseJSONResult(result, &report)).To(Succeed())
+
+			Expect(report.Passed).To(BeFalse())
+			Expect(report.TotalResources).To(Equal(0))
+			Expect(report.Summary.Diagnostics).To(Equal(1))
+			Expect(report.Summary.Errors).To(Equal(1))
+			Expect(report.Results).To(ConsistOf(And(
+				HaveField("Diagnostic", true),
+				HaveField("ResourceType", "AuthorizationPolicy"),
+			)))
+		})
+
+		It("should validate EgressNetworks as the egress type", func() {
+			_, err := dynamicClient.Resource(schema.GroupVersionResource{Group: "policy.linkerd.io", Version: "v1alpha1", Resource: "egressnetworks"}).
+				Namespace("prod").Create(ctx, testutil.CreateEgressNetwork("external", "prod", "Audit", nil), metav1.CreateOptions{})
+			Expect(err).NotTo(HaveOccurred())
+
+			result, err := validator.ValidateConfig(ctx, "prod", "egress", "", "", "", 0)
+			Expect(err).NotTo(HaveOccurred())
+
+			var report validators.ClusterValidationReport
+			Expect(testutil.ParseJSONResult(result, &report)).To(Succeed())
+
+			Expect(report.Passed).To(BeFalse())
+			Expect(report.Results).To(ConsistOf(HaveField("ResourceType", "EgressNetwork")))
+		})
+
+		It("should not fail the whole cluster on a missing EgressNetwork CRD", func() {
+			dynamicClient.PrependReactor("list", "egressnetworks", func(action k8stesting.Action) (bool, runtime.Object, error) {
+				return true, nil, apierrors.NewNotFound(schema.GroupResource{Group: "policy.linkerd.io", Resource: "egressnetworks"}, "")
+			})
+
+			result, err := validator.ValidateConfig(ctx, "prod", "all", "", "", "", 0)
+			Expect(err).NotTo(HaveOccurred())
+
+			var report validators.ClusterValidationReport
+			Expect(testutil.ParseJSONResult(result, &report)).To(Succeed())
+			Expect(report.Passed).To(BeTrue())
+			Expect(report.Summary.Diagnostics).To(Equal(0))
+
+			result, err = validator.ValidateConfig(ctx, "prod", "egress", "", "", "", 0)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(testutil.ParseJSONResult(result, &report)).To(Succeed())
+			Expect(report.Passed).To(BeFalse())
+			Expect(report.Summary.Diagnostics).To(Equal(1))
+		})
+
+		It("should reject a negative max_results", func() {
+			result, err := validator.ValidateConfig(ctx, "prod", "all", "", "", "", -1)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.IsError).To(BeTrue())
+		})
+
+		It("should render the report as Markdown", func() {
+			server := testutil.CreateServer("api-server", "prod", map[string]string{"app": "api"}, 70000)
+			_, err := dynamicClient.Resource(schema.GroupVersionResource{Group: "policy.linkerd.io", Version: "v1beta3", Resource: "servers"}).
+				Namespace("prod").Create(ctx, server, metav1.CreateOptions{})
+			Expect(err).NotTo(HaveOccurred())
+
+			result, err := validator.ValidateConfig(ctx, "prod", "server", "", "", "markdown", 0)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.IsError).To(BeFalse())
+
+			var markdown string
+			err = testutil.GetTextFromResult(result, &markdown)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(markdown).To(HavePrefix("# Linkerd Validation Report"))
+			Expect(markdown).To(ContainSubstring("- **Result:** failed (fails on error or above)"))
+			Expect(markdown).To(ContainSubstring("- **Resources:** 1 total, 0 valid, 1 invalid"))
+			Expect(markdown).To(ContainSubstring("| Type | Namespace | Name | Valid | Errors | Warnings | Info |"))
+			Expect(markdown).To(MatchRegexp(`\| Server \| prod \| api-server \| no \| [1-9]`))
+			Expect(markdown).To(ContainSubstring("## Errors"))
+			Expect(markdown).To(ContainSubstring("- **Server prod/api-server** `LNKD-"))
+		})
+	})
+
+	Describe("ValidateResource", func() {
+		parseResult := func(document string) validators.ValidationResult {
+			result, err := validator.ValidateResource(ctx, document)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.IsError).To(BeFalse())
+
+			var validationResult validators.ValidationResult
+			err = testutil.ParseJSONResult(result, &validationResult)
+			Expect(err).NotTo(HaveOccurred())
+			return validationResult
+		}
+
+		policyYAML := `
+apiVersion: policy.linkerd.io/v1alpha1
+kind: AuthorizationPolicy
+metadata:
+  name: api-clients
+  namespace: prod
+spec:
+  targetRef:
+    group: policy.linkerd.io
+    kind: Server
+    name: api-server
+  requiredAuthenticationRefs: []
+`
+
+		It("should validate a MeshTLSAuthentication given as YAML", func() {
+			result := parseResult(`
+apiVersion: policy.linkerd.io/v1alpha1
+kind: MeshTLSAuthentication
+metadata:
+  name: frontend-clients
+  namespace: prod
+spec:
+  identities:
+  - frontend-sa.prod.serviceaccount.identity.linkerd.cluster.local
+`)
+
+			Expect(result.ResourceType).To(Equal("MeshTLSAuthentication"))
+			Expect(result.Name).To(Equal("frontend-clients"))
+			Expect(result.Namespace).To(Equal("prod"))
+			Expect(result.Valid).To(BeTrue())
+		})
+
+		It("should validate a NetworkAuthentication", func() {
+			result := parseResult(`
+apiVersion: policy.linkerd.io/v1alpha1
+kind: NetworkAuthentication
+metadata:
+  name: cluster-network
+  namespace: prod
+spec:
+  networks:
+  - cidr: 10.0.0.0/16
+    except:
+    - 192.168.0.0/24
+`)
+
+			Expect(result.ResourceType).To(Equal("NetworkAuthentication"))
+			Expect(result.Valid).To(BeFalse())
+			Expect(result.Issues[0].Code).To(Equal("LNKD-033"))
+		})
+
+		It("should validate a resource given as JSON and default its namespace", func() {
+			result := parseResult(`{"apiVersion": "policy.linkerd.io/v1beta3", "kind": "Server", "metadata": {"name": "api-server"}, "spec": {"podSelector": {"matchLabels": {"app": "api"}}, "port": 70000}}`)
+
+			Expect(result.ResourceType).To(Equal("Server"))
+			Expect(result.Namespace).To(Equal("default"))
+			Expect(result.Valid).To(BeFalse())
+		})
+
+		It("should check references against the live cluster", func() {
+			result := parseResult(policyYAML)
+			codes := []string{}
+			for _, issue := range result.Issues {
+				codes = append(codes, issue.Code)
+			}
+			Expect(codes).To(ContainElement("LNKD-013"))
+
+			_, err := dynamicClient.Resource(schema.GroupVersionResource{Group: "policy.linkerd.io", Version: "v1beta3", Resource: "servers"}).
+				Namespace("prod").Create(ctx, testutil.CreateServer("api-server", "prod", map[string]string{"app": "api"}, 8080), metav1.CreateOptions{})
+			Expect(err).NotTo(HaveOccurred())
+
+			result = parseResult(policyYAML)
+			for _, issue := range result.Issues {
+				Expect(issue.Code).NotTo(Equal("LNKD-013"))
+			}
+		})
+
+		It("should reject unsupported kinds", func() {
+			result, err := validator.ValidateResource(ctx, "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: settings\n")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.IsError).To(BeTrue())
+		})
+
+		It("should reject documents that can't be parsed", func() {
+			result, err := validator.ValidateResource(ctx, "kind: [Server")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.IsError).To(BeTrue())
+
+			result, err = validator.ValidateResource(ctx, "metadata:\n  name: no-kind\n")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.IsError).To(BeTrue())
+		})
+	})
+})
@@ -3,7 +3,10 @@ package validators
 import (
 	"context"
 	"fmt"
+	"sort"
+	"strings"
 
+	"github.com/christianhuening/linkerd-mcp/internal/policy"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -176,7 +179,9 @@ func (v *AuthPolicyValidator) validateAuthRef(ctx context.Context, result *Valid
 	}
 }
 
-// ValidateAll validates all AuthorizationPolicy resources in a namespace
+// ValidateAll validates all AuthorizationPolicy resources in a namespace. When they
+// can't be listed, because the CRD isn't installed or listing is forbidden, the only result is
+// a diagnostic one
 func (v *AuthPolicyValidator) ValidateAll(ctx context.Context, namespace string) []ValidationResult {
 	var results []ValidationResult
 
@@ -191,7 +196,7 @@ func (v *AuthPolicyValidator) ValidateAll(ctx context.Context, namespace string)
 	}
 
 	if err != nil {
-		return results
+		return append(results, listFailureResult(authPolicyGVR, "AuthorizationPolicy", namespace, err))
 	}
 
 	for i := range policies.Items {
@@ -199,5 +204,134 @@ func (v *AuthPolicyValidator) ValidateAll(ctx context.Context, namespace string)
 		results = append(results, result)
 	}
 
+	v.checkOverlappingPolicies(ctx, policies.Items, results)
+
 	return results
 }
+
+// checkOverlappingPolicies flags policies that share their target Server with other
+// AuthorizationPolicies. Linkerd authorizes a request when any policy on the Server allows it, so
+// the allowed sources of all of them are OR'd together and adding a restrictive policy next to a
+// permissive one restricts nothing. Policies in other namespaces may target the same Server, so
+// every AuthorizationPolicy in the cluster is considered.
+func (v *AuthPolicyValidator) checkOverlappingPolicies(ctx context.Context, policies []unstructured.Unstructured, results []ValidationResult) {
+	all := policies
+	if list, err := v.dynamicClient.Resource(authPolicyGVR).List(ctx, metav1.ListOptions{}); err == nil {
+		all = list.Items
+	}
+
+	byServer := map[string][]unstructured.Unstructured{}
+	for _, authPolicy := range all {
+		if namespace, name, ok := policy.PolicyTargetServer(authPolicy); ok {
+			key := namespace + "/" + name
+			byServer[key] = append(byServer[key], authPolicy)
+		}
+	}
+
+	for i, authPolicy := range policies {
+		namespace, name, ok := policy.PolicyTargetServer(authPolicy)
+		if !ok {
+			continue
+		}
+		key := namespace + "/" + name
+		overlapping := byServer[key]
+		if len(overlapping) < 2 {
+			continue
+		}
+
+		names := []string{}
+		sources := []string{}
+		seen := map[string]bool{}
+		for _, other := range overlapping {
+			names = append(names, other.GetNamespace()+"/"+other.GetName())
+			for _, source := range v.allowedSources(ctx, other) {
+				if !seen[source] {
+					seen[source] = true
+					sources = append(sources, source)
+				}
+			}
+		}
+		sort.Strings(names)
+		sort.Strings(sources)
+
+		results[i].AddIssue(SeverityInfo,
+			fmt.Sprintf("Server '%s' is targeted by %d AuthorizationPolicies (%s); a request is allowed when any of them allows it. Combined allowed sources: %s",
+				key, len(overlapping), strings.Join(names, ", "), strings.Join(sources, ", ")),
+			"spec.targetRef",
+			"LNKD-036",
+			"Merge the policies into one, or remove the more permissive ones, to restrict access to the Server")
+	}
+}
+
+// allowedSources describes the clients an AuthorizationPolicy allows, following its
+// authentication refs. A policy without authentication refs allows any client.
+func (v *AuthPolicyValidator) allowedSources(ctx context.Context, authPolicy unstructured.Unstructured) []string {
+	authRefs, _, _ := unstructured.NestedSlice(authPolicy.Object, "spec", "requiredAuthenticationRefs")
+	if len(authRefs) == 0 {
+		return []string{"any client (no authentication required)"}
+	}
+
+	sources := []string{}
+	for _, ref := range authRefs {
+		refMap, ok := ref.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		kind, _, _ := unstructured.NestedString(refMap, "kind")
+		name, _, _ := unstructured.NestedString(refMap, "name")
+		refNamespace, _, _ := unstructured.NestedString(refMap, "namespace")
+		if refNamespace == "" {
+			refNamespace = authPolicy.GetNamespace()
+		}
+
+		var gvr schema.GroupVersionResource
+		switch kind {
+		case "MeshTLSAuthentication":
+			gvr = meshTLSAuthGVR
+		case "NetworkAuthentication":
+			gvr = networkAuthGVR
+		default:
+			continue
+		}
+
+		auth, err := v.dynamicClient.Resource(gvr).Namespace(refNamespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			continue
+		}
+
+		identities, _, _ := unstructured.NestedStringSlice(auth.Object, "spec", "identities")
+		for _, identity := range identities {
+			if identity == "*" {
+				sources = append(sources, "all authenticated clients")
+				continue
+			}
+			sources = append(sources, "identity "+identity)
+		}
+
+		serviceAccounts, _, _ := unstructured.NestedSlice(auth.Object, "spec", "serviceAccounts")
+		for _, sa := range serviceAccounts {
+			saMap, ok := sa.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			saName, _, _ := unstructured.NestedString(saMap, "name")
+			saNamespace, _, _ := unstructured.NestedString(saMap, "namespace")
+			if saNamespace == "" {
+				saNamespace = refNamespace
+			}
+			sources = append(sources, fmt.Sprintf("serviceAccount %s/%s", saNamespace, saName))
+		}
+
+		networks, _, _ := unstructured.NestedSlice(auth.Object, "spec", "networks")
+		for _, network := range networks {
+			networkMap, ok := network.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			cidr, _, _ := unstructured.NestedString(networkMap, "cidr")
+			sources = append(sources, "network "+cidr)
+		}
+	}
+
+	return sources
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/christianhuening/linkerd-mcp/internal/testutil"
 	"github.com/christianhuening/linkerd-mcp/internal/validation/validators"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/dynamic/fake"
@@ -170,5 +171,46 @@ var _ = Describe("AuthPolicyValidator", func() {
 			Expect(results[0].ResourceType).To(Equal("AuthorizationPolicy"))
 			Expect(results[1].ResourceType).To(Equal("AuthorizationPolicy"))
 		})
+
+		It("should explain that policies on the same Server are OR'd together", func() {
+			server := testutil.CreateServer("backend-server", "prod", map[string]string{"app": "backend"}, 8080)
+			_, err := dynamicClient.Resource(serverGVR).Namespace("prod").Create(ctx, server, metav1.CreateOptions{})
+			Expect(err).NotTo(HaveOccurred())
+
+			meshAuth := testutil.CreateMeshTLSAuthentication("frontend-auth", "prod",
+				[]string{"frontend.prod.serviceaccount.identity.linkerd.cluster.local"},
+				[]map[string]string{{"name": "worker", "namespace": "jobs"}})
+			_, err = dynamicClient.Resource(meshTLSGVR).Namespace("prod").Create(ctx, meshAuth, metav1.CreateOptions{})
+			Expect(err).NotTo(HaveOccurred())
+
+			restrictive := testutil.CreateAuthorizationPolicy("allow-frontend", "prod", "backend-server",
+				[]map[string]string{{"name": "frontend-auth", "kind": "MeshTLSAuthentication"}})
+			permissive := testutil.CreateAuthorizationPolicy("allow-all", "prod", "backend-server", nil)
+			unrelated := testutil.CreateAuthorizationPolicy("allow-other", "prod", "other-server", nil)
+			for _, authPolicy := range []*unstructured.Unstructured{restrictive, permissive, unrelated} {
+				_, err = dynamicClient.Resource(authPolicyGVR).Namespace("prod").Create(ctx, authPolicy, metav1.CreateOptions{})
+				Expect(err).NotTo(HaveOccurred())
+			}
+
+			overlaps := map[string]validators.Issue{}
+			for _, result := range validator.ValidateAll(ctx, "prod") {
+				for _, issue := range result.Issues {
+					if issue.Code == "LNKD-036" {
+						overlaps[result.Name] = issue
+					}
+				}
+			}
+
+			Expect(overlaps).To(HaveLen(2))
+			Expect(overlaps).To(HaveKey("allow-frontend"))
+			Expect(overlaps).To(HaveKey("allow-all"))
+
+			issue := overlaps["allow-frontend"]
+			Expect(issue.Severity).To(Equal(validators.SeverityInfo))
+			Expect(issue.Message).To(ContainSubstring("Server 'prod/backend-server' is targeted by 2 AuthorizationPolicies (prod/allow-all, prod/allow-frontend)"))
+			Expect(issue.Message).To(ContainSubstring("any client (no authentication required)"))
+			Expect(issue.Message).To(ContainSubstring("identity frontend.prod.serviceaccount.identity.linkerd.cluster.local"))
+			Expect(issue.Message).To(ContainSubstring("serviceAccount jobs/worker"))
+		})
 	})
 })
@@ -0,0 +1,243 @@
+package validators
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/dynamic"
+)
+
+var egressNetworkGVR = schema.GroupVersionResource{
+	Group:    "policy.linkerd.io",
+	Version:  "v1alpha1",
+	Resource: "egressnetworks",
+}
+
+// tlsRouteGVR is the Gateway API TLSRoute, which routes TLS traffic to an EgressNetwork by SNI
+var tlsRouteGVR = schema.GroupVersionResource{
+	Group:    "gateway.networking.k8s.io",
+	Version:  "v1alpha2",
+	Resource: "tlsroutes",
+}
+
+// EgressNetworkValidator validates Linkerd EgressNetwork CRDs, which control the traffic of
+// meshed clients to destinations outside the cluster
+type EgressNetworkValidator struct {
+	dynamicClient dynamic.Interface
+}
+
+// NewEgressNetworkValidator creates a new EgressNetwork validator
+func NewEgressNetworkValidator(dynamicClient dynamic.Interface) *EgressNetworkValidator {
+	return &EgressNetworkValidator{
+		dynamicClient: dynamicClient,
+	}
+}
+
+// Validate validates an EgressNetwork resource and the hostnames of the TLSRoutes attached to it
+func (v *EgressNetworkValidator) Validate(ctx context.Context, egress *unstructured.Unstructured) ValidationResult {
+	result := ValidationResult{
+		ResourceType: "EgressNetwork",
+		Name:         egress.GetName(),
+		Namespace:    egress.GetNamespace(),
+		Issues:       []Issue{},
+	}
+
+	// Extract spec
+	spec, found, err := unstructured.NestedMap(egress.Object, "spec")
+	if err != nil || !found {
+		result.AddIssue(SeverityError, "Missing or invalid spec", "spec", "LNKD-040", "Add a valid spec field to the EgressNetwork")
+		result.Finalize()
+		return result
+	}
+
+	trafficPolicy, _, _ := unstructured.NestedString(spec, "trafficPolicy")
+	switch trafficPolicy {
+	case "Allow", "Deny":
+	case "":
+		result.AddIssue(SeverityError,
+			"Missing trafficPolicy",
+			"spec.trafficPolicy",
+			"LNKD-041",
+			"Set trafficPolicy to Allow or Deny")
+	default:
+		result.AddIssue(SeverityError,
+			fmt.Sprintf("Invalid trafficPolicy '%s'", trafficPolicy),
+			"spec.trafficPolicy",
+			"LNKD-041",
+			"Set trafficPolicy to Allow or Deny")
+	}
+
+	allExternal := v.validateNetworks(&result, spec)
+	if trafficPolicy == "Allow" && allExternal {
+		result.AddIssue(SeverityInfo,
+			"Allows traffic to every destination outside the cluster",
+			"spec.networks",
+			"LNKD-044",
+			"Narrow spec.networks, or set trafficPolicy to Deny and allow destinations with routes")
+	}
+
+	v.validateTLSRoutes(ctx, &result, egress)
+
+	result.Finalize()
+	return result
+}
+
+// validateNetworks checks the CIDRs of the networks and reports whether they cover every
+// address. Without networks, an EgressNetwork covers every address outside the cluster
+// networks.
+func (v *EgressNetworkValidator) validateNetworks(result *ValidationResult, spec map[string]interface{}) bool {
+	networks, _, _ := unstructured.NestedSlice(spec, "networks")
+	if len(networks) == 0 {
+		return true
+	}
+
+	allExternal := false
+	for i, network := range networks {
+		field := fmt.Sprintf("spec.networks[%d]", i)
+		networkMap, ok := network.(map[string]interface{})
+		if !ok {
+			result.AddIssue(SeverityError,
+				fmt.Sprintf("Invalid network format at index %d", i),
+				field,
+				"LNKD-042",
+				"Each network must have a cidr field")
+			continue
+		}
+
+		cidr, _, _ := unstructured.NestedString(networkMap, "cidr")
+		parent, err := parseNetwork(cidr)
+		if err != nil {
+			result.AddIssue(SeverityError,
+				fmt.Sprintf("Invalid cidr '%s' at index %d", cidr, i),
+				field+".cidr",
+				"LNKD-042",
+				"Use a valid IPv4 or IPv6 CIDR (e.g. 203.0.113.0/24) or IP address")
+			continue
+		}
+
+		excepts, _, _ := unstructured.NestedStringSlice(networkMap, "except")
+		if ones, _ := parent.Mask.Size(); ones == 0 && len(excepts) == 0 {
+			allExternal = true
+		}
+
+		for j, except := range excepts {
+			exceptField := fmt.Sprintf("%s.except[%d]", field, j)
+			excluded, err := parseNetwork(except)
+			if err != nil {
+				result.AddIssue(SeverityError,
+					fmt.Sprintf("Invalid except entry '%s' in network '%s'", except, cidr),
+					exceptField,
+					"LNKD-043",
+					"Use a valid IPv4 or IPv6 CIDR or IP address")
+				continue
+			}
+
+			if !containsNetwork(parent, excluded) {
+				result.AddIssue(SeverityError,
+					fmt.Sprintf("Except entry '%s' is not contained in network '%s'", except, cidr),
+					exceptField,
+					"LNKD-043",
+					fmt.Sprintf("Only exclude subnets of %s, or remove the entry", cidr))
+			}
+		}
+	}
+	return allExternal
+}
+
+// validateTLSRoutes checks the SNI hostnames of the TLSRoutes attached to the EgressNetwork.
+// Clusters without the Gateway API TLSRoute CRD have no routes to check.
+func (v *EgressNetworkValidator) validateTLSRoutes(ctx context.Context, result *ValidationResult, egress *unstructured.Unstructured) {
+	routes, err := v.dynamicClient.Resource(tlsRouteGVR).Namespace(egress.GetNamespace()).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return
+	}
+
+	for _, route := range routes.Items {
+		if !attachedToEgressNetwork(route, egress.GetNamespace(), egress.GetName()) {
+			continue
+		}
+
+		hostnames, _, _ := unstructured.NestedStringSlice(route.Object, "spec", "hostnames")
+		if len(hostnames) == 0 {
+			result.AddIssue(SeverityWarning,
+				fmt.Sprintf("TLSRoute %s has no hostnames and matches every SNI", route.GetName()),
+				"spec",
+				"LNKD-046",
+				fmt.Sprintf("List the external hosts clients may reach in the hostnames of TLSRoute %s", route.GetName()))
+			continue
+		}
+
+		for _, hostname := range hostnames {
+			if !validHostname(hostname) {
+				result.AddIssue(SeverityError,
+					fmt.Sprintf("TLSRoute %s has invalid hostname '%s'", route.GetName(), hostname),
+					"spec",
+					"LNKD-045",
+					"Use a DNS name, optionally with a leading wildcard label (e.g. *.example.com); TLS matches on SNI, which can't be an IP address")
+			}
+		}
+	}
+}
+
+// attachedToEgressNetwork reports whether a route has the given EgressNetwork as a parent. A
+// parentRef without a namespace refers to the route's own namespace.
+func attachedToEgressNetwork(route unstructured.Unstructured, namespace, name string) bool {
+	parentRefs, _, _ := unstructured.NestedSlice(route.Object, "spec", "parentRefs")
+	for _, parentRef := range parentRefs {
+		refMap, ok := parentRef.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		kind, _, _ := unstructured.NestedString(refMap, "kind")
+		group, _, _ := unstructured.NestedString(refMap, "group")
+		refName, _, _ := unstructured.NestedString(refMap, "name")
+		refNamespace, _, _ := unstructured.NestedString(refMap, "namespace")
+		if refNamespace == "" {
+			refNamespace = route.GetNamespace()
+		}
+		if kind == "EgressNetwork" && group == egressNetworkGVR.Group && refName == name && refNamespace == namespace {
+			return true
+		}
+	}
+	return false
+}
+
+// validHostname reports whether a route hostname is a DNS name, optionally with a leading
+// wildcard label, as Gateway API requires. IP addresses aren't hostnames.
+func validHostname(hostname string) bool {
+	name := strings.TrimPrefix(hostname, "*.")
+	return len(validation.IsDNS1123Subdomain(name)) == 0 && net.ParseIP(name) == nil
+}
+
+// ValidateAll validates all EgressNetwork resources in a namespace. When they can't be listed,
+// because the CRD isn't installed or listing is forbidden, the only result is a diagnostic one
+func (v *EgressNetworkValidator) ValidateAll(ctx context.Context, namespace string) []ValidationResult {
+	var results []ValidationResult
+
+	listOptions := metav1.ListOptions{}
+	var networks *unstructured.UnstructuredList
+	var err error
+
+	if namespace == "" {
+		networks, err = v.dynamicClient.Resource(egressNetworkGVR).List(ctx, listOptions)
+	} else {
+		networks, err = v.dynamicClient.Resource(egressNetworkGVR).Namespace(namespace).List(ctx, listOptions)
+	}
+
+	if err != nil {
+		return append(results, listFailureResult(egressNetworkGVR, "EgressNetwork", namespace, err))
+	}
+
+	for i := range networks.Items {
+		result := v.Validate(ctx, &networks.Items[i])
+		results = append(results, result)
+	}
+
+	return results
+}
@@ -0,0 +1,135 @@
+package validators_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/christianhuening/linkerd-mcp/internal/testutil"
+	"github.com/christianhuening/linkerd-mcp/internal/validation/validators"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/fake"
+)
+
+var _ = Describe("EgressNetworkValidator", func() {
+	var (
+		ctx           context.Context
+		validator     *validators.EgressNetworkValidator
+		dynamicClient *fake.FakeDynamicClient
+	)
+
+	egressNetworkGVR := schema.GroupVersionResource{Group: "policy.linkerd.io", Version: "v1alpha1", Resource: "egressnetworks"}
+	tlsRouteGVR := schema.GroupVersionResource{Group: "gateway.networking.k8s.io", Version: "v1alpha2", Resource: "tlsroutes"}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+
+		gvrToListKind := map[schema.GroupVersionResource]string{
+			egressNetworkGVR: "EgressNetworkList",
+			tlsRouteGVR:      "TLSRouteList",
+		}
+
+		dynamicClient = fake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), gvrToListKind)
+		validator = validators.NewEgressNetworkValidator(dynamicClient)
+	})
+
+	issueCodes := func(result validators.ValidationResult) []string {
+		codes := []string{}
+		for _, issue := range result.Issues {
+			codes = append(codes, issue.Code)
+		}
+		return codes
+	}
+
+	Describe("Validate", func() {
+		It("should pass with valid networks and TLSRoute hostnames", func() {
+			egress := testutil.CreateEgressNetwork("payments", "prod", "Deny", []map[string]interface{}{
+				{"cidr": "0.0.0.0/0", "except": []interface{}{"10.0.0.0/8"}},
+				{"cidr": "2001:db8::/32"},
+			})
+			_, err := dynamicClient.Resource(tlsRouteGVR).Namespace("prod").Create(ctx,
+				testutil.CreateTLSRoute("stripe", "prod", "payments", 443, []string{"api.stripe.com", "*.stripe.com"}), metav1.CreateOptions{})
+			Expect(err).NotTo(HaveOccurred())
+
+			result := validator.Validate(ctx, egress)
+
+			Expect(result.Valid).To(BeTrue())
+			Expect(result.ResourceType).To(Equal("EgressNetwork"))
+			Expect(result.Issues).To(BeEmpty())
+		})
+
+		It("should require a valid trafficPolicy", func() {
+			result := validator.Validate(ctx, testutil.CreateEgressNetwork("external", "prod", "Audit", nil))
+
+			Expect(result.Valid).To(BeFalse())
+			Expect(issueCodes(result)).To(ConsistOf("LNKD-041"))
+
+			result = validator.Validate(ctx, testutil.CreateEgressNetwork("external", "prod", "", nil))
+			Expect(issueCodes(result)).To(ConsistOf("LNKD-041"))
+		})
+
+		It("should report invalid CIDRs and except entries outside their network", func() {
+			egress := testutil.CreateEgressNetwork("external", "prod", "Deny", []map[string]interface{}{
+				{"cidr": "203.0.113.0/33"},
+				{"cidr": "198.51.100.0/24", "except": []interface{}{"10.0.0.0/8", "bogus"}},
+			})
+
+			result := validator.Validate(ctx, egress)
+
+			Expect(result.Valid).To(BeFalse())
+			Expect(issueCodes(result)).To(ConsistOf("LNKD-042", "LNKD-043", "LNKD-043"))
+		})
+
+		It("should note an Allow policy covering every external destination", func() {
+			result := validator.Validate(ctx, testutil.CreateEgressNetwork("all-external", "prod", "Allow", nil))
+
+			Expect(result.Valid).To(BeTrue())
+			Expect(result.Issues).To(ConsistOf(And(
+				HaveField("Severity", validators.SeverityInfo),
+				HaveField("Code", "LNKD-044"),
+			)))
+		})
+
+		It("should check the hostnames of attached TLSRoutes only", func() {
+			for _, route := range []*unstructured.Unstructured{
+				testutil.CreateTLSRoute("ip-host", "prod", "payments", 443, []string{"203.0.113.10", "bad_host.example.com"}),
+				testutil.CreateTLSRoute("any-host", "prod", "payments", 443, nil),
+				testutil.CreateTLSRoute("other-network", "prod", "other", 443, []string{"203.0.113.10"}),
+			} {
+				_, err := dynamicClient.Resource(tlsRouteGVR).Namespace("prod").Create(ctx, route, metav1.CreateOptions{})
+				Expect(err).NotTo(HaveOccurred())
+			}
+
+			result := validator.Validate(ctx, testutil.CreateEgressNetwork("payments", "prod", "Deny", nil))
+
+			Expect(result.Valid).To(BeFalse())
+			Expect(issueCodes(result)).To(ConsistOf("LNKD-045", "LNKD-045", "LNKD-046"))
+		})
+
+		It("should require a spec", func() {
+			egress := testutil.CreateEgressNetwork("external", "prod", "Deny", nil)
+			delete(egress.Object, "spec")
+
+			Expect(issueCodes(validator.Validate(ctx, egress))).To(ConsistOf("LNKD-040"))
+		})
+	})
+
+	Describe("ValidateAll", func() {
+		It("should validate all EgressNetworks in a namespace", func() {
+			for _, name := range []string{"payments", "monitoring"} {
+				_, err := dynamicClient.Resource(egressNetworkGVR).Namespace("prod").Create(ctx,
+					testutil.CreateEgressNetwork(name, "prod", "Deny", nil), metav1.CreateOptions{})
+				Expect(err).NotTo(HaveOccurred())
+			}
+
+			results := validator.ValidateAll(ctx, "prod")
+
+			Expect(results).To(HaveLen(2))
+			Expect(results).To(HaveEach(HaveField("ResourceType", "EgressNetwork")))
+		})
+	})
+})
@@ -0,0 +1,104 @@
+package validators
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Markdown renders the report for human readers: the summary counts, a table with one row per
+// resource, and the issues grouped by severity
+func (cvr *ClusterValidationReport) Markdown() string {
+	var b strings.Builder
+
+	b.WriteString("# Linkerd Validation Report\n\n")
+	result := "passed"
+	if !cvr.Passed {
+		result = "failed"
+	}
+	fmt.Fprintf(&b, "- **Result:** %s (fails on %s or above)\n", result, cvr.FailOn)
+	fmt.Fprintf(&b, "- **Resources:** %d total, %d valid, %d invalid\n",
+		cvr.TotalResources, cvr.ValidResources, cvr.TotalResources-cvr.ValidResources)
+	fmt.Fprintf(&b, "- **Issues:** %d errors, %d warnings, %d info\n",
+		cvr.Summary.Errors, cvr.Summary.Warnings, cvr.Summary.Info)
+	if cvr.Summary.Diagnostics > 0 {
+		fmt.Fprintf(&b, "- **Diagnostics:** %d resource types couldn't be listed; see the errors below\n", cvr.Summary.Diagnostics)
+	}
+	fmt.Fprintf(&b, "- **Duration:** %.2fms\n", cvr.DurationMs)
+	if cvr.Truncated {
+		fmt.Fprintf(&b, "- **Truncated:** showing %d of %d resources, those with the most severe issues first\n",
+			len(cvr.Results), len(cvr.Results)+cvr.OmittedResults)
+	}
+
+	if len(cvr.Results) == 0 {
+		b.WriteString("\nNo resources found.\n")
+		return b.String()
+	}
+
+	b.WriteString("\n| Type | Namespace | Name | Valid | Errors | Warnings | Info |\n")
+	b.WriteString("|------|-----------|------|-------|--------|----------|------|\n")
+	for _, result := range cvr.Results {
+		counts := map[Severity]int{}
+		for _, issue := range result.Issues {
+			counts[issue.Severity]++
+		}
+		valid := "yes"
+		if !result.Valid {
+			valid = "no"
+		}
+		fmt.Fprintf(&b, "| %s | %s | %s | %s | %d | %d | %d |\n",
+			markdownCell(result.ResourceType), markdownCell(result.Namespace), markdownCell(result.Name), valid,
+			counts[SeverityError], counts[SeverityWarning], counts[SeverityInfo])
+	}
+
+	sections := []struct {
+		severity Severity
+		title    string
+	}{
+		{SeverityError, "Errors"},
+		{SeverityWarning, "Warnings"},
+		{SeverityInfo, "Info"},
+	}
+	for _, section := range sections {
+		lines := []string{}
+		for _, result := range cvr.Results {
+			for _, issue := range result.Issues {
+				if issue.Severity != section.severity {
+					continue
+				}
+				line := fmt.Sprintf("- **%s %s**", result.ResourceType, resourceID(result))
+				if issue.Code != "" {
+					line += fmt.Sprintf(" `%s`", issue.Code)
+				}
+				if issue.Field != "" {
+					line += fmt.Sprintf(" (`%s`)", issue.Field)
+				}
+				line += ": " + issue.Message
+				if issue.Remediation != "" {
+					line += " — " + issue.Remediation
+				}
+				lines = append(lines, line)
+			}
+		}
+		if len(lines) == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "\n## %s\n\n", section.title)
+		b.WriteString(strings.Join(lines, "\n"))
+		b.WriteString("\n")
+	}
+
+	return b.String()
+}
+
+// resourceID returns namespace/name, or just the name for cluster-scoped results
+func resourceID(result ValidationResult) string {
+	if result.Namespace == "" {
+		return result.Name
+	}
+	return result.Namespace + "/" + result.Name
+}
+
+// markdownCell escapes a value for use in a Markdown table cell
+func markdownCell(value string) string {
+	return strings.ReplaceAll(value, "|", "\\|")
+}
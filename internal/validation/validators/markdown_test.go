@@ -0,0 +1,65 @@
+package validators_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/christianhuening/linkerd-mcp/internal/validation/validators"
+)
+
+var _ = Describe("ClusterValidationReport Markdown", func() {
+	It("should render summary counts, a resource table and issues by severity", func() {
+		report := validators.ClusterValidationReport{}
+		report.Start()
+
+		server := validators.ValidationResult{ResourceType: "Server", Name: "api|server", Namespace: "prod"}
+		server.AddIssue(validators.SeverityError, "Invalid port", "spec.port", "LNKD-001", "Use a port between 1 and 65535")
+		server.AddIssue(validators.SeverityWarning, "No proxy protocol", "spec.proxyProtocol", "LNKD-004", "")
+		server.Finalize()
+		report.AddResult(server)
+
+		namespace := validators.ValidationResult{ResourceType: "Namespace", Name: "prod"}
+		namespace.AddIssue(validators.SeverityInfo, "Injection enabled", "", "", "")
+		namespace.Finalize()
+		report.AddResult(namespace)
+
+		report.Finalize()
+		markdown := report.Markdown()
+
+		Expect(markdown).To(HavePrefix("# Linkerd Validation Report\n"))
+		Expect(markdown).To(ContainSubstring("- **Result:** failed (fails on error or above)"))
+		Expect(markdown).To(ContainSubstring("- **Resources:** 2 total, 1 valid, 1 invalid"))
+		Expect(markdown).To(ContainSubstring("- **Issues:** 1 errors, 1 warnings, 1 info"))
+		Expect(markdown).To(ContainSubstring("| Server | prod | api\\|server | no | 1 | 1 | 0 |"))
+		Expect(markdown).To(ContainSubstring("| Namespace |  | prod | yes | 0 | 0 | 1 |"))
+		Expect(markdown).To(ContainSubstring("## Errors\n\n- **Server prod/api|server** `LNKD-001` (`spec.port`): Invalid port — Use a port between 1 and 65535\n"))
+		Expect(markdown).To(ContainSubstring("## Warnings\n\n- **Server prod/api|server** `LNKD-004` (`spec.proxyProtocol`): No proxy protocol\n"))
+		Expect(markdown).To(ContainSubstring("## Info\n\n- **Namespace prod**: Injection enabled\n"))
+	})
+
+	It("should count diagnostics apart from resources", func() {
+		report := validators.ClusterValidationReport{}
+
+		diagnostic := validators.ValidationResult{ResourceType: "Server", Name: "servers.policy.linkerd.io", Diagnostic: true}
+		diagnostic.AddIssue(validators.SeverityError, "The servers.policy.linkerd.io CRD is not installed", "", "LNKD-037", "")
+		diagnostic.Finalize()
+		report.AddResult(diagnostic)
+
+		report.Finalize()
+		markdown := report.Markdown()
+
+		Expect(report.Passed).To(BeFalse())
+		Expect(markdown).To(ContainSubstring("- **Resources:** 0 total, 0 valid, 0 invalid"))
+		Expect(markdown).To(ContainSubstring("- **Diagnostics:** 1 resource types couldn't be listed"))
+		Expect(markdown).To(ContainSubstring("- **Server servers.policy.linkerd.io** `LNKD-037`"))
+	})
+
+	It("should note an empty report", func() {
+		report := validators.ClusterValidationReport{}
+		report.Finalize()
+
+		markdown := report.Markdown()
+		Expect(markdown).To(ContainSubstring("No resources found."))
+		Expect(markdown).NotTo(ContainSubstring("| Type |"))
+	})
+})
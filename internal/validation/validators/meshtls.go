@@ -3,8 +3,10 @@ package validators
 import (
 	"context"
 	"fmt"
+	"regexp"
 	"strings"
 
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/client-go/dynamic"
@@ -86,12 +88,23 @@ func (v *MeshTLSValidator) validateIdentities(result *ValidationResult, identiti
 		}
 
 		// Validate identity format (should be like: service-account.namespace.serviceaccount.identity.linkerd.cluster.local)
-		if !isValidIdentityFormat(identity) {
+		trustDomain, ok := parseIdentityTrustDomain(identity)
+		if !ok {
 			result.AddIssue(SeverityWarning,
 				fmt.Sprintf("Identity '%s' at index %d may not be in the correct format", identity, i),
 				fmt.Sprintf("spec.identities[%d]", i),
 				"LNKD-023",
 				"Identity should follow format: <sa>.<ns>.serviceaccount.identity.linkerd.cluster.local")
+			continue
+		}
+
+		// A custom trust domain is legal, but must match the one the control plane was installed with
+		if trustDomain != defaultTrustDomain {
+			result.AddIssue(SeverityWarning,
+				fmt.Sprintf("Identity '%s' at index %d uses the custom trust domain '%s'", identity, i, trustDomain),
+				fmt.Sprintf("spec.identities[%d]", i),
+				"LNKD-035",
+				fmt.Sprintf("Confirm that Linkerd was installed with the identity trust domain '%s'", trustDomain))
 		}
 	}
 }
@@ -141,12 +154,123 @@ func (v *MeshTLSValidator) validateServiceAccounts(ctx context.Context, result *
 	}
 }
 
-func isValidIdentityFormat(identity string) bool {
-	// Basic validation: should contain .serviceaccount.identity.linkerd
-	return strings.Contains(identity, ".serviceaccount.identity.linkerd")
+// FindMissingServiceAccounts collects the service accounts referenced by MeshTLSAuthentications
+// in a namespace (all namespaces if empty), through either spec.serviceAccounts or
+// spec.identities, and returns those that do not exist in the cluster
+func (v *MeshTLSValidator) FindMissingServiceAccounts(ctx context.Context, namespace string) (int, []MissingServiceAccount, error) {
+	listOptions := metav1.ListOptions{}
+	var auths *unstructured.UnstructuredList
+	var err error
+
+	if namespace == "" {
+		auths, err = v.dynamicClient.Resource(meshTLSAuthGVR).List(ctx, listOptions)
+	} else {
+		auths, err = v.dynamicClient.Resource(meshTLSAuthGVR).Namespace(namespace).List(ctx, listOptions)
+	}
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to list MeshTLSAuthentications: %w", err)
+	}
+
+	// "namespace/name" -> references, in discovery order
+	references := map[string]*MissingServiceAccount{}
+	order := []string{}
+	addReference := func(saName, saNamespace string, ref ResourceReference) {
+		key := saNamespace + "/" + saName
+		if _, exists := references[key]; !exists {
+			references[key] = &MissingServiceAccount{
+				ServiceAccount: saName,
+				Namespace:      saNamespace,
+				ReferencedBy:   []ResourceReference{},
+			}
+			order = append(order, key)
+		}
+		references[key].ReferencedBy = append(references[key].ReferencedBy, ref)
+	}
+
+	for _, auth := range auths.Items {
+		identities, _, _ := unstructured.NestedStringSlice(auth.Object, "spec", "identities")
+		for i, identity := range identities {
+			saName, saNamespace, ok := parseServiceAccountIdentity(identity)
+			if !ok {
+				continue
+			}
+			addReference(saName, saNamespace, ResourceReference{
+				Kind:      "MeshTLSAuthentication",
+				Name:      auth.GetName(),
+				Namespace: auth.GetNamespace(),
+				Field:     fmt.Sprintf("spec.identities[%d]", i),
+			})
+		}
+
+		serviceAccounts, _, _ := unstructured.NestedSlice(auth.Object, "spec", "serviceAccounts")
+		for i, sa := range serviceAccounts {
+			saMap, ok := sa.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			saName, _, _ := unstructured.NestedString(saMap, "name")
+			saNamespace, _, _ := unstructured.NestedString(saMap, "namespace")
+			if saName == "" {
+				continue
+			}
+			if saNamespace == "" {
+				saNamespace = auth.GetNamespace()
+			}
+			addReference(saName, saNamespace, ResourceReference{
+				Kind:      "MeshTLSAuthentication",
+				Name:      auth.GetName(),
+				Namespace: auth.GetNamespace(),
+				Field:     fmt.Sprintf("spec.serviceAccounts[%d]", i),
+			})
+		}
+	}
+
+	missing := []MissingServiceAccount{}
+	for _, key := range order {
+		ref := references[key]
+		_, err := v.clientset.CoreV1().ServiceAccounts(ref.Namespace).Get(ctx, ref.ServiceAccount, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			missing = append(missing, *ref)
+		}
+	}
+
+	return len(order), missing, nil
+}
+
+// parseServiceAccountIdentity extracts the service account and namespace from a Linkerd
+// identity of the form <sa>.<ns>.serviceaccount.identity.linkerd.<trust-domain>
+func parseServiceAccountIdentity(identity string) (string, string, bool) {
+	idx := strings.Index(identity, ".serviceaccount.identity.linkerd")
+	if idx == -1 {
+		return "", "", false
+	}
+	parts := strings.Split(identity[:idx], ".")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" || parts[0] == "*" {
+		return "", "", false
+	}
+	return parts[0], parts[1], true
+}
+
+// identityPattern matches a Linkerd service account identity,
+// <sa>.<ns>.serviceaccount.identity.linkerd.<trust-domain>. The service account may be a "*"
+// wildcard, which Linkerd matches as a suffix; the trust domain must be a DNS name.
+var identityPattern = regexp.MustCompile(`^(\*|[a-z0-9]([-a-z0-9]*[a-z0-9])?)\.[a-z0-9]([-a-z0-9]*[a-z0-9])?\.serviceaccount\.identity\.linkerd\.([a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*)$`)
+
+// defaultTrustDomain is the trust domain of a default Linkerd installation
+const defaultTrustDomain = "cluster.local"
+
+// parseIdentityTrustDomain returns the trust domain of a well-formed service account identity
+func parseIdentityTrustDomain(identity string) (string, bool) {
+	match := identityPattern.FindStringSubmatch(identity)
+	if match == nil {
+		return "", false
+	}
+	return match[4], true
 }
 
-// ValidateAll validates all MeshTLSAuthentication resources in a namespace
+// ValidateAll validates all MeshTLSAuthentication resources in a namespace. When they
+// can't be listed, because the CRD isn't installed or listing is forbidden, the only result is
+// a diagnostic one
 func (v *MeshTLSValidator) ValidateAll(ctx context.Context, namespace string) []ValidationResult {
 	var results []ValidationResult
 
@@ -161,7 +285,7 @@ func (v *MeshTLSValidator) ValidateAll(ctx context.Context, namespace string) []
 	}
 
 	if err != nil {
-		return results
+		return append(results, listFailureResult(meshTLSAuthGVR, "MeshTLSAuthentication", namespace, err))
 	}
 
 	for i := range auths.Items {
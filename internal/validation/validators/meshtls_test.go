@@ -112,6 +112,46 @@ var _ = Describe("MeshTLSValidator", func() {
 			})
 		})
 
+		Context("with identity format issues", func() {
+			identityCodes := func(identities ...string) []string {
+				meshAuth := testutil.CreateMeshTLSAuthentication("identity-auth", "prod", identities, nil)
+				result := validator.Validate(ctx, meshAuth)
+
+				codes := []string{}
+				for _, issue := range result.Issues {
+					codes = append(codes, issue.Code)
+				}
+				return codes
+			}
+
+			It("should warn about malformed identities", func() {
+				Expect(identityCodes(
+					"..serviceaccount.identity.linkerd.cluster.local",
+					"frontend-sa..serviceaccount.identity.linkerd.cluster.local",
+					"frontend-sa.prod.serviceaccount.identity.linkerd.",
+					"Frontend.prod.serviceaccount.identity.linkerd.cluster.local",
+					"frontend-sa.prod.serviceaccount.identity.linkerd",
+				)).To(Equal([]string{"LNKD-023", "LNKD-023", "LNKD-023", "LNKD-023", "LNKD-023"}))
+			})
+
+			It("should accept service account wildcards", func() {
+				Expect(identityCodes("*.prod.serviceaccount.identity.linkerd.cluster.local")).To(BeEmpty())
+			})
+
+			It("should warn separately about a custom trust domain", func() {
+				meshAuth := testutil.CreateMeshTLSAuthentication("identity-auth", "prod",
+					[]string{"frontend-sa.prod.serviceaccount.identity.linkerd.example.com"}, nil)
+
+				result := validator.Validate(ctx, meshAuth)
+
+				Expect(result.Valid).To(BeTrue())
+				Expect(result.Issues).To(HaveLen(1))
+				Expect(result.Issues[0].Code).To(Equal("LNKD-035"))
+				Expect(result.Issues[0].Severity).To(Equal(validators.SeverityWarning))
+				Expect(result.Issues[0].Message).To(ContainSubstring("example.com"))
+			})
+		})
+
 		Context("with neither identities nor serviceAccounts", func() {
 			It("should return error", func() {
 				meshAuth := testutil.CreateMeshTLSAuthentication("empty-auth", "prod", nil, nil)
@@ -189,4 +229,65 @@ var _ = Describe("MeshTLSValidator", func() {
 			Expect(results[1].ResourceType).To(Equal("MeshTLSAuthentication"))
 		})
 	})
+
+	Describe("FindMissingServiceAccounts", func() {
+		BeforeEach(func() {
+			sa := &corev1.ServiceAccount{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "frontend-sa",
+					Namespace: "prod",
+				},
+			}
+			_, err := kubeClient.CoreV1().ServiceAccounts("prod").Create(ctx, sa, metav1.CreateOptions{})
+			Expect(err).NotTo(HaveOccurred())
+
+			bySA := testutil.CreateMeshTLSAuthentication("by-sa", "prod", nil,
+				[]map[string]string{
+					{"name": "frontend-sa", "namespace": "prod"},
+					{"name": "deleted-sa"},
+				})
+			byIdentity := testutil.CreateMeshTLSAuthentication("by-identity", "prod",
+				[]string{
+					"frontend-sa.prod.serviceaccount.identity.linkerd.cluster.local",
+					"batch-sa.jobs.serviceaccount.identity.linkerd.cluster.local",
+					"deleted-sa.prod.serviceaccount.identity.linkerd.cluster.local",
+					"*",
+				}, nil)
+
+			_, err = dynamicClient.Resource(meshTLSGVR).Namespace("prod").Create(ctx, bySA, metav1.CreateOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			_, err = dynamicClient.Resource(meshTLSGVR).Namespace("prod").Create(ctx, byIdentity, metav1.CreateOptions{})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("should report only service accounts that do not exist", func() {
+			total, missing, err := validator.FindMissingServiceAccounts(ctx, "prod")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(total).To(Equal(3))
+			Expect(missing).To(HaveLen(2))
+
+			byKey := map[string]validators.MissingServiceAccount{}
+			for _, m := range missing {
+				byKey[m.Namespace+"/"+m.ServiceAccount] = m
+			}
+			Expect(byKey).To(HaveKey("jobs/batch-sa"))
+			Expect(byKey).To(HaveKey("prod/deleted-sa"))
+			Expect(byKey).NotTo(HaveKey("prod/frontend-sa"))
+
+			// referenced once via serviceAccounts (namespace defaulted) and once via identities
+			fields := []string{}
+			for _, ref := range byKey["prod/deleted-sa"].ReferencedBy {
+				fields = append(fields, ref.Field)
+			}
+			Expect(fields).To(ConsistOf("spec.serviceAccounts[1]", "spec.identities[2]"))
+		})
+
+		It("should return nothing when no references are missing", func() {
+			total, missing, err := validator.FindMissingServiceAccounts(ctx, "staging")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(total).To(Equal(0))
+			Expect(missing).To(BeEmpty())
+		})
+	})
 })
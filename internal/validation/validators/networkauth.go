@@ -0,0 +1,170 @@
+package validators
+
+import (
+	"context"
+	"fmt"
+	"net"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic"
+)
+
+// NetworkAuthValidator validates Linkerd NetworkAuthentication CRDs
+type NetworkAuthValidator struct {
+	dynamicClient dynamic.Interface
+}
+
+// NewNetworkAuthValidator creates a new NetworkAuthentication validator
+func NewNetworkAuthValidator(dynamicClient dynamic.Interface) *NetworkAuthValidator {
+	return &NetworkAuthValidator{
+		dynamicClient: dynamicClient,
+	}
+}
+
+// Validate validates a NetworkAuthentication resource
+func (v *NetworkAuthValidator) Validate(ctx context.Context, auth *unstructured.Unstructured) ValidationResult {
+	result := ValidationResult{
+		ResourceType: "NetworkAuthentication",
+		Name:         auth.GetName(),
+		Namespace:    auth.GetNamespace(),
+		Issues:       []Issue{},
+	}
+
+	// Extract spec
+	spec, found, err := unstructured.NestedMap(auth.Object, "spec")
+	if err != nil || !found {
+		result.AddIssue(SeverityError, "Missing or invalid spec", "spec", "LNKD-028", "Add a valid spec field to the NetworkAuthentication")
+		result.Finalize()
+		return result
+	}
+
+	v.validateNetworks(&result, spec)
+
+	result.Finalize()
+	return result
+}
+
+func (v *NetworkAuthValidator) validateNetworks(result *ValidationResult, spec map[string]interface{}) {
+	networks, _, _ := unstructured.NestedSlice(spec, "networks")
+	if len(networks) == 0 {
+		result.AddIssue(SeverityError,
+			"Must specify at least one network",
+			"spec.networks",
+			"LNKD-029",
+			"Add a network with a cidr to spec.networks")
+		return
+	}
+
+	for i, network := range networks {
+		field := fmt.Sprintf("spec.networks[%d]", i)
+		networkMap, ok := network.(map[string]interface{})
+		if !ok {
+			result.AddIssue(SeverityError,
+				fmt.Sprintf("Invalid network format at index %d", i),
+				field,
+				"LNKD-030",
+				"Each network must have a cidr field")
+			continue
+		}
+
+		cidr, _, _ := unstructured.NestedString(networkMap, "cidr")
+		if cidr == "" {
+			result.AddIssue(SeverityError,
+				fmt.Sprintf("Missing cidr at index %d", i),
+				field+".cidr",
+				"LNKD-030",
+				"Specify the network as a CIDR, e.g. 10.0.0.0/8")
+			continue
+		}
+
+		parent, err := parseNetwork(cidr)
+		if err != nil {
+			result.AddIssue(SeverityError,
+				fmt.Sprintf("Invalid cidr '%s' at index %d", cidr, i),
+				field+".cidr",
+				"LNKD-031",
+				"Use a valid IPv4 or IPv6 CIDR (e.g. 10.0.0.0/8) or IP address")
+			continue
+		}
+
+		if ones, _ := parent.Mask.Size(); ones == 0 {
+			result.AddIssue(SeverityWarning,
+				fmt.Sprintf("Network '%s' matches every client address", cidr),
+				field+".cidr",
+				"LNKD-034",
+				"Consider restricting the network to the clients that need access")
+		}
+
+		excepts, _, _ := unstructured.NestedStringSlice(networkMap, "except")
+		for j, except := range excepts {
+			exceptField := fmt.Sprintf("%s.except[%d]", field, j)
+			excluded, err := parseNetwork(except)
+			if err != nil {
+				result.AddIssue(SeverityError,
+					fmt.Sprintf("Invalid except entry '%s' in network '%s'", except, cidr),
+					exceptField,
+					"LNKD-032",
+					"Use a valid IPv4 or IPv6 CIDR or IP address")
+				continue
+			}
+
+			if !containsNetwork(parent, excluded) {
+				result.AddIssue(SeverityError,
+					fmt.Sprintf("Except entry '%s' is not contained in network '%s'", except, cidr),
+					exceptField,
+					"LNKD-033",
+					fmt.Sprintf("Only exclude subnets of %s, or remove the entry", cidr))
+			}
+		}
+	}
+}
+
+// parseNetwork parses a CIDR or, like Linkerd, a single IP address as a host network
+func parseNetwork(value string) (*net.IPNet, error) {
+	if ip := net.ParseIP(value); ip != nil {
+		bits := 8 * net.IPv6len
+		if ip4 := ip.To4(); ip4 != nil {
+			ip, bits = ip4, 8*net.IPv4len
+		}
+		return &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)}, nil
+	}
+
+	_, network, err := net.ParseCIDR(value)
+	return network, err
+}
+
+// containsNetwork reports whether inner lies entirely within outer
+func containsNetwork(outer, inner *net.IPNet) bool {
+	outerOnes, outerBits := outer.Mask.Size()
+	innerOnes, innerBits := inner.Mask.Size()
+	return outerBits == innerBits && innerOnes >= outerOnes && outer.Contains(inner.IP)
+}
+
+// ValidateAll validates all NetworkAuthentication resources in a namespace. When they
+// can't be listed, because the CRD isn't installed or listing is forbidden, the only result is
+// a diagnostic one
+func (v *NetworkAuthValidator) ValidateAll(ctx context.Context, namespace string) []ValidationResult {
+	var results []ValidationResult
+
+	listOptions := metav1.ListOptions{}
+	var auths *unstructured.UnstructuredList
+	var err error
+
+	if namespace == "" {
+		auths, err = v.dynamicClient.Resource(networkAuthGVR).List(ctx, listOptions)
+	} else {
+		auths, err = v.dynamicClient.Resource(networkAuthGVR).Namespace(namespace).List(ctx, listOptions)
+	}
+
+	if err != nil {
+		return append(results, listFailureResult(networkAuthGVR, "NetworkAuthentication", namespace, err))
+	}
+
+	for i := range auths.Items {
+		result := v.Validate(ctx, &auths.Items[i])
+		results = append(results, result)
+	}
+
+	return results
+}
@@ -0,0 +1,133 @@
+package validators_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/christianhuening/linkerd-mcp/internal/testutil"
+	"github.com/christianhuening/linkerd-mcp/internal/validation/validators"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/fake"
+)
+
+var _ = Describe("NetworkAuthValidator", func() {
+	var (
+		ctx            context.Context
+		validator      *validators.NetworkAuthValidator
+		dynamicClient  *fake.FakeDynamicClient
+		networkAuthGVR schema.GroupVersionResource
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+
+		networkAuthGVR = schema.GroupVersionResource{
+			Group:    "policy.linkerd.io",
+			Version:  "v1alpha1",
+			Resource: "networkauthentications",
+		}
+
+		scheme := runtime.NewScheme()
+		gvrToListKind := map[schema.GroupVersionResource]string{
+			networkAuthGVR: "NetworkAuthenticationList",
+		}
+
+		dynamicClient = fake.NewSimpleDynamicClientWithCustomListKinds(scheme, gvrToListKind)
+		validator = validators.NewNetworkAuthValidator(dynamicClient)
+	})
+
+	issueCodes := func(result validators.ValidationResult) []string {
+		codes := []string{}
+		for _, issue := range result.Issues {
+			codes = append(codes, issue.Code)
+		}
+		return codes
+	}
+
+	Describe("Validate", func() {
+		It("should pass with valid networks and exceptions", func() {
+			auth := testutil.CreateNetworkAuthentication("cluster-network", "prod", []map[string]interface{}{
+				{"cidr": "10.0.0.0/8", "except": []interface{}{"10.1.0.0/16", "10.2.3.4"}},
+				{"cidr": "fd00::/8"},
+				{"cidr": "192.168.1.10"},
+			})
+
+			result := validator.Validate(ctx, auth)
+
+			Expect(result.Valid).To(BeTrue())
+			Expect(result.ResourceType).To(Equal("NetworkAuthentication"))
+			Expect(result.Issues).To(BeEmpty())
+		})
+
+		It("should require at least one network", func() {
+			auth := testutil.CreateNetworkAuthentication("empty", "prod", nil)
+
+			result := validator.Validate(ctx, auth)
+
+			Expect(result.Valid).To(BeFalse())
+			Expect(issueCodes(result)).To(Equal([]string{"LNKD-029"}))
+		})
+
+		It("should reject missing and invalid CIDRs", func() {
+			auth := testutil.CreateNetworkAuthentication("broken", "prod", []map[string]interface{}{
+				{"except": []interface{}{"10.1.0.0/16"}},
+				{"cidr": "10.0.0.0/33"},
+				{"cidr": "not-a-network"},
+			})
+
+			result := validator.Validate(ctx, auth)
+
+			Expect(result.Valid).To(BeFalse())
+			Expect(issueCodes(result)).To(Equal([]string{"LNKD-030", "LNKD-031", "LNKD-031"}))
+			Expect(result.Issues[1].Field).To(Equal("spec.networks[1].cidr"))
+		})
+
+		It("should reject exceptions that are invalid or outside the network", func() {
+			auth := testutil.CreateNetworkAuthentication("excepts", "prod", []map[string]interface{}{
+				{"cidr": "10.0.0.0/16", "except": []interface{}{"10.0.0.0/8", "192.168.0.0/24", "fd00::/64", "10.0.1.0/99"}},
+			})
+
+			result := validator.Validate(ctx, auth)
+
+			Expect(result.Valid).To(BeFalse())
+			Expect(issueCodes(result)).To(Equal([]string{"LNKD-033", "LNKD-033", "LNKD-033", "LNKD-032"}))
+			Expect(result.Issues[3].Field).To(Equal("spec.networks[0].except[3]"))
+		})
+
+		It("should warn about networks matching every address", func() {
+			auth := testutil.CreateNetworkAuthentication("everyone", "prod", []map[string]interface{}{
+				{"cidr": "0.0.0.0/0"},
+				{"cidr": "::/0"},
+			})
+
+			result := validator.Validate(ctx, auth)
+
+			Expect(result.Valid).To(BeTrue())
+			Expect(issueCodes(result)).To(Equal([]string{"LNKD-034", "LNKD-034"}))
+			Expect(result.Issues[0].Severity).To(Equal(validators.SeverityWarning))
+		})
+	})
+
+	Describe("ValidateAll", func() {
+		It("should validate every NetworkAuthentication in the namespace", func() {
+			valid := testutil.CreateNetworkAuthentication("valid", "prod", []map[string]interface{}{{"cidr": "10.0.0.0/8"}})
+			invalid := testutil.CreateNetworkAuthentication("invalid", "prod", nil)
+			other := testutil.CreateNetworkAuthentication("other", "staging", []map[string]interface{}{{"cidr": "10.0.0.0/8"}})
+			_, err := dynamicClient.Resource(networkAuthGVR).Namespace("prod").Create(ctx, valid, metav1.CreateOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			_, err = dynamicClient.Resource(networkAuthGVR).Namespace("prod").Create(ctx, invalid, metav1.CreateOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			_, err = dynamicClient.Resource(networkAuthGVR).Namespace("staging").Create(ctx, other, metav1.CreateOptions{})
+			Expect(err).NotTo(HaveOccurred())
+
+			results := validator.ValidateAll(ctx, "prod")
+			Expect(results).To(HaveLen(2))
+
+			Expect(validator.ValidateAll(ctx, "")).To(HaveLen(3))
+		})
+	})
+})
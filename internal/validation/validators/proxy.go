@@ -4,9 +4,13 @@ import (
 	"context"
 	"fmt"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
 
+	"github.com/christianhuening/linkerd-mcp/internal/kube"
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
@@ -14,7 +18,8 @@ import (
 
 // ProxyValidator validates Linkerd proxy configuration annotations
 type ProxyValidator struct {
-	clientset kubernetes.Interface
+	clientset       kubernetes.Interface
+	namespaceFilter *kube.NamespaceFilter
 }
 
 // NewProxyValidator creates a new proxy configuration validator
@@ -24,6 +29,11 @@ func NewProxyValidator(clientset kubernetes.Interface) *ProxyValidator {
 	}
 }
 
+// SetNamespaceFilter restricts the namespaces ValidateAllNamespaces validates
+func (v *ProxyValidator) SetNamespaceFilter(filter *kube.NamespaceFilter) {
+	v.namespaceFilter = filter
+}
+
 // ValidateNamespace validates proxy annotations on a namespace
 func (v *ProxyValidator) ValidateNamespace(ctx context.Context, ns *corev1.Namespace) ValidationResult {
 	result := ValidationResult{
@@ -41,25 +51,65 @@ func (v *ProxyValidator) ValidateNamespace(ctx context.Context, ns *corev1.Names
 	// Validate injection annotation
 	v.validateInjectionAnnotation(&result, annotations)
 
-	// Validate resource annotations
-	v.validateCPURequest(&result, annotations)
-	v.validateCPULimit(&result, annotations)
-	v.validateMemoryRequest(&result, annotations)
-	v.validateMemoryLimit(&result, annotations)
-
-	// Validate log level
-	v.validateLogLevel(&result, annotations)
-
-	// Validate proxy version
-	v.validateProxyVersion(&result, annotations)
+	if annotations["linkerd.io/inject"] == "enabled" {
+		v.validateNamespacePodsInjected(ctx, &result, ns.Name)
+	}
 
-	// Validate wait time
-	v.validateWaitBeforeExit(&result, annotations)
+	v.validateProxyConfigAnnotations(&result, annotations)
 
 	result.Finalize()
 	return result
 }
 
+// validateNamespacePodsInjected warns about pods of an injection-enabled namespace running
+// without the proxy. The injector only meshes pods created after the annotation was set, so
+// pods that predate it stay unmeshed until restarted. Pods opting out with their own inject
+// annotation, host network pods, which are never injected, and completed pods are skipped.
+func (v *ProxyValidator) validateNamespacePodsInjected(ctx context.Context, result *ValidationResult, namespace string) {
+	pods, err := kube.ListPods(ctx, v.clientset, namespace, metav1.ListOptions{})
+	if err != nil {
+		return
+	}
+
+	uninjected := []string{}
+	for _, pod := range pods.Items {
+		if pod.Annotations["linkerd.io/inject"] == "disabled" || pod.Spec.HostNetwork ||
+			pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		if !hasProxyContainer(&pod) {
+			uninjected = append(uninjected, pod.Name)
+		}
+	}
+	if len(uninjected) == 0 {
+		return
+	}
+
+	sort.Strings(uninjected)
+	result.AddIssue(SeverityWarning,
+		fmt.Sprintf("Namespace has injection enabled but %d pod(s) run without %s, likely created before the annotation: %s",
+			len(uninjected), kube.ProxyContainerName(), strings.Join(uninjected, ", ")),
+		"metadata.annotations[linkerd.io/inject]",
+		"LNKD-P021",
+		fmt.Sprintf("Restart the workloads so the injector meshes them, e.g. 'kubectl rollout restart deployment -n %s'", namespace))
+}
+
+// hasProxyContainer reports whether a pod runs the proxy, as a regular container or as a native
+// sidecar init container
+func hasProxyContainer(pod *corev1.Pod) bool {
+	for _, container := range pod.Spec.Containers {
+		if kube.IsProxyContainer(container.Name) {
+			return true
+		}
+	}
+	for _, container := range pod.Spec.InitContainers {
+		if kube.IsProxyContainer(container.Name) {
+			return true
+		}
+	}
+	return false
+}
+
 // ValidatePod validates proxy annotations on a pod
 func (v *ProxyValidator) ValidatePod(ctx context.Context, pod *corev1.Pod) ValidationResult {
 	result := ValidationResult{
@@ -75,13 +125,7 @@ func (v *ProxyValidator) ValidatePod(ctx context.Context, pod *corev1.Pod) Valid
 	}
 
 	// Check if pod has linkerd proxy
-	hasProxy := false
-	for _, container := range pod.Spec.Containers {
-		if container.Name == "linkerd-proxy" {
-			hasProxy = true
-			break
-		}
-	}
+	hasProxy := hasProxyContainer(pod)
 
 	// Validate injection annotation
 	v.validateInjectionAnnotation(&result, annotations)
@@ -89,29 +133,65 @@ func (v *ProxyValidator) ValidatePod(ctx context.Context, pod *corev1.Pod) Valid
 	// If pod should be injected but isn't, warn
 	if annotations["linkerd.io/inject"] == "enabled" && !hasProxy {
 		result.AddIssue(SeverityWarning,
-			"Pod is marked for injection but doesn't have linkerd-proxy container",
+			fmt.Sprintf("Pod is marked for injection but doesn't have %s container", kube.ProxyContainerName()),
 			"metadata.annotations[linkerd.io/inject]",
 			"LNKD-P001",
 			"Ensure the Linkerd proxy injector webhook is running")
 	}
 
+	v.validateProxyConfigAnnotations(&result, annotations)
+
+	result.Finalize()
+	return result
+}
+
+// ValidatePodTemplate validates proxy annotations on a deployment's pod template. The template
+// never carries the proxy container itself, so only the annotations are checked.
+func (v *ProxyValidator) ValidatePodTemplate(ctx context.Context, deployment *appsv1.Deployment) ValidationResult {
+	result := ValidationResult{
+		ResourceType: "Deployment",
+		Name:         deployment.Name,
+		Namespace:    deployment.Namespace,
+		Issues:       []Issue{},
+	}
+
+	annotations := deployment.Spec.Template.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+
+	v.validateInjectionAnnotation(&result, annotations)
+	v.validateProxyConfigAnnotations(&result, annotations)
+
+	result.Finalize()
+	return result
+}
+
+// validateProxyConfigAnnotations validates the config.linkerd.io annotations shared by
+// namespaces, pods and pod templates
+func (v *ProxyValidator) validateProxyConfigAnnotations(result *ValidationResult, annotations map[string]string) {
 	// Validate resource annotations
-	v.validateCPURequest(&result, annotations)
-	v.validateCPULimit(&result, annotations)
-	v.validateMemoryRequest(&result, annotations)
-	v.validateMemoryLimit(&result, annotations)
+	v.validateCPURequest(result, annotations)
+	v.validateCPULimit(result, annotations)
+	v.validateMemoryRequest(result, annotations)
+	v.validateMemoryLimit(result, annotations)
 
 	// Validate log level
-	v.validateLogLevel(&result, annotations)
+	v.validateLogLevel(result, annotations)
 
-	// Validate proxy version
-	v.validateProxyVersion(&result, annotations)
+	// Validate proxy version and image
+	v.validateProxyVersion(result, annotations)
+	v.validateProxyImage(result, annotations)
 
 	// Validate wait time
-	v.validateWaitBeforeExit(&result, annotations)
+	v.validateWaitBeforeExit(result, annotations)
 
-	result.Finalize()
-	return result
+	// Validate startup ordering
+	v.validateProxyAwait(result, annotations)
+
+	// Validate connect timeouts
+	v.validateConnectTimeout(result, annotations, "config.linkerd.io/proxy-inbound-connect-timeout")
+	v.validateConnectTimeout(result, annotations, "config.linkerd.io/proxy-outbound-connect-timeout")
 }
 
 func (v *ProxyValidator) validateInjectionAnnotation(result *ValidationResult, annotations map[string]string) {
@@ -275,6 +355,49 @@ func (v *ProxyValidator) validateProxyVersion(result *ValidationResult, annotati
 	}
 }
 
+// imageReference matches a container image reference: an optional registry host and port, a
+// slash-separated repository path, and an optional tag and digest
+var imageReference = regexp.MustCompile(`^` +
+	`((?:[a-zA-Z0-9](?:[a-zA-Z0-9-]*[a-zA-Z0-9])?(?:\.[a-zA-Z0-9](?:[a-zA-Z0-9-]*[a-zA-Z0-9])?)*(?::[0-9]+)?/)?` +
+	`[a-z0-9]+(?:(?:[._]|__|-+)[a-z0-9]+)*(?:/[a-z0-9]+(?:(?:[._]|__|-+)[a-z0-9]+)*)*)` +
+	`(?::([a-zA-Z0-9_][a-zA-Z0-9_.-]{0,127}))?` +
+	`(?:@([a-zA-Z][a-zA-Z0-9]*(?:[-_+.][a-zA-Z][a-zA-Z0-9]*)*:[0-9a-fA-F]{32,}))?$`)
+
+// parseImageReference splits a container image reference into its name (registry and
+// repository), tag and digest. ok is false when the reference is malformed.
+func parseImageReference(image string) (name, tag, digest string, ok bool) {
+	match := imageReference.FindStringSubmatch(image)
+	if match == nil {
+		return "", "", "", false
+	}
+	return match[1], match[2], match[3], true
+}
+
+func (v *ProxyValidator) validateProxyImage(result *ValidationResult, annotations map[string]string) {
+	image, exists := annotations["config.linkerd.io/proxy-image"]
+	if !exists {
+		return
+	}
+
+	_, tag, _, ok := parseImageReference(image)
+	if !ok {
+		result.AddIssue(SeverityError,
+			fmt.Sprintf("Invalid proxy image reference: %s", image),
+			"metadata.annotations[config.linkerd.io/proxy-image]",
+			"LNKD-P019",
+			"Use format: registry/repository, optionally with :tag or @digest (e.g. cr.l5d.io/linkerd/proxy)")
+		return
+	}
+
+	if version, exists := annotations["config.linkerd.io/proxy-version"]; exists && tag != "" && tag != version {
+		result.AddIssue(SeverityWarning,
+			fmt.Sprintf("Proxy image tag '%s' doesn't match proxy version '%s'", tag, version),
+			"metadata.annotations[config.linkerd.io/proxy-image]",
+			"LNKD-P020",
+			"Drop the tag from the proxy image, so the proxy version is used, or make them match")
+	}
+}
+
 func (v *ProxyValidator) validateWaitBeforeExit(result *ValidationResult, annotations map[string]string) {
 	if wait, exists := annotations["config.alpha.linkerd.io/proxy-wait-before-exit-seconds"]; exists {
 		seconds, err := strconv.Atoi(wait)
@@ -296,17 +419,222 @@ func (v *ProxyValidator) validateWaitBeforeExit(result *ValidationResult, annota
 	}
 }
 
-// ValidateAllNamespaces validates proxy configuration for all namespaces
+// proxyAwaitAnnotation controls whether the application containers wait for the proxy to be ready
+// before starting
+const proxyAwaitAnnotation = "config.linkerd.io/proxy-await"
+
+func (v *ProxyValidator) validateProxyAwait(result *ValidationResult, annotations map[string]string) {
+	value, exists := annotations[proxyAwaitAnnotation]
+	if !exists {
+		return
+	}
+
+	field := fmt.Sprintf("metadata.annotations[%s]", proxyAwaitAnnotation)
+	switch value {
+	case "enabled":
+	case "disabled":
+		result.AddIssue(SeverityInfo,
+			"Proxy await is disabled: application containers may start before the proxy is ready, failing connections made at startup",
+			field,
+			"LNKD-P029",
+			"Use 'enabled' for workloads that make outbound calls at startup")
+	default:
+		result.AddIssue(SeverityError,
+			fmt.Sprintf("Invalid proxy-await value: %s", value),
+			field,
+			"LNKD-P028",
+			"Must be 'enabled' or 'disabled'")
+	}
+}
+
+// maxConnectTimeout is the connect timeout above which a proxy is likely to hold on to
+// unreachable endpoints for too long
+const maxConnectTimeout = 30 * time.Second
+
+func (v *ProxyValidator) validateConnectTimeout(result *ValidationResult, annotations map[string]string, annotation string) {
+	value, exists := annotations[annotation]
+	if !exists {
+		return
+	}
+
+	field := fmt.Sprintf("metadata.annotations[%s]", annotation)
+	timeout, err := time.ParseDuration(value)
+	if err != nil || timeout <= 0 {
+		result.AddIssue(SeverityError,
+			fmt.Sprintf("Invalid connect timeout value: %s", value),
+			field,
+			"LNKD-P017",
+			"Must be a positive duration, e.g. 100ms or 1s")
+		return
+	}
+
+	if timeout > maxConnectTimeout {
+		result.AddIssue(SeverityWarning,
+			fmt.Sprintf("Very long connect timeout (%s) delays failover away from unreachable endpoints", value),
+			field,
+			"LNKD-P018",
+			"Consider a shorter connect timeout (typically 100ms-10s)")
+	}
+}
+
+// failureAccrualAnnotation selects the circuit breaking mode of the proxies balancing requests
+// to a Service; the consecutive mode is configured by the annotations prefixed with its name
+const failureAccrualAnnotation = "balancer.linkerd.io/failure-accrual"
+
+// failureAccrualModes are the known failure accrual modes
+var failureAccrualModes = []string{"consecutive"}
+
+// ValidateService validates the failure accrual (circuit breaking) annotations of a Service, which
+// configure the proxies of its clients
+func (v *ProxyValidator) ValidateService(svc *corev1.Service) ValidationResult {
+	result := ValidationResult{
+		ResourceType: "Service",
+		Name:         svc.Name,
+		Namespace:    svc.Namespace,
+		Issues:       []Issue{},
+	}
+
+	annotations := svc.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+
+	v.validateFailureAccrual(&result, annotations)
+
+	result.Finalize()
+	return result
+}
+
+// hasFailureAccrualAnnotations reports whether any failure accrual annotation is set
+func hasFailureAccrualAnnotations(annotations map[string]string) bool {
+	for key := range annotations {
+		if strings.HasPrefix(key, failureAccrualAnnotation) {
+			return true
+		}
+	}
+	return false
+}
+
+func (v *ProxyValidator) validateFailureAccrual(result *ValidationResult, annotations map[string]string) {
+	mode, exists := annotations[failureAccrualAnnotation]
+	if exists {
+		known := false
+		for _, m := range failureAccrualModes {
+			if mode == m {
+				known = true
+			}
+		}
+		if !known {
+			result.AddIssue(SeverityError,
+				fmt.Sprintf("Invalid failure accrual mode: %s", mode),
+				fmt.Sprintf("metadata.annotations[%s]", failureAccrualAnnotation),
+				"LNKD-P022",
+				fmt.Sprintf("Must be one of: %s", strings.Join(failureAccrualModes, ", ")))
+		}
+	}
+
+	consecutive := failureAccrualAnnotation + "-consecutive-"
+	if mode != "consecutive" {
+		ignored := []string{}
+		for key := range annotations {
+			if strings.HasPrefix(key, consecutive) {
+				ignored = append(ignored, key)
+			}
+		}
+		sort.Strings(ignored)
+		for _, key := range ignored {
+			result.AddIssue(SeverityWarning,
+				fmt.Sprintf("Annotation %s is ignored unless failure accrual is 'consecutive'", key),
+				fmt.Sprintf("metadata.annotations[%s]", key),
+				"LNKD-P027",
+				fmt.Sprintf("Set %s: consecutive to enable circuit breaking, or remove the annotation", failureAccrualAnnotation))
+		}
+	}
+
+	if value, exists := annotations[consecutive+"max-failures"]; exists {
+		if failures, err := strconv.Atoi(value); err != nil || failures < 1 {
+			result.AddIssue(SeverityError,
+				fmt.Sprintf("Invalid consecutive max failures value: %s", value),
+				fmt.Sprintf("metadata.annotations[%smax-failures]", consecutive),
+				"LNKD-P023",
+				"Must be a positive integer, e.g. 7")
+		}
+	}
+
+	minPenalty := v.parsePenalty(result, annotations, consecutive+"min-penalty")
+	maxPenalty := v.parsePenalty(result, annotations, consecutive+"max-penalty")
+	if minPenalty > 0 && maxPenalty > 0 && minPenalty > maxPenalty {
+		result.AddIssue(SeverityError,
+			fmt.Sprintf("Failure accrual min penalty (%s) exceeds max penalty (%s)", minPenalty, maxPenalty),
+			fmt.Sprintf("metadata.annotations[%smin-penalty]", consecutive),
+			"LNKD-P026",
+			"The min penalty must be less than or equal to the max penalty")
+	}
+
+	if value, exists := annotations[consecutive+"jitter-ratio"]; exists {
+		if ratio, err := strconv.ParseFloat(value, 64); err != nil || ratio < 0 || ratio > 100 {
+			result.AddIssue(SeverityError,
+				fmt.Sprintf("Invalid failure accrual jitter ratio: %s", value),
+				fmt.Sprintf("metadata.annotations[%sjitter-ratio]", consecutive),
+				"LNKD-P025",
+				"Must be a number between 0 and 100, e.g. 0.5")
+		}
+	}
+}
+
+// parsePenalty parses a failure accrual backoff penalty, recording an issue when it isn't a
+// positive duration. It returns 0 when the annotation is unset or invalid.
+func (v *ProxyValidator) parsePenalty(result *ValidationResult, annotations map[string]string, annotation string) time.Duration {
+	value, exists := annotations[annotation]
+	if !exists {
+		return 0
+	}
+
+	penalty, err := time.ParseDuration(value)
+	if err != nil || penalty <= 0 {
+		result.AddIssue(SeverityError,
+			fmt.Sprintf("Invalid failure accrual penalty: %s", value),
+			fmt.Sprintf("metadata.annotations[%s]", annotation),
+			"LNKD-P024",
+			"Must be a positive duration, e.g. 1s or 1m")
+		return 0
+	}
+	return penalty
+}
+
+// ValidateAllServices validates the failure accrual annotations of the Services in a namespace
+// ("" for all namespaces). Services without failure accrual annotations are skipped.
+func (v *ProxyValidator) ValidateAllServices(ctx context.Context, namespace string) []ValidationResult {
+	var results []ValidationResult
+
+	services, err := v.clientset.CoreV1().Services(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return results
+	}
+
+	for i := range services.Items {
+		if !hasFailureAccrualAnnotations(services.Items[i].Annotations) {
+			continue
+		}
+		results = append(results, v.ValidateService(&services.Items[i]))
+	}
+
+	return results
+}
+
+// ValidateAllNamespaces validates proxy configuration for all namespaces the namespace filter
+// covers
 func (v *ProxyValidator) ValidateAllNamespaces(ctx context.Context) []ValidationResult {
 	var results []ValidationResult
 
-	namespaces, err := v.clientset.CoreV1().Namespaces().List(ctx, metav1.ListOptions{})
+	list, err := v.clientset.CoreV1().Namespaces().List(ctx, metav1.ListOptions{})
 	if err != nil {
 		return results
 	}
 
-	for i := range namespaces.Items {
-		result := v.ValidateNamespace(ctx, &namespaces.Items[i])
+	namespaces := v.namespaceFilter.Namespaces(list.Items)
+	for i := range namespaces {
+		result := v.ValidateNamespace(ctx, &namespaces[i])
 		results = append(results, result)
 	}
 
@@ -317,15 +645,7 @@ func (v *ProxyValidator) ValidateAllNamespaces(ctx context.Context) []Validation
 func (v *ProxyValidator) ValidateAllPodsInNamespace(ctx context.Context, namespace string) []ValidationResult {
 	var results []ValidationResult
 
-	var pods *corev1.PodList
-	var err error
-
-	if namespace == "" {
-		pods, err = v.clientset.CoreV1().Pods("").List(ctx, metav1.ListOptions{})
-	} else {
-		pods, err = v.clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{})
-	}
-
+	pods, err := kube.ListPods(ctx, v.clientset, namespace, metav1.ListOptions{})
 	if err != nil {
 		return results
 	}
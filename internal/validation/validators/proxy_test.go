@@ -6,6 +6,7 @@ import (
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 
+	"github.com/christianhuening/linkerd-mcp/internal/testutil"
 	"github.com/christianhuening/linkerd-mcp/internal/validation/validators"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -45,6 +46,75 @@ var _ = Describe("ProxyValidator", func() {
 			})
 		})
 
+		Context("with injection enabled after pods were created", func() {
+			var ns *corev1.Namespace
+
+			// countIssues counts the issues of a result with a code
+			countIssues := func(result validators.ValidationResult, code string) int {
+				count := 0
+				for _, issue := range result.Issues {
+					if issue.Code == code {
+						count++
+					}
+				}
+				return count
+			}
+
+			BeforeEach(func() {
+				ns = &corev1.Namespace{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:        "prod",
+						Annotations: map[string]string{"linkerd.io/inject": "enabled"},
+					},
+				}
+			})
+
+			It("should warn about pods running without the proxy", func() {
+				optedOut := testutil.CreatePod("batch-1", "prod", "batch", nil, corev1.PodRunning, true)
+				optedOut.Annotations = map[string]string{"linkerd.io/inject": "disabled"}
+				completed := testutil.CreatePod("migrate-1", "prod", "migrate", nil, corev1.PodSucceeded, false)
+				nativeSidecar := testutil.CreatePod("worker-1", "prod", "worker", nil, corev1.PodRunning, true)
+				nativeSidecar.Spec.InitContainers = []corev1.Container{{Name: "linkerd-proxy"}}
+
+				validator = validators.NewProxyValidator(kubefake.NewSimpleClientset(
+					testutil.CreatePod("web-2", "prod", "web", nil, corev1.PodRunning, true),
+					testutil.CreatePod("api-1", "prod", "api", nil, corev1.PodRunning, true),
+					testutil.CreateMeshedPod("web-1", "prod", "web"),
+					testutil.CreatePod("other-1", "staging", "other", nil, corev1.PodRunning, true),
+					optedOut, completed, nativeSidecar,
+				))
+
+				result := validator.ValidateNamespace(ctx, ns)
+
+				Expect(result.Valid).To(BeTrue())
+				Expect(countIssues(result, "LNKD-P021")).To(Equal(1))
+				for _, issue := range result.Issues {
+					if issue.Code == "LNKD-P021" {
+						Expect(issue.Severity).To(Equal(validators.SeverityWarning))
+						Expect(issue.Message).To(ContainSubstring("2 pod(s)"))
+						Expect(issue.Message).To(HaveSuffix("api-1, web-2"))
+					}
+				}
+			})
+
+			It("should not warn when every pod is injected", func() {
+				validator = validators.NewProxyValidator(kubefake.NewSimpleClientset(
+					testutil.CreateMeshedPod("web-1", "prod", "web"),
+				))
+
+				Expect(countIssues(validator.ValidateNamespace(ctx, ns), "LNKD-P021")).To(BeZero())
+			})
+
+			It("should not check pods of namespaces without injection", func() {
+				validator = validators.NewProxyValidator(kubefake.NewSimpleClientset(
+					testutil.CreatePod("api-1", "prod", "api", nil, corev1.PodRunning, true),
+				))
+				ns.Annotations["linkerd.io/inject"] = "disabled"
+
+				Expect(countIssues(validator.ValidateNamespace(ctx, ns), "LNKD-P021")).To(BeZero())
+			})
+		})
+
 		Context("with no injection annotation", func() {
 			It("should return info message", func() {
 				ns := &corev1.Namespace{
@@ -341,6 +411,138 @@ var _ = Describe("ProxyValidator", func() {
 				Expect(foundWarning).To(BeTrue())
 			})
 		})
+
+		Context("with connect timeout annotations", func() {
+			// connectTimeoutCodes validates a namespace with the given connect timeouts and
+			// returns the issue codes
+			connectTimeoutCodes := func(inbound, outbound string) []string {
+				ns := &corev1.Namespace{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "test",
+						Annotations: map[string]string{
+							"linkerd.io/inject": "enabled",
+							"config.linkerd.io/proxy-inbound-connect-timeout":  inbound,
+							"config.linkerd.io/proxy-outbound-connect-timeout": outbound,
+						},
+					},
+				}
+
+				var codes []string
+				for _, issue := range validator.ValidateNamespace(ctx, ns).Issues {
+					codes = append(codes, issue.Code)
+				}
+				return codes
+			}
+
+			It("should accept valid durations", func() {
+				codes := connectTimeoutCodes("100ms", "1s")
+				Expect(codes).NotTo(ContainElement("LNKD-P017"))
+				Expect(codes).NotTo(ContainElement("LNKD-P018"))
+			})
+
+			It("should return errors for unparseable and non-positive durations", func() {
+				Expect(connectTimeoutCodes("100", "1s")).To(ContainElement("LNKD-P017"))
+				Expect(connectTimeoutCodes("1s", "0s")).To(ContainElement("LNKD-P017"))
+				Expect(connectTimeoutCodes("-5s", "1s")).To(ContainElement("LNKD-P017"))
+			})
+
+			It("should warn about very long timeouts", func() {
+				codes := connectTimeoutCodes("1s", "1m")
+				Expect(codes).To(ContainElement("LNKD-P018"))
+				Expect(codes).NotTo(ContainElement("LNKD-P017"))
+			})
+		})
+
+		Context("with a proxy-await annotation", func() {
+			// proxyAwaitCodes validates a pod with the given proxy-await value and returns the
+			// issue codes
+			proxyAwaitCodes := func(await string) []string {
+				pod := &corev1.Pod{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-pod",
+						Namespace: "test",
+						Annotations: map[string]string{
+							"linkerd.io/inject":             "enabled",
+							"config.linkerd.io/proxy-await": await,
+						},
+					},
+				}
+
+				var codes []string
+				for _, issue := range validator.ValidatePod(ctx, pod).Issues {
+					codes = append(codes, issue.Code)
+				}
+				return codes
+			}
+
+			It("should accept enabled", func() {
+				codes := proxyAwaitCodes("enabled")
+				Expect(codes).NotTo(ContainElement("LNKD-P028"))
+				Expect(codes).NotTo(ContainElement("LNKD-P029"))
+			})
+
+			It("should recommend enabled when disabled", func() {
+				codes := proxyAwaitCodes("disabled")
+				Expect(codes).To(ContainElement("LNKD-P029"))
+				Expect(codes).NotTo(ContainElement("LNKD-P028"))
+			})
+
+			It("should return an error for other values", func() {
+				Expect(proxyAwaitCodes("true")).To(ContainElement("LNKD-P028"))
+				Expect(proxyAwaitCodes("")).To(ContainElement("LNKD-P028"))
+			})
+		})
+
+		Context("with a proxy image annotation", func() {
+			// proxyImageCodes validates a namespace with the given proxy image, and proxy version
+			// when not empty, and returns the issue codes
+			proxyImageCodes := func(image, version string) []string {
+				annotations := map[string]string{
+					"linkerd.io/inject":             "enabled",
+					"config.linkerd.io/proxy-image": image,
+				}
+				if version != "" {
+					annotations["config.linkerd.io/proxy-version"] = version
+				}
+				ns := &corev1.Namespace{
+					ObjectMeta: metav1.ObjectMeta{Name: "test", Annotations: annotations},
+				}
+
+				var codes []string
+				for _, issue := range validator.ValidateNamespace(ctx, ns).Issues {
+					codes = append(codes, issue.Code)
+				}
+				return codes
+			}
+
+			It("should accept valid image references", func() {
+				for _, image := range []string{
+					"cr.l5d.io/linkerd/proxy",
+					"registry.example.com:5000/mirror/linkerd-proxy:stable-2.14.0",
+					"proxy@sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef",
+				} {
+					Expect(proxyImageCodes(image, "")).NotTo(ContainElement("LNKD-P019"), image)
+				}
+			})
+
+			It("should return errors for malformed image references", func() {
+				for _, image := range []string{
+					"",
+					"cr.l5d.io/Linkerd/proxy",
+					"cr.l5d.io//proxy",
+					"cr.l5d.io/linkerd/proxy:",
+					"cr.l5d.io/linkerd/proxy@sha256:abc",
+				} {
+					Expect(proxyImageCodes(image, "")).To(ContainElement("LNKD-P019"), image)
+				}
+			})
+
+			It("should warn when the image tag doesn't match the proxy version", func() {
+				Expect(proxyImageCodes("cr.l5d.io/linkerd/proxy:stable-2.13.0", "stable-2.14.0")).To(ContainElement("LNKD-P020"))
+				Expect(proxyImageCodes("cr.l5d.io/linkerd/proxy:stable-2.14.0", "stable-2.14.0")).NotTo(ContainElement("LNKD-P020"))
+				Expect(proxyImageCodes("cr.l5d.io/linkerd/proxy", "stable-2.14.0")).NotTo(ContainElement("LNKD-P020"))
+			})
+		})
 	})
 
 	Describe("ValidatePod", func() {
@@ -399,6 +601,87 @@ var _ = Describe("ProxyValidator", func() {
 		})
 	})
 
+	Describe("ValidateService", func() {
+		// failureAccrualCodes validates a Service with the given annotations and returns its issue codes
+		failureAccrualCodes := func(annotations map[string]string) []string {
+			svc := &corev1.Service{
+				ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "prod", Annotations: annotations},
+			}
+			result := validator.ValidateService(svc)
+			Expect(result.ResourceType).To(Equal("Service"))
+
+			codes := []string{}
+			for _, issue := range result.Issues {
+				codes = append(codes, issue.Code)
+			}
+			return codes
+		}
+
+		It("should accept a consistent consecutive failure accrual configuration", func() {
+			codes := failureAccrualCodes(map[string]string{
+				"balancer.linkerd.io/failure-accrual":                          "consecutive",
+				"balancer.linkerd.io/failure-accrual-consecutive-max-failures": "7",
+				"balancer.linkerd.io/failure-accrual-consecutive-min-penalty":  "1s",
+				"balancer.linkerd.io/failure-accrual-consecutive-max-penalty":  "1m",
+				"balancer.linkerd.io/failure-accrual-consecutive-jitter-ratio": "0.5",
+			})
+			Expect(codes).To(BeEmpty())
+		})
+
+		It("should reject unknown failure accrual modes", func() {
+			codes := failureAccrualCodes(map[string]string{
+				"balancer.linkerd.io/failure-accrual": "consectuive",
+			})
+			Expect(codes).To(ConsistOf("LNKD-P022"))
+		})
+
+		It("should reject settings that don't parse", func() {
+			codes := failureAccrualCodes(map[string]string{
+				"balancer.linkerd.io/failure-accrual":                          "consecutive",
+				"balancer.linkerd.io/failure-accrual-consecutive-max-failures": "0",
+				"balancer.linkerd.io/failure-accrual-consecutive-min-penalty":  "1",
+				"balancer.linkerd.io/failure-accrual-consecutive-jitter-ratio": "150",
+			})
+			Expect(codes).To(ConsistOf("LNKD-P023", "LNKD-P024", "LNKD-P025"))
+		})
+
+		It("should reject a min penalty above the max penalty", func() {
+			codes := failureAccrualCodes(map[string]string{
+				"balancer.linkerd.io/failure-accrual":                         "consecutive",
+				"balancer.linkerd.io/failure-accrual-consecutive-min-penalty": "2m",
+				"balancer.linkerd.io/failure-accrual-consecutive-max-penalty": "30s",
+			})
+			Expect(codes).To(ConsistOf("LNKD-P026"))
+		})
+
+		It("should warn about consecutive settings without consecutive failure accrual", func() {
+			codes := failureAccrualCodes(map[string]string{
+				"balancer.linkerd.io/failure-accrual-consecutive-max-failures": "7",
+			})
+			Expect(codes).To(ConsistOf("LNKD-P027"))
+		})
+	})
+
+	Describe("ValidateAllServices", func() {
+		It("should only validate services with failure accrual annotations", func() {
+			plain := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "prod"}}
+			annotated := &corev1.Service{ObjectMeta: metav1.ObjectMeta{
+				Name:        "api",
+				Namespace:   "prod",
+				Annotations: map[string]string{"balancer.linkerd.io/failure-accrual": "consecutive"},
+			}}
+			_, err := kubeClient.CoreV1().Services("prod").Create(ctx, plain, metav1.CreateOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			_, err = kubeClient.CoreV1().Services("prod").Create(ctx, annotated, metav1.CreateOptions{})
+			Expect(err).NotTo(HaveOccurred())
+
+			results := validator.ValidateAllServices(ctx, "")
+			Expect(results).To(HaveLen(1))
+			Expect(results[0].Name).To(Equal("api"))
+			Expect(results[0].Valid).To(BeTrue())
+		})
+	})
+
 	Describe("ValidateAllNamespaces", func() {
 		It("should validate all namespaces", func() {
 			ns1 := &corev1.Namespace{
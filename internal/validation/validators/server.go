@@ -4,8 +4,12 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/christianhuening/linkerd-mcp/internal/kube"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
@@ -79,7 +83,7 @@ func (v *ServerValidator) validatePodSelector(ctx context.Context, result *Valid
 
 	// Check if any pods match the selector
 	labelSelector := metav1.FormatLabelSelector(&metav1.LabelSelector{MatchLabels: matchLabels})
-	pods, err := v.clientset.CoreV1().Pods(result.Namespace).List(ctx, metav1.ListOptions{
+	pods, err := kube.ListPods(ctx, v.clientset, result.Namespace, metav1.ListOptions{
 		LabelSelector: labelSelector,
 	})
 	if err == nil && len(pods.Items) == 0 {
@@ -128,6 +132,10 @@ func (v *ServerValidator) validateProxyProtocol(ctx context.Context, result *Val
 	}
 }
 
+// checkConflicts reports other Servers in the namespace that govern the same port of the same
+// pods. Selectors are compared against the pods of the namespace: two Servers conflict when a
+// pod matches both. When neither selects any pod, e.g. before the workload is deployed, they
+// conflict when a pod could carry labels satisfying both.
 func (v *ServerValidator) checkConflicts(ctx context.Context, result *ValidationResult, server *unstructured.Unstructured, spec map[string]interface{}) {
 	// Get all servers in the namespace
 	servers, err := v.dynamicClient.Resource(serverGVR).Namespace(result.Namespace).List(ctx, metav1.ListOptions{})
@@ -136,9 +144,15 @@ func (v *ServerValidator) checkConflicts(ctx context.Context, result *Validation
 		return
 	}
 
-	currentPort, _, _ := unstructured.NestedInt64(spec, "port")
-	currentPodSelector, _, _ := unstructured.NestedMap(spec, "podSelector")
-	currentMatchLabels, _, _ := unstructured.NestedStringMap(currentPodSelector, "matchLabels")
+	currentSelector, err := serverPodSelector(spec)
+	if err != nil {
+		return
+	}
+
+	var pods []corev1.Pod
+	if podList, err := kube.ListPods(ctx, v.clientset, result.Namespace, metav1.ListOptions{}); err == nil {
+		pods = podList.Items
+	}
 
 	for _, otherServer := range servers.Items {
 		// Skip self
@@ -147,37 +161,85 @@ func (v *ServerValidator) checkConflicts(ctx context.Context, result *Validation
 		}
 
 		otherSpec, _, _ := unstructured.NestedMap(otherServer.Object, "spec")
-		otherPort, _, _ := unstructured.NestedInt64(otherSpec, "port")
-		otherPodSelector, _, _ := unstructured.NestedMap(otherSpec, "podSelector")
-		otherMatchLabels, _, _ := unstructured.NestedStringMap(otherPodSelector, "matchLabels")
-
-		// Check if port and podSelector match
-		if currentPort == otherPort && labelsOverlap(currentMatchLabels, otherMatchLabels) {
-			result.AddIssue(SeverityError,
-				fmt.Sprintf("Conflicts with Server '%s' on port %d", otherServer.GetName(), currentPort),
-				"spec",
-				"LNKD-008",
-				fmt.Sprintf("Change port or podSelector to avoid conflict with '%s'", otherServer.GetName()))
+		if fmt.Sprint(spec["port"]) != fmt.Sprint(otherSpec["port"]) {
+			continue
+		}
+		otherSelector, err := serverPodSelector(otherSpec)
+		if err != nil {
+			continue
+		}
+
+		overlap, pod := selectorsOverlap(currentSelector, otherSelector, pods)
+		if !overlap {
+			continue
+		}
+
+		message := fmt.Sprintf("Conflicts with Server '%s' on port %v", otherServer.GetName(), spec["port"])
+		if pod != "" {
+			message += fmt.Sprintf(": both select pod '%s'", pod)
 		}
+		result.AddIssue(SeverityError,
+			message,
+			"spec",
+			"LNKD-008",
+			fmt.Sprintf("Change port or podSelector to avoid conflict with '%s'", otherServer.GetName()))
 	}
 }
 
-// labelsOverlap checks if two label sets could select the same pods
-func labelsOverlap(labels1, labels2 map[string]string) bool {
-	if len(labels1) == 0 || len(labels2) == 0 {
-		return true // Empty selector matches everything
+// serverPodSelector parses the podSelector of a Server spec. A missing or empty podSelector
+// selects every pod.
+func serverPodSelector(spec map[string]interface{}) (*metav1.LabelSelector, error) {
+	podSelector, _, _ := unstructured.NestedMap(spec, "podSelector")
+
+	var selector metav1.LabelSelector
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(podSelector, &selector); err != nil {
+		return nil, err
 	}
+	return &selector, nil
+}
 
-	// Simple check: if all labels in labels1 match those in labels2, they overlap
-	for k, v := range labels1 {
-		if v2, exists := labels2[k]; exists && v == v2 {
-			return true
+// selectorsOverlap reports whether two pod selectors select a common pod, and its name. Without
+// pods matched by either selector, they overlap when the pod carrying the labels both
+// matchLabels require satisfies both selectors.
+func selectorsOverlap(selector1, selector2 *metav1.LabelSelector, pods []corev1.Pod) (bool, string) {
+	first, err := metav1.LabelSelectorAsSelector(selector1)
+	if err != nil {
+		return false, ""
+	}
+	second, err := metav1.LabelSelectorAsSelector(selector2)
+	if err != nil {
+		return false, ""
+	}
+
+	selectsAny := false
+	for _, pod := range pods {
+		podLabels := labels.Set(pod.Labels)
+		matchesFirst, matchesSecond := first.Matches(podLabels), second.Matches(podLabels)
+		if matchesFirst && matchesSecond {
+			return true, pod.Name
+		}
+		selectsAny = selectsAny || matchesFirst || matchesSecond
+	}
+	if selectsAny {
+		return false, ""
+	}
+
+	required := labels.Set{}
+	for key, value := range selector1.MatchLabels {
+		required[key] = value
+	}
+	for key, value := range selector2.MatchLabels {
+		if existing, ok := required[key]; ok && existing != value {
+			return false, ""
 		}
+		required[key] = value
 	}
-	return false
+	return first.Matches(required) && second.Matches(required), ""
 }
 
-// ValidateAll validates all Server resources in a namespace
+// ValidateAll validates all Server resources in a namespace. When they
+// can't be listed, because the CRD isn't installed or listing is forbidden, the only result is
+// a diagnostic one
 func (v *ServerValidator) ValidateAll(ctx context.Context, namespace string) []ValidationResult {
 	var results []ValidationResult
 
@@ -192,7 +254,7 @@ func (v *ServerValidator) ValidateAll(ctx context.Context, namespace string) []V
 	}
 
 	if err != nil {
-		return results
+		return append(results, listFailureResult(serverGVR, "Server", namespace, err))
 	}
 
 	for i := range servers.Items {
@@ -8,11 +8,15 @@ import (
 
 	"github.com/christianhuening/linkerd-mcp/internal/testutil"
 	"github.com/christianhuening/linkerd-mcp/internal/validation/validators"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/dynamic/fake"
 	kubefake "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
 )
 
 var _ = Describe("ServerValidator", func() {
@@ -155,6 +159,97 @@ var _ = Describe("ServerValidator", func() {
 				Expect(foundConflict).To(BeTrue(), "should detect conflict")
 			})
 		})
+
+		Context("with Servers whose selectors overlap in part", func() {
+			serverGVR := schema.GroupVersionResource{Group: "policy.linkerd.io", Version: "v1beta3", Resource: "servers"}
+
+			// conflicts validates server against the existing Server and pods and returns the
+			// conflict messages
+			conflicts := func(server, existing *unstructured.Unstructured, pods ...*corev1.Pod) []string {
+				_, err := dynamicClient.Resource(serverGVR).Namespace("prod").Create(ctx, existing, metav1.CreateOptions{})
+				Expect(err).NotTo(HaveOccurred())
+				for _, pod := range pods {
+					_, err := kubeClient.CoreV1().Pods("prod").Create(ctx, pod, metav1.CreateOptions{})
+					Expect(err).NotTo(HaveOccurred())
+				}
+
+				messages := []string{}
+				for _, issue := range validator.Validate(ctx, server).Issues {
+					if issue.Code == "LNKD-008" {
+						messages = append(messages, issue.Message)
+					}
+				}
+				return messages
+			}
+
+			It("should not flag selectors sharing a label but requiring different values of another", func() {
+				messages := conflicts(
+					testutil.CreateServer("api-web", "prod", map[string]string{"app": "api", "tier": "web"}, 8080),
+					testutil.CreateServer("api-db", "prod", map[string]string{"app": "api", "tier": "db"}, 8080),
+					testutil.CreatePod("api-web-1", "prod", "default", map[string]string{"app": "api", "tier": "web"}, "Running", true),
+					testutil.CreatePod("api-db-1", "prod", "default", map[string]string{"app": "api", "tier": "db"}, "Running", true),
+				)
+				Expect(messages).To(BeEmpty())
+			})
+
+			It("should not flag such selectors before any pod exists", func() {
+				messages := conflicts(
+					testutil.CreateServer("api-web", "prod", map[string]string{"app": "api", "tier": "web"}, 8080),
+					testutil.CreateServer("api-db", "prod", map[string]string{"app": "api", "tier": "db"}, 8080),
+				)
+				Expect(messages).To(BeEmpty())
+			})
+
+			It("should flag selectors on different labels that select a common pod", func() {
+				messages := conflicts(
+					testutil.CreateServer("by-app", "prod", map[string]string{"app": "api"}, 8080),
+					testutil.CreateServer("by-tier", "prod", map[string]string{"tier": "backend"}, 8080),
+					testutil.CreatePod("api-1", "prod", "default", map[string]string{"app": "api", "tier": "backend"}, "Running", true),
+				)
+				Expect(messages).To(ConsistOf("Conflicts with Server 'by-tier' on port 8080: both select pod 'api-1'"))
+			})
+
+			It("should flag an empty selector only when the other Server selects a pod", func() {
+				messages := conflicts(
+					testutil.CreateServer("everything", "prod", map[string]string{}, 8080),
+					testutil.CreateServer("backend", "prod", map[string]string{"app": "backend"}, 8080),
+					testutil.CreatePod("frontend-1", "prod", "default", map[string]string{"app": "frontend"}, "Running", true),
+				)
+				Expect(messages).To(BeEmpty())
+
+				_, err := kubeClient.CoreV1().Pods("prod").Create(ctx,
+					testutil.CreatePod("backend-1", "prod", "default", map[string]string{"app": "backend"}, "Running", true), metav1.CreateOptions{})
+				Expect(err).NotTo(HaveOccurred())
+
+				result := validator.Validate(ctx, testutil.CreateServer("everything", "prod", map[string]string{}, 8080))
+				Expect(result.Issues).To(ContainElement(HaveField("Code", "LNKD-008")))
+			})
+
+			It("should compare matchExpressions against the pods", func() {
+				server := testutil.CreateServer("api-any", "prod", map[string]string{}, 8080)
+				Expect(unstructured.SetNestedField(server.Object, map[string]interface{}{
+					"matchExpressions": []interface{}{
+						map[string]interface{}{"key": "app", "operator": "In", "values": []interface{}{"api", "web"}},
+					},
+				}, "spec", "podSelector")).To(Succeed())
+
+				messages := conflicts(
+					server,
+					testutil.CreateServer("web", "prod", map[string]string{"app": "web"}, 8080),
+					testutil.CreatePod("web-1", "prod", "default", map[string]string{"app": "web"}, "Running", true),
+				)
+				Expect(messages).To(HaveLen(1))
+			})
+
+			It("should not flag Servers on different ports", func() {
+				messages := conflicts(
+					testutil.CreateServer("api-http", "prod", map[string]string{"app": "api"}, 8080),
+					testutil.CreateServer("api-admin", "prod", map[string]string{"app": "api"}, 9990),
+					testutil.CreatePod("api-1", "prod", "default", map[string]string{"app": "api"}, "Running", true),
+				)
+				Expect(messages).To(BeEmpty())
+			})
+		})
 	})
 
 	Describe("ValidateAll", func() {
@@ -182,5 +277,38 @@ var _ = Describe("ServerValidator", func() {
 			Expect(results[0].ResourceType).To(Equal("Server"))
 			Expect(results[1].ResourceType).To(Equal("Server"))
 		})
+
+		It("should return no results when there are no servers", func() {
+			Expect(validator.ValidateAll(ctx, "prod")).To(BeEmpty())
+		})
+
+		It("should report a missing CRD as a diagnostic error", func() {
+			dynamicClient.PrependReactor("list", "servers", func(action k8stesting.Action) (bool, runtime.Object, error) {
+				return true, nil, apierrors.NewNotFound(schema.GroupResource{Group: "policy.linkerd.io", Resource: "servers"}, "")
+			})
+
+			results := validator.ValidateAll(ctx, "prod")
+
+			Expect(results).To(HaveLen(1))
+			Expect(results[0].Diagnostic).To(BeTrue())
+			Expect(results[0].Valid).To(BeFalse())
+			Expect(results[0].Name).To(Equal("servers.policy.linkerd.io"))
+			Expect(results[0].Namespace).To(Equal("prod"))
+			Expect(results[0].Issues).To(ConsistOf(HaveField("Code", "LNKD-037")))
+		})
+
+		It("should report a forbidden list as a diagnostic error", func() {
+			dynamicClient.PrependReactor("list", "servers", func(action k8stesting.Action) (bool, runtime.Object, error) {
+				return true, nil, apierrors.NewForbidden(schema.GroupResource{Group: "policy.linkerd.io", Resource: "servers"}, "", nil)
+			})
+
+			results := validator.ValidateAll(ctx, "")
+
+			Expect(results).To(HaveLen(1))
+			Expect(results[0].Issues).To(ConsistOf(And(
+				HaveField("Severity", validators.SeverityError),
+				HaveField("Code", "LNKD-038"),
+			)))
+		})
 	})
 })
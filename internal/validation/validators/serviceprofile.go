@@ -0,0 +1,245 @@
+package validators
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+var serviceProfileGVR = schema.GroupVersionResource{
+	Group:    "linkerd.io",
+	Version:  "v1alpha2",
+	Resource: "serviceprofiles",
+}
+
+// ServiceProfileValidator validates Linkerd ServiceProfile CRDs
+type ServiceProfileValidator struct {
+	dynamicClient dynamic.Interface
+}
+
+// NewServiceProfileValidator creates a new ServiceProfile validator
+func NewServiceProfileValidator(dynamicClient dynamic.Interface) *ServiceProfileValidator {
+	return &ServiceProfileValidator{
+		dynamicClient: dynamicClient,
+	}
+}
+
+// Validate validates a ServiceProfile resource
+func (v *ServiceProfileValidator) Validate(ctx context.Context, profile *unstructured.Unstructured) ValidationResult {
+	result := ValidationResult{
+		ResourceType: "ServiceProfile",
+		Name:         profile.GetName(),
+		Namespace:    profile.GetNamespace(),
+		Issues:       []Issue{},
+	}
+
+	// Validate name convention
+	v.validateName(&result, profile.GetName(), profile.GetNamespace())
+
+	// Extract spec
+	spec, found, err := unstructured.NestedMap(profile.Object, "spec")
+	if err != nil || !found {
+		result.AddIssue(SeverityError, "Missing or invalid spec", "spec", "LNKD-SP001", "Add a valid spec field to the ServiceProfile")
+		result.Finalize()
+		return result
+	}
+
+	// Validate routes
+	v.validateRoutes(&result, spec)
+
+	// Validate retry budget
+	if retryBudget, found, _ := unstructured.NestedMap(spec, "retryBudget"); found {
+		v.validateRetryBudget(&result, retryBudget)
+	}
+
+	result.Finalize()
+	return result
+}
+
+func (v *ServiceProfileValidator) validateName(result *ValidationResult, name, namespace string) {
+	// ServiceProfiles only apply when named after the FQDN of a Service
+	parts := strings.SplitN(name, ".", 4)
+	if len(parts) == 4 && parts[0] != "" && parts[1] == namespace && parts[2] == "svc" && parts[3] != "" {
+		return
+	}
+
+	result.AddIssue(SeverityWarning,
+		fmt.Sprintf("Name '%s' does not match the Service FQDN convention", name),
+		"metadata.name",
+		"LNKD-SP002",
+		fmt.Sprintf("Name the ServiceProfile <service>.%s.svc.cluster.local so the proxy applies it", namespace))
+}
+
+func (v *ServiceProfileValidator) validateRoutes(result *ValidationResult, spec map[string]interface{}) {
+	routes, found, _ := unstructured.NestedSlice(spec, "routes")
+	if !found || len(routes) == 0 {
+		result.AddIssue(SeverityInfo,
+			"No routes defined",
+			"spec.routes",
+			"LNKD-SP003",
+			"Define routes to get per-route metrics, retries and timeouts")
+		return
+	}
+
+	for i, route := range routes {
+		field := fmt.Sprintf("spec.routes[%d]", i)
+		routeMap, ok := route.(map[string]interface{})
+		if !ok {
+			result.AddIssue(SeverityError,
+				fmt.Sprintf("Invalid route format at index %d", i),
+				field,
+				"LNKD-SP004",
+				"Each route must have a name and a condition")
+			continue
+		}
+
+		if name, _, _ := unstructured.NestedString(routeMap, "name"); name == "" {
+			result.AddIssue(SeverityError,
+				fmt.Sprintf("Missing route name at index %d", i),
+				field+".name",
+				"LNKD-SP005",
+				"Specify a name for the route")
+		}
+
+		condition, found, _ := unstructured.NestedMap(routeMap, "condition")
+		if !found {
+			result.AddIssue(SeverityError,
+				fmt.Sprintf("Missing route condition at index %d", i),
+				field+".condition",
+				"LNKD-SP006",
+				"Specify a condition with a method and/or pathRegex")
+		} else {
+			v.validateCondition(result, condition, field+".condition")
+		}
+
+		if timeout, found, _ := unstructured.NestedString(routeMap, "timeout"); found {
+			v.validateDuration(result, timeout, field+".timeout", "LNKD-SP008")
+		}
+	}
+}
+
+// validateCondition checks that every pathRegex in a route condition compiles, including those
+// nested in all, any and not conditions
+func (v *ServiceProfileValidator) validateCondition(result *ValidationResult, condition map[string]interface{}, field string) {
+	if pathRegex, ok := condition["pathRegex"].(string); ok {
+		if _, err := regexp.Compile(pathRegex); err != nil {
+			result.AddIssue(SeverityError,
+				fmt.Sprintf("Invalid pathRegex '%s': %v", pathRegex, err),
+				field+".pathRegex",
+				"LNKD-SP007",
+				"Fix the regular expression syntax")
+		}
+	}
+
+	for _, key := range []string{"all", "any"} {
+		nested, _ := condition[key].([]interface{})
+		for i, n := range nested {
+			if nestedMap, ok := n.(map[string]interface{}); ok {
+				v.validateCondition(result, nestedMap, fmt.Sprintf("%s.%s[%d]", field, key, i))
+			}
+		}
+	}
+	if not, ok := condition["not"].(map[string]interface{}); ok {
+		v.validateCondition(result, not, field+".not")
+	}
+}
+
+func (v *ServiceProfileValidator) validateRetryBudget(result *ValidationResult, retryBudget map[string]interface{}) {
+	if value, found := retryBudget["retryRatio"]; found {
+		ratio, ok := toFloat64(value)
+		switch {
+		case !ok || ratio < 0:
+			result.AddIssue(SeverityError,
+				fmt.Sprintf("Invalid retryRatio '%v': must be a non-negative number", value),
+				"spec.retryBudget.retryRatio",
+				"LNKD-SP009",
+				"Set retryRatio to a ratio such as 0.2 (20% additional load from retries)")
+		case ratio > 1:
+			result.AddIssue(SeverityWarning,
+				fmt.Sprintf("retryRatio %v allows retries to exceed original requests", ratio),
+				"spec.retryBudget.retryRatio",
+				"LNKD-SP010",
+				"Consider a retryRatio of 1 or less to avoid retry storms")
+		}
+	}
+
+	if value, found := retryBudget["minRetriesPerSecond"]; found {
+		if minRetries, ok := toFloat64(value); !ok || minRetries < 0 {
+			result.AddIssue(SeverityError,
+				fmt.Sprintf("Invalid minRetriesPerSecond '%v': must be a non-negative integer", value),
+				"spec.retryBudget.minRetriesPerSecond",
+				"LNKD-SP011",
+				"Set minRetriesPerSecond to a non-negative integer such as 10")
+		}
+	}
+
+	if ttl, found, _ := unstructured.NestedString(retryBudget, "ttl"); found {
+		v.validateDuration(result, ttl, "spec.retryBudget.ttl", "LNKD-SP012")
+	}
+}
+
+func (v *ServiceProfileValidator) validateDuration(result *ValidationResult, value, field, code string) {
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		result.AddIssue(SeverityError,
+			fmt.Sprintf("Invalid duration '%s'", value),
+			field,
+			code,
+			"Use a duration such as 300ms, 10s or 1m")
+		return
+	}
+	if duration < 0 {
+		result.AddIssue(SeverityError,
+			fmt.Sprintf("Negative duration '%s'", value),
+			field,
+			code,
+			"Use a non-negative duration")
+	}
+}
+
+// toFloat64 converts a numeric field of an unstructured object
+func toFloat64(value interface{}) (float64, bool) {
+	switch n := value.(type) {
+	case float64:
+		return n, true
+	case int64:
+		return float64(n), true
+	default:
+		return 0, false
+	}
+}
+
+// ValidateAll validates all ServiceProfile resources in a namespace. When they
+// can't be listed, because the CRD isn't installed or listing is forbidden, the only result is
+// a diagnostic one
+func (v *ServiceProfileValidator) ValidateAll(ctx context.Context, namespace string) []ValidationResult {
+	var results []ValidationResult
+
+	listOptions := metav1.ListOptions{}
+	var profiles *unstructured.UnstructuredList
+	var err error
+
+	if namespace == "" {
+		profiles, err = v.dynamicClient.Resource(serviceProfileGVR).List(ctx, listOptions)
+	} else {
+		profiles, err = v.dynamicClient.Resource(serviceProfileGVR).Namespace(namespace).List(ctx, listOptions)
+	}
+
+	if err != nil {
+		return append(results, listFailureResult(serviceProfileGVR, "ServiceProfile", namespace, err))
+	}
+
+	for i := range profiles.Items {
+		result := v.Validate(ctx, &profiles.Items[i])
+		results = append(results, result)
+	}
+
+	return results
+}
@@ -0,0 +1,182 @@
+package validators_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/christianhuening/linkerd-mcp/internal/testutil"
+	"github.com/christianhuening/linkerd-mcp/internal/validation/validators"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/fake"
+)
+
+var _ = Describe("ServiceProfileValidator", func() {
+	var (
+		ctx               context.Context
+		validator         *validators.ServiceProfileValidator
+		dynamicClient     *fake.FakeDynamicClient
+		serviceProfileGVR schema.GroupVersionResource
+	)
+
+	issueCodes := func(result validators.ValidationResult) []string {
+		codes := []string{}
+		for _, issue := range result.Issues {
+			codes = append(codes, issue.Code)
+		}
+		return codes
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+
+		serviceProfileGVR = schema.GroupVersionResource{
+			Group:    "linkerd.io",
+			Version:  "v1alpha2",
+			Resource: "serviceprofiles",
+		}
+
+		scheme := runtime.NewScheme()
+		gvrToListKind := map[schema.GroupVersionResource]string{
+			serviceProfileGVR: "ServiceProfileList",
+		}
+
+		dynamicClient = fake.NewSimpleDynamicClientWithCustomListKinds(scheme, gvrToListKind)
+		validator = validators.NewServiceProfileValidator(dynamicClient)
+	})
+
+	Describe("Validate", func() {
+		Context("with a valid ServiceProfile", func() {
+			It("should pass validation", func() {
+				profile := testutil.CreateServiceProfile("web.prod.svc.cluster.local", "prod",
+					[]map[string]interface{}{
+						{
+							"name":        "GET /api/list",
+							"condition":   map[string]interface{}{"method": "GET", "pathRegex": "/api/list"},
+							"isRetryable": true,
+							"timeout":     "300ms",
+						},
+						{
+							"name": "POST /api/vote",
+							"condition": map[string]interface{}{
+								"all": []interface{}{
+									map[string]interface{}{"method": "POST"},
+									map[string]interface{}{"pathRegex": "/api/vote/[^/]*"},
+								},
+							},
+						},
+					},
+					map[string]interface{}{"retryRatio": 0.2, "minRetriesPerSecond": int64(10), "ttl": "10s"})
+
+				result := validator.Validate(ctx, profile)
+
+				Expect(result.Valid).To(BeTrue())
+				Expect(result.Issues).To(BeEmpty())
+				Expect(result.ResourceType).To(Equal("ServiceProfile"))
+				Expect(result.Name).To(Equal("web.prod.svc.cluster.local"))
+			})
+		})
+
+		Context("with a name that isn't a Service FQDN", func() {
+			It("should warn about the naming convention", func() {
+				profile := testutil.CreateServiceProfile("web", "prod",
+					[]map[string]interface{}{{"name": "GET /", "condition": map[string]interface{}{"method": "GET"}}}, nil)
+
+				result := validator.Validate(ctx, profile)
+
+				Expect(result.Valid).To(BeTrue())
+				Expect(issueCodes(result)).To(ConsistOf("LNKD-SP002"))
+			})
+
+			It("should warn when the namespace in the name differs", func() {
+				profile := testutil.CreateServiceProfile("web.staging.svc.cluster.local", "prod",
+					[]map[string]interface{}{{"name": "GET /", "condition": map[string]interface{}{"method": "GET"}}}, nil)
+
+				result := validator.Validate(ctx, profile)
+
+				Expect(issueCodes(result)).To(ConsistOf("LNKD-SP002"))
+			})
+		})
+
+		Context("without routes", func() {
+			It("should report an informational issue", func() {
+				profile := testutil.CreateServiceProfile("web.prod.svc.cluster.local", "prod", nil, nil)
+
+				result := validator.Validate(ctx, profile)
+
+				Expect(result.Valid).To(BeTrue())
+				Expect(issueCodes(result)).To(ConsistOf("LNKD-SP003"))
+				Expect(result.Issues[0].Severity).To(Equal(validators.SeverityInfo))
+			})
+		})
+
+		Context("with malformed routes", func() {
+			It("should report missing names, conditions, invalid regexes and timeouts", func() {
+				profile := testutil.CreateServiceProfile("web.prod.svc.cluster.local", "prod",
+					[]map[string]interface{}{
+						{"condition": map[string]interface{}{"pathRegex": "/api/(list"}},
+						{"name": "no condition", "timeout": "10 seconds"},
+						{
+							"name":      "nested",
+							"condition": map[string]interface{}{"not": map[string]interface{}{"pathRegex": "[z-a]"}},
+							"timeout":   "-1s",
+						},
+					}, nil)
+
+				result := validator.Validate(ctx, profile)
+
+				Expect(result.Valid).To(BeFalse())
+				Expect(issueCodes(result)).To(ConsistOf("LNKD-SP005", "LNKD-SP007", "LNKD-SP006", "LNKD-SP008", "LNKD-SP007", "LNKD-SP008"))
+
+				fields := []string{}
+				for _, issue := range result.Issues {
+					fields = append(fields, issue.Field)
+				}
+				Expect(fields).To(ContainElement("spec.routes[0].condition.pathRegex"))
+				Expect(fields).To(ContainElement("spec.routes[2].condition.not.pathRegex"))
+				Expect(fields).To(ContainElement("spec.routes[2].timeout"))
+			})
+		})
+
+		Context("with a malformed retry budget", func() {
+			It("should report invalid ratios, retries and ttl", func() {
+				profile := testutil.CreateServiceProfile("web.prod.svc.cluster.local", "prod",
+					[]map[string]interface{}{{"name": "GET /", "condition": map[string]interface{}{"method": "GET"}}},
+					map[string]interface{}{"retryRatio": -0.5, "minRetriesPerSecond": int64(-1), "ttl": "forever"})
+
+				result := validator.Validate(ctx, profile)
+
+				Expect(result.Valid).To(BeFalse())
+				Expect(issueCodes(result)).To(ConsistOf("LNKD-SP009", "LNKD-SP011", "LNKD-SP012"))
+			})
+
+			It("should warn when retries may exceed original requests", func() {
+				profile := testutil.CreateServiceProfile("web.prod.svc.cluster.local", "prod",
+					[]map[string]interface{}{{"name": "GET /", "condition": map[string]interface{}{"method": "GET"}}},
+					map[string]interface{}{"retryRatio": int64(2), "minRetriesPerSecond": int64(10), "ttl": "10s"})
+
+				result := validator.Validate(ctx, profile)
+
+				Expect(result.Valid).To(BeTrue())
+				Expect(issueCodes(result)).To(ConsistOf("LNKD-SP010"))
+			})
+		})
+	})
+
+	Describe("ValidateAll", func() {
+		It("should validate all ServiceProfiles in a namespace", func() {
+			for _, name := range []string{"web.prod.svc.cluster.local", "api.prod.svc.cluster.local"} {
+				profile := testutil.CreateServiceProfile(name, "prod", nil, nil)
+				_, err := dynamicClient.Resource(serviceProfileGVR).Namespace("prod").Create(ctx, profile, metav1.CreateOptions{})
+				Expect(err).NotTo(HaveOccurred())
+			}
+
+			results := validator.ValidateAll(ctx, "prod")
+
+			Expect(results).To(HaveLen(2))
+		})
+	})
+})
@@ -1,6 +1,14 @@
 package validators
 
-import "time"
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
 
 // Severity represents the severity level of a validation issue
 type Severity string
@@ -14,6 +22,27 @@ const (
 	SeverityInfo Severity = "info"
 )
 
+// severityRanks orders severities from least to most severe
+var severityRanks = map[Severity]int{
+	SeverityInfo:    0,
+	SeverityWarning: 1,
+	SeverityError:   2,
+}
+
+// ParseSeverity parses a severity name (info, warning or error)
+func ParseSeverity(value string) (Severity, error) {
+	severity := Severity(value)
+	if _, ok := severityRanks[severity]; !ok {
+		return "", fmt.Errorf("invalid severity '%s'. Must be one of: info, warning, error", value)
+	}
+	return severity, nil
+}
+
+// AtLeast reports whether s is as severe as min or more
+func (s Severity) AtLeast(min Severity) bool {
+	return severityRanks[s] >= severityRanks[min]
+}
+
 // Issue represents a single validation issue
 type Issue struct {
 	Severity    Severity `json:"severity"`
@@ -31,6 +60,9 @@ type ValidationResult struct {
 	Valid        bool      `json:"valid"`
 	Issues       []Issue   `json:"issues"`
 	Timestamp    time.Time `json:"timestamp"`
+	// Diagnostic is set on results reporting that a resource type couldn't be listed at all,
+	// rather than on a resource
+	Diagnostic bool `json:"diagnostic,omitempty"`
 }
 
 // ClusterValidationReport represents a complete validation report for the cluster
@@ -40,13 +72,39 @@ type ClusterValidationReport struct {
 	Results        []ValidationResult `json:"results"`
 	Summary        ValidationSummary  `json:"summary"`
 	Timestamp      time.Time          `json:"timestamp"`
+	StartedAt      time.Time          `json:"startedAt"`
+	CompletedAt    time.Time          `json:"completedAt"`
+	DurationMs     float64            `json:"durationMs"` // wall-clock time spent generating the report
+	FailOn         Severity           `json:"failOn"`     // lowest severity that fails the report (default: error)
+	Passed         bool               `json:"passed"`     // false when any issue at or above FailOn was found
+	// Truncated is set when results were left out to respect a results limit; the counts above
+	// still cover every resource
+	Truncated      bool `json:"truncated,omitempty"`
+	OmittedResults int  `json:"omittedResults,omitempty"`
 }
 
 // ValidationSummary provides summary statistics
 type ValidationSummary struct {
-	Errors   int `json:"errors"`
-	Warnings int `json:"warnings"`
-	Info     int `json:"info"`
+	Errors      int `json:"errors"`
+	Warnings    int `json:"warnings"`
+	Info        int `json:"info"`
+	Diagnostics int `json:"diagnostics"` // resource types that couldn't be listed
+}
+
+// ResourceReference identifies the field of a resource that references another object
+type ResourceReference struct {
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	Field     string `json:"field"`
+}
+
+// MissingServiceAccount is a service account referenced by authentication resources
+// that does not exist in the cluster
+type MissingServiceAccount struct {
+	ServiceAccount string              `json:"serviceAccount"`
+	Namespace      string              `json:"namespace"`
+	ReferencedBy   []ResourceReference `json:"referencedBy"`
 }
 
 // AddIssue adds an issue to the validation result
@@ -72,12 +130,59 @@ func (vr *ValidationResult) Finalize() {
 	}
 }
 
+// listFailureResult is the diagnostic result of a resource type whose List failed. Validators
+// report it instead of no results, so that a cluster without the CRD, or a service account
+// without the permission to list it, fails the report rather than passing with nothing to check.
+func listFailureResult(gvr schema.GroupVersionResource, resourceType, namespace string, err error) ValidationResult {
+	crd := gvr.Resource + "." + gvr.Group
+	result := ValidationResult{
+		ResourceType: resourceType,
+		Name:         crd,
+		Namespace:    namespace,
+		Issues:       []Issue{},
+		Diagnostic:   true,
+	}
+
+	switch {
+	case apierrors.IsNotFound(err) || meta.IsNoMatchError(err):
+		result.AddIssue(SeverityError, fmt.Sprintf("The %s CRD is not installed, so no %s resources could be validated", crd, resourceType), "", "LNKD-037",
+			"Install the Linkerd CRDs with `linkerd install --crds | kubectl apply -f -`, or check that the installed version serves "+gvr.Version)
+	case apierrors.IsForbidden(err):
+		result.AddIssue(SeverityError, fmt.Sprintf("Not allowed to list %s resources: %v", resourceType, err), "", "LNKD-038",
+			fmt.Sprintf("Grant the service account of linkerd-mcp list on %s in the %s API group", gvr.Resource, gvr.Group))
+	default:
+		result.AddIssue(SeverityError, fmt.Sprintf("Failed to list %s resources: %v", resourceType, err), "", "LNKD-039",
+			"Check that the Kubernetes API server is reachable and retry")
+	}
+
+	result.Finalize()
+	return result
+}
+
+// MissingCRD reports whether the result is the diagnostic of a CRD that isn't installed
+func (vr ValidationResult) MissingCRD() bool {
+	if !vr.Diagnostic {
+		return false
+	}
+	for _, issue := range vr.Issues {
+		if issue.Code == "LNKD-037" {
+			return true
+		}
+	}
+	return false
+}
+
 // AddResult adds a validation result to the report and updates summary
 func (cvr *ClusterValidationReport) AddResult(result ValidationResult) {
 	cvr.Results = append(cvr.Results, result)
-	cvr.TotalResources++
-	if result.Valid {
+	switch {
+	case result.Diagnostic:
+		cvr.Summary.Diagnostics++
+	case result.Valid:
+		cvr.TotalResources++
 		cvr.ValidResources++
+	default:
+		cvr.TotalResources++
 	}
 
 	for _, issue := range result.Issues {
@@ -92,7 +197,60 @@ func (cvr *ClusterValidationReport) AddResult(result ValidationResult) {
 	}
 }
 
-// Finalize marks the report as complete
+// Truncate keeps at most maxResults results, preferring resources with errors, then warnings,
+// then info, over clean ones. Call it after Finalize, so that the counts and verdict cover every
+// result. A maxResults of zero or less keeps them all.
+func (cvr *ClusterValidationReport) Truncate(maxResults int) {
+	if maxResults <= 0 || len(cvr.Results) <= maxResults {
+		return
+	}
+
+	rank := func(result ValidationResult) int {
+		worst := 0
+		for _, issue := range result.Issues {
+			switch {
+			case issue.Severity == SeverityError:
+				return 3
+			case issue.Severity == SeverityWarning && worst < 2:
+				worst = 2
+			case issue.Severity == SeverityInfo && worst < 1:
+				worst = 1
+			}
+		}
+		return worst
+	}
+	sort.SliceStable(cvr.Results, func(i, j int) bool {
+		return rank(cvr.Results[i]) > rank(cvr.Results[j])
+	})
+
+	cvr.OmittedResults = len(cvr.Results) - maxResults
+	cvr.Results = cvr.Results[:maxResults]
+	cvr.Truncated = true
+}
+
+// Start records the time at which report generation began
+func (cvr *ClusterValidationReport) Start() {
+	cvr.StartedAt = time.Now()
+}
+
+// Finalize marks the report as complete, computes the generation duration and decides whether
+// the report passed
 func (cvr *ClusterValidationReport) Finalize() {
 	cvr.Timestamp = time.Now()
+	cvr.CompletedAt = cvr.Timestamp
+	if !cvr.StartedAt.IsZero() {
+		cvr.DurationMs = float64(cvr.CompletedAt.Sub(cvr.StartedAt)) / float64(time.Millisecond)
+	}
+
+	if cvr.FailOn == "" {
+		cvr.FailOn = SeverityError
+	}
+	cvr.Passed = true
+	for _, result := range cvr.Results {
+		for _, issue := range result.Issues {
+			if issue.Severity.AtLeast(cvr.FailOn) {
+				cvr.Passed = false
+			}
+		}
+	}
 }
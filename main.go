@@ -2,90 +2,265 @@ package main
 
 import (
 	"context"
+	"encoding/json"
+	"flag"
 	"fmt"
 	"log"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
 	"time"
 
+	"github.com/christianhuening/linkerd-mcp/internal/buildinfo"
 	"github.com/christianhuening/linkerd-mcp/internal/server"
 	mcpserver "github.com/mark3labs/mcp-go/server"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
-func main() {
-	// Initialize the Linkerd MCP server
-	linkerdServer, err := server.New()
-	if err != nil {
-		log.Fatalf("Failed to initialize Linkerd MCP server: %v", err)
+// Build details, set at link time with -ldflags "-X main.version=... -X main.commit=... -X main.date=..."
+var (
+	version string
+	commit  string
+	date    string
+)
+
+// Supported MCP transports
+const (
+	transportHTTP  = "http"
+	transportStdio = "stdio"
+)
+
+// resolveTransport picks the MCP transport from the --transport flag, falling back to the
+// MCP_TRANSPORT environment variable and then to HTTP
+func resolveTransport(flagValue, envValue string) (string, error) {
+	transport := flagValue
+	if transport == "" {
+		transport = envValue
+	}
+	if transport == "" {
+		return transportHTTP, nil
+	}
+	if transport != transportHTTP && transport != transportStdio {
+		return "", fmt.Errorf("unsupported transport %q (must be one of: %s, %s)", transport, transportHTTP, transportStdio)
 	}
+	return transport, nil
+}
 
-	// Create MCP server with tool capabilities
-	s := mcpserver.NewMCPServer(
-		"linkerd-mcp",
-		"1.0.0",
-		mcpserver.WithToolCapabilities(true),
-	)
+// listenConfig is where and how the HTTP server listens
+type listenConfig struct {
+	addr     string
+	certFile string
+	keyFile  string
+}
 
-	// Register all tools
-	linkerdServer.RegisterTools(s)
+// tls reports whether the server should serve HTTPS
+func (c listenConfig) tls() bool {
+	return c.certFile != ""
+}
 
-	// Get port from environment or use default
-	port := os.Getenv("PORT")
+// baseURL is the URL the server can be reached at from the local machine
+func (c listenConfig) baseURL() string {
+	scheme := "http"
+	if c.tls() {
+		scheme = "https"
+	}
+	host, port, _ := net.SplitHostPort(c.addr)
+	if host == "" || host == "0.0.0.0" || host == "::" {
+		host = "localhost"
+	}
+	return scheme + "://" + net.JoinHostPort(host, port)
+}
+
+// resolveListenConfig builds the listen address from BIND_ADDRESS and PORT and validates that a
+// TLS certificate and key are either both set or both unset
+func resolveListenConfig(bindAddress, port, certFile, keyFile string) (listenConfig, error) {
 	if port == "" {
 		port = "8080"
 	}
+	if (certFile == "") != (keyFile == "") {
+		return listenConfig{}, fmt.Errorf("TLS_CERT_FILE and TLS_KEY_FILE must be set together")
+	}
+	return listenConfig{
+		addr:     net.JoinHostPort(bindAddress, port),
+		certFile: certFile,
+		keyFile:  keyFile,
+	}, nil
+}
 
-	// Create HTTP mux
-	mux := http.NewServeMux()
+// minWriteTimeout bounds writing an HTTP response when every tool times out sooner
+const minWriteTimeout = 15 * time.Second
 
-	// Health check endpoint
-	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
+// writeTimeoutMargin leaves time to write the response of a tool call that ran for its whole
+// timeout, including the timeout error itself
+const writeTimeoutMargin = 10 * time.Second
+
+// httpWriteTimeout returns the write timeout of the HTTP server, long enough for the slowest
+// tool to reply before the connection is cut
+func httpWriteTimeout(maxToolTimeout time.Duration) time.Duration {
+	return max(minWriteTimeout, maxToolTimeout+writeTimeoutMargin)
+}
+
+// healthHandler serves the liveness probe, reporting the server version
+func healthHandler(version string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusOK)
-		if _, err := fmt.Fprintf(w, `{"status":"healthy","service":"linkerd-mcp","version":"1.0.0"}`); err != nil {
+		if err := json.NewEncoder(w).Encode(map[string]string{"status": "healthy", "service": "linkerd-mcp", "version": version}); err != nil {
 			log.Printf("Error writing health response: %v", err)
 		}
 	})
+}
+
+// versionHandler serves the build details of the server
+func versionHandler(info buildinfo.Info) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		if err := json.NewEncoder(w).Encode(info); err != nil {
+			log.Printf("Error writing version response: %v", err)
+		}
+	})
+}
 
-	// Readiness check endpoint
-	mux.HandleFunc("/ready", func(w http.ResponseWriter, r *http.Request) {
+// readyHandler serves the readiness probe, answering 503 with the reason while check fails
+func readyHandler(check func(ctx context.Context) error) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
+		if err := check(r.Context()); err != nil {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			if err := json.NewEncoder(w).Encode(map[string]string{"status": "not ready", "reason": err.Error()}); err != nil {
+				log.Printf("Error writing ready response: %v", err)
+			}
+			return
+		}
+
 		w.WriteHeader(http.StatusOK)
 		if _, err := fmt.Fprintf(w, `{"status":"ready"}`); err != nil {
 			log.Printf("Error writing ready response: %v", err)
 		}
 	})
+}
+
+func main() {
+	transportFlag := flag.String("transport", "", "MCP transport: http or stdio (default http, or MCP_TRANSPORT)")
+	contextFlag := flag.String("context", "", "Kubeconfig context to use (default the current context, or KUBE_CONTEXT)")
+	flag.Parse()
+
+	// The Kubernetes clients are configured from the environment, so the flag overrides KUBE_CONTEXT
+	if *contextFlag != "" {
+		if err := os.Setenv("KUBE_CONTEXT", *contextFlag); err != nil {
+			log.Fatalf("Failed to set kubeconfig context: %v", err)
+		}
+	}
+
+	transport, err := resolveTransport(*transportFlag, os.Getenv("MCP_TRANSPORT"))
+	if err != nil {
+		log.Fatalf("Invalid transport: %v", err)
+	}
+
+	build := buildinfo.New(version, commit, date)
+
+	// Initialize the Linkerd MCP server
+	linkerdServer, err := server.New()
+	if err != nil {
+		log.Fatalf("Failed to initialize Linkerd MCP server: %v", err)
+	}
+	linkerdServer.SetBuildInfo(build)
+	defer linkerdServer.Close()
+
+	// Re-check the Kubernetes API server in the background when KUBE_CHECK_INTERVAL is set
+	watchCtx, stopWatch := context.WithCancel(context.Background())
+	defer stopWatch()
+	go linkerdServer.WatchKubernetes(watchCtx)
+
+	// Create MCP server with tool, resource and prompt capabilities
+	s := mcpserver.NewMCPServer(
+		"linkerd-mcp",
+		build.Version,
+		mcpserver.WithToolCapabilities(true),
+		mcpserver.WithResourceCapabilities(false, false),
+		mcpserver.WithPromptCapabilities(false),
+	)
+
+	// Register all tools, resources and prompts
+	linkerdServer.RegisterTools(s)
+	linkerdServer.RegisterResources(s)
+	linkerdServer.RegisterPrompts(s)
+
+	// In stdio mode the client owns the process, so no HTTP listener or health endpoints are started.
+	// Logs go to stderr and never interfere with the protocol on stdout.
+	if transport == transportStdio {
+		log.Printf("Starting MCP server on stdio")
+		if err := mcpserver.ServeStdio(s); err != nil {
+			linkerdServer.Close()
+			log.Fatalf("Server error: %v", err)
+		}
+		return
+	}
+
+	// Get the listen address and optional TLS key pair from the environment
+	listen, err := resolveListenConfig(os.Getenv("BIND_ADDRESS"), os.Getenv("PORT"), os.Getenv("TLS_CERT_FILE"), os.Getenv("TLS_KEY_FILE"))
+	if err != nil {
+		log.Fatalf("Invalid listen configuration: %v", err)
+	}
+
+	// Create HTTP mux
+	mux := http.NewServeMux()
+
+	// Health check endpoint
+	mux.Handle("/health", healthHandler(build.Version))
+
+	// Build details: version, git commit, build date and Go version
+	mux.Handle("/version", versionHandler(build))
+
+	// Readiness check endpoint: only ready while the Kubernetes API server is reachable
+	mux.Handle("/ready", readyHandler(linkerdServer.Ready))
+
+	// Prometheus metrics about the server itself (tool calls, upstream query durations)
+	mux.Handle("/metrics", promhttp.Handler())
 
 	// Create StreamableHTTP server for MCP protocol (replaces deprecated SSE)
 	// This mounts the MCP endpoints at /mcp/*
 	streamableServer := mcpserver.NewStreamableHTTPServer(s)
 
-	// Mount StreamableHTTP server at /mcp
-	mux.Handle("/mcp/", http.StripPrefix("/mcp", streamableServer))
+	// Mount StreamableHTTP server at /mcp, with CORS headers for the origins in CORS_ALLOWED_ORIGINS
+	mux.Handle("/mcp/", withCORS(parseAllowedOrigins(os.Getenv("CORS_ALLOWED_ORIGINS")), http.StripPrefix("/mcp", streamableServer)))
 
-	// Create HTTP server with timeouts
+	// Create HTTP server with timeouts. The write timeout outlasts the slowest tool, whose calls
+	// withTimeout bounds. Every request is logged and handler panics are recovered into a 500
+	// response.
 	httpServer := &http.Server{
-		Addr:         ":" + port,
-		Handler:      mux,
+		Addr:         listen.addr,
+		Handler:      withMiddleware(mux),
 		ReadTimeout:  15 * time.Second,
-		WriteTimeout: 15 * time.Second,
+		WriteTimeout: httpWriteTimeout(linkerdServer.MaxToolTimeout()),
 		IdleTimeout:  60 * time.Second,
 	}
 
 	// Start server in goroutine
 	go func() {
-		log.Printf("Starting MCP server on port %s", port)
-		log.Printf("Health check: http://localhost:%s/health", port)
-		log.Printf("Readiness check: http://localhost:%s/ready", port)
-		log.Printf("MCP StreamableHTTP endpoint: http://localhost:%s/mcp", port)
+		baseURL := listen.baseURL()
+		log.Printf("Starting MCP server on %s (TLS: %t)", listen.addr, listen.tls())
+		log.Printf("Health check: %s/health", baseURL)
+		log.Printf("Readiness check: %s/ready", baseURL)
+		log.Printf("Version: %s/version", baseURL)
+		log.Printf("Metrics: %s/metrics", baseURL)
+		log.Printf("MCP StreamableHTTP endpoint: %s/mcp", baseURL)
 		log.Printf("  - POST /mcp/initialize")
 		log.Printf("  - POST /mcp/tools/list")
 		log.Printf("  - POST /mcp/tools/call")
 		log.Printf("  - GET /mcp/health")
 		log.Printf("  - GET /mcp/capabilities")
-		if err := httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+
+		var err error
+		if listen.tls() {
+			err = httpServer.ListenAndServeTLS(listen.certFile, listen.keyFile)
+		} else {
+			err = httpServer.ListenAndServe()
+		}
+		if err != nil && err != http.ErrServerClosed {
 			log.Fatalf("Server error: %v", err)
 		}
 	}()
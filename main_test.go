@@ -3,13 +3,16 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/christianhuening/linkerd-mcp/internal/buildinfo"
 	"github.com/christianhuening/linkerd-mcp/internal/server"
 	mcpserver "github.com/mark3labs/mcp-go/server"
 )
@@ -502,3 +505,198 @@ func TestEndpoints_WithQuery(t *testing.T) {
 		})
 	}
 }
+
+// TestResolveTransport tests transport selection from the flag and environment
+func TestResolveTransport(t *testing.T) {
+	tests := []struct {
+		name      string
+		flagValue string
+		envValue  string
+		expected  string
+		expectErr bool
+	}{
+		{name: "defaults to http", expected: "http"},
+		{name: "stdio from flag", flagValue: "stdio", expected: "stdio"},
+		{name: "stdio from env", envValue: "stdio", expected: "stdio"},
+		{name: "flag overrides env", flagValue: "http", envValue: "stdio", expected: "http"},
+		{name: "rejects unknown transport", flagValue: "sse", expectErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transport, err := resolveTransport(tt.flagValue, tt.envValue)
+			if tt.expectErr {
+				if err == nil {
+					t.Errorf("Expected error for transport %q, got none", tt.flagValue)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if transport != tt.expected {
+				t.Errorf("Expected transport '%s', got '%s'", tt.expected, transport)
+			}
+		})
+	}
+}
+
+// TestReadyHandler tests that readiness reflects the Kubernetes check
+func TestReadyHandler(t *testing.T) {
+	tests := []struct {
+		name           string
+		checkErr       error
+		expectedStatus int
+		expectedBody   map[string]interface{}
+	}{
+		{
+			name:           "ready when the check passes",
+			expectedStatus: http.StatusOK,
+			expectedBody:   map[string]interface{}{"status": "ready"},
+		},
+		{
+			name:           "not ready with the reason when the check fails",
+			checkErr:       errors.New("kubernetes API server is unreachable"),
+			expectedStatus: http.StatusServiceUnavailable,
+			expectedBody:   map[string]interface{}{"status": "not ready", "reason": "kubernetes API server is unreachable"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := readyHandler(func(ctx context.Context) error { return tt.checkErr })
+
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, httptest.NewRequest("GET", "/ready", nil))
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("Expected status code %d, got %d", tt.expectedStatus, w.Code)
+			}
+			if ct := w.Header().Get("Content-Type"); ct != "application/json" {
+				t.Errorf("Expected Content-Type 'application/json', got '%s'", ct)
+			}
+
+			var response map[string]interface{}
+			if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+			if !reflect.DeepEqual(response, tt.expectedBody) {
+				t.Errorf("Expected response %v, got %v", tt.expectedBody, response)
+			}
+		})
+	}
+}
+
+// TestResolveListenConfig tests the listen address and TLS settings from the environment
+func TestResolveListenConfig(t *testing.T) {
+	tests := []struct {
+		name            string
+		bindAddress     string
+		port            string
+		certFile        string
+		keyFile         string
+		expectedAddr    string
+		expectedTLS     bool
+		expectedBaseURL string
+		expectErr       bool
+	}{
+		{name: "defaults to plain HTTP on :8080", expectedAddr: ":8080", expectedBaseURL: "http://localhost:8080"},
+		{name: "custom port", port: "9090", expectedAddr: ":9090", expectedBaseURL: "http://localhost:9090"},
+		{name: "bind address", bindAddress: "127.0.0.1", port: "9090", expectedAddr: "127.0.0.1:9090", expectedBaseURL: "http://127.0.0.1:9090"},
+		{name: "IPv6 bind address", bindAddress: "::1", expectedAddr: "[::1]:8080", expectedBaseURL: "http://[::1]:8080"},
+		{
+			name:            "TLS with certificate and key",
+			certFile:        "/etc/tls/tls.crt",
+			keyFile:         "/etc/tls/tls.key",
+			expectedAddr:    ":8080",
+			expectedTLS:     true,
+			expectedBaseURL: "https://localhost:8080",
+		},
+		{name: "rejects certificate without key", certFile: "/etc/tls/tls.crt", expectErr: true},
+		{name: "rejects key without certificate", keyFile: "/etc/tls/tls.key", expectErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			listen, err := resolveListenConfig(tt.bindAddress, tt.port, tt.certFile, tt.keyFile)
+			if tt.expectErr {
+				if err == nil {
+					t.Error("Expected error for incomplete TLS configuration, got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if listen.addr != tt.expectedAddr {
+				t.Errorf("Expected address '%s', got '%s'", tt.expectedAddr, listen.addr)
+			}
+			if listen.tls() != tt.expectedTLS {
+				t.Errorf("Expected TLS %t, got %t", tt.expectedTLS, listen.tls())
+			}
+			if listen.baseURL() != tt.expectedBaseURL {
+				t.Errorf("Expected base URL '%s', got '%s'", tt.expectedBaseURL, listen.baseURL())
+			}
+		})
+	}
+}
+
+// TestHealthHandler tests that the health endpoint reports the build version
+func TestHealthHandler(t *testing.T) {
+	w := httptest.NewRecorder()
+	healthHandler("v1.2.3").ServeHTTP(w, httptest.NewRequest("GET", "/health", nil))
+
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status code %d, got %d", http.StatusOK, w.Code)
+	}
+
+	var response map[string]interface{}
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	expected := map[string]interface{}{"status": "healthy", "service": "linkerd-mcp", "version": "v1.2.3"}
+	if !reflect.DeepEqual(response, expected) {
+		t.Errorf("Expected response %v, got %v", expected, response)
+	}
+}
+
+// TestVersionHandler tests the /version endpoint
+func TestVersionHandler(t *testing.T) {
+	info := buildinfo.New("v1.2.3", "abc123", "2025-01-02T03:04:05Z")
+
+	w := httptest.NewRecorder()
+	versionHandler(info).ServeHTTP(w, httptest.NewRequest("GET", "/version", nil))
+
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status code %d, got %d", http.StatusOK, w.Code)
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("Expected Content-Type 'application/json', got '%s'", ct)
+	}
+
+	var response buildinfo.Info
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if response != info {
+		t.Errorf("Expected response %+v, got %+v", info, response)
+	}
+}
+
+// TestHTTPWriteTimeout tests that the write timeout outlasts the slowest tool
+func TestHTTPWriteTimeout(t *testing.T) {
+	tests := []struct {
+		maxToolTimeout time.Duration
+		want           time.Duration
+	}{
+		{maxToolTimeout: time.Second, want: 15 * time.Second},
+		{maxToolTimeout: 30 * time.Second, want: 40 * time.Second},
+		{maxToolTimeout: 2 * time.Minute, want: 2*time.Minute + 10*time.Second},
+	}
+
+	for _, tt := range tests {
+		if got := httpWriteTimeout(tt.maxToolTimeout); got != tt.want {
+			t.Errorf("httpWriteTimeout(%s) = %s, want %s", tt.maxToolTimeout, got, tt.want)
+		}
+	}
+}
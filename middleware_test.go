@@ -0,0 +1,170 @@
+package main
+
+import (
+	"bytes"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// captureLogs redirects the standard logger for the duration of a test
+func captureLogs(t *testing.T) *bytes.Buffer {
+	var buf bytes.Buffer
+	previous := log.Writer()
+	log.SetOutput(&buf)
+	t.Cleanup(func() { log.SetOutput(previous) })
+	return &buf
+}
+
+// TestMiddleware_LogsRequests tests that each request is logged with its status and duration
+func TestMiddleware_LogsRequests(t *testing.T) {
+	logs := captureLogs(t)
+
+	handler := withMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	}))
+
+	req := httptest.NewRequest("POST", "/mcp/tools/call", nil)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	if w.Code != http.StatusTeapot {
+		t.Errorf("Expected status code %d, got %d", http.StatusTeapot, w.Code)
+	}
+
+	line := logs.String()
+	for _, field := range []string{"http_request", "method=POST", `path="/mcp/tools/call"`, "status=418", "duration_ms="} {
+		if !strings.Contains(line, field) {
+			t.Errorf("Expected log line to contain %q, got %q", field, line)
+		}
+	}
+}
+
+// TestMiddleware_DefaultStatus tests that handlers writing no status are logged as 200
+func TestMiddleware_DefaultStatus(t *testing.T) {
+	logs := captureLogs(t)
+
+	handler := withMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"status":"ready"}`))
+	}))
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/ready", nil))
+
+	if !strings.Contains(logs.String(), "status=200") {
+		t.Errorf("Expected status=200 in log, got %q", logs.String())
+	}
+}
+
+// TestMiddleware_RecoversPanics tests that a panicking handler results in a logged 500
+func TestMiddleware_RecoversPanics(t *testing.T) {
+	logs := captureLogs(t)
+
+	handler := withMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("tool handler exploded")
+	}))
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/health", nil))
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("Expected status code %d, got %d", http.StatusInternalServerError, w.Code)
+	}
+
+	output := logs.String()
+	for _, field := range []string{"http_panic", `error="tool handler exploded"`, "goroutine", "status=500"} {
+		if !strings.Contains(output, field) {
+			t.Errorf("Expected logs to contain %q, got %q", field, output)
+		}
+	}
+}
+
+// TestMiddleware_Flush tests that streamed responses can still be flushed
+func TestMiddleware_Flush(t *testing.T) {
+	captureLogs(t)
+
+	flushed := false
+	handler := withMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			t.Fatal("Expected response writer to implement http.Flusher")
+		}
+		_, _ = w.Write([]byte("data: {}\n\n"))
+		flusher.Flush()
+		flushed = true
+	}))
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/mcp/", nil))
+
+	if !flushed || !w.Flushed {
+		t.Error("Expected response to be flushed")
+	}
+}
+
+// TestParseAllowedOrigins tests splitting CORS_ALLOWED_ORIGINS
+func TestParseAllowedOrigins(t *testing.T) {
+	origins := parseAllowedOrigins(" https://a.example.com, ,https://b.example.com ")
+	if len(origins) != 2 || origins[0] != "https://a.example.com" || origins[1] != "https://b.example.com" {
+		t.Errorf("Expected two trimmed origins, got %q", origins)
+	}
+
+	if origins := parseAllowedOrigins(""); len(origins) != 0 {
+		t.Errorf("Expected no origins, got %q", origins)
+	}
+}
+
+// TestCORS tests CORS headers and preflight handling for allowed and other origins
+func TestCORS(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+	})
+
+	tests := []struct {
+		name           string
+		allowedOrigins []string
+		method         string
+		origin         string
+		preflight      bool
+		expectedStatus int
+		expectedOrigin string
+	}{
+		{"disabled", nil, "POST", "https://ui.example.com", false, http.StatusAccepted, ""},
+		{"allowed origin", []string{"https://ui.example.com"}, "POST", "https://ui.example.com", false, http.StatusAccepted, "https://ui.example.com"},
+		{"other origin", []string{"https://ui.example.com"}, "POST", "https://evil.example.com", false, http.StatusAccepted, ""},
+		{"no origin", []string{"https://ui.example.com"}, "POST", "", false, http.StatusAccepted, ""},
+		{"any origin", []string{"*"}, "POST", "https://ui.example.com", false, http.StatusAccepted, "https://ui.example.com"},
+		{"preflight", []string{"https://ui.example.com"}, "OPTIONS", "https://ui.example.com", true, http.StatusNoContent, "https://ui.example.com"},
+		{"preflight from other origin", []string{"https://ui.example.com"}, "OPTIONS", "https://evil.example.com", true, http.StatusAccepted, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/mcp/", nil)
+			if tt.origin != "" {
+				req.Header.Set("Origin", tt.origin)
+			}
+			if tt.preflight {
+				req.Header.Set("Access-Control-Request-Method", "POST")
+			}
+			w := httptest.NewRecorder()
+			withCORS(tt.allowedOrigins, next).ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("Expected status code %d, got %d", tt.expectedStatus, w.Code)
+			}
+			if got := w.Header().Get("Access-Control-Allow-Origin"); got != tt.expectedOrigin {
+				t.Errorf("Expected Access-Control-Allow-Origin %q, got %q", tt.expectedOrigin, got)
+			}
+
+			allowMethods := w.Header().Get("Access-Control-Allow-Methods")
+			if tt.expectedStatus == http.StatusNoContent && !strings.Contains(allowMethods, "POST") {
+				t.Errorf("Expected preflight to allow POST, got %q", allowMethods)
+			}
+			if tt.expectedStatus != http.StatusNoContent && allowMethods != "" {
+				t.Errorf("Expected no Access-Control-Allow-Methods, got %q", allowMethods)
+			}
+		})
+	}
+}